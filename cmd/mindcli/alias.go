@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/internal/tui"
+)
+
+// runAlias manages aliases: short names that jump straight to a document.
+func runAlias(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: mindcli alias <add|remove|list> [args...]")
+	}
+
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	switch args[0] {
+	case "add":
+		if len(args) != 3 || storage.NormalizeAlias(args[1]) == "" {
+			return fmt.Errorf("usage: mindcli alias add <name> <doc-path>")
+		}
+		doc, err := lookupDocument(s.db, args[2])
+		if err != nil {
+			return err
+		}
+		if err := s.db.SetAlias(ctx, args[1], doc.ID); err != nil {
+			return err
+		}
+		fmt.Printf("Alias %q → %s\n", storage.NormalizeAlias(args[1]), doc.Title)
+
+	case "remove":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: mindcli alias remove <name>")
+		}
+		if err := s.db.DeleteAlias(ctx, args[1]); errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("no alias %q", args[1])
+		} else if err != nil {
+			return err
+		}
+		fmt.Printf("Removed alias %q\n", storage.NormalizeAlias(args[1]))
+
+	case "list":
+		aliases, err := s.db.ListAliases(ctx)
+		if err != nil {
+			return err
+		}
+		printAliases(os.Stdout, aliases)
+
+	default:
+		return fmt.Errorf("unknown alias subcommand %q: use add, remove, or list", args[0])
+	}
+	return nil
+}
+
+// printAliases prints one alias per line with the document it points at.
+func printAliases(w io.Writer, aliases []storage.Alias) {
+	if len(aliases) == 0 {
+		fmt.Fprintln(w, "No aliases. Add one with 'mindcli alias add <name> <doc-path>'.")
+		return
+	}
+	width := 0
+	for _, a := range aliases {
+		width = max(width, len(a.Name))
+	}
+	for _, a := range aliases {
+		fmt.Fprintf(w, "%-*s  %s (%s)\n", width, a.Name, a.Title, a.Path)
+	}
+}
+
+// runOpen opens a document, named by alias, path or part of its title, in
+// its default app.
+func runOpen(args []string) error {
+	fs := flag.NewFlagSet("open", flag.ExitOnError)
+	printPath := fs.Bool("print", false, "Print the document's path instead of opening it")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: mindcli open [--print] <alias|doc-path|title>")
+	}
+
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	// The picker for ties asks on stderr, leaving stdout to --print.
+	doc, err := quickOpen(s.db, fs.Arg(0), os.Stdin, os.Stderr, isTerminal(os.Stdin))
+	if err != nil {
+		return err
+	}
+	path := doc.FilePath()
+	if *printPath {
+		fmt.Println(path)
+		return nil
+	}
+	if err := tui.OpenFile(path); err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	if err := s.db.RecordInteraction(context.Background(), doc.ID, storage.InteractionOpened); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return nil
+}
+
+// resolveAliasOrPath finds the document an alias points at, or else the
+// document at the given path.
+func resolveAliasOrPath(db *storage.DB, name string) (*storage.Document, error) {
+	doc, err := db.ResolveAlias(context.Background(), name)
+	if err == nil {
+		return doc, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	if doc, err := lookupDocument(db, name); err == nil {
+		return doc, nil
+	}
+	return nil, fmt.Errorf("no alias or indexed document %q", name)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestResolveAliasOrPath(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	ctx := t.Context()
+	doc := &storage.Document{ID: "1", Source: storage.SourceMarkdown, Path: "/notes/standup.md", Title: "Standup", ContentHash: "h"}
+	if err := db.InsertDocument(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetAlias(ctx, "standup", doc.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"standup", "StandUp", "/notes/standup.md"} {
+		if got, err := resolveAliasOrPath(db, name); err != nil || got.ID != doc.ID {
+			t.Errorf("resolveAliasOrPath(%q) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := resolveAliasOrPath(db, "retro"); err == nil {
+		t.Error("expected an error for an unknown alias")
+	}
+}
+
+func TestPrintAliases(t *testing.T) {
+	var buf bytes.Buffer
+	printAliases(&buf, []storage.Alias{
+		{Name: "go", Title: "Go notes", Path: "/notes/go.md", CreatedAt: time.Now()},
+		{Name: "standup", Title: "Standup", Path: "/notes/standup.md"},
+	})
+	want := "go       Go notes (/notes/go.md)\n" +
+		"standup  Standup (/notes/standup.md)\n"
+	if buf.String() != want {
+		t.Errorf("printAliases() output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	printAliases(&buf, nil)
+	if buf.Len() == 0 {
+		t.Error("printAliases() printed nothing for no aliases")
+	}
+}
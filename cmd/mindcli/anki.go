@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// card is a study card found in a note.
+type card struct {
+	Front string
+	Back  string
+}
+
+var (
+	// questionRe and answerRe match "Q: ..." and "A: ..." lines, also
+	// spelled out as "Question:" and "Answer:".
+	questionRe = regexp.MustCompile(`(?i)^\s*(?:[-*]\s+)?(?:q|question)\s*:\s*(.+)$`)
+	answerRe   = regexp.MustCompile(`(?i)^\s*(?:[-*]\s+)?(?:a|answer)\s*:\s*(.*)$`)
+	// inlineCardRe matches single-line cards, "front :: back", as written
+	// for Obsidian's spaced repetition plugin.
+	inlineCardRe = regexp.MustCompile(`^\s*(?:[-*]\s+)?(.+?)\s+::\s+(.+)$`)
+)
+
+// extractCards finds the cards in a note: a "Q:" line followed by an "A:"
+// answer, which runs until a blank line or the next question, and
+// "front :: back" lines. Fenced code blocks are skipped.
+func extractCards(content string) []card {
+	var cards []card
+	var question string // pending question awaiting its answer
+	var answer []string // lines of the answer being read
+	inAnswer, inFence := false, false
+	flush := func() {
+		if inAnswer {
+			if back := strings.TrimSpace(strings.Join(answer, "\n")); back != "" {
+				cards = append(cards, card{Front: question, Back: back})
+			}
+		}
+		question, answer, inAnswer = "", nil, false
+	}
+
+	sc := bufio.NewScanner(strings.NewReader(content))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		switch m := questionRe.FindStringSubmatch(line); {
+		case m != nil:
+			flush()
+			question = strings.TrimSpace(m[1])
+		case question != "" && !inAnswer && answerRe.MatchString(line):
+			inAnswer = true
+			answer = []string{answerRe.FindStringSubmatch(line)[1]}
+		case inAnswer && strings.TrimSpace(line) != "":
+			answer = append(answer, line)
+		case strings.TrimSpace(line) == "":
+			if inAnswer {
+				flush()
+			}
+		default:
+			flush()
+			if m := inlineCardRe.FindStringSubmatch(line); m != nil {
+				cards = append(cards, card{Front: strings.TrimSpace(m[1]), Back: strings.TrimSpace(m[2])})
+			}
+		}
+	}
+	flush()
+	return cards
+}
+
+// exportAnki writes the cards in the results as a tab-separated file that
+// Anki imports (File > Import) as Basic notes, tagged with each note's tags
+// and "mindcli".
+func exportAnki(w io.Writer, results storage.SearchResults, redactor privacy.Redactor) error {
+	if _, err := io.WriteString(w, "#separator:tab\n#html:true\n#tags column:3\n"); err != nil {
+		return err
+	}
+	for _, r := range results {
+		tags := ankiTags(r.Document.Metadata["tags"])
+		for _, c := range extractCards(r.Document.Content) {
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n",
+				ankiField(redactor.Redact(c.Front)), ankiField(redactor.Redact(c.Back)), tags); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ankiField escapes text for an HTML field of a tab-separated import.
+func ankiField(text string) string {
+	text = html.EscapeString(strings.ReplaceAll(text, "\t", " "))
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+// ankiTags converts comma-separated document tags to Anki's space-separated
+// tags, which can't contain spaces.
+func ankiTags(tags string) string {
+	out := []string{"mindcli"}
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.Join(strings.Fields(tag), "_"); tag != "" {
+			out = append(out, tag)
+		}
+	}
+	return strings.Join(out, " ")
+}
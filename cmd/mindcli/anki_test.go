@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestExtractCards(t *testing.T) {
+	content := "# Go\n\n" +
+		"Q: What does `go vet` do?\n" +
+		"A: Reports suspicious constructs,\n" +
+		"such as unreachable code.\n\n" +
+		"- Question: Zero value of a map?\n\n" +
+		"  Answer: nil\n" +
+		"Q: A question without an answer\n\n" +
+		"Goroutine :: a lightweight thread managed by the Go runtime\n" +
+		"```\nQ: in code\nA: skipped\nkey :: value\n```\n" +
+		"Plain text that is not a card.\n"
+	want := []card{
+		{Front: "What does `go vet` do?", Back: "Reports suspicious constructs,\nsuch as unreachable code."},
+		{Front: "Zero value of a map?", Back: "nil"},
+		{Front: "Goroutine", Back: "a lightweight thread managed by the Go runtime"},
+	}
+	if got := extractCards(content); !slices.Equal(got, want) {
+		t.Errorf("extractCards() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestExportAnki(t *testing.T) {
+	results := storage.SearchResults{{Document: &storage.Document{
+		Content:  "Q: Is <b>1 < 2</b>?\nA: Yes\tindeed\nand always.\n",
+		Metadata: map[string]string{"tags": "go,study notes"},
+	}}}
+	var buf bytes.Buffer
+	if err := exportAnki(&buf, results, privacy.Redactor{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "#separator:tab\n#html:true\n#tags column:3\n" +
+		"Is &lt;b&gt;1 &lt; 2&lt;/b&gt;?\tYes indeed<br>and always.\tmindcli go study_notes\n"
+	if buf.String() != want {
+		t.Errorf("exportAnki() =\n%q\nwant\n%q", buf.String(), want)
+	}
+
+	wantErr := errors.New("write failed")
+	if err := exportAnki(failingWriter{err: wantErr}, results, privacy.Redactor{}); !errors.Is(err, wantErr) {
+		t.Errorf("error = %v, want %v", err, wantErr)
+	}
+}
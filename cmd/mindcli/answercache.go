@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/query"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// answerCacheKey identifies a question together with everything else that
+// shapes its answer: filters, scope, retrieval settings, excluded sources,
+// the relevance floor and the model. scope names the collection or document
+// the question is limited to, or is empty for all documents. Questions
+// differing only in case and spacing share a key.
+func answerCacheKey(cfg *config.Config, parsed query.ParsedQuery, scope string, limit int, floor float64, excluded []storage.Source) string {
+	h := sha256.New()
+	for _, part := range []string{
+		strings.Join(strings.Fields(strings.ToLower(parsed.Original)), " "),
+		parsed.Filters(),
+		scope,
+		strconv.FormatFloat(parsed.Weight(cfg.Search.HybridWeight), 'g', -1, 64),
+		strconv.Itoa(limit),
+		strconv.FormatFloat(floor, 'g', -1, 64),
+		cfg.Embeddings.Provider,
+		cfg.Embeddings.LLMModel,
+		strconv.FormatBool(cfg.Query.LLMParsing),
+		cfg.Ask.AnswerLength,
+		strconv.Itoa(cfg.Ask.MaxContexts),
+		strconv.Itoa(cfg.Ask.MaxContextChars),
+		storage.JoinSources(excluded),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// newCachedAnswer returns the cache entry for a question answered from the
+// index at generation.
+func newCachedAnswer(key string, generation int64, t query.Transcript) *storage.CachedAnswer {
+	sources := make([]storage.CachedSource, len(t.Sources))
+	for i, doc := range t.Sources {
+		sources[i] = storage.CachedSource{DocumentID: doc.ID, ContentHash: doc.ContentHash}
+	}
+	return &storage.CachedAnswer{
+		Key:             key,
+		Question:        t.Question,
+		Answer:          t.Answer,
+		Sources:         sources,
+		ConfidenceLevel: t.Confidence.Level,
+		ConfidenceScore: t.Confidence.Score,
+		CreatedAt:       t.CreatedAt,
+		Generation:      generation,
+	}
+}
+
+// cachedTranscript returns the answer cached under key as a transcript, or
+// false if there is no answer still valid.
+func cachedTranscript(ctx context.Context, db *storage.DB, key string) (query.Transcript, bool) {
+	cached, err := db.GetCachedAnswer(ctx, key)
+	if err != nil {
+		return query.Transcript{}, false
+	}
+	t := query.Transcript{
+		Question:   cached.Question,
+		Answer:     cached.Answer,
+		Confidence: query.AnswerConfidence{Level: cached.ConfidenceLevel, Score: cached.ConfidenceScore},
+		CreatedAt:  cached.CreatedAt,
+	}
+	for _, src := range cached.Sources {
+		doc, err := db.GetDocument(ctx, src.DocumentID)
+		if err != nil {
+			return query.Transcript{}, false
+		}
+		t.Sources = append(t.Sources, doc)
+	}
+	return t, true
+}
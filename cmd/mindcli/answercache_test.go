@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/query"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestAnswerCacheKey(t *testing.T) {
+	cfg := config.Default()
+	key := answerCacheKey(cfg, query.ParseQuery("What is Go?"), "", 10, 0, nil)
+	if got := answerCacheKey(cfg, query.ParseQuery("  what   is go?"), "", 10, 0, nil); got != key {
+		t.Error("questions differing in case and spacing should share a key")
+	}
+	for name, other := range map[string]string{
+		"question":  answerCacheKey(cfg, query.ParseQuery("What is Rust?"), "", 10, 0, nil),
+		"filter":    answerCacheKey(cfg, query.ParseQuery("What is Go? in my notes"), "", 10, 0, nil),
+		"limit":     answerCacheKey(cfg, query.ParseQuery("What is Go?"), "", 5, 0, nil),
+		"scope":     answerCacheKey(cfg, query.ParseQuery("What is Go?"), "collection:reading-list", 10, 0, nil),
+		"floor":     answerCacheKey(cfg, query.ParseQuery("What is Go?"), "", 10, 0.5, nil),
+		"exclusion": answerCacheKey(cfg, query.ParseQuery("What is Go?"), "", 10, 0, []storage.Source{storage.SourceBrowser}),
+	} {
+		if other == key {
+			t.Errorf("a different %s should change the key", name)
+		}
+	}
+	cfg.Embeddings.LLMModel = "other-model"
+	if answerCacheKey(cfg, query.ParseQuery("What is Go?"), "", 10, 0, nil) == key {
+		t.Error("a different model should change the key")
+	}
+}
+
+func TestCachedTranscript(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	ctx := context.Background()
+
+	now := time.Now()
+	doc := &storage.Document{ID: "go", Source: storage.SourceMarkdown, Path: "/go.md", Title: "Go", ContentHash: "h1", IndexedAt: now, ModifiedAt: now}
+	if err := db.InsertDocument(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	answered := query.Transcript{
+		Question:   "What is Go?",
+		Answer:     "A language.",
+		Sources:    []*storage.Document{doc},
+		Confidence: query.AnswerConfidence{Level: "high", Score: 0.9},
+		CreatedAt:  now,
+	}
+	generation, err := db.IndexGeneration(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.PutCachedAnswer(ctx, newCachedAnswer("k", generation, answered)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := cachedTranscript(ctx, db, "k")
+	if !ok {
+		t.Fatal("cachedTranscript() found nothing")
+	}
+	if got.Answer != "A language." || got.Confidence.Level != "high" || len(got.Sources) != 1 || got.Sources[0].Title != "Go" {
+		t.Errorf("cachedTranscript() = %+v, want the answer with its source", got)
+	}
+
+	doc.ContentHash = "h2"
+	if err := db.UpsertDocument(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cachedTranscript(ctx, db, "k"); ok {
+		t.Error("cachedTranscript() served an answer whose source changed")
+	}
+}
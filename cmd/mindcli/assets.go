@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// copyAssets copies the files each exported note references into dir, so
+// an export can be moved without breaking its images, and records where
+// the copies went in the document's "assets" metadata. Copy paths are
+// relative to base when it is set, e.g. the directory of the output file.
+// A file shared by several notes is copied once; different files with the
+// same name get a numeric suffix.
+func copyAssets(ctx context.Context, db *storage.DB, results storage.SearchResults, dir, base string) (int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("creating assets directory: %w", err)
+	}
+	copies := make(map[string]string) // source path -> copy
+	used := make(map[string]bool)     // copy names taken
+	for _, r := range results {
+		doc := r.Document
+		sources, err := db.GetRelations(ctx, doc.ID, storage.RelationAsset)
+		if err != nil {
+			return len(copies), err
+		}
+		var names []string
+		for _, src := range sources {
+			dst, ok := copies[src]
+			if !ok {
+				dst = filepath.Join(dir, uniqueName(used, filepath.Base(src)))
+				if err := copyFile(src, dst); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: skipping asset %s: %v\n", src, err)
+					continue
+				}
+				copies[src] = dst
+			}
+			if base != "" {
+				if rel, err := filepath.Rel(base, dst); err == nil {
+					dst = rel
+				}
+			}
+			names = append(names, filepath.ToSlash(dst))
+		}
+		if len(names) > 0 {
+			doc.Metadata = maps.Clone(doc.Metadata)
+			if doc.Metadata == nil {
+				doc.Metadata = make(map[string]string)
+			}
+			doc.Metadata["assets"] = strings.Join(names, ", ")
+		}
+	}
+	return len(copies), nil
+}
+
+// uniqueName returns name, or name with a "-2", "-3", ... suffix before its
+// extension if it is already used, and marks the result used.
+func uniqueName(used map[string]bool, name string) string {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	candidate := name
+	for n := 2; used[strings.ToLower(candidate)]; n++ {
+		candidate = stem + "-" + strconv.Itoa(n) + ext
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
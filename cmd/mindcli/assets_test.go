@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestCopyAssets(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	// Two notes share one chart, and each has a photo named the same.
+	files := map[string]string{"a/chart.png": "chart", "a/photo.jpg": "photo a", "b/photo.jpg": "photo b"}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	now := time.Now()
+	assets := map[string][]string{
+		"a": {filepath.Join(dir, "a/chart.png"), filepath.Join(dir, "a/photo.jpg")},
+		"b": {filepath.Join(dir, "a/chart.png"), filepath.Join(dir, "b/photo.jpg"), filepath.Join(dir, "b/gone.png")},
+	}
+	var results storage.SearchResults
+	for _, id := range []string{"a", "b"} {
+		doc := &storage.Document{ID: id, Source: storage.SourceMarkdown, Path: filepath.Join(dir, id+".md"), ContentHash: id, IndexedAt: now, ModifiedAt: now}
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.SetRelations(ctx, id, storage.RelationAsset, assets[id]); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, &storage.SearchResult{Document: doc})
+	}
+
+	out := filepath.Join(dir, "export")
+	n, err := copyAssets(ctx, db, results, filepath.Join(out, "assets"), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("copyAssets() copied %d files, want 3", n)
+	}
+	if got := results[0].Document.Metadata["assets"]; got != "assets/chart.png, assets/photo.jpg" {
+		t.Errorf("a assets = %q", got)
+	}
+	if got := results[1].Document.Metadata["assets"]; got != "assets/chart.png, assets/photo-2.jpg" {
+		t.Errorf("b assets = %q, want the shared chart and a renamed photo", got)
+	}
+	if data, err := os.ReadFile(filepath.Join(out, "assets", "photo-2.jpg")); err != nil || string(data) != "photo b" {
+		t.Errorf("photo-2.jpg = %q, %v; want b's photo", data, err)
+	}
+}
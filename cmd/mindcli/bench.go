@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/embeddings"
+	"github.com/J-1000/mindcli/internal/index"
+	"github.com/J-1000/mindcli/internal/query"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// benchWords is the vocabulary of generated benchmark notes.
+var benchWords = strings.Fields(`go rust python channel goroutine mutex
+	compiler parser index search vector embedding query latency cache
+	database schema migration backup snapshot sync cluster network socket
+	protocol request response budget roadmap meeting project deadline review
+	design pattern refactor test benchmark profile memory garbage allocation
+	thread process kernel file system editor terminal shell script deploy
+	release version feature bug issue ticket customer travel recipe garden
+	book article paper research idea note journal habit focus energy`)
+
+// runBench indexes a corpus into a throwaway data directory, then times
+// searches in each mode. The user's own index is never touched.
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	corpus := fs.String("corpus", "", "Directory of markdown notes to index (default: generate notes)")
+	docs := fs.Int("docs", 500, "Number of notes to generate when no corpus is given")
+	queries := fs.Int("queries", 50, "Number of search queries per mode")
+	limit := fs.Int("limit", 10, "Results per query")
+	noEmbed := fs.Bool("no-embed", false, "Skip embeddings and benchmark BM25 only")
+	_ = fs.Parse(args)
+
+	if *docs < 1 || *queries < 1 || *limit < 1 {
+		return fmt.Errorf("usage: mindcli bench [--corpus dir | --docs N] [--queries N] [--limit N] [--no-embed]")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp("", "mindcli-bench-")
+	if err != nil {
+		return fmt.Errorf("creating bench directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	dir := *corpus
+	if dir == "" {
+		dir = filepath.Join(tmp, "notes")
+		fmt.Printf("Generating %d notes...\n", *docs)
+		if err := generateBenchCorpus(dir, *docs, rand.New(rand.NewSource(1))); err != nil {
+			return err
+		}
+	}
+
+	var embedder *timedEmbedder
+	if !*noEmbed {
+		if base := newEmbedder(cfg, cfg.Embeddings.Model); base != nil {
+			if _, err := base.Embed(context.Background(), "test"); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: embeddings unavailable (%s), benchmarking BM25 only: %v\n", cfg.Embeddings.Provider, err)
+			} else {
+				embedder = &timedEmbedder{Embedder: base}
+			}
+		}
+	}
+
+	return benchmark(context.Background(), os.Stdout, benchConfig(cfg, dir, tmp), embedder, *queries, *limit)
+}
+
+// benchConfig returns cfg reduced to indexing the markdown notes in dir, with
+// all data stored under dataDir.
+func benchConfig(cfg *config.Config, dir, dataDir string) *config.Config {
+	bc := *cfg
+	bc.Sources = config.SourcesConfig{Markdown: config.MarkdownSourceConfig{
+		Enabled:    true,
+		Paths:      []string{dir},
+		Extensions: cfg.Sources.Markdown.Extensions,
+		Ignore:     cfg.Sources.Markdown.Ignore,
+	}}
+	bc.Indexing.Throttle = config.ThrottleConfig{}
+	bc.Storage = config.StorageConfig{Path: dataDir}
+	return &bc
+}
+
+// benchmark indexes the configured notes and prints indexing throughput and
+// search latencies. A nil embedder benchmarks BM25 only.
+func benchmark(ctx context.Context, w io.Writer, cfg *config.Config, embedder *timedEmbedder, queries, limit int) error {
+	dbPath := filepath.Join(cfg.Storage.Path, "mindcli.db")
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+	textIndex, err := openSearchBackend(cfg.Search.Backend, cfg.Storage.Path, dbPath)
+	if err != nil {
+		return fmt.Errorf("opening search index: %w", err)
+	}
+	defer func() { _ = textIndex.Close() }()
+
+	var vectors *storage.VectorStore
+	var emb embeddings.Embedder
+	if embedder != nil {
+		vectors, err = storage.NewVectorStore(filepath.Join(cfg.Storage.Path, "vectors.graph"))
+		if err != nil {
+			return fmt.Errorf("opening vector store: %w", err)
+		}
+		defer func() { _ = vectors.Close() }()
+		emb = embedder
+	}
+
+	indexer := index.NewIndexer(db, textIndex, vectors, emb, cfg)
+	start := time.Now()
+	stats, err := indexer.IndexAll(ctx)
+	if err != nil {
+		return fmt.Errorf("indexing: %w", err)
+	}
+	elapsed := time.Since(start)
+	if stats.IndexedFiles == 0 {
+		return fmt.Errorf("no notes found to index")
+	}
+
+	fmt.Fprintf(w, "\nIndexing (%s backend, %d workers):\n", cfg.Search.Backend, cfg.Indexing.Workers)
+	fmt.Fprintf(w, "  Documents:  %d in %s (%.1f docs/sec)\n", stats.IndexedFiles, elapsed.Round(time.Millisecond), perSecond(stats.IndexedFiles, elapsed))
+	if embedder != nil {
+		texts := embedder.texts.Load()
+		fmt.Fprintf(w, "  Embeddings: %d chunks (%.1f embeds/sec, %s in embedding calls)\n",
+			texts, perSecond(texts, elapsed), time.Duration(embedder.nanos.Load()).Round(time.Millisecond))
+	}
+
+	qs, err := benchQueries(ctx, db, queries)
+	if err != nil {
+		return err
+	}
+	// The modes match "exact:" queries, "semantic:" queries and the default.
+	modes := []benchMode{{"bm25", 0}}
+	if embedder != nil {
+		modes = append(modes,
+			benchMode{"vector", 1},
+			benchMode{fmt.Sprintf("hybrid (%.1f)", cfg.Search.HybridWeight), cfg.Search.HybridWeight})
+	}
+
+	h := query.NewHybridSearcher(textIndex, vectors, emb, db, cfg.Search.HybridWeight)
+	fmt.Fprintf(w, "\nSearch latency (%d queries, limit %d):\n", len(qs), limit)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  MODE\tP50\tP95\tP99\tMEAN\tQPS")
+	for _, m := range modes {
+		latencies := make([]time.Duration, 0, len(qs))
+		for _, q := range qs {
+			start := time.Now()
+			if _, err := h.SearchWithWeight(ctx, q, limit, m.weight); err != nil {
+				return fmt.Errorf("searching %q: %w", q, err)
+			}
+			latencies = append(latencies, time.Since(start))
+		}
+		sum := summarizeLatencies(latencies)
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%.1f\n", m.name,
+			formatLatency(sum.p50), formatLatency(sum.p95), formatLatency(sum.p99), formatLatency(sum.mean),
+			perSecond(int64(len(latencies)), sum.total))
+	}
+	return tw.Flush()
+}
+
+// benchMode is a search mode, by hybrid weight.
+type benchMode struct {
+	name   string
+	weight float64
+}
+
+// benchQueries derives n queries from indexed document titles, cycling
+// through them when there are fewer documents than queries.
+func benchQueries(ctx context.Context, db *storage.DB, n int) ([]string, error) {
+	docs, err := db.ListDocumentSummaries(ctx, "", 0, n)
+	if err != nil {
+		return nil, fmt.Errorf("listing documents: %w", err)
+	}
+	var titles []string
+	for _, d := range docs {
+		if t := strings.TrimSpace(d.Title); t != "" {
+			titles = append(titles, t)
+		}
+	}
+	if len(titles) == 0 {
+		return nil, fmt.Errorf("no document titles to build queries from")
+	}
+	qs := make([]string, n)
+	for i := range qs {
+		qs[i] = titles[i%len(titles)]
+	}
+	return qs, nil
+}
+
+// generateBenchCorpus writes n markdown notes of varying length into dir.
+func generateBenchCorpus(dir string, n int, rng *rand.Rand) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating corpus directory: %w", err)
+	}
+	words := func(count int) string {
+		ws := make([]string, count)
+		for i := range ws {
+			ws[i] = benchWords[rng.Intn(len(benchWords))]
+		}
+		return strings.Join(ws, " ")
+	}
+	for i := range n {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "# %s\n\n", words(3))
+		for range 1 + rng.Intn(6) {
+			for range 2 + rng.Intn(5) {
+				s := words(6 + rng.Intn(12))
+				sb.WriteString(strings.ToUpper(s[:1]) + s[1:] + ". ")
+			}
+			sb.WriteString("\n\n")
+		}
+		path := filepath.Join(dir, fmt.Sprintf("note-%05d.md", i))
+		if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+			return fmt.Errorf("writing note: %w", err)
+		}
+	}
+	return nil
+}
+
+// timedEmbedder counts embedded texts and the time spent embedding them.
+type timedEmbedder struct {
+	embeddings.Embedder
+	texts atomic.Int64
+	nanos atomic.Int64
+}
+
+func (e *timedEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	start := time.Now()
+	out, err := e.Embedder.EmbedBatch(ctx, texts)
+	e.nanos.Add(int64(time.Since(start)))
+	if err == nil {
+		e.texts.Add(int64(len(texts)))
+	}
+	return out, err
+}
+
+type latencySummary struct {
+	p50, p95, p99, mean, total time.Duration
+}
+
+// summarizeLatencies returns nearest-rank percentiles of the latencies.
+func summarizeLatencies(latencies []time.Duration) latencySummary {
+	if len(latencies) == 0 {
+		return latencySummary{}
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	pct := func(p float64) time.Duration {
+		i := int(p*float64(len(sorted))+0.999999) - 1
+		return sorted[max(0, min(i, len(sorted)-1))]
+	}
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	return latencySummary{
+		p50:   pct(0.50),
+		p95:   pct(0.95),
+		p99:   pct(0.99),
+		mean:  total / time.Duration(len(sorted)),
+		total: total,
+	}
+}
+
+func formatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return fmt.Sprintf("%dµs", d.Microseconds())
+	}
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+func perSecond(n int64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
+}
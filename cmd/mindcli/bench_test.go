@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+)
+
+// hashEmbedder is a deterministic embedder for benchmarks without a backend.
+type hashEmbedder struct{}
+
+func (hashEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	v := make([]float32, 4)
+	for i, c := range text {
+		v[i%4] += float32(c % 7)
+	}
+	return v, nil
+}
+
+func (e hashEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		out[i], _ = e.Embed(ctx, t)
+	}
+	return out, nil
+}
+
+func (hashEmbedder) Dimensions() int { return 4 }
+
+func TestGenerateBenchCorpus(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "notes")
+	if err := generateBenchCorpus(dir, 5, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatalf("generateBenchCorpus() error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("generated %d notes, want 5", len(entries))
+	}
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "# ") {
+		t.Errorf("note should start with a title heading:\n%s", data)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	tmp := t.TempDir()
+	notes := filepath.Join(tmp, "notes")
+	if err := generateBenchCorpus(notes, 20, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatal(err)
+	}
+	cfg := benchConfig(config.Default(), notes, filepath.Join(tmp, "data"))
+	if err := os.MkdirAll(cfg.Storage.Path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	embedder := &timedEmbedder{Embedder: hashEmbedder{}}
+	if err := benchmark(t.Context(), &sb, cfg, embedder, 5, 3); err != nil {
+		t.Fatalf("benchmark() error = %v", err)
+	}
+	out := sb.String()
+	for _, want := range []string{"Documents:  20", "Embeddings:", "Search latency (5 queries, limit 3)", "bm25", "vector", "hybrid (0.5)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if embedder.texts.Load() == 0 {
+		t.Error("expected chunks to be embedded")
+	}
+}
+
+func TestBenchmarkBM25Only(t *testing.T) {
+	tmp := t.TempDir()
+	notes := filepath.Join(tmp, "notes")
+	if err := generateBenchCorpus(notes, 3, rand.New(rand.NewSource(2))); err != nil {
+		t.Fatal(err)
+	}
+	cfg := benchConfig(config.Default(), notes, filepath.Join(tmp, "data"))
+	if err := os.MkdirAll(cfg.Storage.Path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := benchmark(t.Context(), &sb, cfg, nil, 4, 3); err != nil {
+		t.Fatalf("benchmark() error = %v", err)
+	}
+	if out := sb.String(); strings.Contains(out, "vector") || strings.Contains(out, "Embeddings:") {
+		t.Errorf("BM25-only run should not report vector modes:\n%s", out)
+	}
+}
+
+func TestSummarizeLatencies(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	sum := summarizeLatencies(latencies)
+	if sum.p50 != 50*time.Millisecond || sum.p95 != 95*time.Millisecond || sum.p99 != 99*time.Millisecond {
+		t.Errorf("percentiles = %v/%v/%v, want 50ms/95ms/99ms", sum.p50, sum.p95, sum.p99)
+	}
+	if sum.mean != 50500*time.Microsecond {
+		t.Errorf("mean = %v, want 50.5ms", sum.mean)
+	}
+	if got := summarizeLatencies(nil); got != (latencySummary{}) {
+		t.Errorf("empty summary = %+v, want zero", got)
+	}
+}
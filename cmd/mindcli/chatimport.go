@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/chatimport"
+	"github.com/J-1000/mindcli/internal/highlights"
+	"github.com/J-1000/mindcli/internal/readlater"
+)
+
+func runImport(args []string) error {
+	if len(args) > 0 && slices.Contains(readlater.Formats, args[0]) {
+		return runImportArticles(args[0], args[1:])
+	}
+	if len(args) > 0 && slices.Contains(highlights.Formats, args[0]) {
+		return runImportHighlights(args[0], args[1:])
+	}
+	if len(args) == 0 || args[0] != "chat" {
+		return fmt.Errorf("usage: mindcli import chat --format whatsapp|telegram|slack [--per-day] [--dir folder] <export>\n       mindcli import pocket|instapaper|omnivore [--dir folder] [--queue collection] <export>\n       mindcli import kindle|readwise [--dir folder] <export>")
+	}
+	return runImportChat(args[1:])
+}
+
+// runImportChat converts a chat export into markdown notes in the notes
+// folder and indexes them, so discussions are searchable like notes.
+func runImportChat(args []string) error {
+	fs := flag.NewFlagSet("import chat", flag.ExitOnError)
+	format := fs.String("format", "", "Export format: "+strings.Join(chatimport.Formats, ", "))
+	perDay := fs.Bool("per-day", false, "Write one note per chat and day instead of one per chat")
+	dir := fs.String("dir", "", "Folder to write the notes to (default: chats/<format> in the notes folder)")
+	_ = fs.Parse(args)
+
+	if *format == "" || fs.NArg() != 1 {
+		return fmt.Errorf("usage: mindcli import chat --format whatsapp|telegram|slack [--per-day] [--dir folder] <export>")
+	}
+	convs, err := chatimport.Parse(*format, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	s, err := openStores(openOpts{vectors: true, embedder: true, indexing: true})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	outDir := *dir
+	if outDir == "" {
+		notes, err := transcriptNotesDir(s.cfg)
+		if err != nil {
+			return err
+		}
+		outDir = filepath.Join(notes, "chats", strings.ToLower(*format))
+	}
+	paths, err := writeChatNotes(outDir, convs, *perDay)
+	if err != nil {
+		return err
+	}
+
+	indexer := s.newIndexer(s.vectors)
+	ctx := context.Background()
+	failed := 0
+	for _, path := range paths {
+		if err := indexer.IndexFile(ctx, path); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: indexing %s: %v\n", path, err)
+			failed++
+		}
+	}
+	if err := indexer.SaveVectors(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: saving vectors: %v\n", err)
+	}
+
+	fmt.Printf("Imported %d chats into %d notes in %s\n", len(convs), len(paths), outDir)
+	if failed > 0 {
+		fmt.Printf("%d notes could not be indexed; is %s inside a markdown notes folder?\n", failed, outDir)
+	}
+	return nil
+}
+
+// writeChatNotes writes the conversations as markdown notes in dir, one per
+// chat or one per chat and day, and returns their paths. Notes from an
+// earlier import of the same chats are overwritten.
+func writeChatNotes(dir string, convs []chatimport.Conversation, perDay bool) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating notes folder: %w", err)
+	}
+	var paths []string
+	written := make(map[string]bool)
+	for _, conv := range convs {
+		notes := []chatimport.Conversation{conv}
+		if perDay {
+			notes = conv.SplitByDay()
+		}
+		for _, note := range notes {
+			name := note.FileName(perDay)
+			// Chats whose names differ only in punctuation share a slug.
+			stem := strings.TrimSuffix(name, ".md")
+			for i := 2; written[name]; i++ {
+				name = fmt.Sprintf("%s-%d.md", stem, i)
+			}
+			written[name] = true
+
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(note.Markdown(perDay)), 0o644); err != nil {
+				return paths, fmt.Errorf("writing chat note: %w", err)
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
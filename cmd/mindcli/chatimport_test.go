@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/chatimport"
+	"github.com/J-1000/mindcli/internal/index/sources"
+)
+
+func TestWriteChatNotes(t *testing.T) {
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	convs := []chatimport.Conversation{
+		{Platform: "Slack", Name: "eng", Messages: []chatimport.Message{
+			{Time: day, Sender: "ann", Text: "Deploy at noon"},
+			{Time: day.Add(24 * time.Hour), Sender: "ben", Text: "Rolled back"},
+		}},
+		{Platform: "Slack", Name: "eng!", Messages: []chatimport.Message{
+			{Time: day, Sender: "ann", Text: "Wrong channel"},
+		}},
+	}
+	dir := filepath.Join(t.TempDir(), "chats")
+
+	paths, err := writeChatNotes(dir, convs, false)
+	if err != nil {
+		t.Fatalf("writeChatNotes() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "eng.md"), filepath.Join(dir, "eng-2.md")}
+	if len(paths) != 2 || paths[0] != want[0] || paths[1] != want[1] {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+
+	// The notes are indexed as markdown with the chat details as metadata.
+	info, err := os.Stat(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := sources.NewMarkdownSource([]string{dir}, []string{".md"}, nil)
+	doc, err := src.Parse(context.Background(), sources.FileInfo{Path: paths[0], ModifiedAt: info.ModTime().Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Slack: eng" || doc.Metadata["fm_participants"] != "ann, ben" || !doc.DocumentDate.Equal(day) {
+		t.Errorf("note = title %q participants %q date %v", doc.Title, doc.Metadata["fm_participants"], doc.DocumentDate)
+	}
+
+	// Per day, and importing again overwrites rather than duplicating.
+	for range 2 {
+		if paths, err = writeChatNotes(dir, convs[:1], true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(paths) != 2 || filepath.Base(paths[1]) != "eng-2024-03-02.md" {
+		t.Errorf("per-day paths = %v", paths)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 4 {
+		t.Errorf("%d files in the chats folder, want 4", len(entries))
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/J-1000/mindcli/internal/embeddings"
+)
+
+// dataPart is a part of the data directory whose disk usage compact
+// reports, made of one or more files or directories.
+type dataPart struct {
+	label string
+	paths []string
+}
+
+// sqliteFiles returns a SQLite database's file with its write-ahead log and
+// shared-memory files.
+func sqliteFiles(path string) []string {
+	return []string{path, path + "-wal", path + "-shm"}
+}
+
+// usage returns the bytes the part takes on disk.
+func (p dataPart) usage() int64 {
+	var size int64
+	for _, path := range p.paths {
+		n, _ := pathSize(path)
+		size += n
+	}
+	return size
+}
+
+func runCompact() error {
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	dbPath, err := s.cfg.DatabasePath()
+	if err != nil {
+		return fmt.Errorf("getting database path: %w", err)
+	}
+	cachePath := filepath.Join(s.dataDir, "embeddings.db")
+	parts := []dataPart{
+		{"database", sqliteFiles(dbPath)},
+		{"search index", []string{filepath.Join(s.dataDir, "search.bleve")}},
+		{"embedding cache", sqliteFiles(cachePath)},
+	}
+	before := make([]int64, len(parts))
+	for i, p := range parts {
+		before[i] = p.usage()
+	}
+
+	ctx := context.Background()
+	if err := s.search.Compact(ctx); err != nil {
+		return fmt.Errorf("compacting search index: %w", err)
+	}
+	pruned := 0
+	if _, err := os.Stat(cachePath); err == nil {
+		chunks, err := s.db.ListChunkContents(ctx)
+		if err != nil {
+			return err
+		}
+		pruned, err = embeddings.PruneCache(ctx, cachePath, embeddingModels(s), chunks)
+		if err != nil {
+			return fmt.Errorf("pruning embedding cache: %w", err)
+		}
+	}
+	if err := s.db.Vacuum(ctx); err != nil {
+		return err
+	}
+	// The search index lets go of merged segments once closed.
+	_ = s.search.Close()
+	s.search = nil
+
+	after := make([]int64, len(parts))
+	for i, p := range parts {
+		after[i] = p.usage()
+	}
+	printCompaction(os.Stdout, parts, before, after, pruned)
+	return nil
+}
+
+// embeddingModels returns every model chunks may have been embedded with.
+func embeddingModels(s *stores) []string {
+	models := []string{s.cfg.Embeddings.Model}
+	for _, model := range s.cfg.Embeddings.LanguageModels {
+		models = append(models, model)
+	}
+	return models
+}
+
+// printCompaction writes the disk usage of each part before and after
+// compacting, skipping parts that take no space, and the total saved.
+func printCompaction(w io.Writer, parts []dataPart, before, after []int64, pruned int) {
+	fmt.Fprintln(w, "Compacted the data directory:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	var totalBefore, totalAfter int64
+	for i, p := range parts {
+		totalBefore += before[i]
+		totalAfter += after[i]
+		if before[i] == 0 && after[i] == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t-> %s\n", p.label, humanSize(before[i]), humanSize(after[i]))
+	}
+	fmt.Fprintf(tw, "  total\t%s\t-> %s\n", humanSize(totalBefore), humanSize(totalAfter))
+	_ = tw.Flush()
+	if saved := totalBefore - totalAfter; saved > 0 {
+		fmt.Fprintf(w, "Saved %s.\n", humanSize(saved))
+	}
+	if pruned > 0 {
+		fmt.Fprintf(w, "Pruned %d cached %s no chunk uses any more.\n", pruned, plural(pruned, "embedding", "embeddings"))
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDataPartUsage(t *testing.T) {
+	dir := t.TempDir()
+	db := filepath.Join(dir, "mindcli.db")
+	if err := os.WriteFile(db, make([]byte, 100), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(db+"-wal", make([]byte, 20), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	index := filepath.Join(dir, "search.bleve", "store")
+	if err := os.MkdirAll(index, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(index, "a.zap"), make([]byte, 7), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		part dataPart
+		want int64
+	}{
+		{dataPart{"database", sqliteFiles(db)}, 120},
+		{dataPart{"search index", []string{filepath.Join(dir, "search.bleve")}}, 7},
+		{dataPart{"embedding cache", sqliteFiles(filepath.Join(dir, "embeddings.db"))}, 0},
+	} {
+		if got := tt.part.usage(); got != tt.want {
+			t.Errorf("%s usage = %d, want %d", tt.part.label, got, tt.want)
+		}
+	}
+}
+
+func TestPrintCompaction(t *testing.T) {
+	parts := []dataPart{{label: "database"}, {label: "search index"}, {label: "embedding cache"}}
+	var out bytes.Buffer
+	printCompaction(&out, parts, []int64{3 << 20, 0, 2048}, []int64{1 << 20, 0, 1024}, 12)
+
+	got := out.String()
+	for _, want := range []string{"database         3.0 MB  -> 1.0 MB", "embedding cache  2.0 KB  -> 1.0 KB",
+		"total            3.0 MB  -> 1.0 MB", "Saved 2.0 MB.", "Pruned 12 cached embeddings"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "search index") {
+		t.Errorf("output lists a part that takes no space:\n%s", got)
+	}
+
+	out.Reset()
+	printCompaction(&out, parts[:1], []int64{100}, []int64{100}, 0)
+	if got := out.String(); strings.Contains(got, "Saved") || strings.Contains(got, "Pruned") {
+		t.Errorf("output reports savings when there were none:\n%s", got)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/index"
+)
+
+// confirmNewPaths estimates what a first index of the directories nothing
+// was indexed from yet would add and, when that is more than the limits
+// allow, asks on in whether to go on. Without a terminal to ask on it
+// returns an error instead. It reports whether indexing should go ahead.
+func confirmNewPaths(ctx context.Context, indexer *index.Indexer, limits config.ConfirmConfig, in io.Reader, out io.Writer, interactive bool) (bool, error) {
+	if limits.Files == 0 && limits.SizeMB == 0 {
+		return true, nil
+	}
+	estimates, err := indexer.EstimateNewPaths(ctx)
+	if err != nil {
+		return false, fmt.Errorf("estimating new paths: %w", err)
+	}
+	var files int
+	var bytes int64
+	for _, e := range estimates {
+		files += e.Files
+		bytes += e.Bytes
+	}
+	overFiles := limits.Files > 0 && files > limits.Files
+	overSize := limits.SizeMB > 0 && bytes > int64(limits.SizeMB)<<20
+	if !overFiles && !overSize {
+		return true, nil
+	}
+
+	fmt.Fprintf(out, "Indexing would add ~%s %s, ~%s, from %s not indexed before:\n",
+		approxCount(files), plural(files, "file", "files"), humanSize(bytes), plural(len(estimates), "a directory", "directories"))
+	for _, e := range estimates {
+		fmt.Fprintf(out, "  %s (%s): %s %s, %s\n", e.Path, e.Source, approxCount(e.Files), plural(e.Files, "file", "files"), humanSize(e.Bytes))
+	}
+	if !interactive {
+		return false, fmt.Errorf("new directories exceed indexing.confirm; run with --yes to index them anyway")
+	}
+	fmt.Fprint(out, "Continue? [y/N] ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	fmt.Fprintln(out, "Indexing cancelled. Adjust the source paths or ignore lists, or raise indexing.confirm.")
+	return false, nil
+}
+
+// approxCount rounds n for an estimate: 950, 12k, 1.2M.
+func approxCount(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprint(n)
+	case n < 1000000:
+		return fmt.Sprintf("%dk", (n+500)/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	}
+}
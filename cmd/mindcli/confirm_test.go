@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/index"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestConfirmNewPaths(t *testing.T) {
+	dir := t.TempDir()
+	downloads := filepath.Join(dir, "downloads")
+	if err := os.MkdirAll(downloads, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		if err := os.WriteFile(filepath.Join(downloads, name), []byte("# note\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db, err := storage.Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	cfg := &config.Config{Indexing: config.IndexingConfig{Workers: 1}}
+	cfg.Sources.Markdown = config.MarkdownSourceConfig{Enabled: true, Paths: []string{downloads}, Extensions: []string{".md"}}
+	indexer := index.NewIndexer(db, nil, nil, nil, cfg)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		limits      config.ConfirmConfig
+		answer      string
+		interactive bool
+		wantOK      bool
+		wantErr     bool
+		wantOut     string
+	}{
+		{name: "under the limit", limits: config.ConfirmConfig{Files: 3}, wantOK: true},
+		{name: "no limits", wantOK: true},
+		{name: "confirmed", limits: config.ConfirmConfig{Files: 2}, answer: "y\n", interactive: true, wantOK: true,
+			wantOut: "Indexing would add ~3 files, ~21 B, from a directory not indexed before:\n  " + downloads + " (markdown): 3 files, 21 B\nContinue? [y/N] "},
+		{name: "declined", limits: config.ConfirmConfig{Files: 2}, answer: "\n", interactive: true, wantOut: "Indexing cancelled."},
+		{name: "not a terminal", limits: config.ConfirmConfig{Files: 2}, wantErr: true, wantOut: downloads},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			ok, err := confirmNewPaths(ctx, indexer, tt.limits, strings.NewReader(tt.answer), &out, tt.interactive)
+			if ok != tt.wantOK || (err != nil) != tt.wantErr {
+				t.Errorf("confirmNewPaths() = %v, %v; want %v, error %v", ok, err, tt.wantOK, tt.wantErr)
+			}
+			if tt.wantOut == "" && out.Len() > 0 {
+				t.Errorf("output = %q, want none", out.String())
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("output = %q, want it to contain %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestApproxCount(t *testing.T) {
+	for n, want := range map[int]string{950: "950", 12345: "12k", 119600: "120k", 1234567: "1.2M"} {
+		if got := approxCount(n); got != want {
+			t.Errorf("approxCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// runContacts lists the people you email most, from the senders and
+// recipients of indexed email.
+func runContacts(args []string) error {
+	fs := flag.NewFlagSet("contacts", flag.ExitOnError)
+	limit := fs.Int("limit", 20, "Maximum number of contacts (0 = all)")
+	_ = fs.Parse(args)
+
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	contacts, err := s.db.ListContacts(context.Background(), *limit)
+	if err != nil {
+		return err
+	}
+	printContacts(os.Stdout, contacts, buildRedactor(s.cfg))
+	return nil
+}
+
+// printContacts prints one contact per line with their message counts.
+func printContacts(w io.Writer, contacts []storage.Contact, redactor privacy.Redactor) {
+	if len(contacts) == 0 {
+		fmt.Fprintln(w, "No contacts. Index email (sources.email) to see who you correspond with.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MESSAGES\tSENT\tRECEIVED\tCONTACT")
+	for _, c := range contacts {
+		label := c.Address
+		if c.Name != "" {
+			label = fmt.Sprintf("%s <%s>", c.Name, c.Address)
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\n", c.Messages, c.Sent, c.Received, redactor.Redact(label))
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestPrintContacts(t *testing.T) {
+	var buf bytes.Buffer
+	printContacts(&buf, []storage.Contact{
+		{Address: "alice@example.com", Name: "Alice Smith", Messages: 12, Sent: 8, Received: 4},
+		{Address: "ops@example.com", Messages: 3, Received: 3},
+	}, privacy.Redactor{})
+	want := "MESSAGES  SENT  RECEIVED  CONTACT\n" +
+		"12        8     4         Alice Smith <alice@example.com>\n" +
+		"3         0     3         ops@example.com\n"
+	if buf.String() != want {
+		t.Errorf("printContacts() output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	printContacts(&buf, nil, privacy.Redactor{})
+	if buf.Len() == 0 {
+		t.Error("printContacts() printed nothing for no contacts")
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/J-1000/mindcli/internal/devicesync"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// curationVersion is the version of the tag and collection export format.
+const curationVersion = 1
+
+// docRef identifies a document portably: by path, with the home directory
+// written as ~, and by content hash for when the file has moved.
+type docRef struct {
+	Path        string `json:"path"`
+	ContentHash string `json:"content_hash,omitempty"`
+}
+
+type taggedDoc struct {
+	docRef
+	Tags []string `json:"tags"`
+}
+
+type tagExport struct {
+	Version   int         `json:"version"`
+	Documents []taggedDoc `json:"documents"`
+}
+
+type exportedCollection struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Query       string   `json:"query,omitempty"`
+	Documents   []docRef `json:"documents"`
+}
+
+type collectionExport struct {
+	Version     int                  `json:"version"`
+	Collections []exportedCollection `json:"collections"`
+}
+
+// importResult counts what an import did. Missing lists documents that
+// aren't indexed on this machine.
+type importResult struct {
+	Applied int
+	Missing []string
+}
+
+func newDocRef(d storage.DocumentRef) docRef {
+	return docRef{Path: devicesync.PortablePath(d.Path), ContentHash: d.ContentHash}
+}
+
+// exportTags writes every manual tag, grouped by document.
+func exportTags(ctx context.Context, db *storage.DB, w io.Writer) error {
+	tags, err := db.ListManualTags(ctx)
+	if err != nil {
+		return err
+	}
+	out := tagExport{Version: curationVersion, Documents: []taggedDoc{}}
+	for _, t := range tags {
+		ref := newDocRef(t.Document)
+		if n := len(out.Documents); n > 0 && out.Documents[n-1].docRef == ref {
+			out.Documents[n-1].Tags = append(out.Documents[n-1].Tags, t.Tag)
+			continue
+		}
+		out.Documents = append(out.Documents, taggedDoc{docRef: ref, Tags: []string{t.Tag}})
+	}
+	return writeCuration(w, out)
+}
+
+// importTags adds the tags in r to the matching local documents.
+func importTags(ctx context.Context, db *storage.DB, r io.Reader) (*importResult, error) {
+	var in tagExport
+	if err := readCuration(r, &in, &in.Version); err != nil {
+		return nil, err
+	}
+	res := &importResult{}
+	for _, d := range in.Documents {
+		doc, err := devicesync.ResolveDocument(ctx, db, d.Path, d.ContentHash)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			res.Missing = append(res.Missing, d.Path)
+			continue
+		}
+		for _, tag := range d.Tags {
+			if err := db.AddTag(ctx, doc.ID, tag); err != nil {
+				return nil, fmt.Errorf("adding tag: %w", err)
+			}
+			res.Applied++
+		}
+	}
+	return res, nil
+}
+
+// exportCollections writes every collection with its members.
+func exportCollections(ctx context.Context, db *storage.DB, w io.Writer) error {
+	cols, err := db.ListCollections(ctx)
+	if err != nil {
+		return err
+	}
+	members, err := db.ListCollectionMembers(ctx)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string][]docRef)
+	for _, m := range members {
+		byName[m.Collection] = append(byName[m.Collection], newDocRef(m.Document))
+	}
+
+	out := collectionExport{Version: curationVersion, Collections: []exportedCollection{}}
+	for _, c := range cols {
+		docs := byName[c.Name]
+		if docs == nil {
+			docs = []docRef{}
+		}
+		out.Collections = append(out.Collections, exportedCollection{
+			Name: c.Name, Description: c.Description, Query: c.Query, Documents: docs,
+		})
+	}
+	return writeCuration(w, out)
+}
+
+// importCollections creates missing collections and adds their members.
+// Existing collections keep their description and query unless unset.
+func importCollections(ctx context.Context, db *storage.DB, r io.Reader) (*importResult, error) {
+	var in collectionExport
+	if err := readCuration(r, &in, &in.Version); err != nil {
+		return nil, err
+	}
+	res := &importResult{}
+	for _, c := range in.Collections {
+		col, err := db.GetCollectionByName(ctx, c.Name)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			col = &storage.Collection{Name: c.Name, Description: c.Description, Query: c.Query}
+			if err := db.CreateCollection(ctx, col); err != nil {
+				return nil, fmt.Errorf("creating collection: %w", err)
+			}
+		case err != nil:
+			return nil, err
+		default:
+			if col.Description == "" && c.Description != "" {
+				if err := db.UpdateCollectionDescription(ctx, col.ID, c.Description); err != nil {
+					return nil, err
+				}
+			}
+			if col.Query == "" && c.Query != "" {
+				if err := db.UpdateCollectionQuery(ctx, col.ID, c.Query); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		for _, d := range c.Documents {
+			doc, err := devicesync.ResolveDocument(ctx, db, d.Path, d.ContentHash)
+			if err != nil {
+				return nil, err
+			}
+			if doc == nil {
+				res.Missing = append(res.Missing, d.Path)
+				continue
+			}
+			if err := db.AddToCollection(ctx, col.ID, doc.ID); err != nil {
+				return nil, fmt.Errorf("adding to collection: %w", err)
+			}
+			res.Applied++
+		}
+	}
+	return res, nil
+}
+
+func writeCuration(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func readCuration(r io.Reader, v any, version *int) error {
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("parsing import file: %w", err)
+	}
+	if *version != curationVersion {
+		return fmt.Errorf("unsupported import file version %d", *version)
+	}
+	return nil
+}
+
+// runCurationExport handles "tag export" and "collection export".
+func runCurationExport(ctx context.Context, db *storage.DB, name string, args []string, export func(context.Context, *storage.DB, io.Writer) error) error {
+	fs := flag.NewFlagSet(name+"-export", flag.ExitOnError)
+	output := fs.String("output", "", "Output file (default: stdout)")
+	_ = fs.Parse(args)
+
+	if *output == "" {
+		return export(ctx, db, os.Stdout)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	if err := export(ctx, db, f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing output file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %ss to %s\n", name, *output)
+	return nil
+}
+
+// runCurationImport handles "tag import" and "collection import". A path of
+// "-" reads standard input.
+func runCurationImport(ctx context.Context, db *storage.DB, name string, args []string, imp func(context.Context, *storage.DB, io.Reader) (*importResult, error)) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: mindcli %s import <file>", name)
+	}
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("opening import file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	res, err := imp(ctx, db, r)
+	if err != nil {
+		return err
+	}
+	noun := "tag(s)"
+	if name == "collection" {
+		noun = "collection membership(s)"
+	}
+	fmt.Printf("Imported %d %s.\n", res.Applied, noun)
+	if len(res.Missing) > 0 {
+		fmt.Printf("Skipped %d document(s) not indexed on this machine:\n", len(res.Missing))
+		for _, p := range res.Missing {
+			fmt.Printf("  %s\n", p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func openCurationDB(t *testing.T, docs map[string]string) *storage.DB {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "mindcli.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	now := time.Now()
+	for path, hash := range docs {
+		doc := &storage.Document{ID: hash, Source: storage.SourceMarkdown, Path: path, Title: filepath.Base(path), ContentHash: hash, IndexedAt: now, ModifiedAt: now}
+		if err := db.InsertDocument(context.Background(), doc); err != nil {
+			t.Fatalf("InsertDocument() error = %v", err)
+		}
+	}
+	return db
+}
+
+func TestTagExportImport(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	ctx := context.Background()
+
+	src := openCurationDB(t, map[string]string{
+		filepath.Join(home, "notes", "a.md"): "ha",
+		filepath.Join(home, "notes", "b.md"): "hb",
+	})
+	for _, tag := range []string{"go", "work"} {
+		if err := src.AddTag(ctx, "ha", tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := src.AddTag(ctx, "hb", "later"); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.AddAutoTag(ctx, "hb", "auto"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := exportTags(ctx, src, &buf); err != nil {
+		t.Fatalf("exportTags() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"path": "~/notes/a.md"`) {
+		t.Errorf("export should use portable paths:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "auto") {
+		t.Errorf("export should skip automatic tags:\n%s", buf.String())
+	}
+
+	// a.md moved to another directory; b.md isn't indexed at all.
+	dst := openCurationDB(t, map[string]string{"/moved/a.md": "ha"})
+	res, err := importTags(ctx, dst, &buf)
+	if err != nil {
+		t.Fatalf("importTags() error = %v", err)
+	}
+	if res.Applied != 2 || len(res.Missing) != 1 || res.Missing[0] != "~/notes/b.md" {
+		t.Errorf("importTags() = %+v, want 2 applied and b.md missing", res)
+	}
+	tags, _ := dst.GetTags(ctx, "ha")
+	if strings.Join(tags, ",") != "go,work" {
+		t.Errorf("imported tags = %v, want [go work]", tags)
+	}
+}
+
+func TestCollectionExportImport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+
+	src := openCurationDB(t, map[string]string{"/notes/a.md": "ha"})
+	col := &storage.Collection{Name: "reading", Description: "to read", Query: "tag:go"}
+	if err := src.CreateCollection(ctx, col); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.AddToCollection(ctx, col.ID, "ha"); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.CreateCollection(ctx, &storage.Collection{Name: "empty"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := exportCollections(ctx, src, &buf); err != nil {
+		t.Fatalf("exportCollections() error = %v", err)
+	}
+
+	dst := openCurationDB(t, map[string]string{"/notes/a.md": "ha"})
+	res, err := importCollections(ctx, dst, &buf)
+	if err != nil {
+		t.Fatalf("importCollections() error = %v", err)
+	}
+	if res.Applied != 1 || len(res.Missing) != 0 {
+		t.Errorf("importCollections() = %+v, want 1 applied", res)
+	}
+	got, err := dst.GetCollectionByName(ctx, "reading")
+	if err != nil {
+		t.Fatalf("imported collection missing: %v", err)
+	}
+	if got.Description != "to read" || got.Query != "tag:go" {
+		t.Errorf("imported collection = %+v, want description and query", got)
+	}
+	if n, _ := dst.CountCollectionDocuments(ctx, got.ID); n != 1 {
+		t.Errorf("imported collection has %d documents, want 1", n)
+	}
+	if _, err := dst.GetCollectionByName(ctx, "empty"); err != nil {
+		t.Errorf("empty collection not imported: %v", err)
+	}
+}
+
+func TestImportRejectsUnknownVersion(t *testing.T) {
+	db := openCurationDB(t, nil)
+	_, err := importTags(context.Background(), db, strings.NewReader(`{"version": 2, "documents": []}`))
+	if err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Errorf("importTags() error = %v, want unsupported version", err)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/notify"
+	"github.com/J-1000/mindcli/internal/query"
+)
+
+// runDigest lists the configured digests, prints one, or delivers it now
+// with --send.
+func runDigest(args []string) error {
+	fs := flag.NewFlagSet("digest", flag.ExitOnError)
+	send := fs.Bool("send", false, "Deliver the digest now instead of printing it")
+	since := fs.String("since", "", "Cover this period instead of the query's, e.g. \"past 3 days\" or \"since 2024-01-01\"")
+	_ = fs.Parse(args)
+	if *since != "" {
+		if err := new(query.ParsedQuery).SetTimeFilter(*since, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	digests, err := configuredDigests(cfg)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		printDigests(os.Stdout, cfg.Notifications.Digests)
+		return nil
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("usage: mindcli digest [--send] [--since period] [name]")
+	}
+	var digest *notify.Digest
+	for i := range digests {
+		if digests[i].Name == fs.Arg(0) {
+			digest = &digests[i]
+		}
+	}
+	if digest == nil {
+		return fmt.Errorf("no digest named %q in notifications.digests", fs.Arg(0))
+	}
+
+	s, err := openStores(openOpts{vectors: true, embedder: true, hybrid: true})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	if *send {
+		sent, err := sendDigest(ctx, s, *digest, *since)
+		if err != nil {
+			return err
+		}
+		if !sent {
+			fmt.Println("Nothing to send: the query has no results.")
+		}
+		return nil
+	}
+	body, _, err := renderDigest(ctx, s, *digest, *since)
+	if err != nil {
+		return err
+	}
+	fmt.Print(body)
+	return nil
+}
+
+// printDigests lists the configured digests and when they run.
+func printDigests(w io.Writer, digests []config.DigestConfig) {
+	if len(digests) == 0 {
+		fmt.Fprintln(w, "No digests configured (see notifications.digests in the config file).")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, d := range digests {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", d.Name, d.Schedule, d.Query)
+	}
+	_ = tw.Flush()
+}
+
+// configuredDigests parses the digests in cfg.Notifications.
+func configuredDigests(cfg *config.Config) ([]notify.Digest, error) {
+	digests := make([]notify.Digest, 0, len(cfg.Notifications.Digests))
+	for _, d := range cfg.Notifications.Digests {
+		sched, err := notify.ParseSchedule(d.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("notifications.digests %q: %w", d.Name, err)
+		}
+		digests = append(digests, notify.Digest{
+			Name:     d.Name,
+			Query:    d.Query,
+			Schedule: sched,
+			Limit:    resultsLimit(cfg, d.Limit),
+			Command:  d.Command,
+			Webhook:  d.Webhook,
+
+			OnThisDay: d.OnThisDay,
+		})
+	}
+	return digests, nil
+}
+
+// renderDigest runs a digest's query and renders the results as markdown,
+// followed by the documents from this day in earlier years when the digest
+// asks for them, redacted with the privacy patterns since it is about to
+// leave the index. since, a time expression, replaces the period the query
+// covers.
+func renderDigest(ctx context.Context, s *stores, d notify.Digest, since string) (string, int, error) {
+	now := time.Now()
+	parsed := query.ParseQuery(d.Query)
+	if since != "" {
+		if err := parsed.SetTimeFilter(since, now); err != nil {
+			return "", 0, err
+		}
+	}
+	results, err := searchResults(ctx, s, parsed, d.Limit)
+	if err != nil {
+		return "", 0, fmt.Errorf("searching: %w", err)
+	}
+	period := ""
+	if start, end, ok := parsed.TimeRange(now); ok {
+		period = describePeriod(start, end)
+	}
+	body := notify.Render(d.Name, d.Query, period, results, now, s.cfg.Display.SnippetLength)
+	n := len(results)
+	if d.OnThisDay {
+		earlier, err := s.db.OnThisDay(ctx, now, d.Limit)
+		if err != nil {
+			return "", 0, err
+		}
+		body += notify.RenderOnThisDay(earlier, s.cfg.Display.SnippetLength)
+		n += len(earlier)
+	}
+	return buildRedactor(s.cfg).Redact(body), n, nil
+}
+
+// describePeriod formats a time range by its days, e.g. "8 June 2026 – 15
+// June 2026", or "until 31 January 2024" when it has no start.
+func describePeriod(start, end time.Time) string {
+	const layout = "2 January 2006"
+	if start.IsZero() {
+		return "until " + end.Format(layout)
+	}
+	return start.Format(layout) + " – " + end.Format(layout)
+}
+
+// sendDigest renders and delivers a digest. A digest whose query has no
+// results isn't sent. since, when set, replaces the period the query covers.
+func sendDigest(ctx context.Context, s *stores, d notify.Digest, since string) (bool, error) {
+	body, n, err := renderDigest(ctx, s, d, since)
+	if err != nil || n == 0 {
+		return false, err
+	}
+	if err := notify.Deliver(ctx, d, body); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// startDigests delivers the configured digests on their schedules until the
+// returned stop function is called.
+func startDigests(s *stores, digests []notify.Digest) (stop func()) {
+	if len(digests) == 0 {
+		return func() {}
+	}
+	fmt.Printf("Sending %d digest(s):\n", len(digests))
+	for _, d := range s.cfg.Notifications.Digests {
+		fmt.Printf("  %s (%s)\n", d.Name, d.Schedule)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		notify.Run(ctx, digests, func(ctx context.Context, d notify.Digest) error {
+			_, err := sendDigest(ctx, s, d, "")
+			return err
+		}, func(d notify.Digest, err error) {
+			fmt.Fprintf(os.Stderr, "digest %s: %v\n", d.Name, err)
+		})
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+)
+
+func TestConfiguredDigests(t *testing.T) {
+	cfg := config.Default()
+	cfg.Notifications.Digests = []config.DigestConfig{
+		{Name: "inbox", Query: "tag:inbox", Schedule: "daily 08:00", Command: "cat", OnThisDay: true},
+		{Name: "reading", Query: "in:pdf", Schedule: "hourly", Limit: 5, Webhook: "http://localhost/hook"},
+	}
+	digests, err := configuredDigests(cfg)
+	if err != nil {
+		t.Fatalf("configuredDigests() error = %v", err)
+	}
+	if len(digests) != 2 || digests[0].Limit != cfg.Search.ResultsLimit || digests[1].Limit != 5 {
+		t.Errorf("digests = %+v, want limits defaulting to search.results_limit", digests)
+	}
+	if !digests[0].OnThisDay || digests[1].OnThisDay {
+		t.Errorf("digests = %+v, want on_this_day carried over", digests)
+	}
+
+	cfg.Notifications.Digests[1].Schedule = "fortnightly"
+	if _, err := configuredDigests(cfg); err == nil || !strings.Contains(err.Error(), `"reading"`) {
+		t.Errorf("configuredDigests() error = %v, want the digest's name", err)
+	}
+}
+
+func TestPrintDigests(t *testing.T) {
+	var buf bytes.Buffer
+	printDigests(&buf, nil)
+	if !strings.Contains(buf.String(), "No digests configured") {
+		t.Errorf("printDigests(nil) = %q", buf.String())
+	}
+	buf.Reset()
+	printDigests(&buf, []config.DigestConfig{
+		{Name: "inbox", Schedule: "daily 08:00", Query: "tag:inbox"},
+		{Name: "reading-list", Schedule: "hourly", Query: "in:pdf"},
+	})
+	want := "inbox         daily 08:00  tag:inbox\nreading-list  hourly       in:pdf\n"
+	if buf.String() != want {
+		t.Errorf("printDigests() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestDescribePeriod(t *testing.T) {
+	start := time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	if got, want := describePeriod(start, end), "8 June 2026 – 15 June 2026"; got != want {
+		t.Errorf("describePeriod() = %q, want %q", got, want)
+	}
+	if got, want := describePeriod(time.Time{}, end), "until 15 June 2026"; got != want {
+		t.Errorf("describePeriod() without a start = %q, want %q", got, want)
+	}
+}
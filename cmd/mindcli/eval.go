@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/query"
+)
+
+// runEval sweeps hybrid weights against labeled queries and recommends the
+// one that ranks the relevant documents best.
+func runEval(args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	qrelsPath := fs.String("qrels", "", "Labeled queries: one \"query<TAB>path\" pair per line")
+	k := fs.Int("k", 10, "Number of results scored per query")
+	_ = fs.Parse(args)
+
+	if *qrelsPath == "" || *k < 1 {
+		return fmt.Errorf("usage: mindcli eval --qrels file [--k N]")
+	}
+	f, err := os.Open(*qrelsPath)
+	if err != nil {
+		return fmt.Errorf("opening qrels: %w", err)
+	}
+	qrels, err := query.ParseQrels(f)
+	_ = f.Close()
+	if err != nil {
+		return err
+	}
+	home, _ := os.UserHomeDir()
+	for i := range qrels {
+		for j, p := range qrels[i].Relevant {
+			qrels[i].Relevant[j] = expandHome(p, home)
+		}
+	}
+
+	s, err := openStores(openOpts{vectors: true, embedder: true, hybrid: true})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if s.hybrid == nil {
+		return errors.New("eval compares BM25 with vector search: run 'mindcli index' with Ollama/OpenAI available first")
+	}
+
+	scores, err := s.hybrid.EvaluateWeights(context.Background(), qrels, query.DefaultEvalWeights, *k)
+	if err != nil {
+		return err
+	}
+	printWeightScores(os.Stdout, scores, len(qrels), *k, s.cfg.Search.HybridWeight)
+	return nil
+}
+
+func printWeightScores(w io.Writer, scores []query.WeightScore, queries, k int, current float64) {
+	best := query.BestWeight(scores, current)
+	fmt.Fprintf(w, "Evaluated %d quer%s at k=%d:\n\n", queries, plural(queries, "y", "ies"), k)
+	fmt.Fprintf(w, "  weight  nDCG@%-3d MRR     recall@%d\n", k, k)
+	for _, sc := range scores {
+		marker := ""
+		switch {
+		case sc.Weight == best.Weight:
+			marker = "  <- best"
+		case sc.Weight == current:
+			marker = "  (current)"
+		}
+		fmt.Fprintf(w, "  %-6.1f  %-7.3f  %-6.3f  %.3f%s\n", sc.Weight, sc.NDCG, sc.MRR, sc.Recall, marker)
+	}
+	fmt.Fprintln(w)
+	if best.Weight == current {
+		fmt.Fprintf(w, "The current search.hybrid_weight (%.1f) is already the best setting.\n", current)
+		return
+	}
+	fmt.Fprintf(w, "Recommended: search.hybrid_weight: %.1f (currently %.1f)\n", best.Weight, current)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// expandHome expands a leading ~ in a labeled path.
+func expandHome(path, home string) string {
+	if home == "" {
+		return path
+	}
+	if path == "~" {
+		return home
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return path
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/query"
+)
+
+func TestPrintWeightScores(t *testing.T) {
+	scores := []query.WeightScore{
+		{Weight: 0, NDCG: 0.4, MRR: 0.5, Recall: 0.5},
+		{Weight: 0.5, NDCG: 0.6, MRR: 0.5, Recall: 0.5},
+		{Weight: 0.7, NDCG: 0.9, MRR: 1, Recall: 1},
+	}
+	var sb strings.Builder
+	printWeightScores(&sb, scores, 3, 10, 0.5)
+	out := sb.String()
+
+	for _, want := range []string{"Evaluated 3 queries at k=10", "0.7     0.900", "<- best", "(current)", "Recommended: search.hybrid_weight: 0.7 (currently 0.5)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	sb.Reset()
+	printWeightScores(&sb, scores, 1, 10, 0.7)
+	if !strings.Contains(sb.String(), "already the best setting") {
+		t.Errorf("expected no recommendation when the current weight is best:\n%s", sb.String())
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := filepath.FromSlash("/home/u")
+	if got := expandHome("~/notes/go.md", home); got != filepath.Join(home, "notes", "go.md") {
+		t.Errorf("expandHome() = %q", got)
+	}
+	if got := expandHome("notes/go.md", home); got != "notes/go.md" {
+		t.Errorf("expandHome() changed a relative path to %q", got)
+	}
+}
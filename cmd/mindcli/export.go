@@ -19,6 +19,7 @@ type exportDoc struct {
 	Score      float64           `json:"score"`
 	Tags       string            `json:"tags,omitempty"`
 	ModifiedAt string            `json:"modified_at"`
+	Date       string            `json:"document_date,omitempty"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
 }
 
@@ -73,6 +74,11 @@ func exportMarkdown(w io.Writer, results storage.SearchResults, redactor privacy
 				return err
 			}
 		}
+		if assets := r.Document.Metadata["assets"]; assets != "" {
+			if _, err := fmt.Fprintf(w, "- **Assets:** %s\n", assets); err != nil {
+				return err
+			}
+		}
 		if _, err := fmt.Fprintf(w, "\n%s\n\n---\n\n", redactor.Redact(r.Document.Preview)); err != nil {
 			return err
 		}
@@ -89,6 +95,15 @@ func toExportDoc(r *storage.SearchResult, redactor privacy.Redactor) exportDoc {
 		Score:      r.Score,
 		Tags:       r.Document.Metadata["tags"],
 		ModifiedAt: r.Document.ModifiedAt.Format(time.RFC3339),
+		Date:       formatDate(r.Document.DocumentDate),
 		Metadata:   r.Document.Metadata,
 	}
 }
+
+// formatDate formats t as RFC 3339, or returns "" for the zero time.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
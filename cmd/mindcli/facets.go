@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/query"
+	"github.com/J-1000/mindcli/internal/search"
+)
+
+// facetLimit caps the sources, tags and months listed by --facets.
+const facetLimit = 10
+
+// searchFacets counts the full-text matches of parsed by source, tag and
+// month. Time and metadata filters, applied after the full-text search,
+// aren't counted.
+func searchFacets(ctx context.Context, s *stores, parsed query.ParsedQuery) (*search.Facets, error) {
+	return s.search.Facets(ctx, strings.TrimSpace(parsed.SearchTerms+" "+parsed.Filters()), facetLimit)
+}
+
+// printFacets prints the match counts of a query, one line per facet.
+func printFacets(w io.Writer, f *search.Facets) {
+	fmt.Fprintf(w, "%d matching %s\n", f.Total, plural(f.Total, "document", "documents"))
+	for _, facet := range []struct {
+		name   string
+		counts []search.FacetCount
+	}{{"Sources", f.Sources}, {"Tags", f.Tags}, {"Months", f.Months}} {
+		if len(facet.counts) == 0 {
+			continue
+		}
+		parts := make([]string, len(facet.counts))
+		for i, c := range facet.counts {
+			parts[i] = fmt.Sprintf("%s (%d)", c.Value, c.Count)
+		}
+		fmt.Fprintf(w, "  %-8s %s\n", facet.name+":", strings.Join(parts, ", "))
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/query"
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestSearchFacets(t *testing.T) {
+	searchIndex, err := search.NewBleveIndex(filepath.Join(t.TempDir(), "search.bleve"))
+	if err != nil {
+		t.Fatalf("Failed to create search index: %v", err)
+	}
+	defer closeTestIndex(t, searchIndex)
+
+	ctx := context.Background()
+	day := time.Date(2024, 3, 9, 12, 0, 0, 0, time.Local)
+	for _, doc := range []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Path: "/notes/k8s.md", Title: "Kubernetes", Content: "kubernetes deployment",
+			ModifiedAt: day, Metadata: map[string]string{"tags": "ops,k8s"}},
+		{ID: "2", Source: storage.SourcePDF, Path: "/docs/k8s.pdf", Title: "Kubernetes book", Content: "kubernetes",
+			ModifiedAt: day.AddDate(0, -2, 0), Metadata: map[string]string{"tags": "ops"}},
+		{ID: "3", Source: storage.SourceMarkdown, Path: "/notes/lunch.md", Title: "Lunch", Content: "sandwiches", ModifiedAt: day},
+	} {
+		if err := searchIndex.Index(ctx, doc); err != nil {
+			t.Fatalf("Failed to index doc: %v", err)
+		}
+	}
+	s := &stores{cfg: config.Default(), search: searchIndex}
+
+	f, err := searchFacets(ctx, s, query.ParseQuery("kubernetes"))
+	if err != nil {
+		t.Fatalf("searchFacets: %v", err)
+	}
+	var out bytes.Buffer
+	printFacets(&out, f)
+	want := "2 matching documents\n" +
+		"  Sources: markdown (1), pdf (1)\n" +
+		"  Tags:    ops (2), k8s (1)\n" +
+		"  Months:  2024-03 (1), 2024-01 (1)\n"
+	if got := out.String(); got != want {
+		t.Errorf("facets =\n%s\nwant\n%s", got, want)
+	}
+
+	parsed := query.ParseQuery("kubernetes")
+	parsed.SourceFilter = "pdf"
+	if f, err = searchFacets(ctx, s, parsed); err != nil || f.Total != 1 {
+		t.Errorf("searchFacets with a source filter = %+v, %v, want 1 match", f, err)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// runGrep prints the lines of one indexed document that contain a term.
+func runGrep(args []string) error {
+	fs := flag.NewFlagSet("grep", flag.ExitOnError)
+	around := fs.Int("C", 0, "Lines of context to show around each match")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 2 || *around < 0 {
+		return fmt.Errorf(`usage: mindcli grep [-C N] <doc-path> "term"`)
+	}
+	path, term := fs.Arg(0), fs.Arg(1)
+	if strings.TrimSpace(term) == "" {
+		return fmt.Errorf("empty search term")
+	}
+
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	doc, err := lookupDocument(s.db, path)
+	if err != nil {
+		return err
+	}
+	n := printGrep(os.Stdout, doc, term, *around, buildRedactor(s.cfg))
+	if n == 0 {
+		return fmt.Errorf("no matches for %q in %s", term, doc.Title)
+	}
+	return nil
+}
+
+// lookupDocument finds an indexed document by its path as given, or as an
+// absolute path with ~ expanded.
+func lookupDocument(db *storage.DB, path string) (*storage.Document, error) {
+	ctx := context.Background()
+	if doc, err := db.GetDocumentByPath(ctx, path); err == nil {
+		return doc, nil
+	}
+	home, _ := os.UserHomeDir()
+	if abs, err := filepath.Abs(expandHome(path, home)); err == nil {
+		if doc, err := db.GetDocumentByPath(ctx, abs); err == nil {
+			return doc, nil
+		}
+	}
+	return nil, fmt.Errorf("document not found: %s", path)
+}
+
+// grepLines returns the indexes of the lines containing term, ignoring case.
+func grepLines(lines []string, term string) []int {
+	term = strings.ToLower(term)
+	var matches []int
+	for i, line := range lines {
+		if strings.Contains(strings.ToLower(line), term) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// printGrep prints the matching lines of doc, numbered, with up to around
+// lines around each, and returns the number of matching lines.
+func printGrep(w io.Writer, doc *storage.Document, term string, around int, redactor privacy.Redactor) int {
+	lines := strings.Split(doc.Content, "\n")
+	matches := grepLines(lines, term)
+	if len(matches) == 0 {
+		return 0
+	}
+	fmt.Fprintf(w, "%s (%s): %d matching line%s\n", doc.Title, doc.Path, len(matches), plural(len(matches), "", "s"))
+
+	isMatch := make(map[int]bool, len(matches))
+	for _, i := range matches {
+		isMatch[i] = true
+	}
+	last := -1 // last line printed
+	for _, i := range matches {
+		from, to := max(i-around, last+1), min(i+around, len(lines)-1)
+		if from > to {
+			continue
+		}
+		if last >= 0 && from > last+1 {
+			fmt.Fprintln(w, "  --")
+		}
+		for j := from; j <= to; j++ {
+			sep := "-"
+			if isMatch[j] {
+				sep = ":"
+			}
+			fmt.Fprintf(w, "  %4d%s %s\n", j+1, sep, redactor.Redact(strings.TrimRight(lines[j], "\r")))
+		}
+		last = to
+	}
+	return len(matches)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestGrepLines(t *testing.T) {
+	lines := []string{"Go channels", "mutexes", "buffered CHANNELS block", ""}
+	got := grepLines(lines, "channel")
+	if len(got) != 2 || got[0] != 0 || got[1] != 2 {
+		t.Errorf("grepLines() = %v, want [0 2]", got)
+	}
+}
+
+func TestPrintGrep(t *testing.T) {
+	doc := &storage.Document{Title: "Go", Path: "/go.md",
+		Content: "# Go\nintro\nchannels connect goroutines\nmore\nmore\nmore\nclose the channel\nend"}
+
+	var buf bytes.Buffer
+	if n := printGrep(&buf, doc, "channel", 1, privacy.Redactor{}); n != 2 {
+		t.Errorf("printGrep() = %d matches, want 2", n)
+	}
+	want := "Go (/go.md): 2 matching lines\n" +
+		"     2- intro\n" +
+		"     3: channels connect goroutines\n" +
+		"     4- more\n" +
+		"  --\n" +
+		"     6- more\n" +
+		"     7: close the channel\n" +
+		"     8- end\n"
+	if buf.String() != want {
+		t.Errorf("printGrep() output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if n := printGrep(&buf, doc, "rust", 0, privacy.Redactor{}); n != 0 || buf.Len() != 0 {
+		t.Errorf("printGrep() with no matches = %d, %q", n, buf.String())
+	}
+}
+
+func TestLookupDocument(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	abs, err := filepath.Abs("notes/go.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &storage.Document{ID: "1", Source: storage.SourceMarkdown, Path: abs, Title: "Go", ContentHash: "h"}
+	if err := db.InsertDocument(t.Context(), doc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{abs, "notes/go.md"} {
+		if got, err := lookupDocument(db, path); err != nil || got.ID != "1" {
+			t.Errorf("lookupDocument(%q) = %v, %v", path, got, err)
+		}
+	}
+	if _, err := lookupDocument(db, "missing.md"); err == nil {
+		t.Error("expected an error for an unindexed path")
+	}
+}
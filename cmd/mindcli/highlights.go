@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/highlights"
+)
+
+// runImportHighlights converts a Kindle or Readwise highlights export into
+// markdown notes in the notes folder, one per book, and indexes them.
+func runImportHighlights(format string, args []string) error {
+	usage := fmt.Sprintf("usage: mindcli import %s [--dir folder] <export>", format)
+	fs := flag.NewFlagSet("import "+format, flag.ExitOnError)
+	dir := fs.String("dir", "", "Folder to write the notes to (default: highlights/"+format+" in the notes folder)")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New(usage)
+	}
+	books, err := highlights.Parse(format, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(books) == 0 {
+		return fmt.Errorf("no highlights found in %s", fs.Arg(0))
+	}
+
+	s, err := openStores(openOpts{vectors: true, embedder: true, indexing: true})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	outDir := *dir
+	if outDir == "" {
+		notes, err := transcriptNotesDir(s.cfg)
+		if err != nil {
+			return err
+		}
+		outDir = filepath.Join(notes, "highlights", format)
+	}
+	paths, err := writeBookNotes(outDir, books)
+	if err != nil {
+		return err
+	}
+
+	indexer := s.newIndexer(s.vectors)
+	ctx := context.Background()
+	failed := 0
+	for _, path := range paths {
+		if err := indexer.IndexFile(ctx, path); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: indexing %s: %v\n", path, err)
+			failed++
+		}
+	}
+	if err := indexer.SaveVectors(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: saving vectors: %v\n", err)
+	}
+
+	count := 0
+	for _, b := range books {
+		count += len(b.Highlights)
+	}
+	fmt.Printf("Imported %d highlights from %d books into %s\n", count, len(books), outDir)
+	if failed > 0 {
+		fmt.Printf("%d notes could not be indexed; is %s inside a markdown notes folder?\n", failed, outDir)
+	}
+	return nil
+}
+
+// writeBookNotes writes the books as markdown notes in dir, one per book,
+// and returns their paths. Notes from an earlier import of the same books
+// are overwritten; as exports hold every highlight so far, nothing is lost.
+func writeBookNotes(dir string, books []highlights.Book) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating notes folder: %w", err)
+	}
+	paths := make([]string, 0, len(books))
+	written := make(map[string]bool)
+	for _, b := range books {
+		name := b.FileName()
+		// Books whose titles differ only in punctuation share a slug.
+		stem := strings.TrimSuffix(name, ".md")
+		for i := 2; written[name]; i++ {
+			name = fmt.Sprintf("%s-%d.md", stem, i)
+		}
+		written[name] = true
+
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(b.Markdown()), 0o644); err != nil {
+			return paths, fmt.Errorf("writing highlights note: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/highlights"
+	"github.com/J-1000/mindcli/internal/index/sources"
+)
+
+func TestWriteBookNotes(t *testing.T) {
+	books := []highlights.Book{
+		{Service: "Kindle", Title: "Deep Work", Author: "Cal Newport", Highlights: []highlights.Highlight{
+			{Text: "Depth over breadth", Location: "120-121"},
+		}},
+		{Service: "Kindle", Title: "Deep Work!", Highlights: []highlights.Highlight{{Text: "Another edition"}}},
+	}
+	dir := filepath.Join(t.TempDir(), "highlights")
+
+	paths, err := writeBookNotes(dir, books)
+	if err != nil {
+		t.Fatalf("writeBookNotes() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "deep-work.md"), filepath.Join(dir, "deep-work-2.md")}
+	if len(paths) != 2 || paths[0] != want[0] || paths[1] != want[1] {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+
+	// The notes are indexed as markdown, tagged and with the author and
+	// each highlight's location as metadata.
+	src := sources.NewMarkdownSource([]string{dir}, []string{".md"}, nil)
+	doc, err := src.Parse(context.Background(), sources.FileInfo{Path: paths[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Deep Work" || doc.Metadata["fm_author"] != "Cal Newport" || doc.Metadata["tags"] != "highlights,kindle" {
+		t.Errorf("note = title %q metadata %v", doc.Title, doc.Metadata)
+	}
+	if !strings.Contains(doc.Metadata["headings"], "Location 120-121") || !strings.Contains(doc.Content, "> Depth over breadth") {
+		t.Errorf("note headings %q content %q", doc.Metadata["headings"], doc.Content)
+	}
+}
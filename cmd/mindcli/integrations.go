@@ -0,0 +1,287 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/pkg/chunker"
+)
+
+// alfredResultsLimit is how many results the Alfred workflow lists.
+const alfredResultsLimit = 20
+
+// alfredSubtitleLength caps the preview shown under an Alfred result.
+const alfredSubtitleLength = 120
+
+func runIntegrations(args []string) error {
+	const usage = "usage: mindcli integrations install <raycast|alfred> [--dir dir] [--binary path] [--keyword kb]"
+	if len(args) < 2 || args[0] != "install" {
+		return fmt.Errorf(usage)
+	}
+	target := args[1]
+	fs := flag.NewFlagSet("integrations", flag.ExitOnError)
+	dir := fs.String("dir", "", "Directory to write to (default: the mindcli config directory)")
+	binary := fs.String("binary", "", "mindcli binary the integration runs (default: this one)")
+	keyword := fs.String("keyword", "kb", "Alfred keyword that starts a search")
+	_ = fs.Parse(args[2:])
+
+	if *binary == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("finding the mindcli binary (use --binary): %w", err)
+		}
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		*binary = exe
+	}
+	if *dir == "" {
+		configDir, err := config.ConfigDir()
+		if err != nil {
+			return fmt.Errorf("getting config directory: %w", err)
+		}
+		*dir = filepath.Join(configDir, "integrations", target)
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", *dir, err)
+	}
+
+	switch target {
+	case "raycast":
+		paths, err := installRaycast(*dir, *binary)
+		if err != nil {
+			return err
+		}
+		for _, p := range paths {
+			fmt.Printf("Wrote %s\n", p)
+		}
+		fmt.Printf("In Raycast, open Settings > Extensions > Script Commands > Add Directories and add %s\n", *dir)
+	case "alfred":
+		path, err := installAlfred(*dir, *binary, *keyword)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", path)
+		fmt.Printf("Open it to import the workflow into Alfred, then type %q followed by a search\n", *keyword)
+	default:
+		return fmt.Errorf("unknown integration %q: use raycast or alfred", target)
+	}
+	return nil
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// raycastScript is a Raycast script command that runs mindcli with the
+// text typed into Raycast as its last argument.
+type raycastScript struct {
+	file, title, mode, placeholder, args string
+}
+
+var raycastScripts = []raycastScript{
+	{"mindcli-search.sh", "Search Knowledge Base", "fullOutput", "Query", "search"},
+	{"mindcli-ask.sh", "Ask Knowledge Base", "fullOutput", "Question", "ask"},
+	{"mindcli-open.sh", "Open Document", "silent", "Alias, path or title", "open"},
+}
+
+// installRaycast writes the Raycast script commands to dir and returns
+// their paths.
+func installRaycast(dir, binary string) ([]string, error) {
+	var paths []string
+	for _, s := range raycastScripts {
+		script := fmt.Sprintf(`#!/bin/bash
+
+# Required parameters:
+# @raycast.schemaVersion 1
+# @raycast.title %s
+# @raycast.mode %s
+# @raycast.packageName MindCLI
+
+# Optional parameters:
+# @raycast.icon 🧠
+# @raycast.argument1 { "type": "text", "placeholder": "%s" }
+
+exec %s %s "$1"
+`, s.title, s.mode, s.placeholder, shellQuote(binary), s.args)
+		path := filepath.Join(dir, s.file)
+		if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// alfredPlist is the workflow's info.plist: a script filter listing search
+// results as they are typed, connected to a script opening the one chosen.
+var alfredPlist = template.Must(template.New("info.plist").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>bundleid</key>
+	<string>com.github.J-1000.mindcli</string>
+	<key>name</key>
+	<string>MindCLI</string>
+	<key>description</key>
+	<string>Search your knowledge base</string>
+	<key>connections</key>
+	<dict>
+		<key>mindcli.search</key>
+		<array>
+			<dict>
+				<key>destinationuid</key>
+				<string>mindcli.open</string>
+				<key>modifiers</key>
+				<integer>0</integer>
+				<key>modifiersubtext</key>
+				<string></string>
+			</dict>
+		</array>
+	</dict>
+	<key>objects</key>
+	<array>
+		<dict>
+			<key>config</key>
+			<dict>
+				<key>alfredfiltersresults</key>
+				<false/>
+				<key>argumenttype</key>
+				<integer>0</integer>
+				<key>escaping</key>
+				<integer>0</integer>
+				<key>keyword</key>
+				<string>{{xml .Keyword}}</string>
+				<key>queuedelaycustom</key>
+				<integer>3</integer>
+				<key>queuedelaymode</key>
+				<integer>0</integer>
+				<key>queuemode</key>
+				<integer>1</integer>
+				<key>runningsubtext</key>
+				<string>Searching…</string>
+				<key>script</key>
+				<string>{{xml .Search}}</string>
+				<key>scriptargtype</key>
+				<integer>1</integer>
+				<key>title</key>
+				<string>Search knowledge base</string>
+				<key>type</key>
+				<integer>0</integer>
+				<key>withspace</key>
+				<true/>
+			</dict>
+			<key>type</key>
+			<string>alfred.workflow.input.scriptfilter</string>
+			<key>uid</key>
+			<string>mindcli.search</string>
+			<key>version</key>
+			<integer>3</integer>
+		</dict>
+		<dict>
+			<key>config</key>
+			<dict>
+				<key>concurrently</key>
+				<false/>
+				<key>escaping</key>
+				<integer>0</integer>
+				<key>script</key>
+				<string>{{xml .Open}}</string>
+				<key>scriptargtype</key>
+				<integer>1</integer>
+				<key>type</key>
+				<integer>0</integer>
+			</dict>
+			<key>type</key>
+			<string>alfred.workflow.action.script</string>
+			<key>uid</key>
+			<string>mindcli.open</string>
+			<key>version</key>
+			<integer>2</integer>
+		</dict>
+	</array>
+</dict>
+</plist>
+`))
+
+// xmlEscape escapes s for XML character data.
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// installAlfred writes MindCLI.alfredworkflow to dir and returns its path.
+func installAlfred(dir, binary, keyword string) (string, error) {
+	var plist bytes.Buffer
+	err := alfredPlist.Execute(&plist, struct{ Keyword, Search, Open string }{
+		Keyword: keyword,
+		Search:  fmt.Sprintf(`%s export --format alfred --limit %d "$1"`, shellQuote(binary), alfredResultsLimit),
+		Open:    fmt.Sprintf(`%s open "$1"`, shellQuote(binary)),
+	})
+	if err != nil {
+		return "", fmt.Errorf("rendering workflow: %w", err)
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	f, err := zw.Create("info.plist")
+	if err == nil {
+		_, err = f.Write(plist.Bytes())
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		return "", fmt.Errorf("packing workflow: %w", err)
+	}
+
+	path := filepath.Join(dir, "MindCLI.alfredworkflow")
+	if err := os.WriteFile(path, archive.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// alfredItem is a result in Alfred's script filter JSON.
+type alfredItem struct {
+	UID      string `json:"uid,omitempty"`
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+	Arg      string `json:"arg,omitempty"`
+	Valid    bool   `json:"valid"`
+}
+
+// exportAlfred writes results as Alfred script filter items, each opening
+// its document with mindcli open. No results is an item saying so, as Alfred
+// shows nothing for a failed script.
+func exportAlfred(w io.Writer, results storage.SearchResults, redactor privacy.Redactor) error {
+	items := make([]alfredItem, 0, len(results))
+	for _, r := range results {
+		doc := r.Document
+		subtitle := string(doc.Source) + " · " + doc.Path
+		if preview := strings.Join(strings.Fields(redactor.Redact(doc.Preview)), " "); preview != "" {
+			subtitle = string(doc.Source) + " · " + chunker.Truncate(preview, alfredSubtitleLength)
+		}
+		items = append(items, alfredItem{UID: doc.ID, Title: redactor.Redact(doc.Title), Subtitle: subtitle, Arg: doc.Path, Valid: true})
+	}
+	if len(items) == 0 {
+		items = append(items, alfredItem{Title: "No results", Subtitle: "Try other words, or check that mindcli index has run"})
+	}
+	return json.NewEncoder(w).Encode(struct {
+		Items []alfredItem `json:"items"`
+	}{items})
+}
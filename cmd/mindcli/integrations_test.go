@@ -0,0 +1,101 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote("/Users/o'brien/bin/mindcli"); got != `'/Users/o'\''brien/bin/mindcli'` {
+		t.Errorf("shellQuote() = %s", got)
+	}
+}
+
+func TestInstallRaycast(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := installRaycast(dir, "/opt/my tools/mindcli")
+	if err != nil {
+		t.Fatalf("installRaycast() error = %v", err)
+	}
+	if len(paths) != len(raycastScripts) {
+		t.Fatalf("wrote %d scripts, want %d", len(paths), len(raycastScripts))
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "mindcli-search.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"@raycast.schemaVersion 1", "@raycast.title Search Knowledge Base", `exec '/opt/my tools/mindcli' search "$1"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("search script missing %q:\n%s", want, data)
+		}
+	}
+	if info, err := os.Stat(paths[0]); err != nil || info.Mode()&0o100 == 0 {
+		t.Errorf("script mode = %v, want it executable", info.Mode())
+	}
+}
+
+func TestInstallAlfred(t *testing.T) {
+	path, err := installAlfred(t.TempDir(), "/usr/local/bin/mindcli", "kb")
+	if err != nil {
+		t.Fatalf("installAlfred() error = %v", err)
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("workflow isn't a zip: %v", err)
+	}
+	defer func() { _ = zr.Close() }()
+	if len(zr.File) != 1 || zr.File[0].Name != "info.plist" {
+		t.Fatalf("workflow holds %v, want info.plist", zr.File)
+	}
+	f, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plist, _ := io.ReadAll(f)
+	_ = f.Close()
+
+	if err := xml.Unmarshal(plist, new(struct{})); err != nil {
+		t.Errorf("info.plist isn't well-formed XML: %v", err)
+	}
+	for _, want := range []string{"<string>kb</string>", `&#39;/usr/local/bin/mindcli&#39; export --format alfred --limit 20 &#34;$1&#34;`, "alfred.workflow.input.scriptfilter"} {
+		if !strings.Contains(string(plist), want) {
+			t.Errorf("info.plist missing %q", want)
+		}
+	}
+}
+
+func TestExportAlfred(t *testing.T) {
+	results := storage.SearchResults{{Document: &storage.Document{
+		ID: "1", Title: "Go channels", Source: storage.SourceMarkdown, Path: "/notes/go.md", Preview: "Channels   connect\ngoroutines.",
+	}}}
+	var buf bytes.Buffer
+	if err := exportAlfred(&buf, results, privacy.Redactor{}); err != nil {
+		t.Fatalf("exportAlfred() error = %v", err)
+	}
+	var out struct{ Items []alfredItem }
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("output isn't JSON: %v", err)
+	}
+	want := alfredItem{UID: "1", Title: "Go channels", Subtitle: "markdown · Channels connect goroutines.", Arg: "/notes/go.md", Valid: true}
+	if len(out.Items) != 1 || out.Items[0] != want {
+		t.Errorf("items = %+v, want %+v", out.Items, want)
+	}
+
+	buf.Reset()
+	if err := exportAlfred(&buf, nil, privacy.Redactor{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"title":"No results"`) {
+		t.Errorf("no results should be an item saying so: %s", buf.String())
+	}
+}
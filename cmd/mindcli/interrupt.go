@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// interruptible returns a context cancelled by the first SIGINT or SIGTERM,
+// after printing notice, so that long-running work can stop cleanly. A
+// second signal exits at once. stop restores the default handling.
+func interruptible(notice string) (ctx context.Context, stop func()) {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	ctx, done := cancelOnSignal(sigs, os.Stderr, notice, func() { os.Exit(130) })
+	return ctx, func() {
+		signal.Stop(sigs)
+		done()
+	}
+}
+
+// cancelOnSignal cancels the returned context on the first value from sigs
+// and calls exit on the second, until done is called.
+func cancelOnSignal(sigs <-chan os.Signal, w io.Writer, notice string, exit func()) (ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-sigs:
+		case <-stopped:
+			return
+		}
+		fmt.Fprintln(w, "\n"+notice)
+		cancel()
+		select {
+		case <-sigs:
+			exit()
+		case <-stopped:
+		}
+	}()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() { close(stopped) })
+		cancel()
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to write from another goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestCancelOnSignal(t *testing.T) {
+	sigs := make(chan os.Signal, 2)
+	var out syncBuffer
+	exited := make(chan struct{})
+	ctx, done := cancelOnSignal(sigs, &out, "Stopping...", func() { close(exited) })
+	defer done()
+
+	sigs <- os.Interrupt
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("first signal did not cancel the context")
+	}
+	if !strings.Contains(out.String(), "Stopping...") {
+		t.Errorf("output = %q, want the notice", out.String())
+	}
+
+	sigs <- os.Interrupt
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("second signal did not exit")
+	}
+
+	ctx, done = cancelOnSignal(sigs, &out, "Stopping...", func() { t.Error("exited without a signal") })
+	done()
+	if ctx.Err() == nil {
+		t.Error("done did not release the context")
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/query"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// runKeywords prints the words and phrases that set one document apart from
+// the rest of the index, and with --tag stores them as auto tags.
+func runKeywords(args []string) error {
+	fs := flag.NewFlagSet("keywords", flag.ExitOnError)
+	n := fs.Int("n", 10, "Number of keywords to show")
+	tag := fs.Bool("tag", false, "Store the keywords as auto tags on the document")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 || *n < 1 {
+		return fmt.Errorf("usage: mindcli keywords [-n 10] [--tag] <doc-path>")
+	}
+
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	doc, err := lookupDocument(s.db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	docs, err := s.db.ListDocuments(ctx, "", 0, 0)
+	if err != nil {
+		return err
+	}
+	keywords := query.ExtractKeywords(doc.Content, corpusTermStats(docs), *n)
+	if len(keywords) == 0 {
+		fmt.Printf("No keywords found in %s\n", doc.Title)
+		return nil
+	}
+	printKeywords(os.Stdout, doc, keywords)
+
+	if *tag {
+		for _, k := range keywords {
+			if err := s.db.AddAutoTag(ctx, doc.ID, keywordTag(k.Phrase)); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Tagged %s with %s\n", doc.Title, plural(len(keywords), "1 keyword", fmt.Sprintf("%d keywords", len(keywords))))
+	}
+	return nil
+}
+
+// corpusTermStats counts the words of docs, leaving out sections so a note
+// split by heading isn't counted twice.
+func corpusTermStats(docs []*storage.Document) *query.TermStats {
+	stats := query.NewTermStats()
+	for _, doc := range docs {
+		if !doc.IsSection() {
+			stats.Add(doc.Title + "\n" + doc.Content)
+		}
+	}
+	return stats
+}
+
+// keywordTag turns a keyword into a tag, joining a phrase's words with
+// hyphens.
+func keywordTag(phrase string) string {
+	return strings.Join(strings.Fields(phrase), "-")
+}
+
+// printKeywords prints keywords ranked, with how often each occurs in doc.
+func printKeywords(w io.Writer, doc *storage.Document, keywords []query.Keyword) {
+	fmt.Fprintf(w, "Keywords for %s:\n", doc.Title)
+	for i, k := range keywords {
+		fmt.Fprintf(w, "  %2d. %s (%s)\n", i+1, k.Phrase, plural(k.Count, "1 time", fmt.Sprintf("%d times", k.Count)))
+	}
+}
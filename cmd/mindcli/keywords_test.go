@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/query"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestCorpusTermStats(t *testing.T) {
+	docs := []*storage.Document{
+		{ID: "1", Title: "Go", Content: "channels"},
+		{ID: "1a", Title: "Go » Channels", Content: "channels", Metadata: map[string]string{"section_of": "1"}},
+		{ID: "2", Title: "Rust", Content: "ownership"},
+	}
+	if got := corpusTermStats(docs).Documents(); got != 2 {
+		t.Errorf("Documents() = %d, want 2 (sections left out)", got)
+	}
+}
+
+func TestKeywordTag(t *testing.T) {
+	if got := keywordTag("vector  databases"); got != "vector-databases" {
+		t.Errorf("keywordTag = %q", got)
+	}
+}
+
+func TestPrintKeywords(t *testing.T) {
+	var buf bytes.Buffer
+	printKeywords(&buf, &storage.Document{Title: "Paper"}, []query.Keyword{
+		{Phrase: "vector databases", Count: 4},
+		{Phrase: "embeddings", Count: 1},
+	})
+	out := buf.String()
+	for _, want := range []string{"Keywords for Paper:", " 1. vector databases (4 times)", " 2. embeddings (1 time)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// runLint reports wiki links to notes that don't exist, notes no other note
+// links to or from, and documents with next to nothing in them.
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	minWords := fs.Int("min-words", 10, "Report documents with fewer words than this as near-empty (0 to skip)")
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("usage: mindcli lint [--min-words 10]")
+	}
+	if *minWords < 0 {
+		return fmt.Errorf("-min-words must not be negative")
+	}
+
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	docs, err := s.db.ListDocuments(context.Background(), "", 0, 0)
+	if err != nil {
+		return err
+	}
+	report := lintDocuments(docs, *minWords)
+	printLint(os.Stdout, report)
+	if n := report.problems(); n > 0 {
+		return fmt.Errorf("%s found", plural(n, "1 problem", fmt.Sprintf("%d problems", n)))
+	}
+	return nil
+}
+
+// lintWikiLinkRe matches a wiki link or embed, capturing its target.
+var lintWikiLinkRe = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
+
+// brokenLink is a wiki link in a note that no note answers to.
+type brokenLink struct {
+	doc    *storage.Document
+	target string
+}
+
+// lintReport is what lint found wrong with the index.
+type lintReport struct {
+	notes   int
+	broken  []brokenLink
+	orphans []*storage.Document
+	empty   []*storage.Document
+}
+
+// problems returns how many things the report lists.
+func (r lintReport) problems() int {
+	return len(r.broken) + len(r.orphans) + len(r.empty)
+}
+
+// lintDocuments checks docs: the wiki links of markdown notes against the
+// notes they name, resolved as ResolveLink does, and every document's length
+// against minWords. Sections are checked as part of their document.
+func lintDocuments(docs []*storage.Document, minWords int) lintReport {
+	var r lintReport
+	links := newLinkTable()
+	var notes []*storage.Document
+	for _, doc := range docs {
+		if doc.IsSection() {
+			continue
+		}
+		if minWords > 0 && storage.CountWords(doc.Content) < minWords {
+			r.empty = append(r.empty, doc)
+		}
+		if doc.Source == storage.SourceMarkdown {
+			notes = append(notes, doc)
+			links.add(doc)
+		}
+	}
+	r.notes = len(notes)
+
+	linked := make(map[string]bool)
+	for _, doc := range notes {
+		for _, target := range noteLinks(doc.Content) {
+			to := links.resolve(target)
+			switch {
+			case to == nil:
+				r.broken = append(r.broken, brokenLink{doc: doc, target: target})
+			case to.ID != doc.ID:
+				linked[doc.ID] = true
+				linked[to.ID] = true
+			}
+		}
+	}
+	for _, doc := range notes {
+		if !linked[doc.ID] {
+			r.orphans = append(r.orphans, doc)
+		}
+	}
+
+	byPath := func(a, b *storage.Document) int { return cmp.Compare(a.Path, b.Path) }
+	slices.SortFunc(r.orphans, byPath)
+	slices.SortFunc(r.empty, byPath)
+	slices.SortStableFunc(r.broken, func(a, b brokenLink) int { return byPath(a.doc, b.doc) })
+	return r
+}
+
+// noteLinks returns the unique wiki link targets in content that name a note,
+// without any "|display" or "#heading" part. Links to a heading in the same
+// note and embeds of other files, such as ![[image.png]], are left out.
+func noteLinks(content string) []string {
+	var targets []string
+	seen := make(map[string]bool)
+	for _, loc := range lintWikiLinkRe.FindAllStringSubmatchIndex(content, -1) {
+		target, _, _ := strings.Cut(content[loc[2]:loc[3]], "|")
+		target, _, _ = strings.Cut(target, "#")
+		target = strings.TrimSpace(target)
+		if target == "" || seen[strings.ToLower(target)] {
+			continue
+		}
+		if ext := strings.ToLower(filepath.Ext(target)); loc[0] > 0 && content[loc[0]-1] == '!' && ext != "" && ext != ".md" && ext != ".markdown" {
+			continue
+		}
+		seen[strings.ToLower(target)] = true
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// linkTable resolves wiki link targets to notes in memory, so a vault's links
+// can be checked without a query each.
+type linkTable struct {
+	titles  map[string]*storage.Document
+	stems   map[string][]*storage.Document // by lowercased file name without extension
+	aliases map[string]*storage.Document
+}
+
+func newLinkTable() *linkTable {
+	return &linkTable{
+		titles:  make(map[string]*storage.Document),
+		stems:   make(map[string][]*storage.Document),
+		aliases: make(map[string]*storage.Document),
+	}
+}
+
+// add makes doc a link target. Notes added earlier win ties, as the most
+// recently modified do for ResolveLink.
+func (t *linkTable) add(doc *storage.Document) {
+	if title := strings.ToLower(doc.Title); title != "" && t.titles[title] == nil {
+		t.titles[title] = doc
+	}
+	p := strings.ToLower(filepath.ToSlash(doc.Path))
+	stem := strings.TrimSuffix(p, filepath.Ext(p))
+	name := stem[strings.LastIndex(stem, "/")+1:]
+	t.stems[name] = append(t.stems[name], doc)
+	for _, a := range strings.Split(doc.Metadata["fm_aliases"], ",") {
+		a = strings.ToLower(strings.Trim(strings.TrimSpace(a), `"'`))
+		if a != "" && t.aliases[a] == nil {
+			t.aliases[a] = doc
+		}
+	}
+}
+
+// resolve returns the note target names by title, file name (with an
+// optional folder prefix) or alias, or nil when there is none.
+func (t *linkTable) resolve(target string) *storage.Document {
+	base := strings.ToLower(filepath.ToSlash(target))
+	if doc := t.titles[base]; doc != nil {
+		return doc
+	}
+	for _, doc := range t.stems[base[strings.LastIndex(base, "/")+1:]] {
+		p := strings.ToLower(filepath.ToSlash(doc.Path))
+		stem := strings.TrimSuffix(p, filepath.Ext(p))
+		if stem == base || strings.HasSuffix(stem, "/"+base) {
+			return doc
+		}
+	}
+	return t.aliases[base]
+}
+
+// printLint prints each broken link under the note it is in, then the orphan
+// and near-empty documents.
+func printLint(w io.Writer, r lintReport) {
+	if r.problems() == 0 {
+		fmt.Fprintf(w, "ok %s checked, nothing to fix\n", plural(r.notes, "1 note", fmt.Sprintf("%d notes", r.notes)))
+		return
+	}
+	if len(r.broken) > 0 {
+		fmt.Fprintf(w, "Broken links (%d):\n", len(r.broken))
+		var last *storage.Document
+		for _, b := range r.broken {
+			if b.doc != last {
+				last = b.doc
+				fmt.Fprintf(w, "  %s\n", b.doc.Path)
+			}
+			fmt.Fprintf(w, "    [[%s]]\n", b.target)
+		}
+	}
+	if len(r.orphans) > 0 {
+		fmt.Fprintf(w, "Orphan notes, with no links in or out (%d):\n", len(r.orphans))
+		for _, doc := range r.orphans {
+			fmt.Fprintf(w, "  %s\n", doc.Path)
+		}
+	}
+	if len(r.empty) > 0 {
+		fmt.Fprintf(w, "Empty or near-empty documents (%d):\n", len(r.empty))
+		for _, doc := range r.empty {
+			n := storage.CountWords(doc.Content)
+			fmt.Fprintf(w, "  %s (%s)\n", doc.Path, plural(n, "1 word", fmt.Sprintf("%d words", n)))
+		}
+	}
+}
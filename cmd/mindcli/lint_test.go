@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestLintDocuments(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+	note := func(id, path, title, content string) *storage.Document {
+		return &storage.Document{ID: id, Source: storage.SourceMarkdown, Path: path, Title: title, Content: content + " " + long}
+	}
+	docs := []*storage.Document{
+		note("1", "/vault/index.md", "Index", "See [[Projects]], [[archive/old-plan|the old plan]] and [[Missing Note]]."),
+		note("2", "/vault/projects.md", "Projects", "Back to [[index#top]]. ![[diagram.png]]"),
+		note("3", "/vault/archive/old-plan.md", "Old Plan", "Nothing links out."),
+		note("4", "/vault/alone.md", "Alone", "Only [[#a heading]] and [[Alone]] itself."),
+		note("5", "/vault/nicknamed.md", "Nicknamed", "Points at [[nowhere]] and [[Nowhere]]."),
+		note("6", "/vault/friend.md", "Friend", "Call me [[nick]]."),
+		{ID: "6a", Source: storage.SourceMarkdown, Path: "/vault/friend.md#call", Title: "Call", Content: "", Metadata: map[string]string{"section_of": "6"}},
+		{ID: "7", Source: storage.SourcePDF, Path: "/docs/blank.pdf", Title: "Blank", Content: "two words"},
+	}
+	docs[4].Metadata = map[string]string{"fm_aliases": `"Nick"`}
+
+	r := lintDocuments(docs, 10)
+
+	var broken []string
+	for _, b := range r.broken {
+		broken = append(broken, b.doc.ID+":"+b.target)
+	}
+	if got, want := strings.Join(broken, ","), "1:Missing Note,5:nowhere"; got != want {
+		t.Errorf("broken = %s, want %s", got, want)
+	}
+	var orphans []string
+	for _, doc := range r.orphans {
+		orphans = append(orphans, doc.ID)
+	}
+	if got, want := strings.Join(orphans, ","), "4"; got != want {
+		t.Errorf("orphans = %s, want %s", got, want)
+	}
+	if len(r.empty) != 1 || r.empty[0].ID != "7" {
+		t.Errorf("empty = %v, want only the blank PDF", r.empty)
+	}
+	if r.notes != 6 || r.problems() != 4 {
+		t.Errorf("notes = %d, problems = %d, want 6 and 4", r.notes, r.problems())
+	}
+
+	if r := lintDocuments(docs, 0); len(r.empty) != 0 {
+		t.Errorf("empty with -min-words 0 = %d documents, want none", len(r.empty))
+	}
+}
+
+func TestPrintLint(t *testing.T) {
+	var buf bytes.Buffer
+	printLint(&buf, lintReport{notes: 3})
+	if !strings.Contains(buf.String(), "3 notes checked") {
+		t.Errorf("clean output = %q", buf.String())
+	}
+
+	buf.Reset()
+	index := &storage.Document{Path: "/vault/index.md"}
+	printLint(&buf, lintReport{
+		notes:   2,
+		broken:  []brokenLink{{doc: index, target: "Missing"}, {doc: index, target: "Gone"}},
+		orphans: []*storage.Document{{Path: "/vault/alone.md"}},
+		empty:   []*storage.Document{{Path: "/vault/stub.md", Content: "todo"}},
+	})
+	out := buf.String()
+	for _, want := range []string{"Broken links (2)", "[[Missing]]", "[[Gone]]", "Orphan notes", "/vault/alone.md", "Empty or near-empty documents (1)", "/vault/stub.md (1 word)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "/vault/index.md") != 1 {
+		t.Errorf("broken links should be grouped under their note:\n%s", out)
+	}
+}
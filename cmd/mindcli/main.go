@@ -1,14 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -16,11 +20,15 @@ import (
 	"github.com/J-1000/mindcli/internal/config"
 	"github.com/J-1000/mindcli/internal/embeddings"
 	"github.com/J-1000/mindcli/internal/index"
+	"github.com/J-1000/mindcli/internal/index/sources"
+	"github.com/J-1000/mindcli/internal/notify"
+	"github.com/J-1000/mindcli/internal/plugins"
 	"github.com/J-1000/mindcli/internal/privacy"
 	"github.com/J-1000/mindcli/internal/query"
 	"github.com/J-1000/mindcli/internal/search"
 	"github.com/J-1000/mindcli/internal/storage"
 	"github.com/J-1000/mindcli/internal/tui"
+	"github.com/J-1000/mindcli/pkg/chunker"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -44,24 +52,36 @@ func run() error {
 	indexPaths := indexCmd.String("paths", "", "Comma-separated paths to index (overrides config)")
 	indexWatch := indexCmd.Bool("watch", false, "Watch for file changes after indexing")
 	indexForce := indexCmd.Bool("force", false, "Re-index everything, ignoring unchanged-file checks")
+	indexIdleOnly := indexCmd.Bool("idle-only", false, "Pause indexing while the machine is busy")
+	indexRetryFailed := indexCmd.Bool("retry-failed", false, "Only re-attempt files that failed to index")
+	indexLowMemory := indexCmd.Bool("low-memory", false, "Use one worker and smaller memory and batch limits")
+	indexYes := indexCmd.Bool("yes", false, "Index new directories without asking, however large")
 
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "index":
 			_ = indexCmd.Parse(os.Args[2:])
-			return runIndex(*indexPaths, *indexWatch, *indexForce)
+			if *indexRetryFailed {
+				return runRetryFailed(*indexIdleOnly, *indexLowMemory)
+			}
+			return runIndex(*indexPaths, *indexWatch, *indexForce, *indexIdleOnly, *indexLowMemory, *indexYes)
 		case "reindex":
 			fs := flag.NewFlagSet("reindex", flag.ExitOnError)
 			paths := fs.String("paths", "", "Comma-separated paths to index (overrides config)")
+			lowMemory := fs.Bool("low-memory", false, "Use one worker and smaller memory and batch limits")
+			yes := fs.Bool("yes", false, "Index new directories without asking, however large")
 			_ = fs.Parse(os.Args[2:])
-			return runIndex(*paths, false, true)
+			return runIndex(*paths, false, true, false, *lowMemory, *yes)
+		case "digest":
+			return runDigest(os.Args[2:])
 		case "watch":
-			return runWatch()
+			fs := flag.NewFlagSet("watch", flag.ExitOnError)
+			idleOnly := fs.Bool("idle-only", false, "Pause indexing while the machine is busy")
+			lowMemory := fs.Bool("low-memory", false, "Use one worker and smaller memory and batch limits")
+			_ = fs.Parse(os.Args[2:])
+			return runWatch(*idleOnly, *lowMemory)
 		case "search":
-			if len(os.Args) < 3 {
-				return fmt.Errorf("usage: mindcli search \"query\"")
-			}
-			return runSearch(strings.Join(os.Args[2:], " "))
+			return runSearch(os.Args[2:])
 		case "export":
 			return runExport(os.Args[2:])
 		case "tag":
@@ -70,15 +90,54 @@ func run() error {
 			return runClipboard(os.Args[2:])
 		case "collection":
 			return runCollection(os.Args[2:])
+		case "alias":
+			return runAlias(os.Args[2:])
+		case "open":
+			return runOpen(os.Args[2:])
+		case "recent":
+			return runRecent(os.Args[2:])
+		case "random":
+			return runRandom(os.Args[2:])
+		case "onthisday":
+			return runOnThisDay(os.Args[2:])
+		case "contacts":
+			return runContacts(os.Args[2:])
+		case "errors":
+			return runErrors(os.Args[2:])
+		case "snapshot":
+			return runSnapshot(os.Args[2:])
+		case "sync":
+			return runSync(os.Args[2:])
 		case "ask":
-			if len(os.Args) < 3 {
-				return fmt.Errorf("usage: mindcli ask \"your question\"")
-			}
-			return runAsk(strings.Join(os.Args[2:], " "))
+			return runAsk(os.Args[2:])
+		case "eval":
+			return runEval(os.Args[2:])
+		case "bench":
+			return runBench(os.Args[2:])
+		case "similar":
+			return runSimilar(os.Args[2:])
+		case "grep":
+			return runGrep(os.Args[2:])
+		case "keywords":
+			return runKeywords(os.Args[2:])
+		case "import":
+			return runImport(os.Args[2:])
+		case "integrations":
+			return runIntegrations(os.Args[2:])
 		case "clean":
 			return runClean()
+		case "compact":
+			return runCompact()
+		case "rebuild":
+			return runRebuild(os.Args[2:])
 		case "stats":
 			return runStats()
+		case "verify":
+			return runVerify(os.Args[2:])
+		case "lint":
+			return runLint(os.Args[2:])
+		case "plugins":
+			return runPlugins(os.Args[2:])
 		case "doctor":
 			return runDoctor()
 		case "config":
@@ -105,13 +164,37 @@ Usage:
   mindcli reindex      Re-index everything (ignores unchanged-file checks)
   mindcli watch        Watch for file changes and re-index
   mindcli search "..." Search and print results
-  mindcli export "..." Export search results (--format json|csv|markdown)
-  mindcli ask "..."    Ask a question (RAG answer via Ollama)
-  mindcli tag ...      Manage document tags (add, remove, list)
+  mindcli similar ...  Find notes related to a passage (--text "...", --file path, or stdin)
+  mindcli grep ...     Find a term within one document (<doc-path> "term", -C N for context)
+  mindcli keywords ... List the key words and phrases of one document (<doc-path>, -n N, --tag to store as tags)
+  mindcli export "..." Export search results (--format json|csv|markdown|anki|alfred, --collection, --doc, --assets dir)
+  mindcli ask "..."    Ask a question (RAG answer via Ollama; --output file, --collection, --doc, --save, --fresh, --verbose, --include-source, --show-context, --min-relevance)
+  mindcli eval         Sweep hybrid weights against labeled queries (--qrels file)
+  mindcli tag ...      Manage document tags (add, remove, list, export, import)
+  mindcli import chat  Import a chat export as notes (--format whatsapp|telegram|slack, --per-day)
+  mindcli import pocket  Import saved articles as notes, queueing unread ones (also instapaper, omnivore)
+  mindcli import kindle  Import book highlights as notes, one per book (also readwise)
   mindcli clipboard    Manage clipboard index (clear, cleanup)
-  mindcli collection   Manage collections (create, delete, list, show, add, remove, rename)
-  mindcli clean        Remove documents whose files no longer exist
+  mindcli collection   Manage collections (create, delete, list, show, add, remove, rename, export, import)
+  mindcli alias        Name documents to jump to (add, remove, list)
+  mindcli open <name>  Open a document by alias, path or part of its title (--print to print its path)
+  mindcli recent       List documents you opened recently (--indexed for recently indexed, -n N)
+  mindcli random       Show a random document (--tag x, --older-than days, --open)
+  mindcli onthisday    List documents from today's date in earlier years (--date YYYY-MM-DD)
+  mindcli contacts     List the people you email most, with message counts (--limit N)
+  mindcli digest       List digests, or print one (<name>, --send to deliver it now)
+  mindcli errors       Show or clear files that failed to index (list, clear)
+  mindcli snapshot     Manage database snapshots (create, list, restore)
+  mindcli sync         Sync tags and collections with other machines (push, pull)
+  mindcli bench        Benchmark indexing and search on a throwaway index (--corpus dir)
+  mindcli clean        Remove documents whose files are gone or expired; trim clips over budget
+  mindcli rebuild      Back up a damaged database or search index and rebuild it
+  mindcli compact      Shrink the database, search index and embedding cache, showing the space saved
   mindcli stats        Show index statistics
+  mindcli verify       Cross-check the database, search index and vectors (--repair to fix)
+  mindcli lint         Report broken wiki links, orphan notes and near-empty documents (--min-words N)
+  mindcli plugins      List exporter and post-processor plugins
+  mindcli integrations Install a launcher integration (install raycast|alfred, --dir, --binary, --keyword)
   mindcli doctor       Check configuration and service health
   mindcli config       Initialize config file
   mindcli version      Show version info
@@ -121,6 +204,10 @@ Index options:
   -paths string        Comma-separated paths to index (overrides config)
   -watch               Watch for file changes after indexing
   -force               Re-index everything, ignoring unchanged-file checks
+  -idle-only           Pause indexing while the machine is busy (also for watch)
+  -retry-failed        Only re-attempt files listed by 'mindcli errors list'
+  -low-memory          Use one worker and smaller memory and batch limits (also for reindex and watch)
+  -yes                 Index new directories over indexing.confirm without asking (also for reindex)
 
 Examples:
   mindcli                                      # Start TUI
@@ -129,13 +216,41 @@ Examples:
   mindcli index -watch                         # Index then watch for changes
   mindcli reindex                              # Full rebuild (e.g. after model change)
   mindcli search "Go concurrency"               # Search without TUI
+  mindcli search --limit 5 "Go"                 # Limit results (default: search.results_limit)
+  mindcli search --weight 0.8 "Go"              # Favor semantic matches for one query
+  mindcli search --semantic "staying focused"   # Vector similarity only, with matching passages
+  pbpaste | mindcli similar                     # Find your notes related to copied text
+  mindcli search "exact: ERR_CONN_RESET"        # Keyword-only search (semantic: for vectors only)
+  mindcli search --sources markdown,pdf "Go"    # Only search notes and PDFs
+  mindcli search "roadmap path:~/notes/alpha"   # Only search one folder (or --path-prefix dir)
+  mindcli search --facets "roadmap"             # Also count matches per source, tag and month
+  mindcli grep ~/notes/go.md "channel"          # Show the lines of one note that mention a term
+  mindcli keywords ~/papers/rag.pdf             # What a long document is about, at a glance
   mindcli export "Go" --format csv             # Export results as CSV
   mindcli export "Go" --output results.json    # Export to file
+  mindcli export "Go" --format org             # Export with the export-org plugin
+  mindcli export --doc ~/notes/go.md --assets out/ # Export a note with copies of its images
+  mindcli export --format anki "#study" > cards.txt # Export Q:/A: study cards for Anki
   mindcli ask "what did I write about Go?"     # Ask a question
+  mindcli ask --save "how do I deploy?"        # Ask and save the answer as an indexed note
+  mindcli eval --qrels queries.tsv              # Recommend a search.hybrid_weight for your notes
+  mindcli bench --docs 2000                     # Time indexing and BM25/vector/hybrid search
+  mindcli import chat --format slack export.zip # Import Slack channels as searchable notes
+  mindcli import pocket part_000000.csv         # Import Pocket saves; unread ones join the "Reading queue" collection
+  mindcli import kindle "My Clippings.txt"      # Import Kindle highlights as one note per book
   mindcli clipboard clear                       # Remove all clipboard documents from index
   mindcli clipboard cleanup                     # Remove old clipboard documents by retention policy
+  mindcli snapshot restore <name>               # Roll the database back to a snapshot
+  mindcli compact                              # Reclaim disk space after removing many documents
+  mindcli sync                                  # Exchange tags and collections via sync.remote
   mindcli collection create "reading-list"   # Create a collection
-  mindcli collection list                    # List all collections`)
+  mindcli collection list                    # List all collections
+  mindcli alias add standup ~/notes/standup.md # Type "standup" in the TUI to jump there
+  mindcli open standup                       # Open the aliased document
+  mindcli digest --send weekly-inbox         # Deliver a digest now instead of waiting for watch
+  mindcli tag export --output tags.json       # Save manual tags as portable JSON
+  mindcli collection import collections.json # Restore collections after a reindex
+  mindcli integrations install alfred        # Write an Alfred workflow: type "kb <query>" to search`)
 }
 
 func loadConfig() (*config.Config, error) {
@@ -167,19 +282,21 @@ type openOpts struct {
 }
 
 // stores holds the open handles shared across commands. Always includes the
-// config, data dir, database, and Bleve search index; optional members may be
+// config, data dir, database, and full-text search index; optional members may be
 // nil depending on openOpts and availability (semantic search degrades
 // gracefully).
 type stores struct {
 	cfg      *config.Config
 	dataDir  string
 	db       *storage.DB
-	bleve    *search.BleveIndex
+	search   search.Backend
 	vectors  *storage.VectorStore
 	embedder embeddings.Embedder
-	cached   *embeddings.CachedEmbedder
+	caches   []*embeddings.CachedEmbedder
 	llm      *query.LLMClient
+	parser   *query.LLMParser // nil unless query.llm_parsing is on
 	hybrid   *query.HybridSearcher
+	hooks    *notify.Hooks // started by the first newIndexer call
 }
 
 // openStores opens the database and search index, then optionally wires up the
@@ -198,20 +315,26 @@ func openStores(opts openOpts) (*stores, error) {
 	if err != nil {
 		return nil, fmt.Errorf("getting database path: %w", err)
 	}
-	db, err := storage.Open(dbPath)
+	db, err := storage.OpenWithSnapshots(dbPath, snapshotPolicy(cfg))
 	if err != nil {
-		return nil, fmt.Errorf("opening database: %w", err)
+		if db, err = openReadOnly(dbPath, err, opts.indexing); err != nil {
+			return nil, err
+		}
+	}
+	if err := db.SetPathRoots(context.Background(), cfg.PathRoots()); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("applying path roots: %w", err)
 	}
 
 	s := &stores{cfg: cfg, dataDir: dataDir, db: db}
 
-	indexPath := filepath.Join(dataDir, "search.bleve")
-	bleve, err := search.NewBleveIndex(indexPath)
+	textIndex, err := openSearchBackend(cfg.Search.Backend, dataDir, dbPath)
 	if err != nil {
 		_ = db.Close()
-		return nil, fmt.Errorf("opening search index: %w", err)
+		return nil, fmt.Errorf("opening search index: %w\n%s", err, searchRecoveryHint)
 	}
-	s.bleve = bleve
+	s.search = textIndex
+	warnIfSearchIndexEmpty(db, textIndex, cfg.Search.Backend)
 
 	if opts.vectors {
 		s.openVectors(opts.indexing)
@@ -226,14 +349,59 @@ func openStores(opts openOpts) (*stores, error) {
 		case "openai":
 			s.llm = query.NewOpenAILLMClient(cfg.Embeddings.OpenAIKey, cfg.Embeddings.LLMModel)
 		}
+		if s.llm != nil {
+			s.llm.SetAnswerLength(cfg.Ask.AnswerLength)
+			if cfg.Query.LLMParsing {
+				s.parser = query.NewLLMParser(s.llm)
+			}
+		}
 	}
 	if opts.hybrid && s.vectors != nil && s.embedder != nil && s.vectors.Len() > 0 {
-		s.hybrid = query.NewHybridSearcher(s.bleve, s.vectors, s.embedder, s.db, cfg.Search.HybridWeight)
+		s.hybrid = query.NewHybridSearcher(s.search, s.vectors, s.embedder, s.db, cfg.Search.HybridWeight)
 	}
 
 	return s, nil
 }
 
+// newIndexer builds an indexer over s with vectors, index-time redaction,
+// post-processor plugins and the configured hooks.
+func (s *stores) newIndexer(vectors *storage.VectorStore) *index.Indexer {
+	indexer := index.NewIndexer(s.db, s.search, vectors, s.embedder, s.cfg)
+	indexer.SetRedactor(buildRedactor(s.cfg), s.cfg.Privacy.RedactContent)
+	indexer.SetPostProcessors(postProcessors(s.cfg))
+	h := s.cfg.Hooks
+	if h.OnDocumentIndexed.Enabled() || h.OnDocumentRemoved.Enabled() || h.OnIndexComplete.Enabled() {
+		if s.hooks == nil {
+			s.hooks = notify.NewHooks(h, func(event string, err error) {
+				fmt.Fprintf(os.Stderr, "warning: %s hook: %v\n", event, err)
+			})
+		}
+		indexer.SetEventHandler(s.hooks)
+	}
+	return indexer
+}
+
+// openSearchBackend opens the configured full-text index: a Bleve index in
+// the data dir, or an FTS5 table inside the SQLite database at dbPath.
+func openSearchBackend(backend, dataDir, dbPath string) (search.Backend, error) {
+	if backend == "fts5" {
+		return search.NewFTSIndex(dbPath)
+	}
+	return search.NewBleveIndex(filepath.Join(dataDir, "search.bleve"))
+}
+
+// warnIfSearchIndexEmpty points at a reindex when documents exist but the
+// full-text index has none, e.g. right after switching search.backend.
+func warnIfSearchIndexEmpty(db *storage.DB, textIndex search.Backend, backend string) {
+	n, err := textIndex.Count()
+	if err != nil || n > 0 {
+		return
+	}
+	if docs, _ := db.CountDocuments(context.Background()); docs > 0 {
+		fmt.Fprintf(os.Stderr, "warning: the %s search index is empty; run 'mindcli reindex' to rebuild it\n", backend)
+	}
+}
+
 // openVectors loads the vector store. In indexing mode it is always created
 // (so embeddings can be added); otherwise it is only loaded when a non-empty
 // graph already exists on disk.
@@ -271,45 +439,78 @@ func (s *stores) openVectors(indexing bool) {
 	s.vectors = vs
 }
 
-// openEmbedder sets up the embedder for the configured provider. In indexing
-// mode it tests connectivity and disables embeddings if the backend is down.
+// openEmbedder sets up the embedder for the configured provider, routing
+// the languages in embeddings.language_models to their own models. In
+// indexing mode it tests connectivity and disables embeddings if the backend
+// is down.
 func (s *stores) openEmbedder(indexing bool) {
-	var base embeddings.Embedder
-	switch s.cfg.Embeddings.Provider {
-	case "ollama":
-		base = embeddings.NewOllamaEmbedder(s.cfg.Embeddings.OllamaURL, s.cfg.Embeddings.Model)
-	case "openai":
-		base = embeddings.NewOpenAIEmbedder(s.cfg.Embeddings.OpenAIKey, s.cfg.Embeddings.Model)
-	default:
+	base := newEmbedder(s.cfg, s.cfg.Embeddings.Model)
+	if base == nil {
 		return
 	}
 
-	cachePath := filepath.Join(s.dataDir, "embeddings.db")
-	if cached, err := embeddings.NewCachedEmbedder(base, cachePath, s.cfg.Embeddings.Model); err != nil {
-		fmt.Fprintf(os.Stderr, "warning: embedding cache unavailable: %v\n", err)
-		s.embedder = base
-	} else {
-		s.cached = cached
-		s.embedder = cached
-	}
-
 	if indexing {
 		// Probe the backend so a misconfigured provider degrades to BM25-only
 		// rather than failing every document.
 		if _, err := base.Embed(context.Background(), "test"); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: embeddings unavailable (%s), skipping: %v\n", s.cfg.Embeddings.Provider, err)
-			s.embedder = nil
+			return
 		}
 	}
+	s.embedder = s.cacheEmbeddings(base, s.cfg.Embeddings.Model)
+	if len(s.cfg.Embeddings.LanguageModels) == 0 {
+		return
+	}
+
+	byLanguage := make(map[string]embeddings.Embedder, len(s.cfg.Embeddings.LanguageModels))
+	for code, model := range s.cfg.Embeddings.LanguageModels {
+		emb := newEmbedder(s.cfg, model)
+		if indexing {
+			if _, err := emb.Embed(context.Background(), "test"); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: embedding model %s unavailable, using %s for %s: %v\n", model, s.cfg.Embeddings.Model, code, err)
+				continue
+			}
+		}
+		byLanguage[code] = s.cacheEmbeddings(emb, model)
+	}
+	s.embedder = embeddings.NewLanguageRouter(s.embedder, byLanguage)
+}
+
+// cacheEmbeddings wraps emb in the embedding cache, scoped to model, or
+// returns it as is when the cache can't be opened.
+func (s *stores) cacheEmbeddings(emb embeddings.Embedder, model string) embeddings.Embedder {
+	cachePath := filepath.Join(s.dataDir, "embeddings.db")
+	cached, err := embeddings.NewCachedEmbedder(emb, cachePath, model)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: embedding cache unavailable: %v\n", err)
+		return emb
+	}
+	s.caches = append(s.caches, cached)
+	return cached
 }
 
 // Close releases all open handles.
+// newEmbedder returns an uncached embedder for model with the configured
+// provider, or nil when the provider is unknown.
+func newEmbedder(cfg *config.Config, model string) embeddings.Embedder {
+	switch cfg.Embeddings.Provider {
+	case "ollama":
+		return embeddings.NewOllamaEmbedder(cfg.Embeddings.OllamaURL, model)
+	case "openai":
+		return embeddings.NewOpenAIEmbedder(cfg.Embeddings.OpenAIKey, model)
+	}
+	return nil
+}
+
 func (s *stores) Close() {
 	if s == nil {
 		return
 	}
-	if s.cached != nil {
-		if err := s.cached.Close(); err != nil {
+	if s.hooks != nil {
+		s.hooks.Close()
+	}
+	for _, cached := range s.caches {
+		if err := cached.Close(); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: closing embedding cache: %v\n", err)
 		}
 	}
@@ -318,8 +519,8 @@ func (s *stores) Close() {
 			fmt.Fprintf(os.Stderr, "warning: closing vector store: %v\n", err)
 		}
 	}
-	if s.bleve != nil {
-		if err := s.bleve.Close(); err != nil {
+	if s.search != nil {
+		if err := s.search.Close(); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: closing search index: %v\n", err)
 		}
 	}
@@ -331,27 +532,36 @@ func (s *stores) Close() {
 }
 
 // searchResults runs a parsed query through the hybrid searcher when available,
-// falling back to Bleve-only. It is the single search entry point shared by the
+// falling back to full-text search only. It is the single search entry point shared by the
 // search, export, and ask commands.
 func searchResults(ctx context.Context, s *stores, parsed query.ParsedQuery, limit int) (storage.SearchResults, error) {
-	searchQ := parsed.SearchTerms
-	if parsed.SourceFilter != "" {
-		searchQ = searchQ + " source:" + parsed.SourceFilter
+	if parsed.SearchTerms == "" && len(parsed.MetadataFilters) > 0 {
+		docs, err := query.DocumentsByMetadata(ctx, s.db, parsed, limit)
+		if err != nil {
+			return nil, err
+		}
+		results := make(storage.SearchResults, 0, len(docs))
+		for _, doc := range docs {
+			results = append(results, &storage.SearchResult{Document: doc})
+		}
+		return query.FilterByTime(results, parsed, time.Now()), nil
 	}
 
+	searchQ := strings.TrimSpace(parsed.SearchTerms + " " + parsed.Filters())
+
 	var results storage.SearchResults
 	if s.hybrid != nil {
-		r, err := s.hybrid.Search(ctx, searchQ, limit)
+		r, err := s.hybrid.SearchWithWeight(ctx, searchQ, parsed.SearchLimit(limit), parsed.Weight(s.hybrid.HybridWeight))
 		if err != nil {
 			return nil, err
 		}
 		results = r
 	} else {
-		bleveResults, err := s.bleve.Search(ctx, searchQ, limit)
+		textResults, err := s.search.Search(ctx, searchQ, parsed.SearchLimit(limit))
 		if err != nil {
 			return nil, err
 		}
-		for _, r := range bleveResults {
+		for _, r := range textResults {
 			doc, err := s.db.GetDocument(ctx, r.ID)
 			if err == nil && doc != nil {
 				results = append(results, &storage.SearchResult{
@@ -363,31 +573,31 @@ func searchResults(ctx context.Context, s *stores, parsed query.ParsedQuery, lim
 		}
 	}
 
+	results, err := query.FilterByMetadata(ctx, s.db, results, parsed)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
 	return query.FilterByTime(results, parsed, time.Now()), nil
 }
 
 func runTUI() error {
-	s, err := openStores(openOpts{vectors: true, embedder: true, llm: true, hybrid: true})
+	// Vectors and the embedder load in the background once the UI is up;
+	// searches are full-text only until they have.
+	s, err := openStores(openOpts{llm: true})
 	if err != nil {
 		return err
 	}
 	defer s.Close()
+	semantic := &lazySemantic{s: s}
+	defer semantic.settle()
 
 	redactor := buildRedactor(s.cfg)
 
-	// Build an indexer for the in-app "index now" action. Ensure a vector
-	// store exists so embeddings can be added on a first index.
-	vectors := s.vectors
-	if vectors == nil {
-		if vs, vErr := storage.NewVectorStore(filepath.Join(s.dataDir, "vectors.graph")); vErr == nil {
-			vs.SetModel(s.cfg.Embeddings.Model)
-			vectors = vs
-			defer func() { _ = vs.Close() }()
-		}
-	}
-	indexer := index.NewIndexer(s.db, s.bleve, vectors, s.embedder, s.cfg)
-	indexer.SetRedactor(redactor, s.cfg.Privacy.RedactContent)
 	reindex := func(ctx context.Context) (int, int, error) {
+		indexer := semantic.index()
 		stats, err := indexer.IndexAll(ctx)
 		if err != nil {
 			return 0, 0, err
@@ -396,7 +606,14 @@ func runTUI() error {
 		return int(stats.IndexedFiles), int(stats.Errors), saveErr
 	}
 
-	model := tui.New(s.db, s.bleve, s.hybrid, s.llm, redactor, reindex)
+	model := tui.New(s.db, s.search, nil, s.llm, redactor, reindex)
+	model.SetSemanticLoader(semantic.hybrid)
+	applyTUIConfig(&model, s.cfg)
+	model.SetConfigReloader(reloadTUIConfig)
+	model.SetQueryParser(s.parser)
+	model.SetAnswerSaver(func(ctx context.Context, t query.Transcript) (string, error) {
+		return saveTranscriptNote(ctx, s.cfg, semantic.index(), t)
+	})
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
@@ -406,45 +623,97 @@ func runTUI() error {
 	return nil
 }
 
-func runIndex(pathsOverride string, watch, force bool) error {
+func runIndex(pathsOverride string, watch, force, idleOnly, lowMemory, yes bool) error {
 	s, err := openStores(openOpts{vectors: true, embedder: true, indexing: true})
 	if err != nil {
 		return err
 	}
 	defer s.Close()
+	s.cfg.Indexing.LowMemory = s.cfg.Indexing.LowMemory || lowMemory
 
 	// Override paths if provided.
 	if pathsOverride != "" {
 		s.cfg.Sources.Markdown.Paths = parsePathsOverride(pathsOverride)
 	}
 
-	indexer := index.NewIndexer(s.db, s.bleve, s.vectors, s.embedder, s.cfg)
+	indexer := s.newIndexer(s.vectors)
 	indexer.SetForce(force)
-	indexer.SetRedactor(buildRedactor(s.cfg), s.cfg.Privacy.RedactContent)
-	indexer.SetProgressReporter(&consoleProgressReporter{})
+	progress := newConsoleProgressReporter()
+	indexer.SetProgressReporter(progress)
+	configureBackgroundIndexing(indexer, s.cfg, idleOnly)
 
 	ctx := context.Background()
-	stats, err := indexer.IndexAll(ctx)
-	if err != nil {
+	if !yes {
+		ok, err := confirmNewPaths(ctx, indexer, s.cfg.Indexing.Confirm, os.Stdin, os.Stdout, isTerminal(os.Stdin))
+		if !ok || err != nil {
+			return err
+		}
+	}
+	snapshotBeforeIndexing(ctx, s.db, force)
+
+	// An interrupted run still saves what it indexed, so the next one
+	// resumes from there.
+	indexCtx, stop := interruptible("Stopping after the files in progress (press Ctrl+C again to quit now)...")
+	stats, err := indexer.IndexAll(indexCtx)
+	interrupted := indexCtx.Err() != nil
+	stop()
+	if err != nil && !interrupted {
 		return fmt.Errorf("indexing: %w", err)
 	}
+	stats.Interrupted = stats.Interrupted || interrupted
 
 	if err := indexer.SaveVectors(); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: saving vectors: %v\n", err)
 	}
 
-	fmt.Printf("\nIndexing complete:\n")
-	fmt.Printf("  Total files:   %d\n", stats.TotalFiles)
-	fmt.Printf("  Indexed:       %d\n", stats.IndexedFiles)
-	fmt.Printf("  Errors:        %d\n", stats.Errors)
+	vectors := -1
 	if s.embedder != nil && s.vectors != nil {
-		fmt.Printf("  Vectors:       %d\n", s.vectors.Len())
+		vectors = s.vectors.Len()
+	}
+	progress.printSummary(os.Stdout, stats, vectors)
+	if interrupted {
+		return errors.New("indexing interrupted")
 	}
 
 	if watch {
-		return startWatching(indexer, s.cfg)
+		return startWatching(indexer)
+	}
+
+	return nil
+}
+
+// runRetryFailed re-indexes only the files recorded as failed by earlier runs.
+func runRetryFailed(idleOnly, lowMemory bool) error {
+	s, err := openStores(openOpts{vectors: true, embedder: true, indexing: true})
+	if err != nil {
+		return err
 	}
+	defer s.Close()
+	s.cfg.Indexing.LowMemory = s.cfg.Indexing.LowMemory || lowMemory
 
+	indexer := s.newIndexer(s.vectors)
+	progress := newConsoleProgressReporter()
+	indexer.SetProgressReporter(progress)
+	configureBackgroundIndexing(indexer, s.cfg, idleOnly)
+
+	stats, err := indexer.RetryFailed(context.Background())
+	if err != nil {
+		return fmt.Errorf("retrying failed files: %w", err)
+	}
+	if stats.TotalFiles == 0 {
+		fmt.Println("No failed files to retry.")
+		return nil
+	}
+
+	if err := indexer.SaveVectors(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: saving vectors: %v\n", err)
+	}
+
+	vectors := -1
+	if s.embedder != nil && s.vectors != nil {
+		vectors = s.vectors.Len()
+	}
+	progress.printSummary(os.Stdout, stats, vectors)
 	return nil
 }
 
@@ -461,28 +730,47 @@ func parsePathsOverride(pathsOverride string) []string {
 	return paths
 }
 
-func runWatch() error {
-	s, err := openStores(openOpts{vectors: true, embedder: true, indexing: true})
+func runWatch(idleOnly, lowMemory bool) error {
+	s, err := openStores(openOpts{vectors: true, embedder: true, indexing: true, hybrid: true})
 	if err != nil {
 		return err
 	}
 	defer s.Close()
+	s.cfg.Indexing.LowMemory = s.cfg.Indexing.LowMemory || lowMemory
 
-	indexer := index.NewIndexer(s.db, s.bleve, s.vectors, s.embedder, s.cfg)
-	indexer.SetRedactor(buildRedactor(s.cfg), s.cfg.Privacy.RedactContent)
-	return startWatching(indexer, s.cfg)
+	digests, err := configuredDigests(s.cfg)
+	if err != nil {
+		return err
+	}
+
+	indexer := s.newIndexer(s.vectors)
+	configureBackgroundIndexing(indexer, s.cfg, idleOnly)
+	stopDigests := startDigests(s, digests)
+	defer stopDigests()
+	return startWatching(indexer)
 }
 
-func startWatching(indexer *index.Indexer, cfg *config.Config) error {
-	var paths []string
-	if cfg.Sources.Markdown.Enabled {
-		paths = append(paths, cfg.Sources.Markdown.Paths...)
+// configureBackgroundIndexing applies the low-priority and idle-only settings
+// for long-running indexing.
+func configureBackgroundIndexing(indexer *index.Indexer, cfg *config.Config, idleOnly bool) {
+	if cfg.Indexing.Throttle.LowPriority {
+		if err := index.LowerPriority(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: lowering priority: %v\n", err)
+		}
 	}
-	if cfg.Sources.PDF.Enabled {
-		paths = append(paths, cfg.Sources.PDF.Paths...)
+	if idleOnly {
+		if !index.IdleDetectionSupported() {
+			fmt.Fprintln(os.Stderr, "warning: --idle-only is not supported on this platform; indexing will not pause")
+			return
+		}
+		indexer.SetIdleOnly(index.SystemIdle)
 	}
+}
 
-	if len(paths) == 0 {
+func startWatching(indexer *index.Indexer) error {
+	paths := indexer.WatchPaths()
+	pollers := indexer.Pollers()
+	if len(paths) == 0 && len(pollers) == 0 {
 		return fmt.Errorf("no paths to watch")
 	}
 
@@ -491,10 +779,13 @@ func startWatching(indexer *index.Indexer, cfg *config.Config) error {
 		return fmt.Errorf("creating watcher: %w", err)
 	}
 
-	fmt.Printf("Watching %d directories for changes (Ctrl+C to stop)...\n", len(paths))
+	fmt.Printf("Watching %d paths for changes (Ctrl+C to stop, SIGHUP to reload the config)...\n", len(paths))
 	for _, p := range paths {
 		fmt.Printf("  %s\n", p)
 	}
+	for _, src := range pollers {
+		fmt.Printf("  %s (polled every %s)\n", src.Name(), src.(sources.Poller).PollInterval())
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -507,62 +798,169 @@ func startWatching(indexer *index.Indexer, cfg *config.Config) error {
 		fmt.Println("\nStopping watcher...")
 		cancel()
 	}()
+	reloadOnHangup(ctx, indexer)
 
 	return watcher.Start(ctx)
 }
 
-func runSearch(queryStr string) error {
+// resultsLimit returns the per-command --limit override when set, else the
+// configured search.results_limit.
+func resultsLimit(cfg *config.Config, override int) int {
+	if override > 0 {
+		return override
+	}
+	return cfg.Search.ResultsLimit
+}
+
+// applyWeight sets a --weight override on the parsed query. A negative
+// weight means the flag wasn't given.
+func applyWeight(parsed *query.ParsedQuery, weight float64) error {
+	if weight < 0 {
+		return nil
+	}
+	if weight > 1 {
+		return fmt.Errorf("--weight must be between 0 and 1")
+	}
+	parsed.HybridWeight = &weight
+	return nil
+}
+
+// applySources restricts the parsed query to a --sources list, replacing
+// any source named in the query itself. An empty list leaves it unchanged.
+func applySources(parsed *query.ParsedQuery, list string) error {
+	sources, err := storage.ParseSources(list)
+	if err != nil {
+		return fmt.Errorf("--sources: %w", err)
+	}
+	if len(sources) > 0 {
+		parsed.SourceFilter = storage.JoinSources(sources)
+	}
+	return nil
+}
+
+func runSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	limit := fs.Int("limit", 0, "Maximum number of results (default: search.results_limit)")
+	weight := fs.Float64("weight", -1, "Hybrid weight for this query: 0 = BM25 only, 1 = vectors only (default: search.hybrid_weight)")
+	sourceList := fs.String("sources", "", "Only search these sources, comma-separated (e.g. markdown,pdf)")
+	pathPrefix := fs.String("path-prefix", "", "Only search documents in this directory or below it")
+	semantic := fs.Bool("semantic", false, "Rank by vector similarity only and show the matching passage (like a semantic: prefix)")
+	facets := fs.Bool("facets", false, "Also print how many documents match per source, tag and month")
+	_ = fs.Parse(args)
+
+	queryStr := strings.Join(fs.Args(), " ")
+	if queryStr == "" {
+		return fmt.Errorf("usage: mindcli search [--limit N] [--weight W | --semantic] [--sources list] [--path-prefix dir] [--facets] \"query\"")
+	}
+	if *semantic {
+		*weight = 1
+	}
+	parsed := query.ParseQuery(queryStr)
+	if err := applyWeight(&parsed, *weight); err != nil {
+		return err
+	}
+	if err := applySources(&parsed, *sourceList); err != nil {
+		return err
+	}
+	if *pathPrefix != "" {
+		parsed.PathPrefix = query.NormalizePathPrefix(*pathPrefix)
+	}
+
 	s, err := openStores(openOpts{vectors: true, embedder: true, hybrid: true})
 	if err != nil {
 		return err
 	}
 	defer s.Close()
 
-	parsed := query.ParseQuery(queryStr)
+	semanticOnly := parsed.Weight(s.cfg.Search.HybridWeight) >= 1
+	if semanticOnly && s.hybrid == nil {
+		fmt.Fprintln(os.Stderr, "warning: no vector index (run 'mindcli index' with Ollama/OpenAI available); showing keyword results")
+	}
+
 	ctx := context.Background()
-	results, err := searchResults(ctx, s, parsed, s.cfg.Search.ResultsLimit)
+	results, err := searchResults(ctx, s, parsed, resultsLimit(s.cfg, *limit))
 	if err != nil {
 		return fmt.Errorf("searching: %w", err)
 	}
 
 	if len(results) == 0 {
 		fmt.Println("No results found.")
+		printSuggestions(os.Stdout, suggestAlternatives(ctx, s, parsed, resultsLimit(s.cfg, *limit)))
 		return nil
 	}
 
-	redactor := buildRedactor(s.cfg)
+	printSearchResults(os.Stdout, results, buildRedactor(s.cfg), semanticOnly, s.cfg.Display.SnippetLength)
+	if *facets {
+		f, err := searchFacets(ctx, s, parsed)
+		if err != nil {
+			return fmt.Errorf("counting facets: %w", err)
+		}
+		printFacets(os.Stdout, f)
+	}
+	return nil
+}
+
+// printSearchResults prints numbered results with a preview of each. With
+// passages set, vector-only results show the passage that matched instead.
+func printSearchResults(w io.Writer, results storage.SearchResults, redactor privacy.Redactor, passages bool, snippetLen int) {
 	for i, r := range results {
 		doc := r.Document
+		if passages && r.ChunkID != "" && len(r.Highlights) > 0 {
+			fmt.Fprintf(w, "%d. %s\n   %s [%s] (similarity: %.2f)\n   > %s\n\n",
+				i+1, doc.Title, doc.Path, sourceLabel(doc), r.VectorScore, passage(redactor.Redact(r.Highlights[0]), snippetLen))
+			continue
+		}
 		preview := doc.Preview
-		if preview == "" && len(doc.Content) > 100 {
-			preview = doc.Content[:100] + "..."
-		} else if preview == "" {
-			preview = doc.Content
+		if preview == "" {
+			preview = passage(doc.Content, snippetLen)
 		}
 		preview = redactor.Redact(preview)
-		fmt.Printf("%d. %s\n   %s [%s] (score: %.2f)\n   %s\n\n",
-			i+1, doc.Title, doc.Path, doc.Source, r.Score, preview)
+		fmt.Fprintf(w, "%d. %s\n   %s [%s] (score: %.2f)\n   %s\n\n",
+			i+1, doc.Title, doc.Path, sourceLabel(doc), r.Score, preview)
 	}
+}
 
-	return nil
+// sourceLabel names a result's source, and for notes in a vault the vault,
+// as it is searched for: "markdown vault:work".
+func sourceLabel(doc *storage.Document) string {
+	if vault := doc.Metadata["vault"]; vault != "" {
+		return string(doc.Source) + " vault:" + vault
+	}
+	return string(doc.Source)
+}
+
+// passage collapses whitespace in text and shortens it to about limit
+// characters, ending at a sentence or word boundary.
+func passage(text string, limit int) string {
+	return chunker.Truncate(strings.Join(strings.Fields(text), " "), limit)
 }
 
 func runExport(args []string) error {
 	fs := flag.NewFlagSet("export", flag.ExitOnError)
-	format := fs.String("format", "json", "Output format: json, csv, markdown")
+	format := fs.String("format", "json", "Output format: json, csv, markdown, anki, alfred, or one added by a plugin")
 	output := fs.String("output", "", "Output file (default: stdout)")
-	limit := fs.Int("limit", 50, "Maximum number of results")
+	limit := fs.Int("limit", 0, "Maximum number of results (default: search.results_limit)")
+	weight := fs.Float64("weight", -1, "Hybrid weight for this query: 0 = BM25 only, 1 = vectors only (default: search.hybrid_weight)")
+	sourceList := fs.String("sources", "", "Only search these sources, comma-separated (e.g. markdown,pdf)")
+	pathPrefix := fs.String("path-prefix", "", "Only search documents in this directory or below it")
+	collection := fs.String("collection", "", "Export the documents of a collection instead of search results")
+	docPath := fs.String("doc", "", "Export one document, by alias or path, instead of search results")
+	assetsDir := fs.String("assets", "", "Copy the images and files the exported notes reference into this directory")
 	_ = fs.Parse(args)
 
 	queryStr := strings.Join(fs.Args(), " ")
-	if queryStr == "" {
-		return fmt.Errorf("usage: mindcli export \"query\" [--format json|csv|markdown] [--output file] [--limit N]")
+	if (queryStr == "") == (*collection == "" && *docPath == "") || (*collection != "" && *docPath != "") {
+		return fmt.Errorf("usage: mindcli export \"query\" | --collection name | --doc path [--format json|csv|markdown|anki|alfred|<plugin>] [--output file] [--assets dir] [--limit N] [--weight W] [--sources list] [--path-prefix dir]")
 	}
-
-	switch *format {
-	case "json", "csv", "markdown":
-	default:
-		return fmt.Errorf("unsupported format %q: use json, csv, or markdown", *format)
+	parsed := query.ParseQuery(queryStr)
+	if err := applyWeight(&parsed, *weight); err != nil {
+		return err
+	}
+	if err := applySources(&parsed, *sourceList); err != nil {
+		return err
+	}
+	if *pathPrefix != "" {
+		parsed.PathPrefix = query.NormalizePathPrefix(*pathPrefix)
 	}
 
 	s, err := openStores(openOpts{vectors: true, embedder: true, hybrid: true})
@@ -571,16 +969,54 @@ func runExport(args []string) error {
 	}
 	defer s.Close()
 
-	parsed := query.ParseQuery(queryStr)
+	var exporter *plugins.Exporter
+	if !slices.Contains(builtinFormats, *format) {
+		p, err := loadPlugins(s.cfg)
+		if err != nil {
+			return err
+		}
+		if exporter = p.Exporters[*format]; exporter == nil {
+			return fmt.Errorf("unsupported format %q: use %s", *format, exportFormats(p))
+		}
+	}
+
 	ctx := context.Background()
-	results, err := searchResults(ctx, s, parsed, *limit)
+	var results storage.SearchResults
+	switch {
+	case *collection != "":
+		queryStr = *collection
+		results, err = collectionResults(ctx, s, *collection, resultsLimit(s.cfg, *limit))
+	case *docPath != "":
+		var doc *storage.Document
+		if doc, err = resolveAliasOrPath(s.db, *docPath); err == nil {
+			queryStr = doc.Title
+			results = storage.SearchResults{{Document: doc}}
+		}
+	default:
+		results, err = searchResults(ctx, s, parsed, resultsLimit(s.cfg, *limit))
+		if err != nil {
+			err = fmt.Errorf("searching: %w", err)
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("searching: %w", err)
+		return err
 	}
-	if len(results) == 0 {
+	if len(results) == 0 && *format != "alfred" {
 		return fmt.Errorf("no results found for %q", queryStr)
 	}
 
+	if *assetsDir != "" {
+		base := ""
+		if *output != "" {
+			base = filepath.Dir(*output)
+		}
+		n, err := copyAssets(ctx, s.db, results, *assetsDir, base)
+		if err != nil {
+			return fmt.Errorf("copying assets: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Copied %d assets to %s\n", n, *assetsDir)
+	}
+
 	redactor := buildRedactor(s.cfg)
 
 	// Determine output writer.
@@ -603,6 +1039,15 @@ func runExport(args []string) error {
 		exportErr = exportCSV(w, results, redactor)
 	case "markdown":
 		exportErr = exportMarkdown(w, results, redactor)
+	case "anki":
+		exportErr = exportAnki(w, results, redactor)
+	case "alfred":
+		exportErr = exportAlfred(w, results, redactor)
+	default:
+		var buf bytes.Buffer
+		if exportErr = exportJSON(&buf, results, redactor); exportErr == nil {
+			exportErr = exporter.Export(ctx, w, queryStr, buf.Bytes())
+		}
 	}
 	if outputFile != nil {
 		if exportErr != nil {
@@ -616,9 +1061,72 @@ func runExport(args []string) error {
 	return exportErr
 }
 
+// collectionResults returns the documents of a collection as results: its
+// members, or for a smart collection, the results of its saved query.
+func collectionResults(ctx context.Context, s *stores, name string, limit int) (storage.SearchResults, error) {
+	col, err := s.db.GetCollectionByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("collection not found: %s", name)
+	}
+	if strings.TrimSpace(col.Query) != "" {
+		results, err := searchResults(ctx, s, query.ParseQuery(col.Query), limit)
+		if err != nil {
+			return nil, fmt.Errorf("searching: %w", err)
+		}
+		return results, nil
+	}
+	docs, err := s.db.GetCollectionDocuments(ctx, col.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing collection documents: %w", err)
+	}
+	results := make(storage.SearchResults, len(docs))
+	for i, doc := range docs {
+		results[i] = &storage.SearchResult{Document: doc}
+	}
+	return results, nil
+}
+
+// scopeSearchLimit is how many search results are looked through for the
+// documents of a collection when a question is scoped to it.
+const scopeSearchLimit = 200
+
+// scopedResults searches for parsed among the documents of a collection,
+// best ranked first. When none of them match the search, the collection's
+// documents are returned as they are, so a question about the collection as
+// a whole still has context to go on.
+func scopedResults(ctx context.Context, s *stores, parsed query.ParsedQuery, name string, limit int) (storage.SearchResults, error) {
+	members, err := collectionResults(ctx, s, name, scopeSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+	inScope := make(map[string]bool, len(members))
+	for _, r := range members {
+		inScope[r.Document.ID] = true
+	}
+
+	results, err := searchResults(ctx, s, parsed, scopeSearchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("searching: %w", err)
+	}
+	var scoped storage.SearchResults
+	for _, r := range results {
+		if len(scoped) == limit {
+			break
+		}
+		// A heading section belongs to the collection its note is in.
+		if inScope[r.Document.ID] || (r.Document.IsSection() && inScope[r.Document.Metadata["section_of"]]) {
+			scoped = append(scoped, r)
+		}
+	}
+	if len(scoped) == 0 {
+		scoped = members[:min(limit, len(members))]
+	}
+	return scoped, nil
+}
+
 func runTag(args []string) error {
 	if len(args) == 0 {
-		return fmt.Errorf("usage: mindcli tag <add|remove|list> [args...]")
+		return fmt.Errorf("usage: mindcli tag <add|remove|list|export|import> [args...]")
 	}
 
 	s, err := openStores(openOpts{})
@@ -691,8 +1199,14 @@ func runTag(args []string) error {
 			}
 		}
 
+	case "export":
+		return runCurationExport(ctx, db, "tag", args[1:], exportTags)
+
+	case "import":
+		return runCurationImport(ctx, db, "tag", args[1:], importTags)
+
 	default:
-		return fmt.Errorf("unknown tag subcommand %q: use add, remove, or list", args[0])
+		return fmt.Errorf("unknown tag subcommand %q: use add, remove, list, export, or import", args[0])
 	}
 
 	return nil
@@ -700,7 +1214,7 @@ func runTag(args []string) error {
 
 func runCollection(args []string) error {
 	if len(args) == 0 {
-		return fmt.Errorf("usage: mindcli collection <create|delete|list|show|add|remove|rename> [args...]")
+		return fmt.Errorf("usage: mindcli collection <create|delete|list|show|add|remove|rename|export|import> [args...]")
 	}
 
 	// Open search subsystems too so "show" can execute saved queries.
@@ -839,8 +1353,14 @@ func runCollection(args []string) error {
 		}
 		fmt.Printf("Renamed collection %q to %q\n", args[1], args[2])
 
+	case "export":
+		return runCurationExport(ctx, db, "collection", args[1:], exportCollections)
+
+	case "import":
+		return runCurationImport(ctx, db, "collection", args[1:], importCollections)
+
 	default:
-		return fmt.Errorf("unknown collection subcommand %q: use create, delete, list, show, add, remove, or rename", args[0])
+		return fmt.Errorf("unknown collection subcommand %q: use create, delete, list, show, add, remove, rename, export, or import", args[0])
 	}
 
 	return nil
@@ -858,14 +1378,14 @@ func runClipboard(args []string) error {
 	defer s.Close()
 
 	ctx := context.Background()
-	docs, err := s.db.ListDocuments(ctx, storage.SourceClipboard)
+	docs, err := s.db.ListDocumentSummaries(ctx, storage.SourceClipboard, 0, 0)
 	if err != nil {
 		return fmt.Errorf("listing clipboard documents: %w", err)
 	}
 
 	switch args[0] {
 	case "clear":
-		removed, err := purgeClipboardDocuments(ctx, s.db, s.bleve, s.vectors, docs, func(*storage.Document) bool { return true })
+		removed, err := purgeClipboardDocuments(ctx, s.db, s.search, s.vectors, docs, func(*storage.Document) bool { return true })
 		if err != nil {
 			return err
 		}
@@ -874,7 +1394,7 @@ func runClipboard(args []string) error {
 
 	case "cleanup":
 		cutoff := time.Now().AddDate(0, 0, -s.cfg.Sources.Clipboard.RetentionDays)
-		removed, err := purgeClipboardDocuments(ctx, s.db, s.bleve, s.vectors, docs, func(doc *storage.Document) bool {
+		removed, err := purgeClipboardDocuments(ctx, s.db, s.search, s.vectors, docs, func(doc *storage.Document) bool {
 			return doc.ModifiedAt.Before(cutoff)
 		})
 		if err != nil {
@@ -888,10 +1408,57 @@ func runClipboard(args []string) error {
 	}
 }
 
+func runErrors(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: mindcli errors <list|clear>")
+	}
+
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	switch args[0] {
+	case "list":
+		errs, err := s.db.ListIndexErrors(ctx)
+		if err != nil {
+			return err
+		}
+		printIndexErrors(os.Stdout, errs)
+		return nil
+
+	case "clear":
+		n, err := s.db.ClearIndexErrors(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Cleared %d index errors.\n", n)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown errors subcommand %q: use list or clear", args[0])
+	}
+}
+
+// printIndexErrors writes recorded indexing failures, most recent first.
+func printIndexErrors(w io.Writer, errs []*storage.IndexError) {
+	if len(errs) == 0 {
+		fmt.Fprintln(w, "No index errors.")
+		return
+	}
+	fmt.Fprintf(w, "%d files failed to index (retry with 'mindcli index -retry-failed'):\n\n", len(errs))
+	for _, e := range errs {
+		fmt.Fprintf(w, "  %s  %-9s %s\n", e.FailedAt.Local().Format("2006-01-02 15:04"), e.Source, e.Path)
+		fmt.Fprintf(w, "      %s\n", e.Error)
+	}
+}
+
 func purgeClipboardDocuments(
 	ctx context.Context,
 	db *storage.DB,
-	searchIndex *search.BleveIndex,
+	searchIndex search.Backend,
 	vectors *storage.VectorStore,
 	docs []*storage.Document,
 	shouldDelete func(*storage.Document) bool,
@@ -921,84 +1488,303 @@ func purgeClipboardDocuments(
 	return removed, nil
 }
 
-func runAsk(question string) error {
-	s, err := openStores(openOpts{vectors: true, embedder: true, llm: true, hybrid: true})
+func runAsk(args []string) error {
+	fs := flag.NewFlagSet("ask", flag.ExitOnError)
+	output := fs.String("output", "", "Save the answer and its sources to a markdown file")
+	save := fs.Bool("save", false, "Save the answer as a note in the notes folder and index it")
+	limit := fs.Int("limit", 0, "Maximum number of documents to retrieve (default: search.results_limit)")
+	weight := fs.Float64("weight", -1, "Hybrid weight for this query: 0 = BM25 only, 1 = vectors only (default: search.hybrid_weight)")
+	sourceList := fs.String("sources", "", "Only search these sources, comma-separated (e.g. markdown,pdf)")
+	pathPrefix := fs.String("path-prefix", "", "Only search documents in this directory or below it")
+	fresh := fs.Bool("fresh", false, "Generate a new answer even if this question was answered before")
+	verbose := fs.Bool("verbose", false, "Print how long retrieval and generation took")
+	collection := fs.String("collection", "", "Only retrieve from the documents of this collection")
+	docPath := fs.String("doc", "", "Answer from one document, by alias or path, without retrieval")
+	include := fs.String("include-source", "", "Use these sources in the answer despite ask.excluded_sources, comma-separated (e.g. browser)")
+	showContext := fs.Bool("show-context", false, "Print the prompt sent to the LLM, with the full context, to stderr")
+	minRelevance := fs.Float64("min-relevance", -1, "Relevance the best match needs for an answer, 0 to 1 (default: ask.min_relevance)")
+	_ = fs.Parse(args)
+
+	question := strings.Join(fs.Args(), " ")
+	if question == "" || (*collection != "" && *docPath != "") {
+		return fmt.Errorf("usage: mindcli ask [--collection name | --doc path] [--output file] [--save] [--fresh] [--verbose] [--limit N] [--weight W] [--sources list] [--include-source list] [--path-prefix dir] [--show-context] [--min-relevance R] \"your question\"")
+	}
+	if *minRelevance > 1 {
+		return fmt.Errorf("--min-relevance must be between 0 and 1")
+	}
+	included, err := storage.ParseSources(*include)
 	if err != nil {
+		return fmt.Errorf("--include-source: %w", err)
+	}
+	parsed := query.ParseQuery(question)
+	if err := applyWeight(&parsed, *weight); err != nil {
 		return err
 	}
-	defer s.Close()
+	if err := applySources(&parsed, *sourceList); err != nil {
+		return err
+	}
+	if *pathPrefix != "" {
+		parsed.PathPrefix = query.NormalizePathPrefix(*pathPrefix)
+	}
 
-	parsed := query.ParseQuery(question)
-	ctx := context.Background()
-	results, err := searchResults(ctx, s, parsed, 10)
+	s, err := openStores(openOpts{vectors: true, embedder: true, llm: true, hybrid: true})
 	if err != nil {
-		return fmt.Errorf("searching: %w", err)
+		return err
 	}
+	defer s.Close()
 
-	docs := make([]*storage.Document, 0, len(results))
-	for _, r := range results {
-		docs = append(docs, r.Document)
+	// --doc answers from one document, so it is resolved before anything
+	// else: a wrong path should fail before the cache or the LLM is used.
+	var doc *storage.Document
+	scope := ""
+	switch {
+	case *docPath != "":
+		if doc, err = resolveAliasOrPath(s.db, *docPath); err != nil {
+			return err
+		}
+		scope = "doc:" + doc.ID
+	case *collection != "":
+		scope = "collection:" + *collection
 	}
 
-	if len(docs) == 0 {
-		fmt.Println("No relevant documents found.")
-		return nil
-	}
+	ctx := context.Background()
+	n := resultsLimit(s.cfg, *limit)
+	timings := newPhaseTimings()
+	if *verbose {
+		defer func() { fmt.Fprintf(os.Stderr, "\nTimings: %s\n", timings) }()
+	}
+	// The spinner only shows on a terminal, so piped output stays plain.
+	spin := newSpinner(os.Stderr, isTerminal(os.Stdout) && isTerminal(os.Stderr))
+	defer spin.Stop()
+
+	// Sources a question names, with --sources or a filter, are used even
+	// if excluded.
+	budget := contextBudget(s.cfg).Allowing(append(included, parsed.NamedSources()...))
+
+	// A repeated question is answered from the cache, unless the index has
+	// changed since. The generation is read before retrieval, so documents
+	// indexed meanwhile make the new answer stale too.
+	floor := s.cfg.Ask.MinRelevance
+	if *minRelevance >= 0 {
+		floor = *minRelevance
+	}
+	var cacheKey string
+	var generation int64
+	if s.cfg.Ask.CacheAnswers && s.llm != nil {
+		if g, err := s.db.IndexGeneration(ctx); err == nil {
+			cacheKey = answerCacheKey(s.cfg, parsed, scope, n, floor, budget.ExcludedSources)
+			generation = g
+		}
+	}
+	if cacheKey != "" {
+		t, ok := cachedTranscript(ctx, s.db, cacheKey)
+		timings.Done("cache lookup")
+		if ok && !*fresh {
+			fmt.Print(t.Answer)
+			fmt.Print("\n(cached, regenerate with --fresh)\n")
+			return finishAsk(ctx, s, t, nil, *output, *save)
+		}
+	}
+
+	var contexts []string
+	var sources []*storage.Document
+	var used []query.AnswerContext
+	if doc != nil {
+		// Only the document's own chunks are used as context.
+		contexts = query.DocumentContexts(doc, question, budget)
+		sources = []*storage.Document{doc}
+		used = []query.AnswerContext{query.NewAnswerContext(doc, contexts...)}
+	} else {
+		spin.Start("Searching your notes…")
+		// The cache key above uses the heuristic reading, so a cached
+		// answer is served without asking the LLM to parse the question.
+		if s.parser != nil {
+			parsed = s.parser.Refine(ctx, parsed)
+			timings.Done("query parsing")
+		}
+		var results storage.SearchResults
+		if *collection != "" {
+			results, err = scopedResults(ctx, s, parsed, *collection, n)
+		} else if results, err = searchResults(ctx, s, parsed, n); err != nil {
+			err = fmt.Errorf("searching: %w", err)
+		}
+		spin.Stop()
+		timings.Done("retrieval")
+		if err != nil {
+			return err
+		}
 
-	// Build context from search results.
-	contexts := make([]string, 0, 5)
-	for i, doc := range docs {
-		if i >= 5 {
-			break
+		docs := make([]*storage.Document, 0, len(results))
+		for _, r := range results {
+			docs = append(docs, r.Document)
 		}
-		content := doc.Content
-		if len(content) > 1000 {
-			content = content[:1000]
+
+		if len(docs) == 0 {
+			fmt.Println("No relevant documents found.")
+			return nil
+		}
+		if best, ok := query.TopRelevance(results); ok && best < floor {
+			printWeakMatches(os.Stdout, results, floor)
+			return nil
+		}
+
+		// Build context from search results.
+		budget = budget.Allowing(parsed.NamedSources())
+		used = s.hybrid.AssembleContexts(ctx, question, docs, budget)
+		contexts, sources = query.ContextTexts(used), query.ContextSources(used)
+		if len(sources) == 0 {
+			fmt.Printf("No relevant documents found outside %s (use --include-source to answer from them).\n", storage.JoinSources(budget.ExcludedSources))
+			return nil
 		}
-		contexts = append(contexts, content)
 	}
 	conf := query.EstimateAnswerConfidence(question, contexts)
+	timings.Done("context")
+	redactor := buildRedactor(s.cfg)
+	if *showContext {
+		spin.Stop()
+		fmt.Fprintf(os.Stderr, "%s\n\n", redactor.Redact(query.BuildAnswerPrompt(question, contexts, s.cfg.Ask.AnswerLength)))
+	}
 
 	if s.llm == nil {
 		fmt.Printf("(LLM unavailable, showing top results for: %s)\n\n", parsed.SearchTerms)
-		printAskSources(docs)
+		printAskSources(os.Stdout, sources, used, redactor, s.cfg.Display.SnippetLength)
 		return nil
 	}
 
 	// Generate answer via the LLM with streaming.
-	redactor := buildRedactor(s.cfg)
 	var answerBuilder strings.Builder
+	firstToken := true
+	spin.Start("Generating answer…")
 	err = s.llm.GenerateAnswerStream(ctx, question, contexts, func(token string, done bool) {
-		if redactor.Enabled() {
-			if done {
+		if done {
+			spin.Stop()
+			if redactor.Enabled() {
 				fmt.Print(redactor.Redact(answerBuilder.String()))
-				return
 			}
-			answerBuilder.WriteString(token)
 			return
 		}
-		fmt.Print(token)
+		if firstToken {
+			firstToken = false
+			timings.Done("first token")
+			// A redacted answer is printed whole once it is complete.
+			if !redactor.Enabled() {
+				spin.Stop()
+			}
+		}
+		answerBuilder.WriteString(token)
+		if !redactor.Enabled() {
+			fmt.Print(token)
+		}
 	})
+	spin.Stop()
+	timings.Done("generation")
 	if err != nil {
 		// If the LLM fails, show search results instead.
 		fmt.Printf("(LLM unavailable, showing top results for: %s)\n\n", parsed.SearchTerms)
-		printAskSources(docs)
+		printAskSources(os.Stdout, sources, used, redactor, s.cfg.Display.SnippetLength)
 		return nil
 	}
 
-	fmt.Printf("\nConfidence: %s (%.2f)\n", strings.ToUpper(conf.Level), conf.Score)
+	transcript := query.Transcript{
+		Question:   question,
+		Answer:     redactor.Redact(answerBuilder.String()),
+		Sources:    sources,
+		Confidence: conf,
+		CreatedAt:  time.Now(),
+	}
+	if cacheKey != "" && strings.TrimSpace(transcript.Answer) != "" {
+		if err := s.db.PutCachedAnswer(ctx, newCachedAnswer(cacheKey, generation, transcript)); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	return finishAsk(ctx, s, transcript, used, *output, *save)
+}
+
+// finishAsk prints an answer's confidence and sources, with the excerpts of
+// them in used, and writes or saves the transcript when asked to.
+func finishAsk(ctx context.Context, s *stores, transcript query.Transcript, used []query.AnswerContext, output string, save bool) error {
+	fmt.Printf("\nConfidence: %s (%.2f)\n", strings.ToUpper(transcript.Confidence.Level), transcript.Confidence.Score)
 	fmt.Printf("\n\nSources:\n")
-	printAskSources(docs)
+	printAskSources(os.Stdout, transcript.Sources, used, buildRedactor(s.cfg), s.cfg.Display.SnippetLength)
 
+	if output != "" {
+		if err := os.WriteFile(output, []byte(transcript.Markdown()), 0o644); err != nil {
+			return fmt.Errorf("writing transcript: %w", err)
+		}
+		fmt.Printf("\nSaved answer to %s\n", output)
+	}
+	if save {
+		indexer := s.newIndexer(s.vectors)
+		path, err := saveTranscriptNote(ctx, s.cfg, indexer, transcript)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nSaved answer to %s\n", path)
+	}
 	return nil
 }
 
-func printAskSources(docs []*storage.Document) {
+// saveTranscriptNote writes the transcript into the notes folder and indexes
+// it so the answer becomes searchable right away.
+func saveTranscriptNote(ctx context.Context, cfg *config.Config, indexer *index.Indexer, t query.Transcript) (string, error) {
+	dir, err := transcriptNotesDir(cfg)
+	if err != nil {
+		return "", err
+	}
+	path, err := writeTranscriptFile(dir, t)
+	if err != nil {
+		return "", err
+	}
+	if err := indexer.IndexFile(ctx, path); err != nil {
+		return path, fmt.Errorf("indexing saved answer: %w", err)
+	}
+	if err := indexer.SaveVectors(); err != nil {
+		return path, fmt.Errorf("saving vectors: %w", err)
+	}
+	return path, nil
+}
+
+// contextBudget returns the configured answer context budget.
+func contextBudget(cfg *config.Config) query.ContextBudget {
+	excluded, err := storage.ParseSources(strings.Join(cfg.Ask.ExcludedSources, ","))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ask.excluded_sources: %v\n", err)
+	}
+	return query.ContextBudget{MaxContexts: cfg.Ask.MaxContexts, MaxChars: cfg.Ask.MaxContextChars, ExcludedSources: excluded}
+}
+
+// maxWeakMatches is how many of the closest matches are listed when none is
+// relevant enough to answer from.
+const maxWeakMatches = 5
+
+// printWeakMatches says a question found nothing relevant enough to answer
+// from, and lists the closest matches with their relevance.
+func printWeakMatches(w io.Writer, results storage.SearchResults, floor float64) {
+	fmt.Fprintln(w, "I found nothing relevant in your knowledge base.")
+	fmt.Fprintf(w, "\nClosest matches, all under the relevance of %.2f an answer needs:\n", floor)
+	for i, r := range results[:min(len(results), maxWeakMatches)] {
+		fmt.Fprintf(w, "  %d. %s (%s) %.2f\n", i+1, r.Document.Title, r.Document.Path, r.VectorScore)
+	}
+	fmt.Fprintln(w, "\nRephrase the question, or ask with --min-relevance 0 to answer from these anyway.")
+}
+
+// printAskSources lists the documents an answer is based on, each with the
+// excerpts of it in used, where they are in the document in bytes, so the
+// answer's claims can be checked against them. A cached answer has no used.
+func printAskSources(w io.Writer, docs []*storage.Document, used []query.AnswerContext, redactor privacy.Redactor, snippetLen int) {
+	excerpts := make(map[string][]query.Excerpt, len(used))
+	for _, c := range used {
+		excerpts[c.Document.ID] = c.Excerpts
+	}
 	for i, doc := range docs {
-		if i >= 5 {
-			break
+		fmt.Fprintf(w, "  %d. %s (%s)\n", i+1, doc.Title, doc.Path)
+		for _, e := range excerpts[doc.ID] {
+			where := "excerpt"
+			if e.Start >= 0 {
+				where = fmt.Sprintf("bytes %d-%d", e.Start, e.End)
+			}
+			fmt.Fprintf(w, "     %s: %s\n", where, redactor.Redact(passage(e.Text, snippetLen)))
 		}
-		fmt.Printf("  %d. %s (%s)\n", i+1, doc.Title, doc.Path)
 	}
 }
 
@@ -1009,15 +1795,30 @@ func runClean() error {
 	}
 	defer s.Close()
 
-	indexer := index.NewIndexer(s.db, s.bleve, s.vectors, s.embedder, s.cfg)
-	removed, err := indexer.Prune(context.Background())
+	ctx := context.Background()
+	indexer := s.newIndexer(s.vectors)
+	expired, err := indexer.Expire(ctx)
+	if err != nil {
+		return fmt.Errorf("expiring: %w", err)
+	}
+	removed, err := indexer.Prune(ctx)
 	if err != nil {
 		return fmt.Errorf("pruning: %w", err)
 	}
+	trimmed, err := indexer.Trim(ctx)
+	if err != nil {
+		return fmt.Errorf("trimming to budget: %w", err)
+	}
 	if err := indexer.SaveVectors(); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: saving vectors: %v\n", err)
 	}
 	fmt.Printf("Removed %d documents whose files no longer exist.\n", removed)
+	for _, t := range expired {
+		fmt.Printf("Expired %s past retention.\n", trimSummary(t))
+	}
+	for _, t := range trimmed {
+		fmt.Printf("Trimmed %s to fit its budget.\n", trimSummary(t))
+	}
 	return nil
 }
 
@@ -1033,19 +1834,26 @@ func runStats() error {
 	fmt.Printf("Documents: %d\n", total)
 
 	fmt.Println("By source:")
-	for _, src := range []storage.Source{
-		storage.SourceMarkdown, storage.SourcePDF, storage.SourceEmail,
-		storage.SourceBrowser, storage.SourceClipboard,
-	} {
+	for _, src := range storage.AllSources {
 		if n, _ := s.db.CountDocumentsBySource(ctx, src); n > 0 {
 			fmt.Printf("  %-10s %d\n", src, n)
 		}
 	}
 
+	if words, err := s.db.CountDocumentWords(ctx, 5); err == nil {
+		printWordStats(os.Stdout, words)
+	}
+
 	tags, _ := s.db.ListAllTags(ctx)
 	cols, _ := s.db.ListCollections(ctx)
 	fmt.Printf("Tags: %d\n", len(tags))
 	fmt.Printf("Collections: %d\n", len(cols))
+	if n, _ := s.db.CountDocumentsWithMetadata(ctx, "parse_warnings"); n > 0 {
+		fmt.Printf("Partially indexed: %d (files only partly readable)\n", n)
+	}
+	if failed, _ := s.db.ListIndexErrors(ctx); len(failed) > 0 {
+		fmt.Printf("Index errors: %d (see 'mindcli errors list')\n", len(failed))
+	}
 
 	if s.vectors != nil {
 		fmt.Printf("Vectors: %d (model: %s, dim: %d)\n", s.vectors.Len(), s.vectors.Model(), s.vectors.Dim())
@@ -1060,25 +1868,51 @@ func runStats() error {
 	return nil
 }
 
+// printWordStats prints the words in the index, the average length of a
+// document and the largest ones. It prints nothing before words are counted,
+// as they are from the next index on.
+func printWordStats(w io.Writer, stats storage.WordStats) {
+	if stats.Documents == 0 {
+		return
+	}
+	avg := int(stats.Words / int64(stats.Documents))
+	fmt.Fprintf(w, "Words: %d (average %d per document, %d min read)\n",
+		stats.Words, avg, int(storage.ReadingTime(avg).Minutes()))
+	if len(stats.Largest) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Largest:")
+	for _, d := range stats.Largest {
+		fmt.Fprintf(w, "  %7d  %s (%s)\n", d.Words, d.Title, d.Path)
+	}
+}
+
 func printPathSize(label, path string) {
+	if size, ok := pathSize(path); ok {
+		fmt.Printf("%s %s\n", label, humanSize(size))
+	}
+}
+
+// pathSize returns the bytes a file, or all the files in a directory, take
+// on disk, and false when path doesn't exist.
+func pathSize(path string) (int64, bool) {
 	info, err := os.Stat(path)
 	if err != nil {
-		return
+		return 0, false
+	}
+	if !info.IsDir() {
+		return info.Size(), true
 	}
 	var size int64
-	if info.IsDir() {
-		_ = filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
-			if err == nil && !d.IsDir() {
-				if fi, e := d.Info(); e == nil {
-					size += fi.Size()
-				}
+	_ = filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if fi, e := d.Info(); e == nil {
+				size += fi.Size()
 			}
-			return nil
-		})
-	} else {
-		size = info.Size()
-	}
-	fmt.Printf("%s %s\n", label, humanSize(size))
+		}
+		return nil
+	})
+	return size, true
 }
 
 func humanSize(n int64) string {
@@ -1138,6 +1972,28 @@ func runDoctor() error {
 	checkPaths("markdown", cfg.Sources.Markdown.Enabled, cfg.Sources.Markdown.Paths)
 	checkPaths("pdf", cfg.Sources.PDF.Enabled, cfg.Sources.PDF.Paths)
 	checkPaths("email", cfg.Sources.Email.Enabled, cfg.Sources.Email.Paths)
+	checkPaths("data", cfg.Sources.Data.Enabled, cfg.Sources.Data.Paths)
+	checkPaths("references", cfg.Sources.References.Enabled, cfg.Sources.References.Paths)
+	checkPaths("screenshots", cfg.Sources.Screenshots.Enabled, cfg.Sources.Screenshots.Paths)
+	checkPaths("projects", cfg.Sources.Projects.Enabled, cfg.Sources.Projects.Paths)
+	if cfg.Sources.Screenshots.Enabled {
+		if ocr := strings.Fields(cfg.Sources.Screenshots.OCRCommand); len(ocr) > 0 {
+			if _, err := exec.LookPath(ocr[0]); err != nil {
+				fmt.Printf("x screenshots OCR command not found: %s\n", ocr[0])
+			} else {
+				fmt.Printf("ok screenshots OCR command: %s\n", ocr[0])
+			}
+		}
+	}
+	if cfg.Sources.Clipboard.Enabled && len(cfg.Sources.Clipboard.ExcludeApps) > 0 {
+		if !sources.FocusedAppSupported() {
+			fmt.Printf("x clipboard exclude_apps does nothing here: the focused app can only be found on macOS and on Linux under X11 with xdotool\n")
+		} else if app, err := sources.FrontmostApp(context.Background()); err != nil {
+			fmt.Printf("x clipboard can't see the focused app, so clips are skipped: %v\n", err)
+		} else {
+			fmt.Printf("ok clipboard can see the focused app (%s)\n", app)
+		}
+	}
 
 	dataDir, err := cfg.DataDir()
 	if err != nil {
@@ -1169,37 +2025,3 @@ func runConfigInit() error {
 	fmt.Printf("Config written to: %s\n", configPath)
 	return nil
 }
-
-// consoleProgressReporter prints progress to the console.
-type consoleProgressReporter struct {
-	current int
-	total   int
-}
-
-func (r *consoleProgressReporter) OnStart(source string, total int) {
-	r.total = total
-	fmt.Printf("Indexing %s: %d files\n", source, total)
-}
-
-func (r *consoleProgressReporter) OnProgress(source string, current, total int, path string) {
-	r.current = current
-	// Print progress every 10 files or at the end
-	if current%10 == 0 || current == total {
-		fmt.Printf("\r  [%d/%d] %s", current, total, truncatePath(path, 50))
-	}
-}
-
-func (r *consoleProgressReporter) OnComplete(source string, indexed, errors int) {
-	fmt.Printf("\r  Completed: %d indexed, %d errors\n", indexed, errors)
-}
-
-func (r *consoleProgressReporter) OnError(source string, path string, err error) {
-	fmt.Fprintf(os.Stderr, "\n  Error: %s: %v\n", path, err)
-}
-
-func truncatePath(path string, maxLen int) string {
-	if len(path) <= maxLen {
-		return path + " "
-	}
-	return "..." + path[len(path)-maxLen+3:] + " "
-}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/privacy"
 	"github.com/J-1000/mindcli/internal/query"
 	"github.com/J-1000/mindcli/internal/search"
 	"github.com/J-1000/mindcli/internal/storage"
@@ -74,7 +79,13 @@ func TestPrintUsage(t *testing.T) {
 		"mindcli export",
 		"mindcli tag",
 		"mindcli clipboard",
+		"mindcli errors",
+		"-retry-failed",
 		"mindcli ask",
+		"mindcli eval",
+		"mindcli bench",
+		"mindcli similar",
+		"mindcli grep",
 		"mindcli config",
 		"mindcli version",
 		"mindcli help",
@@ -237,6 +248,67 @@ func TestSearchWithSourceFilter(t *testing.T) {
 	}
 }
 
+func TestScopedResults(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer closeTestDB(t, db)
+	searchIndex, err := search.NewBleveIndex(filepath.Join(tmpDir, "search.bleve"))
+	if err != nil {
+		t.Fatalf("Failed to create search index: %v", err)
+	}
+	defer closeTestIndex(t, searchIndex)
+
+	ctx := context.Background()
+	now := time.Now()
+	docs := []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Path: "/notes/go.md", Title: "Go Notes", Content: "Go concurrency patterns", ContentHash: "h1", IndexedAt: now, ModifiedAt: now},
+		{ID: "2", Source: storage.SourceMarkdown, Path: "/notes/paper.md", Title: "Paper", Content: "A paper on Go concurrency", ContentHash: "h2", IndexedAt: now, ModifiedAt: now},
+		{ID: "3", Source: storage.SourceMarkdown, Path: "/notes/recipes.md", Title: "Recipes", Content: "Soup and bread", ContentHash: "h3", IndexedAt: now, ModifiedAt: now},
+	}
+	for _, doc := range docs {
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatalf("Failed to insert doc: %v", err)
+		}
+		if err := searchIndex.Index(ctx, doc); err != nil {
+			t.Fatalf("Failed to index doc: %v", err)
+		}
+	}
+	col := &storage.Collection{Name: "reading-list"}
+	if err := db.CreateCollection(ctx, col); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"2", "3"} {
+		if err := db.AddToCollection(ctx, col.ID, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &stores{cfg: config.Default(), db: db, search: searchIndex}
+
+	results, err := scopedResults(ctx, s, query.ParseQuery("go concurrency"), "reading-list", 10)
+	if err != nil {
+		t.Fatalf("scopedResults() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Document.ID != "2" {
+		t.Errorf("scopedResults() = %d results, want only the paper in the collection", len(results))
+	}
+
+	// A question nothing in the collection matches gets the whole collection.
+	results, err = scopedResults(ctx, s, query.ParseQuery("kubernetes"), "reading-list", 10)
+	if err != nil {
+		t.Fatalf("scopedResults() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("scopedResults() without matches = %d results, want the 2 collection documents", len(results))
+	}
+
+	if _, err := scopedResults(ctx, s, query.ParseQuery("go"), "missing", 10); err == nil {
+		t.Error("scopedResults() on a missing collection should fail")
+	}
+}
+
 // TestAskWithNoOllama tests that runAsk falls back gracefully when Ollama is unavailable.
 func TestAskFallbackWithoutOllama(t *testing.T) {
 	// LLMClient with a bad URL should fail to generate, triggering the fallback path.
@@ -338,3 +410,161 @@ func searchString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestPrintIndexErrors(t *testing.T) {
+	var buf bytes.Buffer
+	printIndexErrors(&buf, nil)
+	if !strings.Contains(buf.String(), "No index errors") {
+		t.Errorf("empty output = %q", buf.String())
+	}
+
+	buf.Reset()
+	printIndexErrors(&buf, []*storage.IndexError{
+		{Path: "/notes/bad.md", Source: storage.SourceMarkdown, Error: "parsing: invalid frontmatter", FailedAt: time.Now()},
+		{Path: "/docs/locked.pdf", Source: storage.SourcePDF, Error: "parsing: encrypted", FailedAt: time.Now()},
+	})
+	out := buf.String()
+	for _, want := range []string{"2 files failed", "-retry-failed", "/notes/bad.md", "invalid frontmatter", "pdf", "/docs/locked.pdf"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintWordStats(t *testing.T) {
+	var buf bytes.Buffer
+	printWordStats(&buf, storage.WordStats{})
+	if buf.Len() != 0 {
+		t.Errorf("output before words are counted = %q, want none", buf.String())
+	}
+
+	printWordStats(&buf, storage.WordStats{
+		Documents: 2,
+		Words:     1020,
+		Largest: []storage.DocumentWords{
+			{DocumentTitle: storage.DocumentTitle{ID: "1", Path: "/notes/long.md", Title: "Long Note"}, Words: 900},
+		},
+	})
+	out := buf.String()
+	for _, want := range []string{"Words: 1020", "average 510 per document", "3 min read", "Largest:", "900", "Long Note", "/notes/long.md"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintAskSources(t *testing.T) {
+	guide := &storage.Document{ID: "guide", Title: "Go Guide", Path: "/notes/go.md"}
+	paper := &storage.Document{ID: "paper", Title: "Paper", Path: "/docs/paper.pdf"}
+	used := []query.AnswerContext{
+		{Document: guide, Excerpts: []query.Excerpt{
+			{Text: "Goroutines are cheap threads.", Start: 120, End: 149},
+			{Text: "Channels   connect\ngoroutines, token-123.", Start: 400, End: 442},
+		}},
+		{Document: paper, Excerpts: []query.Excerpt{{Text: "Moved text.", Start: -1, End: -1}}},
+	}
+	redactor, errs := privacy.NewRedactor([]string{`token-[0-9]+`})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	var buf bytes.Buffer
+	printAskSources(&buf, []*storage.Document{guide, paper}, used, redactor, 300)
+	out := buf.String()
+	for _, want := range []string{
+		"1. Go Guide (/notes/go.md)",
+		"bytes 120-149: Goroutines are cheap threads.",
+		"bytes 400-442: Channels connect goroutines, [REDACTED].",
+		"2. Paper (/docs/paper.pdf)",
+		"excerpt: Moved text.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printAskSources(&buf, []*storage.Document{guide}, nil, privacy.Redactor{}, 300)
+	if got := buf.String(); got != "  1. Go Guide (/notes/go.md)\n" {
+		t.Errorf("cached answer sources = %q, want the title and path only", got)
+	}
+}
+
+func TestPrintWeakMatches(t *testing.T) {
+	var results storage.SearchResults
+	for i := range 7 {
+		results = append(results, &storage.SearchResult{
+			Document:    &storage.Document{Title: fmt.Sprintf("Note %d", i+1), Path: fmt.Sprintf("/notes/%d.md", i+1)},
+			VectorScore: 0.6 - float64(i)/100,
+		})
+	}
+	var buf bytes.Buffer
+	printWeakMatches(&buf, results, 0.7)
+	out := buf.String()
+	for _, want := range []string{"nothing relevant in your knowledge base", "0.70", "1. Note 1 (/notes/1.md) 0.60", "5. Note 5", "--min-relevance 0"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Note 6") {
+		t.Errorf("output lists more than %d matches:\n%s", maxWeakMatches, out)
+	}
+}
+
+func TestResultsLimit(t *testing.T) {
+	cfg := config.Default()
+	cfg.Search.ResultsLimit = 25
+	if got := resultsLimit(cfg, 0); got != 25 {
+		t.Errorf("resultsLimit() without override = %d, want 25", got)
+	}
+	if got := resultsLimit(cfg, 5); got != 5 {
+		t.Errorf("resultsLimit() with --limit 5 = %d, want 5", got)
+	}
+}
+
+func TestApplyWeight(t *testing.T) {
+	parsed := query.ParseQuery("semantic: focus")
+	if err := applyWeight(&parsed, -1); err != nil || parsed.Weight(0.5) != 1 {
+		t.Errorf("without --weight the prefix weight should stay, got %v (err %v)", parsed.Weight(0.5), err)
+	}
+	if err := applyWeight(&parsed, 0.2); err != nil || parsed.Weight(0.5) != 0.2 {
+		t.Errorf("--weight 0.2 should override the prefix, got %v (err %v)", parsed.Weight(0.5), err)
+	}
+	if err := applyWeight(&parsed, 1.5); err == nil {
+		t.Error("expected an error for --weight above 1")
+	}
+}
+
+func TestApplySources(t *testing.T) {
+	parsed := query.ParseQuery("budget in my emails")
+	if err := applySources(&parsed, ""); err != nil || parsed.SourceFilter != "email" {
+		t.Errorf("without --sources the query's source should stay, got %q (err %v)", parsed.SourceFilter, err)
+	}
+	if err := applySources(&parsed, "markdown, pdf"); err != nil || parsed.SourceFilter != "markdown,pdf" {
+		t.Errorf("--sources should replace the query's source, got %q (err %v)", parsed.SourceFilter, err)
+	}
+	if err := applySources(&parsed, "markdown,wiki"); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
+
+func TestPassage(t *testing.T) {
+	if got := passage("short\n\n  text", 50); got != "short text" {
+		t.Errorf("passage() = %q, want whitespace collapsed", got)
+	}
+	if got := passage("one two three four", 10); got != "one two..." {
+		t.Errorf("passage() = %q, want it cut at a word", got)
+	}
+}
+
+func TestContextBudget(t *testing.T) {
+	cfg := config.Default()
+	cfg.Ask.MaxContexts = 3
+	cfg.Ask.MaxContextChars = 1200
+	if got := contextBudget(cfg); got.MaxContexts != 3 || got.MaxChars != 1200 {
+		t.Errorf("contextBudget() = %+v, want 3 contexts and 1200 chars", got)
+	}
+	if got := contextBudget(config.Default()).ExcludedSources; storage.JoinSources(got) != "browser,clipboard" {
+		t.Errorf("default excluded sources = %v, want browser and clipboard", got)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// runOnThisDay lists the documents dated on today's date in earlier years.
+func runOnThisDay(args []string) error {
+	fs := flag.NewFlagSet("onthisday", flag.ExitOnError)
+	date := fs.String("date", "", "Look back from this day instead of today (YYYY-MM-DD)")
+	limit := fs.Int("limit", 0, "Maximum number of documents (default: search.results_limit)")
+	_ = fs.Parse(args)
+
+	day := time.Now()
+	if *date != "" {
+		d, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+		if err != nil {
+			return fmt.Errorf("-date must be YYYY-MM-DD: %w", err)
+		}
+		day = d
+	}
+
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	docs, err := s.db.OnThisDay(context.Background(), day, resultsLimit(s.cfg, *limit))
+	if err != nil {
+		return err
+	}
+	printOnThisDay(os.Stdout, docs, day)
+	return nil
+}
+
+// printOnThisDay prints the documents under a heading for each year, with
+// how long ago it was.
+func printOnThisDay(w io.Writer, docs []*storage.Document, day time.Time) {
+	if len(docs) == 0 {
+		fmt.Fprintf(w, "Nothing from %s in earlier years.\n", day.Format("2 January"))
+		return
+	}
+	fmt.Fprintf(w, "On this day, %s:\n", day.Format("2 January"))
+	year := 0
+	for _, doc := range docs {
+		if y := doc.Date().Year(); y != year {
+			year = y
+			ago := day.Year() - y
+			fmt.Fprintf(w, "\n%d (%s ago)\n", y, plural(ago, "a year", fmt.Sprintf("%d years", ago)))
+		}
+		fmt.Fprintf(w, "  %s\n     %s [%s]\n", doc.Title, doc.Path, sourceLabel(doc))
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestPrintOnThisDay(t *testing.T) {
+	day := time.Date(2026, time.October, 16, 9, 0, 0, 0, time.Local)
+	at := func(y int) time.Time { return time.Date(y, time.October, 16, 20, 0, 0, 0, time.Local) }
+	docs := []*storage.Document{
+		{Title: "Standup", Path: "/j/a.md", Source: storage.SourceMarkdown, ModifiedAt: at(2025)},
+		{Title: "Trip", Path: "/j/b.md", Source: storage.SourceMarkdown, ModifiedAt: at(2023)},
+		{Title: "Paper", Path: "/p/c.pdf", Source: storage.SourcePDF, ModifiedAt: at(2023)},
+	}
+	var buf bytes.Buffer
+	printOnThisDay(&buf, docs, day)
+	want := "On this day, 16 October:\n" +
+		"\n2025 (a year ago)\n  Standup\n     /j/a.md [markdown]\n" +
+		"\n2023 (3 years ago)\n  Trip\n     /j/b.md [markdown]\n  Paper\n     /p/c.pdf [pdf]\n"
+	if buf.String() != want {
+		t.Errorf("printOnThisDay() output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	printOnThisDay(&buf, nil, day)
+	if !strings.Contains(buf.String(), "Nothing from 16 October") {
+		t.Errorf("printOnThisDay(none) = %q", buf.String())
+	}
+}
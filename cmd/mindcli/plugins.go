@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/index"
+	"github.com/J-1000/mindcli/internal/plugins"
+)
+
+// builtinFormats are the export formats mindcli writes itself.
+var builtinFormats = []string{"json", "csv", "markdown", "anki", "alfred"}
+
+// runPlugins lists the plugins found in the plugins directory.
+func runPlugins(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: mindcli plugins")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	dir, err := cfg.PluginsDir()
+	if err != nil {
+		return err
+	}
+	p, err := loadPlugins(cfg)
+	if err != nil {
+		return err
+	}
+	printPlugins(os.Stdout, dir, p)
+	return nil
+}
+
+// loadPlugins discovers the plugins configured by cfg.
+func loadPlugins(cfg *config.Config) (*plugins.Plugins, error) {
+	dir, err := cfg.PluginsDir()
+	if err != nil {
+		return nil, fmt.Errorf("finding plugins directory: %w", err)
+	}
+	return plugins.Discover(dir, cfg.Plugins.Disabled)
+}
+
+// postProcessors returns the post-processor plugins configured by cfg. A
+// plugins directory that can't be read is reported and skipped.
+func postProcessors(cfg *config.Config) []index.PostProcessor {
+	p, err := loadPlugins(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return nil
+	}
+	processors := make([]index.PostProcessor, len(p.Processors))
+	for i, proc := range p.Processors {
+		processors[i] = proc
+	}
+	return processors
+}
+
+func printPlugins(w io.Writer, dir string, p *plugins.Plugins) {
+	fmt.Fprintf(w, "Plugins directory: %s\n", dir)
+	if len(p.Exporters) == 0 && len(p.Processors) == 0 {
+		fmt.Fprintln(w, "No plugins found. Add executables named export-<format> or process-<name>.")
+		return
+	}
+	if formats := p.Formats(); len(formats) > 0 {
+		fmt.Fprintf(w, "\nExport formats (mindcli export --format <format>):\n")
+		for _, f := range formats {
+			fmt.Fprintf(w, "  %-16s %s\n", f, p.Exporters[f].Path)
+		}
+	}
+	if len(p.Processors) > 0 {
+		fmt.Fprintf(w, "\nPost-processors (run on every indexed document):\n")
+		for _, proc := range p.Processors {
+			fmt.Fprintf(w, "  %s\n", proc.Name())
+		}
+	}
+}
+
+// exportFormats describes the formats export accepts, for error messages.
+func exportFormats(p *plugins.Plugins) string {
+	formats := append([]string{}, builtinFormats...)
+	if p != nil {
+		formats = append(formats, p.Formats()...)
+	}
+	return strings.Join(formats, ", ")
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/plugins"
+)
+
+func TestPrintPlugins(t *testing.T) {
+	var buf bytes.Buffer
+	printPlugins(&buf, "/plugins", &plugins.Plugins{})
+	if !strings.Contains(buf.String(), "No plugins found") {
+		t.Errorf("printPlugins() with none = %q", buf.String())
+	}
+
+	buf.Reset()
+	printPlugins(&buf, "/plugins", &plugins.Plugins{Exporters: map[string]*plugins.Exporter{
+		"org": {Format: "org", Path: "/plugins/export-org"},
+	}})
+	if !strings.Contains(buf.String(), "org") || !strings.Contains(buf.String(), "/plugins/export-org") {
+		t.Errorf("printPlugins() = %q, want the exporter listed", buf.String())
+	}
+	if got := exportFormats(&plugins.Plugins{Exporters: map[string]*plugins.Exporter{"org": {}}}); got != "json, csv, markdown, anki, alfred, org" {
+		t.Errorf("exportFormats() = %q", got)
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/index"
+	"github.com/J-1000/mindcli/internal/index/sources"
+	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/pkg/chunker"
+)
+
+const (
+	progressBarWidth  = 24
+	progressRedrawGap = 100 * time.Millisecond
+)
+
+// consoleProgressReporter renders indexing progress. On a terminal it redraws
+// a progress bar per source with throughput and ETA; otherwise it prints
+// plain lines at 10% steps so logs stay readable.
+type consoleProgressReporter struct {
+	out    io.Writer // defaults to stdout
+	errOut io.Writer // defaults to stderr
+	tty    bool
+	now    func() time.Time
+
+	mu       sync.Mutex
+	source   string
+	current  int
+	total    int
+	errors   int
+	warnings int
+	started  time.Time
+	lastDraw time.Time
+	lastStep int // last 10% step printed in plain mode
+
+	summaries []sourceSummary
+}
+
+// sourceSummary records the outcome of indexing one source.
+type sourceSummary struct {
+	source   string
+	total    int
+	indexed  int
+	errors   int
+	warnings int
+	elapsed  time.Duration
+}
+
+// newConsoleProgressReporter returns a reporter writing to stdout/stderr,
+// using the interactive renderer only when stdout is a terminal.
+func newConsoleProgressReporter() *consoleProgressReporter {
+	return &consoleProgressReporter{
+		out:    os.Stdout,
+		errOut: os.Stderr,
+		tty:    isTerminal(os.Stdout),
+		now:    time.Now,
+	}
+}
+
+// isTerminal reports whether f refers to a character device.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func (r *consoleProgressReporter) writer() io.Writer {
+	if r.out == nil {
+		return os.Stdout
+	}
+	return r.out
+}
+
+func (r *consoleProgressReporter) errWriter() io.Writer {
+	if r.errOut == nil {
+		return os.Stderr
+	}
+	return r.errOut
+}
+
+func (r *consoleProgressReporter) clock() time.Time {
+	if r.now == nil {
+		return time.Now()
+	}
+	return r.now()
+}
+
+func (r *consoleProgressReporter) OnStart(source string, total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.source = source
+	r.total = total
+	r.current = 0
+	r.errors = 0
+	r.warnings = 0
+	r.lastStep = 0
+	r.started = r.clock()
+	r.lastDraw = time.Time{}
+	fmt.Fprintf(r.writer(), "Indexing %s: %d files\n", source, total)
+}
+
+func (r *consoleProgressReporter) OnProgress(source string, current, total int, path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Workers report concurrently and may arrive out of order.
+	if current < r.current {
+		return
+	}
+	r.current = current
+	r.total = total
+
+	if r.tty {
+		now := r.clock()
+		if current != total && now.Sub(r.lastDraw) < progressRedrawGap {
+			return
+		}
+		r.lastDraw = now
+		fmt.Fprintf(r.writer(), "\r\033[K  %s %s", r.progressLine(now), truncatePath(path, 40))
+		return
+	}
+
+	if total == 0 {
+		return
+	}
+	if step := current * 10 / total; step > r.lastStep {
+		r.lastStep = step
+		fmt.Fprintf(r.writer(), "  %s: %d/%d (%d%%)\n", source, current, total, current*100/total)
+	}
+}
+
+func (r *consoleProgressReporter) OnComplete(source string, indexed, errors int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elapsed := r.clock().Sub(r.started)
+	r.summaries = append(r.summaries, sourceSummary{
+		source:   source,
+		total:    r.total,
+		indexed:  indexed,
+		errors:   errors,
+		warnings: r.warnings,
+		elapsed:  elapsed,
+	})
+
+	if r.tty {
+		fmt.Fprint(r.writer(), "\r\033[K")
+	}
+	fmt.Fprintf(r.writer(), "  Completed: %d indexed, %d errors in %s\n", indexed, errors, formatDuration(elapsed))
+}
+
+func (r *consoleProgressReporter) OnError(source string, path string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errors++
+	if r.tty {
+		// Clear the bar so the error gets its own line; the next redraw
+		// restores the bar.
+		fmt.Fprint(r.writer(), "\r\033[K")
+		r.lastDraw = time.Time{}
+	}
+	if path == "" {
+		fmt.Fprintf(r.errWriter(), "  Error: %s: %v\n", source, err)
+		return
+	}
+	fmt.Fprintf(r.errWriter(), "  Error: %s: %v\n", path, err)
+}
+
+// OnWarning reports a file that was indexed from partially parsed content.
+func (r *consoleProgressReporter) OnWarning(source string, path string, warnings []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.warnings++
+	if r.tty {
+		fmt.Fprint(r.writer(), "\r\033[K")
+		r.lastDraw = time.Time{}
+	}
+	fmt.Fprintf(r.errWriter(), "  Warning: %s: %s\n", path, strings.Join(warnings, "; "))
+}
+
+// progressLine renders the bar, percentage, counts, throughput and ETA.
+func (r *consoleProgressReporter) progressLine(now time.Time) string {
+	fraction := 0.0
+	if r.total > 0 {
+		fraction = float64(r.current) / float64(r.total)
+	}
+	filled := int(fraction * progressBarWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+
+	line := fmt.Sprintf("%-9s [%s] %3d%% %d/%d", r.source, bar, int(fraction*100), r.current, r.total)
+
+	elapsed := now.Sub(r.started)
+	if elapsed > 0 && r.current > 0 {
+		rate := float64(r.current) / elapsed.Seconds()
+		line += fmt.Sprintf("  %.1f files/s", rate)
+		if remaining := r.total - r.current; remaining > 0 {
+			eta := time.Duration(float64(remaining) / rate * float64(time.Second))
+			line += "  ETA " + formatDuration(eta)
+		}
+	}
+	if r.errors > 0 {
+		line += fmt.Sprintf("  %d errors", r.errors)
+	}
+	return line
+}
+
+// printSummary writes a per-source table followed by totals and embedding
+// statistics. vectors is the vector store size, or -1 when semantic search is
+// disabled.
+func (r *consoleProgressReporter) printSummary(w io.Writer, stats *index.Stats, vectors int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stats.Interrupted {
+		fmt.Fprintf(w, "\nIndexing interrupted:\n")
+	} else {
+		fmt.Fprintf(w, "\nIndexing complete:\n")
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  SOURCE\tFILES\tINDEXED\tERRORS\tWARNINGS\tTIME")
+	for _, s := range r.summaries {
+		fmt.Fprintf(tw, "  %s\t%d\t%d\t%d\t%d\t%s\n", s.source, s.total, s.indexed, s.errors, s.warnings, formatDuration(s.elapsed))
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintf(w, "\n  Total files:   %d\n", stats.TotalFiles)
+	fmt.Fprintf(w, "  Indexed:       %d\n", stats.IndexedFiles)
+	fmt.Fprintf(w, "  Errors:        %d\n", stats.Errors)
+	if stats.Warnings > 0 {
+		fmt.Fprintf(w, "  Warnings:      %d (indexed from partially readable files)\n", stats.Warnings)
+	}
+	if stats.Recovered > 0 {
+		fmt.Fprintf(w, "  Recovered:     %d (interrupted by an earlier run)\n", stats.Recovered)
+	}
+	for _, t := range stats.Trimmed {
+		fmt.Fprintf(w, "  Trimmed:       %s\n", trimSummary(t))
+	}
+	for _, t := range stats.Expired {
+		fmt.Fprintf(w, "  Expired:       %s\n", trimSummary(t))
+	}
+	if vectors >= 0 {
+		fmt.Fprintf(w, "  Embedded:      %d documents\n", stats.Embedded)
+		fmt.Fprintf(w, "  Vectors:       %d\n", vectors)
+	}
+	if stats.Interrupted {
+		fmt.Fprintf(w, "  Left to do:    %s\n", leftToDo(stats))
+		fmt.Fprintln(w, "\nRun 'mindcli index' again to resume; files already indexed are skipped.")
+	}
+}
+
+// leftToDo describes what an interrupted run didn't get to, e.g.
+// "420 files; not started: pdf,email".
+func leftToDo(stats *index.Stats) string {
+	left := fmt.Sprintf("%d %s", stats.Remaining, plural(int(stats.Remaining), "file", "files"))
+	if len(stats.NotStarted) > 0 {
+		left += "; not started: " + storage.JoinSources(stats.NotStarted)
+	}
+	return left
+}
+
+// trimSummary describes what was trimmed from a source to fit its budget or
+// expired from it, e.g. "120 clipboard documents (1.2 MB), the newest from 2024-03-01".
+func trimSummary(t sources.TrimReport) string {
+	what := fmt.Sprintf("%d %s %s", t.Removed, t.Source, plural(t.Removed, "document", "documents"))
+	if t.Path != "" {
+		what = fmt.Sprintf("%d %s %s of %s", t.Removed, t.Source, plural(t.Removed, "entry", "entries"), t.Path)
+	}
+	what += fmt.Sprintf(" (%s)", humanSize(t.Bytes))
+	if !t.Newest.IsZero() {
+		what += ", the newest from " + t.Newest.Format("2006-01-02")
+	}
+	return what
+}
+
+// formatDuration renders d compactly, e.g. "850ms", "12s", "3m05s".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// truncatePath shortens path from the front to fit in maxLen columns, so
+// the file name stays visible, and adds a separating space.
+func truncatePath(path string, maxLen int) string {
+	return chunker.TruncateWidthLeft(path, maxLen) + " "
+}
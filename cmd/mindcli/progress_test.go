@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/index"
+	"github.com/J-1000/mindcli/internal/index/sources"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// fakeClock returns a clock that advances by step on every call.
+func fakeClock(step time.Duration) func() time.Time {
+	t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		t = t.Add(step)
+		return t
+	}
+}
+
+func TestProgressReporterPlainOutput(t *testing.T) {
+	var out, errOut bytes.Buffer
+	r := &consoleProgressReporter{out: &out, errOut: &errOut, now: fakeClock(time.Second)}
+
+	r.OnStart("markdown", 20)
+	for i := 1; i <= 20; i++ {
+		r.OnProgress("markdown", i, 20, "/notes/file.md")
+	}
+	r.OnError("markdown", "/notes/bad.md", errors.New("boom"))
+	r.OnComplete("markdown", 19, 1)
+
+	got := out.String()
+	if strings.Contains(got, "\r") || strings.Contains(got, "\033[") {
+		t.Errorf("plain output should not contain terminal control sequences: %q", got)
+	}
+	if strings.Count(got, "  markdown: ") != 10 {
+		t.Errorf("expected one line per 10%% step, got:\n%s", got)
+	}
+	if !strings.Contains(got, "markdown: 20/20 (100%)") {
+		t.Errorf("missing final progress line:\n%s", got)
+	}
+	if !strings.Contains(errOut.String(), "/notes/bad.md: boom") {
+		t.Errorf("error output = %q", errOut.String())
+	}
+}
+
+func TestProgressReporterTerminalLine(t *testing.T) {
+	var out bytes.Buffer
+	r := &consoleProgressReporter{out: &out, tty: true, now: fakeClock(time.Second)}
+
+	r.OnStart("pdf", 10)
+	r.OnProgress("pdf", 5, 10, "/docs/a.pdf")
+	r.OnProgress("pdf", 4, 10, "/docs/b.pdf") // stale, out of order
+
+	got := out.String()
+	for _, want := range []string{"pdf", "50%", "5/10", "files/s", "ETA"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("terminal output missing %q: %q", want, got)
+		}
+	}
+	if r.current != 5 {
+		t.Errorf("current = %d, want 5 (stale updates ignored)", r.current)
+	}
+}
+
+func TestProgressReporterSummary(t *testing.T) {
+	var out bytes.Buffer
+	r := &consoleProgressReporter{out: &out, now: fakeClock(time.Second)}
+	r.OnStart("markdown", 3)
+	r.OnComplete("markdown", 3, 0)
+	r.OnStart("pdf", 2)
+	r.OnWarning("pdf", "/docs/b.pdf", []string{"skipped 2 pages"})
+	r.OnComplete("pdf", 1, 1)
+
+	var summary bytes.Buffer
+	stats := &index.Stats{TotalFiles: 5, IndexedFiles: 4, Errors: 1, Warnings: 1, Embedded: 2, Recovered: 1,
+		Trimmed: []sources.TrimReport{
+			{Source: storage.SourceClipboard, Removed: 120, Bytes: 3 << 20, Newest: time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)},
+			{Source: storage.SourceBrowser, Path: "/chrome/History", Removed: 1, Bytes: 80},
+		},
+		Expired: []sources.TrimReport{
+			{Source: storage.SourceScreenshot, Removed: 3, Bytes: 2048, Newest: time.Date(2024, 1, 2, 9, 0, 0, 0, time.Local)},
+		}}
+	r.printSummary(&summary, stats, 12)
+
+	got := summary.String()
+	for _, want := range []string{"SOURCE", "WARNINGS", "markdown", "pdf", "Total files:   5", "Warnings:      1", "Recovered:     1", "Embedded:      2 documents", "Vectors:       12",
+		"Trimmed:       120 clipboard documents (3.0 MB), the newest from 2024-03-01",
+		"Trimmed:       1 browser entry of /chrome/History (80 B)\n",
+		"Expired:       3 screenshot documents (2.0 KB), the newest from 2024-01-02"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+
+	summary.Reset()
+	r.printSummary(&summary, stats, -1)
+	if strings.Contains(summary.String(), "Vectors") {
+		t.Errorf("summary without vectors should omit embedding stats:\n%s", summary.String())
+	}
+	if strings.Contains(summary.String(), "Indexing interrupted") || strings.Contains(summary.String(), "Left to do") {
+		t.Errorf("a finished run's summary mentions an interruption:\n%s", summary.String())
+	}
+
+	summary.Reset()
+	stats.Interrupted, stats.Remaining, stats.NotStarted = true, 420, []storage.Source{storage.SourcePDF, storage.SourceEmail}
+	r.printSummary(&summary, stats, -1)
+	for _, want := range []string{"Indexing interrupted:", "Left to do:    420 files; not started: pdf,email\n", "Run 'mindcli index' again to resume"} {
+		if !strings.Contains(summary.String(), want) {
+			t.Errorf("interrupted summary missing %q:\n%s", want, summary.String())
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{850 * time.Millisecond, "850ms"},
+		{12 * time.Second, "12s"},
+		{3*time.Minute + 5*time.Second, "3m05s"},
+		{2*time.Hour + 7*time.Minute, "2h07m"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// quickOpen finds the document name refers to: the one an alias or path
+// names, or else the one whose title or alias matches name best, picked
+// from the ties on in when several match equally well.
+func quickOpen(db *storage.DB, name string, in io.Reader, out io.Writer, interactive bool) (*storage.Document, error) {
+	if doc, err := resolveAliasOrPath(db, name); err == nil {
+		return doc, nil
+	}
+	ctx := context.Background()
+	titles, err := db.ListTitles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := db.ListAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := pickMatch(name, matchTitles(name, titles, aliases), in, out, interactive)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetDocument(ctx, id)
+}
+
+// How well a title or alias matches a quick-open query, from weakest to
+// strongest.
+const (
+	matchSubsequence = iota + 1 // the query's letters appear in order
+	matchWords                  // every word of the query appears
+	matchSubstring              // the query appears as is
+	matchPrefix                 // the name starts with the query
+	matchExact                  // the name is the query
+)
+
+// maxPicks bounds how many tied matches the picker offers.
+const maxPicks = 9
+
+// titleMatch is a document whose title or one of its aliases matches a
+// quick-open query.
+type titleMatch struct {
+	storage.DocumentTitle
+	name  string // the title or alias that matched
+	score int
+}
+
+// matchTitles returns the documents whose title or an alias matches q,
+// ignoring case, best first. Matches of the same kind are ordered by the
+// length of the name they matched, so the closest comes first.
+func matchTitles(q string, titles []storage.DocumentTitle, aliases []storage.Alias) []titleMatch {
+	q = strings.Join(strings.Fields(strings.ToLower(q)), " ")
+	if q == "" {
+		return nil
+	}
+	best := make(map[string]titleMatch)
+	consider := func(t storage.DocumentTitle, name string) {
+		score := titleScore(q, strings.ToLower(name))
+		if score == 0 {
+			return
+		}
+		if prev, ok := best[t.ID]; ok && (prev.score > score || prev.score == score && len(prev.name) <= len(name)) {
+			return
+		}
+		best[t.ID] = titleMatch{DocumentTitle: t, name: name, score: score}
+	}
+	for _, t := range titles {
+		consider(t, t.Title)
+	}
+	for _, a := range aliases {
+		consider(storage.DocumentTitle{ID: a.DocumentID, Path: a.Path, Title: a.Title}, a.Name)
+	}
+
+	matches := make([]titleMatch, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	slices.SortFunc(matches, func(a, b titleMatch) int {
+		return cmp.Or(
+			cmp.Compare(b.score, a.score),
+			cmp.Compare(len(a.name), len(b.name)),
+			cmp.Compare(a.Title, b.Title),
+			cmp.Compare(a.Path, b.Path),
+		)
+	})
+	return matches
+}
+
+// titleScore rates how well name matches q, both lowercased; 0 is no match.
+func titleScore(q, name string) int {
+	switch {
+	case name == q:
+		return matchExact
+	case strings.HasPrefix(name, q):
+		return matchPrefix
+	case strings.Contains(name, q):
+		return matchSubstring
+	}
+	words := strings.Fields(q)
+	if len(words) > 1 && !slices.ContainsFunc(words, func(w string) bool { return !strings.Contains(name, w) }) {
+		return matchWords
+	}
+	rest := name
+	for _, r := range strings.ReplaceAll(q, " ", "") {
+		i := strings.IndexRune(rest, r)
+		if i < 0 {
+			return 0
+		}
+		rest = rest[i+len(string(r)):]
+	}
+	return matchSubsequence
+}
+
+// pickMatch returns the ID of the document to open: the best match, or when
+// several match equally well, the one chosen on in from a numbered list.
+// Without a terminal to ask on it returns an error listing them instead.
+func pickMatch(q string, matches []titleMatch, in io.Reader, out io.Writer, interactive bool) (string, error) {
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no alias, path or title matches %q", q)
+	}
+	tied := 1
+	for tied < len(matches) && matches[tied].score == matches[0].score {
+		tied++
+	}
+	if tied == 1 {
+		return matches[0].ID, nil
+	}
+
+	var list strings.Builder
+	for i, m := range matches[:min(tied, maxPicks)] {
+		fmt.Fprintf(&list, "  %d. %s (%s)\n", i+1, m.Title, m.Path)
+	}
+	if tied > maxPicks {
+		fmt.Fprintf(&list, "  ...and %d more\n", tied-maxPicks)
+	}
+	if !interactive {
+		return "", fmt.Errorf("%d documents match %q:\n%sbe more specific, or open one by path", tied, q, list.String())
+	}
+	fmt.Fprintf(out, "%d documents match %q:\n%s", tied, q, list.String())
+	fmt.Fprintf(out, "Open which? [1-%d] ", min(tied, maxPicks))
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	n, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || n < 1 || n > min(tied, maxPicks) {
+		return "", fmt.Errorf("nothing opened")
+	}
+	return matches[n-1].ID, nil
+}
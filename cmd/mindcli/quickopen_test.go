@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestTitleScore(t *testing.T) {
+	tests := []struct {
+		q, name string
+		want    int
+	}{
+		{"standup", "standup", matchExact},
+		{"stand", "standup notes", matchPrefix},
+		{"notes", "standup notes", matchSubstring},
+		{"notes standup", "standup notes", matchWords},
+		{"stdnts", "standup notes", matchSubsequence},
+		{"retro", "standup notes", 0},
+	}
+	for _, tt := range tests {
+		if got := titleScore(tt.q, tt.name); got != tt.want {
+			t.Errorf("titleScore(%q, %q) = %d, want %d", tt.q, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchTitles(t *testing.T) {
+	titles := []storage.DocumentTitle{
+		{ID: "1", Path: "/n/standup-2024.md", Title: "Standup 2024"},
+		{ID: "2", Path: "/n/standup.md", Title: "Standup"},
+		{ID: "3", Path: "/n/ideas.md", Title: "Ideas"},
+		{ID: "4", Path: "/n/q3.md", Title: "Q3 planning"},
+	}
+	aliases := []storage.Alias{{Name: "plan", DocumentID: "4", Path: "/n/q3.md", Title: "Q3 planning"}}
+
+	ids := func(matches []titleMatch) string {
+		var s []string
+		for _, m := range matches {
+			s = append(s, m.ID)
+		}
+		return strings.Join(s, " ")
+	}
+	if got := ids(matchTitles("  STANDUP ", titles, aliases)); got != "2 1" {
+		t.Errorf("matchTitles(standup) = %q, want the exact title first", got)
+	}
+	// The alias matches better than the title, and counts once.
+	if got := matchTitles("plan", titles, aliases); len(got) != 1 || got[0].score != matchExact || got[0].name != "plan" {
+		t.Errorf("matchTitles(plan) = %+v, want the alias", got)
+	}
+	if got := matchTitles("", titles, aliases); got != nil {
+		t.Errorf("matchTitles(\"\") = %+v, want none", got)
+	}
+}
+
+func TestPickMatch(t *testing.T) {
+	matches := []titleMatch{
+		{DocumentTitle: storage.DocumentTitle{ID: "1", Path: "/n/a.md", Title: "Standup A"}, score: matchPrefix},
+		{DocumentTitle: storage.DocumentTitle{ID: "2", Path: "/n/b.md", Title: "Standup B"}, score: matchPrefix},
+		{DocumentTitle: storage.DocumentTitle{ID: "3", Path: "/n/c.md", Title: "Old standup"}, score: matchSubstring},
+	}
+
+	if id, err := pickMatch("stand", matches[2:], nil, nil, false); err != nil || id != "3" {
+		t.Errorf("pickMatch(one best) = %q, %v", id, err)
+	}
+
+	var out bytes.Buffer
+	id, err := pickMatch("stand", matches, strings.NewReader("2\n"), &out, true)
+	if err != nil || id != "2" {
+		t.Errorf("pickMatch(picked 2) = %q, %v", id, err)
+	}
+	if got := out.String(); !strings.Contains(got, "1. Standup A (/n/a.md)") || strings.Contains(got, "Old standup") || !strings.Contains(got, "[1-2]") {
+		t.Errorf("picker = %q, want the two tied matches", got)
+	}
+
+	if _, err := pickMatch("stand", matches, strings.NewReader("x\n"), &out, true); err == nil {
+		t.Error("pickMatch() with no valid choice: want an error")
+	}
+	if _, err := pickMatch("stand", matches, nil, nil, false); err == nil || !strings.Contains(err.Error(), "Standup B") {
+		t.Errorf("pickMatch() without a terminal: error = %v, want the matches listed", err)
+	}
+	if _, err := pickMatch("zzz", nil, nil, nil, false); err == nil {
+		t.Error("pickMatch() with no matches: want an error")
+	}
+}
+
+func TestQuickOpen(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	ctx := t.Context()
+	for _, doc := range []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Path: "/notes/standup.md", Title: "Weekly standup", ContentHash: "1"},
+		{ID: "2", Source: storage.SourceMarkdown, Path: "/notes/retro.md", Title: "Retro", ContentHash: "2"},
+	} {
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, want := range map[string]string{"/notes/retro.md": "2", "weekly": "1", "wkly stnd": "1"} {
+		if doc, err := quickOpen(db, name, nil, nil, false); err != nil || doc.ID != want {
+			t.Errorf("quickOpen(%q) = %v, %v, want document %s", name, doc, err, want)
+		}
+	}
+}
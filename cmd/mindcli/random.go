@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/internal/tui"
+)
+
+// runRandom shows a document picked at random, to rediscover forgotten
+// notes.
+func runRandom(args []string) error {
+	fs := flag.NewFlagSet("random", flag.ExitOnError)
+	tag := fs.String("tag", "", "Only pick documents with this tag")
+	olderThan := fs.Int("older-than", 0, "Only pick documents dated at least this many days ago")
+	open := fs.Bool("open", false, "Open the document in its default app")
+	_ = fs.Parse(args)
+	if *olderThan < 0 {
+		return fmt.Errorf("-older-than must not be negative")
+	}
+
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	var before time.Time
+	if *olderThan > 0 {
+		before = time.Now().AddDate(0, 0, -*olderThan)
+	}
+	ctx := context.Background()
+	doc, err := s.db.RandomDocument(ctx, *tag, before)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("no documents match")
+	}
+	if err != nil {
+		return err
+	}
+	printRandom(os.Stdout, doc, buildRedactor(s.cfg), s.cfg.Display.SnippetLength)
+
+	if *open {
+		if err := tui.OpenFile(doc.FilePath()); err != nil {
+			return fmt.Errorf("opening %s: %w", doc.FilePath(), err)
+		}
+		if err := s.db.RecordInteraction(ctx, doc.ID, storage.InteractionOpened); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// printRandom prints a document's title, where it is and when it dates
+// from, and a passage to jog the memory.
+func printRandom(w io.Writer, doc *storage.Document, redactor privacy.Redactor, snippetLen int) {
+	preview := doc.Preview
+	if preview == "" {
+		preview = passage(doc.Content, snippetLen)
+	}
+	fmt.Fprintf(w, "%s\n   %s [%s] from %s\n   %s\n",
+		doc.Title, doc.Path, sourceLabel(doc), doc.Date().Local().Format("2006-01-02"), redactor.Redact(preview))
+}
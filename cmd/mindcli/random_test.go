@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestPrintRandom(t *testing.T) {
+	doc := &storage.Document{
+		Title: "Old idea", Path: "/notes/idea.md", Source: storage.SourceMarkdown,
+		Content:    "A thought   worth\nkeeping.",
+		ModifiedAt: time.Date(2022, 5, 4, 10, 0, 0, 0, time.Local),
+	}
+	var buf bytes.Buffer
+	printRandom(&buf, doc, privacy.Redactor{}, 300)
+	want := "Old idea\n   /notes/idea.md [markdown] from 2022-05-04\n   A thought worth keeping.\n"
+	if buf.String() != want {
+		t.Errorf("printRandom() = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/readlater"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// readingQueue is the collection unread imported articles are added to.
+const readingQueue = "Reading queue"
+
+// runImportArticles converts a read-it-later export into markdown notes in
+// the notes folder, one per article, indexes them, and adds the unread ones
+// to the reading queue collection.
+func runImportArticles(format string, args []string) error {
+	usage := fmt.Sprintf("usage: mindcli import %s [--dir folder] [--queue collection] <export>", format)
+	fs := flag.NewFlagSet("import "+format, flag.ExitOnError)
+	dir := fs.String("dir", "", "Folder to write the notes to (default: articles/"+format+" in the notes folder)")
+	queue := fs.String("queue", readingQueue, "Collection to add unread articles to (\"\" to skip)")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New(usage)
+	}
+	articles, err := readlater.Parse(format, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(articles) == 0 {
+		return fmt.Errorf("no articles found in %s", fs.Arg(0))
+	}
+
+	s, err := openStores(openOpts{vectors: true, embedder: true, indexing: true})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	outDir := *dir
+	if outDir == "" {
+		notes, err := transcriptNotesDir(s.cfg)
+		if err != nil {
+			return err
+		}
+		outDir = filepath.Join(notes, "articles", format)
+	}
+	paths, err := writeArticleNotes(outDir, articles)
+	if err != nil {
+		return err
+	}
+
+	indexer := s.newIndexer(s.vectors)
+	ctx := context.Background()
+	failed := 0
+	for _, path := range paths {
+		if err := indexer.IndexFile(ctx, path); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: indexing %s: %v\n", path, err)
+			failed++
+		}
+	}
+	if err := indexer.SaveVectors(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: saving vectors: %v\n", err)
+	}
+
+	fmt.Printf("Imported %d articles into %s\n", len(paths), outDir)
+	if *queue != "" {
+		queued, err := queueArticles(ctx, s.db, *queue, articles, paths)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d unread articles are in the %q collection\n", queued, *queue)
+	}
+	if failed > 0 {
+		fmt.Printf("%d notes could not be indexed; is %s inside a markdown notes folder?\n", failed, outDir)
+	}
+	return nil
+}
+
+// writeArticleNotes writes the articles as markdown notes in dir and returns
+// their paths, in the same order. Notes from an earlier import of the same
+// articles are overwritten, so their read state follows the latest export.
+func writeArticleNotes(dir string, articles []readlater.Article) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating notes folder: %w", err)
+	}
+	paths := make([]string, 0, len(articles))
+	written := make(map[string]bool)
+	for _, a := range articles {
+		name := a.FileName()
+		// Articles whose titles differ only in punctuation share a slug.
+		stem := strings.TrimSuffix(name, ".md")
+		for i := 2; written[name]; i++ {
+			name = fmt.Sprintf("%s-%d.md", stem, i)
+		}
+		written[name] = true
+
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(a.Markdown()), 0o644); err != nil {
+			return paths, fmt.Errorf("writing article note: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// queueArticles adds the indexed notes of unread articles to the named
+// collection, creating it if needed, and takes read ones out of it. paths
+// holds each article's note. It returns how many articles are queued.
+func queueArticles(ctx context.Context, db *storage.DB, name string, articles []readlater.Article, paths []string) (int, error) {
+	col, err := db.GetCollectionByName(ctx, name)
+	if errors.Is(err, storage.ErrNotFound) {
+		col = &storage.Collection{Name: name, Description: "Saved articles still to read"}
+		err = db.CreateCollection(ctx, col)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("opening %s collection: %w", name, err)
+	}
+
+	queued := 0
+	for i, a := range articles {
+		doc, err := db.GetDocumentByPath(ctx, paths[i])
+		if errors.Is(err, storage.ErrNotFound) {
+			continue // not indexed
+		}
+		if err != nil {
+			return queued, err
+		}
+		if a.Read {
+			if err := db.RemoveFromCollection(ctx, col.ID, doc.ID); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return queued, err
+			}
+			continue
+		}
+		if err := db.AddToCollection(ctx, col.ID, doc.ID); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
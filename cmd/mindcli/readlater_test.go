@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/index/sources"
+	"github.com/J-1000/mindcli/internal/readlater"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestWriteArticleNotes(t *testing.T) {
+	saved := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	articles := []readlater.Article{
+		{Service: "Pocket", Title: "Deep Work", URL: "https://example.com/deep-work", Saved: saved, Tags: []string{"focus"}},
+		{Service: "Pocket", Title: "Deep work!", URL: "https://example.com/other", Read: true},
+	}
+	dir := filepath.Join(t.TempDir(), "articles")
+
+	paths, err := writeArticleNotes(dir, articles)
+	if err != nil {
+		t.Fatalf("writeArticleNotes() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "deep-work.md"), filepath.Join(dir, "deep-work-2.md")}
+	if len(paths) != 2 || paths[0] != want[0] || paths[1] != want[1] {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+
+	// The notes are indexed as markdown with the article details as metadata.
+	src := sources.NewMarkdownSource([]string{dir}, []string{".md"}, nil)
+	doc, err := src.Parse(context.Background(), sources.FileInfo{Path: paths[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Deep Work" || doc.Metadata["fm_url"] != "https://example.com/deep-work" || doc.Metadata["fm_status"] != "unread" {
+		t.Errorf("note = title %q metadata %v", doc.Title, doc.Metadata)
+	}
+	if doc.Metadata["tags"] != "article,pocket,focus" || !doc.DocumentDate.Equal(saved) {
+		t.Errorf("note tags %q date %v", doc.Metadata["tags"], doc.DocumentDate)
+	}
+}
+
+func TestQueueArticles(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	ctx := context.Background()
+
+	now := time.Now()
+	paths := []string{"/notes/articles/a.md", "/notes/articles/b.md", "/notes/articles/missing.md"}
+	for i, p := range paths[:2] {
+		doc := &storage.Document{ID: string(rune('a' + i)), Source: storage.SourceMarkdown, Path: p, ContentHash: p, IndexedAt: now, ModifiedAt: now}
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	articles := []readlater.Article{{Title: "A"}, {Title: "B"}, {Title: "Not indexed"}}
+
+	queued, err := queueArticles(ctx, db, readingQueue, articles, paths)
+	if err != nil {
+		t.Fatalf("queueArticles() error = %v", err)
+	}
+	if queued != 2 {
+		t.Errorf("queued = %d, want the two indexed articles", queued)
+	}
+
+	// Importing again after reading one takes it out of the queue.
+	articles[0].Read = true
+	if queued, err = queueArticles(ctx, db, readingQueue, articles, paths); err != nil || queued != 1 {
+		t.Fatalf("queueArticles() = %d, %v; want 1", queued, err)
+	}
+	col, err := db.GetCollectionByName(ctx, readingQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs, err := db.GetCollectionDocuments(ctx, col.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].ID != "b" {
+		t.Errorf("queue holds %d documents, want only b", len(docs))
+	}
+}
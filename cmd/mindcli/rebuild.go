@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+const (
+	// dbRecoveryHint follows errors about a damaged database.
+	dbRecoveryHint = "Run 'mindcli rebuild' to back it up and restore the latest snapshot, or start over if there is none."
+	// searchRecoveryHint follows errors opening the search index.
+	searchRecoveryHint = "If no other mindcli is running, the index may be damaged: run 'mindcli rebuild' to back it up and rebuild it from the database."
+)
+
+// openReadOnly falls back to opening the database read-only after openErr
+// kept it from opening normally, so what can still be read stays
+// searchable until it is rebuilt. Indexing needs to write, and gets openErr.
+func openReadOnly(dbPath string, openErr error, writing bool) (*storage.DB, error) {
+	fail := fmt.Errorf("opening database: %w", openErr)
+	if errors.Is(openErr, storage.ErrCorrupt) {
+		fail = fmt.Errorf("opening database: %w\n%s", openErr, dbRecoveryHint)
+	}
+	if writing {
+		return nil, fail
+	}
+	db, err := storage.OpenReadOnly(dbPath)
+	if err != nil {
+		return nil, fail
+	}
+	fmt.Fprintf(os.Stderr, "warning: %v\nOpened the database read-only; nothing can be changed until it is fixed.\n", fail)
+	return db, nil
+}
+
+// runRebuild checks the database and search index, moves whichever is
+// damaged aside, replaces it, and refills the search index and vectors from
+// the database.
+func runRebuild(args []string) error {
+	fs := flag.NewFlagSet("rebuild", flag.ExitOnError)
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("usage: mindcli rebuild")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	dataDir, err := cfg.DataDir()
+	if err != nil {
+		return fmt.Errorf("creating data directory: %w", err)
+	}
+	dbPath, err := cfg.DatabasePath()
+	if err != nil {
+		return fmt.Errorf("getting database path: %w", err)
+	}
+	suffix := ".damaged-" + time.Now().Format("20060102-150405")
+
+	replaced, err := rebuildDatabase(os.Stdout, dbPath, cfg.SnapshotDir(), suffix)
+	if err != nil {
+		return err
+	}
+	// The FTS5 index lives in the database and goes with it.
+	if cfg.Search.Backend != "fts5" {
+		if err := rebuildSearchIndex(os.Stdout, filepath.Join(dataDir, "search.bleve"), suffix); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println()
+	if err := runVerify([]string{"--repair"}); err != nil {
+		return err
+	}
+	if replaced {
+		fmt.Println("\nRun 'mindcli index' to index what changed since.")
+	}
+	return nil
+}
+
+// rebuildDatabase checks the database at dbPath and, when it is damaged,
+// moves it aside with suffix and restores the newest snapshot that isn't
+// damaged too, or leaves no database so the next open starts an empty one.
+// It reports whether the database was replaced. A database that can't be
+// opened for any other reason, e.g. its permissions, is left alone.
+func rebuildDatabase(w io.Writer, dbPath, snapshotDir, suffix string) (bool, error) {
+	err := checkDatabase(dbPath)
+	if err == nil {
+		fmt.Fprintln(w, "Database: ok")
+		return false, nil
+	}
+	if !errors.Is(err, storage.ErrCorrupt) {
+		return false, fmt.Errorf("opening database: %w", err)
+	}
+	fmt.Fprintf(w, "Database: %v\n", err)
+	backup, err := moveAside(dbPath, suffix, sqliteFiles(dbPath)...)
+	if err != nil {
+		return false, err
+	}
+	fmt.Fprintf(w, "  moved to %s\n", backup)
+
+	snaps, err := storage.ListSnapshots(snapshotDir)
+	if err != nil {
+		return true, err
+	}
+	for _, snap := range snaps {
+		if err := storage.RestoreSnapshot(dbPath, snap.Path); err != nil {
+			return true, err
+		}
+		if err := checkDatabase(dbPath); err == nil {
+			fmt.Fprintf(w, "  restored snapshot %s\n", snap.Name)
+			return true, nil
+		}
+		fmt.Fprintf(w, "  snapshot %s is damaged too\n", snap.Name)
+	}
+	for _, path := range sqliteFiles(dbPath) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return true, fmt.Errorf("removing damaged snapshot: %w", err)
+		}
+	}
+	fmt.Fprintln(w, "  no usable snapshot; starting with an empty database")
+	return true, nil
+}
+
+// checkDatabase opens the database at path and scans it for damage.
+func checkDatabase(path string) error {
+	db, err := storage.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return db.QuickCheck(context.Background())
+}
+
+// rebuildSearchIndex moves the Bleve index at path aside with suffix when it
+// can't be opened, so the next open starts an empty one for repair to fill.
+func rebuildSearchIndex(w io.Writer, path, suffix string) error {
+	idx, err := search.NewBleveIndex(path)
+	if err == nil {
+		_, err = idx.Count()
+		if closeErr := idx.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err == nil {
+		fmt.Fprintln(w, "Search index: ok")
+		return nil
+	}
+	fmt.Fprintf(w, "Search index: %v\n", err)
+	backup, err := moveAside(path, suffix, path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "  moved to %s\n", backup)
+	return nil
+}
+
+// moveAside renames each of paths that exists, all named after main, by
+// adding suffix to main's part of the name, and returns main's new name.
+// "mindcli.db-wal" becomes "mindcli.db.damaged-…-wal", so a SQLite backup
+// keeps its write-ahead log.
+func moveAside(main, suffix string, paths ...string) (string, error) {
+	for _, path := range paths {
+		if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		to := main + suffix + path[len(main):]
+		if err := os.Rename(path, to); err != nil {
+			return "", fmt.Errorf("backing up %s: %w", path, err)
+		}
+	}
+	return main + suffix, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func writeDamaged(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("this is not a sqlite database, just some text long enough"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRebuildDatabaseRestoresSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "mindcli.db")
+	snapDir := filepath.Join(dir, "snapshots")
+	ctx := context.Background()
+
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := db.InsertDocument(ctx, &storage.Document{ID: "a", Source: storage.SourceMarkdown, Path: "/a.md", ContentHash: "h", IndexedAt: now, ModifiedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	good, err := db.CreateSnapshot(ctx, snapDir, storage.SnapshotManual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+	// A newer snapshot that is damaged as well is skipped.
+	writeDamaged(t, filepath.Join(snapDir, "29991231-000000.000-manual.db"))
+	writeDamaged(t, dbPath)
+
+	var out bytes.Buffer
+	replaced, err := rebuildDatabase(&out, dbPath, snapDir, ".damaged-test")
+	if err != nil || !replaced {
+		t.Fatalf("rebuildDatabase() = %v, %v; want the database replaced", replaced, err)
+	}
+	if _, err := os.Stat(dbPath + ".damaged-test"); err != nil {
+		t.Errorf("damaged database not backed up: %v", err)
+	}
+	for _, want := range []string{"file is not a database", "moved to " + dbPath + ".damaged-test",
+		"29991231-000000.000-manual.db is damaged too", "restored snapshot " + good.Name} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	db, err = storage.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	if _, err := db.GetDocument(ctx, "a"); err != nil {
+		t.Errorf("restored database is missing the snapshotted document: %v", err)
+	}
+}
+
+func TestRebuildDatabase(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "mindcli.db")
+
+	// A healthy database is left alone.
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+	var out bytes.Buffer
+	if replaced, err := rebuildDatabase(&out, dbPath, filepath.Join(dir, "snapshots"), ".damaged-test"); err != nil || replaced {
+		t.Errorf("rebuildDatabase() of a healthy database = %v, %v", replaced, err)
+	}
+
+	// Without snapshots, the next open starts over.
+	writeDamaged(t, dbPath)
+	out.Reset()
+	if replaced, err := rebuildDatabase(&out, dbPath, filepath.Join(dir, "snapshots"), ".damaged-test"); err != nil || !replaced {
+		t.Fatalf("rebuildDatabase() = %v, %v; want the database replaced", replaced, err)
+	}
+	if !strings.Contains(out.String(), "starting with an empty database") {
+		t.Errorf("output = %q, want it to start over", out.String())
+	}
+	if _, err := os.Stat(dbPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("damaged database still in place: %v", err)
+	}
+}
+
+func TestRebuildSearchIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.bleve")
+	idx, err := search.NewBleveIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = idx.Close()
+
+	var out bytes.Buffer
+	if err := rebuildSearchIndex(&out, path, ".damaged-test"); err != nil || out.String() != "Search index: ok\n" {
+		t.Errorf("rebuildSearchIndex() of a healthy index = %q, %v", out.String(), err)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "index_meta.json"), []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := rebuildSearchIndex(&out, path, ".damaged-test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(path+".damaged-test", "index_meta.json")); err != nil {
+		t.Errorf("damaged index not backed up: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("damaged index still in place: %v", err)
+	}
+}
+
+func TestMoveAside(t *testing.T) {
+	dir := t.TempDir()
+	db := filepath.Join(dir, "mindcli.db")
+	for _, p := range []string{db, db + "-wal"} {
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backup, err := moveAside(db, ".damaged-x", sqliteFiles(db)...)
+	if err != nil || backup != db+".damaged-x" {
+		t.Fatalf("moveAside() = %q, %v", backup, err)
+	}
+	for _, p := range []string{db + ".damaged-x", db + ".damaged-x-wal"} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s missing: %v", p, err)
+		}
+	}
+}
+
+func TestOpenReadOnlyFallback(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "mindcli.db")
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+	damaged := fmt.Errorf("running migrations: %w: file is not a database", storage.ErrCorrupt)
+
+	if _, err := openReadOnly(dbPath, damaged, true); err == nil || !strings.Contains(err.Error(), "mindcli rebuild") {
+		t.Errorf("openReadOnly() for indexing = %v, want an error pointing at rebuild", err)
+	}
+	ro, err := openReadOnly(dbPath, damaged, false)
+	if err != nil {
+		t.Fatalf("openReadOnly() = %v", err)
+	}
+	defer func() { _ = ro.Close() }()
+	if !ro.ReadOnly() {
+		t.Error("fallback database isn't read-only")
+	}
+
+	writeDamaged(t, dbPath)
+	if _, err := openReadOnly(dbPath, damaged, false); err == nil {
+		t.Error("openReadOnly() of an unreadable database succeeded")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// runRecent lists the documents opened or indexed most recently, for
+// getting back to current work without a query.
+func runRecent(args []string) error {
+	fs := flag.NewFlagSet("recent", flag.ExitOnError)
+	opened := fs.Bool("opened", false, "List the documents you opened most recently (the default)")
+	indexed := fs.Bool("indexed", false, "List the documents indexed most recently")
+	limit := fs.Int("n", 20, "Number of documents to list")
+	_ = fs.Parse(args)
+	if *opened && *indexed {
+		return fmt.Errorf("usage: mindcli recent [--opened|--indexed] [-n 20]")
+	}
+	if *limit <= 0 {
+		return fmt.Errorf("-n must be positive")
+	}
+
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	var docs []*storage.Document
+	if *indexed {
+		docs, err = s.db.RecentlyIndexed(ctx, *limit)
+	} else {
+		docs, err = s.db.RecentInteractions(ctx, storage.InteractionOpened, *limit)
+	}
+	if err != nil {
+		return err
+	}
+	printRecent(os.Stdout, docs, *indexed)
+	return nil
+}
+
+// printRecent prints one document per entry, with when it was indexed for
+// the recently indexed list.
+func printRecent(w io.Writer, docs []*storage.Document, indexed bool) {
+	if len(docs) == 0 {
+		if indexed {
+			fmt.Fprintln(w, "Nothing indexed yet. Run 'mindcli index' first.")
+		} else {
+			fmt.Fprintln(w, "Nothing opened yet. Documents opened with 'mindcli open' or from the TUI show up here; try --indexed.")
+		}
+		return
+	}
+	for i, doc := range docs {
+		fmt.Fprintf(w, "%d. %s\n   %s [%s]", i+1, doc.Title, doc.Path, sourceLabel(doc))
+		if indexed {
+			fmt.Fprintf(w, " indexed %s", doc.IndexedAt.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestPrintRecent(t *testing.T) {
+	indexedAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local)
+	docs := []*storage.Document{
+		{Title: "Standup", Path: "/notes/standup.md", Source: storage.SourceMarkdown, IndexedAt: indexedAt},
+		{Title: "Paper", Path: "/papers/p.pdf", Source: storage.SourcePDF, IndexedAt: indexedAt},
+	}
+
+	var buf bytes.Buffer
+	printRecent(&buf, docs, false)
+	want := "1. Standup\n   /notes/standup.md [markdown]\n" +
+		"2. Paper\n   /papers/p.pdf [pdf]\n"
+	if buf.String() != want {
+		t.Errorf("printRecent(opened) output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	printRecent(&buf, docs[:1], true)
+	if want := "1. Standup\n   /notes/standup.md [markdown] indexed 2024-03-01 09:30\n"; buf.String() != want {
+		t.Errorf("printRecent(indexed) = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	printRecent(&buf, nil, false)
+	if !strings.Contains(buf.String(), "--indexed") {
+		t.Errorf("printRecent(none) = %q, want a pointer to --indexed", buf.String())
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/index"
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/tui"
+)
+
+// reloadConfig rereads and validates the config for a running command. Unlike
+// at startup, a bad redact pattern is an error, so a typo can't quietly stop
+// masking something.
+func reloadConfig() (*config.Config, privacy.Redactor, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, privacy.Redactor{}, err
+	}
+	redactor, errs := privacy.NewRedactor(cfg.Privacy.RedactPatterns)
+	if len(errs) > 0 {
+		return nil, privacy.Redactor{}, fmt.Errorf("invalid redact pattern: %w", errors.Join(errs...))
+	}
+	return cfg, redactor, nil
+}
+
+// applyTUIConfig applies the settings the TUI can change while running.
+func applyTUIConfig(m *tui.Model, cfg *config.Config) {
+	m.SetResultsLimit(cfg.Search.ResultsLimit)
+	m.SetPreviewLength(cfg.Display.PreviewPanelLength)
+	m.SetContextBudget(contextBudget(cfg))
+	m.SetHybridWeight(cfg.Search.HybridWeight)
+}
+
+// reloadTUIConfig is the TUI's config reloader.
+func reloadTUIConfig() (func(*tui.Model), error) {
+	cfg, redactor, err := reloadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return func(m *tui.Model) {
+		applyTUIConfig(m, cfg)
+		m.SetRedactor(redactor)
+	}, nil
+}
+
+// reloadOnHangup rereads the config whenever the process gets SIGHUP, until
+// ctx is done, applying the indexing throttle. Sources and paths are set up
+// once, so changes to them wait for a restart.
+func reloadOnHangup(ctx context.Context, indexer *index.Indexer) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				reloadWatchConfig(indexer, os.Stdout, os.Stderr)
+			}
+		}
+	}()
+}
+
+// reloadWatchConfig applies a reread config to a watching indexer, or says
+// why it kept the running one.
+func reloadWatchConfig(indexer *index.Indexer, stdout, stderr io.Writer) {
+	cfg, _, err := reloadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: config not reloaded: %v\n", err)
+		return
+	}
+	indexer.SetThrottle(cfg.Indexing.Throttle)
+	fmt.Fprintln(stdout, "Reloaded config; changes to sources and paths apply after a restart")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/index"
+)
+
+// writeTestConfig points the config at a file holding yaml.
+func writeTestConfig(t *testing.T, yaml string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MINDCLI_CONFIG_PATH", path)
+	t.Setenv("MINDCLI_STORAGE_PATH", t.TempDir())
+}
+
+func TestReloadConfig(t *testing.T) {
+	writeTestConfig(t, "search:\n  results_limit: 7\n")
+	cfg, _, err := reloadConfig()
+	if err != nil {
+		t.Fatalf("reloadConfig() error = %v", err)
+	}
+	if cfg.Search.ResultsLimit != 7 {
+		t.Errorf("results limit = %d, want the file's 7", cfg.Search.ResultsLimit)
+	}
+
+	writeTestConfig(t, "search:\n  hybrid_weight: 2\n")
+	if _, _, err := reloadConfig(); err == nil || !strings.Contains(err.Error(), "invalid configuration") {
+		t.Errorf("reloadConfig() error = %v, want the validation error", err)
+	}
+
+	writeTestConfig(t, "privacy:\n  redact_patterns: [\"(unclosed\"]\n")
+	if _, _, err := reloadConfig(); err == nil || !strings.Contains(err.Error(), "invalid redact pattern") {
+		t.Errorf("reloadConfig() error = %v, want the bad pattern reported", err)
+	}
+}
+
+func TestReloadWatchConfig(t *testing.T) {
+	indexer := &index.Indexer{}
+	var stdout, stderr bytes.Buffer
+
+	writeTestConfig(t, "indexing:\n  throttle:\n    max_files_per_second: -1\n")
+	reloadWatchConfig(indexer, &stdout, &stderr)
+	if !strings.Contains(stderr.String(), "config not reloaded") || stdout.Len() != 0 {
+		t.Errorf("stdout = %q, stderr = %q; want an invalid config kept out", stdout.String(), stderr.String())
+	}
+
+	stderr.Reset()
+	writeTestConfig(t, "indexing:\n  throttle:\n    max_files_per_second: 5\n")
+	reloadWatchConfig(indexer, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "Reloaded config") || stderr.Len() != 0 {
+		t.Errorf("stdout = %q, stderr = %q; want the config reloaded", stdout.String(), stderr.String())
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/J-1000/mindcli/internal/index"
+	"github.com/J-1000/mindcli/internal/query"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// lazySemantic sets up the vector store, embedder and hybrid searcher of s
+// on first use, so the TUI can draw before the vectors are loaded.
+type lazySemantic struct {
+	s *stores
+
+	once        sync.Once
+	indexerOnce sync.Once
+	indexer     *index.Indexer
+}
+
+// hybrid loads the vector store and embedder and returns the hybrid
+// searcher, or nil when there are no vectors to search. Calls after the
+// first return what it set up.
+func (l *lazySemantic) hybrid() *query.HybridSearcher {
+	l.once.Do(func() {
+		s := l.s
+		s.openVectors(false)
+		s.openEmbedder(false)
+		if s.vectors != nil && s.embedder != nil {
+			s.hybrid = query.NewHybridSearcher(s.search, s.vectors, s.embedder, s.db, s.cfg.Search.HybridWeight)
+		}
+	})
+	return l.s.hybrid
+}
+
+// settle waits for a load in progress to finish, and stops one from
+// starting, so s can be closed.
+func (l *lazySemantic) settle() {
+	l.once.Do(func() {})
+}
+
+// index returns the indexer for the in-app "index now" action. It needs the
+// vectors, so it waits for them to load and creates an empty store when
+// there are none yet, so embeddings can be added on a first index.
+func (l *lazySemantic) index() *index.Indexer {
+	l.indexerOnce.Do(func() {
+		l.hybrid()
+		s := l.s
+		if s.vectors == nil {
+			if vs, err := storage.NewVectorStore(filepath.Join(s.dataDir, "vectors.graph")); err == nil {
+				vs.SetModel(s.cfg.Embeddings.Model)
+				s.vectors = vs
+			}
+		}
+		l.indexer = s.newIndexer(s.vectors)
+	})
+	return l.indexer
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestLazySemantic(t *testing.T) {
+	open := func(t *testing.T, dataDir string) *stores {
+		t.Helper()
+		db, err := storage.Open(filepath.Join(dataDir, "test.db"))
+		if err != nil {
+			t.Fatalf("Failed to open database: %v", err)
+		}
+		searchIndex, err := search.NewBleveIndex(filepath.Join(dataDir, "search.bleve"))
+		if err != nil {
+			t.Fatalf("Failed to create search index: %v", err)
+		}
+		cfg := config.Default()
+		cfg.Embeddings.OllamaURL = "http://127.0.0.1:1" // never reached
+		s := &stores{cfg: cfg, dataDir: dataDir, db: db, search: searchIndex}
+		t.Cleanup(s.Close)
+		return s
+	}
+
+	t.Run("no vectors yet", func(t *testing.T) {
+		s := open(t, t.TempDir())
+		semantic := &lazySemantic{s: s}
+		if s.embedder != nil || s.vectors != nil {
+			t.Fatal("stores loaded before first use")
+		}
+		if h := semantic.hybrid(); h != nil {
+			t.Errorf("hybrid() = %v, want nil without vectors", h)
+		}
+		if s.embedder == nil {
+			t.Error("hybrid() did not set up the embedder")
+		}
+		// Indexing from the TUI creates the vector store it adds to.
+		if semantic.index() == nil || s.vectors == nil {
+			t.Error("index() did not create a vector store")
+		}
+	})
+
+	t.Run("vectors on disk", func(t *testing.T) {
+		dataDir := t.TempDir()
+		vs, err := storage.NewVectorStore(filepath.Join(dataDir, "vectors.graph"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vs.Add("doc", []float32{1, 0, 0}); err != nil {
+			t.Fatal(err)
+		}
+		if err := vs.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		semantic := &lazySemantic{s: open(t, dataDir)}
+		h := semantic.hybrid()
+		if h == nil {
+			t.Fatal("hybrid() = nil, want a searcher over the saved vectors")
+		}
+		if again := semantic.hybrid(); again != h {
+			t.Error("hybrid() loaded twice")
+		}
+	})
+
+	t.Run("settled before use", func(t *testing.T) {
+		s := open(t, t.TempDir())
+		semantic := &lazySemantic{s: s}
+		semantic.settle()
+		if semantic.hybrid(); s.embedder != nil {
+			t.Error("hybrid() loaded after settle")
+		}
+	})
+}
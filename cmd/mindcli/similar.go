@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/query"
+)
+
+// runSimilar finds documents related to a passage of text, given with
+// --text, read from --file, or piped on stdin.
+func runSimilar(args []string) error {
+	fs := flag.NewFlagSet("similar", flag.ExitOnError)
+	text := fs.String("text", "", "Passage to find related notes for")
+	file := fs.String("file", "", "Read the passage from a file (- for stdin)")
+	limit := fs.Int("limit", 0, "Maximum number of results (default: search.results_limit)")
+	_ = fs.Parse(args)
+
+	passage, err := similarInput(*text, *file, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	s, err := openStores(openOpts{vectors: true, embedder: true, hybrid: true})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	h := s.hybrid
+	if h == nil {
+		fmt.Fprintln(os.Stderr, "warning: no vector index (run 'mindcli index' with Ollama/OpenAI available); matching on key terms instead")
+		h = query.NewHybridSearcher(s.search, nil, nil, s.db, s.cfg.Search.HybridWeight)
+	}
+	results, err := h.SimilarToText(context.Background(), passage, resultsLimit(s.cfg, *limit))
+	if err != nil {
+		return fmt.Errorf("searching: %w", err)
+	}
+	if len(results) == 0 {
+		fmt.Println("No related documents found.")
+		return nil
+	}
+	printSearchResults(os.Stdout, results, buildRedactor(s.cfg), true, s.cfg.Display.SnippetLength)
+	return nil
+}
+
+// similarInput returns the passage from --text, --file, or stdin when
+// neither is given and stdin isn't a terminal.
+func similarInput(text, file string, stdin *os.File) (string, error) {
+	const usage = `usage: mindcli similar --text "passage" | --file path | < file`
+	var data []byte
+	var err error
+	switch {
+	case text != "" && file != "":
+		return "", fmt.Errorf("use either --text or --file")
+	case text != "":
+		data = []byte(text)
+	case file == "-":
+		data, err = io.ReadAll(stdin)
+	case file != "":
+		data, err = os.ReadFile(file)
+	default:
+		if info, statErr := stdin.Stat(); statErr != nil || info.Mode()&os.ModeCharDevice != 0 {
+			return "", fmt.Errorf(usage)
+		}
+		data, err = io.ReadAll(stdin)
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading passage: %w", err)
+	}
+	passage := strings.TrimSpace(string(data))
+	if passage == "" {
+		return "", fmt.Errorf(usage)
+	}
+	return passage, nil
+}
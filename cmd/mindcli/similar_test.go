@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestSimilarInput(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "passage.txt")
+	if err := os.WriteFile(file, []byte("  from a file\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = stdin.Close() }()
+
+	if got, err := similarInput(" pasted text ", "", stdin); err != nil || got != "pasted text" {
+		t.Errorf("--text: got %q, %v", got, err)
+	}
+	if got, err := similarInput("", file, stdin); err != nil || got != "from a file" {
+		t.Errorf("--file: got %q, %v", got, err)
+	}
+	// Piped input is read when no flag is given.
+	if got, err := similarInput("", "", stdin); err != nil || got != "from a file" {
+		t.Errorf("stdin: got %q, %v", got, err)
+	}
+	if _, err := similarInput("text", file, stdin); err == nil {
+		t.Error("expected an error for both --text and --file")
+	}
+	if _, err := similarInput("", filepath.Join(dir, "missing.txt"), stdin); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestPrintSearchResultsPassages(t *testing.T) {
+	results := storage.SearchResults{
+		{Document: &storage.Document{Title: "Go", Path: "/go.md", Source: storage.SourceMarkdown},
+			ChunkID: "1:0", VectorScore: 0.87, Highlights: []string{"goroutines\nand channels"}},
+		{Document: &storage.Document{Title: "Rust", Path: "/rust.md", Source: storage.SourceMarkdown, Content: "ownership",
+			Metadata: map[string]string{"vault": "work"}}, Score: 0.5},
+		{Document: &storage.Document{Title: "Zig", Path: "/zig.md", Source: storage.SourceMarkdown, Content: "Comptime runs code. At compile time, mostly."},
+			Score: 0.4},
+	}
+	var buf bytes.Buffer
+	printSearchResults(&buf, results, privacy.Redactor{}, true, 30)
+	out := buf.String()
+	for _, want := range []string{"(similarity: 0.87)", "> goroutines and channels", "[markdown vault:work] (score: 0.50)", "ownership", "Comptime runs code. ...\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// snapshotPolicy builds the automatic snapshot policy from cfg.
+func snapshotPolicy(cfg *config.Config) storage.SnapshotPolicy {
+	return storage.SnapshotPolicy{
+		Dir:      cfg.SnapshotDir(),
+		Keep:     cfg.Storage.Snapshots.Count,
+		Interval: time.Duration(cfg.Storage.Snapshots.IntervalHours) * time.Hour,
+	}
+}
+
+// snapshotBeforeIndexing takes the automatic snapshot preceding an index run:
+// always before a full re-index, otherwise only when one is due. Failures
+// are reported but don't stop indexing.
+func snapshotBeforeIndexing(ctx context.Context, db *storage.DB, force bool) {
+	var snap *storage.Snapshot
+	var err error
+	if force {
+		snap, err = db.AutoSnapshot(ctx, "pre-reindex")
+	} else {
+		snap, err = db.PeriodicSnapshot(ctx)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: database snapshot: %v\n", err)
+		return
+	}
+	if snap != nil {
+		fmt.Printf("Saved database snapshot %s\n", snap.Name)
+	}
+}
+
+func runSnapshot(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: mindcli snapshot <create|list|restore>")
+	}
+
+	switch args[0] {
+	case "create":
+		s, err := openStores(openOpts{})
+		if err != nil {
+			return err
+		}
+		defer s.Close()
+
+		snap, err := s.db.CreateSnapshot(context.Background(), s.cfg.SnapshotDir(), storage.SnapshotManual)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created snapshot %s (%s)\n", snap.Name, humanSize(snap.Size))
+		return nil
+
+	case "list":
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		snaps, err := storage.ListSnapshots(cfg.SnapshotDir())
+		if err != nil {
+			return err
+		}
+		printSnapshots(os.Stdout, snaps)
+		return nil
+
+	case "restore":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: mindcli snapshot restore <name>")
+		}
+		return restoreSnapshot(args[1])
+
+	default:
+		return fmt.Errorf("unknown snapshot subcommand %q: use create, list or restore", args[0])
+	}
+}
+
+// restoreSnapshot replaces the database with the named snapshot, first
+// snapshotting the current database so the restore can itself be undone.
+func restoreSnapshot(name string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	snaps, err := storage.ListSnapshots(cfg.SnapshotDir())
+	if err != nil {
+		return err
+	}
+	snap := findSnapshot(snaps, name)
+	if snap == nil {
+		return fmt.Errorf("no snapshot named %q (see 'mindcli snapshot list')", name)
+	}
+
+	dbPath, err := cfg.DatabasePath()
+	if err != nil {
+		return fmt.Errorf("getting database path: %w", err)
+	}
+	if _, err := os.Stat(dbPath); err == nil {
+		db, err := storage.Open(dbPath)
+		if err != nil {
+			return fmt.Errorf("opening current database: %w", err)
+		}
+		backup, err := db.CreateSnapshot(context.Background(), cfg.SnapshotDir(), "pre-restore")
+		closeErr := db.Close()
+		if err != nil {
+			return fmt.Errorf("saving current database: %w", err)
+		}
+		if closeErr != nil {
+			return fmt.Errorf("closing database: %w", closeErr)
+		}
+		fmt.Printf("Saved current database as %s\n", backup.Name)
+	}
+
+	if err := storage.RestoreSnapshot(dbPath, snap.Path); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s.\n", snap.Name)
+	if cfg.Search.Backend != "fts5" {
+		fmt.Println("The search index and vectors are not part of snapshots; run 'mindcli reindex' to rebuild them.")
+	} else {
+		fmt.Println("Vectors are not part of snapshots; run 'mindcli reindex' to rebuild them.")
+	}
+	return nil
+}
+
+// findSnapshot returns the snapshot whose name matches name, with or without
+// the .db extension.
+func findSnapshot(snaps []storage.Snapshot, name string) *storage.Snapshot {
+	name = strings.TrimSuffix(name, ".db") + ".db"
+	for i := range snaps {
+		if snaps[i].Name == name {
+			return &snaps[i]
+		}
+	}
+	return nil
+}
+
+// printSnapshots writes the snapshot list, newest first.
+func printSnapshots(w io.Writer, snaps []storage.Snapshot) {
+	if len(snaps) == 0 {
+		fmt.Fprintln(w, "No snapshots.")
+		return
+	}
+	for _, s := range snaps {
+		fmt.Fprintf(w, "  %-40s %s  %-13s %s\n",
+			strings.TrimSuffix(s.Name, ".db"), s.CreatedAt.Format("2006-01-02 15:04"), s.Reason, humanSize(s.Size))
+	}
+}
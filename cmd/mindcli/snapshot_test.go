@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestSnapshotPolicy(t *testing.T) {
+	cfg := config.Default()
+	cfg.Storage.Path = "/data"
+	cfg.Storage.Snapshots = config.SnapshotsConfig{Count: 3, IntervalHours: 12}
+
+	p := snapshotPolicy(cfg)
+	if p.Dir != cfg.SnapshotDir() || p.Keep != 3 || p.Interval != 12*time.Hour {
+		t.Errorf("snapshotPolicy = %+v", p)
+	}
+}
+
+func TestFindSnapshot(t *testing.T) {
+	snaps := []storage.Snapshot{
+		{Name: "20240302-080000.000-periodic.db"},
+		{Name: "20240301-120000.000-manual.db"},
+	}
+	for _, name := range []string{"20240301-120000.000-manual", "20240301-120000.000-manual.db"} {
+		if got := findSnapshot(snaps, name); got == nil || got.Name != snaps[1].Name {
+			t.Errorf("findSnapshot(%q) = %v, want %s", name, got, snaps[1].Name)
+		}
+	}
+	if got := findSnapshot(snaps, "missing"); got != nil {
+		t.Errorf("findSnapshot(missing) = %v, want nil", got)
+	}
+}
+
+func TestPrintSnapshots(t *testing.T) {
+	var buf bytes.Buffer
+	printSnapshots(&buf, nil)
+	if !strings.Contains(buf.String(), "No snapshots") {
+		t.Errorf("empty output = %q", buf.String())
+	}
+
+	buf.Reset()
+	printSnapshots(&buf, []storage.Snapshot{{
+		Name:      "20240301-120000.000-pre-reindex.db",
+		Reason:    "pre-reindex",
+		CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local),
+		Size:      2048,
+	}})
+	out := buf.String()
+	for _, want := range []string{"20240301-120000.000-pre-reindex ", "2024-03-01 12:00", "pre-reindex", "2.0 KB"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const spinnerInterval = 80 * time.Millisecond
+
+// spinner animates a status line while a slow step runs, such as retrieval
+// before an answer starts streaming. A nil spinner does nothing, which is
+// what newSpinner returns when output isn't a terminal.
+type spinner struct {
+	out io.Writer
+
+	mu   sync.Mutex
+	msg  string
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newSpinner returns a spinner drawing on out, or nil when disabled.
+func newSpinner(out io.Writer, enabled bool) *spinner {
+	if !enabled {
+		return nil
+	}
+	return &spinner{out: out}
+}
+
+// Start shows msg with an animation, replacing the message if the spinner
+// is already running.
+func (s *spinner) Start(msg string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msg = msg
+	if s.stop != nil {
+		return
+	}
+	s.stop, s.done = make(chan struct{}), make(chan struct{})
+	go s.run(s.stop, s.done)
+}
+
+func (s *spinner) run(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		s.mu.Lock()
+		fmt.Fprintf(s.out, "\r\033[K%s %s", spinnerFrames[frame%len(spinnerFrames)], s.msg)
+		s.mu.Unlock()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop ends the animation and clears its line. It is safe to call when the
+// spinner isn't running.
+func (s *spinner) Stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+	fmt.Fprint(s.out, "\r\033[K")
+}
+
+// phaseTimings records how long each step of a command took, for --verbose.
+type phaseTimings struct {
+	start  time.Time
+	last   time.Time
+	phases []string
+}
+
+func newPhaseTimings() *phaseTimings {
+	now := time.Now()
+	return &phaseTimings{start: now, last: now}
+}
+
+// Done records that the named phase ended now.
+func (p *phaseTimings) Done(name string) {
+	now := time.Now()
+	p.add(name, now.Sub(p.last))
+	p.last = now
+}
+
+func (p *phaseTimings) add(name string, d time.Duration) {
+	p.phases = append(p.phases, name+" "+d.Round(time.Millisecond).String())
+}
+
+// String lists the phases in order, then the total.
+func (p *phaseTimings) String() string {
+	return strings.Join(p.phases, ", ") + ", total " + p.last.Sub(p.start).Round(time.Millisecond).String()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer safe to write from the spinner goroutine.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSpinner(t *testing.T) {
+	var out lockedBuffer
+	s := newSpinner(&out, true)
+	s.Start("Searching your notes…")
+	s.Start("Generating answer…")
+	time.Sleep(2 * spinnerInterval)
+	s.Stop()
+	s.Stop()
+
+	got := out.String()
+	if !strings.Contains(got, "Generating answer…") {
+		t.Errorf("spinner output %q doesn't show the latest message", got)
+	}
+	if !strings.HasSuffix(got, "\r\033[K") {
+		t.Errorf("spinner output %q doesn't end by clearing the line", got)
+	}
+}
+
+func TestSpinnerDisabled(t *testing.T) {
+	var out lockedBuffer
+	s := newSpinner(&out, false)
+	if s != nil {
+		t.Fatal("newSpinner() with output piped should return nil")
+	}
+	s.Start("Searching your notes…")
+	s.Stop()
+	if out.String() != "" {
+		t.Errorf("disabled spinner wrote %q", out.String())
+	}
+}
+
+func TestPhaseTimings(t *testing.T) {
+	p := newPhaseTimings()
+	p.add("retrieval", 120*time.Millisecond)
+	p.add("generation", 2*time.Second)
+	p.last = p.start.Add(2120 * time.Millisecond)
+	if got, want := p.String(), "retrieval 120ms, generation 2s, total 2.12s"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
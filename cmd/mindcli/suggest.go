@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/J-1000/mindcli/internal/query"
+)
+
+// suggestAlternatives offers alternatives to parsed, a query that found
+// nothing, running them the way the search command does.
+func suggestAlternatives(ctx context.Context, s *stores, parsed query.ParsedQuery, limit int) query.Suggestions {
+	run := func(ctx context.Context, q query.ParsedQuery) (int, error) {
+		results, err := searchResults(ctx, s, q, limit)
+		return len(results), err
+	}
+	return query.Suggest(ctx, s.search, s.hybrid, parsed, run)
+}
+
+// printSuggestions prints the alternatives to a query that found nothing.
+func printSuggestions(w io.Writer, sugg query.Suggestions) {
+	if sugg.Spelling != "" {
+		fmt.Fprintf(w, "Did you mean: %s\n", sugg.Spelling)
+	}
+	for _, b := range sugg.Broader {
+		fmt.Fprintf(w, "Without %s: %d %s\n", b.Dropped, b.Found, plural(b.Found, "result", "results"))
+	}
+	if len(sugg.Nearest) > 0 {
+		fmt.Fprintln(w, "Nearest in meaning:")
+		for _, r := range sugg.Nearest {
+			fmt.Fprintf(w, "  %s\n    %s [%s]\n", r.Document.Title, r.Document.Path, sourceLabel(r.Document))
+		}
+	}
+}
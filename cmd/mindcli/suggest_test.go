@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/query"
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestSuggestAlternatives(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer closeTestDB(t, db)
+	searchIndex, err := search.NewBleveIndex(filepath.Join(tmpDir, "search.bleve"))
+	if err != nil {
+		t.Fatalf("Failed to create search index: %v", err)
+	}
+	defer closeTestIndex(t, searchIndex)
+
+	ctx := context.Background()
+	now := time.Now()
+	doc := &storage.Document{ID: "1", Source: storage.SourceMarkdown, Path: "/notes/k8s.md", Title: "Kubernetes",
+		Content: "Kubernetes deployment notes", ContentHash: "h1", IndexedAt: now, ModifiedAt: now}
+	if err := db.InsertDocument(ctx, doc); err != nil {
+		t.Fatalf("Failed to insert doc: %v", err)
+	}
+	if err := searchIndex.Index(ctx, doc); err != nil {
+		t.Fatalf("Failed to index doc: %v", err)
+	}
+	s := &stores{cfg: config.Default(), db: db, search: searchIndex}
+
+	var out bytes.Buffer
+	printSuggestions(&out, suggestAlternatives(ctx, s, query.ParseQuery("kubernetes source:pdf"), 10))
+	if got, want := out.String(), "Without source:pdf: 1 result\n"; got != want {
+		t.Errorf("suggestions for a filtered query =\n%s\nwant\n%s", got, want)
+	}
+
+	out.Reset()
+	printSuggestions(&out, suggestAlternatives(ctx, s, query.ParseQuery("kubernets deploymnt"), 10))
+	if got, want := out.String(), "Did you mean: kubernetes deployment\n"; got != want {
+		t.Errorf("suggestions for misspelled words =\n%s\nwant\n%s", got, want)
+	}
+}
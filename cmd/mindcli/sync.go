@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/devicesync"
+)
+
+func runSync(args []string) error {
+	mode := devicesync.Both
+	if len(args) > 0 {
+		switch args[0] {
+		case "push":
+			mode = devicesync.Push
+		case "pull":
+			mode = devicesync.Pull
+		default:
+			return fmt.Errorf("unknown sync subcommand %q: use push or pull, or no argument for both", args[0])
+		}
+	}
+
+	s, err := openStores(openOpts{})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	remote, err := devicesync.ParseRemote(s.cfg.Sync.Remote, filepath.Join(s.dataDir, "sync"))
+	if err != nil {
+		return err
+	}
+	device, err := syncDevice(s.cfg)
+	if err != nil {
+		return err
+	}
+
+	res, err := devicesync.NewSyncer(s.db, remote, device).Sync(context.Background(), mode)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Synced as %q: %d local change(s)", device, res.LocalChanges)
+	if mode&devicesync.Pull != 0 {
+		fmt.Printf(", %d applied from %d other device(s)", res.Applied, res.Devices)
+	}
+	fmt.Println(".")
+	if res.Pending > 0 {
+		fmt.Printf("%d change(s) are waiting for their documents to be indexed on this machine.\n", res.Pending)
+	}
+	return nil
+}
+
+// syncDevice returns the configured device name, defaulting to the hostname.
+func syncDevice(cfg *config.Config) (string, error) {
+	if cfg.Sync.Device != "" {
+		return cfg.Sync.Device, nil
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("getting hostname (set sync.device): %w", err)
+	}
+	return host, nil
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/config"
+)
+
+func TestSyncDevice(t *testing.T) {
+	cfg := &config.Config{}
+	host, _ := os.Hostname()
+	if got, err := syncDevice(cfg); err != nil || got != host {
+		t.Errorf("syncDevice() = %q, %v, want hostname %q", got, err, host)
+	}
+
+	cfg.Sync.Device = "laptop"
+	if got, _ := syncDevice(cfg); got != "laptop" {
+		t.Errorf("syncDevice() = %q, want configured laptop", got)
+	}
+}
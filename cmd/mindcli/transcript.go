@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/query"
+)
+
+// transcriptNotesDir returns the notes folder answers are saved into: the
+// first configured markdown path.
+func transcriptNotesDir(cfg *config.Config) (string, error) {
+	if !cfg.Sources.Markdown.Enabled || len(cfg.Sources.Markdown.Paths) == 0 {
+		return "", errors.New("no markdown notes folder configured")
+	}
+	return cfg.Sources.Markdown.Paths[0], nil
+}
+
+// writeTranscriptFile writes t as a markdown note in dir and returns its path.
+// An existing file is never overwritten; a numeric suffix is added instead.
+func writeTranscriptFile(dir string, t query.Transcript) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("creating notes folder: %w", err)
+	}
+
+	name := t.FileName()
+	stem := strings.TrimSuffix(name, ".md")
+	for i := 2; ; i++ {
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			name = fmt.Sprintf("%s-%d.md", stem, i)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("creating transcript: %w", err)
+		}
+		if _, err := f.WriteString(t.Markdown()); err != nil {
+			_ = f.Close()
+			return "", fmt.Errorf("writing transcript: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return "", fmt.Errorf("closing transcript: %w", err)
+		}
+		return path, nil
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/query"
+)
+
+func TestTranscriptNotesDir(t *testing.T) {
+	cfg := config.Default()
+	cfg.Sources.Markdown.Paths = []string{"/notes", "/more"}
+	dir, err := transcriptNotesDir(cfg)
+	if err != nil {
+		t.Fatalf("transcriptNotesDir() error = %v", err)
+	}
+	if dir != "/notes" {
+		t.Errorf("transcriptNotesDir() = %q, want /notes", dir)
+	}
+
+	cfg.Sources.Markdown.Paths = nil
+	if _, err := transcriptNotesDir(cfg); err == nil {
+		t.Error("transcriptNotesDir() with no paths should fail")
+	}
+}
+
+func TestWriteTranscriptFileDoesNotOverwrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "answers")
+	tr := query.Transcript{Question: "Go tips", Answer: "Use gofmt.", CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+
+	first, err := writeTranscriptFile(dir, tr)
+	if err != nil {
+		t.Fatalf("writeTranscriptFile() error = %v", err)
+	}
+	second, err := writeTranscriptFile(dir, tr)
+	if err != nil {
+		t.Fatalf("writeTranscriptFile() error = %v", err)
+	}
+
+	if filepath.Base(first) != "2024-03-01-go-tips.md" {
+		t.Errorf("first = %q", first)
+	}
+	if filepath.Base(second) != "2024-03-01-go-tips-2.md" {
+		t.Errorf("second = %q", second)
+	}
+	data, err := os.ReadFile(second)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), "Use gofmt.") {
+		t.Errorf("transcript content = %q", data)
+	}
+}
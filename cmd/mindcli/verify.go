@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/J-1000/mindcli/internal/index"
+)
+
+// runVerify cross-checks the database, search index and vector store, and
+// with --repair fixes what it can.
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	repair := fs.Bool("repair", false, "Fix inconsistencies by re-indexing the affected documents")
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("usage: mindcli verify [--repair]")
+	}
+
+	s, err := openStores(openOpts{vectors: true, embedder: *repair, indexing: *repair})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	indexer := s.newIndexer(s.vectors)
+	report, err := indexer.Verify(ctx)
+	if err != nil {
+		return err
+	}
+	printVerifyReport(os.Stdout, report)
+	if report.OK() {
+		return nil
+	}
+	if !*repair {
+		if report.Repairable() {
+			fmt.Println("\nRun 'mindcli verify --repair' to fix these.")
+		}
+		return fmt.Errorf("index is inconsistent")
+	}
+
+	indexer.SetProgressReporter(newConsoleProgressReporter())
+	stats, repairErr := indexer.Repair(ctx, report)
+	if err := indexer.SaveVectors(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: saving vectors: %v\n", err)
+	}
+	fmt.Printf("\nRepaired: %d re-indexed, %d stale entries removed, %d orphan chunks removed, %d re-embedded\n",
+		stats.Reindexed, stats.Removed, stats.Orphans, stats.Reembedded)
+	if repairErr != nil {
+		return fmt.Errorf("repairing: %w", repairErr)
+	}
+	if report.ExtraVectors > 0 {
+		fmt.Println("Run 'mindcli reindex' to drop the extra vectors.")
+	}
+	return nil
+}
+
+// printVerifyReport prints the counts Verify compared and each problem it
+// found.
+func printVerifyReport(w io.Writer, r *index.VerifyReport) {
+	fmt.Fprintf(w, "Documents:      %d\n", r.Documents)
+	fmt.Fprintf(w, "Search entries: %d\n", r.SearchEntries)
+	fmt.Fprintf(w, "Chunks:         %d\n", r.Chunks)
+	if r.Vectors >= 0 {
+		fmt.Fprintf(w, "Vectors:        %d (dim %d)\n", r.Vectors, r.VectorDim)
+	} else {
+		fmt.Fprintln(w, "Vectors:        none")
+	}
+	if r.OK() {
+		fmt.Fprintln(w, "\nok index is consistent")
+		return
+	}
+	fmt.Fprintln(w)
+	if n := len(r.MissingFromSearch); n > 0 {
+		fmt.Fprintf(w, "x %d documents missing from the search index\n", n)
+	}
+	if n := len(r.StaleInSearch); n > 0 {
+		fmt.Fprintf(w, "x %d search entries without a document\n", n)
+	}
+	if n := len(r.OrphanChunks); n > 0 {
+		fmt.Fprintf(w, "x %d orphan chunks whose document is gone\n", n)
+	}
+	if n := len(r.MissingVectors); n > 0 {
+		fmt.Fprintf(w, "x %d documents with chunks missing vectors\n", n)
+	}
+	if n := len(r.WrongDimension); n > 0 {
+		fmt.Fprintf(w, "x %d documents with vectors of the wrong dimension\n", n)
+	}
+	if r.ExtraVectors > 0 {
+		fmt.Fprintf(w, "x %d vectors without a chunk (run 'mindcli reindex' to drop them)\n", r.ExtraVectors)
+	}
+}
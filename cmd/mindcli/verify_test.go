@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/index"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestPrintVerifyReport(t *testing.T) {
+	var buf bytes.Buffer
+	printVerifyReport(&buf, &index.VerifyReport{Documents: 2, SearchEntries: 2, Vectors: -1})
+	if out := buf.String(); !strings.Contains(out, "Vectors:        none") || !strings.Contains(out, "ok index is consistent") {
+		t.Errorf("consistent report = %q", out)
+	}
+
+	buf.Reset()
+	printVerifyReport(&buf, &index.VerifyReport{
+		Documents:         3,
+		SearchEntries:     2,
+		Chunks:            4,
+		Vectors:           5,
+		VectorDim:         768,
+		MissingFromSearch: []string{"a"},
+		OrphanChunks:      []*storage.Chunk{{ID: "gone:0"}, {ID: "gone:1"}},
+		ExtraVectors:      1,
+	})
+	out := buf.String()
+	for _, want := range []string{
+		"Vectors:        5 (dim 768)",
+		"x 1 documents missing from the search index",
+		"x 2 orphan chunks",
+		"x 1 vectors without a chunk",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report = %q, want it to contain %q", out, want)
+		}
+	}
+	if strings.Contains(out, "ok index") || strings.Contains(out, "wrong dimension") {
+		t.Errorf("report = %q, lists problems it doesn't have", out)
+	}
+}
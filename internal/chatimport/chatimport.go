@@ -0,0 +1,174 @@
+// Package chatimport converts chat exports from WhatsApp, Telegram and Slack
+// into markdown notes, one per conversation or per day, so that important
+// discussions can be indexed and searched like any other note.
+package chatimport
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formats lists the supported export formats.
+var Formats = []string{"whatsapp", "telegram", "slack"}
+
+// Message is one chat message.
+type Message struct {
+	Time   time.Time
+	Sender string
+	Text   string
+}
+
+// Conversation is a chat (a group, channel or direct chat) and its messages
+// in the order they were sent.
+type Conversation struct {
+	Platform string // "WhatsApp", "Telegram" or "Slack"
+	Name     string
+	Messages []Message
+}
+
+// Parse reads the export at path in the given format. WhatsApp exports are a
+// .txt file or the .zip holding it, Telegram exports are the result.json of
+// a JSON export, and Slack exports are the unzipped folder or the .zip.
+func Parse(format, path string) ([]Conversation, error) {
+	switch strings.ToLower(format) {
+	case "whatsapp":
+		return parseWhatsAppFile(path)
+	case "telegram":
+		return parseTelegramFile(path)
+	case "slack":
+		return parseSlackExport(path)
+	default:
+		return nil, fmt.Errorf("unknown chat format %q (want one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Participants returns the senders of the conversation, most active first.
+func (c Conversation) Participants() []string {
+	counts := make(map[string]int)
+	var names []string
+	for _, m := range c.Messages {
+		if m.Sender == "" {
+			continue
+		}
+		if counts[m.Sender] == 0 {
+			names = append(names, m.Sender)
+		}
+		counts[m.Sender]++
+	}
+	sort.SliceStable(names, func(i, j int) bool { return counts[names[i]] > counts[names[j]] })
+	return names
+}
+
+// SplitByDay splits the conversation into one conversation per calendar day
+// that has messages.
+func (c Conversation) SplitByDay() []Conversation {
+	var days []Conversation
+	for _, m := range c.Messages {
+		if n := len(days); n == 0 || !sameDay(days[n-1].Messages[0].Time, m.Time) {
+			days = append(days, Conversation{Platform: c.Platform, Name: c.Name})
+		}
+		last := &days[len(days)-1]
+		last.Messages = append(last.Messages, m)
+	}
+	return days
+}
+
+// Title returns the note title: the platform and chat name, plus the date
+// for a single day's messages.
+func (c Conversation) Title(perDay bool) string {
+	title := c.Platform + ": " + c.Name
+	if perDay && len(c.Messages) > 0 {
+		title += " " + c.Messages[0].Time.Format("2006-01-02")
+	}
+	return title
+}
+
+// Markdown renders the conversation as a markdown note whose frontmatter
+// holds the platform, participants and dates, with one heading per day.
+func (c Conversation) Markdown(perDay bool) string {
+	var sb strings.Builder
+	title := c.Title(perDay)
+
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "title: %q\n", title)
+	fmt.Fprintf(&sb, "platform: %s\n", strings.ToLower(c.Platform))
+	fmt.Fprintf(&sb, "chat: %q\n", c.Name)
+	if participants := c.Participants(); len(participants) > 0 {
+		quoted := make([]string, len(participants))
+		for i, p := range participants {
+			quoted[i] = fmt.Sprintf("%q", p)
+		}
+		fmt.Fprintf(&sb, "participants: [%s]\n", strings.Join(quoted, ", "))
+	}
+	if len(c.Messages) > 0 {
+		fmt.Fprintf(&sb, "date: %s\n", c.Messages[0].Time.Format(time.RFC3339))
+		fmt.Fprintf(&sb, "last_message: %s\n", c.Messages[len(c.Messages)-1].Time.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&sb, "messages: %d\n", len(c.Messages))
+	fmt.Fprintf(&sb, "tags: [chat, %s]\n", strings.ToLower(c.Platform))
+	sb.WriteString("---\n\n")
+	fmt.Fprintf(&sb, "# %s\n", title)
+
+	var day time.Time
+	for i, m := range c.Messages {
+		if i == 0 || !sameDay(day, m.Time) {
+			day = m.Time
+			fmt.Fprintf(&sb, "\n## %s\n\n", m.Time.Format("Monday, 2 January 2006"))
+		}
+		sender := m.Sender
+		if sender == "" {
+			sender = "unknown"
+		}
+		// Indent continuation lines so a message can't start a heading.
+		text := strings.ReplaceAll(strings.TrimSpace(m.Text), "\n", "\n  ")
+		fmt.Fprintf(&sb, "- %s **%s**: %s\n", m.Time.Format("15:04"), sender, text)
+	}
+	return sb.String()
+}
+
+// FileName returns a file name for the note derived from the chat name and,
+// for a single day, its date, e.g. "family-2024-03-01.md". Importing the same
+// export again produces the same names.
+func (c Conversation) FileName(perDay bool) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(c.Name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+			dash = false
+		case !dash && sb.Len() > 0:
+			sb.WriteByte('-')
+			dash = true
+		}
+		if sb.Len() >= 60 {
+			break
+		}
+	}
+	name := strings.Trim(sb.String(), "-")
+	if name == "" {
+		name = "chat"
+	}
+	if perDay && len(c.Messages) > 0 {
+		name += "-" + c.Messages[0].Time.Format("2006-01-02")
+	}
+	return name + ".md"
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// readExportFile returns the contents of name from fsys.
+func readExportFile(fsys fs.FS, name string) ([]byte, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return data, nil
+}
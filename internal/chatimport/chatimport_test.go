@@ -0,0 +1,67 @@
+package chatimport
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testConversation() Conversation {
+	day1 := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	return Conversation{
+		Platform: "WhatsApp",
+		Name:     "Family & Friends",
+		Messages: []Message{
+			{Time: day1, Sender: "Bob", Text: "Lunch?"},
+			{Time: day1.Add(time.Minute), Sender: "Alice", Text: "Yes"},
+			{Time: day2, Sender: "Alice", Text: "Thanks for lunch\n# not a heading"},
+		},
+	}
+}
+
+func TestConversationMarkdown(t *testing.T) {
+	conv := testConversation()
+	if got := conv.Participants(); !slices.Equal(got, []string{"Alice", "Bob"}) {
+		t.Errorf("Participants() = %v, want most active first", got)
+	}
+
+	md := conv.Markdown(false)
+	for _, want := range []string{
+		"title: \"WhatsApp: Family & Friends\"\n",
+		"platform: whatsapp\n",
+		"participants: [\"Alice\", \"Bob\"]\n",
+		"date: 2024-03-01T09:00:00Z\n",
+		"last_message: 2024-03-02T09:00:00Z\n",
+		"tags: [chat, whatsapp]\n",
+		"## Friday, 1 March 2024\n\n- 09:00 **Bob**: Lunch?\n- 09:01 **Alice**: Yes\n",
+		"## Saturday, 2 March 2024\n\n- 09:00 **Alice**: Thanks for lunch\n  # not a heading\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q in:\n%s", want, md)
+		}
+	}
+	if got := conv.FileName(false); got != "family-friends.md" {
+		t.Errorf("FileName() = %q", got)
+	}
+}
+
+func TestConversationSplitByDay(t *testing.T) {
+	days := testConversation().SplitByDay()
+	if len(days) != 2 || len(days[0].Messages) != 2 || len(days[1].Messages) != 1 {
+		t.Fatalf("SplitByDay() = %+v, want two days", days)
+	}
+	if got := days[1].Title(true); got != "WhatsApp: Family & Friends 2024-03-02" {
+		t.Errorf("Title(true) = %q", got)
+	}
+	if got := days[1].FileName(true); got != "family-friends-2024-03-02.md" {
+		t.Errorf("FileName(true) = %q", got)
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	if _, err := Parse("irc", "log.txt"); err == nil || !strings.Contains(err.Error(), "whatsapp") {
+		t.Errorf("Parse() error = %v, want the supported formats listed", err)
+	}
+}
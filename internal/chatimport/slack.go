@@ -0,0 +1,183 @@
+package chatimport
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// slackMentionRegex matches user mentions such as <@U024BE7LH> and
+// <@U024BE7LH|bob>.
+var slackMentionRegex = regexp.MustCompile(`<@([A-Z0-9]+)(?:\|[^>]*)?>`)
+
+// slackLinkRegex matches links and channel references such as
+// <https://example.com|Example> and <#C024BE7LR|general>.
+var slackLinkRegex = regexp.MustCompile(`<([^@>|][^>|]*)(?:\|([^>]*))?>`)
+
+// slackUser is an entry of users.json.
+type slackUser struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Profile  struct {
+		RealName    string `json:"real_name"`
+		DisplayName string `json:"display_name"`
+	} `json:"profile"`
+}
+
+// slackMessage is a message in a channel's daily file.
+type slackMessage struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype"`
+	User        string `json:"user"`
+	Username    string `json:"username"`
+	Text        string `json:"text"`
+	TS          string `json:"ts"`
+	UserProfile struct {
+		RealName string `json:"real_name"`
+	} `json:"user_profile"`
+}
+
+// slackSkippedSubtypes are membership and housekeeping messages.
+var slackSkippedSubtypes = map[string]bool{
+	"channel_join":    true,
+	"channel_leave":   true,
+	"channel_purpose": true,
+	"channel_topic":   true,
+	"channel_name":    true,
+	"group_join":      true,
+	"group_leave":     true,
+}
+
+// parseSlackExport reads a Slack workspace export, either the .zip Slack
+// provides or the folder it unpacks to.
+func parseSlackExport(p string) ([]Conversation, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	if info.IsDir() {
+		return parseSlack(os.DirFS(p), time.Local)
+	}
+	zr, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, fmt.Errorf("opening export zip: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+	return parseSlack(zr, time.Local)
+}
+
+// parseSlack parses a Slack export: one folder per channel or direct
+// message, holding one JSON file of messages per day, with users.json to
+// resolve user IDs to names.
+func parseSlack(fsys fs.FS, loc *time.Location) ([]Conversation, error) {
+	names := make(map[string]string)
+	if data, err := fs.ReadFile(fsys, "users.json"); err == nil {
+		var users []slackUser
+		if err := json.Unmarshal(data, &users); err != nil {
+			return nil, fmt.Errorf("parsing users.json: %w", err)
+		}
+		for _, u := range users {
+			names[u.ID] = firstNonEmpty(u.Profile.DisplayName, u.Profile.RealName, u.RealName, u.Name, u.ID)
+		}
+	}
+
+	files, err := fs.Glob(fsys, "*/*.json")
+	if err != nil {
+		return nil, err
+	}
+	// Daily files are named by date, so sorting puts them in order.
+	sort.Strings(files)
+
+	var convs []Conversation
+	for _, file := range files {
+		channel := path.Dir(file)
+		if n := len(convs); n == 0 || convs[n-1].Name != channel {
+			convs = append(convs, Conversation{Platform: "Slack", Name: channel})
+		}
+		data, err := readExportFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var msgs []slackMessage
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		conv := &convs[len(convs)-1]
+		for _, m := range msgs {
+			text := strings.TrimSpace(slackText(m.Text, names))
+			if m.Type != "message" || slackSkippedSubtypes[m.Subtype] || text == "" {
+				continue
+			}
+			conv.Messages = append(conv.Messages, Message{
+				Time:   slackTime(m.TS, loc),
+				Sender: firstNonEmpty(names[m.User], m.UserProfile.RealName, m.Username, m.User),
+				Text:   text,
+			})
+		}
+	}
+
+	kept := convs[:0]
+	for _, c := range convs {
+		if len(c.Messages) > 0 {
+			sort.SliceStable(c.Messages, func(i, j int) bool { return c.Messages[i].Time.Before(c.Messages[j].Time) })
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("no Slack messages found")
+	}
+	return kept, nil
+}
+
+// slackText replaces Slack's markup for mentions, links and entities with
+// plain text.
+func slackText(text string, names map[string]string) string {
+	text = slackMentionRegex.ReplaceAllStringFunc(text, func(m string) string {
+		id := slackMentionRegex.FindStringSubmatch(m)[1]
+		return "@" + firstNonEmpty(names[id], id)
+	})
+	text = slackLinkRegex.ReplaceAllStringFunc(text, func(m string) string {
+		parts := slackLinkRegex.FindStringSubmatch(m)
+		switch {
+		case strings.HasPrefix(parts[1], "#") && parts[2] != "":
+			return "#" + parts[2]
+		case strings.HasPrefix(parts[1], "!"):
+			return "@" + strings.TrimPrefix(parts[1], "!")
+		case parts[2] != "":
+			return "[" + parts[2] + "](" + parts[1] + ")"
+		default:
+			return parts[1]
+		}
+	})
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(text)
+}
+
+// slackTime converts a message timestamp such as "1700000000.000100".
+func slackTime(ts string, loc *time.Location) time.Time {
+	secs, frac, _ := strings.Cut(ts, ".")
+	s, _ := strconv.ParseInt(secs, 10, 64)
+	var nanos int64
+	if frac != "" {
+		frac = (frac + "000000000")[:9]
+		nanos, _ = strconv.ParseInt(frac, 10, 64)
+	}
+	return time.Unix(s, nanos).In(loc)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
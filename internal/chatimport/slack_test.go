@@ -0,0 +1,53 @@
+package chatimport
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestParseSlack(t *testing.T) {
+	fsys := fstest.MapFS{
+		"users.json": {Data: []byte(`[
+			{"id": "U1", "name": "ann", "profile": {"real_name": "Ann Lee", "display_name": "ann"}},
+			{"id": "U2", "name": "ben", "real_name": "Ben Ode", "profile": {}}
+		]`)},
+		"channels.json": {Data: []byte(`[{"id": "C1", "name": "eng"}]`)},
+		"eng/2024-05-02.json": {Data: []byte(`[
+			{"type": "message", "user": "U2", "text": "Deployed &amp; verified", "ts": "1714644000.000200"}
+		]`)},
+		"eng/2024-05-01.json": {Data: []byte(`[
+			{"type": "message", "subtype": "channel_join", "user": "U2", "text": "<@U2> has joined the channel", "ts": "1714550000.000100"},
+			{"type": "message", "user": "U1", "text": "<@U2> see <https://example.com/rfc|the RFC> in <#C1|eng>", "ts": "1714557600.000100"}
+		]`)},
+		"random/2024-05-01.json": {Data: []byte(`[
+			{"type": "message", "username": "deploybot", "text": "build 42 passed", "ts": "1714557700.5"}
+		]`)},
+	}
+
+	convs, err := parseSlack(fsys, time.UTC)
+	if err != nil {
+		t.Fatalf("parseSlack() error = %v", err)
+	}
+	if len(convs) != 2 || convs[0].Name != "eng" || convs[1].Name != "random" {
+		t.Fatalf("conversations = %+v, want eng and random", convs)
+	}
+
+	eng := convs[0].Messages
+	if len(eng) != 2 {
+		t.Fatalf("got %d eng messages, want 2 (join skipped)", len(eng))
+	}
+	if eng[0].Sender != "ann" || eng[0].Text != "@Ben Ode see [the RFC](https://example.com/rfc) in #eng" {
+		t.Errorf("first message = %+v", eng[0])
+	}
+	if eng[1].Text != "Deployed & verified" || !eng[1].Time.Equal(time.Unix(1714644000, 200000).UTC()) {
+		t.Errorf("second message = %+v", eng[1])
+	}
+	if bot := convs[1].Messages[0]; bot.Sender != "deploybot" || bot.Time.Nanosecond() != 500000000 {
+		t.Errorf("bot message = %+v", bot)
+	}
+
+	if _, err := parseSlack(fstest.MapFS{"users.json": {Data: []byte(`[]`)}}, time.UTC); err == nil {
+		t.Error("expected an error for an export without messages")
+	}
+}
@@ -0,0 +1,111 @@
+package chatimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// telegramChat is one chat of a Telegram Desktop JSON export.
+type telegramChat struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Messages []struct {
+		Type string       `json:"type"`
+		Date string       `json:"date"`
+		From string       `json:"from"`
+		Text telegramText `json:"text"`
+	} `json:"messages"`
+}
+
+// telegramExport is a Telegram Desktop JSON export: either a single chat or
+// the whole account with a list of chats.
+type telegramExport struct {
+	telegramChat
+	Chats struct {
+		List []telegramChat `json:"list"`
+	} `json:"chats"`
+	LeftChats struct {
+		List []telegramChat `json:"list"`
+	} `json:"left_chats"`
+}
+
+// telegramText is a message text, exported either as a string or as a list
+// of plain strings and formatted entities such as links and mentions.
+type telegramText string
+
+func (t *telegramText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = telegramText(s)
+		return nil
+	}
+	var parts []json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for _, p := range parts {
+		var entity struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(p, &s); err == nil {
+			sb.WriteString(s)
+		} else if err := json.Unmarshal(p, &entity); err == nil {
+			sb.WriteString(entity.Text)
+		}
+	}
+	*t = telegramText(sb.String())
+	return nil
+}
+
+// parseTelegramFile reads the result.json of a Telegram Desktop export.
+func parseTelegramFile(path string) ([]Conversation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	return parseTelegram(data, time.Local)
+}
+
+// parseTelegram parses a Telegram JSON export into one conversation per chat
+// with messages. Service messages (joins, pins, calls) are skipped.
+func parseTelegram(data []byte, loc *time.Location) ([]Conversation, error) {
+	var export telegramExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("parsing Telegram export: %w", err)
+	}
+	chats := append(export.Chats.List, export.LeftChats.List...)
+	if len(chats) == 0 {
+		chats = []telegramChat{export.telegramChat}
+	}
+
+	var convs []Conversation
+	for _, chat := range chats {
+		name := chat.Name
+		if name == "" {
+			name = strings.ReplaceAll(chat.Type, "_", " ")
+		}
+		conv := Conversation{Platform: "Telegram", Name: name}
+		for _, m := range chat.Messages {
+			text := strings.TrimSpace(string(m.Text))
+			if m.Type != "message" || text == "" {
+				continue
+			}
+			t, err := time.ParseInLocation("2006-01-02T15:04:05", m.Date, loc)
+			if err != nil {
+				return nil, fmt.Errorf("parsing message date %q: %w", m.Date, err)
+			}
+			conv.Messages = append(conv.Messages, Message{Time: t, Sender: m.From, Text: text})
+		}
+		if len(conv.Messages) > 0 {
+			convs = append(convs, conv)
+		}
+	}
+	if len(convs) == 0 {
+		return nil, fmt.Errorf("no Telegram messages found")
+	}
+	return convs, nil
+}
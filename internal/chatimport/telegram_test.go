@@ -0,0 +1,49 @@
+package chatimport
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTelegram(t *testing.T) {
+	single := `{
+		"name": "Trip planning",
+		"type": "private_group",
+		"messages": [
+			{"id": 1, "type": "service", "date": "2024-05-01T10:00:00", "actor": "Ann", "action": "create_group", "text": ""},
+			{"id": 2, "type": "message", "date": "2024-05-01T10:01:30", "from": "Ann", "text": "Lisbon in June?"},
+			{"id": 3, "type": "message", "date": "2024-05-01T10:03:00", "from": "Ben",
+			 "text": ["Flights are cheap: ", {"type": "link", "text": "https://example.com/fares"}]},
+			{"id": 4, "type": "message", "date": "2024-05-01T10:04:00", "from": "Ben", "text": ""}
+		]
+	}`
+	convs, err := parseTelegram([]byte(single), time.UTC)
+	if err != nil {
+		t.Fatalf("parseTelegram() error = %v", err)
+	}
+	if len(convs) != 1 || convs[0].Name != "Trip planning" || convs[0].Platform != "Telegram" {
+		t.Fatalf("conversations = %+v", convs)
+	}
+	msgs := convs[0].Messages
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2 (service and empty messages skipped)", len(msgs))
+	}
+	if msgs[1].Sender != "Ben" || msgs[1].Text != "Flights are cheap: https://example.com/fares" {
+		t.Errorf("second message = %+v, want formatted text flattened", msgs[1])
+	}
+	if want := time.Date(2024, 5, 1, 10, 1, 30, 0, time.UTC); !msgs[0].Time.Equal(want) {
+		t.Errorf("first message time = %v, want %v", msgs[0].Time, want)
+	}
+
+	account := `{"chats": {"list": [
+		{"name": "Ann", "type": "personal_chat", "messages": [{"type": "message", "date": "2024-05-02T08:00:00", "from": "Ann", "text": "hi"}]},
+		{"name": "Empty", "type": "personal_chat", "messages": []}
+	]}}`
+	if convs, err = parseTelegram([]byte(account), time.UTC); err != nil || len(convs) != 1 || convs[0].Name != "Ann" {
+		t.Errorf("account export = %+v, %v; want only the chat with messages", convs, err)
+	}
+
+	if _, err := parseTelegram([]byte(`{"name": "Quiet", "messages": []}`), time.UTC); err == nil {
+		t.Error("expected an error for an export without messages")
+	}
+}
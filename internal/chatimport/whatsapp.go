@@ -0,0 +1,162 @@
+package chatimport
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// whatsAppLineRegex matches the start of a WhatsApp message, as exported on
+// Android ("31/12/2023, 21:15 - Alice: Hi") or iOS ("[31/12/2023, 21:15:03]
+// Alice: Hi"). Lines that don't match continue the previous message.
+var whatsAppLineRegex = regexp.MustCompile(`^\[?(\d{1,4})[./-](\d{1,2})[./-](\d{1,4}),? (\d{1,2}):(\d{2})(?::(\d{2}))?\s?([AaPp]\.?[Mm]\.?)?\]?(?: -|:)? (.*)$`)
+
+// whatsAppMessage is a message line split into its parts, before the date
+// order is known.
+type whatsAppMessage struct {
+	date   [3]int
+	hour   int
+	minute int
+	second int
+	pm     string // "am", "pm" or "" for 24-hour times
+	sender string
+	text   string
+}
+
+// parseWhatsAppFile reads a WhatsApp "Export chat" file: the .txt, or the
+// .zip that also holds attachments. The chat is named after the file.
+func parseWhatsAppFile(path string) ([]Conversation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		if data, err = whatsAppZipText(data); err != nil {
+			return nil, err
+		}
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name = strings.TrimPrefix(name, "WhatsApp Chat with ")
+	name = strings.TrimPrefix(name, "WhatsApp Chat - ")
+	conv, err := parseWhatsApp(bytes.NewReader(data), name, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return []Conversation{conv}, nil
+}
+
+// whatsAppZipText returns the chat text from a WhatsApp export zip.
+func whatsAppZipText(data []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("opening export zip: %w", err)
+	}
+	for _, f := range zr.File {
+		if strings.EqualFold(filepath.Ext(f.Name), ".txt") {
+			return readExportFile(zr, f.Name)
+		}
+	}
+	return nil, fmt.Errorf("no chat .txt file in export zip")
+}
+
+// parseWhatsApp parses an exported WhatsApp chat. System lines such as
+// "Messages are end-to-end encrypted" are skipped. Whether dates are written
+// day or month first depends on the phone's locale, so it is inferred from
+// the dates in the file.
+func parseWhatsApp(r io.Reader, name string, loc *time.Location) (Conversation, error) {
+	var raw []whatsAppMessage
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		// Exports mark lines with invisible direction marks and use narrow
+		// no-break spaces before AM/PM.
+		line = strings.NewReplacer("\u200e", "", "\u200f", "", "\ufeff", "", "\u202f", " ", "\u00a0", " ").Replace(line)
+		m := whatsAppLineRegex.FindStringSubmatch(line)
+		if m == nil {
+			if n := len(raw); n > 0 {
+				raw[n-1].text += "\n" + line
+			}
+			continue
+		}
+		msg := whatsAppMessage{pm: strings.ToLower(strings.ReplaceAll(m[7], ".", ""))}
+		for i := range 3 {
+			msg.date[i], _ = strconv.Atoi(m[i+1])
+		}
+		msg.hour, _ = strconv.Atoi(m[4])
+		msg.minute, _ = strconv.Atoi(m[5])
+		msg.second, _ = strconv.Atoi(m[6])
+		// Lines without "Sender: " are system messages.
+		if sender, text, ok := strings.Cut(m[8], ": "); ok {
+			msg.sender, msg.text = sender, text
+		}
+		raw = append(raw, msg)
+	}
+	if err := sc.Err(); err != nil {
+		return Conversation{}, fmt.Errorf("reading chat: %w", err)
+	}
+	if len(raw) == 0 {
+		return Conversation{}, fmt.Errorf("no WhatsApp messages found")
+	}
+
+	dayFirst := whatsAppDayFirst(raw)
+	conv := Conversation{Platform: "WhatsApp", Name: name}
+	for _, m := range raw {
+		if m.sender == "" || strings.TrimSpace(m.text) == "" {
+			continue
+		}
+		year, month, day := m.date[2], m.date[0], m.date[1]
+		switch {
+		case m.date[0] > 31:
+			year, month, day = m.date[0], m.date[1], m.date[2]
+		case dayFirst:
+			month, day = m.date[1], m.date[0]
+		}
+		if year < 100 {
+			year += 2000
+		}
+		hour := m.hour % 12
+		if m.pm == "" {
+			hour = m.hour
+		} else if m.pm == "pm" {
+			hour += 12
+		}
+		conv.Messages = append(conv.Messages, Message{
+			Time:   time.Date(year, time.Month(month), day, hour, m.minute, m.second, 0, loc),
+			Sender: m.sender,
+			Text:   strings.TrimSpace(m.text),
+		})
+	}
+	if len(conv.Messages) == 0 {
+		return Conversation{}, fmt.Errorf("no WhatsApp messages found")
+	}
+	return conv, nil
+}
+
+// whatsAppDayFirst reports whether the dates are written day first. A first
+// number above 12 means day first and a second number above 12 means month
+// first; otherwise 12-hour times suggest a US-style month-first export.
+func whatsAppDayFirst(msgs []whatsAppMessage) bool {
+	twelveHour := false
+	for _, m := range msgs {
+		if m.date[0] > 31 {
+			return false
+		}
+		if m.date[0] > 12 {
+			return true
+		}
+		if m.date[1] > 12 {
+			return false
+		}
+		twelveHour = twelveHour || m.pm != ""
+	}
+	return !twelveHour
+}
@@ -0,0 +1,97 @@
+package chatimport
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWhatsApp(t *testing.T) {
+	tests := []struct {
+		name, export string
+		want         []Message
+	}{
+		{
+			name: "android day first",
+			export: "31/12/2023, 21:15 - Messages and calls are end-to-end encrypted.\n" +
+				"31/12/2023, 21:15 - Alice: Happy new year!\n" +
+				"01/01/2024, 09:02 - Bob: Same to you\nsee you at 5: ok?\n",
+			want: []Message{
+				{Time: time.Date(2023, 12, 31, 21, 15, 0, 0, time.UTC), Sender: "Alice", Text: "Happy new year!"},
+				{Time: time.Date(2024, 1, 1, 9, 2, 0, 0, time.UTC), Sender: "Bob", Text: "Same to you\nsee you at 5: ok?"},
+			},
+		},
+		{
+			name: "us month first with 12-hour times and narrow spaces",
+			export: "3/4/24, 9:15\u202fPM - Alice: Dinner?\n" +
+				"3/4/24, 12:05 AM - Bob: Too late\n",
+			want: []Message{
+				{Time: time.Date(2024, 3, 4, 21, 15, 0, 0, time.UTC), Sender: "Alice", Text: "Dinner?"},
+				{Time: time.Date(2024, 3, 4, 0, 5, 0, 0, time.UTC), Sender: "Bob", Text: "Too late"},
+			},
+		},
+		{
+			name:   "ios with seconds and a direction mark",
+			export: "\u200e[15/03/2024, 08:30:12] Carol: Morning all\n",
+			want: []Message{
+				{Time: time.Date(2024, 3, 15, 8, 30, 12, 0, time.UTC), Sender: "Carol", Text: "Morning all"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := parseWhatsApp(strings.NewReader(tt.export), "Family", time.UTC)
+			if err != nil {
+				t.Fatalf("parseWhatsApp() error = %v", err)
+			}
+			if conv.Platform != "WhatsApp" || conv.Name != "Family" {
+				t.Errorf("conversation = %q %q", conv.Platform, conv.Name)
+			}
+			if len(conv.Messages) != len(tt.want) {
+				t.Fatalf("got %d messages, want %d: %+v", len(conv.Messages), len(tt.want), conv.Messages)
+			}
+			for i, m := range conv.Messages {
+				if !m.Time.Equal(tt.want[i].Time) || m.Sender != tt.want[i].Sender || m.Text != tt.want[i].Text {
+					t.Errorf("message %d = %+v, want %+v", i, m, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := parseWhatsApp(strings.NewReader("just some text\n"), "x", time.UTC); err == nil {
+		t.Error("expected an error for a file without messages")
+	}
+}
+
+func TestParseWhatsAppZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "WhatsApp Chat - Book Club.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("_chat.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("[15/03/2024, 08:30:12] Carol: Chapter 3 tonight\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	convs, err := Parse("whatsapp", path)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(convs) != 1 || convs[0].Name != "Book Club" || len(convs[0].Messages) != 1 {
+		t.Errorf("conversations = %+v, want Book Club with one message", convs)
+	}
+}
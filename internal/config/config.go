@@ -3,8 +3,10 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 
@@ -13,21 +15,32 @@ import (
 
 // Config holds all configuration for MindCLI.
 type Config struct {
-	Sources    SourcesConfig    `yaml:"sources"`
-	Embeddings EmbeddingsConfig `yaml:"embeddings"`
-	Search     SearchConfig     `yaml:"search"`
-	Indexing   IndexingConfig   `yaml:"indexing"`
-	Storage    StorageConfig    `yaml:"storage"`
-	Privacy    PrivacyConfig    `yaml:"privacy"`
+	Sources       SourcesConfig       `yaml:"sources"`
+	Embeddings    EmbeddingsConfig    `yaml:"embeddings"`
+	Search        SearchConfig        `yaml:"search"`
+	Query         QueryConfig         `yaml:"query"`
+	Ask           AskConfig           `yaml:"ask"`
+	Display       DisplayConfig       `yaml:"display"`
+	Indexing      IndexingConfig      `yaml:"indexing"`
+	Storage       StorageConfig       `yaml:"storage"`
+	Privacy       PrivacyConfig       `yaml:"privacy"`
+	Sync          SyncConfig          `yaml:"sync"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Hooks         HooksConfig         `yaml:"hooks"`
+	Plugins       PluginsConfig       `yaml:"plugins"`
 }
 
 // SourcesConfig configures which data sources to index.
 type SourcesConfig struct {
-	Markdown  MarkdownSourceConfig  `yaml:"markdown"`
-	PDF       PDFSourceConfig       `yaml:"pdf"`
-	Email     EmailSourceConfig     `yaml:"email"`
-	Browser   BrowserSourceConfig   `yaml:"browser"`
-	Clipboard ClipboardSourceConfig `yaml:"clipboard"`
+	Markdown    MarkdownSourceConfig    `yaml:"markdown"`
+	PDF         PDFSourceConfig         `yaml:"pdf"`
+	Email       EmailSourceConfig       `yaml:"email"`
+	Browser     BrowserSourceConfig     `yaml:"browser"`
+	Clipboard   ClipboardSourceConfig   `yaml:"clipboard"`
+	Data        DataSourceConfig        `yaml:"data"`
+	References  ReferencesSourceConfig  `yaml:"references"`
+	Screenshots ScreenshotsSourceConfig `yaml:"screenshots"`
+	Projects    ProjectsSourceConfig    `yaml:"projects"`
 }
 
 // MarkdownSourceConfig configures markdown/notes indexing.
@@ -36,6 +49,24 @@ type MarkdownSourceConfig struct {
 	Paths      []string `yaml:"paths"`
 	Extensions []string `yaml:"extensions"`
 	Ignore     []string `yaml:"ignore"`
+	// Sections also indexes each heading of notes at least SectionMinChars
+	// long as its own searchable entry.
+	Sections        bool `yaml:"sections"`
+	SectionMinChars int  `yaml:"section_min_chars"`
+	// GitMetadata reads the git history of notes kept in a git repository:
+	// last commit date, author and commit count.
+	GitMetadata bool `yaml:"git_metadata"`
+	// Vaults are named note directories indexed alongside Paths, e.g. work
+	// and personal. Their notes are searchable with vault:<name>.
+	Vaults []VaultConfig `yaml:"vaults"`
+}
+
+// VaultConfig is a named set of note directories with its own ignore list,
+// which applies on top of the markdown source's.
+type VaultConfig struct {
+	Name   string   `yaml:"name"`
+	Paths  []string `yaml:"paths"`
+	Ignore []string `yaml:"ignore"`
 }
 
 // PDFSourceConfig configures PDF indexing.
@@ -58,6 +89,14 @@ type BrowserSourceConfig struct {
 	Enabled        bool     `yaml:"enabled"`
 	Browsers       []string `yaml:"browsers"`
 	IncludeContent bool     `yaml:"include_content"`
+	// MaxEntries and MaxSizeMB cap the history entries indexed per browser
+	// and their megabytes of text; past either, the least visited and
+	// oldest are dropped, never bookmarks. 0 means no limit.
+	MaxEntries int `yaml:"max_entries"`
+	MaxSizeMB  int `yaml:"max_size_mb"`
+	// RetentionDays drops history entries last visited longer ago than
+	// this; bookmarks are kept. 0 keeps history indexed forever.
+	RetentionDays int `yaml:"retention_days"`
 }
 
 // ClipboardSourceConfig configures clipboard history.
@@ -65,6 +104,54 @@ type ClipboardSourceConfig struct {
 	Enabled       bool `yaml:"enabled"`
 	RetentionDays int  `yaml:"retention_days"`
 	SkipPasswords bool `yaml:"skip_passwords"`
+	// MaxDocuments and MaxSizeMB cap the clips kept indexed and their
+	// megabytes of text; past either, the oldest are trimmed, except those
+	// tagged by hand, collected or aliased. 0 means no limit.
+	MaxDocuments int `yaml:"max_documents"`
+	MaxSizeMB    int `yaml:"max_size_mb"`
+	// ExcludeApps names applications, such as password managers and banking
+	// apps, whose clips are never captured: none is while one is focused.
+	// It only applies on macOS and on Linux under X11 with xdotool.
+	ExcludeApps []string `yaml:"exclude_apps"`
+}
+
+// DataSourceConfig configures indexing of CSV, TSV and JSON data files,
+// one searchable entry per row or record.
+type DataSourceConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	Paths      []string `yaml:"paths"`
+	Extensions []string `yaml:"extensions"`
+	Ignore     []string `yaml:"ignore"`
+	Columns    []string `yaml:"columns"`     // columns or fields to index; empty indexes all
+	MaxRecords int      `yaml:"max_records"` // rows or records indexed per file
+}
+
+// ReferencesSourceConfig configures indexing of reference libraries: BibTeX
+// files and Zotero databases.
+type ReferencesSourceConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Paths   []string `yaml:"paths"` // .bib files, folders of them, or Zotero data directories
+	Ignore  []string `yaml:"ignore"`
+}
+
+// ScreenshotsSourceConfig configures indexing of screenshots by the text
+// OCR finds in them.
+type ScreenshotsSourceConfig struct {
+	Enabled       bool     `yaml:"enabled"`
+	Paths         []string `yaml:"paths"`
+	Extensions    []string `yaml:"extensions"`
+	Ignore        []string `yaml:"ignore"`
+	OCRCommand    string   `yaml:"ocr_command"`    // {path} is replaced by the image path
+	RetentionDays int      `yaml:"retention_days"` // 0 keeps screenshots indexed forever
+}
+
+// ProjectsSourceConfig configures indexing of project directories, such as
+// code repositories, by their documentation alone.
+type ProjectsSourceConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Paths   []string `yaml:"paths"`   // one directory per project
+	Include []string `yaml:"include"` // globs relative to a project; ** matches any directories
+	Ignore  []string `yaml:"ignore"`
 }
 
 // EmbeddingsConfig configures the embedding provider and LLM.
@@ -74,23 +161,196 @@ type EmbeddingsConfig struct {
 	LLMModel  string `yaml:"llm_model"`
 	OllamaURL string `yaml:"ollama_url"`
 	OpenAIKey string `yaml:"openai_key"`
+	// LanguageModels embeds documents and queries in some languages with
+	// another model, keyed by ISO 639-1 code (e.g. de: jina/jina-embeddings-v2-base-de).
+	// The models must produce vectors of the same dimension as Model. Vector
+	// search only compares a query with documents embedded by its model.
+	LanguageModels map[string]string `yaml:"language_models"`
 }
 
 // SearchConfig configures search behavior.
 type SearchConfig struct {
+	// Backend selects the full-text index: "bleve" (a separate on-disk
+	// index) or "fts5" (a table inside the SQLite database).
+	Backend      string  `yaml:"backend"`
 	HybridWeight float64 `yaml:"hybrid_weight"`
 	ResultsLimit int     `yaml:"results_limit"`
 }
 
+// QueryConfig configures how queries are parsed.
+type QueryConfig struct {
+	// LLMParsing asks the LLM for a query's intent and filters when the
+	// keyword heuristics aren't sure, falling back to them when the LLM is
+	// unavailable or unconfident.
+	LLMParsing bool `yaml:"llm_parsing"`
+}
+
+// AskConfig configures how questions are answered from retrieved documents.
+type AskConfig struct {
+	// MaxContexts is the number of top documents passed to the LLM.
+	MaxContexts int `yaml:"max_contexts"`
+	// MaxContextChars is the total size, in characters, of the document
+	// excerpts passed to the LLM (roughly four characters per token).
+	MaxContextChars int `yaml:"max_context_chars"`
+	// AnswerLength is the target answer length: "short", "medium" or "long".
+	AnswerLength string `yaml:"answer_length"`
+	// CacheAnswers serves repeated questions from a cache of earlier
+	// answers, until a document is added, changed or removed.
+	CacheAnswers bool `yaml:"cache_answers"`
+	// ExcludedSources are searched but never used to answer questions,
+	// unless a question asks for them with a source filter.
+	ExcludedSources []string `yaml:"excluded_sources"`
+	// MinRelevance is the vector relevance, from 0 to 1, the best search
+	// result must reach for a question to be answered. Below it the closest
+	// matches are listed instead, so the LLM isn't left to make up an answer
+	// from unrelated notes. 0 always answers.
+	MinRelevance float64 `yaml:"min_relevance"`
+}
+
+// DisplayConfig configures how much of a document is shown. All lengths are
+// in characters; text is cut at a sentence or word boundary.
+type DisplayConfig struct {
+	// PreviewLength is the length of the preview stored for each document
+	// when it is indexed, shown in results and exports.
+	PreviewLength int `yaml:"preview_length"`
+	// SnippetLength is the length of the snippets printed by the CLI and
+	// sent in digests.
+	SnippetLength int `yaml:"snippet_length"`
+	// PreviewPanelLength is how much of a document the TUI preview panel
+	// shows; 0 shows all of it.
+	PreviewPanelLength int `yaml:"preview_panel_length"`
+}
+
 // IndexingConfig configures the indexing pipeline.
 type IndexingConfig struct {
-	Workers int  `yaml:"workers"`
-	Watch   bool `yaml:"watch"`
+	Workers  int            `yaml:"workers"`
+	Watch    bool           `yaml:"watch"`
+	Throttle ThrottleConfig `yaml:"throttle"`
+	// MaxMemoryMB caps the combined size, in megabytes, of the documents the
+	// workers hold in memory at once. Zero means unlimited.
+	MaxMemoryMB int `yaml:"max_memory_mb"`
+	// EmbedBatchSize is the most chunks sent to the embedding service in one
+	// request. Zero sends all of a document's chunks together.
+	EmbedBatchSize int `yaml:"embed_batch_size"`
+	// LowMemory trades speed for a smaller footprint: one worker and lower
+	// memory and batch limits (also set by --low-memory).
+	LowMemory bool `yaml:"low_memory"`
+	// Entities extracts the people, organizations and projects documents
+	// name, for person:, org: and project: filters.
+	Entities bool `yaml:"entities"`
+	// Confirm sets how large a first index of new directories may be before
+	// mindcli index asks to go on.
+	Confirm ConfirmConfig `yaml:"confirm"`
+	// Order is the order files are indexed in: "recent-first" (newest
+	// modification time first, so the latest notes are searchable soonest),
+	// "path" or "size" (smallest first).
+	Order string `yaml:"order"`
+}
+
+// ConfirmConfig guards against indexing a directory such as Downloads or a
+// code monorepo by mistake. Zero disables a limit.
+type ConfirmConfig struct {
+	// Files is the most files new directories may add without asking.
+	Files int `yaml:"files"`
+	// SizeMB is the most megabytes new directories may add without asking.
+	SizeMB int `yaml:"size_mb"`
+}
+
+// ThrottleConfig slows indexing down so large runs stay in the background
+// instead of saturating the CPU and the embedding service.
+type ThrottleConfig struct {
+	// MaxFilesPerSecond caps how many files are processed per second across
+	// all workers. Zero means unlimited.
+	MaxFilesPerSecond float64 `yaml:"max_files_per_second"`
+	// EmbedPauseMs is a pause, in milliseconds, after each embedding batch.
+	EmbedPauseMs int `yaml:"embed_pause_ms"`
+	// LowPriority lowers the process scheduling priority (like nice) while
+	// indexing.
+	LowPriority bool `yaml:"low_priority"`
 }
 
 // StorageConfig configures where data is stored.
 type StorageConfig struct {
-	Path string `yaml:"path"`
+	Path      string          `yaml:"path"`
+	Snapshots SnapshotsConfig `yaml:"snapshots"`
+	// Roots labels the directories document paths are stored relative to,
+	// e.g. {notes: ~/Dropbox/notes}. When empty, every configured source
+	// path is a root labeled with its base name.
+	Roots map[string]string `yaml:"roots,omitempty"`
+}
+
+// SnapshotsConfig configures automatic database snapshots, taken before
+// migrations, before full re-indexes and periodically before index runs.
+type SnapshotsConfig struct {
+	// Count is the number of automatic snapshots kept. Zero disables them.
+	Count int `yaml:"count"`
+	// IntervalHours is the minimum time between periodic snapshots.
+	IntervalHours int `yaml:"interval_hours"`
+}
+
+// SyncConfig configures syncing tags and collections between machines.
+type SyncConfig struct {
+	// Remote is where devices exchange sync files: a directory (e.g. a
+	// synced folder or mount), "git:<dir>" for a git checkout, or
+	// "rclone:<remote:path>" for any rclone remote such as S3 or WebDAV.
+	Remote string `yaml:"remote"`
+	// Device names this machine in the remote; defaults to the hostname.
+	Device string `yaml:"device"`
+}
+
+// NotificationsConfig configures digests that mindcli watch delivers on a
+// schedule.
+type NotificationsConfig struct {
+	Digests []DigestConfig `yaml:"digests"`
+}
+
+// DigestConfig is a saved query whose results are rendered as markdown and
+// delivered on a schedule.
+type DigestConfig struct {
+	Name  string `yaml:"name"`
+	Query string `yaml:"query"`
+	// Schedule is "hourly", "every <duration>", "daily HH:MM" or
+	// "weekly <day> HH:MM", in local time.
+	Schedule string `yaml:"schedule"`
+	// Limit caps the number of results; 0 uses search.results_limit.
+	Limit int `yaml:"limit"`
+	// Command is run by the shell with the digest on stdin, e.g.
+	// "sendmail me@example.com" or "curl -d @- ntfy.sh/topic".
+	Command string `yaml:"command"`
+	// Webhook is a URL the digest is POSTed to.
+	Webhook string `yaml:"webhook"`
+	// OnThisDay adds the documents dated on this day in earlier years.
+	OnThisDay bool `yaml:"on_this_day"`
+}
+
+// HooksConfig configures scripts and webhooks run when the index changes.
+type HooksConfig struct {
+	OnDocumentIndexed HookConfig `yaml:"on_document_indexed"`
+	OnDocumentRemoved HookConfig `yaml:"on_document_removed"`
+	OnIndexComplete   HookConfig `yaml:"on_index_complete"`
+}
+
+// HookConfig is what to run for one index event; either or both may be set.
+type HookConfig struct {
+	// Command is run by the shell with the event as JSON on stdin.
+	Command string `yaml:"command"`
+	// Webhook is a URL the event is POSTed to as JSON.
+	Webhook string `yaml:"webhook"`
+}
+
+// Enabled reports whether the hook runs anything.
+func (h HookConfig) Enabled() bool {
+	return h.Command != "" || h.Webhook != ""
+}
+
+// PluginsConfig configures where exporter and post-processor plugins are
+// found.
+type PluginsConfig struct {
+	// Dir holds the plugin executables; empty means "plugins" in the
+	// config directory.
+	Dir string `yaml:"dir"`
+	// Disabled lists plugins, by file name, that are not run.
+	Disabled []string `yaml:"disabled"`
 }
 
 // PrivacyConfig configures privacy controls.
@@ -109,10 +369,11 @@ func Default() *Config {
 	return &Config{
 		Sources: SourcesConfig{
 			Markdown: MarkdownSourceConfig{
-				Enabled:    true,
-				Paths:      []string{filepath.Join(homeDir, "notes")},
-				Extensions: []string{".md", ".txt"},
-				Ignore:     []string{"node_modules", ".git", ".obsidian"},
+				Enabled:         true,
+				Paths:           []string{filepath.Join(homeDir, "notes")},
+				Extensions:      []string{".md", ".txt", ".canvas", ".excalidraw", ".ipynb"},
+				Ignore:          []string{"node_modules", ".git", ".obsidian"},
+				SectionMinChars: 4000,
 			},
 			PDF: PDFSourceConfig{
 				Enabled: true,
@@ -129,11 +390,42 @@ func Default() *Config {
 				Enabled:        true,
 				Browsers:       []string{"chrome", "firefox", "safari"},
 				IncludeContent: false,
+				MaxEntries:     5000,
+				MaxSizeMB:      20,
 			},
 			Clipboard: ClipboardSourceConfig{
 				Enabled:       true,
 				RetentionDays: 30,
 				SkipPasswords: true,
+				MaxDocuments:  5000,
+				MaxSizeMB:     50,
+				ExcludeApps:   []string{"1Password", "Bitwarden", "Dashlane", "KeePassXC", "Keychain Access", "LastPass", "Passwords"},
+			},
+			Data: DataSourceConfig{
+				Enabled:    false,
+				Paths:      []string{},
+				Extensions: []string{".csv", ".tsv", ".json", ".jsonl"},
+				Ignore:     []string{},
+				MaxRecords: 5000,
+			},
+			References: ReferencesSourceConfig{
+				Enabled: false,
+				Paths:   []string{filepath.Join(homeDir, "Zotero", "zotero.sqlite")},
+				Ignore:  []string{"storage"},
+			},
+			Screenshots: ScreenshotsSourceConfig{
+				Enabled:       false,
+				Paths:         []string{defaultScreenshotsDir(runtime.GOOS, homeDir)},
+				Extensions:    []string{".png", ".jpg", ".jpeg"},
+				Ignore:        []string{},
+				OCRCommand:    "tesseract {path} stdout",
+				RetentionDays: 90,
+			},
+			Projects: ProjectsSourceConfig{
+				Enabled: false,
+				Paths:   []string{},
+				Include: []string{"README*", "docs/**/*.md", "CHANGELOG*"},
+				Ignore:  []string{".git", "node_modules", "vendor"},
 			},
 		},
 		Embeddings: EmbeddingsConfig{
@@ -143,15 +435,40 @@ func Default() *Config {
 			OllamaURL: "http://localhost:11434",
 		},
 		Search: SearchConfig{
+			Backend:      "bleve",
 			HybridWeight: 0.5,
 			ResultsLimit: 50,
 		},
+		Ask: AskConfig{
+			MaxContexts:     5,
+			MaxContextChars: 5000,
+			AnswerLength:    "short",
+			CacheAnswers:    true,
+			ExcludedSources: []string{"browser", "clipboard"},
+			MinRelevance:    0.7,
+		},
+		Display: DisplayConfig{
+			PreviewLength:      500,
+			SnippetLength:      300,
+			PreviewPanelLength: 2000,
+		},
 		Indexing: IndexingConfig{
-			Workers: 4,
-			Watch:   true,
+			Workers:        4,
+			Watch:          true,
+			MaxMemoryMB:    512,
+			EmbedBatchSize: 64,
+			Confirm: ConfirmConfig{
+				Files:  50000,
+				SizeMB: 5000,
+			},
+			Order: "recent-first",
 		},
 		Storage: StorageConfig{
-			Path: filepath.Join(homeDir, ".local", "share", "mindcli"),
+			Path: defaultDataDir(runtime.GOOS, homeDir, os.Getenv),
+			Snapshots: SnapshotsConfig{
+				Count:         5,
+				IntervalHours: 24,
+			},
 		},
 		Privacy: PrivacyConfig{
 			RedactPatterns: []string{},
@@ -167,15 +484,147 @@ func (c *Config) Validate() error {
 	if c.Search.ResultsLimit < 1 {
 		return errors.New("search.results_limit must be at least 1")
 	}
+	if c.Search.Backend != "bleve" && c.Search.Backend != "fts5" {
+		return errors.New("search.backend must be 'bleve' or 'fts5'")
+	}
+	if c.Ask.MaxContexts < 1 {
+		return errors.New("ask.max_contexts must be at least 1")
+	}
+	if c.Ask.MaxContextChars < 100 {
+		return errors.New("ask.max_context_chars must be at least 100")
+	}
+	switch c.Ask.AnswerLength {
+	case "short", "medium", "long":
+	default:
+		return errors.New("ask.answer_length must be 'short', 'medium' or 'long'")
+	}
+	if c.Ask.MinRelevance < 0 || c.Ask.MinRelevance > 1 {
+		return errors.New("ask.min_relevance must be between 0 and 1")
+	}
+	if c.Display.PreviewLength < 1 {
+		return errors.New("display.preview_length must be at least 1")
+	}
+	if c.Display.SnippetLength < 1 {
+		return errors.New("display.snippet_length must be at least 1")
+	}
+	if c.Display.PreviewPanelLength < 0 {
+		return errors.New("display.preview_panel_length must not be negative")
+	}
+	if c.Sources.Data.MaxRecords < 1 {
+		return errors.New("sources.data.max_records must be at least 1")
+	}
+	if c.Sources.Browser.MaxEntries < 0 {
+		return errors.New("sources.browser.max_entries must not be negative")
+	}
+	if c.Sources.Browser.MaxSizeMB < 0 {
+		return errors.New("sources.browser.max_size_mb must not be negative")
+	}
+	if c.Sources.Browser.RetentionDays < 0 {
+		return errors.New("sources.browser.retention_days must not be negative")
+	}
+	if c.Sources.Clipboard.RetentionDays < 0 {
+		return errors.New("sources.clipboard.retention_days must not be negative")
+	}
+	if c.Sources.Clipboard.MaxDocuments < 0 {
+		return errors.New("sources.clipboard.max_documents must not be negative")
+	}
+	if c.Sources.Clipboard.MaxSizeMB < 0 {
+		return errors.New("sources.clipboard.max_size_mb must not be negative")
+	}
+	if c.Sources.Screenshots.RetentionDays < 0 {
+		return errors.New("sources.screenshots.retention_days must not be negative")
+	}
+	for _, inc := range c.Sources.Projects.Include {
+		if _, err := filepath.Match(inc, ""); err != nil {
+			return fmt.Errorf("sources.projects.include has an invalid glob %q", inc)
+		}
+	}
+	if c.Sources.Screenshots.Enabled && strings.TrimSpace(c.Sources.Screenshots.OCRCommand) == "" {
+		return errors.New("sources.screenshots.ocr_command is required when screenshots are enabled")
+	}
+	if c.Sources.Markdown.SectionMinChars < 0 {
+		return errors.New("sources.markdown.section_min_chars must not be negative")
+	}
+	vaults := make(map[string]bool)
+	for i, v := range c.Sources.Markdown.Vaults {
+		switch {
+		case v.Name == "":
+			return fmt.Errorf("sources.markdown.vaults[%d]: name is required", i)
+		case strings.ContainsAny(v.Name, ": \t\""):
+			return fmt.Errorf("sources.markdown.vaults: invalid name %q, names can't contain spaces or colons", v.Name)
+		case vaults[v.Name]:
+			return fmt.Errorf("sources.markdown.vaults: duplicate name %q", v.Name)
+		case len(v.Paths) == 0:
+			return fmt.Errorf("sources.markdown.vaults %q: paths are required", v.Name)
+		}
+		vaults[v.Name] = true
+	}
 	if c.Indexing.Workers < 1 {
 		return errors.New("indexing.workers must be at least 1")
 	}
+	if c.Indexing.Throttle.MaxFilesPerSecond < 0 {
+		return errors.New("indexing.throttle.max_files_per_second must not be negative")
+	}
+	if c.Indexing.Throttle.EmbedPauseMs < 0 {
+		return errors.New("indexing.throttle.embed_pause_ms must not be negative")
+	}
+	if c.Indexing.MaxMemoryMB < 0 {
+		return errors.New("indexing.max_memory_mb must not be negative")
+	}
+	if c.Indexing.EmbedBatchSize < 0 {
+		return errors.New("indexing.embed_batch_size must not be negative")
+	}
+	switch c.Indexing.Order {
+	case "recent-first", "path", "size":
+	default:
+		return errors.New("indexing.order must be 'recent-first', 'path' or 'size'")
+	}
+	if c.Indexing.Confirm.Files < 0 {
+		return errors.New("indexing.confirm.files must not be negative")
+	}
+	if c.Indexing.Confirm.SizeMB < 0 {
+		return errors.New("indexing.confirm.size_mb must not be negative")
+	}
+	if c.Storage.Snapshots.Count < 0 {
+		return errors.New("storage.snapshots.count must not be negative")
+	}
+	if c.Storage.Snapshots.IntervalHours < 0 {
+		return errors.New("storage.snapshots.interval_hours must not be negative")
+	}
+	for label := range c.Storage.Roots {
+		if label == "" || label == "~" || strings.ContainsAny(label, `/\`) {
+			return fmt.Errorf("storage.roots: invalid label %q", label)
+		}
+	}
+	digests := make(map[string]bool)
+	for i, d := range c.Notifications.Digests {
+		switch {
+		case strings.TrimSpace(d.Name) == "":
+			return fmt.Errorf("notifications.digests[%d]: name is required", i)
+		case digests[d.Name]:
+			return fmt.Errorf("notifications.digests: duplicate name %q", d.Name)
+		case strings.TrimSpace(d.Query) == "":
+			return fmt.Errorf("notifications.digests %q: query is required", d.Name)
+		case strings.TrimSpace(d.Schedule) == "":
+			return fmt.Errorf("notifications.digests %q: schedule is required", d.Name)
+		case d.Limit < 0:
+			return fmt.Errorf("notifications.digests %q: limit must not be negative", d.Name)
+		case d.Command == "" && d.Webhook == "":
+			return fmt.Errorf("notifications.digests %q: command or webhook is required", d.Name)
+		}
+		digests[d.Name] = true
+	}
 	if c.Embeddings.Provider != "ollama" && c.Embeddings.Provider != "openai" {
 		return errors.New("embeddings.provider must be 'ollama' or 'openai'")
 	}
 	if c.Embeddings.Provider == "openai" && c.Embeddings.OpenAIKey == "" {
 		return errors.New("embeddings.openai_key is required when embeddings.provider is 'openai'")
 	}
+	for code, model := range c.Embeddings.LanguageModels {
+		if strings.TrimSpace(code) == "" || strings.TrimSpace(model) == "" {
+			return fmt.Errorf("embeddings.language_models: %q needs a language code and a model", code+": "+model)
+		}
+	}
 	return nil
 }
 
@@ -202,16 +651,88 @@ func Load() (*Config, error) {
 	applyEnvOverrides(cfg)
 	expandConfigPaths(cfg)
 
+	// Data used to live in ~/.local/share/mindcli on every platform. Move it
+	// when the platform default applies and nothing is there yet.
+	if home, err := os.UserHomeDir(); err == nil && cfg.Storage.Path == defaultDataDir(runtime.GOOS, home, os.Getenv) {
+		cfg.Storage.Path = migrateDataDir(legacyDataDir(home), cfg.Storage.Path)
+	}
+
 	return cfg, nil
 }
 
+// defaultDataDir returns the platform's data directory for mindcli:
+// $XDG_DATA_HOME/mindcli when set, otherwise ~/Library/Application Support
+// on macOS, %APPDATA% on Windows and ~/.local/share elsewhere.
+func defaultDataDir(goos, home string, getenv func(string) string) string {
+	if dir := getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "mindcli")
+	}
+	switch goos {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "mindcli")
+	case "windows":
+		if dir := getenv("APPDATA"); dir != "" {
+			return filepath.Join(dir, "mindcli")
+		}
+	}
+	return legacyDataDir(home)
+}
+
+// defaultScreenshotsDir returns where the platform saves screenshots by
+// default: the desktop on macOS, Pictures\Screenshots on Windows and
+// ~/Pictures/Screenshots elsewhere.
+func defaultScreenshotsDir(goos, home string) string {
+	if goos == "darwin" {
+		return filepath.Join(home, "Desktop")
+	}
+	return filepath.Join(home, "Pictures", "Screenshots")
+}
+
+// legacyDataDir is where older versions kept data on every platform.
+func legacyDataDir(home string) string {
+	return filepath.Join(home, ".local", "share", "mindcli")
+}
+
+// migrateDataDir moves the legacy data directory to target unless target
+// already exists. It returns the directory to use, which stays the legacy
+// one if the move fails (e.g. across file systems).
+func migrateDataDir(legacy, target string) string {
+	if legacy == target {
+		return target
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		return target
+	}
+	if info, err := os.Stat(legacy); err != nil || !info.IsDir() {
+		return target
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return legacy
+	}
+	if err := os.Rename(legacy, target); err != nil {
+		return legacy
+	}
+	return target
+}
+
 // expandConfigPaths expands a leading ~ in all configured paths so that
 // hand-edited configs (and env overrides) using ~ behave like absolute paths.
 func expandConfigPaths(cfg *Config) {
 	cfg.Storage.Path = expandUserPath(cfg.Storage.Path)
+	for label, dir := range cfg.Storage.Roots {
+		cfg.Storage.Roots[label] = expandUserPath(dir)
+	}
 	cfg.Sources.Markdown.Paths = expandUserPaths(cfg.Sources.Markdown.Paths)
+	for i := range cfg.Sources.Markdown.Vaults {
+		cfg.Sources.Markdown.Vaults[i].Paths = expandUserPaths(cfg.Sources.Markdown.Vaults[i].Paths)
+	}
 	cfg.Sources.PDF.Paths = expandUserPaths(cfg.Sources.PDF.Paths)
 	cfg.Sources.Email.Paths = expandUserPaths(cfg.Sources.Email.Paths)
+	cfg.Sources.Data.Paths = expandUserPaths(cfg.Sources.Data.Paths)
+	cfg.Sources.References.Paths = expandUserPaths(cfg.Sources.References.Paths)
+	cfg.Sources.Screenshots.Paths = expandUserPaths(cfg.Sources.Screenshots.Paths)
+	cfg.Sources.Projects.Paths = expandUserPaths(cfg.Sources.Projects.Paths)
+	cfg.Plugins.Dir = expandUserPath(cfg.Plugins.Dir)
 }
 
 func expandUserPaths(paths []string) []string {
@@ -288,6 +809,18 @@ func (c *Config) DataDir() (string, error) {
 	return c.Storage.Path, nil
 }
 
+// PluginsDir returns the directory plugins are discovered in.
+func (c *Config) PluginsDir() (string, error) {
+	if c.Plugins.Dir != "" {
+		return c.Plugins.Dir, nil
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "plugins"), nil
+}
+
 // DatabasePath returns the path to the SQLite database.
 func (c *Config) DatabasePath() (string, error) {
 	dataDir, err := c.DataDir()
@@ -297,18 +830,88 @@ func (c *Config) DatabasePath() (string, error) {
 	return filepath.Join(dataDir, "mindcli.db"), nil
 }
 
+// PathRoots returns the labeled directories document paths are stored
+// relative to: storage.roots if set, otherwise the configured source paths
+// labeled by base name, with a numeric suffix when names repeat.
+func (c *Config) PathRoots() map[string]string {
+	if len(c.Storage.Roots) > 0 {
+		return c.Storage.Roots
+	}
+	roots := make(map[string]string)
+	seen := make(map[string]bool)
+	var paths []string
+	paths = append(paths, c.Sources.Markdown.Paths...)
+	for _, v := range c.Sources.Markdown.Vaults {
+		paths = append(paths, v.Paths...)
+	}
+	paths = append(paths, c.Sources.PDF.Paths...)
+	paths = append(paths, c.Sources.Email.Paths...)
+	paths = append(paths, c.Sources.Data.Paths...)
+	paths = append(paths, c.Sources.References.Paths...)
+	paths = append(paths, c.Sources.Screenshots.Paths...)
+	paths = append(paths, c.Sources.Projects.Paths...)
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		if !filepath.IsAbs(p) || filepath.Dir(p) == p || seen[p] {
+			continue
+		}
+		seen[p] = true
+		base := filepath.Base(p)
+		label := base
+		for n := 2; roots[label] != ""; n++ {
+			label = fmt.Sprintf("%s-%d", base, n)
+		}
+		roots[label] = p
+	}
+	return roots
+}
+
+// SnapshotDir returns the directory database snapshots are written to.
+func (c *Config) SnapshotDir() string {
+	return filepath.Join(c.Storage.Path, "snapshots")
+}
+
 func applyEnvOverrides(cfg *Config) {
 	// Storage
 	setStringFromEnv("MINDCLI_STORAGE_PATH", &cfg.Storage.Path)
+	setIntFromEnv("MINDCLI_STORAGE_SNAPSHOTS_COUNT", &cfg.Storage.Snapshots.Count)
+	setIntFromEnv("MINDCLI_STORAGE_SNAPSHOTS_INTERVAL_HOURS", &cfg.Storage.Snapshots.IntervalHours)
+
+	// Sync
+	setStringFromEnv("MINDCLI_SYNC_REMOTE", &cfg.Sync.Remote)
+	setStringFromEnv("MINDCLI_SYNC_DEVICE", &cfg.Sync.Device)
 
 	// Indexing
 	setIntFromEnv("MINDCLI_INDEXING_WORKERS", &cfg.Indexing.Workers)
 	setBoolFromEnv("MINDCLI_INDEXING_WATCH", &cfg.Indexing.Watch)
+	setFloat64FromEnv("MINDCLI_INDEXING_THROTTLE_MAX_FILES_PER_SECOND", &cfg.Indexing.Throttle.MaxFilesPerSecond)
+	setIntFromEnv("MINDCLI_INDEXING_THROTTLE_EMBED_PAUSE_MS", &cfg.Indexing.Throttle.EmbedPauseMs)
+	setBoolFromEnv("MINDCLI_INDEXING_THROTTLE_LOW_PRIORITY", &cfg.Indexing.Throttle.LowPriority)
+	setIntFromEnv("MINDCLI_INDEXING_MAX_MEMORY_MB", &cfg.Indexing.MaxMemoryMB)
+	setIntFromEnv("MINDCLI_INDEXING_EMBED_BATCH_SIZE", &cfg.Indexing.EmbedBatchSize)
+	setBoolFromEnv("MINDCLI_INDEXING_LOW_MEMORY", &cfg.Indexing.LowMemory)
+	setBoolFromEnv("MINDCLI_INDEXING_ENTITIES", &cfg.Indexing.Entities)
+	setStringFromEnv("MINDCLI_INDEXING_ORDER", &cfg.Indexing.Order)
+	setIntFromEnv("MINDCLI_INDEXING_CONFIRM_FILES", &cfg.Indexing.Confirm.Files)
+	setIntFromEnv("MINDCLI_INDEXING_CONFIRM_SIZE_MB", &cfg.Indexing.Confirm.SizeMB)
 
 	// Search
+	setStringFromEnv("MINDCLI_SEARCH_BACKEND", &cfg.Search.Backend)
 	setFloat64FromEnv("MINDCLI_SEARCH_HYBRID_WEIGHT", &cfg.Search.HybridWeight)
 	setIntFromEnv("MINDCLI_SEARCH_RESULTS_LIMIT", &cfg.Search.ResultsLimit)
 
+	// Ask
+	setIntFromEnv("MINDCLI_ASK_MAX_CONTEXTS", &cfg.Ask.MaxContexts)
+	setIntFromEnv("MINDCLI_ASK_MAX_CONTEXT_CHARS", &cfg.Ask.MaxContextChars)
+	setStringFromEnv("MINDCLI_ASK_ANSWER_LENGTH", &cfg.Ask.AnswerLength)
+	setBoolFromEnv("MINDCLI_ASK_CACHE_ANSWERS", &cfg.Ask.CacheAnswers)
+	setCSVFromEnv("MINDCLI_ASK_EXCLUDED_SOURCES", &cfg.Ask.ExcludedSources)
+	setFloat64FromEnv("MINDCLI_ASK_MIN_RELEVANCE", &cfg.Ask.MinRelevance)
+	setBoolFromEnv("MINDCLI_QUERY_LLM_PARSING", &cfg.Query.LLMParsing)
+	setIntFromEnv("MINDCLI_DISPLAY_PREVIEW_LENGTH", &cfg.Display.PreviewLength)
+	setIntFromEnv("MINDCLI_DISPLAY_SNIPPET_LENGTH", &cfg.Display.SnippetLength)
+	setIntFromEnv("MINDCLI_DISPLAY_PREVIEW_PANEL_LENGTH", &cfg.Display.PreviewPanelLength)
+
 	// Embeddings
 	setStringFromEnv("MINDCLI_EMBEDDINGS_PROVIDER", &cfg.Embeddings.Provider)
 	setStringFromEnv("MINDCLI_EMBEDDINGS_MODEL", &cfg.Embeddings.Model)
@@ -321,6 +924,9 @@ func applyEnvOverrides(cfg *Config) {
 	setCSVFromEnv("MINDCLI_SOURCES_MARKDOWN_PATHS", &cfg.Sources.Markdown.Paths)
 	setCSVFromEnv("MINDCLI_SOURCES_MARKDOWN_EXTENSIONS", &cfg.Sources.Markdown.Extensions)
 	setCSVFromEnv("MINDCLI_SOURCES_MARKDOWN_IGNORE", &cfg.Sources.Markdown.Ignore)
+	setBoolFromEnv("MINDCLI_SOURCES_MARKDOWN_SECTIONS", &cfg.Sources.Markdown.Sections)
+	setIntFromEnv("MINDCLI_SOURCES_MARKDOWN_SECTION_MIN_CHARS", &cfg.Sources.Markdown.SectionMinChars)
+	setBoolFromEnv("MINDCLI_SOURCES_MARKDOWN_GIT_METADATA", &cfg.Sources.Markdown.GitMetadata)
 
 	// Sources: pdf
 	setBoolFromEnv("MINDCLI_SOURCES_PDF_ENABLED", &cfg.Sources.PDF.Enabled)
@@ -337,11 +943,38 @@ func applyEnvOverrides(cfg *Config) {
 	setBoolFromEnv("MINDCLI_SOURCES_BROWSER_ENABLED", &cfg.Sources.Browser.Enabled)
 	setCSVFromEnv("MINDCLI_SOURCES_BROWSER_BROWSERS", &cfg.Sources.Browser.Browsers)
 	setBoolFromEnv("MINDCLI_SOURCES_BROWSER_INCLUDE_CONTENT", &cfg.Sources.Browser.IncludeContent)
+	setIntFromEnv("MINDCLI_SOURCES_BROWSER_MAX_ENTRIES", &cfg.Sources.Browser.MaxEntries)
+	setIntFromEnv("MINDCLI_SOURCES_BROWSER_MAX_SIZE_MB", &cfg.Sources.Browser.MaxSizeMB)
+	setIntFromEnv("MINDCLI_SOURCES_BROWSER_RETENTION_DAYS", &cfg.Sources.Browser.RetentionDays)
 
 	// Sources: clipboard
 	setBoolFromEnv("MINDCLI_SOURCES_CLIPBOARD_ENABLED", &cfg.Sources.Clipboard.Enabled)
 	setIntFromEnv("MINDCLI_SOURCES_CLIPBOARD_RETENTION_DAYS", &cfg.Sources.Clipboard.RetentionDays)
 	setBoolFromEnv("MINDCLI_SOURCES_CLIPBOARD_SKIP_PASSWORDS", &cfg.Sources.Clipboard.SkipPasswords)
+	setIntFromEnv("MINDCLI_SOURCES_CLIPBOARD_MAX_DOCUMENTS", &cfg.Sources.Clipboard.MaxDocuments)
+	setIntFromEnv("MINDCLI_SOURCES_CLIPBOARD_MAX_SIZE_MB", &cfg.Sources.Clipboard.MaxSizeMB)
+	setCSVFromEnv("MINDCLI_SOURCES_CLIPBOARD_EXCLUDE_APPS", &cfg.Sources.Clipboard.ExcludeApps)
+
+	// Sources: data
+	setBoolFromEnv("MINDCLI_SOURCES_DATA_ENABLED", &cfg.Sources.Data.Enabled)
+	setCSVFromEnv("MINDCLI_SOURCES_DATA_PATHS", &cfg.Sources.Data.Paths)
+	setCSVFromEnv("MINDCLI_SOURCES_DATA_COLUMNS", &cfg.Sources.Data.Columns)
+	setIntFromEnv("MINDCLI_SOURCES_DATA_MAX_RECORDS", &cfg.Sources.Data.MaxRecords)
+
+	// Sources: references
+	setBoolFromEnv("MINDCLI_SOURCES_REFERENCES_ENABLED", &cfg.Sources.References.Enabled)
+	setCSVFromEnv("MINDCLI_SOURCES_REFERENCES_PATHS", &cfg.Sources.References.Paths)
+
+	// Sources: screenshots
+	setBoolFromEnv("MINDCLI_SOURCES_SCREENSHOTS_ENABLED", &cfg.Sources.Screenshots.Enabled)
+	setCSVFromEnv("MINDCLI_SOURCES_SCREENSHOTS_PATHS", &cfg.Sources.Screenshots.Paths)
+	setStringFromEnv("MINDCLI_SOURCES_SCREENSHOTS_OCR_COMMAND", &cfg.Sources.Screenshots.OCRCommand)
+	setIntFromEnv("MINDCLI_SOURCES_SCREENSHOTS_RETENTION_DAYS", &cfg.Sources.Screenshots.RetentionDays)
+
+	// Sources: projects
+	setBoolFromEnv("MINDCLI_SOURCES_PROJECTS_ENABLED", &cfg.Sources.Projects.Enabled)
+	setCSVFromEnv("MINDCLI_SOURCES_PROJECTS_PATHS", &cfg.Sources.Projects.Paths)
+	setCSVFromEnv("MINDCLI_SOURCES_PROJECTS_INCLUDE", &cfg.Sources.Projects.Include)
 
 	// Privacy
 	setCSVFromEnv("MINDCLI_PRIVACY_REDACT_PATTERNS", &cfg.Privacy.RedactPatterns)
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -80,6 +82,238 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid max_contexts",
+			modify: func(c *Config) {
+				c.Ask.MaxContexts = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "tiny max_context_chars",
+			modify: func(c *Config) {
+				c.Ask.MaxContextChars = 10
+			},
+			wantErr: true,
+		},
+		{
+			name: "long answers",
+			modify: func(c *Config) {
+				c.Ask.AnswerLength = "long"
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown answer_length",
+			modify: func(c *Config) {
+				c.Ask.AnswerLength = "epic"
+			},
+			wantErr: true,
+		},
+		{
+			name: "min_relevance above 1",
+			modify: func(c *Config) {
+				c.Ask.MinRelevance = 1.5
+			},
+			wantErr: true,
+		},
+		{
+			name: "fts5 backend",
+			modify: func(c *Config) {
+				c.Search.Backend = "fts5"
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown backend",
+			modify: func(c *Config) {
+				c.Search.Backend = "lucene"
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero preview_length",
+			modify: func(c *Config) {
+				c.Display.PreviewLength = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative snippet_length",
+			modify: func(c *Config) {
+				c.Display.SnippetLength = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "unlimited preview panel",
+			modify: func(c *Config) {
+				c.Display.PreviewPanelLength = 0
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative section_min_chars",
+			modify: func(c *Config) {
+				c.Sources.Markdown.SectionMinChars = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "markdown vaults",
+			modify: func(c *Config) {
+				c.Sources.Markdown.Vaults = []VaultConfig{
+					{Name: "work", Paths: []string{"/work/notes"}},
+					{Name: "personal", Paths: []string{"/home/me/vault"}, Ignore: []string{"journal"}},
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "unnamed vault",
+			modify: func(c *Config) {
+				c.Sources.Markdown.Vaults = []VaultConfig{{Paths: []string{"/work/notes"}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "vault name with a space",
+			modify: func(c *Config) {
+				c.Sources.Markdown.Vaults = []VaultConfig{{Name: "day job", Paths: []string{"/work/notes"}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate vault",
+			modify: func(c *Config) {
+				c.Sources.Markdown.Vaults = []VaultConfig{
+					{Name: "work", Paths: []string{"/work/notes"}},
+					{Name: "work", Paths: []string{"/work/wiki"}},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "vault without paths",
+			modify: func(c *Config) {
+				c.Sources.Markdown.Vaults = []VaultConfig{{Name: "work"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "indexing order by size",
+			modify: func(c *Config) {
+				c.Indexing.Order = "size"
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown indexing order",
+			modify: func(c *Config) {
+				c.Indexing.Order = "random"
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative indexing confirm files",
+			modify: func(c *Config) {
+				c.Indexing.Confirm.Files = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative indexing confirm size_mb",
+			modify: func(c *Config) {
+				c.Indexing.Confirm.SizeMB = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "no indexing confirm limits",
+			modify: func(c *Config) {
+				c.Indexing.Confirm = ConfirmConfig{}
+			},
+			wantErr: false,
+		},
+		{
+			name: "zero data max_records",
+			modify: func(c *Config) {
+				c.Sources.Data.MaxRecords = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative browser max_entries",
+			modify: func(c *Config) {
+				c.Sources.Browser.MaxEntries = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative browser retention_days",
+			modify: func(c *Config) {
+				c.Sources.Browser.RetentionDays = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative clipboard retention_days",
+			modify: func(c *Config) {
+				c.Sources.Clipboard.RetentionDays = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative clipboard max_size_mb",
+			modify: func(c *Config) {
+				c.Sources.Clipboard.MaxSizeMB = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "no clipboard budget",
+			modify: func(c *Config) {
+				c.Sources.Clipboard.MaxDocuments, c.Sources.Clipboard.MaxSizeMB = 0, 0
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative screenshots retention_days",
+			modify: func(c *Config) {
+				c.Sources.Screenshots.RetentionDays = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "screenshots without ocr_command",
+			modify: func(c *Config) {
+				c.Sources.Screenshots.Enabled = true
+				c.Sources.Screenshots.OCRCommand = " "
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid digest",
+			modify: func(c *Config) {
+				c.Notifications.Digests = []DigestConfig{{Name: "inbox", Query: "tag:inbox", Schedule: "daily 08:00", Command: "cat"}}
+			},
+			wantErr: false,
+		},
+		{
+			name: "digest without delivery",
+			modify: func(c *Config) {
+				c.Notifications.Digests = []DigestConfig{{Name: "inbox", Query: "tag:inbox", Schedule: "daily 08:00"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate digest names",
+			modify: func(c *Config) {
+				d := DigestConfig{Name: "inbox", Query: "tag:inbox", Schedule: "hourly", Webhook: "http://localhost/hook"}
+				c.Notifications.Digests = []DigestConfig{d, d}
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid workers",
 			modify: func(c *Config) {
@@ -87,6 +321,70 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative throttle rate",
+			modify: func(c *Config) {
+				c.Indexing.Throttle.MaxFilesPerSecond = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative embed pause",
+			modify: func(c *Config) {
+				c.Indexing.Throttle.EmbedPauseMs = -5
+			},
+			wantErr: true,
+		},
+		{
+			name: "language model",
+			modify: func(c *Config) {
+				c.Embeddings.LanguageModels = map[string]string{"de": "jina/jina-embeddings-v2-base-de"}
+			},
+			wantErr: false,
+		},
+		{
+			name: "language without a model",
+			modify: func(c *Config) {
+				c.Embeddings.LanguageModels = map[string]string{"de": ""}
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative memory cap",
+			modify: func(c *Config) {
+				c.Indexing.MaxMemoryMB = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "unlimited memory and embed batches",
+			modify: func(c *Config) {
+				c.Indexing.MaxMemoryMB = 0
+				c.Indexing.EmbedBatchSize = 0
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative embed batch size",
+			modify: func(c *Config) {
+				c.Indexing.EmbedBatchSize = -8
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative snapshot count",
+			modify: func(c *Config) {
+				c.Storage.Snapshots.Count = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "snapshots disabled",
+			modify: func(c *Config) {
+				c.Storage.Snapshots.Count = 0
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalid embeddings provider",
 			modify: func(c *Config) {
@@ -213,7 +511,7 @@ func TestMarkdownSourceDefaults(t *testing.T) {
 	md := cfg.Sources.Markdown
 
 	// Check extensions
-	expectedExts := map[string]bool{".md": true, ".txt": true}
+	expectedExts := map[string]bool{".md": true, ".txt": true, ".canvas": true, ".excalidraw": true, ".ipynb": true}
 	for _, ext := range md.Extensions {
 		if !expectedExts[ext] {
 			t.Errorf("Unexpected extension in defaults: %s", ext)
@@ -253,6 +551,13 @@ func TestEmbeddingsDefaults(t *testing.T) {
 	}
 }
 
+func TestDisplayDefaults(t *testing.T) {
+	d := Default().Display
+	if d.PreviewLength != 500 || d.SnippetLength != 300 || d.PreviewPanelLength != 2000 {
+		t.Errorf("Display = %+v, want preview 500, snippet 300, panel 2000", d)
+	}
+}
+
 func TestEmailSourceDefaults(t *testing.T) {
 	cfg := Default()
 	email := cfg.Sources.Email
@@ -335,6 +640,87 @@ func TestClipboardSourceDefaults(t *testing.T) {
 	}
 }
 
+func TestDataSourceDefaults(t *testing.T) {
+	data := Default().Sources.Data
+
+	if data.Enabled {
+		t.Error("Expected data source to be disabled by default")
+	}
+	if want := []string{".csv", ".tsv", ".json", ".jsonl"}; !slices.Equal(data.Extensions, want) {
+		t.Errorf("Extensions = %v, want %v", data.Extensions, want)
+	}
+	if data.MaxRecords != 5000 {
+		t.Errorf("Expected max_records 5000, got %d", data.MaxRecords)
+	}
+}
+
+func TestReferencesSourceDefaults(t *testing.T) {
+	refs := Default().Sources.References
+
+	if refs.Enabled {
+		t.Error("Expected references source to be disabled by default")
+	}
+	if len(refs.Paths) != 1 || filepath.Base(refs.Paths[0]) != "zotero.sqlite" {
+		t.Errorf("Paths = %v, want the Zotero database", refs.Paths)
+	}
+}
+
+func TestScreenshotsSourceDefaults(t *testing.T) {
+	shots := Default().Sources.Screenshots
+
+	if shots.Enabled {
+		t.Error("Expected screenshots source to be disabled by default")
+	}
+	if shots.OCRCommand != "tesseract {path} stdout" {
+		t.Errorf("OCRCommand = %q, want tesseract", shots.OCRCommand)
+	}
+	if shots.RetentionDays != 90 {
+		t.Errorf("Expected retention_days 90, got %d", shots.RetentionDays)
+	}
+	if got := defaultScreenshotsDir("darwin", "/home/me"); got != "/home/me/Desktop" {
+		t.Errorf("defaultScreenshotsDir(darwin) = %q, want the desktop", got)
+	}
+	if got := defaultScreenshotsDir("linux", "/home/me"); got != "/home/me/Pictures/Screenshots" {
+		t.Errorf("defaultScreenshotsDir(linux) = %q, want ~/Pictures/Screenshots", got)
+	}
+}
+
+func TestClipboardExcludesPasswordManagers(t *testing.T) {
+	apps := Default().Sources.Clipboard.ExcludeApps
+	for _, want := range []string{"1Password", "Bitwarden", "KeePassXC"} {
+		if !slices.Contains(apps, want) {
+			t.Errorf("ExcludeApps = %v, want %s excluded by default", apps, want)
+		}
+	}
+
+	t.Setenv("MINDCLI_CONFIG_PATH", filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("MINDCLI_SOURCES_CLIPBOARD_EXCLUDE_APPS", "My Bank,1Password")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Sources.Clipboard.ExcludeApps; !slices.Equal(got, []string{"My Bank", "1Password"}) {
+		t.Errorf("ExcludeApps = %v, want the env list", got)
+	}
+}
+
+func TestProjectsSourceDefaults(t *testing.T) {
+	cfg := Default()
+	projects := cfg.Sources.Projects
+
+	if projects.Enabled || len(projects.Paths) != 0 {
+		t.Errorf("projects = %+v, want disabled with no projects", projects)
+	}
+	if want := []string{"README*", "docs/**/*.md", "CHANGELOG*"}; !slices.Equal(projects.Include, want) {
+		t.Errorf("Include = %v, want %v", projects.Include, want)
+	}
+
+	cfg.Sources.Projects.Include = []string{"docs/[.md"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject a malformed include glob")
+	}
+}
+
 func TestLoadAppliesEnvOverrides(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -346,12 +732,27 @@ func TestLoadAppliesEnvOverrides(t *testing.T) {
 
 	t.Setenv("MINDCLI_CONFIG_PATH", configPath)
 	t.Setenv("MINDCLI_SEARCH_HYBRID_WEIGHT", "0.9")
+	t.Setenv("MINDCLI_SEARCH_BACKEND", "fts5")
+	t.Setenv("MINDCLI_ASK_MAX_CONTEXTS", "8")
+	t.Setenv("MINDCLI_ASK_ANSWER_LENGTH", "medium")
+	t.Setenv("MINDCLI_ASK_CACHE_ANSWERS", "false")
+	t.Setenv("MINDCLI_QUERY_LLM_PARSING", "true")
+	t.Setenv("MINDCLI_DISPLAY_SNIPPET_LENGTH", "120")
 	t.Setenv("MINDCLI_INDEXING_WORKERS", "8")
+	t.Setenv("MINDCLI_INDEXING_THROTTLE_MAX_FILES_PER_SECOND", "2.5")
+	t.Setenv("MINDCLI_INDEXING_THROTTLE_EMBED_PAUSE_MS", "200")
+	t.Setenv("MINDCLI_INDEXING_LOW_MEMORY", "true")
+	t.Setenv("MINDCLI_INDEXING_CONFIRM_FILES", "1000")
+	t.Setenv("MINDCLI_INDEXING_ORDER", "path")
 	t.Setenv("MINDCLI_STORAGE_PATH", filepath.Join(tmpDir, "data"))
+	t.Setenv("MINDCLI_STORAGE_SNAPSHOTS_COUNT", "3")
+	t.Setenv("MINDCLI_SYNC_REMOTE", "git:~/mindcli-sync")
 	t.Setenv("MINDCLI_SOURCES_MARKDOWN_PATHS", "/tmp/notes,/tmp/wiki")
 	t.Setenv("MINDCLI_SOURCES_EMAIL_IGNORE", "private,secret")
 	t.Setenv("MINDCLI_SOURCES_EMAIL_MASK_SENSITIVE_PREVIEW", "false")
 	t.Setenv("MINDCLI_PRIVACY_REDACT_PATTERNS", "token-[0-9]+,secret-[a-z]+")
+	t.Setenv("MINDCLI_ASK_EXCLUDED_SOURCES", "clipboard")
+	t.Setenv("MINDCLI_ASK_MIN_RELEVANCE", "0.5")
 
 	cfg, err := Load()
 	if err != nil {
@@ -361,11 +762,59 @@ func TestLoadAppliesEnvOverrides(t *testing.T) {
 	if cfg.Search.HybridWeight != 0.9 {
 		t.Errorf("Search.HybridWeight = %v, want 0.9", cfg.Search.HybridWeight)
 	}
+	if cfg.Search.Backend != "fts5" {
+		t.Errorf("Search.Backend = %q, want fts5", cfg.Search.Backend)
+	}
+
+	if cfg.Ask.MaxContexts != 8 {
+		t.Errorf("Ask.MaxContexts = %d, want 8", cfg.Ask.MaxContexts)
+	}
+	if cfg.Ask.AnswerLength != "medium" {
+		t.Errorf("Ask.AnswerLength = %q, want medium", cfg.Ask.AnswerLength)
+	}
+	if cfg.Ask.CacheAnswers {
+		t.Error("Ask.CacheAnswers = true, want false from the environment")
+	}
+	if len(cfg.Ask.ExcludedSources) != 1 || cfg.Ask.ExcludedSources[0] != "clipboard" {
+		t.Errorf("Ask.ExcludedSources = %v, want [clipboard]", cfg.Ask.ExcludedSources)
+	}
+	if cfg.Ask.MinRelevance != 0.5 {
+		t.Errorf("Ask.MinRelevance = %v, want 0.5", cfg.Ask.MinRelevance)
+	}
+	if !cfg.Query.LLMParsing {
+		t.Error("Query.LLMParsing = false, want true from the environment")
+	}
+	if cfg.Display.SnippetLength != 120 {
+		t.Errorf("Display.SnippetLength = %d, want 120", cfg.Display.SnippetLength)
+	}
 
 	if cfg.Indexing.Workers != 8 {
 		t.Errorf("Indexing.Workers = %d, want 8", cfg.Indexing.Workers)
 	}
 
+	if cfg.Indexing.Throttle.MaxFilesPerSecond != 2.5 {
+		t.Errorf("Indexing.Throttle.MaxFilesPerSecond = %v, want 2.5", cfg.Indexing.Throttle.MaxFilesPerSecond)
+	}
+	if cfg.Indexing.Throttle.EmbedPauseMs != 200 {
+		t.Errorf("Indexing.Throttle.EmbedPauseMs = %d, want 200", cfg.Indexing.Throttle.EmbedPauseMs)
+	}
+	if !cfg.Indexing.LowMemory {
+		t.Error("Indexing.LowMemory = false, want true")
+	}
+	if cfg.Indexing.Order != "path" {
+		t.Errorf("Indexing.Order = %q, want path", cfg.Indexing.Order)
+	}
+	if cfg.Indexing.Confirm.Files != 1000 {
+		t.Errorf("Indexing.Confirm.Files = %d, want 1000", cfg.Indexing.Confirm.Files)
+	}
+
+	if cfg.Storage.Snapshots.Count != 3 {
+		t.Errorf("Storage.Snapshots.Count = %d, want 3", cfg.Storage.Snapshots.Count)
+	}
+	if cfg.Sync.Remote != "git:~/mindcli-sync" {
+		t.Errorf("Sync.Remote = %q, want git:~/mindcli-sync", cfg.Sync.Remote)
+	}
+
 	wantStorage := filepath.Join(tmpDir, "data")
 	if cfg.Storage.Path != wantStorage {
 		t.Errorf("Storage.Path = %q, want %q", cfg.Storage.Path, wantStorage)
@@ -417,6 +866,34 @@ func TestLoadExpandsTildePaths(t *testing.T) {
 	}
 }
 
+func TestLoadExpandsVaultPaths(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home dir: %v", err)
+	}
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "sources:\n  markdown:\n    vaults:\n      - name: work\n        paths: [~/work-notes]\n        ignore: [drafts]\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MINDCLI_CONFIG_PATH", configPath)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Sources.Markdown.Vaults) != 1 {
+		t.Fatalf("Sources.Markdown.Vaults = %#v, want one vault", cfg.Sources.Markdown.Vaults)
+	}
+	v := cfg.Sources.Markdown.Vaults[0]
+	if want := filepath.Join(home, "work-notes"); v.Name != "work" || len(v.Paths) != 1 || v.Paths[0] != want {
+		t.Errorf("vault = %#v, want work at %s", v, want)
+	}
+	if strings.Join(v.Ignore, ",") != "drafts" {
+		t.Errorf("vault ignore = %v, want [drafts]", v.Ignore)
+	}
+}
+
 func TestLoadAppliesEnvOverridesWithoutConfigFile(t *testing.T) {
 	// Env overrides must apply even when no config file exists on disk.
 	t.Setenv("MINDCLI_CONFIG_PATH", filepath.Join(t.TempDir(), "absent.yaml"))
@@ -463,3 +940,87 @@ func TestConfigPathAndDirFromEnv(t *testing.T) {
 		t.Fatalf("EnsureConfigDir() did not create %q: %v", customDir, err)
 	}
 }
+
+func TestPathRoots(t *testing.T) {
+	cfg := &Config{}
+	cfg.Sources.Markdown.Paths = []string{"/home/me/notes", "/work/notes", "/home/me/notes/"}
+	cfg.Sources.Markdown.Vaults = []VaultConfig{{Name: "personal", Paths: []string{"/home/me/vault"}}}
+	cfg.Sources.PDF.Paths = []string{"/home/me/Documents", "relative"}
+
+	got := cfg.PathRoots()
+	want := map[string]string{
+		"notes":     "/home/me/notes",
+		"notes-2":   "/work/notes",
+		"vault":     "/home/me/vault",
+		"Documents": "/home/me/Documents",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("PathRoots() = %v, want %v", got, want)
+	}
+
+	cfg.Storage.Roots = map[string]string{"vault": "/srv/vault"}
+	if got := cfg.PathRoots(); fmt.Sprint(got) != "map[vault:/srv/vault]" {
+		t.Errorf("PathRoots() with storage.roots = %v, want only the configured roots", got)
+	}
+}
+
+func TestDefaultDataDir(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(k string) string { return vars[k] }
+	}
+	tests := []struct {
+		goos string
+		env  map[string]string
+		want string
+	}{
+		{"linux", nil, "/home/me/.local/share/mindcli"},
+		{"linux", map[string]string{"XDG_DATA_HOME": "/data"}, "/data/mindcli"},
+		{"linux", map[string]string{"XDG_DATA_HOME": "relative"}, "/home/me/.local/share/mindcli"},
+		{"darwin", nil, "/home/me/Library/Application Support/mindcli"},
+		{"darwin", map[string]string{"XDG_DATA_HOME": "/data"}, "/data/mindcli"},
+		{"windows", map[string]string{"APPDATA": "/appdata"}, "/appdata/mindcli"},
+	}
+	for _, tt := range tests {
+		if got := defaultDataDir(tt.goos, "/home/me", env(tt.env)); got != tt.want {
+			t.Errorf("defaultDataDir(%s, %v) = %q, want %q", tt.goos, tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateDataDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	legacy := filepath.Join(tmpDir, "legacy")
+	target := filepath.Join(tmpDir, "Application Support", "mindcli")
+
+	// Nothing to migrate.
+	if got := migrateDataDir(legacy, target); got != target {
+		t.Errorf("migrateDataDir() without legacy data = %q, want %q", got, target)
+	}
+
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacy, "mindcli.db"), []byte("db"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := migrateDataDir(legacy, target); got != target {
+		t.Fatalf("migrateDataDir() = %q, want %q", got, target)
+	}
+	if _, err := os.Stat(filepath.Join(target, "mindcli.db")); err != nil {
+		t.Errorf("database not moved: %v", err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("legacy directory still exists: %v", err)
+	}
+
+	// An existing target is never overwritten.
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := migrateDataDir(legacy, target); got != target {
+		t.Errorf("migrateDataDir() with existing target = %q, want %q", got, target)
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("legacy directory removed despite existing target: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package devicesync syncs curation (manual tags and collections) between
+// machines through a shared remote. Each device publishes its view as a JSONL
+// file; devices merge every file they find, and the most recent change to a
+// record wins.
+package devicesync
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// Record kinds.
+const (
+	KindTag        = "tag"
+	KindCollection = "collection"
+	KindMember     = "member"
+)
+
+// Record is one synced fact: a tag on a document, a collection, or a
+// document's membership of a collection. Deleted records are tombstones that
+// propagate removals.
+type Record struct {
+	Kind        string    `json:"kind"`
+	Path        string    `json:"path,omitempty"`         // document path, ~ for the home directory
+	ContentHash string    `json:"content_hash,omitempty"` // fallback when paths differ between machines
+	Tag         string    `json:"tag,omitempty"`
+	Collection  string    `json:"collection,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Query       string    `json:"query,omitempty"`
+	Deleted     bool      `json:"deleted,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Device      string    `json:"device,omitempty"` // device that made the change
+}
+
+// Key identifies the fact a record describes, independent of its state.
+func (r Record) Key() string {
+	switch r.Kind {
+	case KindTag:
+		return KindTag + "\x00" + r.Path + "\x00" + r.Tag
+	case KindMember:
+		return KindMember + "\x00" + r.Collection + "\x00" + r.Path
+	default:
+		return KindCollection + "\x00" + r.Collection
+	}
+}
+
+// sameValue reports whether r and o describe the same state, ignoring when
+// and where the change was made.
+func (r Record) sameValue(o Record) bool {
+	return r.Deleted == o.Deleted && r.Description == o.Description && r.Query == o.Query
+}
+
+// newer reports whether r should replace o when merging: the later change
+// wins, and on a tie the device name decides so every machine agrees.
+func (r Record) newer(o Record) bool {
+	if !r.UpdatedAt.Equal(o.UpdatedAt) {
+		return r.UpdatedAt.After(o.UpdatedAt)
+	}
+	return r.Device > o.Device
+}
+
+// PortablePath rewrites a path under the home directory to start with ~ so
+// it matches on machines with different home directories.
+func PortablePath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(filepath.Join("~", rel))
+	}
+	return path
+}
+
+// LocalPath is the inverse of PortablePath.
+func LocalPath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, filepath.FromSlash(strings.TrimPrefix(path, "~")))
+}
+
+func tagRecord(t storage.TagAssignment) Record {
+	return Record{Kind: KindTag, Path: PortablePath(t.Document.Path), ContentHash: t.Document.ContentHash, Tag: t.Tag}
+}
+
+func memberRecord(m storage.CollectionMember) Record {
+	return Record{Kind: KindMember, Path: PortablePath(m.Document.Path), ContentHash: m.Document.ContentHash, Collection: m.Collection}
+}
+
+func collectionRecord(c *storage.Collection) Record {
+	return Record{Kind: KindCollection, Collection: c.Name, Description: c.Description, Query: c.Query}
+}
+
+// encodeRecords writes records as JSONL.
+func encodeRecords(records []Record) ([]byte, error) {
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return nil, err
+		}
+	}
+	return []byte(sb.String()), nil
+}
+
+// decodeRecords parses JSONL, skipping blank lines.
+func decodeRecords(data []byte) ([]Record, error) {
+	var records []Record
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
@@ -0,0 +1,84 @@
+package devicesync
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordNewer(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := Record{UpdatedAt: t0.Add(time.Second), Device: "a"}
+	b := Record{UpdatedAt: t0, Device: "b"}
+	if !a.newer(b) || b.newer(a) {
+		t.Error("the later change should win")
+	}
+	b.UpdatedAt = a.UpdatedAt
+	if a.newer(b) || !b.newer(a) {
+		t.Error("on a tie the higher device name should win")
+	}
+}
+
+func TestRecordKey(t *testing.T) {
+	tag := Record{Kind: KindTag, Path: "~/a.md", Tag: "go"}
+	deleted := tag
+	deleted.Deleted = true
+	deleted.UpdatedAt = time.Now()
+	if tag.Key() != deleted.Key() {
+		t.Error("a tombstone should share the key of the record it deletes")
+	}
+	other := Record{Kind: KindMember, Path: "~/a.md", Collection: "go"}
+	if tag.Key() == other.Key() {
+		t.Error("records of different kinds should not share a key")
+	}
+}
+
+func TestPortablePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		path, want string
+	}{
+		{filepath.Join(home, "notes", "a.md"), "~/notes/a.md"},
+		{home, "~"},
+		{"/elsewhere/a.md", "/elsewhere/a.md"},
+		{home + "2/a.md", home + "2/a.md"},
+	}
+	for _, tt := range tests {
+		got := PortablePath(tt.path)
+		if got != tt.want {
+			t.Errorf("PortablePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+		if back := LocalPath(got); back != tt.path {
+			t.Errorf("LocalPath(%q) = %q, want %q", got, back, tt.path)
+		}
+	}
+}
+
+func TestEncodeDecodeRecords(t *testing.T) {
+	in := []Record{
+		{Kind: KindTag, Path: "~/a.md", ContentHash: "h", Tag: "go", UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Device: "laptop"},
+		{Kind: KindCollection, Collection: "reading", Deleted: true},
+	}
+	data, err := encodeRecords(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := decodeRecords(append(data, '\n'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("decoded %d records, want %d", len(out), len(in))
+	}
+	for i := range in {
+		if out[i].Key() != in[i].Key() || !out[i].sameValue(in[i]) || !out[i].UpdatedAt.Equal(in[i].UpdatedAt) || out[i].Device != in[i].Device {
+			t.Errorf("record %d = %+v, want %+v", i, out[i], in[i])
+		}
+	}
+
+	if _, err := decodeRecords([]byte("{not json\n")); err == nil {
+		t.Error("decodeRecords() of invalid JSON succeeded, want error")
+	}
+}
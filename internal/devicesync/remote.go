@@ -0,0 +1,161 @@
+package devicesync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// fileExt is the extension of device sync files in a remote.
+const fileExt = ".jsonl"
+
+// Remote is where devices exchange their sync files.
+type Remote interface {
+	// Fetch makes the latest remote files available and returns the local
+	// directory holding them.
+	Fetch(ctx context.Context) (string, error)
+	// Publish stores this device's sync file in the remote.
+	Publish(ctx context.Context, device string, data []byte) error
+}
+
+// ParseRemote returns the remote described by spec:
+//
+//	/path/to/dir          a plain directory (synced folder, network mount)
+//	git:/path/to/checkout a git checkout, pulled before and pushed after
+//	rclone:remote:path    any rclone remote (S3, WebDAV, ...)
+//
+// stagingDir holds local copies of files for remotes that need them.
+func ParseRemote(spec, stagingDir string) (Remote, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		return nil, errors.New("no sync remote configured (set sync.remote)")
+	case strings.HasPrefix(spec, "rclone:"):
+		target := strings.TrimPrefix(spec, "rclone:")
+		if target == "" {
+			return nil, errors.New("rclone remote is empty")
+		}
+		return &rcloneRemote{target: target, staging: stagingDir}, nil
+	case strings.HasPrefix(spec, "git:"):
+		return &gitRemote{dirRemote{dir: LocalPath(strings.TrimPrefix(spec, "git:"))}}, nil
+	default:
+		return &dirRemote{dir: LocalPath(spec)}, nil
+	}
+}
+
+// dirRemote is a directory shared between machines by other means.
+type dirRemote struct {
+	dir string
+}
+
+func (r *dirRemote) Fetch(context.Context) (string, error) {
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return "", fmt.Errorf("creating sync directory: %w", err)
+	}
+	return r.dir, nil
+}
+
+func (r *dirRemote) Publish(_ context.Context, device string, data []byte) error {
+	return writeFileAtomic(filepath.Join(r.dir, device+fileExt), data)
+}
+
+// gitRemote is a git checkout whose upstream is the shared copy.
+type gitRemote struct {
+	dirRemote
+}
+
+func (r *gitRemote) Fetch(ctx context.Context) (string, error) {
+	if _, err := runCommand(ctx, "git", "-C", r.dir, "pull", "--rebase", "--quiet"); err != nil {
+		return "", err
+	}
+	return r.dir, nil
+}
+
+func (r *gitRemote) Publish(ctx context.Context, device string, data []byte) error {
+	if err := r.dirRemote.Publish(ctx, device, data); err != nil {
+		return err
+	}
+	name := device + fileExt
+	if _, err := runCommand(ctx, "git", "-C", r.dir, "add", "--", name); err != nil {
+		return err
+	}
+	// Nothing staged means nothing changed since the last sync.
+	if _, err := runCommand(ctx, "git", "-C", r.dir, "diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+	if _, err := runCommand(ctx, "git", "-C", r.dir, "commit", "--quiet", "-m", "mindcli sync from "+device); err != nil {
+		return err
+	}
+	_, err := runCommand(ctx, "git", "-C", r.dir, "push", "--quiet")
+	return err
+}
+
+// rcloneRemote copies sync files to and from an rclone remote through a
+// local staging directory.
+type rcloneRemote struct {
+	target  string
+	staging string
+}
+
+func (r *rcloneRemote) Fetch(ctx context.Context) (string, error) {
+	if err := os.MkdirAll(r.staging, 0o755); err != nil {
+		return "", fmt.Errorf("creating staging directory: %w", err)
+	}
+	if _, err := runCommand(ctx, "rclone", "copy", "--include", "*"+fileExt, r.target, r.staging); err != nil {
+		return "", err
+	}
+	return r.staging, nil
+}
+
+func (r *rcloneRemote) Publish(ctx context.Context, device string, data []byte) error {
+	local := filepath.Join(r.staging, device+fileExt)
+	if err := writeFileAtomic(local, data); err != nil {
+		return err
+	}
+	_, err := runCommand(ctx, "rclone", "copyto", local, strings.TrimSuffix(r.target, "/")+"/"+device+fileExt)
+	return err
+}
+
+// runCommand runs an external tool, folding its stderr into any error.
+func runCommand(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s %s: %w: %s", name, args[0], err, msg)
+		}
+		return "", fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// writeFileAtomic replaces path with data so other machines never see a
+// partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating sync directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("writing sync file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing sync file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing sync file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing sync file: %w", err)
+	}
+	return nil
+}
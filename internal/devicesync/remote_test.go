@@ -0,0 +1,90 @@
+package devicesync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRemote(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "", wantErr: true},
+		{spec: "rclone:", wantErr: true},
+		{spec: "~/Dropbox/mindcli", want: "*devicesync.dirRemote"},
+		{spec: "git:~/sync", want: "*devicesync.gitRemote"},
+		{spec: "rclone:s3:bucket/mindcli", want: "*devicesync.rcloneRemote"},
+	}
+	for _, tt := range tests {
+		r, err := ParseRemote(tt.spec, "/tmp/staging")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRemote(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if err == nil {
+			if got := fmt.Sprintf("%T", r); got != tt.want {
+				t.Errorf("ParseRemote(%q) = %s, want %s", tt.spec, got, tt.want)
+			}
+		}
+	}
+
+	r, _ := ParseRemote("~/Dropbox/mindcli", "")
+	if dir := r.(*dirRemote).dir; dir != filepath.Join("/home/me", "Dropbox", "mindcli") {
+		t.Errorf("dir = %q, want ~ expanded", dir)
+	}
+}
+
+func TestDirRemotePublish(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "remote")
+	r := &dirRemote{dir: dir}
+	ctx := context.Background()
+
+	got, err := r.Fetch(ctx)
+	if err != nil || got != dir {
+		t.Fatalf("Fetch() = %q, %v, want %q", got, err, dir)
+	}
+	if err := r.Publish(ctx, "laptop", []byte("one\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Publish(ctx, "laptop", []byte("two\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "laptop.jsonl"))
+	if err != nil || string(data) != "two\n" {
+		t.Errorf("published file = %q, %v, want the latest data", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("remote holds %d files, want only laptop.jsonl", len(entries))
+	}
+}
+
+func TestReadDeviceFilesSkipsOwnFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"laptop.jsonl":  `{"kind":"tag","path":"~/a.md","tag":"mine"}`,
+		"desktop.jsonl": `{"kind":"tag","path":"~/a.md","tag":"theirs"}`,
+		".tmp-x.jsonl":  `garbage`,
+		"notes.txt":     `garbage`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, devices, err := readDeviceFiles(dir, "laptop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if devices != 1 || len(records) != 1 || records[0].Tag != "theirs" {
+		t.Errorf("readDeviceFiles() = %+v from %d devices, want desktop's record only", records, devices)
+	}
+}
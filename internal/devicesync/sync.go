@@ -0,0 +1,408 @@
+package devicesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// Mode selects the directions a sync runs in.
+type Mode int
+
+const (
+	Pull Mode = 1 << iota // apply other devices' changes locally
+	Push                  // publish this device's state
+	Both = Pull | Push
+)
+
+// Result summarizes a sync.
+type Result struct {
+	LocalChanges int // local changes detected since the last sync
+	Applied      int // remote changes applied to the local database
+	Pending      int // remote changes waiting for their document to be indexed here
+	Devices      int // other devices seen in the remote
+}
+
+// Syncer syncs one database with a remote.
+type Syncer struct {
+	db     *storage.DB
+	remote Remote
+	device string
+	now    func() time.Time
+}
+
+// NewSyncer creates a syncer publishing as device.
+func NewSyncer(db *storage.DB, remote Remote, device string) *Syncer {
+	return &Syncer{db: db, remote: remote, device: device, now: time.Now}
+}
+
+// Sync detects local changes since the last sync, merges the remote state
+// (pull) and publishes the merged state (push).
+func (s *Syncer) Sync(ctx context.Context, mode Mode) (*Result, error) {
+	if s.device == "" || strings.ContainsAny(s.device, `/\`) {
+		return nil, fmt.Errorf("invalid device name %q", s.device)
+	}
+	res := &Result{}
+
+	prev, wasLocal, err := s.loadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.currentRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := s.detectLocalChanges(ctx, prev, wasLocal, current, res)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode&Pull != 0 {
+		dir, err := s.remote.Fetch(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetching remote: %w", err)
+		}
+		remote, devices, err := readDeviceFiles(dir, s.device)
+		if err != nil {
+			return nil, err
+		}
+		res.Devices = devices
+		for _, r := range remote {
+			if cur, ok := merged[r.Key()]; !ok || r.newer(cur) {
+				merged[r.Key()] = r
+			}
+		}
+		if err := s.apply(ctx, merged, current, res); err != nil {
+			return nil, err
+		}
+		if current, err = s.currentRecords(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.saveState(ctx, merged, current); err != nil {
+		return nil, err
+	}
+
+	if mode&Push != 0 {
+		data, err := encodeRecords(sortedRecords(merged))
+		if err != nil {
+			return nil, fmt.Errorf("encoding sync file: %w", err)
+		}
+		if err := s.remote.Publish(ctx, s.device, data); err != nil {
+			return nil, fmt.Errorf("publishing to remote: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// currentRecords reads the local curation state.
+func (s *Syncer) currentRecords(ctx context.Context) (map[string]Record, error) {
+	records := make(map[string]Record)
+	cols, err := s.db.ListCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cols {
+		r := collectionRecord(c)
+		records[r.Key()] = r
+	}
+	tags, err := s.db.ListManualTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		r := tagRecord(t)
+		records[r.Key()] = r
+	}
+	members, err := s.db.ListCollectionMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		r := memberRecord(m)
+		records[r.Key()] = r
+	}
+	return records, nil
+}
+
+// detectLocalChanges compares the current state with the state saved by the
+// last sync. New or changed facts are stamped now. Facts that were present
+// locally and have disappeared become tombstones, unless their document is
+// simply no longer indexed here; other records are carried forward untouched.
+func (s *Syncer) detectLocalChanges(ctx context.Context, prev map[string]Record, wasLocal map[string]bool, current map[string]Record, res *Result) (map[string]Record, error) {
+	now := s.now().UTC()
+	merged := make(map[string]Record, len(current))
+
+	for key, r := range current {
+		if p, ok := prev[key]; ok && p.sameValue(r) {
+			merged[key] = p
+			continue
+		}
+		r.UpdatedAt = now
+		r.Device = s.device
+		merged[key] = r
+		res.LocalChanges++
+	}
+
+	for key, p := range prev {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		if p.Deleted || !wasLocal[key] {
+			merged[key] = p
+			continue
+		}
+		if p.Kind != KindCollection {
+			doc, err := s.resolveDocument(ctx, p)
+			if err != nil {
+				return nil, err
+			}
+			if doc == nil {
+				merged[key] = p
+				continue
+			}
+		}
+		p.Deleted = true
+		p.UpdatedAt = now
+		p.Device = s.device
+		merged[key] = p
+		res.LocalChanges++
+	}
+	return merged, nil
+}
+
+// apply brings the local database in line with the merged records.
+// Collections are created before memberships are added and deleted after
+// memberships are removed.
+func (s *Syncer) apply(ctx context.Context, merged, current map[string]Record, res *Result) error {
+	records := sortedRecords(merged)
+	order := []string{KindCollection, KindMember, KindTag}
+	for _, kind := range order {
+		for _, r := range records {
+			if r.Kind != kind || (kind == KindCollection && r.Deleted) {
+				continue
+			}
+			if cur, ok := current[r.Key()]; ok && cur.sameValue(r) {
+				continue
+			}
+			if _, ok := current[r.Key()]; !ok && r.Deleted {
+				continue
+			}
+			outcome, err := s.applyRecord(ctx, r, merged)
+			if err != nil {
+				return err
+			}
+			switch outcome {
+			case applied:
+				res.Applied++
+			case pending:
+				res.Pending++
+			}
+		}
+	}
+	for _, r := range records {
+		if r.Kind != KindCollection || !r.Deleted {
+			continue
+		}
+		if _, ok := current[r.Key()]; !ok {
+			continue
+		}
+		if err := s.db.DeleteCollectionByName(ctx, r.Collection); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+		res.Applied++
+	}
+	return nil
+}
+
+// outcome is the result of applying one record.
+type outcome int
+
+const (
+	applied outcome = iota
+	pending         // the document isn't indexed here yet; retried by a later sync
+	skipped         // superseded by the record for the document's local path
+)
+
+// applyRecord applies one live or deleted tag or membership, or a live
+// collection. Pending records stay in the sync state for a later sync.
+func (s *Syncer) applyRecord(ctx context.Context, r Record, merged map[string]Record) (outcome, error) {
+	if r.Kind == KindCollection {
+		return applied, s.upsertCollection(ctx, r)
+	}
+
+	doc, err := s.resolveDocument(ctx, r)
+	if err != nil || doc == nil {
+		return pending, err
+	}
+	// A document found by content hash lives at a different path here. The
+	// record keyed by the local path, if any, is the one that counts.
+	if local := PortablePath(doc.Path); local != r.Path {
+		alias := r
+		alias.Path = local
+		if _, ok := merged[alias.Key()]; ok {
+			return skipped, nil
+		}
+	}
+
+	switch r.Kind {
+	case KindTag:
+		if r.Deleted {
+			err = s.db.RemoveTag(ctx, doc.ID, r.Tag)
+		} else {
+			err = s.db.AddTag(ctx, doc.ID, r.Tag)
+		}
+	case KindMember:
+		col, cErr := s.db.GetCollectionByName(ctx, r.Collection)
+		if errors.Is(cErr, storage.ErrNotFound) {
+			if r.Deleted {
+				return applied, nil
+			}
+			col = &storage.Collection{Name: r.Collection}
+			cErr = s.db.CreateCollection(ctx, col)
+		}
+		if cErr != nil {
+			return pending, cErr
+		}
+		if r.Deleted {
+			err = s.db.RemoveFromCollection(ctx, col.ID, doc.ID)
+		} else {
+			err = s.db.AddToCollection(ctx, col.ID, doc.ID)
+		}
+	}
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return pending, err
+	}
+	return applied, nil
+}
+
+func (s *Syncer) upsertCollection(ctx context.Context, r Record) error {
+	col, err := s.db.GetCollectionByName(ctx, r.Collection)
+	if errors.Is(err, storage.ErrNotFound) {
+		return s.db.CreateCollection(ctx, &storage.Collection{Name: r.Collection, Description: r.Description, Query: r.Query})
+	}
+	if err != nil {
+		return err
+	}
+	if col.Description != r.Description {
+		if err := s.db.UpdateCollectionDescription(ctx, col.ID, r.Description); err != nil {
+			return err
+		}
+	}
+	if col.Query != r.Query {
+		return s.db.UpdateCollectionQuery(ctx, col.ID, r.Query)
+	}
+	return nil
+}
+
+// resolveDocument finds the local document a record refers to.
+func (s *Syncer) resolveDocument(ctx context.Context, r Record) (*storage.Document, error) {
+	return ResolveDocument(ctx, s.db, r.Path, r.ContentHash)
+}
+
+// ResolveDocument finds the local document for a portable path, falling back
+// to the content hash when the file lives elsewhere on this machine. It
+// returns nil when the document isn't indexed here.
+func ResolveDocument(ctx context.Context, db *storage.DB, path, contentHash string) (*storage.Document, error) {
+	doc, err := db.GetDocumentByPath(ctx, LocalPath(path))
+	if err == nil {
+		return doc, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	if contentHash == "" {
+		return nil, nil
+	}
+	doc, err = db.GetDocumentByContentHash(ctx, contentHash)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	return doc, err
+}
+
+// savedRecord is a record in the sync state. Local marks records that were
+// in effect in the local database after the last sync, which tells a local
+// removal apart from a remote change still waiting for its document.
+type savedRecord struct {
+	Record
+	Local bool `json:"local,omitempty"`
+}
+
+func (s *Syncer) loadState(ctx context.Context) (map[string]Record, map[string]bool, error) {
+	raw, err := s.db.LoadSyncState(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	state := make(map[string]Record, len(raw))
+	local := make(map[string]bool, len(raw))
+	for key, data := range raw {
+		var r savedRecord
+		if err := json.Unmarshal([]byte(data), &r); err != nil {
+			return nil, nil, fmt.Errorf("decoding sync record: %w", err)
+		}
+		state[key] = r.Record
+		local[key] = r.Local
+	}
+	return state, local, nil
+}
+
+func (s *Syncer) saveState(ctx context.Context, records, current map[string]Record) error {
+	raw := make(map[string]string, len(records))
+	for key, r := range records {
+		_, local := current[key]
+		data, err := json.Marshal(savedRecord{Record: r, Local: local})
+		if err != nil {
+			return fmt.Errorf("encoding sync record: %w", err)
+		}
+		raw[key] = string(data)
+	}
+	return s.db.SaveSyncState(ctx, raw)
+}
+
+// readDeviceFiles reads every device's sync file in dir except self's and
+// returns the records along with the number of devices found.
+func readDeviceFiles(dir, self string) ([]Record, int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading remote: %w", err)
+	}
+	var records []Record
+	devices := 0
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, fileExt) || name == self+fileExt {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, 0, fmt.Errorf("reading %s: %w", name, err)
+		}
+		recs, err := decodeRecords(data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		records = append(records, recs...)
+		devices++
+	}
+	return records, devices, nil
+}
+
+// sortedRecords returns records in a stable order so sync files diff well.
+func sortedRecords(records map[string]Record) []Record {
+	out := make([]Record, 0, len(records))
+	for _, r := range records {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key() < out[j].Key() })
+	return out
+}
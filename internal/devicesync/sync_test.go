@@ -0,0 +1,221 @@
+package devicesync
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// device is one machine in a sync test: its own database, a shared remote,
+// and a controllable clock.
+type device struct {
+	t      *testing.T
+	db     *storage.DB
+	syncer *Syncer
+	clock  time.Time
+}
+
+func newDevice(t *testing.T, name string, remote Remote) *device {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "mindcli.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	d := &device{t: t, db: db, clock: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	d.syncer = NewSyncer(db, remote, name)
+	d.syncer.now = func() time.Time { return d.clock }
+	return d
+}
+
+func (d *device) addDoc(id, path, hash string) {
+	d.t.Helper()
+	now := time.Now()
+	doc := &storage.Document{ID: id, Source: storage.SourceMarkdown, Path: path, Title: id, ContentHash: hash, IndexedAt: now, ModifiedAt: now}
+	if err := d.db.InsertDocument(context.Background(), doc); err != nil {
+		d.t.Fatalf("InsertDocument() error = %v", err)
+	}
+}
+
+func (d *device) sync(mode Mode) *Result {
+	d.t.Helper()
+	d.clock = d.clock.Add(time.Minute)
+	res, err := d.syncer.Sync(context.Background(), mode)
+	if err != nil {
+		d.t.Fatalf("Sync() error = %v", err)
+	}
+	return res
+}
+
+func (d *device) tags(docID string) string {
+	d.t.Helper()
+	tags, err := d.db.GetTags(context.Background(), docID)
+	if err != nil {
+		d.t.Fatalf("GetTags() error = %v", err)
+	}
+	return fmt.Sprint(tags)
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncTagsBetweenDevices(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	remote := &dirRemote{dir: t.TempDir()}
+	ctx := context.Background()
+
+	laptop := newDevice(t, "laptop", remote)
+	desktop := newDevice(t, "desktop", remote)
+	laptop.addDoc("a", filepath.Join(home, "notes", "a.md"), "ha")
+	desktop.addDoc("x", filepath.Join(home, "notes", "a.md"), "ha")
+
+	must(t, laptop.db.AddTag(ctx, "a", "go"))
+	if res := laptop.sync(Both); res.LocalChanges != 1 {
+		t.Errorf("LocalChanges = %d, want 1", res.LocalChanges)
+	}
+	if res := desktop.sync(Both); res.Applied != 1 || res.Devices != 1 {
+		t.Errorf("desktop result = %+v, want 1 applied from 1 device", res)
+	}
+	if got := desktop.tags("x"); got != "[go]" {
+		t.Errorf("desktop tags = %s, want [go]", got)
+	}
+
+	// A removal on the desktop propagates back as a tombstone.
+	must(t, desktop.db.RemoveTag(ctx, "x", "go"))
+	desktop.sync(Both)
+	laptop.sync(Both)
+	if got := laptop.tags("a"); got != "[]" {
+		t.Errorf("laptop tags after remote removal = %s, want []", got)
+	}
+}
+
+func TestSyncLatestChangeWins(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	remote := &dirRemote{dir: t.TempDir()}
+	ctx := context.Background()
+
+	laptop := newDevice(t, "laptop", remote)
+	desktop := newDevice(t, "desktop", remote)
+	path := filepath.Join(home, "a.md")
+	laptop.addDoc("a", path, "ha")
+	desktop.addDoc("a", path, "ha")
+	must(t, laptop.db.AddTag(ctx, "a", "go"))
+	laptop.sync(Both)
+	desktop.sync(Both)
+
+	// The laptop removes the tag, then the desktop re-adds it later.
+	must(t, laptop.db.RemoveTag(ctx, "a", "go"))
+	laptop.sync(Push)
+	must(t, desktop.db.RemoveTag(ctx, "a", "go"))
+	desktop.sync(Push)
+	desktop.clock = desktop.clock.Add(time.Hour)
+	must(t, desktop.db.AddTag(ctx, "a", "go"))
+	desktop.sync(Both)
+	laptop.sync(Both)
+
+	if got := laptop.tags("a"); got != "[go]" {
+		t.Errorf("laptop tags = %s, want the later re-add [go]", got)
+	}
+}
+
+func TestSyncCollections(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	remote := &dirRemote{dir: t.TempDir()}
+	ctx := context.Background()
+
+	laptop := newDevice(t, "laptop", remote)
+	desktop := newDevice(t, "desktop", remote)
+	laptop.addDoc("a", filepath.Join(home, "a.md"), "ha")
+	desktop.addDoc("a", filepath.Join(home, "a.md"), "ha")
+
+	col := &storage.Collection{Name: "reading", Description: "to read", Query: "tag:go"}
+	must(t, laptop.db.CreateCollection(ctx, col))
+	must(t, laptop.db.AddToCollection(ctx, col.ID, "a"))
+	laptop.sync(Both)
+	desktop.sync(Both)
+
+	got, err := desktop.db.GetCollectionByName(ctx, "reading")
+	must(t, err)
+	if got.Description != "to read" || got.Query != "tag:go" {
+		t.Errorf("desktop collection = %+v, want description and query synced", got)
+	}
+	if n, _ := desktop.db.CountCollectionDocuments(ctx, got.ID); n != 1 {
+		t.Errorf("desktop collection has %d documents, want 1", n)
+	}
+
+	must(t, laptop.db.DeleteCollectionByName(ctx, "reading"))
+	laptop.sync(Both)
+	desktop.sync(Both)
+	if _, err := desktop.db.GetCollectionByName(ctx, "reading"); err != storage.ErrNotFound {
+		t.Errorf("desktop collection after remote delete: error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSyncMatchesByContentHash(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	remote := &dirRemote{dir: t.TempDir()}
+	ctx := context.Background()
+
+	laptop := newDevice(t, "laptop", remote)
+	desktop := newDevice(t, "desktop", remote)
+	laptop.addDoc("a", "/mnt/notes/a.md", "ha")
+	desktop.addDoc("a", "/data/notes/a.md", "ha")
+
+	must(t, laptop.db.AddTag(ctx, "a", "go"))
+	laptop.sync(Both)
+	desktop.sync(Both)
+	if got := desktop.tags("a"); got != "[go]" {
+		t.Errorf("desktop tags = %s, want [go] matched by content hash", got)
+	}
+
+	// The desktop now owns a record for its own path; removing the tag
+	// there must not be undone by the laptop's record.
+	desktop.sync(Both)
+	must(t, desktop.db.RemoveTag(ctx, "a", "go"))
+	desktop.sync(Both)
+	if got := desktop.tags("a"); got != "[]" {
+		t.Errorf("desktop tags after local removal = %s, want []", got)
+	}
+}
+
+func TestSyncKeepsChangesForMissingDocuments(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	remote := &dirRemote{dir: t.TempDir()}
+	ctx := context.Background()
+
+	laptop := newDevice(t, "laptop", remote)
+	desktop := newDevice(t, "desktop", remote)
+	path := filepath.Join(home, "a.md")
+	laptop.addDoc("a", path, "ha")
+	must(t, laptop.db.AddTag(ctx, "a", "go"))
+	laptop.sync(Both)
+
+	if res := desktop.sync(Both); res.Pending != 1 {
+		t.Errorf("Pending = %d, want 1 for a document not indexed yet", res.Pending)
+	}
+
+	desktop.addDoc("a", path, "ha")
+	desktop.sync(Both)
+	if got := desktop.tags("a"); got != "[go]" {
+		t.Errorf("desktop tags after indexing = %s, want [go]", got)
+	}
+}
+
+func TestSyncRejectsBadDeviceName(t *testing.T) {
+	d := newDevice(t, "a/b", &dirRemote{dir: t.TempDir()})
+	if _, err := d.syncer.Sync(context.Background(), Both); err == nil {
+		t.Error("Sync() with a device name containing / succeeded, want error")
+	}
+}
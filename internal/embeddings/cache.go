@@ -46,7 +46,11 @@ func NewCachedEmbedder(inner Embedder, cachePath, model string) (*CachedEmbedder
 // cacheKey scopes the content hash by model so entries from different models
 // never collide.
 func (c *CachedEmbedder) cacheKey(text string) string {
-	return contentHash(c.model + "\x00" + text)
+	return cacheKey(c.model, text)
+}
+
+func cacheKey(model, text string) string {
+	return contentHash(model + "\x00" + text)
 }
 
 // Embed generates or retrieves a cached embedding for text.
@@ -117,6 +121,68 @@ func (c *CachedEmbedder) Close() error {
 	return c.db.Close()
 }
 
+// PruneCache removes the embeddings cached at cachePath for anything but
+// keep, the texts still embedded, under any of models, then vacuums the
+// cache to return the space. Embeddings of search queries go too; they are
+// cached again the next time they are searched. It returns how many
+// embeddings it removed.
+func PruneCache(ctx context.Context, cachePath string, models, keep []string) (int, error) {
+	db, err := sql.Open("sqlite3", cachePath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return 0, fmt.Errorf("opening cache db: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	kept := make(map[string]bool, len(keep)*len(models))
+	for _, model := range models {
+		for _, text := range keep {
+			kept[cacheKey(model, text)] = true
+		}
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT content_hash FROM embedding_cache`)
+	if err != nil {
+		return 0, fmt.Errorf("listing cached embeddings: %w", err)
+	}
+	var stale []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("listing cached embeddings: %w", err)
+		}
+		if !kept[hash] {
+			stale = append(stale, hash)
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("listing cached embeddings: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("pruning cache: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, hash := range stale {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM embedding_cache WHERE content_hash = ?`, hash); err != nil {
+			return 0, fmt.Errorf("pruning cache: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("pruning cache: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `VACUUM`); err != nil {
+		return len(stale), fmt.Errorf("vacuuming cache: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return len(stale), fmt.Errorf("truncating cache log: %w", err)
+	}
+	return len(stale), nil
+}
+
 func contentHash(text string) string {
 	h := sha256.Sum256([]byte(text))
 	return fmt.Sprintf("%x", h[:16])
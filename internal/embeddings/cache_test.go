@@ -234,3 +234,44 @@ func TestEncodeDecodeEmbedding(t *testing.T) {
 		}
 	}
 }
+
+func TestPruneCache(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache.db")
+	ctx := context.Background()
+
+	for _, model := range []string{"model-a", "model-b", "model-gone"} {
+		cache, err := NewCachedEmbedder(&mockEmbedder{dim: 4}, cachePath, model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cache.EmbedBatch(ctx, []string{"kept chunk", "deleted chunk"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := PruneCache(ctx, cachePath, []string{"model-a", "model-b"}, []string{"kept chunk"})
+	if err != nil {
+		t.Fatalf("PruneCache() error = %v", err)
+	}
+	if removed != 4 {
+		t.Errorf("PruneCache() removed %d embeddings, want 4", removed)
+	}
+
+	for model, wantCalls := range map[string]int{"model-a": 0, "model-b": 0, "model-gone": 1} {
+		mock := &mockEmbedder{dim: 4}
+		cache, err := NewCachedEmbedder(mock, cachePath, model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cache.Embed(ctx, "kept chunk"); err != nil {
+			t.Fatal(err)
+		}
+		if mock.calls != wantCalls {
+			t.Errorf("%s: %d calls to embed a kept chunk, want %d", model, mock.calls, wantCalls)
+		}
+		_ = cache.Close()
+	}
+}
@@ -0,0 +1,106 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/J-1000/mindcli/pkg/lang"
+)
+
+type languageKey struct{}
+
+// WithLanguage returns a context telling a LanguageRouter which language
+// the texts embedded with it are in, e.g. the detected language of the
+// document they were chunked from. An empty language, one that couldn't be
+// detected, selects the fallback.
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// LanguageRouter embeds text with the embedder configured for its language,
+// and with a fallback for other languages. The language comes from the
+// context (see WithLanguage), or is detected from the text itself, which is
+// how search queries are routed. All embedders must produce vectors of the
+// same dimension, since they share one vector store, but vectors are only
+// comparable between texts embedded by the same model (see SameModel).
+type LanguageRouter struct {
+	fallback   Embedder
+	byLanguage map[string]Embedder
+}
+
+// NewLanguageRouter creates a router over embedders keyed by ISO 639-1
+// language code.
+func NewLanguageRouter(fallback Embedder, byLanguage map[string]Embedder) *LanguageRouter {
+	return &LanguageRouter{fallback: fallback, byLanguage: byLanguage}
+}
+
+// Language returns the language text is embedded as: the one in ctx, or
+// else the one detected from text.
+func (r *LanguageRouter) Language(ctx context.Context, text string) string {
+	if language, ok := ctx.Value(languageKey{}).(string); ok {
+		return language
+	}
+	return lang.Detect(text)
+}
+
+// SameModel reports whether texts in languages a and b are embedded by the
+// same model, so that their vectors can be compared.
+func (r *LanguageRouter) SameModel(a, b string) bool {
+	return r.embedderForLanguage(a) == r.embedderForLanguage(b)
+}
+
+// embedderFor returns the embedder for text's language.
+func (r *LanguageRouter) embedderFor(ctx context.Context, text string) Embedder {
+	return r.embedderForLanguage(r.Language(ctx, text))
+}
+
+func (r *LanguageRouter) embedderForLanguage(language string) Embedder {
+	if e, ok := r.byLanguage[language]; ok {
+		return e
+	}
+	return r.fallback
+}
+
+// Embed generates an embedding with the embedder for text's language.
+func (r *LanguageRouter) Embed(ctx context.Context, text string) ([]float32, error) {
+	return r.embedderFor(ctx, text).Embed(ctx, text)
+}
+
+// EmbedBatch groups texts by embedder, so each is called once, and returns
+// the embeddings in the order of texts.
+func (r *LanguageRouter) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	var order []Embedder
+	groups := make(map[Embedder][]int)
+	for i, text := range texts {
+		e := r.embedderFor(ctx, text)
+		if _, seen := groups[e]; !seen {
+			order = append(order, e)
+		}
+		groups[e] = append(groups[e], i)
+	}
+
+	results := make([][]float32, len(texts))
+	for _, e := range order {
+		indices := groups[e]
+		batch := make([]string, len(indices))
+		for j, i := range indices {
+			batch[j] = texts[i]
+		}
+		embeds, err := e.EmbedBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(embeds) != len(batch) {
+			return nil, fmt.Errorf("got %d embeddings for %d texts", len(embeds), len(batch))
+		}
+		for j, i := range indices {
+			results[i] = embeds[j]
+		}
+	}
+	return results, nil
+}
+
+// Dimensions returns the fallback embedder's dimension.
+func (r *LanguageRouter) Dimensions() int {
+	return r.fallback.Dimensions()
+}
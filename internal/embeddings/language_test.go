@@ -0,0 +1,74 @@
+package embeddings
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLanguageRouter(t *testing.T) {
+	fallback := &mockEmbedder{dim: 2}
+	german := &mockEmbedder{dim: 3}
+	router := NewLanguageRouter(fallback, map[string]Embedder{"de": german})
+	ctx := context.Background()
+
+	// Queries are routed by the language detected in them.
+	emb, err := router.Embed(ctx, "Wo ist die Liste mit den Zielen für das nächste Jahr und der Plan?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emb) != 3 {
+		t.Errorf("German text embedded with dim %d, want the German model's 3", len(emb))
+	}
+	if emb, _ := router.Embed(ctx, "Where is the list of goals for the next year and the plan?"); len(emb) != 2 {
+		t.Errorf("English text embedded with dim %d, want the fallback's 2", len(emb))
+	}
+
+	// A language in the context wins over detection, e.g. for the short
+	// chunks of a German document.
+	if emb, _ := router.Embed(WithLanguage(ctx, "de"), "Kubernetes"); len(emb) != 3 {
+		t.Errorf("text with language de embedded with dim %d, want 3", len(emb))
+	}
+
+	texts := []string{
+		"The plan is to ship the release and then take a week off.",
+		"Der Plan ist, das Release auszuliefern und dann eine Woche frei zu nehmen.",
+		"This is the second English note, with the notes from the review.",
+	}
+	embeds, err := router.EmbedBatch(ctx, texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(embeds[0]) != 2 || len(embeds[1]) != 3 || len(embeds[2]) != 2 {
+		t.Errorf("EmbedBatch() dims = %d, %d, %d; want 2, 3, 2", len(embeds[0]), len(embeds[1]), len(embeds[2]))
+	}
+	if fallback.batchCalls != 1 || german.batchCalls != 1 {
+		t.Errorf("batch calls = %d fallback, %d German; want one each", fallback.batchCalls, german.batchCalls)
+	}
+	if router.Dimensions() != 2 {
+		t.Errorf("Dimensions() = %d, want the fallback's 2", router.Dimensions())
+	}
+
+	// An empty language in the context is one that couldn't be detected,
+	// and languages without a model share the fallback's.
+	if got := router.Language(WithLanguage(ctx, ""), "Der Plan ist fertig und das Release ist draußen."); got != "" {
+		t.Errorf("Language() with an empty language in the context = %q, want it kept", got)
+	}
+	if !router.SameModel("", "fr") || router.SameModel("en", "de") || !router.SameModel("de", "de") {
+		t.Error("SameModel() should only match languages embedded by one model")
+	}
+}
+
+// shortEmbedder returns one embedding fewer than it is asked for.
+type shortEmbedder struct{ mockEmbedder }
+
+func (s *shortEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	embeds, err := s.mockEmbedder.EmbedBatch(ctx, texts)
+	return embeds[:len(embeds)-1], err
+}
+
+func TestLanguageRouter_ShortBatch(t *testing.T) {
+	router := NewLanguageRouter(&shortEmbedder{mockEmbedder{dim: 2}}, nil)
+	if _, err := router.EmbedBatch(context.Background(), []string{"one", "two"}); err == nil {
+		t.Error("EmbedBatch() with a backend returning too few embeddings succeeded")
+	}
+}
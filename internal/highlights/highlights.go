@@ -0,0 +1,185 @@
+// Package highlights converts the highlights exported from e-readers
+// (Kindle's My Clippings.txt) and Readwise into markdown notes, one per
+// book, so that book notes can be searched like any other note.
+package highlights
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats lists the supported export formats.
+var Formats = []string{"kindle", "readwise"}
+
+// Highlight is a highlighted passage and the note made on it, if any.
+type Highlight struct {
+	Text     string
+	Note     string
+	Location string // e.g. "180-182"; "" when unknown
+	Page     string
+	Added    time.Time
+}
+
+// Book is a book and its highlights in reading order.
+type Book struct {
+	Service    string // "Kindle" or "Readwise"
+	Title      string
+	Author     string
+	Tags       []string
+	Highlights []Highlight
+}
+
+// Parse reads the export at path in the given format: the My Clippings.txt
+// of a Kindle, or a Readwise CSV export.
+func Parse(format, path string) ([]Book, error) {
+	switch strings.ToLower(format) {
+	case "kindle":
+		return parseKindleFile(path)
+	case "readwise":
+		return parseReadwiseFile(path)
+	default:
+		return nil, fmt.Errorf("unknown highlights format %q (want one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// LastAdded returns when the latest highlight was made, or the zero time
+// when no dates were exported.
+func (b Book) LastAdded() time.Time {
+	var last time.Time
+	for _, h := range b.Highlights {
+		if h.Added.After(last) {
+			last = h.Added
+		}
+	}
+	return last
+}
+
+// Markdown renders the book as a markdown note whose frontmatter holds the
+// author, service and highlight count. Each highlight gets a heading naming
+// its location, so it can be found and cited on its own.
+func (b Book) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "title: %q\n", b.Title)
+	if b.Author != "" {
+		fmt.Fprintf(&sb, "author: %q\n", b.Author)
+	}
+	fmt.Fprintf(&sb, "service: %s\n", strings.ToLower(b.Service))
+	if last := b.LastAdded(); !last.IsZero() {
+		fmt.Fprintf(&sb, "date: %s\n", last.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&sb, "highlights: %d\n", len(b.Highlights))
+	tags := []string{"highlights", strings.ToLower(b.Service)}
+	for _, t := range b.Tags {
+		if t = tagName(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	fmt.Fprintf(&sb, "tags: [%s]\n", strings.Join(tags, ", "))
+	sb.WriteString("---\n\n")
+	fmt.Fprintf(&sb, "# %s\n", b.Title)
+	if b.Author != "" {
+		fmt.Fprintf(&sb, "\nby %s\n", b.Author)
+	}
+
+	for i, h := range b.Highlights {
+		fmt.Fprintf(&sb, "\n## %s\n\n", h.heading(i))
+		if text := strings.TrimSpace(h.Text); text != "" {
+			// Quote every line so a passage can't start a heading.
+			fmt.Fprintf(&sb, "> %s\n", strings.ReplaceAll(text, "\n", "\n> "))
+		}
+		if note := strings.TrimSpace(h.Note); note != "" {
+			if h.Text != "" {
+				sb.WriteString("\n")
+			}
+			fmt.Fprintf(&sb, "Note: %s\n", strings.ReplaceAll(note, "\n", "\n  "))
+		}
+		if !h.Added.IsZero() {
+			fmt.Fprintf(&sb, "\nAdded %s\n", h.Added.Format("2 January 2006"))
+		}
+	}
+	return sb.String()
+}
+
+// heading names the highlight by its location and page, or by its position
+// in the book when neither is known.
+func (h Highlight) heading(i int) string {
+	var parts []string
+	if h.Location != "" {
+		parts = append(parts, "Location "+h.Location)
+	}
+	if h.Page != "" {
+		parts = append(parts, "Page "+h.Page)
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("Highlight %d", i+1)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FileName returns a file name for the note derived from the title, e.g.
+// "thinking-fast-and-slow.md". Importing the same export again produces the
+// same names.
+func (b Book) FileName() string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(b.Title) {
+		if sb.Len() >= 60 {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+			dash = false
+		case !dash && sb.Len() > 0:
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	name := strings.Trim(sb.String(), "-")
+	if name == "" {
+		name = "book"
+	}
+	return name + ".md"
+}
+
+// tagName turns an exported tag into a markdown tag: spaces become dashes
+// and characters tags can't hold are dropped.
+func tagName(tag string) string {
+	var sb strings.Builder
+	for _, r := range strings.TrimSpace(tag) {
+		switch {
+		case r == ' ' || r == '-' || r == '_':
+			sb.WriteRune('-')
+		case r == '/' || r == '#' || r == ',' || r == '[' || r == ']':
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return strings.Trim(sb.String(), "-")
+}
+
+// sortHighlights puts each book's highlights in reading order, by where
+// they start. Those without a location come first, in export order.
+func sortHighlights(books []Book) {
+	for _, b := range books {
+		sort.SliceStable(b.Highlights, func(i, j int) bool {
+			return locationStart(b.Highlights[i]) < locationStart(b.Highlights[j])
+		})
+	}
+}
+
+// locationStart returns where a highlight starts: its location, or its page
+// when it has none, or -1 when it has neither.
+func locationStart(h Highlight) int {
+	for _, loc := range []string{h.Location, h.Page} {
+		start, _, _ := strings.Cut(loc, "-")
+		if n, err := strconv.Atoi(strings.TrimSpace(start)); err == nil {
+			return n
+		}
+	}
+	return -1
+}
@@ -0,0 +1,46 @@
+package highlights
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBookMarkdown(t *testing.T) {
+	b := Book{
+		Service: "Kindle",
+		Title:   "Deep Work",
+		Author:  "Cal Newport",
+		Tags:    []string{"productivity"},
+		Highlights: []Highlight{
+			{Text: "Clarity about what matters\n# provides clarity", Location: "120-121", Page: "9", Note: "Agree",
+				Added: time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+			{Text: "Depth over breadth", Added: time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		},
+	}
+	md := b.Markdown()
+	for _, want := range []string{
+		`title: "Deep Work"`,
+		`author: "Cal Newport"`,
+		"service: kindle",
+		"date: 2024-01-05T09:00:00Z",
+		"highlights: 2",
+		"tags: [highlights, kindle, productivity]",
+		"# Deep Work\n\nby Cal Newport\n",
+		"## Location 120-121, Page 9\n\n> Clarity about what matters\n> # provides clarity\n\nNote: Agree\n\nAdded 2 January 2024\n",
+		"## Highlight 2\n\n> Depth over breadth\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestBookFileName(t *testing.T) {
+	if got := (Book{Title: "Thinking, Fast and Slow"}).FileName(); got != "thinking-fast-and-slow.md" {
+		t.Errorf("FileName() = %q", got)
+	}
+	if got := (Book{Title: "思考"}).FileName(); got != "book.md" {
+		t.Errorf("FileName() = %q, want a fallback", got)
+	}
+}
@@ -0,0 +1,170 @@
+package highlights
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// kindleSeparator ends each clipping in My Clippings.txt.
+const kindleSeparator = "=========="
+
+var (
+	// kindleMetaRegex matches a clipping's second line, such as
+	// "- Your Highlight on page 12 | Location 180-182 | Added on ...".
+	kindleMetaRegex = regexp.MustCompile(`(?i)^-\s*(?:your\s+)?(highlight|note|bookmark|clip\w*)\b(.*?)(?:\|\s*added on\s+(.*))?$`)
+
+	// kindleLocationRegex and kindlePageRegex find the location and page in
+	// a clipping's second line; older Kindles write "Loc. 180-82".
+	kindleLocationRegex = regexp.MustCompile(`(?i)\b(?:location|loc\.)\s*([\d]+(?:-[\d]+)?)`)
+	kindlePageRegex     = regexp.MustCompile(`(?i)\bpage\s+([\divxlc]+(?:-[\divxlc]+)?)`)
+
+	// kindleAuthorRegex matches the author in parentheses that ends a
+	// clipping's title line.
+	kindleAuthorRegex = regexp.MustCompile(`^(.*\S)\s*\(([^()]*)\)$`)
+)
+
+// kindleDateLayouts are the "Added on" date formats of English Kindles, US
+// and elsewhere. Dates in other languages are left out.
+var kindleDateLayouts = []string{
+	"Monday, January 2, 2006 3:04:05 PM",
+	"Monday, 2 January 2006 15:04:05",
+	"Monday, January 2, 2006 15:04:05",
+	"Monday, 2 January 06 15:04:05",
+}
+
+// parseKindleFile reads a Kindle's My Clippings.txt.
+func parseKindleFile(path string) ([]Book, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	return parseKindle(string(data)), nil
+}
+
+// parseKindle parses My Clippings.txt: clippings separated by a line of
+// equals signs, each a "Title (Author)" line, a line saying what the
+// clipping is and where, a blank line and the text. Notes are attached to
+// the highlight they were made on, bookmarks are skipped, and a highlight
+// that was later extended replaces the one it grew from.
+func parseKindle(data string) []Book {
+	data = strings.TrimPrefix(data, "\ufeff")
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+
+	var books []Book
+	index := make(map[string]int)
+	for _, clip := range strings.Split(data, kindleSeparator) {
+		lines := strings.Split(strings.Trim(clip, "\n"), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		titleLine := strings.TrimSpace(strings.TrimPrefix(lines[0], "\ufeff"))
+		m := kindleMetaRegex.FindStringSubmatch(strings.TrimSpace(lines[1]))
+		if titleLine == "" || m == nil {
+			continue
+		}
+		kind := strings.ToLower(m[1])
+		if kind == "bookmark" {
+			continue
+		}
+		text := strings.TrimSpace(strings.Join(lines[2:], "\n"))
+		if text == "" {
+			continue
+		}
+
+		h := Highlight{Added: parseKindleDate(m[3])}
+		if loc := kindleLocationRegex.FindStringSubmatch(m[2]); loc != nil {
+			h.Location = loc[1]
+		}
+		if page := kindlePageRegex.FindStringSubmatch(m[2]); page != nil {
+			h.Page = page[1]
+		}
+
+		i, ok := index[titleLine]
+		if !ok {
+			title, author := titleLine, ""
+			if am := kindleAuthorRegex.FindStringSubmatch(titleLine); am != nil {
+				title, author = am[1], strings.TrimSpace(am[2])
+			}
+			i = len(books)
+			index[titleLine] = i
+			books = append(books, Book{Service: "Kindle", Title: title, Author: author})
+		}
+		book := &books[i]
+
+		if kind == "note" {
+			h.Note = text
+			if j := noteTarget(book.Highlights, h); j >= 0 {
+				book.Highlights[j].Note = text
+				continue
+			}
+			book.Highlights = append(book.Highlights, h)
+			continue
+		}
+		h.Text = text
+		if j := extendedHighlight(book.Highlights, h); j >= 0 {
+			h.Note = book.Highlights[j].Note
+			book.Highlights[j] = h
+			continue
+		}
+		book.Highlights = append(book.Highlights, h)
+	}
+	sortHighlights(books)
+	return books
+}
+
+// parseKindleDate parses an "Added on" date, returning the zero time for
+// one it doesn't understand.
+func parseKindleDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range kindleDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// noteTarget returns the index of the highlight a note was made on, the
+// last one ending where the note is, or -1 when there is none.
+func noteTarget(highlights []Highlight, note Highlight) int {
+	if note.Location == "" {
+		return -1
+	}
+	for j := len(highlights) - 1; j >= 0; j-- {
+		h := highlights[j]
+		if h.Text == "" || h.Location == "" {
+			continue
+		}
+		start, end, ranged := strings.Cut(h.Location, "-")
+		if !ranged {
+			end = start
+		}
+		if note.Location == start || note.Location == end {
+			return j
+		}
+	}
+	return -1
+}
+
+// extendedHighlight returns the index of a highlight that h redoes: one
+// starting at the same location whose text h contains, or the other way
+// round. It returns -1 when there is none.
+func extendedHighlight(highlights []Highlight, h Highlight) int {
+	if h.Location == "" {
+		return -1
+	}
+	start, _, _ := strings.Cut(h.Location, "-")
+	for j, other := range highlights {
+		otherStart, _, _ := strings.Cut(other.Location, "-")
+		if other.Text == "" || otherStart != start {
+			continue
+		}
+		if strings.Contains(h.Text, other.Text) || strings.Contains(other.Text, h.Text) {
+			return j
+		}
+	}
+	return -1
+}
@@ -0,0 +1,52 @@
+package highlights
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseKindle(t *testing.T) {
+	clippings := "\ufeffThinking, Fast and Slow (Daniel Kahneman)\r\n" +
+		"- Your Highlight on page 20 | Location 300-302 | Added on Monday, 1 January 2024 10:00:00\r\n\r\n" +
+		"Nothing in life is as important\r\n==========\r\n" +
+		"Thinking, Fast and Slow (Daniel Kahneman)\r\n" +
+		"- Your Highlight on Location 120-121 | Added on Monday, January 1, 2024 9:00:00 AM\r\n\r\n" +
+		"System 1 operates automatically\r\n==========\r\n" +
+		"Thinking, Fast and Slow (Daniel Kahneman)\r\n" +
+		"- Your Note on Location 121 | Added on Monday, January 1, 2024 9:01:00 AM\r\n\r\n" +
+		"Fast thinking\r\n==========\r\n" +
+		"Thinking, Fast and Slow (Daniel Kahneman)\r\n" +
+		"- Your Highlight on page 20 | Location 300-305 | Added on Monday, 1 January 2024 10:05:00\r\n\r\n" +
+		"Nothing in life is as important as you think it is\r\n==========\r\n" +
+		"Thinking, Fast and Slow (Daniel Kahneman)\r\n" +
+		"- Your Bookmark on Location 400 | Added on Monday, 1 January 2024 11:00:00\r\n\r\n\r\n==========\r\n" +
+		"Notes (Dover Edition) (Anon)\r\n" +
+		"- Highlight Loc. 5-6 | Added on Tuesday, 2 January 2024 08:00:00\r\n\r\n" +
+		"An older Kindle\r\n==========\r\n"
+
+	books := parseKindle(clippings)
+	if len(books) != 2 {
+		t.Fatalf("got %d books, want 2: %+v", len(books), books)
+	}
+	book := books[0]
+	if book.Title != "Thinking, Fast and Slow" || book.Author != "Daniel Kahneman" {
+		t.Errorf("book = %q by %q", book.Title, book.Author)
+	}
+	if len(book.Highlights) != 2 {
+		t.Fatalf("got %d highlights, want 2 (bookmark skipped, extended highlight merged): %+v", len(book.Highlights), book.Highlights)
+	}
+	first, second := book.Highlights[0], book.Highlights[1]
+	if first.Location != "120-121" || first.Note != "Fast thinking" {
+		t.Errorf("first highlight = %+v, want location 120-121 with its note", first)
+	}
+	if !first.Added.Equal(time.Date(2024, 1, 1, 9, 0, 0, 0, time.Local)) {
+		t.Errorf("first added = %v", first.Added)
+	}
+	if second.Text != "Nothing in life is as important as you think it is" || second.Location != "300-305" || second.Page != "20" {
+		t.Errorf("second highlight = %+v, want the extended one", second)
+	}
+
+	if books[1].Title != "Notes (Dover Edition)" || books[1].Author != "Anon" || books[1].Highlights[0].Location != "5-6" {
+		t.Errorf("older clipping = %+v", books[1])
+	}
+}
@@ -0,0 +1,103 @@
+package highlights
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// readwiseDateLayouts are the formats of the "Highlighted at" column.
+var readwiseDateLayouts = []string{
+	"2006-01-02 15:04:05-07:00",
+	"2006-01-02 15:04:05.999999-07:00",
+	time.RFC3339,
+}
+
+// parseReadwiseFile reads a Readwise CSV export, whose columns include
+// Highlight, Book Title, Book Author, Note, Location Type, Location,
+// Highlighted at and Document tags. Highlights are grouped into books by
+// title and author.
+func parseReadwiseFile(path string) ([]Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	cr := csv.NewReader(f)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	column := make(map[string]int)
+	for i, name := range records[0] {
+		column[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := column["highlight"]; !ok {
+		return nil, fmt.Errorf("not a Readwise export: no Highlight column")
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := column[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var books []Book
+	index := make(map[string]int)
+	for _, rec := range records[1:] {
+		h := Highlight{
+			Text:  field(rec, "highlight"),
+			Note:  field(rec, "note"),
+			Added: parseReadwiseDate(field(rec, "highlighted at")),
+		}
+		if h.Text == "" && h.Note == "" {
+			continue
+		}
+		switch strings.ToLower(field(rec, "location type")) {
+		case "location":
+			h.Location = field(rec, "location")
+		case "page":
+			h.Page = field(rec, "location")
+		}
+
+		title, author := field(rec, "book title"), field(rec, "book author")
+		if title == "" {
+			title = "Untitled"
+		}
+		key := title + "\x00" + author
+		i, ok := index[key]
+		if !ok {
+			i = len(books)
+			index[key] = i
+			books = append(books, Book{Service: "Readwise", Title: title, Author: author})
+		}
+		book := &books[i]
+		for _, t := range strings.Split(field(rec, "document tags"), ",") {
+			if t = strings.TrimSpace(t); t != "" && !slices.Contains(book.Tags, t) {
+				book.Tags = append(book.Tags, t)
+			}
+		}
+		book.Highlights = append(book.Highlights, h)
+	}
+	sortHighlights(books)
+	return books, nil
+}
+
+// parseReadwiseDate parses a "Highlighted at" date, returning the zero time
+// for one it doesn't understand.
+func parseReadwiseDate(s string) time.Time {
+	for _, layout := range readwiseDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,49 @@
+package highlights
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReadwise(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "readwise-data.csv")
+	export := "\ufeffHighlight,Book Title,Book Author,Amazon Book ID,Note,Color,Tags,Location Type,Location,Highlighted at,Document tags\n" +
+		`"Make it work, then make it fast",Programming Pearls,Jon Bentley,,,yellow,,location,900,2024-03-02 08:00:00+00:00,"cs, classics"` + "\n" +
+		"Back of the envelope,Programming Pearls,Jon Bentley,,Try this,,,page,12,2024-03-01 08:00:00+00:00,cs\n" +
+		"Read slowly,Essays,,,,,,order,3,,\n"
+	if err := os.WriteFile(path, []byte(export), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	books, err := parseReadwiseFile(path)
+	if err != nil {
+		t.Fatalf("parseReadwiseFile() error = %v", err)
+	}
+	if len(books) != 2 {
+		t.Fatalf("got %d books, want 2", len(books))
+	}
+	pearls := books[0]
+	if pearls.Author != "Jon Bentley" || strings.Join(pearls.Tags, ",") != "cs,classics" || len(pearls.Highlights) != 2 {
+		t.Fatalf("book = %+v", pearls)
+	}
+	// Page 12 comes before location 900.
+	if h := pearls.Highlights[0]; h.Page != "12" || h.Note != "Try this" {
+		t.Errorf("first highlight = %+v", h)
+	}
+	if h := pearls.Highlights[1]; h.Location != "900" || !h.Added.Equal(time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("second highlight = %+v", h)
+	}
+	if books[1].Highlights[0].Location != "" || books[1].Highlights[0].Page != "" {
+		t.Errorf("ordinal positions aren't locations: %+v", books[1].Highlights[0])
+	}
+
+	if err := os.WriteFile(path, []byte("Title,URL\nx,y\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseReadwiseFile(path); err == nil {
+		t.Error("a CSV without highlights should be an error")
+	}
+}
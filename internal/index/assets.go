@@ -0,0 +1,86 @@
+package index
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+var (
+	// assetLinkRe matches Markdown links and images, [text](target) and
+	// ![alt](target).
+	assetLinkRe = regexp.MustCompile(`!?\[[^\]]*\]\(([^)]+)\)`)
+	// assetEmbedRe matches Obsidian embeds, ![[image.png]] and
+	// ![[diagram.png|300]].
+	assetEmbedRe = regexp.MustCompile(`!\[\[([^\]|#]+)(?:[|#][^\]]*)?\]\]`)
+)
+
+// noteExtensions are linked files that are notes rather than assets.
+var noteExtensions = map[string]bool{".md": true, ".markdown": true}
+
+// ExtractAssets returns the local files a Markdown note embeds or links to,
+// such as images and PDFs, resolved against the note's directory. Links to
+// other notes, to URLs and to files that don't exist are left out.
+func ExtractAssets(doc *storage.Document) []string {
+	dir := filepath.Dir(doc.FilePath())
+	var assets []string
+	seen := make(map[string]bool)
+	add := func(target string) {
+		path := resolveAsset(dir, target)
+		if path == "" || seen[path] || noteExtensions[strings.ToLower(filepath.Ext(path))] {
+			return
+		}
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			return
+		}
+		seen[path] = true
+		assets = append(assets, path)
+	}
+	for _, m := range assetLinkRe.FindAllStringSubmatch(doc.Content, -1) {
+		add(linkDestination(m[1]))
+	}
+	for _, m := range assetEmbedRe.FindAllStringSubmatch(doc.Content, -1) {
+		add(strings.TrimSpace(m[1]))
+	}
+	return assets
+}
+
+// linkDestination returns the destination of a Markdown link: the part
+// before an optional title, without angle brackets.
+func linkDestination(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "<") {
+		if end := strings.Index(s, ">"); end > 0 {
+			return s[1:end]
+		}
+	}
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// resolveAsset returns the file target names relative to dir, or "" for
+// URLs and in-page anchors.
+func resolveAsset(dir, target string) string {
+	if target == "" || strings.HasPrefix(target, "#") || strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+		return ""
+	}
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	if target == "" {
+		return ""
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	return filepath.Clean(target)
+}
@@ -0,0 +1,81 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestExtractAssets(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"img/diagram.png", "img/my photo.jpg", "spec.pdf", "other.md"} {
+		path := filepath.Join(dir, name)
+		mustIndexerTestSucceed(t, os.MkdirAll(filepath.Dir(path), 0755))
+		mustIndexerTestSucceed(t, os.WriteFile(path, []byte("x"), 0644))
+	}
+
+	doc := &storage.Document{
+		Path: filepath.Join(dir, "note.md"),
+		Content: "![Diagram](img/diagram.png \"The flow\")\n" +
+			"![Photo](<img/my photo.jpg>) and again ![](img/my%20photo.jpg)\n" +
+			"See the [spec](spec.pdf#page=2), [the other note](other.md) and [the site](https://example.com/a.png).\n" +
+			"![[spec.pdf]] ![[missing.png|200]] [anchor](#top)\n",
+	}
+	want := []string{
+		filepath.Join(dir, "img/diagram.png"),
+		filepath.Join(dir, "img/my photo.jpg"),
+		filepath.Join(dir, "spec.pdf"),
+	}
+	if got := ExtractAssets(doc); !slices.Equal(got, want) {
+		t.Errorf("ExtractAssets() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestIndexer_StoresAssets(t *testing.T) {
+	tmpDir := t.TempDir()
+	notesDir := filepath.Join(tmpDir, "notes")
+	mustIndexerTestSucceed(t, os.MkdirAll(notesDir, 0755))
+	image := filepath.Join(notesDir, "chart.png")
+	mustIndexerTestSucceed(t, os.WriteFile(image, []byte("png"), 0644))
+	path := filepath.Join(notesDir, "report.md")
+	mustIndexerTestSucceed(t, os.WriteFile(path, []byte("# Report\n\n![Chart](chart.png)\n"), 0644))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	cfg := &config.Config{
+		Sources: config.SourcesConfig{
+			Markdown: config.MarkdownSourceConfig{Enabled: true, Paths: []string{notesDir}, Extensions: []string{".md"}},
+		},
+		Indexing: config.IndexingConfig{Workers: 1},
+	}
+	indexer := NewIndexer(db, searchIdx, nil, nil, cfg)
+	ctx := context.Background()
+	mustIndexerTestSucceed(t, indexer.IndexFile(ctx, path))
+
+	doc, err := db.GetDocumentByPath(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assets, err := db.GetRelations(ctx, doc.ID, storage.RelationAsset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 1 || assets[0] != image {
+		t.Errorf("assets = %v, want [%s]", assets, image)
+	}
+}
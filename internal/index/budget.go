@@ -0,0 +1,67 @@
+package index
+
+import (
+	"context"
+
+	"github.com/J-1000/mindcli/internal/index/sources"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// budget caps the documents a source keeps indexed and their bytes of
+// text; 0 means no limit.
+type budget struct {
+	maxDocuments int
+	maxBytes     int64
+}
+
+// Trim removes the oldest documents of each source over its budget until it
+// fits, skipping those the user kept by tagging them by hand, collecting or
+// aliasing them. Only high-churn sources such as the clipboard have budgets:
+// notes, PDFs and other files are never trimmed. It reports what it removed
+// from each source that was over budget.
+func (idx *Indexer) Trim(ctx context.Context) ([]sources.TrimReport, error) {
+	var report []sources.TrimReport
+	for _, source := range storage.AllSources {
+		b, ok := idx.budgets[source]
+		if !ok || isFileBackedSource(source) || (b.maxDocuments == 0 && b.maxBytes == 0) {
+			continue
+		}
+		docs, err := idx.db.ListDocumentFootprints(ctx, source)
+		if err != nil {
+			return report, err
+		}
+		count, size := len(docs), int64(0)
+		for _, d := range docs {
+			size += d.Size
+		}
+		over := func() bool {
+			return (b.maxDocuments > 0 && count > b.maxDocuments) || (b.maxBytes > 0 && size > b.maxBytes)
+		}
+
+		t := sources.TrimReport{Source: source}
+		// Documents are listed oldest first.
+		for _, d := range docs {
+			if !over() {
+				break
+			}
+			if d.Kept {
+				continue
+			}
+			if err := idx.RemoveFile(ctx, d.Path); err != nil {
+				if idx.progress != nil {
+					idx.progress.OnError(string(source), d.Path, err)
+				}
+				continue
+			}
+			count--
+			size -= d.Size
+			t.Removed++
+			t.Bytes += d.Size
+			t.Newest = d.Date
+		}
+		if t.Removed > 0 {
+			report = append(report, t)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,80 @@
+package index
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestIndexer_Trim(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC) }
+	add := func(id string, source storage.Source, content string, modified time.Time) {
+		t.Helper()
+		doc := &storage.Document{ID: id, Source: source, Path: string(source) + ":" + id, Title: id, Content: content,
+			ContentHash: id, IndexedAt: modified, ModifiedAt: modified}
+		mustIndexerTestSucceed(t, db.InsertDocument(ctx, doc))
+		mustIndexerTestSucceed(t, searchIdx.Index(ctx, doc))
+	}
+	add("c1", storage.SourceClipboard, "tagged clip", day(1))
+	add("c2", storage.SourceClipboard, "old clip", day(2))
+	add("c3", storage.SourceClipboard, "older clip", day(3))
+	add("c4", storage.SourceClipboard, "newest clip", day(4))
+	add("n1", storage.SourceMarkdown, strings.Repeat("note ", 100), day(1))
+	mustIndexerTestSucceed(t, db.AddTag(ctx, "c1", "keep"))
+
+	indexer := NewIndexer(db, searchIdx, nil, nil, &config.Config{
+		Sources:  config.SourcesConfig{Clipboard: config.ClipboardSourceConfig{MaxDocuments: 2}},
+		Indexing: config.IndexingConfig{Workers: 1},
+	})
+	// Notes never have a budget, even if one were set.
+	indexer.budgets[storage.SourceMarkdown] = budget{maxDocuments: 1, maxBytes: 1}
+
+	report, err := indexer.Trim(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 1 {
+		t.Fatalf("Trim() = %+v, want one source trimmed", report)
+	}
+	if r := report[0]; r.Source != storage.SourceClipboard || r.Removed != 2 || r.Bytes != int64(len("old clip")+len("older clip")) || !r.Newest.Equal(day(3)) {
+		t.Errorf("Trim() = %+v, want c2 and c3 removed", r)
+	}
+	for id, want := range map[string]bool{"c1": true, "c2": false, "c3": false, "c4": true, "n1": true} {
+		doc, _ := db.GetDocument(ctx, id)
+		if got := doc != nil; got != want {
+			t.Errorf("%s kept = %v, want %v", id, got, want)
+		}
+	}
+	if results, _ := searchIdx.Search(ctx, "older", 10); len(results) != 0 {
+		t.Errorf("trimmed clip still searchable: %v", results)
+	}
+
+	// A size budget trims the oldest until the rest fit, skipping the
+	// tagged clip.
+	indexer.budgets[storage.SourceClipboard] = budget{maxBytes: int64(len("tagged clip"))}
+	if report, err = indexer.Trim(ctx); err != nil || len(report) != 1 || report[0].Removed != 1 {
+		t.Errorf("Trim() = %+v, %v; want the newest clip trimmed", report, err)
+	}
+	if doc, _ := db.GetDocument(ctx, "c1"); doc == nil {
+		t.Error("tagged clip trimmed")
+	}
+}
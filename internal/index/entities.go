@@ -0,0 +1,167 @@
+package index
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// maxEntities caps the entities kept per document, so a long list of names
+// doesn't swamp the entity browser.
+const maxEntities = 50
+
+// Metadata fields that name entities outright.
+var (
+	personFields  = []string{"fm_author", "fm_authors", "fm_people", "fm_participants", "git_author"}
+	orgFields     = []string{"fm_organization", "fm_company", "fm_org"}
+	projectFields = []string{"fm_project", "fm_projects"}
+	addressFields = []string{"from", "to"}
+)
+
+var (
+	// orgRe matches capitalized names ending in a word that marks an
+	// organization, such as "Acme Inc" or "Open Source Foundation".
+	orgRe = regexp.MustCompile(`\b((?:[A-Z][\w&.-]*\s+){0,3}(?:Inc|Corp|Corporation|GmbH|Ltd|LLC|AG|Foundation|University|Institute|Labs|Group|Company|Association))\b\.?`)
+	// projectRe matches "project Foo" and "Project Foo Bar".
+	projectRe = regexp.MustCompile(`\b[Pp]roject\s+([A-Z][\w-]*(?:\s+[A-Z][\w-]*)?)`)
+	// honorificRe matches names after a title, such as "Dr. Ada Lovelace".
+	honorificRe = regexp.MustCompile(`\b(?:Dr|Mr|Mrs|Ms|Prof)\.?\s+([A-Z][a-z]+(?:\s+[A-Z][a-z]+)?)`)
+	// nameRe matches two or three capitalized words in a row, the shape of
+	// most personal names.
+	nameRe = regexp.MustCompile(`\b[A-Z][a-z]+(?:[ \t]+[A-Z][a-z]+){1,2}\b`)
+)
+
+// notNames are capitalized words that start sentences or name dates rather
+// than people. A name candidate loses them from its start and is dropped if
+// it contains one anywhere else.
+var notNames = wordSet(`The A An This That These Those It Its I We You They He She Our My Your Their His Her
+	And But Or So If When While Then After Before Since Until Also Yet Still Just Only
+	In On At For With From To Of By As About Into Over Under Between Through During Without
+	Yesterday Today Tomorrow Tonight Next Last Every Each All Some Any No Not Please Thanks Thank Dear Hi Hello
+	Monday Tuesday Wednesday Thursday Friday Saturday Sunday
+	January February March April May June July August September October November December
+	Project Meeting Notes Note Summary Agenda Action Items Todo Plan Goals Risks Budget Status Update Review Team`)
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// ExtractEntities finds the people, organizations and projects a document
+// names. Metadata such as a frontmatter author or an email's sender is
+// taken as is; the text is searched with heuristics: names after a title,
+// runs of capitalized words, organization suffixes and "project X". The
+// heuristics favor precision over recall and only suit languages that
+// capitalize names, so Markdown headings, which are often in title case,
+// are skipped.
+func ExtractEntities(doc *storage.Document) []storage.Entity {
+	var entities []storage.Entity
+	seen := make(map[string]bool)
+	add := func(kind storage.EntityKind, name string) {
+		name = strings.Join(strings.Fields(strings.Trim(name, ` "'`)), " ")
+		key := string(kind) + "\x00" + strings.ToLower(name)
+		if name == "" || seen[key] || len(entities) >= maxEntities {
+			return
+		}
+		seen[key] = true
+		entities = append(entities, storage.Entity{Kind: kind, Name: name})
+	}
+
+	for _, field := range personFields {
+		for _, name := range splitList(doc.Metadata[field]) {
+			add(storage.EntityPerson, name)
+		}
+	}
+	for _, field := range addressFields {
+		for _, a := range parseAddresses(doc.Metadata[field]) {
+			add(storage.EntityPerson, a.Name)
+		}
+	}
+	for _, field := range orgFields {
+		for _, name := range splitList(doc.Metadata[field]) {
+			add(storage.EntityOrganization, name)
+		}
+	}
+	for _, field := range projectFields {
+		for _, name := range splitList(doc.Metadata[field]) {
+			add(storage.EntityProject, name)
+		}
+	}
+
+	text := withoutHeadings(doc.Content)
+	taken := make(map[string]bool) // words already claimed by an org or project
+	for _, m := range orgRe.FindAllStringSubmatch(text, -1) {
+		name := trimLeadingNotNames(m[1])
+		if strings.Contains(name, " ") {
+			add(storage.EntityOrganization, name)
+			claim(taken, name)
+		}
+	}
+	for _, m := range projectRe.FindAllStringSubmatch(text, -1) {
+		add(storage.EntityProject, m[1])
+		claim(taken, m[1])
+	}
+	for _, m := range honorificRe.FindAllStringSubmatch(text, -1) {
+		add(storage.EntityPerson, m[1])
+		claim(taken, m[1])
+	}
+	for _, candidate := range nameRe.FindAllString(text, -1) {
+		if name := personName(candidate, taken); name != "" {
+			add(storage.EntityPerson, name)
+		}
+	}
+	return entities
+}
+
+// personName returns candidate without the words that can't be part of a
+// name at its start, or "" if what is left doesn't look like one.
+func personName(candidate string, taken map[string]bool) string {
+	name := trimLeadingNotNames(candidate)
+	words := strings.Fields(name)
+	if len(words) < 2 {
+		return ""
+	}
+	for _, w := range words {
+		if notNames[w] || taken[w] {
+			return ""
+		}
+	}
+	return name
+}
+
+func trimLeadingNotNames(s string) string {
+	words := strings.Fields(s)
+	for len(words) > 0 && notNames[words[0]] {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+func claim(taken map[string]bool, name string) {
+	for _, w := range strings.Fields(name) {
+		taken[w] = true
+	}
+}
+
+// withoutHeadings drops Markdown heading lines from text.
+func withoutHeadings(text string) string {
+	var sb strings.Builder
+	for line := range strings.Lines(text) {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			sb.WriteString(line)
+		} else {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// splitList splits a metadata value holding one or more names separated by
+// commas or semicolons.
+func splitList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' })
+}
@@ -0,0 +1,94 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestExtractEntities(t *testing.T) {
+	doc := &storage.Document{
+		Content: "# Meeting Notes\n\n" +
+			"Yesterday Jane Doe met Bob Smith at Acme Corporation to talk about project Zeppelin.\n" +
+			"Dr. Ada Lovelace joined later. The Plan is due on Monday.\n" +
+			"Jane Doe will follow up with the Open Source Foundation.\n",
+		Metadata: map[string]string{
+			"fm_author":  "Grace Hopper",
+			"fm_project": "Apollo",
+			"from":       `"Alan Turing" <alan@example.com>`,
+		},
+	}
+	got := ExtractEntities(doc)
+	want := []storage.Entity{
+		{Kind: storage.EntityPerson, Name: "Grace Hopper"},
+		{Kind: storage.EntityPerson, Name: "Alan Turing"},
+		{Kind: storage.EntityProject, Name: "Apollo"},
+		{Kind: storage.EntityOrganization, Name: "Acme Corporation"},
+		{Kind: storage.EntityOrganization, Name: "Open Source Foundation"},
+		{Kind: storage.EntityProject, Name: "Zeppelin"},
+		{Kind: storage.EntityPerson, Name: "Ada Lovelace"},
+		{Kind: storage.EntityPerson, Name: "Jane Doe"},
+		{Kind: storage.EntityPerson, Name: "Bob Smith"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ExtractEntities() =\n%v\nwant\n%v", got, want)
+	}
+
+	if got := ExtractEntities(&storage.Document{Content: "The Plan. Next Monday. Thanks All."}); len(got) != 0 {
+		t.Errorf("ExtractEntities() on capitalized non-names = %v, want none", got)
+	}
+}
+
+func TestIndexer_StoresEntities(t *testing.T) {
+	tmpDir := t.TempDir()
+	notesDir := filepath.Join(tmpDir, "notes")
+	mustIndexerTestSucceed(t, os.MkdirAll(notesDir, 0755))
+	path := filepath.Join(notesDir, "standup.md")
+	mustIndexerTestSucceed(t, os.WriteFile(path, []byte("Jane Doe is blocked on the review from Bob Smith.\n"), 0644))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	cfg := &config.Config{
+		Sources: config.SourcesConfig{
+			Markdown: config.MarkdownSourceConfig{Enabled: true, Paths: []string{notesDir}, Extensions: []string{".md"}},
+		},
+		Indexing: config.IndexingConfig{Workers: 1, Entities: true},
+	}
+	indexer := NewIndexer(db, searchIdx, nil, nil, cfg)
+	ctx := context.Background()
+	mustIndexerTestSucceed(t, indexer.IndexFile(ctx, path))
+
+	docs, err := db.FindByEntity(ctx, storage.EntityPerson, "jane doe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].Path != path {
+		t.Fatalf("FindByEntity(person, jane doe) = %d docs, want the note", len(docs))
+	}
+
+	// Entities follow the content when the note changes.
+	mustIndexerTestSucceed(t, os.WriteFile(path, []byte("Only Bob Smith today.\n"), 0644))
+	mustIndexerTestSucceed(t, indexer.IndexFile(ctx, path))
+	got, err := db.GetEntities(ctx, docs[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []storage.Entity{{Kind: storage.EntityPerson, Name: "Bob Smith"}}; !slices.Equal(got, want) {
+		t.Errorf("entities after the edit = %v, want %v", got, want)
+	}
+}
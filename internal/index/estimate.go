@@ -0,0 +1,91 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/index/sources"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// PathEstimate is how much a first index of a directory would add.
+type PathEstimate struct {
+	Source storage.Source
+	Path   string
+	Files  int
+	Bytes  int64
+}
+
+// EstimateNewPaths returns how many files, and how many bytes of them, each
+// enabled source would index in its directories that nothing was indexed
+// from yet. It scans like indexing does, without reading the files, so a
+// first index of an unexpectedly large directory can be confirmed before it
+// starts. Directories without matching files are left out.
+func (idx *Indexer) EstimateNewPaths(ctx context.Context) ([]PathEstimate, error) {
+	var estimates []PathEstimate
+	for _, src := range idx.sources {
+		w, ok := src.(sources.Watchable)
+		if !ok {
+			continue
+		}
+		var fresh []string
+		for _, path := range w.WatchPaths() {
+			isNew, err := idx.isNewDir(ctx, path)
+			if err != nil {
+				return nil, err
+			}
+			if isNew {
+				fresh = append(fresh, path)
+			}
+		}
+		if len(fresh) == 0 {
+			continue
+		}
+
+		counts := make([]PathEstimate, len(fresh))
+		files, scanErrs := src.Scan(ctx)
+		for f := range files {
+			for i, dir := range fresh {
+				if within(f.Path, dir) {
+					counts[i].Files++
+					counts[i].Bytes += f.Size
+					break
+				}
+			}
+		}
+		for range scanErrs {
+			// Indexing reports scan errors; an estimate does without the
+			// files it couldn't reach.
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for i, dir := range fresh {
+			if counts[i].Files > 0 {
+				counts[i].Source, counts[i].Path = src.Name(), dir
+				estimates = append(estimates, counts[i])
+			}
+		}
+	}
+	return estimates, nil
+}
+
+// isNewDir reports whether path is a directory with no indexed documents.
+func (idx *Indexer) isNewDir(ctx context.Context, path string) (bool, error) {
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return false, nil
+	}
+	paths, err := idx.db.ListPathsUnder(ctx, path)
+	if err != nil {
+		return false, fmt.Errorf("checking %s: %w", path, err)
+	}
+	return len(paths) == 0, nil
+}
+
+func within(path, dir string) bool {
+	dir = filepath.Clean(dir)
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,58 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestIndexer_EstimateNewPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(tmpDir, rel)
+		mustIndexerTestSucceed(t, os.MkdirAll(filepath.Dir(path), 0755))
+		mustIndexerTestSucceed(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write("notes/a.md", "# A\n")
+	write("downloads/b.md", "# B, somewhat longer\n")
+	write("downloads/sub/c.md", "# C\n")
+	write("downloads/d.zip", strings.Repeat("x", 1000)) // not a note
+	mustIndexerTestSucceed(t, os.MkdirAll(filepath.Join(tmpDir, "empty"), 0755))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	mustIndexerTestSucceed(t, err)
+	defer closeIndexerTestDB(t, db)
+	ctx := context.Background()
+	notes := filepath.Join(tmpDir, "notes")
+	mustIndexerTestSucceed(t, db.InsertDocument(ctx, &storage.Document{
+		ID: "a", Source: storage.SourceMarkdown, Path: filepath.Join(notes, "a.md"),
+		ContentHash: "h", IndexedAt: time.Now(), ModifiedAt: time.Now(),
+	}))
+
+	downloads := filepath.Join(tmpDir, "downloads")
+	cfg := &config.Config{
+		Sources: config.SourcesConfig{
+			Markdown: config.MarkdownSourceConfig{
+				Enabled:    true,
+				Paths:      []string{notes, downloads, filepath.Join(tmpDir, "empty"), filepath.Join(tmpDir, "missing")},
+				Extensions: []string{".md"},
+			},
+		},
+		Indexing: config.IndexingConfig{Workers: 1},
+	}
+	idx := NewIndexer(db, nil, nil, nil, cfg)
+
+	got, err := idx.EstimateNewPaths(ctx)
+	mustIndexerTestSucceed(t, err)
+	want := []PathEstimate{{Source: storage.SourceMarkdown, Path: downloads, Files: 2, Bytes: 25}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("EstimateNewPaths() = %v, want %v", got, want)
+	}
+}
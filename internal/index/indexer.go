@@ -3,8 +3,10 @@ package index
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
 	"sync/atomic"
 
@@ -15,21 +17,45 @@ import (
 	"github.com/J-1000/mindcli/internal/search"
 	"github.com/J-1000/mindcli/internal/storage"
 	"github.com/J-1000/mindcli/pkg/chunker"
+	"github.com/J-1000/mindcli/pkg/lang"
 )
 
 // Indexer orchestrates document indexing from various sources.
 type Indexer struct {
 	db       *storage.DB
-	search   *search.BleveIndex
+	search   search.Backend
 	vectors  *storage.VectorStore
 	embedder embeddings.Embedder
 	sources  []sources.Source
 	workers  int
 	progress ProgressReporter
 	force    bool // when true, re-index even unchanged files (and re-embed)
+	throttle *throttle
+
+	// embedBatch is the most chunks per embedding request; 0 = no limit.
+	embedBatch int
+	memory     *memoryBudget
+
+	entities bool   // extract named entities into the entities table
+	order    string // the order files are indexed in, see sortFiles
+
+	// sectionMinChars is the note length from which headings are also
+	// indexed as sections; 0 disables sections.
+	sectionMinChars int
 
 	redactor      privacy.Redactor
 	redactContent bool
+
+	// budgets caps the documents of high-churn sources; see Trim.
+	budgets map[storage.Source]budget
+
+	events     EventHandler
+	processors []PostProcessor
+
+	// unsaved are the paths whose journal entries wait for SaveVectors,
+	// since their vector changes are only in memory until then.
+	unsavedMu sync.Mutex
+	unsaved   []string
 }
 
 // ProgressReporter receives progress updates during indexing.
@@ -40,26 +66,69 @@ type ProgressReporter interface {
 	OnError(source string, path string, err error)
 }
 
+// WarningReporter is optionally implemented by a ProgressReporter to be told
+// about files that were only partially parsed but still indexed.
+type WarningReporter interface {
+	OnWarning(source string, path string, warnings []string)
+}
+
+// EventHandler is told about changes to the index, e.g. to run user hooks.
+// Sections of a document are not reported separately. Methods may be called
+// from several indexing workers at once.
+type EventHandler interface {
+	OnDocumentIndexed(doc *storage.Document)
+	OnDocumentRemoved(doc *storage.Document)
+	OnIndexComplete(stats *Stats)
+}
+
+// PostProcessor adjusts documents after they are parsed and before they are
+// stored, e.g. to add tags. Process may be called from several indexing
+// workers at once.
+type PostProcessor interface {
+	Name() string
+	Process(ctx context.Context, doc *storage.Document) error
+}
+
 // Stats contains indexing statistics.
 type Stats struct {
 	TotalFiles   int64
 	IndexedFiles int64
 	Errors       int64
+	Warnings     int64 // files indexed from partially parsed content
+	Embedded     int64 // documents (re-)embedded into the vector store
+	Recovered    int64 // documents redone after an interrupted run
 	BySource     map[string]int64
+	Trimmed      []sources.TrimReport // what was trimmed to fit source budgets
+	Expired      []sources.TrimReport // what was removed for being past retention
+
+	// Interrupted is set when indexing was cancelled before it finished.
+	// Remaining then counts the scanned files left unprocessed and
+	// NotStarted lists the sources not reached. Indexing again picks up
+	// where it stopped, skipping the files already done.
+	Interrupted bool
+	Remaining   int64
+	NotStarted  []storage.Source
 }
 
 // NewIndexer creates a new indexer with the given configuration.
 // The vectors and embedder parameters are optional; if nil, semantic indexing is skipped.
-func NewIndexer(db *storage.DB, searchIndex *search.BleveIndex, vectors *storage.VectorStore, embedder embeddings.Embedder, cfg *config.Config) *Indexer {
+func NewIndexer(db *storage.DB, searchIndex search.Backend, vectors *storage.VectorStore, embedder embeddings.Embedder, cfg *config.Config) *Indexer {
+	sources.SetPreviewLength(cfg.Display.PreviewLength)
+
 	var srcs []sources.Source
 
 	// Add markdown source if enabled
 	if cfg.Sources.Markdown.Enabled {
-		srcs = append(srcs, sources.NewMarkdownSource(
+		markdownSrc := sources.NewMarkdownSource(
 			cfg.Sources.Markdown.Paths,
 			cfg.Sources.Markdown.Extensions,
 			cfg.Sources.Markdown.Ignore,
-		))
+		)
+		for _, v := range cfg.Sources.Markdown.Vaults {
+			markdownSrc.AddVault(v.Name, v.Paths, v.Ignore)
+		}
+		markdownSrc.SetGitMetadata(cfg.Sources.Markdown.GitMetadata)
+		srcs = append(srcs, markdownSrc)
 	}
 
 	// Add PDF source if enabled
@@ -83,28 +152,87 @@ func NewIndexer(db *storage.DB, searchIndex *search.BleveIndex, vectors *storage
 
 	// Add browser history source if enabled
 	if cfg.Sources.Browser.Enabled {
-		srcs = append(srcs, sources.NewBrowserSource(
-			cfg.Sources.Browser.Browsers,
-		))
+		browserSrc := sources.NewBrowserSource(cfg.Sources.Browser.Browsers)
+		browserSrc.SetBudget(cfg.Sources.Browser.MaxEntries, int64(cfg.Sources.Browser.MaxSizeMB)<<20)
+		browserSrc.SetRetention(cfg.Sources.Browser.RetentionDays)
+		srcs = append(srcs, browserSrc)
 	}
 
 	// Add clipboard source if enabled
 	if cfg.Sources.Clipboard.Enabled {
-		srcs = append(srcs, sources.NewClipboardSource(
+		clipSrc := sources.NewClipboardSource(
 			db,
 			cfg.Sources.Clipboard.RetentionDays,
 			cfg.Sources.Clipboard.SkipPasswords,
+		)
+		// Where the focused app can't be found, exclude_apps does nothing
+		// rather than stop capture altogether; doctor says so.
+		if sources.FocusedAppSupported() {
+			clipSrc.SetExcludedApps(cfg.Sources.Clipboard.ExcludeApps)
+		}
+		srcs = append(srcs, clipSrc)
+	}
+
+	// Add data file source if enabled
+	if cfg.Sources.Data.Enabled {
+		srcs = append(srcs, sources.NewDataSource(
+			cfg.Sources.Data.Paths,
+			cfg.Sources.Data.Extensions,
+			cfg.Sources.Data.Ignore,
+			cfg.Sources.Data.Columns,
+			cfg.Sources.Data.MaxRecords,
+		))
+	}
+
+	// Add reference library source if enabled
+	if cfg.Sources.References.Enabled {
+		srcs = append(srcs, sources.NewReferenceSource(
+			cfg.Sources.References.Paths,
+			cfg.Sources.References.Ignore,
+		))
+	}
+
+	// Add screenshot source if enabled
+	if cfg.Sources.Screenshots.Enabled {
+		srcs = append(srcs, sources.NewScreenshotSource(
+			cfg.Sources.Screenshots.Paths,
+			cfg.Sources.Screenshots.Extensions,
+			cfg.Sources.Screenshots.Ignore,
+			cfg.Sources.Screenshots.OCRCommand,
+			cfg.Sources.Screenshots.RetentionDays,
+		))
+	}
+
+	// Add project docs source if enabled
+	if cfg.Sources.Projects.Enabled {
+		srcs = append(srcs, sources.NewProjectSource(
+			cfg.Sources.Projects.Paths,
+			cfg.Sources.Projects.Include,
+			cfg.Sources.Projects.Ignore,
 		))
 	}
 
-	return &Indexer{
-		db:       db,
-		search:   searchIndex,
-		vectors:  vectors,
-		embedder: embedder,
-		sources:  srcs,
-		workers:  cfg.Indexing.Workers,
+	workers, embedBatch, maxMB := memoryLimits(cfg.Indexing)
+	idx := &Indexer{
+		db:         db,
+		search:     searchIndex,
+		vectors:    vectors,
+		embedder:   embedder,
+		sources:    srcs,
+		workers:    workers,
+		throttle:   newThrottle(cfg.Indexing.Throttle),
+		embedBatch: embedBatch,
+		memory:     newMemoryBudget(maxMB),
+		entities:   cfg.Indexing.Entities,
+		order:      cfg.Indexing.Order,
+		budgets: map[storage.Source]budget{
+			storage.SourceClipboard: {cfg.Sources.Clipboard.MaxDocuments, int64(cfg.Sources.Clipboard.MaxSizeMB) << 20},
+		},
+	}
+	if cfg.Sources.Markdown.Sections {
+		idx.sectionMinChars = cfg.Sources.Markdown.SectionMinChars
 	}
+	return idx
 }
 
 // SetProgressReporter sets the progress reporter.
@@ -112,12 +240,42 @@ func (idx *Indexer) SetProgressReporter(pr ProgressReporter) {
 	idx.progress = pr
 }
 
+// SetEventHandler sets the handler told about indexed and removed documents.
+func (idx *Indexer) SetEventHandler(h EventHandler) {
+	idx.events = h
+}
+
+// SetPostProcessors sets the post-processors run, in order, on every parsed
+// document.
+func (idx *Indexer) SetPostProcessors(processors []PostProcessor) {
+	idx.processors = processors
+}
+
 // SetForce controls whether unchanged files are re-indexed (and re-embedded).
 // Use this for a full rebuild, e.g. after changing the embedding model.
 func (idx *Indexer) SetForce(force bool) {
 	idx.force = force
 }
 
+// SetIdleOnly pauses indexing whenever idle reports that the machine is busy.
+// Pass SystemIdle for load-based detection, or nil to disable.
+func (idx *Indexer) SetIdleOnly(idle func() bool) {
+	if idx.throttle == nil {
+		idx.throttle = newThrottle(config.ThrottleConfig{})
+	}
+	idx.throttle.idle = idle
+}
+
+// SetThrottle replaces the indexing pace limits, keeping any idle-only
+// check. It is safe to call while indexing, as when the config is reloaded.
+func (idx *Indexer) SetThrottle(cfg config.ThrottleConfig) {
+	if idx.throttle == nil {
+		idx.throttle = newThrottle(cfg)
+		return
+	}
+	idx.throttle.configure(cfg)
+}
+
 // SetRedactor configures index-time redaction. When redactContent is true and
 // the redactor has patterns, document content and previews are redacted before
 // they are stored or indexed.
@@ -142,22 +300,69 @@ func (idx *Indexer) IndexAll(ctx context.Context) (*Stats, error) {
 		BySource: make(map[string]int64),
 	}
 
-	for _, src := range idx.sources {
+	recovered, err := idx.Recover(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("recovering interrupted documents: %w", err)
+	}
+	stats.Recovered = int64(recovered)
+
+	for i, src := range idx.sources {
 		srcStats, err := idx.indexSource(ctx, src)
 		if err != nil {
+			stats.add(src, srcStats)
+			if ctx.Err() != nil {
+				stats.Interrupted = true
+				for _, rest := range idx.sources[i+1:] {
+					stats.NotStarted = append(stats.NotStarted, rest.Name())
+				}
+			}
 			return stats, fmt.Errorf("indexing %s: %w", src.Name(), err)
 		}
+		if exp, ok := src.(sources.Expiring); ok {
+			report, err := idx.expire(ctx, src.Name(), exp)
+			if err != nil {
+				return stats, fmt.Errorf("expiring %s: %w", src.Name(), err)
+			}
+			if report.Removed > 0 {
+				stats.Expired = append(stats.Expired, report)
+			}
+		}
 
-		stats.TotalFiles += srcStats.TotalFiles
-		stats.IndexedFiles += srcStats.IndexedFiles
-		stats.Errors += srcStats.Errors
-		stats.BySource[string(src.Name())] = srcStats.IndexedFiles
+		stats.add(src, srcStats)
+		if t, ok := src.(sources.Trimming); ok {
+			stats.Trimmed = append(stats.Trimmed, t.TakeTrimmed()...)
+		}
+		if a, ok := src.(sources.Aging); ok {
+			stats.Expired = append(stats.Expired, a.TakeExpired()...)
+		}
 	}
 
+	trimmed, err := idx.Trim(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("trimming to budget: %w", err)
+	}
+	stats.Trimmed = append(stats.Trimmed, trimmed...)
+
+	if idx.events != nil {
+		idx.events.OnIndexComplete(stats)
+	}
 	return stats, nil
 }
 
-// indexSource indexes all documents from a single source.
+// add counts the files of a source into the totals.
+func (s *Stats) add(src sources.Source, srcStats *Stats) {
+	s.TotalFiles += srcStats.TotalFiles
+	s.IndexedFiles += srcStats.IndexedFiles
+	s.Errors += srcStats.Errors
+	s.Warnings += srcStats.Warnings
+	s.Embedded += srcStats.Embedded
+	s.Remaining += srcStats.Remaining
+	s.BySource[string(src.Name())] = srcStats.IndexedFiles
+}
+
+// indexSource indexes all documents from a single source. When ctx is
+// cancelled, the workers finish the files they are on, so no document is
+// left half stored, and the stats count what was done.
 func (idx *Indexer) indexSource(ctx context.Context, src sources.Source) (*Stats, error) {
 	stats := &Stats{
 		BySource: make(map[string]int64),
@@ -181,6 +386,16 @@ func (idx *Indexer) indexSource(ctx context.Context, src sources.Source) (*Stats
 	}
 
 	stats.TotalFiles = int64(len(allFiles))
+	sortFiles(allFiles, idx.order)
+
+	// Files whose last attempt failed are redone even when unchanged: a
+	// failed embedding leaves the document stored but without vectors.
+	retry := make(map[string]bool)
+	if failures, err := idx.db.ListIndexErrors(ctx); err == nil {
+		for _, f := range failures {
+			retry[f.Path] = true
+		}
+	}
 
 	if idx.progress != nil {
 		idx.progress.OnStart(string(src.Name()), len(allFiles))
@@ -192,7 +407,9 @@ func (idx *Indexer) indexSource(ctx context.Context, src sources.Source) (*Stats
 
 	var processed int64
 	var indexed int64
-	var errors int64
+	var failed int64
+	var warnings int64
+	var embedded int64
 
 	// Start workers
 	for i := 0; i < idx.workers; i++ {
@@ -211,20 +428,33 @@ func (idx *Indexer) indexSource(ctx context.Context, src sources.Source) (*Stats
 					idx.progress.OnProgress(string(src.Name()), int(current), len(allFiles), file.Path)
 				}
 
-				// Fast path: skip files whose mtime hasn't advanced.
+				// Fast path: skip files that haven't changed.
 				existing, _ := idx.db.GetDocumentByPath(ctx, file.Path)
-				if !idx.force && existing != nil && existing.ModifiedAt.Unix() >= file.ModifiedAt {
+				if !idx.force && !retry[file.Path] && upToDate(ctx, src, existing, file) {
 					atomic.AddInt64(&indexed, 1)
 					continue
 				}
 
+				if err := idx.throttle.wait(ctx); err != nil {
+					return
+				}
+				reserved, err := idx.memory.acquire(ctx, file.Size)
+				if err != nil {
+					return
+				}
+				// Once started, a file is finished even if indexing is
+				// cancelled meanwhile.
+				ctx := context.WithoutCancel(ctx)
+
 				// Parse document
-				doc, err := src.Parse(ctx, file)
+				doc, partial, err := idx.parse(ctx, src, file)
+				if partial {
+					atomic.AddInt64(&warnings, 1)
+				}
 				if err != nil {
-					if idx.progress != nil {
-						idx.progress.OnError(string(src.Name()), file.Path, err)
-					}
-					atomic.AddInt64(&errors, 1)
+					idx.memory.release(reserved)
+					idx.recordFailure(ctx, src.Name(), file.Path, err)
+					atomic.AddInt64(&failed, 1)
 					continue
 				}
 
@@ -233,49 +463,35 @@ func (idx *Indexer) indexSource(ctx context.Context, src sources.Source) (*Stats
 				// Content-hash check: if the bytes are identical despite a
 				// newer mtime, refresh metadata but skip the expensive
 				// re-embedding (existing vectors are still valid).
-				unchanged := !idx.force && existing != nil && existing.ContentHash == doc.ContentHash
-
-				// Store in database
-				if err := idx.db.UpsertDocument(ctx, doc); err != nil {
-					if idx.progress != nil {
-						idx.progress.OnError(string(src.Name()), file.Path, err)
-					}
-					atomic.AddInt64(&errors, 1)
+				unchanged := !idx.force && !retry[file.Path] && existing != nil && existing.ContentHash == doc.ContentHash
+				embed := idx.vectors != nil && idx.embedder != nil && !unchanged
+
+				// An embedding failure leaves the document stored and
+				// searchable, but it stays recorded as failed so the next
+				// run or a retry embeds it.
+				err = idx.storeDocument(ctx, doc, embed)
+				idx.memory.release(reserved)
+				switch {
+				case err != nil:
+					idx.recordFailure(ctx, src.Name(), file.Path, err)
+					atomic.AddInt64(&failed, 1)
 					continue
+				case embed:
+					atomic.AddInt64(&embedded, 1)
 				}
 
-				// Index in search
-				if err := idx.search.Index(ctx, doc); err != nil {
-					if idx.progress != nil {
-						idx.progress.OnError(string(src.Name()), file.Path, err)
-					}
-					atomic.AddInt64(&errors, 1)
-					continue
-				}
-
-				// Generate embeddings if available (skipped when content is
-				// unchanged, since existing vectors remain valid).
-				if idx.vectors != nil && idx.embedder != nil && !unchanged {
-					if err := idx.embedDocument(ctx, doc); err != nil {
-						if idx.progress != nil {
-							idx.progress.OnError(string(src.Name()), file.Path, err)
-						}
-						atomic.AddInt64(&errors, 1)
-					}
-				}
-
+				_ = idx.db.ClearIndexError(ctx, file.Path)
 				atomic.AddInt64(&indexed, 1)
 			}
 		}()
 	}
 
 	// Send jobs
+send:
 	for _, file := range allFiles {
 		select {
 		case <-ctx.Done():
-			close(jobs)
-			wg.Wait()
-			return stats, ctx.Err()
+			break send
 		case jobs <- file:
 		}
 	}
@@ -283,15 +499,34 @@ func (idx *Indexer) indexSource(ctx context.Context, src sources.Source) (*Stats
 	wg.Wait()
 
 	stats.IndexedFiles = indexed
-	stats.Errors = errors
+	stats.Errors = failed
+	stats.Warnings = warnings
+	stats.Embedded = embedded
 
 	if idx.progress != nil {
-		idx.progress.OnComplete(string(src.Name()), int(indexed), int(errors))
+		idx.progress.OnComplete(string(src.Name()), int(indexed), int(failed))
 	}
 
+	if err := ctx.Err(); err != nil {
+		stats.Remaining = stats.TotalFiles - processed
+		return stats, err
+	}
 	return stats, nil
 }
 
+// upToDate reports whether existing, indexed earlier from file, is still
+// current: the file's mtime hasn't advanced and, for a Revisable source, the
+// source has nothing newer for it.
+func upToDate(ctx context.Context, src sources.Source, existing *storage.Document, file sources.FileInfo) bool {
+	if existing == nil || existing.ModifiedAt.Unix() < file.ModifiedAt {
+		return false
+	}
+	if r, ok := src.(sources.Revisable); ok && r.Outdated(ctx, file.Path, existing) {
+		return false
+	}
+	return true
+}
+
 // IndexFile indexes a single file.
 func (idx *Indexer) IndexFile(ctx context.Context, path string) error {
 	// Find the appropriate source based on source configuration.
@@ -309,30 +544,400 @@ func (idx *Indexer) IndexFile(ctx context.Context, path string) error {
 			}
 		}
 
-		doc, err := src.Parse(ctx, fileInfo)
+		if err := idx.indexFileInfo(ctx, src, fileInfo); err != nil {
+			idx.recordFailure(ctx, src.Name(), path, err)
+			return err
+		}
+		_ = idx.db.ClearIndexError(ctx, path)
+		return nil
+	}
+
+	return fmt.Errorf("no source found for file: %s", path)
+}
+
+// RetryFailed re-attempts every file recorded in the index error table.
+// Failures for files that no longer exist are dropped.
+func (idx *Indexer) RetryFailed(ctx context.Context) (*Stats, error) {
+	stats := &Stats{
+		BySource: make(map[string]int64),
+	}
+
+	failed, err := idx.db.ListIndexErrors(ctx)
+	if err != nil {
+		return stats, err
+	}
+	stats.TotalFiles = int64(len(failed))
+
+	if idx.progress != nil {
+		idx.progress.OnStart("retry", len(failed))
+	}
+	for i, f := range failed {
+		if ctx.Err() != nil {
+			return stats, ctx.Err()
+		}
+		if idx.progress != nil {
+			idx.progress.OnProgress("retry", i+1, len(failed), f.Path)
+		}
+
+		if isFileBackedSource(f.Source) {
+			if _, err := os.Stat(f.Path); os.IsNotExist(err) {
+				_ = idx.db.ClearIndexError(ctx, f.Path)
+				continue
+			}
+		}
+
+		// IndexFile reports and re-records the failure itself.
+		if err := idx.IndexFile(ctx, f.Path); err != nil {
+			stats.Errors++
+			continue
+		}
+		stats.IndexedFiles++
+		stats.BySource[string(f.Source)]++
+	}
+	if idx.progress != nil {
+		idx.progress.OnComplete("retry", int(stats.IndexedFiles), int(stats.Errors))
+	}
+
+	return stats, nil
+}
+
+// recordFailure reports a file that could not be indexed and persists the
+// failure so it can be listed and retried later.
+func (idx *Indexer) recordFailure(ctx context.Context, source storage.Source, path string, err error) {
+	if idx.progress != nil {
+		idx.progress.OnError(string(source), path, err)
+	}
+	_ = idx.db.RecordIndexError(context.WithoutCancel(ctx), &storage.IndexError{
+		Path:   path,
+		Source: source,
+		Error:  err.Error(),
+	})
+}
+
+// RemoveDir removes every indexed document stored below dir, e.g. after the
+// directory was deleted or moved. It returns the number of documents removed.
+func (idx *Indexer) RemoveDir(ctx context.Context, dir string) (int, error) {
+	paths, err := idx.db.ListPathsUnder(ctx, dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, p := range paths {
+		err := idx.RemoveFile(ctx, p)
+		if errors.Is(err, storage.ErrNotFound) {
+			// A section, already removed along with its note.
+			continue
+		}
 		if err != nil {
-			return fmt.Errorf("parsing: %w", err)
+			return removed, fmt.Errorf("removing %s: %w", p, err)
 		}
-		idx.applyRedaction(doc)
+		removed++
+	}
+	return removed, nil
+}
 
-		if err := idx.db.UpsertDocument(ctx, doc); err != nil {
-			return fmt.Errorf("storing: %w", err)
+// handles reports whether any enabled source is configured for path.
+func (idx *Indexer) handles(path string) bool {
+	for _, src := range idx.sources {
+		if src.MatchesPath(path) {
+			return true
 		}
+	}
+	return false
+}
 
-		if err := idx.search.Index(ctx, doc); err != nil {
-			return fmt.Errorf("indexing: %w", err)
+// parse parses file with src and runs the post-processors on the result. A
+// partially parsed document is accepted: its warnings are passed to the
+// progress reporter and partial is true. A failed post-processor is also
+// reported as a warning and leaves the document as it was.
+func (idx *Indexer) parse(ctx context.Context, src sources.Source, file sources.FileInfo) (doc *storage.Document, partial bool, err error) {
+	doc, err = src.Parse(ctx, file)
+	var warnings []string
+	var perr *sources.PartialError
+	if errors.As(err, &perr) && doc != nil {
+		warnings, err = perr.Warnings, nil
+	}
+	if err != nil {
+		return doc, false, err
+	}
+	setLanguage(doc)
+	setWordCount(doc)
+	for _, p := range idx.processors {
+		if err := p.Process(ctx, doc); err != nil {
+			warnings = append(warnings, fmt.Sprintf("post-processor %s: %v", p.Name(), err))
 		}
+	}
+	if len(warnings) == 0 {
+		return doc, false, nil
+	}
+	if wr, ok := idx.progress.(WarningReporter); ok {
+		wr.OnWarning(string(src.Name()), file.Path, warnings)
+	}
+	return doc, true, nil
+}
 
-		if idx.vectors != nil && idx.embedder != nil {
-			if err := idx.embedDocument(ctx, doc); err != nil {
-				return fmt.Errorf("embedding: %w", err)
-			}
+// setLanguage records the language of doc's content as its "lang"
+// metadata, unless the source already set one.
+func setLanguage(doc *storage.Document) {
+	if doc.Metadata["lang"] != "" {
+		return
+	}
+	code := lang.Detect(doc.Content)
+	if code == "" {
+		return
+	}
+	if doc.Metadata == nil {
+		doc.Metadata = make(map[string]string)
+	}
+	doc.Metadata["lang"] = code
+}
+
+// setWordCount records the number of words in doc's content as its "words"
+// metadata.
+func setWordCount(doc *storage.Document) {
+	if doc.Metadata == nil {
+		doc.Metadata = make(map[string]string)
+	}
+	doc.Metadata["words"] = strconv.Itoa(storage.CountWords(doc.Content))
+}
+
+// indexFileInfo parses, stores, indexes and embeds a single file from src.
+func (idx *Indexer) indexFileInfo(ctx context.Context, src sources.Source, fileInfo sources.FileInfo) error {
+	if err := idx.throttle.wait(ctx); err != nil {
+		return err
+	}
+	reserved, err := idx.memory.acquire(ctx, fileInfo.Size)
+	if err != nil {
+		return err
+	}
+	defer idx.memory.release(reserved)
+
+	doc, _, err := idx.parse(ctx, src, fileInfo)
+	if err != nil {
+		return fmt.Errorf("parsing: %w", err)
+	}
+	idx.applyRedaction(doc)
+
+	return idx.storeDocument(ctx, doc, idx.vectors != nil && idx.embedder != nil)
+}
+
+// embeddingError marks a failure to embed a document that was otherwise
+// stored and indexed.
+type embeddingError struct {
+	err error
+}
+
+func (e *embeddingError) Error() string { return "embedding: " + e.err.Error() }
+func (e *embeddingError) Unwrap() error { return e.err }
+
+// storeDocument writes doc and, when embed is set, its chunks to the
+// database in a single transaction, then updates the search index and
+// vector store. Embeddings are generated before the transaction so no write
+// lock is held during slow embedding calls. If embedding fails the document
+// is still stored (without chunks) and an *embeddingError is returned. The
+// write is journaled, so a crash part way through is redone by Recover.
+func (idx *Indexer) storeDocument(ctx context.Context, doc *storage.Document, embed bool) error {
+	// Keep the ID of the document already stored for this path: IDs derive
+	// from the absolute path, which differs where roots are mounted elsewhere.
+	if existing, err := idx.db.GetDocumentByPath(ctx, doc.Path); err == nil {
+		doc.ID = existing.ID
+	}
+
+	idx.beginJournal(ctx, doc.Source, doc.Path, doc.ID)
+	err := idx.writeDocument(ctx, doc, embed)
+	var embedErr *embeddingError
+	if err == nil || errors.As(err, &embedErr) {
+		// The document is stored either way; only its vector changes, if
+		// any, still have to reach the disk.
+		idx.endJournal(ctx, doc.Path, embed)
+	}
+	return err
+}
+
+// writeDocument does the writes of storeDocument.
+func (idx *Indexer) writeDocument(ctx context.Context, doc *storage.Document, embed bool) error {
+	var chunks []*storage.Chunk
+	var vectors [][]float32
+	var embedErr error
+	if embed {
+		chunks, vectors, embedErr = idx.embedChunks(ctx, doc)
+		if embedErr != nil {
+			// Drop the stale chunks rather than keep ones that no longer
+			// match the content.
+			chunks, vectors = []*storage.Chunk{}, nil
+		}
+		if err := idx.deleteDocumentVectors(ctx, doc.ID); err != nil {
+			return fmt.Errorf("removing old vectors: %w", err)
+		}
+	}
+
+	if err := idx.db.SaveDocument(ctx, doc, chunks); err != nil {
+		return fmt.Errorf("storing: %w", err)
+	}
+	if doc.Source == storage.SourceMarkdown {
+		if err := idx.db.SetRelations(ctx, doc.ID, storage.RelationAsset, ExtractAssets(doc)); err != nil {
+			return fmt.Errorf("storing assets: %w", err)
+		}
+	}
+	if doc.Source == storage.SourceEmail {
+		if err := idx.db.SetParticipants(ctx, doc.ID, ExtractParticipants(doc)); err != nil {
+			return fmt.Errorf("storing participants: %w", err)
+		}
+	}
+	if idx.entities {
+		if err := idx.db.SetEntities(ctx, doc.ID, ExtractEntities(doc)); err != nil {
+			return fmt.Errorf("storing entities: %w", err)
 		}
+	}
+
+	if err := idx.search.Index(ctx, doc); err != nil {
+		return fmt.Errorf("indexing: %w", err)
+	}
+	if hasSections(doc.Source) {
+		if err := idx.storeSections(ctx, doc); err != nil {
+			return fmt.Errorf("indexing sections: %w", err)
+		}
+	}
+	if idx.events != nil {
+		idx.events.OnDocumentIndexed(doc)
+	}
 
+	if !embed {
 		return nil
 	}
+	if embedErr != nil {
+		return &embeddingError{err: embedErr}
+	}
+	if len(chunks) > 0 {
+		keys := make([]string, len(chunks))
+		for i, c := range chunks {
+			keys[i] = c.ID
+		}
+		if err := idx.vectors.AddBatch(keys, vectors); err != nil {
+			return &embeddingError{err: fmt.Errorf("adding vectors: %w", err)}
+		}
+	}
+	return idx.throttle.afterEmbed(ctx)
+}
 
-	return fmt.Errorf("no source found for file: %s", path)
+// hasSections reports whether documents of the source are split into
+// sections.
+func hasSections(s storage.Source) bool {
+	switch s {
+	case storage.SourceMarkdown, storage.SourceData, storage.SourceReference:
+		return true
+	default:
+		return false
+	}
+}
+
+// storeSections stores and indexes the heading sections of a long note, the
+// rows of a data file or the references of a library, and removes sections
+// it no longer has. Sections aren't embedded: the document's own chunks
+// already cover their text for vector search.
+func (idx *Indexer) storeSections(ctx context.Context, doc *storage.Document) error {
+	var sections []*storage.Document
+	switch doc.Source {
+	case storage.SourceMarkdown:
+		sections = sources.Sections(doc, idx.sectionMinChars)
+	case storage.SourceData:
+		sections = sources.Records(doc)
+	case storage.SourceReference:
+		sections = sources.References(doc)
+	}
+	keep := make(map[string]bool, len(sections))
+	for _, section := range sections {
+		keep[section.ID] = true
+		// Sections take the language of their document, so lang: filters
+		// find them too.
+		if code := doc.Metadata["lang"]; code != "" {
+			if section.Metadata == nil {
+				section.Metadata = make(map[string]string)
+			}
+			section.Metadata["lang"] = code
+		}
+		setWordCount(section)
+		if err := idx.db.SaveDocument(ctx, section, nil); err != nil {
+			return fmt.Errorf("storing: %w", err)
+		}
+		if err := idx.search.Index(ctx, section); err != nil {
+			return err
+		}
+	}
+	return idx.removeSections(ctx, doc, keep)
+}
+
+// removeSections removes the stored sections of doc that aren't in keep.
+func (idx *Indexer) removeSections(ctx context.Context, doc *storage.Document, keep map[string]bool) error {
+	existing, err := idx.db.ListSections(ctx, doc.ID, doc.Path)
+	if err != nil {
+		return err
+	}
+	for _, section := range existing {
+		if keep[section.ID] {
+			continue
+		}
+		if err := idx.search.Delete(ctx, section.ID); err != nil {
+			return fmt.Errorf("removing from search: %w", err)
+		}
+		if err := idx.db.DeleteDocument(ctx, section.ID); err != nil {
+			return fmt.Errorf("removing from database: %w", err)
+		}
+	}
+	return nil
+}
+
+// WatchPaths returns the files and directories of every enabled source that
+// can be watched through the file system.
+func (idx *Indexer) WatchPaths() []string {
+	var paths []string
+	for _, src := range idx.sources {
+		if w, ok := src.(sources.Watchable); ok {
+			paths = append(paths, w.WatchPaths()...)
+		}
+	}
+	return paths
+}
+
+// Pollers returns the enabled sources that must be polled instead of watched.
+func (idx *Indexer) Pollers() []sources.Source {
+	var polled []sources.Source
+	for _, src := range idx.sources {
+		if _, ok := src.(sources.Poller); ok {
+			polled = append(polled, src)
+		}
+	}
+	return polled
+}
+
+// PollSource re-scans src and indexes entries that are new or have changed
+// since they were last indexed. It returns the number of entries indexed.
+func (idx *Indexer) PollSource(ctx context.Context, src sources.Source) (int, error) {
+	files, scanErrs := src.Scan(ctx)
+
+	indexed := 0
+	var firstErr error
+	for file := range files {
+		existing, _ := idx.db.GetDocumentByPath(ctx, file.Path)
+		if upToDate(ctx, src, existing, file) {
+			continue
+		}
+		if err := idx.indexFileInfo(ctx, src, file); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", file.Path, err)
+			}
+			continue
+		}
+		indexed++
+	}
+	for err := range scanErrs {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return indexed, firstErr
 }
 
 func statFileInfo(path string) (sources.FileInfo, error) {
@@ -381,6 +986,8 @@ func (idx *Indexer) RemoveFile(ctx context.Context, path string) error {
 		return err
 	}
 
+	idx.beginJournal(ctx, doc.Source, path, doc.ID)
+
 	// Remove semantic vectors for this document's chunks.
 	if err := idx.deleteDocumentVectors(ctx, doc.ID); err != nil && idx.progress != nil {
 		idx.progress.OnError(string(doc.Source), doc.Path, fmt.Errorf("removing vectors: %w", err))
@@ -395,75 +1002,78 @@ func (idx *Indexer) RemoveFile(ctx context.Context, path string) error {
 	if err := idx.db.DeleteDocument(ctx, doc.ID); err != nil {
 		return fmt.Errorf("removing from database: %w", err)
 	}
+	if err := idx.removeSections(ctx, doc, nil); err != nil {
+		return fmt.Errorf("removing sections: %w", err)
+	}
+	_ = idx.db.ClearIndexError(ctx, path)
+	idx.endJournal(ctx, path, true)
+	if idx.events != nil {
+		idx.events.OnDocumentRemoved(doc)
+	}
 
 	return nil
 }
 
-// embedDocument chunks a document, generates embeddings, and stores them.
-// Errors are returned so callers can surface and count them rather than
-// silently leaving a document without vectors.
-func (idx *Indexer) embedDocument(ctx context.Context, doc *storage.Document) error {
-	// Delete old chunks and vectors for this document.
-	if err := idx.deleteDocumentVectors(ctx, doc.ID); err != nil {
-		return fmt.Errorf("removing old vectors: %w", err)
-	}
-	if err := idx.db.DeleteChunksByDocument(ctx, doc.ID); err != nil {
-		return fmt.Errorf("removing old chunks: %w", err)
+// embedChunks splits a document into chunks and generates their embeddings,
+// at most embedBatch chunks per request. Nothing is written; storeDocument
+// persists the result.
+func (idx *Indexer) embedChunks(ctx context.Context, doc *storage.Document) ([]*storage.Chunk, [][]float32, error) {
+	ctx = embeddings.WithLanguage(ctx, doc.Metadata["lang"])
+	parts := chunker.Split(doc.Content, chunker.DefaultOptions())
+	chunks := make([]*storage.Chunk, len(parts))
+	if len(parts) == 0 {
+		return chunks, nil, nil
 	}
 
-	// Chunk the document content.
-	chunks := chunker.Split(doc.Content, chunker.DefaultOptions())
-	if len(chunks) == 0 {
-		return nil
-	}
-
-	// Collect chunk texts and keys.
-	texts := make([]string, len(chunks))
-	keys := make([]string, len(chunks))
-	for i, c := range chunks {
+	texts := make([]string, len(parts))
+	for i, c := range parts {
 		texts[i] = c.Content
-		keys[i] = fmt.Sprintf("%s:%d", doc.ID, i)
-	}
-
-	// Generate embeddings in batch.
-	embeds, err := idx.embedder.EmbedBatch(ctx, texts)
-	if err != nil {
-		return fmt.Errorf("generating embeddings: %w", err)
-	}
-
-	// Store chunks in SQLite and vectors in HNSW.
-	for i, c := range chunks {
-		chunk := &storage.Chunk{
-			ID:         keys[i],
+		chunks[i] = &storage.Chunk{
+			ID:         fmt.Sprintf("%s:%d", doc.ID, i),
 			DocumentID: doc.ID,
 			Content:    c.Content,
 			StartPos:   c.StartPos,
 			EndPos:     c.EndPos,
 		}
-		if err := idx.db.InsertChunk(ctx, chunk); err != nil {
-			return fmt.Errorf("inserting chunk: %w", err)
-		}
 	}
 
-	if err := idx.vectors.AddBatch(keys, embeds); err != nil {
-		return fmt.Errorf("adding vectors: %w", err)
+	batch := idx.embedBatch
+	if batch <= 0 {
+		batch = len(texts)
 	}
-	return nil
+	embeds := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += batch {
+		vecs, err := idx.embedder.EmbedBatch(ctx, texts[start:min(start+batch, len(texts))])
+		if err != nil {
+			return nil, nil, fmt.Errorf("generating embeddings: %w", err)
+		}
+		embeds = append(embeds, vecs...)
+	}
+	return chunks, embeds, nil
 }
 
-// Prune removes indexed documents whose backing file no longer exists. Only
-// filesystem-backed sources (markdown, pdf, email) are considered; browser and
-// clipboard entries are not file-backed and are left untouched. Callers should
-// SaveVectors afterwards to persist vector removals.
+// Prune removes indexed documents whose backing file no longer exists, and
+// those past their source's retention period. Only filesystem-backed sources
+// (markdown, pdf, email, data, reference, screenshot, project) are checked for missing
+// files; browser and clipboard entries are not file-backed and are left
+// untouched. Callers should SaveVectors afterwards to persist vector removals.
 func (idx *Indexer) Prune(ctx context.Context) (int, error) {
-	docs, err := idx.db.ListDocuments(ctx, "")
+	removed := 0
+	expired, err := idx.Expire(ctx)
+	for _, report := range expired {
+		removed += report.Removed
+	}
 	if err != nil {
-		return 0, err
+		return removed, err
 	}
 
-	removed := 0
+	docs, err := idx.db.ListDocumentSummaries(ctx, "", 0, 0)
+	if err != nil {
+		return removed, err
+	}
 	for _, doc := range docs {
-		if !isFileBackedSource(doc.Source) {
+		// Sections go with their document.
+		if !isFileBackedSource(doc.Source) || doc.IsSection() {
 			continue
 		}
 		if _, err := os.Stat(doc.Path); !os.IsNotExist(err) {
@@ -480,9 +1090,66 @@ func (idx *Indexer) Prune(ctx context.Context) (int, error) {
 	return removed, nil
 }
 
+// Expire removes the documents of every source that are past its retention
+// period, from the database, search index and vector store, and reports what
+// it removed per source. Callers should SaveVectors afterwards.
+func (idx *Indexer) Expire(ctx context.Context) ([]sources.TrimReport, error) {
+	var reports []sources.TrimReport
+	for _, src := range idx.sources {
+		exp, ok := src.(sources.Expiring)
+		if !ok {
+			continue
+		}
+		report, err := idx.expire(ctx, src.Name(), exp)
+		if report.Removed > 0 {
+			reports = append(reports, report)
+		}
+		if err != nil {
+			return reports, fmt.Errorf("expiring %s: %w", src.Name(), err)
+		}
+	}
+	return reports, nil
+}
+
+// expire removes the documents of source that exp reports as past their
+// retention period.
+func (idx *Indexer) expire(ctx context.Context, source storage.Source, exp sources.Expiring) (sources.TrimReport, error) {
+	report := sources.TrimReport{Source: source}
+	docs, err := idx.db.ListDocumentSummaries(ctx, source, 0, 0)
+	if err != nil {
+		return report, err
+	}
+	sizes := make(map[string]int64)
+	footprints, err := idx.db.ListDocumentFootprints(ctx, source)
+	if err != nil {
+		return report, err
+	}
+	for _, f := range footprints {
+		sizes[f.ID] = f.Size
+	}
+	for _, doc := range docs {
+		if doc.IsSection() || !exp.Expired(doc) {
+			continue
+		}
+		if err := idx.RemoveFile(ctx, doc.Path); err != nil {
+			if idx.progress != nil {
+				idx.progress.OnError(string(source), doc.Path, err)
+			}
+			continue
+		}
+		report.Removed++
+		report.Bytes += sizes[doc.ID]
+		if date := doc.Date(); date.After(report.Newest) {
+			report.Newest = date
+		}
+	}
+	return report, nil
+}
+
 func isFileBackedSource(s storage.Source) bool {
 	switch s {
-	case storage.SourceMarkdown, storage.SourcePDF, storage.SourceEmail:
+	case storage.SourceMarkdown, storage.SourcePDF, storage.SourceEmail, storage.SourceData, storage.SourceReference,
+		storage.SourceScreenshot, storage.SourceProject:
 		return true
 	default:
 		return false
@@ -505,12 +1172,24 @@ func (idx *Indexer) deleteDocumentVectors(ctx context.Context, docID string) err
 	return nil
 }
 
-// SaveVectors persists the vector store to disk. Call after indexing completes.
+// SaveVectors persists the vector store to disk. Call after indexing
+// completes. Documents whose vector changes it saves are then done and leave
+// the journal.
 func (idx *Indexer) SaveVectors() error {
-	if idx.vectors != nil {
-		return idx.vectors.Save()
+	if idx.vectors == nil {
+		return nil
 	}
-	return nil
+	idx.unsavedMu.Lock()
+	paths := idx.unsaved
+	idx.unsaved = nil
+	idx.unsavedMu.Unlock()
+	if err := idx.vectors.Save(); err != nil {
+		idx.unsavedMu.Lock()
+		idx.unsaved = append(paths, idx.unsaved...)
+		idx.unsavedMu.Unlock()
+		return err
+	}
+	return idx.db.EndJournal(context.Background(), paths...)
 }
 
 // NoopProgressReporter is a no-op progress reporter.
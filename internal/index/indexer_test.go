@@ -2,9 +2,11 @@ package index
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -156,7 +158,7 @@ A note in a subdirectory.
 	}
 
 	// Verify documents in database
-	docs, err := db.ListDocuments(ctx, storage.SourceMarkdown)
+	docs, err := db.ListDocuments(ctx, storage.SourceMarkdown, 0, 0)
 	if err != nil {
 		t.Fatalf("listing documents: %v", err)
 	}
@@ -355,6 +357,73 @@ func TestIndexer_Cancellation(t *testing.T) {
 	// Note: Cancellation may or may not return an error depending on timing
 }
 
+// cancellingReporter cancels indexing as the first file starts.
+type cancellingReporter struct {
+	testProgressReporter
+	cancel context.CancelFunc
+}
+
+func (p *cancellingReporter) OnProgress(source string, current, total int, path string) {
+	p.cancel()
+}
+
+func TestIndexer_CancelFinishesFileInProgress(t *testing.T) {
+	tmpDir := t.TempDir()
+	notesDir := filepath.Join(tmpDir, "notes")
+	mustIndexerTestSucceed(t, os.MkdirAll(notesDir, 0755))
+	for i := 0; i < 10; i++ {
+		path := filepath.Join(notesDir, fmt.Sprintf("note%d.md", i))
+		mustIndexerTestSucceed(t, os.WriteFile(path, []byte(fmt.Sprintf("# Note %d\n\nSome text.", i)), 0644))
+	}
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	mustIndexerTestSucceed(t, err)
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	mustIndexerTestSucceed(t, err)
+	defer closeIndexerTestSearch(t, searchIdx)
+	vectors, err := storage.NewVectorStore(filepath.Join(tmpDir, "vectors.graph"))
+	mustIndexerTestSucceed(t, err)
+	defer closeIndexerTestVectors(t, vectors)
+
+	cfg := &config.Config{
+		Sources: config.SourcesConfig{
+			Markdown: config.MarkdownSourceConfig{Enabled: true, Paths: []string{notesDir}, Extensions: []string{".md"}},
+			PDF:      config.PDFSourceConfig{Enabled: true, Paths: []string{filepath.Join(tmpDir, "docs")}},
+		},
+		Indexing: config.IndexingConfig{Workers: 1},
+	}
+	indexer := NewIndexer(db, searchIdx, vectors, &testEmbedder{}, cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	indexer.SetProgressReporter(&cancellingReporter{cancel: cancel})
+
+	stats, err := indexer.IndexAll(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("IndexAll() error = %v, want context.Canceled", err)
+	}
+	if stats.IndexedFiles != 1 || stats.Errors != 0 || stats.Embedded != 1 {
+		t.Errorf("stats = %+v, want the file in progress indexed and embedded", stats)
+	}
+	if !stats.Interrupted || stats.TotalFiles != 10 || stats.Remaining != 9 {
+		t.Errorf("Interrupted = %v, TotalFiles = %d, Remaining = %d; want true, 10 and 9", stats.Interrupted, stats.TotalFiles, stats.Remaining)
+	}
+	if len(stats.NotStarted) != 1 || stats.NotStarted[0] != storage.SourcePDF {
+		t.Errorf("NotStarted = %v, want [pdf]", stats.NotStarted)
+	}
+	if n, _ := db.CountDocuments(context.Background()); n != 1 || vectors.Len() == 0 {
+		t.Errorf("stored %d documents and %d vectors, want the finished note", n, vectors.Len())
+	}
+
+	// Indexing again resumes with the files left.
+	indexer.SetProgressReporter(nil)
+	stats, err = indexer.IndexAll(context.Background())
+	mustIndexerTestSucceed(t, err)
+	if n, _ := db.CountDocuments(context.Background()); n != 10 || stats.Embedded != 9 {
+		t.Errorf("after resuming: %d documents, %d embedded; want 10 and 9", n, stats.Embedded)
+	}
+}
+
 func TestIndexer_RemoveFileDeletesVectors(t *testing.T) {
 	tmpDir := t.TempDir()
 	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
@@ -421,7 +490,321 @@ func TestIndexer_RemoveFileDeletesVectors(t *testing.T) {
 	}
 }
 
-func TestIndexer_EmbedDocumentRemovesStaleVectors(t *testing.T) {
+func TestIndexer_Sections(t *testing.T) {
+	tmpDir := t.TempDir()
+	notesDir := filepath.Join(tmpDir, "notes")
+	mustIndexerTestSucceed(t, os.MkdirAll(notesDir, 0755))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	cfg := &config.Config{
+		Sources: config.SourcesConfig{Markdown: config.MarkdownSourceConfig{
+			Enabled:         true,
+			Paths:           []string{notesDir},
+			Extensions:      []string{".md"},
+			Sections:        true,
+			SectionMinChars: 50,
+		}},
+		Indexing: config.IndexingConfig{Workers: 1},
+	}
+	indexer := NewIndexer(db, searchIdx, nil, nil, cfg)
+	ctx := context.Background()
+
+	path := filepath.Join(notesDir, "plan.md")
+	write := func(content string) {
+		t.Helper()
+		mustIndexerTestSucceed(t, os.WriteFile(path, []byte(content), 0644))
+		mustIndexerTestSucceed(t, indexer.IndexFile(ctx, path))
+	}
+	write("# Plan\n\n## Goals\n\nShip the zeppelin.\n\n## Risks\n\nWeather delays.\n\n## Budget\n\nTwo million.\n")
+
+	note, err := db.GetDocumentByPath(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections, err := db.ListSections(ctx, note.ID, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sections) != 3 {
+		t.Fatalf("got %d sections, want 3", len(sections))
+	}
+	results, err := searchIdx.Search(ctx, "zeppelin", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	if len(ids) != 2 || !slices.Contains(ids, note.ID) || !slices.Contains(ids, note.ID+"#goals") {
+		t.Errorf("search hits = %v, want the note and its Goals section", ids)
+	}
+
+	// Dropping a heading removes its section.
+	write("# Plan\n\n## Goals\n\nShip the zeppelin, eventually.\n\n## Risks\n\nWeather delays.\n")
+	if sections, _ = db.ListSections(ctx, note.ID, path); len(sections) != 2 {
+		t.Errorf("got %d sections after removing a heading, want 2", len(sections))
+	}
+
+	// Prune keeps sections of notes that still exist.
+	if removed, err := indexer.Prune(ctx); err != nil || removed != 0 {
+		t.Errorf("Prune() = %d, %v; want nothing removed", removed, err)
+	}
+
+	if removed, err := indexer.RemoveDir(ctx, notesDir); err != nil || removed != 1 {
+		t.Errorf("RemoveDir() = %d, %v; want the one note removed", removed, err)
+	}
+	if n, _ := db.CountDocuments(ctx); n != 0 {
+		t.Errorf("%d documents left after removing the note, want 0", n)
+	}
+	if n, _ := searchIdx.Count(); n != 0 {
+		t.Errorf("%d search entries left after removing the note, want 0", n)
+	}
+}
+
+func TestIndexer_DetectsLanguage(t *testing.T) {
+	tmpDir := t.TempDir()
+	notesDir := filepath.Join(tmpDir, "notes")
+	mustIndexerTestSucceed(t, os.MkdirAll(notesDir, 0755))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	cfg := &config.Config{
+		Sources: config.SourcesConfig{Markdown: config.MarkdownSourceConfig{
+			Enabled:         true,
+			Paths:           []string{notesDir},
+			Extensions:      []string{".md"},
+			Sections:        true,
+			SectionMinChars: 50,
+		}},
+		Indexing: config.IndexingConfig{Workers: 1},
+	}
+	indexer := NewIndexer(db, searchIdx, nil, nil, cfg)
+	ctx := context.Background()
+
+	german := filepath.Join(notesDir, "urlaub.md")
+	english := filepath.Join(notesDir, "trip.md")
+	mustIndexerTestSucceed(t, os.WriteFile(german, []byte("# Urlaub\n\n## Packliste\n\nDie Liste ist noch nicht fertig, aber der Koffer steht.\n\n## Route\n\nWir fahren mit dem Zug und dann mit dem Rad.\n"), 0644))
+	mustIndexerTestSucceed(t, os.WriteFile(english, []byte("# Trip\n\nThe list is not done yet, but the bag is ready and the tickets are booked.\n"), 0644))
+	if _, err := indexer.IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll() error = %v", err)
+	}
+
+	for path, want := range map[string]string{german: "de", english: "en"} {
+		doc, err := db.GetDocumentByPath(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc.Metadata["lang"] != want {
+			t.Errorf("%s: lang = %q, want %q", filepath.Base(path), doc.Metadata["lang"], want)
+		}
+	}
+	if doc, _ := db.GetDocumentByPath(ctx, english); doc.WordCount() != storage.CountWords(doc.Content) || doc.WordCount() < 16 {
+		t.Errorf("trip.md: words = %d, want the words of its content", doc.WordCount())
+	}
+
+	docs, err := db.FindByMetadata(ctx, "lang", "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 3 {
+		t.Errorf("documents with lang de = %d, want the note and its 2 sections", len(docs))
+	}
+}
+
+func TestIndexer_DataRecords(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataDir := filepath.Join(tmpDir, "data")
+	mustIndexerTestSucceed(t, os.MkdirAll(dataDir, 0755))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	cfg := &config.Config{
+		Sources: config.SourcesConfig{Data: config.DataSourceConfig{
+			Enabled:    true,
+			Paths:      []string{dataDir},
+			Extensions: []string{".csv"},
+			MaxRecords: 100,
+		}},
+		Indexing: config.IndexingConfig{Workers: 1},
+	}
+	indexer := NewIndexer(db, searchIdx, nil, nil, cfg)
+	ctx := context.Background()
+
+	path := filepath.Join(dataDir, "trips.csv")
+	mustIndexerTestSucceed(t, os.WriteFile(path, []byte("city,note\nLisbon,tram 28\nOslo,zeppelin museum\n"), 0644))
+	stats, err := indexer.IndexAll(ctx)
+	if err != nil || stats.IndexedFiles != 1 {
+		t.Fatalf("IndexAll() = %+v, %v; want one file indexed", stats, err)
+	}
+
+	file, err := db.GetDocumentByPath(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := searchIdx.Search(ctx, "zeppelin", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	if len(ids) != 2 || !slices.Contains(ids, file.ID) || !slices.Contains(ids, file.ID+"#row-2") {
+		t.Errorf("search hits = %v, want the file and its Oslo row", ids)
+	}
+	row, err := db.GetDocument(ctx, file.ID+"#row-2")
+	if err != nil || row.Title != "trips.csv » Oslo" {
+		t.Errorf("row = %+v, %v; want it titled by its first value", row, err)
+	}
+
+	mustIndexerTestSucceed(t, indexer.RemoveFile(ctx, path))
+	if n, _ := db.CountDocuments(ctx); n != 0 {
+		t.Errorf("%d documents left after removing the file, want 0", n)
+	}
+}
+
+func TestIndexer_ExpiresScreenshots(t *testing.T) {
+	tmpDir := t.TempDir()
+	shotsDir := filepath.Join(tmpDir, "shots")
+	mustIndexerTestSucceed(t, os.MkdirAll(shotsDir, 0755))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	// The "OCR" prints the file, so each screenshot holds its own text.
+	recent := filepath.Join(shotsDir, "recent.png")
+	old := filepath.Join(shotsDir, "old.png")
+	mustIndexerTestSucceed(t, os.WriteFile(recent, []byte("invoice total"), 0644))
+	mustIndexerTestSucceed(t, os.WriteFile(old, []byte("boarding pass"), 0644))
+	longAgo := time.Now().AddDate(0, 0, -60)
+	mustIndexerTestSucceed(t, os.Chtimes(old, longAgo, longAgo))
+
+	newIndexer := func(retentionDays int) *Indexer {
+		return NewIndexer(db, searchIdx, nil, nil, &config.Config{
+			Sources: config.SourcesConfig{Screenshots: config.ScreenshotsSourceConfig{
+				Enabled:       true,
+				Paths:         []string{shotsDir},
+				OCRCommand:    "cat",
+				RetentionDays: retentionDays,
+			}},
+			Indexing: config.IndexingConfig{Workers: 1},
+		})
+	}
+	ctx := context.Background()
+
+	if _, err := newIndexer(0).IndexAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := db.CountDocuments(ctx); n != 2 {
+		t.Fatalf("%d documents without a retention period, want 2", n)
+	}
+
+	// With a 30 day retention period the old screenshot is dropped.
+	indexer := newIndexer(30)
+	if removed, err := indexer.Prune(ctx); err != nil || removed != 1 {
+		t.Errorf("Prune() = %d, %v; want the old screenshot removed", removed, err)
+	}
+	if _, err := indexer.IndexAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := db.GetDocumentByPath(ctx, recent)
+	if err != nil || doc.Content != "invoice total" || doc.Metadata["image"] != recent {
+		t.Errorf("recent screenshot = %+v, %v", doc, err)
+	}
+	if doc, _ := db.GetDocumentByPath(ctx, old); doc != nil {
+		t.Error("expired screenshot was indexed again")
+	}
+}
+
+func TestIndexer_ExpiresClips(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	ctx := context.Background()
+	longAgo := time.Now().AddDate(0, 0, -60).Truncate(time.Second)
+	for _, doc := range []*storage.Document{
+		{ID: "old", Source: storage.SourceClipboard, Path: "clipboard:old", Title: "old", Content: "old clip", ModifiedAt: longAgo},
+		{ID: "new", Source: storage.SourceClipboard, Path: "clipboard:new", Title: "new", Content: "new clip", ModifiedAt: time.Now()},
+		{ID: "note", Source: storage.SourceMarkdown, Path: "/notes/old.md", Title: "note", Content: "old note", ModifiedAt: longAgo},
+	} {
+		doc.ContentHash, doc.IndexedAt = doc.ID, doc.ModifiedAt
+		mustIndexerTestSucceed(t, db.InsertDocument(ctx, doc))
+		mustIndexerTestSucceed(t, searchIdx.Index(ctx, doc))
+	}
+
+	indexer := NewIndexer(db, searchIdx, nil, nil, &config.Config{
+		Sources:  config.SourcesConfig{Clipboard: config.ClipboardSourceConfig{Enabled: true, RetentionDays: 30}},
+		Indexing: config.IndexingConfig{Workers: 1},
+	})
+	report, err := indexer.Expire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 1 || report[0].Source != storage.SourceClipboard || report[0].Removed != 1 ||
+		report[0].Bytes != int64(len("old clip")) || !report[0].Newest.Equal(longAgo) {
+		t.Errorf("Expire() = %+v, want the old clip removed", report)
+	}
+	for id, want := range map[string]bool{"old": false, "new": true, "note": true} {
+		doc, _ := db.GetDocument(ctx, id)
+		if got := doc != nil; got != want {
+			t.Errorf("%s kept = %v, want %v", id, got, want)
+		}
+	}
+	if results, _ := searchIdx.Search(ctx, "clip", 10); len(results) != 1 {
+		t.Errorf("search after expiry found %d clips, want 1", len(results))
+	}
+	if report, err := indexer.Expire(ctx); err != nil || len(report) != 0 {
+		t.Errorf("second Expire() = %+v, %v; want nothing left to expire", report, err)
+	}
+}
+
+func TestIndexer_StoreDocumentRemovesStaleVectors(t *testing.T) {
 	tmpDir := t.TempDir()
 	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
 	if err != nil {
@@ -481,7 +864,7 @@ func TestIndexer_EmbedDocumentRemovesStaleVectors(t *testing.T) {
 		t.Fatalf("expected 1 stale vector before embed, got %d", vectors.Len())
 	}
 
-	mustIndexerTestSucceed(t, indexer.embedDocument(ctx, doc))
+	mustIndexerTestSucceed(t, indexer.storeDocument(ctx, doc, true))
 
 	chunks, err := db.GetChunksByDocument(ctx, doc.ID)
 	if err != nil {
@@ -490,6 +873,30 @@ func TestIndexer_EmbedDocumentRemovesStaleVectors(t *testing.T) {
 	if len(chunks) != vectors.Len() {
 		t.Fatalf("chunks=%d vectors=%d, expected equality after re-embed", len(chunks), vectors.Len())
 	}
+
+	// A failed re-embed still stores the new content but drops the chunks
+	// and vectors that no longer match it.
+	indexer.embedder = &failingEmbedder{}
+	doc.Content = "newer content"
+	err = indexer.storeDocument(ctx, doc, true)
+	var embedErr *embeddingError
+	if !errors.As(err, &embedErr) {
+		t.Fatalf("storeDocument() error = %v, want *embeddingError", err)
+	}
+	stored, err := db.GetDocument(ctx, doc.ID)
+	if err != nil {
+		t.Fatalf("loading document: %v", err)
+	}
+	if stored.Content != "newer content" {
+		t.Errorf("stored content = %q, want the new content", stored.Content)
+	}
+	chunks, err = db.GetChunksByDocument(ctx, doc.ID)
+	if err != nil {
+		t.Fatalf("loading chunks: %v", err)
+	}
+	if len(chunks) != 0 || vectors.Len() != 0 {
+		t.Errorf("chunks=%d vectors=%d after failed embed, want 0", len(chunks), vectors.Len())
+	}
 }
 
 func TestIndexer_IndexFile_UsesStatPathWithoutScan(t *testing.T) {
@@ -537,6 +944,50 @@ func TestIndexer_IndexFile_UsesStatPathWithoutScan(t *testing.T) {
 	}
 }
 
+func TestIndexer_IndexFile_KeepsIDWhenRootMoves(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldRoot := filepath.Join(tmpDir, "laptop", "notes")
+	newRoot := filepath.Join(tmpDir, "desktop", "notes")
+	for _, dir := range []string{oldRoot, newRoot} {
+		mustIndexerTestSucceed(t, os.MkdirAll(dir, 0o755))
+		mustIndexerTestSucceed(t, os.WriteFile(filepath.Join(dir, "note.md"), []byte("# note"), 0o644))
+	}
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	ctx := context.Background()
+	src := &mockSource{name: storage.SourceMarkdown}
+	idx := &Indexer{db: db, search: searchIdx, sources: []sources.Source{src}}
+
+	mustIndexerTestSucceed(t, db.SetPathRoots(ctx, map[string]string{"notes": oldRoot}))
+	src.matchPath = filepath.Join(oldRoot, "note.md")
+	mustIndexerTestSucceed(t, idx.IndexFile(ctx, src.matchPath))
+
+	mustIndexerTestSucceed(t, db.SetPathRoots(ctx, map[string]string{"notes": newRoot}))
+	src.matchPath = filepath.Join(newRoot, "note.md")
+	mustIndexerTestSucceed(t, idx.IndexFile(ctx, src.matchPath))
+
+	count, err := db.CountDocuments(ctx)
+	mustIndexerTestSucceed(t, err)
+	if count != 1 {
+		t.Errorf("CountDocuments() = %d, want 1 after re-indexing the moved root", count)
+	}
+	doc, err := db.GetDocumentByPath(ctx, src.matchPath)
+	mustIndexerTestSucceed(t, err)
+	if doc.ID != "doc:"+filepath.Join(oldRoot, "note.md") {
+		t.Errorf("document ID = %q, want the original ID kept", doc.ID)
+	}
+}
+
 func TestIndexer_IndexFile_FallsBackToSourceScan(t *testing.T) {
 	tmpDir := t.TempDir()
 	virtualPath := "clipboard:test"
@@ -582,6 +1033,112 @@ func TestIndexer_IndexFile_FallsBackToSourceScan(t *testing.T) {
 	}
 }
 
+func TestIndexer_WatchPathsAndPollers(t *testing.T) {
+	cfg := &config.Config{
+		Sources: config.SourcesConfig{
+			Markdown:  config.MarkdownSourceConfig{Enabled: true, Paths: []string{"/notes"}, Extensions: []string{".md"}},
+			Email:     config.EmailSourceConfig{Enabled: true, Paths: []string{"/mail"}},
+			Clipboard: config.ClipboardSourceConfig{Enabled: true},
+		},
+		Indexing: config.IndexingConfig{Workers: 1},
+	}
+	idx := NewIndexer(nil, nil, nil, nil, cfg)
+
+	paths := idx.WatchPaths()
+	if len(paths) != 2 || paths[0] != "/notes" || paths[1] != "/mail" {
+		t.Errorf("WatchPaths() = %v, want [/notes /mail]", paths)
+	}
+
+	pollers := idx.Pollers()
+	if len(pollers) != 1 || pollers[0].Name() != storage.SourceClipboard {
+		t.Errorf("Pollers() = %v, want the clipboard source", pollers)
+	}
+}
+
+func TestIndexer_PollSourceSkipsUnchanged(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	src := &mockSource{
+		name: storage.SourceClipboard,
+		scanFiles: []sources.FileInfo{
+			{Path: "clipboard:a", ModifiedAt: time.Now().Add(-time.Minute).Unix()},
+		},
+	}
+	idx := &Indexer{db: db, search: searchIdx, sources: []sources.Source{src}}
+
+	ctx := context.Background()
+	n, err := idx.PollSource(ctx, src)
+	if err != nil {
+		t.Fatalf("PollSource() error = %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("first poll indexed %d, want 1", n)
+	}
+
+	n, err = idx.PollSource(ctx, src)
+	if err != nil {
+		t.Fatalf("PollSource() error = %v", err)
+	}
+	if n != 0 || src.parseCalls != 1 {
+		t.Errorf("second poll indexed %d (parseCalls=%d), want 0 (1)", n, src.parseCalls)
+	}
+}
+
+// revisableSource is a mockSource whose documents can be reported outdated.
+type revisableSource struct {
+	*mockSource
+	outdated bool
+}
+
+func (r *revisableSource) Outdated(ctx context.Context, path string, doc *storage.Document) bool {
+	return r.outdated
+}
+
+func TestIndexer_ReindexesOutdatedDocuments(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	mustIndexerTestSucceed(t, err)
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	mustIndexerTestSucceed(t, err)
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	src := &revisableSource{mockSource: &mockSource{
+		name:      storage.SourceMarkdown,
+		scanFiles: []sources.FileInfo{{Path: "/notes/a.md", ModifiedAt: time.Now().Add(-time.Hour).Unix()}},
+	}}
+	idx := &Indexer{db: db, search: searchIdx, sources: []sources.Source{src}, workers: 1}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := idx.IndexAll(ctx)
+		mustIndexerTestSucceed(t, err)
+	}
+	if src.parseCalls != 1 {
+		t.Fatalf("parseCalls = %d, want 1 while the document is current", src.parseCalls)
+	}
+
+	// The file's mtime hasn't moved, but the source has something newer.
+	src.outdated = true
+	_, err = idx.IndexAll(ctx)
+	mustIndexerTestSucceed(t, err)
+	if src.parseCalls != 2 {
+		t.Errorf("parseCalls = %d, want the outdated document parsed again", src.parseCalls)
+	}
+}
+
 // testProgressReporter tracks progress calls for testing.
 type testProgressReporter struct {
 	mu        sync.Mutex
@@ -635,10 +1192,216 @@ func (e *testEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]floa
 
 func (e *testEmbedder) Dimensions() int { return 2 }
 
+type failingEmbedder struct{ testEmbedder }
+
+func (e *failingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, fmt.Errorf("embedding service unavailable")
+}
+
+func TestIndexer_RecordsAndRetriesFailures(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "broken.md")
+	mustIndexerTestSucceed(t, os.WriteFile(filePath, []byte("# broken"), 0o644))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	src := &mockSource{
+		name:      storage.SourceMarkdown,
+		matchPath: filePath,
+		scanFiles: []sources.FileInfo{{Path: filePath, ModifiedAt: time.Now().Unix()}},
+		parseErr:  fmt.Errorf("invalid frontmatter"),
+	}
+	idx := &Indexer{db: db, search: searchIdx, sources: []sources.Source{src}, workers: 1}
+	ctx := context.Background()
+
+	stats, err := idx.IndexAll(ctx)
+	if err != nil {
+		t.Fatalf("IndexAll() error = %v", err)
+	}
+	if stats.Errors != 1 {
+		t.Fatalf("Errors = %d, want 1", stats.Errors)
+	}
+
+	// A failure for a file that has since been deleted is dropped on retry.
+	gone := filepath.Join(tmpDir, "gone.md")
+	mustIndexerTestSucceed(t, db.RecordIndexError(ctx, &storage.IndexError{Path: gone, Source: storage.SourceMarkdown, Error: "boom"}))
+
+	failed, err := db.ListIndexErrors(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 2 {
+		t.Fatalf("recorded %d failures, want 2", len(failed))
+	}
+
+	// Still failing: the error stays recorded with the new message.
+	src.parseErr = fmt.Errorf("still invalid")
+	stats, err = idx.RetryFailed(ctx)
+	if err != nil {
+		t.Fatalf("RetryFailed() error = %v", err)
+	}
+	if stats.Errors != 1 || stats.IndexedFiles != 0 {
+		t.Fatalf("RetryFailed() stats = %+v, want 1 error", stats)
+	}
+	failed, err = db.ListIndexErrors(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].Path != filePath || !strings.Contains(failed[0].Error, "still invalid") {
+		t.Fatalf("failures after retry = %+v", failed)
+	}
+
+	// Fixed: the retry indexes the file and clears the failure.
+	src.parseErr = nil
+	stats, err = idx.RetryFailed(ctx)
+	if err != nil {
+		t.Fatalf("RetryFailed() error = %v", err)
+	}
+	if stats.IndexedFiles != 1 || stats.Errors != 0 {
+		t.Fatalf("RetryFailed() stats = %+v, want 1 indexed", stats)
+	}
+	failed, err = db.ListIndexErrors(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 0 {
+		t.Fatalf("failures after successful retry = %+v", failed)
+	}
+	if _, err := db.GetDocumentByPath(ctx, filePath); err != nil {
+		t.Fatalf("document not indexed after retry: %v", err)
+	}
+}
+
+func TestIndexer_RetriesFailedEmbeddings(t *testing.T) {
+	tmpDir := t.TempDir()
+	notesDir := filepath.Join(tmpDir, "notes")
+	mustIndexerTestSucceed(t, os.MkdirAll(notesDir, 0755))
+	filePath := filepath.Join(notesDir, "note.md")
+	mustIndexerTestSucceed(t, os.WriteFile(filePath, []byte("# Note\n\nSome text to embed."), 0644))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	mustIndexerTestSucceed(t, err)
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	mustIndexerTestSucceed(t, err)
+	defer closeIndexerTestSearch(t, searchIdx)
+	vectors, err := storage.NewVectorStore(filepath.Join(tmpDir, "vectors.graph"))
+	mustIndexerTestSucceed(t, err)
+	defer closeIndexerTestVectors(t, vectors)
+
+	cfg := &config.Config{
+		Sources: config.SourcesConfig{
+			Markdown: config.MarkdownSourceConfig{Enabled: true, Paths: []string{notesDir}, Extensions: []string{".md"}},
+		},
+		Indexing: config.IndexingConfig{Workers: 1},
+	}
+	indexer := NewIndexer(db, searchIdx, vectors, &failingEmbedder{}, cfg)
+	ctx := context.Background()
+
+	// The document is stored, but the failure stays listed and the file
+	// doesn't count as indexed.
+	stats, err := indexer.IndexAll(ctx)
+	mustIndexerTestSucceed(t, err)
+	if stats.IndexedFiles != 0 || stats.Errors != 1 || stats.Embedded != 0 {
+		t.Fatalf("stats = %+v, want 1 error and nothing indexed", stats)
+	}
+	failed, err := db.ListIndexErrors(ctx)
+	mustIndexerTestSucceed(t, err)
+	if len(failed) != 1 || failed[0].Path != filePath || !strings.Contains(failed[0].Error, "embedding") {
+		t.Fatalf("failures = %+v, want the embedding failure", failed)
+	}
+	if _, err := db.GetDocumentByPath(ctx, filePath); err != nil {
+		t.Fatalf("document not stored after failed embedding: %v", err)
+	}
+
+	// The next run embeds the unchanged file and clears the failure.
+	indexer.embedder = &testEmbedder{}
+	stats, err = indexer.IndexAll(ctx)
+	mustIndexerTestSucceed(t, err)
+	if stats.IndexedFiles != 1 || stats.Errors != 0 || stats.Embedded != 1 {
+		t.Fatalf("stats = %+v, want the file embedded", stats)
+	}
+	failed, err = db.ListIndexErrors(ctx)
+	mustIndexerTestSucceed(t, err)
+	if len(failed) != 0 {
+		t.Errorf("failures after embedding = %+v", failed)
+	}
+	doc, err := db.GetDocumentByPath(ctx, filePath)
+	mustIndexerTestSucceed(t, err)
+	chunks, err := db.GetChunksByDocument(ctx, doc.ID)
+	mustIndexerTestSucceed(t, err)
+	if len(chunks) == 0 || vectors.Len() != len(chunks) {
+		t.Errorf("chunks=%d vectors=%d, want the document embedded", len(chunks), vectors.Len())
+	}
+}
+
+type warningRecorder struct {
+	NoopProgressReporter
+	warnings []string
+}
+
+func (w *warningRecorder) OnWarning(source string, path string, warnings []string) {
+	w.warnings = append(w.warnings, warnings...)
+}
+
+func TestIndexer_IndexesPartiallyParsedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	src := &mockSource{
+		name:      storage.SourceEmail,
+		scanFiles: []sources.FileInfo{{Path: "/mail/inbox.mbox", ModifiedAt: time.Now().Unix()}},
+		partial:   &sources.PartialError{Warnings: []string{"skipped 2 malformed messages"}},
+	}
+	reporter := &warningRecorder{}
+	idx := &Indexer{db: db, search: searchIdx, sources: []sources.Source{src}, workers: 1, progress: reporter}
+	ctx := context.Background()
+
+	stats, err := idx.IndexAll(ctx)
+	if err != nil {
+		t.Fatalf("IndexAll() error = %v", err)
+	}
+	if stats.IndexedFiles != 1 || stats.Errors != 0 || stats.Warnings != 1 {
+		t.Fatalf("stats = %+v, want 1 indexed with 1 warning", stats)
+	}
+	if len(reporter.warnings) != 1 {
+		t.Errorf("reported warnings = %v", reporter.warnings)
+	}
+	if _, err := db.GetDocumentByPath(ctx, "/mail/inbox.mbox"); err != nil {
+		t.Errorf("partial document not stored: %v", err)
+	}
+	if failed, _ := db.ListIndexErrors(ctx); len(failed) != 0 {
+		t.Errorf("partial parse recorded as failure: %+v", failed)
+	}
+}
+
 type mockSource struct {
 	name      storage.Source
 	matchPath string
 	scanFiles []sources.FileInfo
+	parseErr  error
+	partial   *sources.PartialError
 
 	scanCalls  int
 	parseCalls int
@@ -666,12 +1429,15 @@ func (m *mockSource) MatchesPath(path string) bool {
 func (m *mockSource) Parse(ctx context.Context, file sources.FileInfo) (*storage.Document, error) {
 	m.parseCalls++
 	m.lastParsed = file
+	if m.parseErr != nil {
+		return nil, m.parseErr
+	}
 	if file.Path == "" {
 		return nil, fmt.Errorf("empty path")
 	}
 
 	now := time.Now().UTC()
-	return &storage.Document{
+	doc := &storage.Document{
 		ID:          "doc:" + file.Path,
 		Source:      m.name,
 		Path:        file.Path,
@@ -681,5 +1447,161 @@ func (m *mockSource) Parse(ctx context.Context, file sources.FileInfo) (*storage
 		ContentHash: "hash:" + file.Path,
 		IndexedAt:   now,
 		ModifiedAt:  now,
-	}, nil
+	}
+	if m.partial != nil {
+		return doc, m.partial
+	}
+	return doc, nil
+}
+
+type eventRecorder struct {
+	mu       sync.Mutex
+	indexed  []string
+	removed  []string
+	complete []*Stats
+}
+
+func (r *eventRecorder) OnDocumentIndexed(doc *storage.Document) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.indexed = append(r.indexed, doc.Path)
+}
+
+func (r *eventRecorder) OnDocumentRemoved(doc *storage.Document) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.removed = append(r.removed, doc.Path)
+}
+
+func (r *eventRecorder) OnIndexComplete(stats *Stats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.complete = append(r.complete, stats)
+}
+
+func TestIndexer_ReportsEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	notesDir := filepath.Join(tmpDir, "notes")
+	mustIndexerTestSucceed(t, os.MkdirAll(notesDir, 0755))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	indexer := NewIndexer(db, searchIdx, nil, nil, &config.Config{
+		Sources: config.SourcesConfig{Markdown: config.MarkdownSourceConfig{
+			Enabled:         true,
+			Paths:           []string{notesDir},
+			Extensions:      []string{".md"},
+			Sections:        true,
+			SectionMinChars: 10,
+		}},
+		Indexing: config.IndexingConfig{Workers: 2},
+	})
+	events := &eventRecorder{}
+	indexer.SetEventHandler(events)
+	ctx := context.Background()
+
+	path := filepath.Join(notesDir, "plan.md")
+	mustIndexerTestSucceed(t, os.WriteFile(path, []byte("# Plan\n\n## Goals\n\nShip it.\n\n## Risks\n\nRain.\n"), 0644))
+	if _, err := indexer.IndexAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(events.indexed) != 1 || events.indexed[0] != path {
+		t.Errorf("indexed events = %v, want the note only, not its sections", events.indexed)
+	}
+	if len(events.complete) != 1 || events.complete[0].IndexedFiles != 1 {
+		t.Errorf("complete events = %v, want one with the run's stats", events.complete)
+	}
+
+	// Unchanged files aren't reported again.
+	if _, err := indexer.IndexAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(events.indexed) != 1 || len(events.complete) != 2 {
+		t.Errorf("after a second run: %d indexed, %d complete events; want 1 and 2", len(events.indexed), len(events.complete))
+	}
+
+	mustIndexerTestSucceed(t, indexer.RemoveFile(ctx, path))
+	if len(events.removed) != 1 || events.removed[0] != path {
+		t.Errorf("removed events = %v, want the note", events.removed)
+	}
+}
+
+type tagProcessor struct {
+	fail bool
+}
+
+func (p *tagProcessor) Name() string { return "tagger" }
+
+func (p *tagProcessor) Process(ctx context.Context, doc *storage.Document) error {
+	if p.fail {
+		return fmt.Errorf("tagger crashed")
+	}
+	doc.Metadata["tags"] = "processed"
+	return nil
+}
+
+func TestIndexer_RunsPostProcessors(t *testing.T) {
+	tmpDir := t.TempDir()
+	notesDir := filepath.Join(tmpDir, "notes")
+	mustIndexerTestSucceed(t, os.MkdirAll(notesDir, 0755))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	indexer := NewIndexer(db, searchIdx, nil, nil, &config.Config{
+		Sources: config.SourcesConfig{Markdown: config.MarkdownSourceConfig{
+			Enabled:    true,
+			Paths:      []string{notesDir},
+			Extensions: []string{".md"},
+		}},
+		Indexing: config.IndexingConfig{Workers: 1},
+	})
+	processor := &tagProcessor{}
+	indexer.SetPostProcessors([]PostProcessor{processor})
+	warnings := &warningRecorder{}
+	indexer.SetProgressReporter(warnings)
+	ctx := context.Background()
+
+	path := filepath.Join(notesDir, "go.md")
+	mustIndexerTestSucceed(t, os.WriteFile(path, []byte("# Go\n\nGoroutines.\n"), 0644))
+	mustIndexerTestSucceed(t, indexer.IndexFile(ctx, path))
+	doc, err := db.GetDocumentByPath(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Metadata["tags"] != "processed" {
+		t.Errorf("tags = %q, want the post-processor's", doc.Metadata["tags"])
+	}
+
+	// A failing post-processor is a warning; the document is still indexed.
+	processor.fail = true
+	later := time.Now().Add(time.Hour)
+	mustIndexerTestSucceed(t, os.Chtimes(path, later, later))
+	stats, err := indexer.IndexAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.IndexedFiles != 1 || stats.Warnings != 1 || stats.Errors != 0 {
+		t.Errorf("stats = %+v, want the note indexed with a warning", stats)
+	}
+	if len(warnings.warnings) != 1 || warnings.warnings[0] != "post-processor tagger: tagger crashed" {
+		t.Errorf("warnings = %q", warnings.warnings)
+	}
 }
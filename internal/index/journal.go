@@ -0,0 +1,112 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// beginJournal records that the document at path is about to be written or
+// removed. The journal is a safety net, so failing to write it only means
+// a crash right now can't be recovered from.
+func (idx *Indexer) beginJournal(ctx context.Context, source storage.Source, path, docID string) {
+	_ = idx.db.BeginJournal(context.WithoutCancel(ctx), &storage.JournalEntry{
+		Path:       path,
+		Source:     source,
+		DocumentID: docID,
+	})
+}
+
+// endJournal marks the work on path as finished. When vectorsChanged is set
+// and there is a vector store, the entry stays until SaveVectors has written
+// the changes to disk.
+func (idx *Indexer) endJournal(ctx context.Context, path string, vectorsChanged bool) {
+	if vectorsChanged && idx.vectors != nil {
+		idx.unsavedMu.Lock()
+		idx.unsaved = append(idx.unsaved, path)
+		idx.unsavedMu.Unlock()
+		return
+	}
+	_ = idx.db.EndJournal(context.WithoutCancel(ctx), path)
+}
+
+// Recover redoes the documents that an earlier run was writing or removing
+// when it stopped, as recorded in the journal: each is indexed again from
+// its file, or removed when the file is gone. Documents that fail again are
+// recorded as index errors instead. IndexAll calls Recover before anything
+// else; it returns the number of documents recovered.
+func (idx *Indexer) Recover(ctx context.Context) (int, error) {
+	entries, err := idx.db.ListJournal(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Entries waiting for this indexer's SaveVectors aren't interrupted.
+	idx.unsavedMu.Lock()
+	pending := make(map[string]bool, len(idx.unsaved))
+	for _, path := range idx.unsaved {
+		pending[path] = true
+	}
+	idx.unsavedMu.Unlock()
+	var interrupted []*storage.JournalEntry
+	for _, e := range entries {
+		if !pending[e.Path] {
+			interrupted = append(interrupted, e)
+		}
+	}
+	if len(interrupted) == 0 {
+		return 0, nil
+	}
+
+	if idx.progress != nil {
+		idx.progress.OnStart("recover", len(interrupted))
+	}
+	recovered, failed := 0, 0
+	for i, e := range interrupted {
+		if ctx.Err() != nil {
+			return recovered, ctx.Err()
+		}
+		if idx.progress != nil {
+			idx.progress.OnProgress("recover", i+1, len(interrupted), e.Path)
+		}
+		if err := idx.recoverEntry(ctx, e); err != nil {
+			// The failure is recorded for 'mindcli errors', which takes
+			// over from the journal.
+			_ = idx.db.EndJournal(ctx, e.Path)
+			failed++
+			continue
+		}
+		recovered++
+	}
+	if idx.progress != nil {
+		idx.progress.OnComplete("recover", recovered, failed)
+	}
+	return recovered, nil
+}
+
+// recoverEntry redoes the work of one interrupted journal entry.
+func (idx *Indexer) recoverEntry(ctx context.Context, e *storage.JournalEntry) error {
+	if isFileBackedSource(e.Source) {
+		if _, err := os.Stat(e.Path); os.IsNotExist(err) {
+			err := idx.RemoveFile(ctx, e.Path)
+			if errors.Is(err, storage.ErrNotFound) {
+				// The database row went first; drop what's left of the
+				// document in the search index.
+				if e.DocumentID != "" {
+					err = idx.search.Delete(ctx, e.DocumentID)
+				} else {
+					err = nil
+				}
+				if err == nil {
+					err = idx.db.EndJournal(ctx, e.Path)
+				}
+			}
+			if err != nil {
+				idx.recordFailure(ctx, e.Source, e.Path, err)
+			}
+			return err
+		}
+	}
+	return idx.IndexFile(ctx, e.Path)
+}
@@ -0,0 +1,129 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestIndexer_RecoversInterruptedDocuments(t *testing.T) {
+	tmpDir := t.TempDir()
+	notesDir := filepath.Join(tmpDir, "notes")
+	mustIndexerTestSucceed(t, os.MkdirAll(notesDir, 0o755))
+	kept := filepath.Join(notesDir, "kept.md")
+	gone := filepath.Join(notesDir, "gone.md")
+	mustIndexerTestSucceed(t, os.WriteFile(kept, []byte("# Kept\n\nStill here."), 0o644))
+	mustIndexerTestSucceed(t, os.WriteFile(gone, []byte("# Gone\n\nAbout to go."), 0o644))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	cfg := &config.Config{
+		Sources: config.SourcesConfig{
+			Markdown: config.MarkdownSourceConfig{Enabled: true, Paths: []string{notesDir}, Extensions: []string{".md"}},
+		},
+		Indexing: config.IndexingConfig{Workers: 1},
+	}
+	indexer := NewIndexer(db, searchIdx, nil, nil, cfg)
+	ctx := context.Background()
+	if _, err := indexer.IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll() error = %v", err)
+	}
+	if entries, _ := db.ListJournal(ctx); len(entries) != 0 {
+		t.Fatalf("journal after a clean run = %+v, want empty", entries)
+	}
+
+	// Simulate a crash while kept.md was being written, after the database
+	// but before the search index, and while gone.md was being removed.
+	keptDoc, err := db.GetDocumentByPath(ctx, kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goneDoc, err := db.GetDocumentByPath(ctx, gone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustIndexerTestSucceed(t, searchIdx.Delete(ctx, keptDoc.ID))
+	mustIndexerTestSucceed(t, db.BeginJournal(ctx, &storage.JournalEntry{Path: kept, Source: storage.SourceMarkdown, DocumentID: keptDoc.ID}))
+	mustIndexerTestSucceed(t, db.DeleteDocument(ctx, goneDoc.ID))
+	mustIndexerTestSucceed(t, db.BeginJournal(ctx, &storage.JournalEntry{Path: gone, Source: storage.SourceMarkdown, DocumentID: goneDoc.ID}))
+	mustIndexerTestSucceed(t, os.Remove(gone))
+
+	stats, err := indexer.IndexAll(ctx)
+	if err != nil {
+		t.Fatalf("IndexAll() error = %v", err)
+	}
+	if stats.Recovered != 2 {
+		t.Errorf("Recovered = %d, want 2", stats.Recovered)
+	}
+	ids, err := searchIdx.IDs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != keptDoc.ID {
+		t.Errorf("search index = %v, want only %s", ids, keptDoc.ID)
+	}
+	if entries, _ := db.ListJournal(ctx); len(entries) != 0 {
+		t.Errorf("journal after recovery = %+v, want empty", entries)
+	}
+}
+
+func TestIndexer_JournalWaitsForSavedVectors(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	vectors, err := storage.NewVectorStore(filepath.Join(tmpDir, "vectors.graph"))
+	if err != nil {
+		t.Fatalf("creating vector store: %v", err)
+	}
+	defer closeIndexerTestVectors(t, vectors)
+
+	indexer := NewIndexer(db, searchIdx, vectors, &testEmbedder{}, &config.Config{Indexing: config.IndexingConfig{Workers: 1}})
+	ctx := context.Background()
+	doc := &storage.Document{
+		ID:      "doc",
+		Source:  storage.SourceMarkdown,
+		Path:    filepath.Join(tmpDir, "doc.md"),
+		Title:   "Doc",
+		Content: "some content",
+	}
+	mustIndexerTestSucceed(t, indexer.storeDocument(ctx, doc, true))
+
+	// The vectors are only in memory, so the document isn't done yet, but
+	// Recover leaves it alone since this indexer will still save them.
+	if entries, _ := db.ListJournal(ctx); len(entries) != 1 {
+		t.Fatalf("journal before SaveVectors = %+v, want the document", entries)
+	}
+	if n, err := indexer.Recover(ctx); err != nil || n != 0 {
+		t.Errorf("Recover() = %d, %v; want nothing to recover", n, err)
+	}
+
+	mustIndexerTestSucceed(t, indexer.SaveVectors())
+	if entries, _ := db.ListJournal(ctx); len(entries) != 0 {
+		t.Errorf("journal after SaveVectors = %+v, want empty", entries)
+	}
+}
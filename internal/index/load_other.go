@@ -0,0 +1,15 @@
+//go:build !linux
+
+package index
+
+import "time"
+
+// loadAverage is not implemented on this platform.
+func loadAverage() (float64, bool) {
+	return 0, false
+}
+
+// processCPUTime is not implemented on this platform.
+func processCPUTime() (time.Duration, bool) {
+	return 0, false
+}
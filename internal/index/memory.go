@@ -0,0 +1,90 @@
+package index
+
+import (
+	"context"
+	"sync"
+
+	"github.com/J-1000/mindcli/internal/config"
+)
+
+// Limits applied in low-memory mode, unless the configuration is already
+// lower.
+const (
+	lowMemoryMaxMB      = 64
+	lowMemoryEmbedBatch = 8
+)
+
+// memoryLimits returns the worker count, embedding batch size and memory
+// cap (in MB) to index with. Low-memory mode runs a single worker and caps
+// the other two.
+func memoryLimits(cfg config.IndexingConfig) (workers, embedBatch, maxMB int) {
+	workers, embedBatch, maxMB = cfg.Workers, cfg.EmbedBatchSize, cfg.MaxMemoryMB
+	if !cfg.LowMemory {
+		return workers, embedBatch, maxMB
+	}
+	return 1, lowerLimit(embedBatch, lowMemoryEmbedBatch), lowerLimit(maxMB, lowMemoryMaxMB)
+}
+
+// lowerLimit returns limit, or v when it is a tighter one. Zero means
+// unlimited.
+func lowerLimit(v, limit int) int {
+	if v > 0 && v < limit {
+		return v
+	}
+	return limit
+}
+
+// memoryBudget caps the bytes of documents the workers hold in memory at
+// once. File sizes stand in for what a document takes while it is parsed,
+// embedded and stored. A nil budget is unlimited.
+type memoryBudget struct {
+	mu      sync.Mutex
+	limit   int64
+	used    int64
+	changed chan struct{} // closed and replaced on every release
+}
+
+func newMemoryBudget(maxMB int) *memoryBudget {
+	if maxMB <= 0 {
+		return nil
+	}
+	return &memoryBudget{limit: int64(maxMB) << 20, changed: make(chan struct{})}
+}
+
+// acquire blocks until n bytes fit in the budget and returns the amount
+// reserved, to be passed to release. A file larger than the whole budget
+// reserves all of it, so it waits to run alone rather than forever.
+func (b *memoryBudget) acquire(ctx context.Context, n int64) (int64, error) {
+	if b == nil || n <= 0 {
+		return 0, nil
+	}
+	n = min(n, b.limit)
+	for {
+		b.mu.Lock()
+		if b.used+n <= b.limit {
+			b.used += n
+			b.mu.Unlock()
+			return n, nil
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// release returns n bytes reserved by acquire.
+func (b *memoryBudget) release(n int64) {
+	if b == nil || n <= 0 {
+		return
+	}
+	b.mu.Lock()
+	b.used -= n
+	close(b.changed)
+	b.changed = make(chan struct{})
+	b.mu.Unlock()
+}
@@ -0,0 +1,109 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestMemoryLimits(t *testing.T) {
+	tests := []struct {
+		name                       string
+		cfg                        config.IndexingConfig
+		workers, embedBatch, maxMB int
+	}{
+		{"as configured", config.IndexingConfig{Workers: 4, EmbedBatchSize: 64, MaxMemoryMB: 512}, 4, 64, 512},
+		{"low memory", config.IndexingConfig{Workers: 4, EmbedBatchSize: 64, MaxMemoryMB: 512, LowMemory: true}, 1, lowMemoryEmbedBatch, lowMemoryMaxMB},
+		{"low memory caps unlimited", config.IndexingConfig{Workers: 2, LowMemory: true}, 1, lowMemoryEmbedBatch, lowMemoryMaxMB},
+		{"low memory keeps tighter limits", config.IndexingConfig{Workers: 2, EmbedBatchSize: 2, MaxMemoryMB: 16, LowMemory: true}, 1, 2, 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workers, embedBatch, maxMB := memoryLimits(tt.cfg)
+			if workers != tt.workers || embedBatch != tt.embedBatch || maxMB != tt.maxMB {
+				t.Errorf("memoryLimits() = %d, %d, %d; want %d, %d, %d",
+					workers, embedBatch, maxMB, tt.workers, tt.embedBatch, tt.maxMB)
+			}
+		})
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	b := newMemoryBudget(1)
+	ctx := context.Background()
+
+	first, err := b.acquire(ctx, 600<<10)
+	if err != nil || first != 600<<10 {
+		t.Fatalf("acquire() = %d, %v", first, err)
+	}
+
+	// A second file doesn't fit until the first is released.
+	acquired := make(chan int64)
+	go func() {
+		n, _ := b.acquire(ctx, 600<<10)
+		acquired <- n
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquire() returned while the budget was full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	b.release(first)
+	second := <-acquired
+	b.release(second)
+
+	// A file larger than the whole budget takes all of it.
+	if n, err := b.acquire(ctx, 5<<20); err != nil || n != 1<<20 {
+		t.Errorf("acquire(5MB) = %d, %v; want the whole 1MB budget", n, err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := b.acquire(cancelled, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("acquire() on a full budget with a cancelled context = %v, want context.Canceled", err)
+	}
+
+	var unlimited *memoryBudget
+	if n, err := unlimited.acquire(ctx, 1<<40); err != nil || n != 0 {
+		t.Errorf("nil budget acquire() = %d, %v; want 0, nil", n, err)
+	}
+	unlimited.release(0)
+}
+
+type countingEmbedder struct {
+	testEmbedder
+	batches []int
+}
+
+func (e *countingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	e.batches = append(e.batches, len(texts))
+	return e.testEmbedder.EmbedBatch(ctx, texts)
+}
+
+func TestIndexer_EmbedsInBatches(t *testing.T) {
+	embedder := &countingEmbedder{}
+	cfg := &config.Config{Indexing: config.IndexingConfig{Workers: 1, EmbedBatchSize: 2}}
+	indexer := NewIndexer(nil, nil, nil, embedder, cfg)
+
+	paragraphs := make([]string, 5)
+	for i := range paragraphs {
+		paragraphs[i] = strings.Repeat("word ", 120)
+	}
+	doc := &storage.Document{ID: "doc", Content: strings.Join(paragraphs, "\n\n")}
+	chunks, vectors, err := indexer.embedChunks(context.Background(), doc)
+	if err != nil {
+		t.Fatalf("embedChunks() error = %v", err)
+	}
+	if len(chunks) < 3 || len(vectors) != len(chunks) {
+		t.Fatalf("embedChunks() = %d chunks, %d vectors; want matching counts over several batches", len(chunks), len(vectors))
+	}
+	for i, n := range embedder.batches {
+		if n > 2 {
+			t.Errorf("batch %d has %d chunks, want at most 2", i, n)
+		}
+	}
+}
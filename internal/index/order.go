@@ -0,0 +1,28 @@
+package index
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/J-1000/mindcli/internal/index/sources"
+)
+
+// Orders files can be indexed in, as named by indexing.order.
+const (
+	OrderRecentFirst = "recent-first" // newest modification time first
+	OrderPath        = "path"         // by path
+	OrderSize        = "size"         // smallest first
+)
+
+// sortFiles puts a source's files in the order they are indexed in. Ties,
+// and every file for an unknown order, keep their scan order.
+func sortFiles(files []sources.FileInfo, order string) {
+	switch order {
+	case OrderRecentFirst:
+		slices.SortStableFunc(files, func(a, b sources.FileInfo) int { return cmp.Compare(b.ModifiedAt, a.ModifiedAt) })
+	case OrderPath:
+		slices.SortStableFunc(files, func(a, b sources.FileInfo) int { return cmp.Compare(a.Path, b.Path) })
+	case OrderSize:
+		slices.SortStableFunc(files, func(a, b sources.FileInfo) int { return cmp.Compare(a.Size, b.Size) })
+	}
+}
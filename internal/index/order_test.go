@@ -0,0 +1,87 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/index/sources"
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestSortFiles(t *testing.T) {
+	scanned := []sources.FileInfo{
+		{Path: "/b.md", ModifiedAt: 100, Size: 30},
+		{Path: "/c.md", ModifiedAt: 300, Size: 10},
+		{Path: "/a.md", ModifiedAt: 200, Size: 20},
+		{Path: "/d.md", ModifiedAt: 300, Size: 20},
+	}
+	tests := map[string]string{
+		OrderRecentFirst: "/c.md /d.md /a.md /b.md",
+		OrderPath:        "/a.md /b.md /c.md /d.md",
+		OrderSize:        "/c.md /a.md /d.md /b.md",
+		"":               "/b.md /c.md /a.md /d.md",
+	}
+	for order, want := range tests {
+		files := append([]sources.FileInfo(nil), scanned...)
+		sortFiles(files, order)
+		var paths []string
+		for _, f := range files {
+			paths = append(paths, f.Path)
+		}
+		if got := strings.Join(paths, " "); got != want {
+			t.Errorf("sortFiles(%q) = %s, want %s", order, got, want)
+		}
+	}
+}
+
+// pathRecorder records the files in the order indexing starts them.
+type pathRecorder struct {
+	testProgressReporter
+	paths []string
+}
+
+func (p *pathRecorder) OnProgress(source string, current, total int, path string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paths = append(p.paths, filepath.Base(path))
+}
+
+func TestIndexer_IndexesRecentFirst(t *testing.T) {
+	tmpDir := t.TempDir()
+	notesDir := filepath.Join(tmpDir, "notes")
+	mustIndexerTestSucceed(t, os.MkdirAll(notesDir, 0755))
+	now := time.Now()
+	for name, age := range map[string]time.Duration{"old.md": 72 * time.Hour, "new.md": time.Minute, "mid.md": 24 * time.Hour} {
+		path := filepath.Join(notesDir, name)
+		mustIndexerTestSucceed(t, os.WriteFile(path, []byte("# "+name), 0644))
+		mustIndexerTestSucceed(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+	}
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	mustIndexerTestSucceed(t, err)
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	mustIndexerTestSucceed(t, err)
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	cfg := &config.Config{
+		Sources: config.SourcesConfig{
+			Markdown: config.MarkdownSourceConfig{Enabled: true, Paths: []string{notesDir}, Extensions: []string{".md"}},
+		},
+		Indexing: config.IndexingConfig{Workers: 1, Order: OrderRecentFirst},
+	}
+	indexer := NewIndexer(db, searchIdx, nil, nil, cfg)
+	rec := &pathRecorder{}
+	indexer.SetProgressReporter(rec)
+	_, err = indexer.IndexAll(context.Background())
+	mustIndexerTestSucceed(t, err)
+	if got := strings.Join(rec.paths, " "); got != "new.md mid.md old.md" {
+		t.Errorf("indexed %s, want new.md mid.md old.md", got)
+	}
+}
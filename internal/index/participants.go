@@ -0,0 +1,53 @@
+package index
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// addressRe finds bare addresses in headers net/mail can't parse, such as
+// lists with unquoted commas in display names.
+var addressRe = regexp.MustCompile(`[\w.%+*'-]+@[\w.-]+\.[A-Za-z]{2,}`)
+
+// ExtractParticipants returns the senders and recipients of an email from
+// its from and to metadata, with addresses lowercased so the same person is
+// counted once however their mail client wrote them.
+func ExtractParticipants(doc *storage.Document) []storage.Participant {
+	var participants []storage.Participant
+	seen := make(map[string]bool)
+	for _, role := range []storage.ParticipantRole{storage.RoleFrom, storage.RoleTo} {
+		for _, a := range parseAddresses(doc.Metadata[string(role)]) {
+			address := strings.ToLower(strings.TrimSpace(a.Address))
+			key := string(role) + "\x00" + address
+			if address == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			participants = append(participants, storage.Participant{
+				Role:    role,
+				Address: address,
+				Name:    strings.Join(strings.Fields(a.Name), " "),
+			})
+		}
+	}
+	return participants
+}
+
+// parseAddresses parses an address list header, falling back to picking
+// out the bare addresses when it isn't well formed.
+func parseAddresses(header string) []*mail.Address {
+	if strings.TrimSpace(header) == "" {
+		return nil
+	}
+	if addrs, err := mail.ParseAddressList(header); err == nil {
+		return addrs
+	}
+	var addrs []*mail.Address
+	for _, a := range addressRe.FindAllString(header, -1) {
+		addrs = append(addrs, &mail.Address{Address: a})
+	}
+	return addrs
+}
@@ -0,0 +1,78 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestExtractParticipants(t *testing.T) {
+	doc := &storage.Document{Metadata: map[string]string{
+		"from": `"Alice  Smith" <Alice@Example.com>`,
+		"to":   `bob@example.com, "Carol" <carol@example.com>, BOB@example.com`,
+	}}
+	want := []storage.Participant{
+		{Role: storage.RoleFrom, Address: "alice@example.com", Name: "Alice Smith"},
+		{Role: storage.RoleTo, Address: "bob@example.com"},
+		{Role: storage.RoleTo, Address: "carol@example.com", Name: "Carol"},
+	}
+	if got := ExtractParticipants(doc); !slices.Equal(got, want) {
+		t.Errorf("ExtractParticipants() =\n%v\nwant\n%v", got, want)
+	}
+
+	// Malformed headers still give up their addresses.
+	doc = &storage.Document{Metadata: map[string]string{"to": "Smith, Jane <jane@example.com>; ops@example.com"}}
+	want = []storage.Participant{
+		{Role: storage.RoleTo, Address: "jane@example.com"},
+		{Role: storage.RoleTo, Address: "ops@example.com"},
+	}
+	if got := ExtractParticipants(doc); !slices.Equal(got, want) {
+		t.Errorf("ExtractParticipants() on a malformed header = %v, want %v", got, want)
+	}
+}
+
+func TestIndexer_StoresParticipants(t *testing.T) {
+	tmpDir := t.TempDir()
+	mailDir := filepath.Join(tmpDir, "mail")
+	mustIndexerTestSucceed(t, os.MkdirAll(mailDir, 0755))
+	path := filepath.Join(mailDir, "hello.eml")
+	msg := "From: Alice Smith <alice@example.com>\r\nTo: me@example.org\r\nSubject: Hello\r\n\r\nLunch on Friday?\r\n"
+	mustIndexerTestSucceed(t, os.WriteFile(path, []byte(msg), 0644))
+
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	cfg := &config.Config{
+		Sources:  config.SourcesConfig{Email: config.EmailSourceConfig{Enabled: true, Paths: []string{mailDir}}},
+		Indexing: config.IndexingConfig{Workers: 1},
+	}
+	indexer := NewIndexer(db, searchIdx, nil, nil, cfg)
+	ctx := context.Background()
+	mustIndexerTestSucceed(t, indexer.IndexFile(ctx, path))
+
+	contacts, err := db.ListContacts(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []storage.Contact{
+		{Address: "alice@example.com", Name: "Alice Smith", Messages: 1, Sent: 1},
+		{Address: "me@example.org", Messages: 1, Received: 1},
+	}
+	if !slices.Equal(contacts, want) {
+		t.Errorf("ListContacts() = %+v, want %+v", contacts, want)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package index
+
+import "errors"
+
+// LowerPriority is not supported on this platform.
+func LowerPriority() error {
+	return errors.New("lowering process priority is not supported on this platform")
+}
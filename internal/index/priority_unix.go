@@ -0,0 +1,14 @@
+//go:build unix
+
+package index
+
+import "syscall"
+
+// lowPriorityNice is the niceness applied by LowerPriority.
+const lowPriorityNice = 10
+
+// LowerPriority lowers the scheduling priority of the current process so
+// indexing yields the CPU to interactive work.
+func LowerPriority() error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, 0, lowPriorityNice)
+}
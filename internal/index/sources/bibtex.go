@@ -0,0 +1,214 @@
+package sources
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// latexCommandRegex matches LaTeX commands such as \emph or \"{o}, which are
+// dropped from BibTeX values while their arguments are kept.
+var latexCommandRegex = regexp.MustCompile(`\\([a-zA-Z]+|[^a-zA-Z\s])\s*`)
+
+// parseBibTeX parses the entries of a BibTeX file. @string, @preamble and
+// @comment blocks are skipped. Relative file attachments are resolved
+// against dir. Malformed entries are skipped with a warning.
+func parseBibTeX(text, dir string) ([]reference, []string) {
+	var refs []reference
+	var warnings []string
+	for {
+		at := strings.IndexByte(text, '@')
+		if at < 0 {
+			break
+		}
+		text = text[at+1:]
+		open := strings.IndexAny(text, "{(")
+		if open < 0 {
+			break
+		}
+		// An @ outside an entry, as in an email address, is a comment.
+		kind := strings.ToLower(strings.TrimSpace(text[:open]))
+		if kind == "" || strings.ContainsFunc(kind, func(r rune) bool { return !unicode.IsLetter(r) }) {
+			continue
+		}
+		body, rest, ok := bibBlock(text[open:])
+		text = rest
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("unterminated @%s entry", kind))
+			break
+		}
+		switch kind {
+		case "string", "preamble", "comment":
+			continue
+		}
+
+		key, fields, ok := strings.Cut(body, ",")
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("@%s entry without fields", kind))
+			continue
+		}
+		values := bibFields(fields)
+		field := func(name string) string { return cleanBibValue(values[name]) }
+		ref := reference{
+			Key:      strings.TrimSpace(key),
+			Type:     kind,
+			Title:    field("title"),
+			Authors:  bibAuthors(field("author")),
+			Year:     field("year"),
+			Venue:    firstNonBlank(field("journal"), field("booktitle"), field("publisher")),
+			DOI:      field("doi"),
+			URL:      field("url"),
+			Abstract: field("abstract"),
+		}
+		if date := field("date"); ref.Year == "" && len(date) >= 4 {
+			ref.Year = date[:4]
+		}
+		if len(ref.Authors) == 0 {
+			ref.Authors = bibAuthors(field("editor"))
+		}
+		ref.Attachments = bibFiles(values["file"], dir)
+		if ref.Title == "" {
+			ref.Title = ref.Key
+		}
+		refs = append(refs, ref)
+	}
+	return refs, warnings
+}
+
+// bibBlock returns the text inside the brace (or parenthesis) that text
+// starts with, and the text after its match.
+func bibBlock(text string) (body, rest string, ok bool) {
+	braces := 0
+	for i := 1; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '{':
+			braces++
+		case c == '}' && braces == 0 && text[0] == '{':
+			return text[1:i], text[i+1:], true
+		case c == '}':
+			braces--
+		case c == ')' && braces == 0 && text[0] == '(':
+			return text[1:i], text[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// bibFields parses "name = {value}, name = "value", name = 2020" pairs into
+// lowercase names and raw values. Values joined with # are concatenated.
+func bibFields(text string) map[string]string {
+	fields := make(map[string]string)
+	for {
+		eq := strings.IndexByte(text, '=')
+		if eq < 0 {
+			return fields
+		}
+		name := strings.ToLower(strings.TrimSpace(strings.Trim(strings.TrimSpace(text[:eq]), ",")))
+		text = text[eq+1:]
+
+		var value strings.Builder
+		for {
+			text = strings.TrimLeft(text, " \t\r\n")
+			if text == "" {
+				break
+			}
+			switch text[0] {
+			case '{':
+				body, rest, ok := bibBlock(text)
+				if !ok {
+					body, rest = text[1:], ""
+				}
+				value.WriteString(body)
+				text = rest
+			case '"':
+				end := bibQuoteEnd(text)
+				value.WriteString(text[1:end])
+				text = text[min(end+1, len(text)):]
+			default:
+				end := strings.IndexAny(text, ",#")
+				if end < 0 {
+					end = len(text)
+				}
+				value.WriteString(strings.TrimSpace(text[:end]))
+				text = text[end:]
+			}
+			text = strings.TrimLeft(text, " \t\r\n")
+			if !strings.HasPrefix(text, "#") {
+				break
+			}
+			text = text[1:]
+		}
+		fields[name] = value.String()
+		text = strings.TrimPrefix(strings.TrimLeft(text, " \t\r\n"), ",")
+	}
+}
+
+// bibQuoteEnd returns the index of the quote closing the quoted value that
+// text starts with. Quotes inside braces don't count.
+func bibQuoteEnd(text string) int {
+	depth := 0
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '"':
+			if depth == 0 && text[i-1] != '\\' {
+				return i
+			}
+		}
+	}
+	return len(text)
+}
+
+// cleanBibValue strips LaTeX markup: braces, commands and escapes.
+func cleanBibValue(value string) string {
+	value = strings.NewReplacer(`\&`, "&", `\%`, "%", `\_`, "_", `\$`, "$", "~", " ", "---", "—", "--", "–").Replace(value)
+	value = latexCommandRegex.ReplaceAllString(value, "")
+	value = strings.NewReplacer("{", "", "}", "").Replace(value)
+	return oneLine(value)
+}
+
+// bibAuthors splits a BibTeX name list ("Lovelace, Ada and Alan Turing")
+// into names in "First Last" order.
+func bibAuthors(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, " and ") {
+		name = strings.TrimSpace(name)
+		if last, first, ok := strings.Cut(name, ","); ok {
+			name = strings.TrimSpace(strings.TrimSpace(first) + " " + strings.TrimSpace(last))
+		}
+		if name != "" && name != "others" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// bibFiles returns the PDFs of a "file" field as written by Zotero, JabRef
+// and Mendeley: "Description:path:type" entries separated by semicolons, or
+// plain paths, with ":" and "_" escaped by some tools. Relative paths are
+// resolved against dir.
+func bibFiles(field, dir string) []string {
+	// Escaped colons, as in "C\:/papers", are held back from the split.
+	field = strings.NewReplacer(`\:`, "\x00", `\_`, "_", "{", "", "}", "").Replace(field)
+	var files []string
+	for _, entry := range strings.Split(field, ";") {
+		entry = strings.TrimSpace(entry)
+		if parts := strings.Split(entry, ":"); len(parts) >= 3 {
+			entry = strings.Join(parts[1:len(parts)-1], ":")
+		}
+		entry = strings.ReplaceAll(entry, "\x00", ":")
+		if !strings.EqualFold(filepath.Ext(entry), ".pdf") {
+			continue
+		}
+		if !filepath.IsAbs(entry) {
+			entry = filepath.Join(dir, entry)
+		}
+		files = append(files, entry)
+	}
+	return files
+}
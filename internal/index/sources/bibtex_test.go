@@ -0,0 +1,69 @@
+package sources
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBibTeX(t *testing.T) {
+	bib := `% Exported by Zotero; contact admin@example.com
+@string{acm = "ACM"}
+@comment{jabref-meta: databaseType:bibtex;}
+
+@article{lovelace1843,
+  title     = {Notes on the {Analytical Engine}},
+  author    = {Lovelace, Ada and Menabrea, Luigi F. and others},
+  journal   = "Scientific Memoirs",
+  year      = 1843,
+  abstract  = {A translation
+               with \emph{notes} on Bernoulli numbers \& loops.},
+  file      = {Full Text PDF:papers/lovelace.pdf:application/pdf;Snapshot:papers/snap.html:text/html},
+}
+
+@inproceedings(turing50,
+  title = "Computing Machinery " # "and Intelligence",
+  editor = {Alan Turing},
+  date = {1950-10},
+  doi = {10.1093/mind/LIX.236.433},
+  file = {/abs/turing.pdf},
+)
+
+@book{broken,
+`
+	refs, warnings := parseBibTeX(bib, "/lib")
+	want := []reference{
+		{
+			Key:         "lovelace1843",
+			Type:        "article",
+			Title:       "Notes on the Analytical Engine",
+			Authors:     []string{"Ada Lovelace", "Luigi F. Menabrea"},
+			Year:        "1843",
+			Venue:       "Scientific Memoirs",
+			Abstract:    "A translation with notes on Bernoulli numbers & loops.",
+			Attachments: []string{"/lib/papers/lovelace.pdf"},
+		},
+		{
+			Key:         "turing50",
+			Type:        "inproceedings",
+			Title:       "Computing Machinery and Intelligence",
+			Authors:     []string{"Alan Turing"},
+			Year:        "1950",
+			DOI:         "10.1093/mind/LIX.236.433",
+			Attachments: []string{"/abs/turing.pdf"},
+		},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("parseBibTeX() =\n%+v\nwant\n%+v", refs, want)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want one for the unterminated entry", warnings)
+	}
+}
+
+func TestBibFiles(t *testing.T) {
+	got := bibFiles(`:/papers/ch\:1.pdf:PDF;b.PDF`, "/lib")
+	want := []string{"/papers/ch:1.pdf", "/lib/b.PDF"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bibFiles() = %v, want %v", got, want)
+	}
+}
@@ -11,7 +11,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/J-1000/mindcli/internal/storage"
@@ -21,6 +23,19 @@ import (
 // BrowserSource indexes browser history and bookmarks.
 type BrowserSource struct {
 	browsers []string
+
+	// maxEntries and maxBytes cap the history entries of each browser's
+	// document; 0 means no limit.
+	maxEntries int
+	maxBytes   int64
+	// retention is how long history entries are kept after their last
+	// visit; 0 keeps them forever.
+	retention time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	trimmed []TrimReport
+	expired []TrimReport
 }
 
 // NewBrowserSource creates a new browser history source.
@@ -28,7 +43,45 @@ func NewBrowserSource(browsers []string) *BrowserSource {
 	if len(browsers) == 0 {
 		browsers = []string{"chrome", "firefox", "safari"}
 	}
-	return &BrowserSource{browsers: browsers}
+	return &BrowserSource{browsers: browsers, now: time.Now}
+}
+
+// SetBudget caps the entries of each browser's document at maxEntries and
+// their text at maxBytes; 0 means no limit. Past either, the least visited
+// history entries, oldest first, are left out. Bookmarks are always kept.
+func (b *BrowserSource) SetBudget(maxEntries int, maxBytes int64) {
+	b.maxEntries = maxEntries
+	b.maxBytes = maxBytes
+}
+
+// SetRetention leaves history entries last visited more than days ago out
+// of each browser's document; days <= 0 keeps them forever. Bookmarks are
+// always kept.
+func (b *BrowserSource) SetRetention(days int) {
+	b.retention = 0
+	if days > 0 {
+		b.retention = time.Duration(days) * 24 * time.Hour
+	}
+}
+
+// TakeExpired returns the entries left out of documents parsed since it was
+// last called for being past the retention period.
+func (b *BrowserSource) TakeExpired() []TrimReport {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	expired := b.expired
+	b.expired = nil
+	return expired
+}
+
+// TakeTrimmed returns the entries left out of documents parsed since it was
+// last called.
+func (b *BrowserSource) TakeTrimmed() []TrimReport {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	trimmed := b.trimmed
+	b.trimmed = nil
+	return trimmed
 }
 
 // Name returns the source name.
@@ -47,6 +100,23 @@ func (b *BrowserSource) MatchesPath(path string) bool {
 	return false
 }
 
+// WatchPaths returns the history databases and bookmark files that exist for
+// the configured browsers.
+func (b *BrowserSource) WatchPaths() []string {
+	var paths []string
+	for _, browser := range b.browsers {
+		for _, p := range []string{browserDBPath(browser), browserBookmarkPath(browser)} {
+			if p == "" {
+				continue
+			}
+			if _, err := os.Stat(p); err == nil {
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
+
 // historyEntry holds a single browser history entry.
 type historyEntry struct {
 	URL        string
@@ -106,41 +176,154 @@ func (b *BrowserSource) Parse(ctx context.Context, file FileInfo) (*storage.Docu
 		if err != nil {
 			return nil, err
 		}
-		return buildBrowserDocument(file, browser, entries), nil
+		return buildBrowserDocument(file, browser, b.trim(file.Path, b.expire(file.Path, entries))), nil
+	}
+
+	if browser == "" {
+		return nil, fmt.Errorf("unknown browser: %s", file.Path)
+	}
+
+	// The browser may be writing (or locking) its database while we copy
+	// it, so retry transient failures with a fresh copy.
+	var entries []historyEntry
+	var warnings []string
+	err := retryTransient(ctx, lockRetryAttempts, lockRetryDelay, func() error {
+		var err error
+		entries, warnings, err = readBrowserDB(browser, file.Path)
+		return err
+	})
+	if err != nil {
+		if len(entries) == 0 {
+			return nil, err
+		}
+		// Index what was read before the failure.
+		warnings = append(warnings, fmt.Sprintf("history read stopped after %d entries: %v", len(entries), err))
+	}
+
+	return partial(buildBrowserDocument(file, browser, b.trim(file.Path, b.expire(file.Path, entries))), warnings)
+}
+
+// expire leaves out the history entries of path last visited before the
+// retention period, recording what it left out.
+func (b *BrowserSource) expire(path string, entries []historyEntry) []historyEntry {
+	if b.retention <= 0 {
+		return entries
+	}
+	cutoff := b.now().Add(-b.retention)
+	kept := make([]historyEntry, 0, len(entries))
+	report := TrimReport{Source: storage.SourceBrowser, Path: path}
+	for _, e := range entries {
+		if e.Kind == "bookmark" || e.LastVisit.IsZero() || !e.LastVisit.Before(cutoff) {
+			kept = append(kept, e)
+			continue
+		}
+		report.Removed++
+		report.Bytes += entrySize(e)
+		if e.LastVisit.After(report.Newest) {
+			report.Newest = e.LastVisit
+		}
+	}
+	if report.Removed > 0 {
+		b.mu.Lock()
+		b.expired = append(b.expired, report)
+		b.mu.Unlock()
+	}
+	return kept
+}
+
+// entrySize is the bytes of text an entry adds to its document.
+func entrySize(e historyEntry) int64 {
+	return int64(len(e.Title) + len(e.URL) + 3)
+}
+
+// trim leaves out the least visited history entries of path, oldest first,
+// until the rest fit the budget, recording what it left out.
+func (b *BrowserSource) trim(path string, entries []historyEntry) []historyEntry {
+	var total int64
+	for _, e := range entries {
+		total += entrySize(e)
+	}
+	count := len(entries)
+	over := func() bool {
+		return (b.maxEntries > 0 && count > b.maxEntries) || (b.maxBytes > 0 && total > b.maxBytes)
+	}
+	if !over() {
+		return entries
+	}
+
+	order := make([]int, 0, len(entries))
+	for i, e := range entries {
+		if e.Kind != "bookmark" {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		x, y := entries[order[i]], entries[order[j]]
+		if x.VisitCount != y.VisitCount {
+			return x.VisitCount < y.VisitCount
+		}
+		return x.LastVisit.Before(y.LastVisit)
+	})
+	drop := make(map[int]bool)
+	report := TrimReport{Source: storage.SourceBrowser, Path: path}
+	for _, i := range order {
+		if !over() {
+			break
+		}
+		drop[i] = true
+		count--
+		total -= entrySize(entries[i])
+		report.Removed++
+		report.Bytes += entrySize(entries[i])
+		if entries[i].LastVisit.After(report.Newest) {
+			report.Newest = entries[i].LastVisit
+		}
 	}
 
+	kept := make([]historyEntry, 0, count)
+	for i, e := range entries {
+		if !drop[i] {
+			kept = append(kept, e)
+		}
+	}
+	if report.Removed > 0 {
+		b.mu.Lock()
+		b.trimmed = append(b.trimmed, report)
+		b.mu.Unlock()
+	}
+	return kept
+}
+
+// readBrowserDB reads history (and, for Firefox, bookmarks) from a temporary
+// copy of a browser database. On a read error it returns the entries read so
+// far along with the error. Non-fatal problems are returned as warnings.
+func readBrowserDB(browser, path string) ([]historyEntry, []string, error) {
 	// Copy the database to a temp file since browsers may lock it.
-	tmpFile, err := copyToTemp(file.Path)
+	tmpFile, err := copyToTemp(path)
 	if err != nil {
-		return nil, fmt.Errorf("copying browser db: %w", err)
+		return nil, nil, fmt.Errorf("copying browser db: %w", err)
 	}
 	defer func() { _ = os.Remove(tmpFile) }()
 
-	var entries []historyEntry
-	var parseErr error
-
 	switch browser {
 	case "chrome":
-		entries, parseErr = readChromeHistory(tmpFile)
+		entries, err := readChromeHistory(tmpFile)
+		return entries, nil, err
 	case "firefox":
-		entries, parseErr = readFirefoxHistory(tmpFile)
-		if parseErr == nil {
-			bookmarks, err := readFirefoxBookmarks(tmpFile)
-			if err == nil {
-				entries = append(entries, bookmarks...)
-			}
+		entries, err := readFirefoxHistory(tmpFile)
+		if err != nil {
+			return entries, nil, err
+		}
+		bookmarks, err := readFirefoxBookmarks(tmpFile)
+		if err != nil {
+			return entries, []string{fmt.Sprintf("skipped bookmarks: %v", err)}, nil
 		}
+		return append(entries, bookmarks...), nil, nil
 	case "safari":
-		entries, parseErr = readSafariHistory(tmpFile)
-	default:
-		return nil, fmt.Errorf("unknown browser: %s", browser)
-	}
-
-	if parseErr != nil {
-		return nil, parseErr
+		entries, err := readSafariHistory(tmpFile)
+		return entries, nil, err
 	}
-
-	return buildBrowserDocument(file, browser, entries), nil
+	return nil, nil, fmt.Errorf("unknown browser: %s", browser)
 }
 
 // browserDBPath returns the history database path for a browser.
@@ -255,7 +438,8 @@ func copyToTemp(src string) (string, error) {
 	return tmpFile.Name(), nil
 }
 
-// readChromeHistory reads Chrome's History database.
+// readChromeHistory reads Chrome's History database. If reading stops part
+// way through, the entries read so far are returned with the error.
 func readChromeHistory(dbPath string) ([]historyEntry, error) {
 	db, err := sql.Open("sqlite3", dbPath+"?mode=ro")
 	if err != nil {
@@ -298,7 +482,7 @@ func readChromeHistory(dbPath string) ([]historyEntry, error) {
 		})
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("reading chrome history: %w", err)
+		return entries, fmt.Errorf("reading chrome history: %w", err)
 	}
 
 	return entries, nil
@@ -350,7 +534,7 @@ func readFirefoxHistory(dbPath string) ([]historyEntry, error) {
 		})
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("reading firefox history: %w", err)
+		return entries, fmt.Errorf("reading firefox history: %w", err)
 	}
 
 	return entries, nil
@@ -407,7 +591,7 @@ func readSafariHistory(dbPath string) ([]historyEntry, error) {
 		})
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("reading safari history: %w", err)
+		return entries, fmt.Errorf("reading safari history: %w", err)
 	}
 
 	return entries, nil
@@ -454,6 +638,9 @@ func readFirefoxBookmarks(dbPath string) ([]historyEntry, error) {
 			Kind:    "bookmark",
 		})
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading firefox bookmarks: %w", err)
+	}
 	return entries, nil
 }
 
@@ -505,7 +692,9 @@ func buildBrowserDocument(file FileInfo, browser string, entries []historyEntry)
 	var sb strings.Builder
 	var historyCount int
 	var bookmarkCount int
+	var visitCount int
 	for _, e := range entries {
+		visitCount += e.VisitCount
 		if e.Kind == "bookmark" {
 			bookmarkCount++
 			sb.WriteString("[Bookmark] ")
@@ -533,12 +722,13 @@ func buildBrowserDocument(file FileInfo, browser string, entries []historyEntry)
 		Path:    file.Path,
 		Title:   title,
 		Content: content,
-		Preview: generatePreview(content, 500),
+		Preview: browserPreview(entries, previewLen()),
 		Metadata: map[string]string{
 			"browser":        browser,
 			"entry_count":    fmt.Sprintf("%d", len(entries)),
 			"history_count":  fmt.Sprintf("%d", historyCount),
 			"bookmark_count": fmt.Sprintf("%d", bookmarkCount),
+			"visit_count":    fmt.Sprintf("%d", visitCount),
 		},
 		ContentHash: hex.EncodeToString(contentHash[:]),
 		IndexedAt:   time.Now(),
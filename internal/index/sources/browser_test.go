@@ -1,8 +1,12 @@
 package sources
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -47,6 +51,79 @@ func TestChromeTimeToGo(t *testing.T) {
 	}
 }
 
+func TestBrowserSourceTrim(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	entries := []historyEntry{
+		{URL: "https://a.example", Title: "A", VisitCount: 1, LastVisit: day(1), Kind: "history"},
+		{URL: "https://b.example", Title: "B", VisitCount: 1, LastVisit: day(5), Kind: "history"},
+		{URL: "https://c.example", Title: "C", VisitCount: 9, LastVisit: day(2), Kind: "history"},
+		{URL: "https://d.example", Title: "D", Kind: "bookmark"},
+		{URL: "https://e.example", Title: "E", VisitCount: 2, LastVisit: day(3), Kind: "history"},
+	}
+
+	src := NewBrowserSource(nil)
+	if got := src.trim("/fake/History", entries); len(got) != len(entries) || src.TakeTrimmed() != nil {
+		t.Errorf("trim without a budget left %d of %d entries", len(got), len(entries))
+	}
+
+	// The least visited go first, oldest first; bookmarks stay.
+	src.SetBudget(2, 0)
+	var titles []string
+	for _, e := range src.trim("/fake/History", entries) {
+		titles = append(titles, e.Title)
+	}
+	if got := strings.Join(titles, ","); got != "C,D" {
+		t.Errorf("trim kept %s, want C,D", got)
+	}
+	report := src.TakeTrimmed()
+	if len(report) != 1 || report[0].Removed != 3 || report[0].Path != "/fake/History" || !report[0].Newest.Equal(day(5)) {
+		t.Errorf("TakeTrimmed() = %+v, want 3 entries up to March 5 dropped", report)
+	}
+	if src.TakeTrimmed() != nil {
+		t.Error("TakeTrimmed() reported the same entries twice")
+	}
+
+	// Bookmarks are kept even over budget.
+	src.SetBudget(0, 1)
+	if got := src.trim("/fake/History", entries); len(got) != 1 || got[0].Kind != "bookmark" {
+		t.Errorf("trim over a tiny size budget kept %+v, want only the bookmark", got)
+	}
+}
+
+func TestBrowserSourceExpire(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	entries := []historyEntry{
+		{URL: "https://a.example", Title: "A", LastVisit: now.AddDate(-2, 0, 0), Kind: "history"},
+		{URL: "https://b.example", Title: "B", LastVisit: now.AddDate(0, -13, 0), Kind: "history"},
+		{URL: "https://c.example", Title: "C", LastVisit: now.AddDate(0, -1, 0), Kind: "history"},
+		{URL: "https://d.example", Title: "D", LastVisit: now.AddDate(-3, 0, 0), Kind: "bookmark"},
+		{URL: "https://e.example", Title: "E", Kind: "history"},
+	}
+
+	src := NewBrowserSource(nil)
+	src.now = func() time.Time { return now }
+	if got := src.expire("/fake/History", entries); len(got) != len(entries) || src.TakeExpired() != nil {
+		t.Errorf("expire without retention left %d of %d entries", len(got), len(entries))
+	}
+
+	// History older than a year goes; bookmarks and undated entries stay.
+	src.SetRetention(365)
+	var titles []string
+	for _, e := range src.expire("/fake/History", entries) {
+		titles = append(titles, e.Title)
+	}
+	if got := strings.Join(titles, ","); got != "C,D,E" {
+		t.Errorf("expire kept %s, want C,D,E", got)
+	}
+	report := src.TakeExpired()
+	if len(report) != 1 || report[0].Removed != 2 || report[0].Path != "/fake/History" || !report[0].Newest.Equal(entries[1].LastVisit) {
+		t.Errorf("TakeExpired() = %+v, want A and B expired", report)
+	}
+	if src.TakeExpired() != nil {
+		t.Error("TakeExpired() reported the same entries twice")
+	}
+}
+
 func TestBuildBrowserDocument(t *testing.T) {
 	entries := []historyEntry{
 		{URL: "https://example.com", Title: "Example", VisitCount: 5, Browser: "chrome", Kind: "history"},
@@ -75,6 +152,9 @@ func TestBuildBrowserDocument(t *testing.T) {
 	if doc.Metadata["bookmark_count"] != "1" {
 		t.Errorf("bookmark_count = %q, want %q", doc.Metadata["bookmark_count"], "1")
 	}
+	if doc.Metadata["visit_count"] != "8" {
+		t.Errorf("visit_count = %q, want %q", doc.Metadata["visit_count"], "8")
+	}
 	if doc.Title != "Chrome Browser Data (2 entries)" {
 		t.Errorf("Title = %q", doc.Title)
 	}
@@ -113,3 +193,37 @@ func TestReadChromeBookmarks(t *testing.T) {
 		}
 	}
 }
+
+func TestBrowserParseKeepsHistoryWhenBookmarksFail(t *testing.T) {
+	// A places.sqlite without moz_bookmarks: history still indexes.
+	dbPath := filepath.Join(t.TempDir(), "places.sqlite")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE moz_places (url TEXT, title TEXT, visit_count INTEGER, last_visit_date INTEGER)`,
+		`INSERT INTO moz_places VALUES ('https://go.dev', 'Go', 3, 1700000000000000)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewBrowserSource([]string{"firefox"})
+	doc, err := src.Parse(context.Background(), FileInfo{Path: dbPath, ModifiedAt: time.Now().Unix()})
+
+	var perr *PartialError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Parse error = %v, want *PartialError", err)
+	}
+	if doc == nil || !strings.Contains(doc.Content, "https://go.dev") {
+		t.Fatalf("partial document missing history: %+v", doc)
+	}
+	if !strings.Contains(doc.Metadata["parse_warnings"], "skipped bookmarks") {
+		t.Errorf("parse_warnings = %q", doc.Metadata["parse_warnings"])
+	}
+}
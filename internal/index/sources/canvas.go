@@ -0,0 +1,114 @@
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// canvasFile is an Obsidian .canvas file (JSON Canvas).
+type canvasFile struct {
+	Nodes []struct {
+		Type  string  `json:"type"`
+		Text  string  `json:"text"`
+		File  string  `json:"file"`
+		URL   string  `json:"url"`
+		Label string  `json:"label"`
+		X     float64 `json:"x"`
+		Y     float64 `json:"y"`
+	} `json:"nodes"`
+	Edges []struct {
+		Label string `json:"label"`
+	} `json:"edges"`
+}
+
+// canvasText converts an Obsidian canvas to markdown: text cards as they
+// are, group and edge labels as lines, embedded notes as wiki links and web
+// cards as links. Cards are read top to bottom, then left to right.
+func canvasText(data []byte) (string, error) {
+	var c canvasFile
+	if err := json.Unmarshal(data, &c); err != nil {
+		return "", fmt.Errorf("parsing canvas: %w", err)
+	}
+	sort.SliceStable(c.Nodes, func(i, j int) bool {
+		if c.Nodes[i].Y != c.Nodes[j].Y {
+			return c.Nodes[i].Y < c.Nodes[j].Y
+		}
+		return c.Nodes[i].X < c.Nodes[j].X
+	})
+
+	var parts []string
+	for _, n := range c.Nodes {
+		switch n.Type {
+		case "text":
+			parts = append(parts, n.Text)
+		case "file":
+			if n.File != "" {
+				parts = append(parts, "[["+n.File+"]]")
+			}
+		case "link":
+			if n.URL != "" {
+				parts = append(parts, "["+n.URL+"]("+n.URL+")")
+			}
+		case "group":
+			parts = append(parts, n.Label)
+		}
+	}
+	for _, e := range c.Edges {
+		parts = append(parts, e.Label)
+	}
+	return joinParts(parts), nil
+}
+
+// excalidrawFile is an Excalidraw drawing (.excalidraw).
+type excalidrawFile struct {
+	Elements []struct {
+		Type      string  `json:"type"`
+		Text      string  `json:"text"`
+		Link      string  `json:"link"`
+		IsDeleted bool    `json:"isDeleted"`
+		X         float64 `json:"x"`
+		Y         float64 `json:"y"`
+	} `json:"elements"`
+}
+
+// excalidrawText returns the text elements of an Excalidraw drawing, top to
+// bottom, then left to right, followed by any element links.
+func excalidrawText(data []byte) (string, error) {
+	var d excalidrawFile
+	if err := json.Unmarshal(data, &d); err != nil {
+		return "", fmt.Errorf("parsing excalidraw: %w", err)
+	}
+	sort.SliceStable(d.Elements, func(i, j int) bool {
+		if d.Elements[i].Y != d.Elements[j].Y {
+			return d.Elements[i].Y < d.Elements[j].Y
+		}
+		return d.Elements[i].X < d.Elements[j].X
+	})
+
+	var parts, links []string
+	for _, e := range d.Elements {
+		if e.IsDeleted {
+			continue
+		}
+		if e.Type == "text" {
+			parts = append(parts, e.Text)
+		}
+		if e.Link != "" {
+			links = append(links, "["+e.Link+"]("+e.Link+")")
+		}
+	}
+	return joinParts(append(parts, links...)), nil
+}
+
+// joinParts joins the non-blank parts as paragraphs.
+func joinParts(parts []string) string {
+	kept := parts[:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
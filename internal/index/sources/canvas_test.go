@@ -0,0 +1,79 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCanvasText(t *testing.T) {
+	data := []byte(`{
+		"nodes": [
+			{"id": "b", "type": "text", "text": "Second card #idea", "x": 0, "y": 200},
+			{"id": "a", "type": "text", "text": "# Launch plan\n\nFirst card", "x": 0, "y": 0},
+			{"id": "c", "type": "file", "file": "projects/alpha.md", "x": 300, "y": 0},
+			{"id": "d", "type": "link", "url": "https://example.com", "x": 0, "y": 400},
+			{"id": "e", "type": "group", "label": "Phase one", "x": -50, "y": -50}
+		],
+		"edges": [{"id": "x", "fromNode": "a", "toNode": "b", "label": "leads to"}]
+	}`)
+	text, err := canvasText(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Phase one\n\n# Launch plan\n\nFirst card\n\n[[projects/alpha.md]]\n\nSecond card #idea\n\n" +
+		"[https://example.com](https://example.com)\n\nleads to"
+	if text != want {
+		t.Errorf("canvasText() =\n%s\nwant\n%s", text, want)
+	}
+
+	if _, err := canvasText([]byte("not json")); err == nil {
+		t.Error("canvasText() accepted invalid JSON")
+	}
+}
+
+func TestExcalidrawText(t *testing.T) {
+	data := []byte(`{
+		"type": "excalidraw",
+		"elements": [
+			{"type": "rectangle", "x": 0, "y": 0, "link": "https://example.com/spec"},
+			{"type": "text", "text": "Database", "x": 100, "y": 50},
+			{"type": "text", "text": "API server", "x": 0, "y": 50},
+			{"type": "text", "text": "old label", "x": 0, "y": 10, "isDeleted": true}
+		]
+	}`)
+	text, err := excalidrawText(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "API server\n\nDatabase\n\n[https://example.com/spec](https://example.com/spec)"
+	if text != want {
+		t.Errorf("excalidrawText() = %q, want %q", text, want)
+	}
+}
+
+func TestMarkdownSource_ParsesCanvas(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "board.canvas")
+	data := `{"nodes": [{"type": "text", "text": "Roadmap #planning [[Q3 goals]]", "x": 0, "y": 0}]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewMarkdownSource([]string{dir}, []string{".md", ".canvas"}, nil)
+	if !source.MatchesPath(path) {
+		t.Fatal("canvas file not matched")
+	}
+	doc, err := source.Parse(context.Background(), FileInfo{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "board" || !strings.Contains(doc.Content, "Roadmap") {
+		t.Errorf("doc = title %q content %q", doc.Title, doc.Content)
+	}
+	if doc.Metadata["tags"] != "planning" || doc.Metadata["links"] != "Q3 goals" {
+		t.Errorf("metadata = %v, want the card's tag and link", doc.Metadata)
+	}
+}
@@ -5,19 +5,40 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/pkg/chunker"
 	"github.com/atotto/clipboard"
 )
 
+// clipboardPollInterval is how often the clipboard is sampled while watching.
+const clipboardPollInterval = 2 * time.Second
+
+// maxExcludedClips caps the clips remembered as copied in an excluded app.
+const maxExcludedClips = 100
+
 // ClipboardSource indexes clipboard history.
 // It polls the system clipboard and stores unique text entries.
 type ClipboardSource struct {
 	retentionDays int
 	skipPasswords bool
 	db            *storage.DB
+	now           func() time.Time
+
+	// Clips copied while an excluded app was focused are remembered by hash
+	// so they are still skipped once another app is.
+	excludeApps   map[string]bool
+	excluded      map[string]bool
+	appErrShown   bool
+	mu            sync.Mutex
+	frontmostApp  func(ctx context.Context) (string, error)
+	readClipboard func() (string, error)
 }
 
 // NewClipboardSource creates a new clipboard source.
@@ -29,9 +50,29 @@ func NewClipboardSource(db *storage.DB, retentionDays int, skipPasswords bool) *
 		retentionDays: retentionDays,
 		skipPasswords: skipPasswords,
 		db:            db,
+		now:           time.Now,
+		frontmostApp:  FrontmostApp,
+		readClipboard: clipboard.ReadAll,
 	}
 }
 
+// SetExcludedApps sets the applications, by name, whose clips are never
+// captured: none is while one of them is focused. Names are matched
+// case-insensitively against what FrontmostApp reports.
+func (c *ClipboardSource) SetExcludedApps(apps []string) {
+	c.excludeApps = make(map[string]bool, len(apps))
+	for _, app := range apps {
+		if app = strings.TrimSpace(app); app != "" {
+			c.excludeApps[strings.ToLower(app)] = true
+		}
+	}
+}
+
+// Expired reports whether doc was copied before the retention period.
+func (c *ClipboardSource) Expired(doc *storage.Document) bool {
+	return doc.Source == storage.SourceClipboard && doc.ModifiedAt.Before(c.now().AddDate(0, 0, -c.retentionDays))
+}
+
 // Name returns the source name.
 func (c *ClipboardSource) Name() storage.Source {
 	return storage.SourceClipboard
@@ -42,6 +83,11 @@ func (c *ClipboardSource) MatchesPath(path string) bool {
 	return strings.HasPrefix(path, "clipboard:")
 }
 
+// PollInterval returns how often the clipboard should be re-scanned.
+func (c *ClipboardSource) PollInterval() time.Duration {
+	return clipboardPollInterval
+}
+
 // Scan returns the current clipboard content as a single file-like entry.
 // For clipboard, each unique clip is treated as a "file".
 func (c *ClipboardSource) Scan(ctx context.Context) (<-chan FileInfo, <-chan error) {
@@ -52,7 +98,7 @@ func (c *ClipboardSource) Scan(ctx context.Context) (<-chan FileInfo, <-chan err
 		defer close(files)
 		defer close(errs)
 
-		text, err := clipboard.ReadAll()
+		text, err := c.readClipboard()
 		if err != nil {
 			select {
 			case errs <- fmt.Errorf("reading clipboard: %w", err):
@@ -75,6 +121,18 @@ func (c *ClipboardSource) Scan(ctx context.Context) (<-chan FileInfo, <-chan err
 		hash := sha256.Sum256([]byte(text))
 		id := hex.EncodeToString(hash[:8])
 
+		skip, err := c.fromExcludedApp(ctx, id)
+		if err != nil {
+			select {
+			case errs <- err:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if skip {
+			return
+		}
+
 		select {
 		case files <- FileInfo{
 			Path:       "clipboard:" + id,
@@ -88,9 +146,91 @@ func (c *ClipboardSource) Scan(ctx context.Context) (<-chan FileInfo, <-chan err
 	return files, errs
 }
 
+// fromExcludedApp reports whether the clip with the given ID was copied in
+// an excluded app: one is focused now, or was when the clip was first seen.
+// When the focused app can't be found the clip is skipped too, as it may
+// come from an excluded app; the failure is an error the first time only.
+func (c *ClipboardSource) fromExcludedApp(ctx context.Context, id string) (bool, error) {
+	if len(c.excludeApps) == 0 {
+		return false, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.excluded[id] {
+		return true, nil
+	}
+	app, err := c.frontmostApp(ctx)
+	if err != nil {
+		if c.appErrShown {
+			return true, nil
+		}
+		c.appErrShown = true
+		return true, fmt.Errorf("finding the focused app, so clips are skipped for exclude_apps: %w", err)
+	}
+	if !c.excludeApps[strings.ToLower(app)] {
+		return false, nil
+	}
+	if c.excluded == nil || len(c.excluded) >= maxExcludedClips {
+		c.excluded = make(map[string]bool)
+	}
+	c.excluded[id] = true
+	return true, nil
+}
+
+// FocusedAppSupported reports whether FrontmostApp can work here: on macOS,
+// and on Linux under X11 with xdotool installed. Under Wayland no app can
+// see which window is focused.
+func FocusedAppSupported() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		return true
+	case "linux":
+		if os.Getenv("WAYLAND_DISPLAY") != "" || os.Getenv("DISPLAY") == "" {
+			return false
+		}
+		_, err := exec.LookPath("xdotool")
+		return err == nil
+	}
+	return false
+}
+
+// FrontmostApp returns the name of the focused application: its display
+// name on macOS, from lsappinfo, and its window class on Linux, from
+// xdotool, which only works under X11.
+func FrontmostApp(ctx context.Context) (string, error) {
+	var name string
+	switch runtime.GOOS {
+	case "darwin":
+		asn, err := exec.CommandContext(ctx, "lsappinfo", "front").Output()
+		if err != nil {
+			return "", fmt.Errorf("running lsappinfo: %w", err)
+		}
+		out, err := exec.CommandContext(ctx, "lsappinfo", "info", "-only", "name", strings.TrimSpace(string(asn))).Output()
+		if err != nil {
+			return "", fmt.Errorf("running lsappinfo: %w", err)
+		}
+		// The output is a line like "LSDisplayName"="Safari".
+		if _, value, ok := strings.Cut(string(out), "="); ok {
+			name = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	case "linux":
+		out, err := exec.CommandContext(ctx, "xdotool", "getactivewindow", "getwindowclassname").Output()
+		if err != nil {
+			return "", fmt.Errorf("running xdotool: %w", err)
+		}
+		name = strings.TrimSpace(string(out))
+	default:
+		return "", fmt.Errorf("finding the focused app is not supported on %s", runtime.GOOS)
+	}
+	if name == "" {
+		return "", fmt.Errorf("no focused app")
+	}
+	return name, nil
+}
+
 // Parse creates a document from the current clipboard content.
 func (c *ClipboardSource) Parse(ctx context.Context, file FileInfo) (*storage.Document, error) {
-	text, err := clipboard.ReadAll()
+	text, err := c.readClipboard()
 	if err != nil {
 		return nil, fmt.Errorf("reading clipboard: %w", err)
 	}
@@ -105,9 +245,7 @@ func (c *ClipboardSource) Parse(ctx context.Context, file FileInfo) (*storage.Do
 
 	// Title is first line or truncated content.
 	title := firstLine(text)
-	if len(title) > 100 {
-		title = title[:97] + "..."
-	}
+	title = chunker.TruncateWidth(title, 100)
 
 	return &storage.Document{
 		ID:          id,
@@ -115,7 +253,7 @@ func (c *ClipboardSource) Parse(ctx context.Context, file FileInfo) (*storage.Do
 		Path:        "clipboard:" + id,
 		Title:       title,
 		Content:     text,
-		Preview:     generatePreview(text, 500),
+		Preview:     generatePreview(text, previewLen()),
 		ContentHash: hex.EncodeToString(hash[:]),
 		IndexedAt:   time.Now(),
 		ModifiedAt:  time.Now(),
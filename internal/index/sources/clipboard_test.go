@@ -1,7 +1,11 @@
 package sources
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/J-1000/mindcli/internal/storage"
 )
@@ -13,6 +17,76 @@ func TestClipboardSourceName(t *testing.T) {
 	}
 }
 
+func TestClipboardSourceIsPolled(t *testing.T) {
+	var src Source = NewClipboardSource(nil, 30, true)
+	p, ok := src.(Poller)
+	if !ok {
+		t.Fatal("ClipboardSource should implement Poller")
+	}
+	if p.PollInterval() <= 0 {
+		t.Errorf("PollInterval() = %v, want > 0", p.PollInterval())
+	}
+	if _, ok := src.(Watchable); ok {
+		t.Error("ClipboardSource should not implement Watchable")
+	}
+}
+
+func TestClipboardSourceExpired(t *testing.T) {
+	src := NewClipboardSource(nil, 30, true)
+	if !src.Expired(&storage.Document{Source: storage.SourceClipboard, ModifiedAt: time.Now().AddDate(0, 0, -31)}) {
+		t.Error("Expired() = false for a clip past retention")
+	}
+	if src.Expired(&storage.Document{Source: storage.SourceClipboard, ModifiedAt: time.Now()}) {
+		t.Error("Expired() = true for a recent clip")
+	}
+	if src.Expired(&storage.Document{Source: storage.SourceMarkdown, ModifiedAt: time.Now().AddDate(-1, 0, 0)}) {
+		t.Error("Expired() = true for another source's document")
+	}
+}
+
+func TestClipboardSourceExcludedApps(t *testing.T) {
+	src := NewClipboardSource(nil, 30, false)
+	src.SetExcludedApps([]string{"1password", " "})
+	clip, app := "vault recovery code", "1Password"
+	src.readClipboard = func() (string, error) { return clip, nil }
+	src.frontmostApp = func(context.Context) (string, error) { return app, nil }
+	scan := func() (n int, errs []error) {
+		files, errc := src.Scan(context.Background())
+		for range files {
+			n++
+		}
+		for err := range errc {
+			errs = append(errs, err)
+		}
+		return n, errs
+	}
+
+	if n, _ := scan(); n != 0 {
+		t.Error("a clip copied while an excluded app is focused should be skipped")
+	}
+	// Switching apps doesn't let the same clip through.
+	app = "Safari"
+	if n, _ := scan(); n != 0 {
+		t.Error("a clip first seen in an excluded app should stay skipped")
+	}
+	clip = "meeting notes"
+	if n, _ := scan(); n != 1 {
+		t.Error("a clip copied in another app should be captured")
+	}
+
+	// Not knowing the focused app skips the clip, as it may come from an
+	// excluded app, and is reported once.
+	src.frontmostApp = func(context.Context) (string, error) { return "", errors.New("no focused window") }
+	clip = "another clip"
+	if n, errs := scan(); n != 0 || len(errs) != 1 || !strings.Contains(errs[0].Error(), "exclude_apps") {
+		t.Errorf("scan = %d clips, errors %v; want the clip skipped and the error", n, errs)
+	}
+	clip = "yet another clip"
+	if n, errs := scan(); n != 0 || len(errs) != 0 {
+		t.Errorf("scan = %d clips, errors %v; want the clip skipped, the failure reported once", n, errs)
+	}
+}
+
 func TestLooksLikePassword(t *testing.T) {
 	tests := []struct {
 		text string
@@ -0,0 +1,382 @@
+package sources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// dataFieldSep separates the fields of a record on its line.
+const dataFieldSep = " | "
+
+// dataField is one column or field of a record.
+type dataField struct {
+	key, value string
+}
+
+// DataSource indexes structured data files (CSV, TSV, JSON and JSON Lines)
+// so that datasets and logs kept alongside notes can be found by content.
+// Each row or record becomes one line of the file's document, and Records
+// splits those lines into searchable entries of their own.
+type DataSource struct {
+	scanner    *Scanner
+	columns    map[string]bool
+	maxRecords int
+}
+
+// NewDataSource creates a new data source. Only the given columns (CSV and
+// TSV headers, or dotted JSON field names) are indexed, matched without
+// regard to case; none indexes them all. At most maxRecords records are read
+// from each file.
+func NewDataSource(paths, extensions, ignore, columns []string, maxRecords int) *DataSource {
+	d := &DataSource{
+		scanner: NewScanner(ScanConfig{
+			Paths:      paths,
+			Extensions: extensions,
+			Ignore:     ignore,
+		}),
+		maxRecords: maxRecords,
+	}
+	if len(columns) > 0 {
+		d.columns = make(map[string]bool, len(columns))
+		for _, c := range columns {
+			d.columns[strings.ToLower(strings.TrimSpace(c))] = true
+		}
+	}
+	return d
+}
+
+// Name returns the source name.
+func (d *DataSource) Name() storage.Source {
+	return storage.SourceData
+}
+
+// Scan walks configured paths and returns data files.
+func (d *DataSource) Scan(ctx context.Context) (<-chan FileInfo, <-chan error) {
+	return d.scanner.Scan(ctx)
+}
+
+// MatchesPath reports whether this source is configured to handle the path.
+func (d *DataSource) MatchesPath(path string) bool {
+	return d.scanner.MatchesPath(path)
+}
+
+// WatchPaths returns the configured data directories.
+func (d *DataSource) WatchPaths() []string {
+	return d.scanner.Roots()
+}
+
+// Parse reads a data file into a Document with one "key: value | key: value"
+// line per record. Files with more records than the limit are indexed up to
+// it, with a warning. Records are decoded as the file streams in, so only
+// the records kept are held in memory, not the whole file.
+func (d *DataSource) Parse(ctx context.Context, file FileInfo) (*storage.Document, error) {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	hash := sha256.New()
+	r := bufio.NewReader(io.TeeReader(f, hash))
+
+	var records [][]dataField
+	var truncated bool
+	switch strings.ToLower(filepath.Ext(file.Path)) {
+	case ".tsv", ".tab":
+		records, truncated, err = d.csvRecords(r, '\t')
+	case ".json":
+		records, truncated, err = d.jsonRecords(r)
+	case ".jsonl", ".ndjson":
+		records, truncated, err = d.jsonLineRecords(r)
+	default:
+		records, truncated, err = d.csvRecords(r, ',')
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The content hash covers the whole file, including records past the
+	// limit.
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(records))
+	for _, r := range records {
+		if line := formatRecord(r); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	text := strings.Join(lines, "\n")
+
+	pathHash := sha256.Sum256([]byte(file.Path))
+	doc := &storage.Document{
+		ID:          hex.EncodeToString(pathHash[:16]),
+		Source:      storage.SourceData,
+		Path:        file.Path,
+		Title:       filepath.Base(file.Path),
+		Content:     text,
+		Preview:     generatePreview(text, previewLen()),
+		Metadata:    map[string]string{"records": strconv.Itoa(len(lines))},
+		ContentHash: hex.EncodeToString(hash.Sum(nil)),
+		IndexedAt:   time.Now(),
+		ModifiedAt:  time.Unix(file.ModifiedAt, 0),
+	}
+	if truncated {
+		return partial(doc, []string{fmt.Sprintf("only the first %d records were indexed", d.maxRecords)})
+	}
+	return doc, nil
+}
+
+// csvRecords reads delimited rows, using the first row as the header.
+func (d *DataSource) csvRecords(br *bufio.Reader, comma rune) ([][]dataField, bool, error) {
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte("\ufeff")) {
+		_, _ = br.Discard(3)
+	}
+	r := csv.NewReader(br)
+	r.Comma = comma
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reading header: %w", err)
+	}
+	header = append([]string(nil), header...)
+	for i, h := range header {
+		if header[i] = strings.TrimSpace(h); header[i] == "" {
+			header[i] = "column " + strconv.Itoa(i+1)
+		}
+	}
+
+	var records [][]dataField
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records, false, nil
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("reading row: %w", err)
+		}
+		if len(records) == d.maxRecords {
+			return records, true, nil
+		}
+		var fields []dataField
+		for i, value := range row {
+			key := "column " + strconv.Itoa(i+1)
+			if i < len(header) {
+				key = header[i]
+			}
+			fields = d.appendField(fields, key, value)
+		}
+		records = append(records, fields)
+	}
+}
+
+// jsonRecords reads a JSON file: each element of a top-level array is a
+// record, and any other value is a single record. Array elements are
+// decoded one at a time.
+func (d *DataSource) jsonRecords(r *bufio.Reader) ([][]dataField, bool, error) {
+	dec := json.NewDecoder(r)
+	if !startsWithArray(r) {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, false, fmt.Errorf("parsing JSON: %w", err)
+		}
+		return [][]dataField{d.flatten(nil, "", v)}, false, nil
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, false, fmt.Errorf("parsing JSON: %w", err)
+	}
+	var records [][]dataField
+	for dec.More() {
+		var item any
+		if err := dec.Decode(&item); err != nil {
+			return nil, false, fmt.Errorf("parsing JSON: %w", err)
+		}
+		if len(records) == d.maxRecords {
+			return records, true, nil
+		}
+		records = append(records, d.flatten(nil, "", item))
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, false, fmt.Errorf("parsing JSON: %w", err)
+	}
+	return records, false, nil
+}
+
+// startsWithArray reports whether the first non-blank byte in r opens a
+// JSON array, without consuming it.
+func startsWithArray(r *bufio.Reader) bool {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return false
+		}
+		switch b {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+		_ = r.UnreadByte()
+		return b == '['
+	}
+}
+
+// jsonLineRecords reads JSON Lines, one record per non-blank line.
+func (d *DataSource) jsonLineRecords(r io.Reader) ([][]dataField, bool, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var records [][]dataField
+	for n := 1; sc.Scan(); n++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(line, &v); err != nil {
+			return nil, false, fmt.Errorf("parsing line %d: %w", n, err)
+		}
+		if len(records) == d.maxRecords {
+			return records, true, nil
+		}
+		records = append(records, d.flatten(nil, "", v))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, false, fmt.Errorf("reading lines: %w", err)
+	}
+	return records, false, nil
+}
+
+// flatten appends the fields of a JSON value, naming nested fields with
+// dotted keys ("author.name"). Lists of scalars are joined with commas.
+func (d *DataSource) flatten(fields []dataField, key string, v any) []dataField {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := k
+			if key != "" {
+				child = key + "." + k
+			}
+			fields = d.flatten(fields, child, v[k])
+		}
+		return fields
+	case []any:
+		var values []string
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				fields = d.flatten(fields, key, item)
+			default:
+				if s := jsonScalar(item); s != "" {
+					values = append(values, s)
+				}
+			}
+		}
+		return d.appendField(fields, key, strings.Join(values, ", "))
+	default:
+		if key == "" {
+			key = "value"
+		}
+		return d.appendField(fields, key, jsonScalar(v))
+	}
+}
+
+// appendField adds a non-blank field in an indexed column.
+func (d *DataSource) appendField(fields []dataField, key, value string) []dataField {
+	if d.columns != nil && !d.columns[strings.ToLower(key)] {
+		return fields
+	}
+	// Records are stored one per line, with fields split by " | ".
+	value = strings.Join(strings.Fields(value), " ")
+	value = strings.ReplaceAll(value, dataFieldSep, " / ")
+	if value == "" {
+		return fields
+	}
+	return append(fields, dataField{key: key, value: value})
+}
+
+func jsonScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func formatRecord(fields []dataField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.key + ": " + f.value
+	}
+	return strings.Join(parts, dataFieldSep)
+}
+
+// Records splits a data file's document into one document per record, so a
+// search can land on the matching row. Each record is titled "file » first
+// value" and its path is the file's path plus "#row-N". Files with fewer
+// than two records return nil.
+func Records(doc *storage.Document) []*storage.Document {
+	if doc.Source != storage.SourceData || doc.Content == "" {
+		return nil
+	}
+	lines := strings.Split(doc.Content, "\n")
+	if len(lines) < 2 {
+		return nil
+	}
+
+	records := make([]*storage.Document, 0, len(lines))
+	for i, line := range lines {
+		anchor := "row-" + strconv.Itoa(i+1)
+		label := "row " + strconv.Itoa(i+1)
+		first, _, _ := strings.Cut(line, dataFieldSep)
+		if _, value, ok := strings.Cut(first, ": "); ok {
+			label = value
+		}
+		records = append(records, &storage.Document{
+			ID:      doc.ID + "#" + anchor,
+			Source:  doc.Source,
+			Path:    doc.Path + "#" + anchor,
+			Title:   doc.Title + " » " + generatePreview(label, 80),
+			Content: line,
+			Preview: generatePreview(line, previewLen()),
+			Metadata: map[string]string{
+				"section_of": doc.ID,
+				"anchor":     anchor,
+			},
+			ContentHash:  hashContent(line),
+			IndexedAt:    doc.IndexedAt,
+			ModifiedAt:   doc.ModifiedAt,
+			DocumentDate: doc.DocumentDate,
+		})
+	}
+	return records
+}
@@ -0,0 +1,145 @@
+package sources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func parseDataFile(t *testing.T, d *DataSource, name, content string) (*storage.Document, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return d.Parse(context.Background(), FileInfo{Path: path, ModifiedAt: 1700000000})
+}
+
+func TestDataSourceParse(t *testing.T) {
+	d := NewDataSource(nil, nil, nil, nil, 100)
+	tests := []struct {
+		name, file, content string
+		want                []string
+	}{
+		{
+			name:    "csv with header",
+			file:    "books.csv",
+			content: "\ufefftitle,author,notes\nDune,Frank Herbert,\"sand,\nspice\"\nEmma,Jane Austen,\n",
+			want:    []string{"title: Dune | author: Frank Herbert | notes: sand, spice", "title: Emma | author: Jane Austen"},
+		},
+		{
+			name:    "tsv with ragged rows",
+			file:    "log.tsv",
+			content: "level\tmessage\nerror\tdisk full | retrying\textra\n",
+			want:    []string{"level: error | message: disk full / retrying | column 3: extra"},
+		},
+		{
+			name:    "json array of objects",
+			file:    "people.json",
+			content: `[{"name": "Ada", "meta": {"born": 1815, "tags": ["math", "poetry"]}}, {"name": "Alan"}]`,
+			want:    []string{"meta.born: 1815 | meta.tags: math, poetry | name: Ada", "name: Alan"},
+		},
+		{
+			name:    "json object",
+			file:    "settings.json",
+			content: `{"theme": "dark", "font": {"size": 12}}`,
+			want:    []string{"font.size: 12 | theme: dark"},
+		},
+		{
+			name:    "json lines",
+			file:    "events.jsonl",
+			content: "{\"event\": \"login\", \"user\": null}\n\n{\"event\": \"logout\"}\n",
+			want:    []string{"event: login", "event: logout"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseDataFile(t, d, tt.file, tt.content)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := strings.Split(doc.Content, "\n"); !slicesEqual(got, tt.want) {
+				t.Errorf("records = %q, want %q", got, tt.want)
+			}
+			if doc.Source != storage.SourceData || doc.Title != tt.file {
+				t.Errorf("source %q title %q", doc.Source, doc.Title)
+			}
+		})
+	}
+}
+
+func TestDataSourceColumnsAndLimit(t *testing.T) {
+	d := NewDataSource(nil, nil, nil, []string{"Message"}, 2)
+	doc, err := parseDataFile(t, d, "app.csv", "time,message\n1,started\n2,ready\n3,stopped\n")
+	var partialErr *PartialError
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("Parse() error = %v, want a partial parse past max_records", err)
+	}
+	if want := "message: started\nmessage: ready"; doc.Content != want {
+		t.Errorf("Content = %q, want %q", doc.Content, want)
+	}
+	if doc.Metadata["parse_warnings"] == "" {
+		t.Error("expected parse_warnings metadata")
+	}
+
+	if _, err := parseDataFile(t, d, "broken.json", "{not json"); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+	if _, err := parseDataFile(t, d, "broken-array.json", `[{"message": "a"}, {"message"`); err == nil {
+		t.Error("expected an error for a truncated JSON array")
+	}
+
+	// Reading stops at the limit, but the hash still covers the whole file.
+	content := `  [{"message": "one"}, {"message": "two"}, {"message": "three"}]`
+	doc, err = parseDataFile(t, d, "log.json", content)
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("Parse() error = %v, want a partial parse past max_records", err)
+	}
+	if want := "message: one\nmessage: two"; doc.Content != want {
+		t.Errorf("Content = %q, want %q", doc.Content, want)
+	}
+	if sum := sha256.Sum256([]byte(content)); doc.ContentHash != hex.EncodeToString(sum[:]) {
+		t.Errorf("ContentHash = %s, want the hash of the whole file", doc.ContentHash)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	doc := &storage.Document{
+		ID:      "data",
+		Source:  storage.SourceData,
+		Path:    "/data/books.csv",
+		Title:   "books.csv",
+		Content: "title: Dune | author: Frank Herbert\nauthor: Jane Austen",
+	}
+	records := Records(doc)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	first := records[0]
+	if first.ID != "data#row-1" || first.Path != "/data/books.csv#row-1" || first.Title != "books.csv » Dune" {
+		t.Errorf("first record = %q %q %q", first.ID, first.Path, first.Title)
+	}
+	if first.Content != "title: Dune | author: Frank Herbert" || !first.IsSection() || first.FilePath() != doc.Path {
+		t.Errorf("first record content %q, IsSection() = %v, FilePath() = %q", first.Content, first.IsSection(), first.FilePath())
+	}
+	if records[1].Title != "books.csv » Jane Austen" {
+		t.Errorf("second record title = %q", records[1].Title)
+	}
+
+	doc.Content = "title: Dune"
+	if got := Records(doc); got != nil {
+		t.Errorf("single record got %d entries, want none", len(got))
+	}
+	doc.Source = storage.SourceMarkdown
+	doc.Content = "a: 1\nb: 2"
+	if got := Records(doc); got != nil {
+		t.Errorf("markdown document got %d records, want none", len(got))
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -158,6 +159,15 @@ func (e *EmailSource) MatchesPath(path string) bool {
 	return false
 }
 
+// WatchPaths returns the configured mailbox files and maildir directories.
+func (e *EmailSource) WatchPaths() []string {
+	paths := make([]string, 0, len(e.paths))
+	for _, p := range e.paths {
+		paths = append(paths, expandPath(p))
+	}
+	return paths
+}
+
 // Parse reads an email file and returns the parsed document.
 // For mbox files, the first message is used as the document.
 func (e *EmailSource) Parse(ctx context.Context, file FileInfo) (*storage.Document, error) {
@@ -197,6 +207,7 @@ func (e *EmailSource) parseMbox(file FileInfo) (*storage.Document, error) {
 	var messages []emailMessage
 	var currentMsg strings.Builder
 	inMessage := false
+	malformed := 0
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -206,6 +217,8 @@ func (e *EmailSource) parseMbox(file FileInfo) (*storage.Document, error) {
 				msg, err := parseEmailMessage(strings.NewReader(currentMsg.String()))
 				if err == nil {
 					messages = append(messages, msg)
+				} else {
+					malformed++
 				}
 				currentMsg.Reset()
 			}
@@ -224,17 +237,27 @@ func (e *EmailSource) parseMbox(file FileInfo) (*storage.Document, error) {
 		msg, err := parseEmailMessage(strings.NewReader(currentMsg.String()))
 		if err == nil {
 			messages = append(messages, msg)
+		} else {
+			malformed++
 		}
 	}
+
+	var warnings []string
+	if malformed > 0 {
+		warnings = append(warnings, fmt.Sprintf("skipped %d malformed messages", malformed))
+	}
 	if err := scanner.Err(); err != nil {
 		_ = f.Close()
-		return nil, fmt.Errorf("reading mbox: %w", err)
-	}
-	if err := f.Close(); err != nil {
+		if len(messages) == 0 {
+			return nil, fmt.Errorf("reading mbox: %w", err)
+		}
+		// Keep the messages read before the failure.
+		warnings = append(warnings, fmt.Sprintf("reading stopped after %d messages: %v", len(messages), err))
+	} else if err := f.Close(); err != nil {
 		return nil, fmt.Errorf("closing mbox: %w", err)
 	}
 
-	return buildEmailDocument(file, messages, e.maskSensitivePreview), nil
+	return partial(buildEmailDocument(file, messages, e.maskSensitivePreview), warnings)
 }
 
 // parseEmlx parses an Apple Mail .emlx file.
@@ -244,22 +267,37 @@ func (e *EmailSource) parseEmlx(file FileInfo) (*storage.Document, error) {
 		return nil, fmt.Errorf("reading emlx: %w", err)
 	}
 
-	content := string(data)
-	// .emlx files start with a byte count on the first line, followed by the RFC 2822 message.
-	if idx := strings.Index(content, "\n"); idx != -1 {
-		content = content[idx+1:]
-	}
-	// Trim trailing Apple plist metadata
-	if idx := strings.Index(content, "<?xml"); idx != -1 {
-		content = content[:idx]
-	}
+	content, warnings := splitEmlx(string(data))
 
 	msg, err := parseEmailMessage(strings.NewReader(content))
 	if err != nil {
 		return nil, fmt.Errorf("parsing emlx message: %w", err)
 	}
 
-	return buildEmailDocument(file, []emailMessage{msg}, e.maskSensitivePreview), nil
+	return partial(buildEmailDocument(file, []emailMessage{msg}, e.maskSensitivePreview), warnings)
+}
+
+// splitEmlx extracts the RFC 2822 message from .emlx data. The file starts
+// with the message's byte count on its own line, and Apple plist metadata
+// follows the message. When the byte count is missing or wrong the message
+// is cut at the start of the plist instead, and a warning is returned.
+func splitEmlx(data string) (string, []string) {
+	header, rest, ok := strings.Cut(data, "\n")
+	if !ok {
+		return data, []string{"missing emlx byte count"}
+	}
+
+	if n, err := strconv.Atoi(strings.TrimSpace(header)); err == nil && n > 0 && n <= len(rest) {
+		if tail := strings.TrimSpace(rest[n:]); tail == "" || strings.HasPrefix(tail, "<?xml") || strings.HasPrefix(tail, "<!DOCTYPE") {
+			return rest[:n], nil
+		}
+	}
+
+	// Byte count unusable: fall back to trimming the trailing plist.
+	if idx := strings.LastIndex(rest, "<?xml"); idx != -1 {
+		rest = rest[:idx]
+	}
+	return rest, []string{"invalid emlx byte count; message boundary guessed"}
 }
 
 // parseSingleEmail parses a single .eml or maildir message.
@@ -466,6 +504,7 @@ func buildEmailDocument(file FileInfo, messages []emailMessage, maskSensitivePre
 	}
 
 	content := sb.String()
+	metadata["messages"] = strconv.Itoa(len(messages))
 	if len(attachments) > 0 {
 		var names []string
 		for name := range attachments {
@@ -482,19 +521,24 @@ func buildEmailDocument(file FileInfo, messages []emailMessage, maskSensitivePre
 		metadata["from"] = maskEmailMetadata(metadata["from"])
 		metadata["to"] = maskEmailMetadata(metadata["to"])
 	}
-	preview := generatePreview(content, 500)
+	body := messages[0].Body
+	if maskSensitivePreview {
+		body = maskSensitiveText(body)
+	}
+	preview := emailPreview(metadata["from"], messages[0].Subject, body, previewLen())
 
 	return &storage.Document{
-		ID:          hashPath(file.Path),
-		Source:      storage.SourceEmail,
-		Path:        file.Path,
-		Title:       title,
-		Content:     content,
-		Preview:     preview,
-		Metadata:    metadata,
-		ContentHash: hashContent(content),
-		IndexedAt:   time.Now(),
-		ModifiedAt:  time.Unix(file.ModifiedAt, 0),
+		ID:           hashPath(file.Path),
+		Source:       storage.SourceEmail,
+		Path:         file.Path,
+		Title:        title,
+		Content:      content,
+		Preview:      preview,
+		Metadata:     metadata,
+		ContentHash:  hashContent(content),
+		IndexedAt:    time.Now(),
+		ModifiedAt:   time.Unix(file.ModifiedAt, 0),
+		DocumentDate: messages[0].Date,
 	}
 }
 
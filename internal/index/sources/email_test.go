@@ -2,10 +2,13 @@ package sources
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/J-1000/mindcli/internal/storage"
 )
@@ -104,6 +107,7 @@ func TestParseSingleEmail(t *testing.T) {
 	emailContent := `From: alice@example.com
 To: bob@example.com
 Subject: Quick Note
+Date: Mon, 02 Jan 2023 15:04:05 +0000
 Content-Type: text/plain
 
 Just a quick note about the meeting tomorrow.
@@ -137,6 +141,9 @@ Just a quick note about the meeting tomorrow.
 	if doc.Metadata["from"] != "a***@example.com" {
 		t.Errorf("from = %q, want %q", doc.Metadata["from"], "a***@example.com")
 	}
+	if want := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC); !doc.DocumentDate.Equal(want) {
+		t.Errorf("DocumentDate = %v, want the Date header %v", doc.DocumentDate, want)
+	}
 }
 
 func TestStripHTML(t *testing.T) {
@@ -265,4 +272,68 @@ func TestBuildEmailDocumentIncludesAttachmentsMetadata(t *testing.T) {
 	if got := doc.Metadata["attachments"]; got != "a.pdf, b.png" {
 		t.Fatalf("attachments metadata = %q, want %q", got, "a.pdf, b.png")
 	}
+	if got := doc.Metadata["messages"]; got != "2" {
+		t.Errorf("messages metadata = %q, want 2", got)
+	}
+}
+
+func TestParseMboxSkipsMalformedMessages(t *testing.T) {
+	mboxContent := `From sender@example.com Mon Jan  1 12:00:00 2024
+From: sender@example.com
+Subject: Good Message
+
+Good body.
+
+From broken@example.com Tue Jan  2 12:00:00 2024
+this line is not a header
+
+Broken body.
+`
+	tmpDir := t.TempDir()
+	mboxPath := filepath.Join(tmpDir, "test.mbox")
+	if err := os.WriteFile(mboxPath, []byte(mboxContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewEmailSource([]string{tmpDir}, nil)
+	doc, err := src.Parse(context.Background(), FileInfo{Path: mboxPath})
+
+	var perr *PartialError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Parse error = %v, want *PartialError", err)
+	}
+	if doc == nil || !strings.Contains(doc.Content, "Good body") {
+		t.Fatalf("partial document missing parsed message: %+v", doc)
+	}
+	if !strings.Contains(doc.Metadata["parse_warnings"], "skipped 1 malformed messages") {
+		t.Errorf("parse_warnings = %q", doc.Metadata["parse_warnings"])
+	}
+}
+
+func TestSplitEmlx(t *testing.T) {
+	msg := "From: a@example.com\nSubject: Hi\n\nBody with <?xml inside\n"
+	plist := `<?xml version="1.0" encoding="UTF-8"?><plist version="1.0"><dict></dict></plist>`
+
+	tests := []struct {
+		name        string
+		data        string
+		want        string
+		wantWarning bool
+	}{
+		{"valid byte count", fmt.Sprintf("%d\n%s%s", len(msg), msg, plist), msg, false},
+		{"wrong byte count", "12\n" + msg + plist, msg, true},
+		{"garbage byte count", "abc\n" + msg + plist, msg, true},
+		{"no header line", "no newline at all", "no newline at all", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings := splitEmlx(tt.data)
+			if got != tt.want {
+				t.Errorf("message = %q, want %q", got, tt.want)
+			}
+			if (len(warnings) > 0) != tt.wantWarning {
+				t.Errorf("warnings = %v, want warning: %v", warnings, tt.wantWarning)
+			}
+		})
+	}
 }
@@ -0,0 +1,224 @@
+package sources
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// gitRefreshInterval is how long a repository's history is reused by Parse
+// before HEAD is checked again. Scan always checks it.
+const gitRefreshInterval = time.Minute
+
+// gitMetadataKeys are the metadata fields git history adds to a note.
+var gitMetadataKeys = []string{"git_last_commit", "git_author", "git_commits"}
+
+// gitFile is the history of one file in a git repository.
+type gitFile struct {
+	LastCommit time.Time
+	Author     string // author of the last commit
+	Commits    int
+	Blob       plumbing.Hash // the file's content at HEAD
+}
+
+// metadata returns the file's history as document metadata.
+func (f *gitFile) metadata() map[string]string {
+	return map[string]string{
+		"git_last_commit": f.LastCommit.Format(time.RFC3339),
+		"git_author":      f.Author,
+		"git_commits":     strconv.Itoa(f.Commits),
+	}
+}
+
+// gitRepo is what git knows about the files under one configured folder.
+type gitRepo struct {
+	prefix   string              // the folder's path inside the repository, "" or ending in "/"
+	head     plumbing.Hash       // commit the history was read at
+	files    map[string]*gitFile // by slash-separated path from the repository root
+	loadedAt time.Time
+}
+
+// gitHistory caches the git history of the repositories holding a source's
+// folders. Folders outside a repository are remembered as such.
+type gitHistory struct {
+	mu    sync.Mutex
+	repos map[string]*gitRepo // by folder; nil when not in a repository
+}
+
+func newGitHistory() *gitHistory {
+	return &gitHistory{repos: make(map[string]*gitRepo)}
+}
+
+// refresh rereads the history of the repository holding root when HEAD has
+// moved since it was last read.
+func (h *gitHistory) refresh(ctx context.Context, root string) *gitRepo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.refreshLocked(ctx, root)
+}
+
+func (h *gitHistory) refreshLocked(ctx context.Context, root string) *gitRepo {
+	repo, err := git.PlainOpenWithOptions(root, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		// Not in a repository.
+		h.repos[root] = nil
+		return nil
+	}
+	head, err := repo.Head()
+	if err != nil {
+		// No commits yet.
+		h.repos[root] = nil
+		return nil
+	}
+	if cached := h.repos[root]; cached != nil && cached.head == head.Hash() {
+		cached.loadedAt = time.Now()
+		return cached
+	}
+	prefix, err := gitPrefix(repo, root)
+	if err != nil {
+		h.repos[root] = nil
+		return nil
+	}
+	files, err := readGitHistory(ctx, repo, head.Hash(), prefix)
+	if err != nil {
+		h.repos[root] = nil
+		return nil
+	}
+	r := &gitRepo{prefix: prefix, head: head.Hash(), files: files, loadedAt: time.Now()}
+	h.repos[root] = r
+	return r
+}
+
+// lookup returns the history of the file at path, found under one of roots.
+// ok is false for files outside a repository or not committed at HEAD.
+func (h *gitHistory) lookup(ctx context.Context, roots []string, path string) (file *gitFile, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		repo, seen := h.repos[root]
+		if !seen || repo != nil && time.Since(repo.loadedAt) > gitRefreshInterval {
+			repo = h.refreshLocked(ctx, root)
+		}
+		if repo == nil {
+			return nil, false
+		}
+		file, ok := repo.files[repo.prefix+filepath.ToSlash(rel)]
+		return file, ok
+	}
+	return nil, false
+}
+
+// gitPrefix returns the path of root inside the work tree of repo, "" for
+// the top of the work tree or ending in "/".
+func gitPrefix(repo *git.Repository, root string) (string, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	top, err := filepath.EvalSymlinks(wt.Filesystem.Root())
+	if err != nil {
+		return "", err
+	}
+	dir, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(top, dir)
+	if err != nil {
+		return "", err
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel) + "/", nil
+}
+
+// readGitHistory walks the history from head and returns, for each file
+// under prefix at head, its last commit, that commit's author and the number
+// of commits that changed it. Like git log, merge commits are not counted.
+func readGitHistory(ctx context.Context, repo *git.Repository, head plumbing.Hash, prefix string) (map[string]*gitFile, error) {
+	commit, err := repo.CommitObject(head)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*gitFile)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if strings.HasPrefix(f.Name, prefix) {
+			files[f.Name] = &gitFile{Blob: f.Hash}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := repo.Log(&git.LogOptions{From: head})
+	if err != nil {
+		return nil, err
+	}
+	defer commits.Close()
+	err = commits.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if c.NumParents() > 1 {
+			return nil
+		}
+		tree, err := c.Tree()
+		if err != nil {
+			return err
+		}
+		var parent *object.Tree
+		if c.NumParents() == 1 {
+			p, err := c.Parent(0)
+			if err != nil {
+				return err
+			}
+			if parent, err = p.Tree(); err != nil {
+				return err
+			}
+		}
+		changes, err := object.DiffTreeContext(ctx, parent, tree)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			f := files[change.To.Name]
+			if f == nil {
+				continue
+			}
+			f.Commits++
+			if when := c.Committer.When; when.After(f.LastCommit) {
+				f.LastCommit = when.Local()
+				f.Author = c.Author.Name
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A file only brought in by a merge has no commit of its own.
+	for name, f := range files {
+		if f.Commits == 0 {
+			delete(files, name)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,131 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestMarkdownSourceGitMetadata(t *testing.T) {
+	repoDir := t.TempDir()
+	notes := filepath.Join(repoDir, "notes")
+	if err := os.MkdirAll(notes, 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(author string, when time.Time, files ...string) {
+		t.Helper()
+		for _, f := range files {
+			if _, err := wt.Add(filepath.ToSlash(filepath.Join("notes", f))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		sig := &object.Signature{Name: author, Email: author + "@example.com", When: when}
+		if _, err := wt.Commit("update", &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(notes, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	first := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	lastCommit := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+	clean := write("clean.md", "# Clean\n")
+	edited := write("edited.md", "# Edited\n")
+	commit("Ann", first, "clean.md", "edited.md")
+	write("clean.md", "# Clean\n\nMore.\n")
+	commit("Ann", lastCommit, "clean.md")
+	write("edited.md", "# Edited\n\nNot committed yet.\n")
+	untracked := write("new.md", "# New\n")
+
+	src := NewMarkdownSource([]string{notes}, []string{".md"}, nil)
+	src.SetGitMetadata(true)
+	ctx := context.Background()
+	files, errs := src.Scan(ctx)
+	scanned := make(map[string]FileInfo)
+	for f := range files {
+		scanned[f.Path] = f
+	}
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	// Change detection keeps using the files' own mtimes.
+	for _, p := range []string{clean, edited, untracked} {
+		if got := scanned[p].ModifiedAt; got < time.Now().Add(-time.Hour).Unix() {
+			t.Errorf("%s ModifiedAt = %v, want its mtime", filepath.Base(p), time.Unix(got, 0))
+		}
+	}
+
+	// The committed, unchanged note is dated by its last commit.
+	cleanDoc, err := src.Parse(ctx, scanned[clean])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cleanDoc.Metadata["git_author"] != "Ann" || cleanDoc.Metadata["git_commits"] != "2" ||
+		cleanDoc.Metadata["git_last_commit"] != lastCommit.Local().Format(time.RFC3339) || !cleanDoc.DocumentDate.Equal(lastCommit) {
+		t.Errorf("clean.md metadata = %v, date %v", cleanDoc.Metadata, cleanDoc.DocumentDate)
+	}
+	editedDoc, err := src.Parse(ctx, scanned[edited])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if editedDoc.Metadata["git_commits"] != "1" || !editedDoc.DocumentDate.IsZero() {
+		t.Errorf("edited.md metadata = %v, date %v; want history but no commit date", editedDoc.Metadata, editedDoc.DocumentDate)
+	}
+	untrackedDoc, err := src.Parse(ctx, scanned[untracked])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := untrackedDoc.Metadata["git_commits"]; ok {
+		t.Errorf("untracked note got git metadata %v", untrackedDoc.Metadata)
+	}
+
+	// Committing a note outdates its document even though the file is
+	// untouched; the others stay current.
+	if src.Outdated(ctx, clean, cleanDoc) || src.Outdated(ctx, untracked, untrackedDoc) {
+		t.Error("unchanged notes reported outdated")
+	}
+	commit("Ben", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), "edited.md")
+	src.git.refresh(ctx, notes)
+	if !src.Outdated(ctx, edited, editedDoc) {
+		t.Error("edited.md not outdated after being committed")
+	}
+
+	// Turning git metadata off outdates documents that have it.
+	src.SetGitMetadata(false)
+	if !src.Outdated(ctx, clean, cleanDoc) || src.Outdated(ctx, untracked, untrackedDoc) {
+		t.Error("only notes with git metadata should be outdated once it is off")
+	}
+
+	// Outside a repository nothing changes.
+	plain := t.TempDir()
+	src = NewMarkdownSource([]string{plain}, []string{".md"}, nil)
+	src.SetGitMetadata(true)
+	path := filepath.Join(plain, "a.md")
+	if err := os.WriteFile(path, []byte("# A\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := src.Parse(ctx, FileInfo{Path: path})
+	if err != nil || doc.Metadata["git_author"] != "" {
+		t.Errorf("note outside git = %v, %v", doc.Metadata, err)
+	}
+}
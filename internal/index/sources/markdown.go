@@ -5,13 +5,21 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"gopkg.in/yaml.v3"
 
 	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/pkg/chunker"
 )
 
 var (
@@ -29,11 +37,30 @@ var (
 
 	// Markdown link regex [text](url)
 	mdLinkRegex = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+
+	// Inline code spans, `code` or ``code with ` inside``
+	inlineCodeRegex = regexp.MustCompile("``.+?``|`[^`\n]+`")
+
+	// URLs, whose fragments and paths may contain #
+	urlRegex = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://\S+`)
+
+	// A heading's closing sequence ("## Heading ##") and trailing #tags
+	headingClosingRegex = regexp.MustCompile(`\s+#+\s*$`)
+	headingTagsRegex    = regexp.MustCompile(`(\s+#[a-zA-Z][a-zA-Z0-9_-]*)+\s*$`)
 )
 
 // MarkdownSource indexes markdown files.
 type MarkdownSource struct {
 	scanner *Scanner
+	vaults  []noteVault
+	git     *gitHistory // nil unless git metadata is enabled
+}
+
+// noteVault is a named set of note directories, whose notes are tagged with
+// its name.
+type noteVault struct {
+	name    string
+	scanner *Scanner
 }
 
 // NewMarkdownSource creates a new markdown source.
@@ -52,17 +79,109 @@ func (m *MarkdownSource) Name() storage.Source {
 	return storage.SourceMarkdown
 }
 
-// Scan walks configured paths and returns markdown files.
+// SetGitMetadata enables reading git history for notes in git repositories:
+// each note gets its last commit date, author and commit count as metadata,
+// and notes as last committed are dated by that commit, which unlike the
+// file's mtime survives syncing and fresh clones. Change detection still
+// uses the mtime.
+func (m *MarkdownSource) SetGitMetadata(enabled bool) {
+	m.git = nil
+	if enabled {
+		m.git = newGitHistory()
+	}
+}
+
+// AddVault adds named note directories, scanned with the source's
+// extensions and its ignore list extended by ignore. Their notes get the
+// vault's name as "vault" metadata. A note within several vaults, or within
+// a vault and the source's own paths, belongs to the vault added first.
+func (m *MarkdownSource) AddVault(name string, paths, ignore []string) {
+	m.vaults = append(m.vaults, noteVault{
+		name: name,
+		scanner: NewScanner(ScanConfig{
+			Paths:      paths,
+			Extensions: m.scanner.config.Extensions,
+			Ignore:     append(slices.Clone(m.scanner.config.Ignore), ignore...),
+		}),
+	})
+}
+
+// vaultOf returns the name of the vault path is in, or "" for a path only
+// in the source's own paths. ok is false when the source doesn't handle the
+// path, including when the vault it is in ignores it.
+func (m *MarkdownSource) vaultOf(path string) (name string, ok bool) {
+	for _, v := range m.vaults {
+		if v.scanner.Contains(path) {
+			return v.name, v.scanner.MatchesPath(path)
+		}
+	}
+	return "", m.scanner.MatchesPath(path)
+}
+
+// roots returns the source's own paths followed by those of its vaults.
+func (m *MarkdownSource) roots() []string {
+	roots := m.scanner.Roots()
+	for _, v := range m.vaults {
+		roots = append(roots, v.scanner.Roots()...)
+	}
+	return roots
+}
+
+// Scan walks configured paths and vaults and returns markdown files.
 func (m *MarkdownSource) Scan(ctx context.Context) (<-chan FileInfo, <-chan error) {
-	return m.scanner.Scan(ctx)
+	files, errs := m.scanner.Scan(ctx)
+	if len(m.vaults) > 0 {
+		scanners := []*Scanner{m.scanner}
+		for _, v := range m.vaults {
+			scanners = append(scanners, v.scanner)
+		}
+		files, errs = scanAll(ctx, scanners, func(i int, f FileInfo) bool {
+			name, ok := m.vaultOf(f.Path)
+			if i == 0 {
+				return ok && name == ""
+			}
+			return ok && name == m.vaults[i-1].name
+		})
+	}
+	if m.git != nil {
+		for _, root := range m.roots() {
+			m.git.refresh(ctx, root)
+		}
+	}
+	return files, errs
+}
+
+// Outdated reports whether doc, indexed earlier from path, has git metadata
+// that no longer matches the repository: the note was committed since, or
+// git metadata was turned on or off.
+func (m *MarkdownSource) Outdated(ctx context.Context, path string, doc *storage.Document) bool {
+	var want map[string]string
+	if m.git != nil {
+		if gf, ok := m.git.lookup(ctx, m.roots(), path); ok {
+			want = gf.metadata()
+		}
+	}
+	for _, key := range gitMetadataKeys {
+		if doc.Metadata[key] != want[key] {
+			return true
+		}
+	}
+	return false
 }
 
 // MatchesPath reports whether this source is configured to handle the path.
 func (m *MarkdownSource) MatchesPath(path string) bool {
-	return m.scanner.MatchesPath(path)
+	_, ok := m.vaultOf(path)
+	return ok
 }
 
-// Parse reads and parses a markdown file into a Document.
+// WatchPaths returns the configured note directories, vaults included.
+func (m *MarkdownSource) WatchPaths() []string {
+	return m.roots()
+}
+
+// Parse reads and parses a markdown file into a Document. Obsidian canvases,
+// Excalidraw drawings and Jupyter notebooks are converted to markdown first.
 func (m *MarkdownSource) Parse(ctx context.Context, file FileInfo) (*storage.Document, error) {
 	content, err := os.ReadFile(file.Path)
 	if err != nil {
@@ -70,6 +189,18 @@ func (m *MarkdownSource) Parse(ctx context.Context, file FileInfo) (*storage.Doc
 	}
 
 	text := string(content)
+	var language string
+	switch strings.ToLower(filepath.Ext(file.Path)) {
+	case ".canvas":
+		text, err = canvasText(content)
+	case ".excalidraw":
+		text, err = excalidrawText(content)
+	case ".ipynb":
+		text, language, err = notebookMarkdown(content)
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	// Calculate content hash
 	hash := sha256.Sum256(content)
@@ -85,8 +216,8 @@ func (m *MarkdownSource) Parse(ctx context.Context, file FileInfo) (*storage.Doc
 		title = strings.TrimSuffix(filepath.Base(file.Path), filepath.Ext(file.Path))
 	}
 
-	// Create preview (first ~500 chars of body content)
-	preview := createPreview(parsed.Body, 500)
+	// Create preview from the start of the body content
+	preview := createPreview(parsed.Body, previewLen())
 
 	// Build metadata
 	metadata := make(map[string]string)
@@ -100,30 +231,78 @@ func (m *MarkdownSource) Parse(ctx context.Context, file FileInfo) (*storage.Doc
 	if len(parsed.Headings) > 0 {
 		metadata["headings"] = strings.Join(parsed.Headings, ",")
 	}
+	if language != "" {
+		metadata["language"] = language
+	}
 
 	// Include frontmatter fields
 	for k, v := range parsed.Frontmatter {
 		metadata["fm_"+k] = v
 	}
 
+	if vault, _ := m.vaultOf(file.Path); vault != "" {
+		metadata["vault"] = vault
+	}
+
+	date, _ := parseDocumentDate(parsed.Frontmatter["date"])
+
+	if m.git != nil {
+		if gf, ok := m.git.lookup(ctx, m.roots(), file.Path); ok {
+			maps.Copy(metadata, gf.metadata())
+			// A note as last committed is dated by that commit, unless its
+			// frontmatter gives a date.
+			if date.IsZero() && plumbing.ComputeHash(plumbing.BlobObject, content) == gf.Blob {
+				date = gf.LastCommit
+			}
+		}
+	}
+
 	// Generate ID from path (stable across re-indexing)
 	pathHash := sha256.Sum256([]byte(file.Path))
 	id := hex.EncodeToString(pathHash[:16])
 
 	return &storage.Document{
-		ID:          id,
-		Source:      storage.SourceMarkdown,
-		Path:        file.Path,
-		Title:       title,
-		Content:     parsed.Body,
-		Preview:     preview,
-		Metadata:    metadata,
-		ContentHash: contentHash,
-		IndexedAt:   time.Now(),
-		ModifiedAt:  time.Unix(file.ModifiedAt, 0),
+		ID:           id,
+		Source:       storage.SourceMarkdown,
+		Path:         file.Path,
+		Title:        title,
+		Content:      parsed.Body,
+		Preview:      preview,
+		Metadata:     metadata,
+		ContentHash:  contentHash,
+		IndexedAt:    time.Now(),
+		ModifiedAt:   time.Unix(file.ModifiedAt, 0),
+		DocumentDate: date,
 	}, nil
 }
 
+// documentDateLayouts are the frontmatter date formats understood, most
+// specific first.
+var documentDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+// parseDocumentDate parses a frontmatter date such as "2024-03-01" or
+// "2024-03-01T09:30:00Z". Dates without a zone are in local time.
+func parseDocumentDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range documentDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
 // ParsedMarkdown contains parsed markdown content.
 type ParsedMarkdown struct {
 	Title       string
@@ -153,11 +332,15 @@ func parseMarkdown(content string) ParsedMarkdown {
 		}
 	}
 
+	// Headings and tags inside fenced code blocks, such as a "# comment" in
+	// a shell snippet, aren't part of the note's structure.
+	prose := withoutFencedCode(body)
+
 	// Extract headings
-	headingMatches := headingRegex.FindAllStringSubmatch(body, -1)
+	headingMatches := headingRegex.FindAllStringSubmatch(prose, -1)
 	for _, match := range headingMatches {
 		if len(match) > 2 {
-			heading := strings.TrimSpace(match[2])
+			heading := cleanHeading(match[2])
 			result.Headings = append(result.Headings, heading)
 
 			// Use first H1 as title if not set
@@ -167,16 +350,21 @@ func parseMarkdown(content string) ParsedMarkdown {
 		}
 	}
 
-	// Extract tags
-	tagMatches := tagRegex.FindAllStringSubmatch(body, -1)
+	// Extract tags: frontmatter tags first, then #tags in the body
 	tagSet := make(map[string]bool)
+	addTag := func(tag string) {
+		if !tagSet[tag] {
+			tagSet[tag] = true
+			result.Tags = append(result.Tags, tag)
+		}
+	}
+	for _, tag := range frontmatterTags(result.Frontmatter["tags"]) {
+		addTag(tag)
+	}
+	tagMatches := tagRegex.FindAllStringSubmatch(tagText(prose), -1)
 	for _, match := range tagMatches {
 		if len(match) > 1 {
-			tag := strings.ToLower(match[1])
-			if !tagSet[tag] {
-				tagSet[tag] = true
-				result.Tags = append(result.Tags, tag)
-			}
+			addTag(strings.ToLower(match[1]))
 		}
 	}
 
@@ -200,15 +388,138 @@ func parseMarkdown(content string) ParsedMarkdown {
 	return result
 }
 
-// parseFrontmatter extracts key-value pairs from YAML frontmatter.
+// withoutFencedCode blanks out the lines of fenced code blocks (``` or ~~~),
+// keeping the line count. An unclosed fence runs to the end of the content.
+func withoutFencedCode(content string) string {
+	var sb strings.Builder
+	var fence string
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence == "":
+			if f := fenceMarker(trimmed); f != "" {
+				fence = f
+				break
+			}
+			sb.WriteString(line)
+			continue
+		case strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "":
+			fence = ""
+		}
+		if strings.HasSuffix(line, "\n") {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// fenceMarker returns the ``` or ~~~ run opening a fenced code block, or ""
+// when line doesn't open one.
+func fenceMarker(line string) string {
+	if !strings.HasPrefix(line, "```") && !strings.HasPrefix(line, "~~~") {
+		return ""
+	}
+	n := len(line) - len(strings.TrimLeft(line, line[:1]))
+	return line[:n]
+}
+
+// tagText returns prose with the places a # doesn't start a tag removed:
+// inline code, URLs and escaped \# hashes.
+func tagText(prose string) string {
+	prose = inlineCodeRegex.ReplaceAllString(prose, " ")
+	prose = urlRegex.ReplaceAllString(prose, " ")
+	return strings.ReplaceAll(prose, `\#`, " ")
+}
+
+// cleanHeading trims a heading's closing #s and trailing #tags, which are
+// indexed as tags rather than as part of the heading text.
+func cleanHeading(heading string) string {
+	heading = strings.TrimSpace(heading)
+	cleaned := headingClosingRegex.ReplaceAllString(" "+heading, "")
+	cleaned = strings.TrimSpace(headingTagsRegex.ReplaceAllString(" "+strings.TrimSpace(cleaned), ""))
+	if cleaned == "" {
+		return heading
+	}
+	return cleaned
+}
+
+// maxFrontmatterDepth bounds how deeply nested frontmatter is flattened, so
+// self-referencing YAML aliases can't recurse forever.
+const maxFrontmatterDepth = 10
+
+// parseFrontmatter extracts fields from YAML frontmatter. Nested maps are
+// flattened into dotted keys ("project.status"), and lists of values are
+// joined with ", ". Frontmatter that isn't valid YAML, which is common in
+// hand-written notes, falls back to reading simple "key: value" lines.
 func parseFrontmatter(content string) map[string]string {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil ||
+		len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return parseFrontmatterLines(content)
+	}
+	result := make(map[string]string)
+	flattenFrontmatter(result, "", root.Content[0], 0)
+	return result
+}
+
+func flattenFrontmatter(out map[string]string, key string, node *yaml.Node, depth int) {
+	if depth > maxFrontmatterDepth {
+		return
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k := strings.TrimSpace(node.Content[i].Value)
+			if k == "" {
+				continue
+			}
+			if key != "" {
+				k = key + "." + k
+			}
+			flattenFrontmatter(out, k, node.Content[i+1], depth+1)
+		}
+	case yaml.SequenceNode:
+		var values []string
+		for i, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				if v := scalarValue(item); v != "" {
+					values = append(values, v)
+				}
+				continue
+			}
+			flattenFrontmatter(out, fmt.Sprintf("%s.%d", key, i), item, depth+1)
+		}
+		if len(values) > 0 {
+			out[key] = strings.Join(values, ", ")
+		}
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			flattenFrontmatter(out, key, node.Alias, depth+1)
+		}
+	case yaml.ScalarNode:
+		if v := scalarValue(node); v != "" {
+			out[key] = v
+		}
+	}
+}
+
+// scalarValue returns a scalar's text as written, or "" for null.
+func scalarValue(node *yaml.Node) string {
+	if node.ShortTag() == "!!null" {
+		return ""
+	}
+	return strings.TrimSpace(node.Value)
+}
+
+// parseFrontmatterLines reads simple "key: value" lines, for frontmatter
+// the YAML parser rejects.
+func parseFrontmatterLines(content string) map[string]string {
 	result := make(map[string]string)
 
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		// Simple key: value parsing (doesn't handle nested YAML)
 		if idx := strings.Index(line, ":"); idx > 0 {
 			key := strings.TrimSpace(line[:idx])
 			value := strings.TrimSpace(line[idx+1:])
@@ -230,6 +541,18 @@ func parseFrontmatter(content string) map[string]string {
 	return result
 }
 
+// frontmatterTags splits a frontmatter tags value, written as a list or as
+// a comma- or space-separated string, into normalized tags.
+func frontmatterTags(value string) []string {
+	var tags []string
+	for _, t := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		if t = strings.ToLower(strings.TrimLeft(t, "#")); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
 // createPreview creates a preview from content.
 func createPreview(content string, maxLen int) string {
 	// Remove markdown formatting for cleaner preview
@@ -261,14 +584,5 @@ func createPreview(content string, maxLen int) string {
 	preview = regexp.MustCompile(`\s+`).ReplaceAllString(preview, " ")
 	preview = strings.TrimSpace(preview)
 
-	if len(preview) > maxLen {
-		// Find a good break point
-		preview = preview[:maxLen]
-		if lastSpace := strings.LastIndex(preview, " "); lastSpace > maxLen*3/4 {
-			preview = preview[:lastSpace]
-		}
-		preview += "..."
-	}
-
-	return preview
+	return chunker.Truncate(preview, maxLen)
 }
@@ -2,9 +2,11 @@ package sources
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestParseMarkdown(t *testing.T) {
@@ -32,7 +34,7 @@ Some content with #tag1 and #tag2.
 Link to [[Another Note]] and [External](https://example.com).
 `,
 			wantTitle: "My Note",
-			wantTags:  []string{"tag1", "tag2"},
+			wantTags:  []string{"test", "demo", "tag1", "tag2"},
 			wantLinks: []string{"Another Note", "https://example.com"},
 			wantFM: map[string]string{
 				"title": "My Note",
@@ -41,6 +43,54 @@ Link to [[Another Note]] and [External](https://example.com).
 			},
 			wantHeadings: []string{"Heading One"},
 		},
+		{
+			name: "nested frontmatter and lists",
+			content: `---
+title: "Roadmap: Q3"
+project:
+  name: Alpha
+  owner:
+    name: Sam
+tags:
+  - Planning
+  - "#work"
+aliases:
+  - Q3 plan
+  - Alpha roadmap
+summary: |
+  First line.
+  Second line.
+draft: null
+---
+
+Body with #planning.
+`,
+			wantTitle: "Roadmap: Q3",
+			wantTags:  []string{"planning", "work"},
+			wantFM: map[string]string{
+				"title":              "Roadmap: Q3",
+				"project.name":       "Alpha",
+				"project.owner.name": "Sam",
+				"tags":               "Planning, #work",
+				"aliases":            "Q3 plan, Alpha roadmap",
+				"summary":            "First line.\nSecond line.",
+				"draft":              "",
+			},
+		},
+		{
+			name: "invalid YAML falls back to key: value lines",
+			content: `---
+title: Notes: misc
+tags: a b
+---
+`,
+			wantTitle: "Notes: misc",
+			wantTags:  []string{"a", "b"},
+			wantFM: map[string]string{
+				"title": "Notes: misc",
+				"tags":  "a b",
+			},
+		},
 		{
 			name: "no frontmatter, h1 title",
 			content: `# My Title
@@ -79,14 +129,29 @@ Also see [Google](https://google.com) and [GitHub](https://github.com).`,
 			},
 		},
 		{
-			name:    "code blocks should not extract tags",
-			content: "# Title\n\nReal #tag here.\n\n```go\n// #notag\nfunc main() {}\n```\n",
-			// Note: Our simple parser doesn't handle code blocks for tags yet
-			// This test documents current behavior
+			name:         "code blocks should not extract tags",
+			content:      "# Title\n\nReal #tag here.\n\n```go\n// #notag\nfunc main() {}\n```\n",
 			wantTitle:    "Title",
-			wantTags:     []string{"tag", "notag"}, // Known limitation
+			wantTags:     []string{"tag"},
 			wantHeadings: []string{"Title"},
 		},
+		{
+			name:         "headings inside code blocks are ignored",
+			content:      "Setup:\n\n~~~bash\n# install deps #notag\nmake\n~~~\n\n## Usage\n````md\n```\n# still code\n````\n",
+			wantHeadings: []string{"Usage"},
+		},
+		{
+			name:     "code spans, URLs and escaped hashes are not tags",
+			content:  "Use `#define` or ``a #b``, see https://example.com/#fragment and <https://x.io/#/route>.\nIssue \\#42 and \\#nottag, but #real.\n",
+			wantTags: []string{"real"},
+		},
+		{
+			name:         "heading closing sequence and trailing tags",
+			content:      "# Weekly review ## \n\n## Plans #work #q3\n\n## #inbox\n",
+			wantTitle:    "Weekly review",
+			wantTags:     []string{"work", "q3", "inbox"},
+			wantHeadings: []string{"Weekly review", "Plans", "#inbox"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -227,6 +292,56 @@ It has #tags and [[links]].
 	}
 }
 
+func TestMarkdownSource_DocumentDate(t *testing.T) {
+	tmpDir := t.TempDir()
+	dated := filepath.Join(tmpDir, "dated.md")
+	undated := filepath.Join(tmpDir, "undated.md")
+	if err := os.WriteFile(dated, []byte("---\ndate: 2021-03-14\n---\n\nPi day notes.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(undated, []byte("---\ndate: someday\n---\n\nNo real date.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewMarkdownSource([]string{tmpDir}, []string{".md"}, nil)
+	doc, err := source.Parse(context.Background(), FileInfo{Path: dated, ModifiedAt: time.Now().Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 3, 14, 0, 0, 0, 0, time.Local); !doc.DocumentDate.Equal(want) {
+		t.Errorf("DocumentDate = %v, want %v", doc.DocumentDate, want)
+	}
+
+	doc, err = source.Parse(context.Background(), FileInfo{Path: undated, ModifiedAt: time.Now().Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.DocumentDate.IsZero() {
+		t.Errorf("DocumentDate = %v for an unparseable date, want zero", doc.DocumentDate)
+	}
+}
+
+func TestParseDocumentDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), true},
+		{"2024/03/01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), true},
+		{"2024-03-01 09:30", time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local), true},
+		{"2024-03-01T09:30:00Z", time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC), true},
+		{"", time.Time{}, false},
+		{"March", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseDocumentDate(tt.value)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("parseDocumentDate(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
 func TestMarkdownSource_TitleFallback(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -286,3 +401,57 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestMarkdownSource_Vaults(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel string) string {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# "+rel+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	inbox := write("notes/inbox.md")
+	plan := write("notes/work/plan.md") // within both the notes and the work vault
+	write("notes/work/drafts/idea.md")  // ignored by the work vault only
+	diary := write("personal/diary.md")
+	write("personal/node_modules/pkg.md")
+
+	source := NewMarkdownSource([]string{filepath.Join(dir, "notes")}, []string{".md"}, []string{"node_modules"})
+	source.AddVault("work", []string{filepath.Join(dir, "notes", "work")}, []string{"drafts"})
+	source.AddVault("personal", []string{filepath.Join(dir, "personal")}, nil)
+
+	ctx := context.Background()
+	files, errs := source.Scan(ctx)
+	vaults := make(map[string]string)
+	for f := range files {
+		if _, seen := vaults[f.Path]; seen {
+			t.Errorf("%s scanned twice", f.Path)
+		}
+		doc, err := source.Parse(ctx, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vaults[f.Path] = doc.Metadata["vault"]
+	}
+	for err := range errs {
+		t.Errorf("scan error: %v", err)
+	}
+	want := map[string]string{inbox: "", plan: "work", diary: "personal"}
+	if fmt.Sprint(vaults) != fmt.Sprint(want) {
+		t.Errorf("vaults = %v, want %v", vaults, want)
+	}
+
+	if source.MatchesPath(filepath.Join(dir, "notes", "work", "drafts", "idea.md")) {
+		t.Error("MatchesPath matched a note the work vault ignores")
+	}
+	if !source.MatchesPath(diary) || source.MatchesPath(filepath.Join(dir, "other", "x.md")) {
+		t.Error("MatchesPath should match vault notes and nothing outside the vaults")
+	}
+	if got := len(source.WatchPaths()); got != 3 {
+		t.Errorf("WatchPaths() = %v, want the notes and both vaults", source.WatchPaths())
+	}
+}
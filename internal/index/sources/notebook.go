@@ -0,0 +1,75 @@
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// notebookFile is a Jupyter notebook (.ipynb, nbformat 4).
+type notebookFile struct {
+	Cells []struct {
+		CellType string       `json:"cell_type"`
+		Source   notebookText `json:"source"`
+	} `json:"cells"`
+	Metadata struct {
+		KernelSpec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+// notebookText is a cell source, stored either as one string or as a list
+// of lines.
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = notebookText(s)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return err
+	}
+	*t = notebookText(strings.Join(lines, ""))
+	return nil
+}
+
+// notebookMarkdown converts a Jupyter notebook to markdown: markdown cells
+// as they are and code cells as fenced code blocks. Outputs are dropped,
+// since they are mostly numbers, tables and images. It also returns the
+// notebook's language.
+func notebookMarkdown(data []byte) (text, language string, err error) {
+	var nb notebookFile
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return "", "", fmt.Errorf("parsing notebook: %w", err)
+	}
+	language = nb.Metadata.LanguageInfo.Name
+	if language == "" {
+		language = nb.Metadata.KernelSpec.Language
+	}
+
+	var parts []string
+	for _, cell := range nb.Cells {
+		src := strings.TrimSpace(string(cell.Source))
+		if src == "" {
+			continue
+		}
+		switch cell.CellType {
+		case "markdown":
+			parts = append(parts, src)
+		case "code":
+			fence := "```"
+			for strings.Contains(src, fence) {
+				fence += "`"
+			}
+			parts = append(parts, fence+language+"\n"+src+"\n"+fence)
+		}
+	}
+	return joinParts(parts), language, nil
+}
@@ -0,0 +1,63 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNotebookMarkdown(t *testing.T) {
+	data := []byte(`{
+		"cells": [
+			{"cell_type": "markdown", "source": ["# Churn analysis\n", "\n", "Monthly #retention numbers."]},
+			{"cell_type": "code", "source": "# load the data\ndf = load()", "outputs": [{"output_type": "stream", "text": ["SECRET OUTPUT"]}]},
+			{"cell_type": "code", "source": []},
+			{"cell_type": "raw", "source": "raw cell"}
+		],
+		"metadata": {"kernelspec": {"language": "python"}, "language_info": {"name": "python"}},
+		"nbformat": 4
+	}`)
+	text, language, err := notebookMarkdown(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if language != "python" {
+		t.Errorf("language = %q, want python", language)
+	}
+	want := "# Churn analysis\n\nMonthly #retention numbers.\n\n```python\n# load the data\ndf = load()\n```"
+	if text != want {
+		t.Errorf("notebookMarkdown() =\n%s\nwant\n%s", text, want)
+	}
+
+	if _, _, err := notebookMarkdown([]byte(`{"cells": "nope"}`)); err == nil {
+		t.Error("notebookMarkdown() accepted a malformed notebook")
+	}
+}
+
+func TestMarkdownSource_ParsesNotebook(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "analysis.ipynb")
+	data := `{"cells": [
+		{"cell_type": "markdown", "source": "# Churn analysis"},
+		{"cell_type": "code", "source": "# comment, not a heading\nprint(1)", "outputs": [{"text": "1"}]}
+	], "metadata": {"kernelspec": {"language": "python"}}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := NewMarkdownSource([]string{dir}, []string{".ipynb"}, nil).Parse(context.Background(), FileInfo{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Churn analysis" {
+		t.Errorf("title = %q, want the first markdown heading", doc.Title)
+	}
+	if doc.Metadata["headings"] != "Churn analysis" || doc.Metadata["language"] != "python" {
+		t.Errorf("metadata = %v", doc.Metadata)
+	}
+	if !strings.Contains(doc.Content, "print(1)") {
+		t.Errorf("content = %q, want the code cell", doc.Content)
+	}
+}
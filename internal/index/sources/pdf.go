@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -45,9 +46,14 @@ func (p *PDFSource) MatchesPath(path string) bool {
 	return p.scanner.MatchesPath(path)
 }
 
+// WatchPaths returns the configured PDF directories.
+func (p *PDFSource) WatchPaths() []string {
+	return p.scanner.Roots()
+}
+
 // Parse reads a PDF file and returns the parsed document.
 func (p *PDFSource) Parse(ctx context.Context, file FileInfo) (*storage.Document, error) {
-	content, err := extractPDFText(file.Path)
+	content, pages, err := extractPDFText(file.Path)
 	if err != nil {
 		return nil, fmt.Errorf("extracting PDF text: %w", err)
 	}
@@ -60,7 +66,7 @@ func (p *PDFSource) Parse(ctx context.Context, file FileInfo) (*storage.Document
 	title := strings.TrimSuffix(filepath.Base(file.Path), ".pdf")
 
 	// Generate preview.
-	preview := generatePreview(content, 500)
+	preview := pdfPreview(content, previewLen())
 
 	// Content hash for change detection.
 	contentHash := sha256.Sum256([]byte(content))
@@ -81,17 +87,19 @@ func (p *PDFSource) Parse(ctx context.Context, file FileInfo) (*storage.Document
 		Title:       title,
 		Content:     content,
 		Preview:     preview,
+		Metadata:    map[string]string{"pages": strconv.Itoa(pages)},
 		ContentHash: hex.EncodeToString(contentHash[:]),
 		IndexedAt:   time.Now(),
 		ModifiedAt:  modTime,
 	}, nil
 }
 
-// extractPDFText extracts plain text from a PDF file.
-func extractPDFText(path string) (string, error) {
+// extractPDFText extracts plain text from a PDF file, and returns it with
+// the number of pages.
+func extractPDFText(path string) (string, int, error) {
 	f, r, err := pdf.Open(path)
 	if err != nil {
-		return "", fmt.Errorf("opening PDF: %w", err)
+		return "", 0, fmt.Errorf("opening PDF: %w", err)
 	}
 	var sb strings.Builder
 	numPages := r.NumPage()
@@ -112,24 +120,8 @@ func extractPDFText(path string) (string, error) {
 		}
 	}
 	if err := f.Close(); err != nil {
-		return "", fmt.Errorf("closing PDF: %w", err)
+		return "", 0, fmt.Errorf("closing PDF: %w", err)
 	}
 
-	return strings.TrimSpace(sb.String()), nil
-}
-
-// generatePreview creates a truncated preview of the content.
-func generatePreview(content string, maxLen int) string {
-	// Collapse multiple whitespace.
-	content = strings.Join(strings.Fields(content), " ")
-	if len(content) <= maxLen {
-		return content
-	}
-
-	// Truncate at word boundary.
-	truncated := content[:maxLen]
-	if lastSpace := strings.LastIndex(truncated, " "); lastSpace > maxLen/2 {
-		truncated = truncated[:lastSpace]
-	}
-	return truncated + "..."
+	return strings.TrimSpace(sb.String()), numPages, nil
 }
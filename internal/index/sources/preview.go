@@ -0,0 +1,120 @@
+package sources
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/J-1000/mindcli/pkg/chunker"
+)
+
+// DefaultPreviewLength is the length, in characters, of the previews
+// sources generate unless SetPreviewLength changes it.
+const DefaultPreviewLength = 500
+
+// previewLength is the configured preview length; 0 means the default.
+var previewLength atomic.Int64
+
+// SetPreviewLength sets the length, in characters, of the previews all
+// sources generate. n <= 0 restores DefaultPreviewLength.
+func SetPreviewLength(n int) {
+	previewLength.Store(int64(n))
+}
+
+// previewLen returns the configured preview length.
+func previewLen() int {
+	if n := previewLength.Load(); n > 0 {
+		return int(n)
+	}
+	return DefaultPreviewLength
+}
+
+// generatePreview collapses the whitespace in content and truncates it to
+// maxLen characters at a sentence or word boundary.
+func generatePreview(content string, maxLen int) string {
+	return chunker.Truncate(strings.Join(strings.Fields(content), " "), maxLen)
+}
+
+// emailPreview leads with who a message is from and its subject, then the
+// first lines of its body, so a list of results reads like an inbox.
+func emailPreview(from, subject, body string, maxLen int) string {
+	var parts []string
+	if from = strings.TrimSpace(from); from != "" {
+		if addr, err := mail.ParseAddress(from); err == nil && addr.Name != "" {
+			from = addr.Name
+		}
+		parts = append(parts, "From: "+from)
+	}
+	if subject = strings.TrimSpace(subject); subject != "" {
+		parts = append(parts, "Subject: "+subject)
+	}
+	if body = strings.TrimSpace(body); body != "" {
+		parts = append(parts, body)
+	}
+	return generatePreview(strings.Join(parts, " · "), maxLen)
+}
+
+// maxPreviewURLs is how many pages a browser preview lists.
+const maxPreviewURLs = 5
+
+// browserPreview lists the most visited pages of a browser's history, then
+// its bookmarks, rather than whatever was visited last.
+func browserPreview(entries []historyEntry, maxLen int) string {
+	top := make([]historyEntry, len(entries))
+	copy(top, entries)
+	// Stable, so bookmarks and equally visited pages keep their recency order.
+	sort.SliceStable(top, func(i, j int) bool { return top[i].VisitCount > top[j].VisitCount })
+	var parts []string
+	seen := make(map[string]bool)
+	for _, e := range top {
+		if len(parts) == maxPreviewURLs {
+			break
+		}
+		if e.URL == "" || seen[e.URL] {
+			continue
+		}
+		seen[e.URL] = true
+		if e.VisitCount > 0 {
+			parts = append(parts, fmt.Sprintf("%s (%d visits)", e.URL, e.VisitCount))
+		} else {
+			parts = append(parts, e.URL)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return generatePreview("Top pages: "+strings.Join(parts, ", "), maxLen)
+}
+
+// pdfAbstractWindow is how far into a PDF's text pdfPreview looks for an
+// abstract: about the first page.
+const pdfAbstractWindow = 4000
+
+var (
+	// pdfAbstractRe matches an "Abstract" heading at the start of a line.
+	pdfAbstractRe = regexp.MustCompile(`(?im)^[ \t]*abstract\b[ \t.:—-]*`)
+	// pdfAbstractEndRe matches the heading that usually follows an abstract.
+	pdfAbstractEndRe = regexp.MustCompile(`(?im)^[ \t]*(?:(?:1|i)\.?[ \t]+)?(?:introduction|keywords|index terms)\b`)
+)
+
+// pdfPreview shows a paper's abstract when its first page has one, and the
+// start of the text otherwise.
+func pdfPreview(content string, maxLen int) string {
+	head := content
+	if len(head) > pdfAbstractWindow {
+		head = head[:pdfAbstractWindow]
+	}
+	if loc := pdfAbstractRe.FindStringIndex(head); loc != nil {
+		abstract := content[loc[1]:]
+		if end := pdfAbstractEndRe.FindStringIndex(abstract); end != nil {
+			abstract = abstract[:end[0]]
+		}
+		if strings.TrimSpace(abstract) != "" {
+			return generatePreview(abstract, maxLen)
+		}
+	}
+	return generatePreview(content, maxLen)
+}
@@ -0,0 +1,65 @@
+package sources
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetPreviewLength(t *testing.T) {
+	defer SetPreviewLength(0)
+
+	text := strings.Repeat("word ", 200)
+	if got := len(generatePreview(text, previewLen())); got > DefaultPreviewLength+3 {
+		t.Errorf("default preview is %d bytes, want at most %d", got, DefaultPreviewLength+3)
+	}
+	SetPreviewLength(40)
+	if got := generatePreview(text, previewLen()); len(got) > 43 || !strings.HasSuffix(got, "...") {
+		t.Errorf("preview = %q, want at most 40 characters and an ellipsis", got)
+	}
+	if got := createPreview("# Title\n\nFirst **bold** sentence here. Then a second, longer sentence.", previewLen()); got != "Title First bold sentence here. ..." {
+		t.Errorf("createPreview() = %q, want it cut after the first sentence", got)
+	}
+}
+
+func TestEmailPreview(t *testing.T) {
+	got := emailPreview(`"Alice Smith" <alice@example.com>`, "Budget review", "Hi Bob,\n\nThe numbers are in.", 200)
+	if want := "From: Alice Smith · Subject: Budget review · Hi Bob, The numbers are in."; got != want {
+		t.Errorf("emailPreview() = %q, want %q", got, want)
+	}
+	if got := emailPreview("a***@example.com", "", "Body", 200); got != "From: a***@example.com · Body" {
+		t.Errorf("emailPreview() without a name or subject = %q", got)
+	}
+}
+
+func TestBrowserPreview(t *testing.T) {
+	entries := []historyEntry{
+		{URL: "https://news.example.com/today", VisitCount: 2},
+		{URL: "https://docs.example.com/go", VisitCount: 40},
+		{URL: "https://bookmarked.example.com", Kind: "bookmark"},
+		{URL: "https://docs.example.com/go", VisitCount: 40},
+		{URL: "https://mail.example.com", VisitCount: 15},
+	}
+	got := browserPreview(entries, 500)
+	want := "Top pages: https://docs.example.com/go (40 visits), https://mail.example.com (15 visits), https://news.example.com/today (2 visits), https://bookmarked.example.com"
+	if got != want {
+		t.Errorf("browserPreview() = %q, want %q", got, want)
+	}
+	if got := browserPreview(nil, 500); got != "" {
+		t.Errorf("browserPreview(nil) = %q, want empty", got)
+	}
+}
+
+func TestPDFPreview(t *testing.T) {
+	paper := "Attention Is All You Need\nA. Author, B. Author\n\nAbstract\nThe dominant sequence transduction models are based on recurrent networks.\n\n1 Introduction\nRecurrent neural networks have been established..."
+	if got, want := pdfPreview(paper, 500), "The dominant sequence transduction models are based on recurrent networks."; got != want {
+		t.Errorf("pdfPreview() = %q, want %q", got, want)
+	}
+	ieee := "Title\nAbstract—We present a method.\nIndex Terms—retrieval\nBody text"
+	if got, want := pdfPreview(ieee, 500), "We present a method."; got != want {
+		t.Errorf("pdfPreview() = %q, want %q", got, want)
+	}
+	plain := "Invoice 42\n\nAmount due: 10 EUR"
+	if got := pdfPreview(plain, 500); got != "Invoice 42 Amount due: 10 EUR" {
+		t.Errorf("pdfPreview() without an abstract = %q", got)
+	}
+}
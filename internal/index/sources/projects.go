@@ -0,0 +1,186 @@
+package sources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// defaultProjectIncludes are the documentation files indexed in a project
+// when none are configured.
+var defaultProjectIncludes = []string{"README*", "docs/**/*.md", "CHANGELOG*"}
+
+// ProjectSource indexes the documentation of project directories, such as
+// code repositories: only files matching its include globs, relative to the
+// project, so the code stays out of the index. Each document gets the
+// project's directory name as "project" metadata.
+type ProjectSource struct {
+	scanner  *Scanner
+	includes []string
+}
+
+// NewProjectSource creates a new project source. Each path is a project
+// directory. includes are slash-separated globs relative to a project, where
+// ** matches any number of directories; they are matched case-insensitively.
+func NewProjectSource(paths, includes, ignore []string) *ProjectSource {
+	if len(includes) == 0 {
+		includes = defaultProjectIncludes
+	}
+	lowered := make([]string, 0, len(includes))
+	for _, inc := range includes {
+		lowered = append(lowered, strings.ToLower(filepath.ToSlash(inc)))
+	}
+	return &ProjectSource{
+		scanner: NewScanner(ScanConfig{
+			Paths:  paths,
+			Ignore: ignore,
+		}),
+		includes: lowered,
+	}
+}
+
+// Name returns the source name.
+func (p *ProjectSource) Name() storage.Source {
+	return storage.SourceProject
+}
+
+// Scan walks the configured projects and returns their documentation files.
+func (p *ProjectSource) Scan(ctx context.Context) (<-chan FileInfo, <-chan error) {
+	files, errs := p.scanner.Scan(ctx)
+	out := make(chan FileInfo, 100)
+	go func() {
+		defer close(out)
+		for f := range files {
+			if _, ok := p.projectOf(f.Path); !ok {
+				continue
+			}
+			select {
+			case out <- f:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, errs
+}
+
+// MatchesPath reports whether path is a documentation file of a configured
+// project.
+func (p *ProjectSource) MatchesPath(path string) bool {
+	if !p.scanner.MatchesPath(path) {
+		return false
+	}
+	_, ok := p.projectOf(path)
+	return ok
+}
+
+// WatchPaths returns the configured project directories.
+func (p *ProjectSource) WatchPaths() []string {
+	return p.scanner.Roots()
+}
+
+// projectOf returns the root of the project path is in. ok is false when
+// path is in none, or matches none of the include globs.
+func (p *ProjectSource) projectOf(file string) (root string, ok bool) {
+	file = normalizePath(file)
+	for _, r := range p.scanner.Roots() {
+		r = normalizePath(r)
+		if !pathWithin(file, r) || file == r {
+			continue
+		}
+		rel, err := filepath.Rel(r, file)
+		if err != nil {
+			continue
+		}
+		rel = strings.ToLower(filepath.ToSlash(rel))
+		for _, inc := range p.includes {
+			if matchGlob(inc, rel) {
+				return r, true
+			}
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// matchGlob reports whether the slash-separated name matches pattern, whose
+// ** segments match any number of path segments, none included.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// Parse reads a documentation file into a Document. Markdown files are
+// parsed for their title, headings and tags; anything else, such as a
+// README.txt or a CHANGELOG without an extension, is indexed as plain text.
+func (p *ProjectSource) Parse(ctx context.Context, file FileInfo) (*storage.Document, error) {
+	content, err := os.ReadFile(file.Path)
+	if err != nil {
+		return nil, err
+	}
+	root, _ := p.projectOf(file.Path)
+	project := filepath.Base(root)
+
+	text := string(content)
+	metadata := map[string]string{"project": project}
+	var title string
+	switch strings.ToLower(filepath.Ext(file.Path)) {
+	case ".md", ".markdown", ".mdx":
+		parsed := parseMarkdown(text)
+		text, title = parsed.Body, parsed.Title
+		if len(parsed.Headings) > 0 {
+			metadata["headings"] = strings.Join(parsed.Headings, ",")
+		}
+		if len(parsed.Tags) > 0 {
+			metadata["tags"] = strings.Join(parsed.Tags, ",")
+		}
+	}
+	if rel, err := filepath.Rel(root, file.Path); err == nil {
+		metadata["project_path"] = filepath.ToSlash(rel)
+		if title == "" {
+			title = project + ": " + filepath.ToSlash(rel)
+		}
+	}
+
+	pathHash := sha256.Sum256([]byte(file.Path))
+	contentHash := sha256.Sum256(content)
+	return &storage.Document{
+		ID:          hex.EncodeToString(pathHash[:16]),
+		Source:      storage.SourceProject,
+		Path:        file.Path,
+		Title:       title,
+		Content:     text,
+		Preview:     createPreview(text, previewLen()),
+		Metadata:    metadata,
+		ContentHash: hex.EncodeToString(contentHash[:]),
+		IndexedAt:   time.Now(),
+		ModifiedAt:  time.Unix(file.ModifiedAt, 0),
+	}, nil
+}
@@ -0,0 +1,86 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"readme*", "readme.md", true},
+		{"readme*", "pkg/readme.md", false},
+		{"docs/**/*.md", "docs/intro.md", true},
+		{"docs/**/*.md", "docs/guides/deploy/k8s.md", true},
+		{"docs/**/*.md", "docs/diagram.png", false},
+		{"docs/**/*.md", "src/docs/intro.md", false},
+		{"**/changelog*", "changelog", true},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProjectSource(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mindcli")
+	files := map[string]string{
+		"README.md":            "# MindCLI\n\nA local knowledge base.\n\n## Install\n",
+		"CHANGELOG":            "v1.2.0: projects source\n",
+		"docs/guides/setup.md": "# Setup\n\nRun make.\n",
+		"main.go":              "package main\n",
+		"pkg/README.md":        "# Package\n",
+		"vendor/docs/x.md":     "# Vendored\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src := NewProjectSource([]string{root}, nil, []string{"vendor"})
+	var found []string
+	scanned, _ := src.Scan(context.Background())
+	for f := range scanned {
+		rel, _ := filepath.Rel(root, f.Path)
+		found = append(found, filepath.ToSlash(rel))
+	}
+	slices.Sort(found)
+	if want := []string{"CHANGELOG", "README.md", "docs/guides/setup.md"}; !slices.Equal(found, want) {
+		t.Errorf("Scan() found %v, want only the docs %v", found, want)
+	}
+	if src.MatchesPath(filepath.Join(root, "main.go")) || !src.MatchesPath(filepath.Join(root, "docs", "guides", "setup.md")) {
+		t.Error("MatchesPath() should accept documentation only")
+	}
+
+	doc, err := src.Parse(context.Background(), FileInfo{Path: filepath.Join(root, "README.md")})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if doc.Source != storage.SourceProject || doc.Title != "MindCLI" {
+		t.Errorf("doc = source %q title %q", doc.Source, doc.Title)
+	}
+	if doc.Metadata["project"] != "mindcli" || doc.Metadata["project_path"] != "README.md" || doc.Metadata["headings"] != "MindCLI,Install" {
+		t.Errorf("metadata = %v", doc.Metadata)
+	}
+
+	doc, err = src.Parse(context.Background(), FileInfo{Path: filepath.Join(root, "CHANGELOG")})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if doc.Title != "mindcli: CHANGELOG" || doc.Content != files["CHANGELOG"] {
+		t.Errorf("plain text doc = title %q content %q", doc.Title, doc.Content)
+	}
+}
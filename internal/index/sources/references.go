@@ -0,0 +1,382 @@
+package sources
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// zoteroDBName is the file name of Zotero's database.
+const zoteroDBName = "zotero.sqlite"
+
+// reference is one entry of a reference library.
+type reference struct {
+	Key         string
+	Type        string
+	Title       string
+	Authors     []string
+	Year        string
+	Venue       string
+	DOI         string
+	URL         string
+	Abstract    string
+	Attachments []string // attached PDF files
+}
+
+// ReferenceSource indexes reference libraries: BibTeX files and Zotero's
+// zotero.sqlite database. A library is one document, and References splits
+// it into one document per reference carrying its authors, year and
+// attached PDF.
+type ReferenceSource struct {
+	scanner *Scanner
+}
+
+// NewReferenceSource creates a new reference source. Paths may be .bib files,
+// folders holding them, or Zotero data directories or databases.
+func NewReferenceSource(paths, ignore []string) *ReferenceSource {
+	return &ReferenceSource{
+		scanner: NewScanner(ScanConfig{
+			Paths:      paths,
+			Extensions: []string{".bib", ".sqlite"},
+			Ignore:     ignore,
+		}),
+	}
+}
+
+// Name returns the source name.
+func (r *ReferenceSource) Name() storage.Source {
+	return storage.SourceReference
+}
+
+// Scan walks configured paths and returns BibTeX files and Zotero databases.
+func (r *ReferenceSource) Scan(ctx context.Context) (<-chan FileInfo, <-chan error) {
+	files, errs := r.scanner.Scan(ctx)
+	out := make(chan FileInfo, 10)
+	go func() {
+		defer close(out)
+		for f := range files {
+			if !isReferenceLibrary(f.Path) {
+				continue
+			}
+			select {
+			case out <- f:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, errs
+}
+
+// MatchesPath reports whether this source is configured to handle the path.
+func (r *ReferenceSource) MatchesPath(path string) bool {
+	return isReferenceLibrary(path) && r.scanner.MatchesPath(path)
+}
+
+// WatchPaths returns the configured library paths.
+func (r *ReferenceSource) WatchPaths() []string {
+	return r.scanner.Roots()
+}
+
+// isReferenceLibrary reports whether path is a BibTeX file or a Zotero
+// database, rather than some other SQLite file.
+func isReferenceLibrary(path string) bool {
+	base := filepath.Base(path)
+	return strings.EqualFold(filepath.Ext(base), ".bib") || strings.EqualFold(base, zoteroDBName)
+}
+
+// Parse reads a BibTeX file or Zotero database into a Document listing its
+// references, one "## Title" block each.
+func (r *ReferenceSource) Parse(ctx context.Context, file FileInfo) (*storage.Document, error) {
+	var refs []reference
+	var warnings []string
+	title := filepath.Base(file.Path)
+	if strings.EqualFold(title, zoteroDBName) {
+		title = "Zotero library"
+		var err error
+		if refs, err = readZotero(ctx, file.Path); err != nil {
+			return nil, err
+		}
+	} else {
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, err
+		}
+		refs, warnings = parseBibTeX(string(data), filepath.Dir(file.Path))
+	}
+
+	blocks := make([]string, len(refs))
+	for i, ref := range refs {
+		blocks[i] = ref.block()
+	}
+	content := strings.Join(blocks, "\n\n")
+
+	hash := sha256.Sum256([]byte(content))
+	pathHash := sha256.Sum256([]byte(file.Path))
+	doc := &storage.Document{
+		ID:          hex.EncodeToString(pathHash[:16]),
+		Source:      storage.SourceReference,
+		Path:        file.Path,
+		Title:       title,
+		Content:     content,
+		Preview:     generatePreview(content, previewLen()),
+		Metadata:    map[string]string{"references": strconv.Itoa(len(refs))},
+		ContentHash: hex.EncodeToString(hash[:]),
+		IndexedAt:   time.Now(),
+		ModifiedAt:  time.Unix(file.ModifiedAt, 0),
+	}
+	return partial(doc, warnings)
+}
+
+// referenceFields are the "Field: value" lines of a reference block, in
+// order. References parses them back into metadata.
+var referenceFields = []struct {
+	label, key string
+	value      func(reference) string
+}{
+	{"Key", "citekey", func(r reference) string { return r.Key }},
+	{"Type", "type", func(r reference) string { return r.Type }},
+	{"Authors", "authors", func(r reference) string { return strings.Join(r.Authors, "; ") }},
+	{"Year", "date", func(r reference) string { return r.Year }},
+	{"Published in", "venue", func(r reference) string { return r.Venue }},
+	{"DOI", "doi", func(r reference) string { return r.DOI }},
+	{"URL", "url", func(r reference) string { return r.URL }},
+	{"PDF", "pdf", func(r reference) string { return strings.Join(r.Attachments, "; ") }},
+}
+
+// block renders the reference as a heading, its fields and its abstract.
+// Every value is kept to one line so the block can be split back apart.
+func (r reference) block() string {
+	var sb strings.Builder
+	sb.WriteString("## " + oneLine(r.Title))
+	for _, f := range referenceFields {
+		if v := oneLine(f.value(r)); v != "" {
+			sb.WriteString("\n" + f.label + ": " + v)
+		}
+	}
+	if abstract := oneLine(r.Abstract); abstract != "" {
+		sb.WriteString("\n\n" + abstract)
+	}
+	return sb.String()
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// References splits a reference library's document into one document per
+// reference, titled with the reference's title, whose path is the library's
+// path plus "#citekey". Its metadata holds the authors, year (as "date"),
+// DOI and the first attached PDF ("pdf").
+func References(doc *storage.Document) []*storage.Document {
+	if doc.Source != storage.SourceReference || doc.Content == "" {
+		return nil
+	}
+
+	var refs []*storage.Document
+	seen := make(map[string]int)
+	for _, block := range strings.Split("\n"+doc.Content, "\n## ")[1:] {
+		block = "## " + strings.TrimSpace(block)
+		lines := strings.Split(block, "\n")
+		title := strings.TrimPrefix(lines[0], "## ")
+		metadata := make(map[string]string)
+		for _, line := range lines[1:] {
+			label, value, ok := strings.Cut(line, ": ")
+			if !ok {
+				break
+			}
+			for _, f := range referenceFields {
+				if f.label == label {
+					metadata[f.key] = value
+				}
+			}
+		}
+		if pdf, _, _ := strings.Cut(metadata["pdf"], "; "); pdf != "" {
+			metadata["pdf"] = pdf
+		}
+
+		base := headingAnchor(firstNonBlank(metadata["citekey"], title))
+		anchor := base
+		if n := seen[base]; n > 0 {
+			anchor = fmt.Sprintf("%s-%d", base, n)
+		}
+		seen[base]++
+		metadata["section_of"] = doc.ID
+		metadata["anchor"] = anchor
+
+		var date time.Time
+		if year, err := strconv.Atoi(metadata["date"]); err == nil {
+			date = time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+		}
+		refs = append(refs, &storage.Document{
+			ID:           doc.ID + "#" + anchor,
+			Source:       doc.Source,
+			Path:         doc.Path + "#" + anchor,
+			Title:        title,
+			Content:      block,
+			Preview:      generatePreview(strings.Join(lines[1:], "\n"), previewLen()),
+			Metadata:     metadata,
+			ContentHash:  hashContent(block),
+			IndexedAt:    doc.IndexedAt,
+			ModifiedAt:   doc.ModifiedAt,
+			DocumentDate: date,
+		})
+	}
+	return refs
+}
+
+func firstNonBlank(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// zoteroSkippedTypes are Zotero items that aren't references themselves.
+const zoteroSkippedTypes = "'attachment', 'note', 'annotation'"
+
+// readZotero reads the references of a Zotero database, skipping items in
+// the trash. Zotero locks its database while running, so a copy is read.
+func readZotero(ctx context.Context, path string) ([]reference, error) {
+	tmp, err := copyToTemp(path)
+	if err != nil {
+		return nil, fmt.Errorf("copying Zotero database: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp) }()
+
+	db, err := sql.Open("sqlite3", tmp+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("opening Zotero database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var refs []reference
+	byID := make(map[int64]*reference)
+	rows, err := db.QueryContext(ctx, `
+		SELECT i.itemID, i.key, t.typeName FROM items i
+		JOIN itemTypes t ON t.itemTypeID = i.itemTypeID
+		WHERE t.typeName NOT IN (`+zoteroSkippedTypes+`)
+		AND i.itemID NOT IN (SELECT itemID FROM deletedItems)
+		ORDER BY i.itemID`)
+	if err != nil {
+		return nil, fmt.Errorf("reading Zotero items: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var ref reference
+		if err := rows.Scan(&id, &ref.Key, &ref.Type); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("reading Zotero items: %w", err)
+		}
+		ids = append(ids, id)
+		refs = append(refs, ref)
+	}
+	_ = rows.Close()
+	for i, id := range ids {
+		byID[id] = &refs[i]
+	}
+
+	if err := zoteroRows(ctx, db, `
+		SELECT d.itemID, f.fieldName, v.value FROM itemData d
+		JOIN fieldsCombined f ON f.fieldID = d.fieldID
+		JOIN itemDataValues v ON v.valueID = d.valueID`,
+		func(ref *reference, field, value string) {
+			switch field {
+			case "title":
+				ref.Title = value
+			case "abstractNote":
+				ref.Abstract = value
+			case "date":
+				// Zotero stores "2020-05-00 May 2020": the year comes first.
+				if len(value) >= 4 {
+					ref.Year = value[:4]
+				}
+			case "publicationTitle", "proceedingsTitle", "bookTitle", "publisher":
+				if ref.Venue == "" {
+					ref.Venue = value
+				}
+			case "DOI":
+				ref.DOI = value
+			case "url":
+				ref.URL = value
+			case "citationKey":
+				ref.Key = value
+			}
+		}, byID); err != nil {
+		return nil, err
+	}
+
+	if err := zoteroRows(ctx, db, `
+		SELECT ic.itemID, c.firstName, c.lastName FROM itemCreators ic
+		JOIN creators c ON c.creatorID = ic.creatorID
+		ORDER BY ic.itemID, ic.orderIndex`,
+		func(ref *reference, first, last string) {
+			ref.Authors = append(ref.Authors, strings.TrimSpace(first+" "+last))
+		}, byID); err != nil {
+		return nil, err
+	}
+
+	// Stored files live in storage/<attachment key>/; linked files are
+	// absolute paths. Files relative to a linked-attachment base directory
+	// can't be resolved from the database alone and are skipped.
+	storageDir := filepath.Join(filepath.Dir(path), "storage")
+	if err := zoteroRows(ctx, db, `
+		SELECT a.parentItemID, i.key, a.path FROM itemAttachments a
+		JOIN items i ON i.itemID = a.itemID
+		WHERE a.parentItemID IS NOT NULL AND a.contentType = 'application/pdf'
+		AND a.itemID NOT IN (SELECT itemID FROM deletedItems)`,
+		func(ref *reference, key, p string) {
+			switch {
+			case strings.HasPrefix(p, "storage:"):
+				ref.Attachments = append(ref.Attachments, filepath.Join(storageDir, key, strings.TrimPrefix(p, "storage:")))
+			case filepath.IsAbs(p):
+				ref.Attachments = append(ref.Attachments, p)
+			}
+		}, byID); err != nil {
+		return nil, err
+	}
+
+	kept := refs[:0]
+	for _, ref := range refs {
+		if strings.TrimSpace(ref.Title) != "" {
+			kept = append(kept, ref)
+		}
+	}
+	return kept, nil
+}
+
+// zoteroRows runs a query returning (itemID, a, b) rows and calls fn for the
+// rows of known references.
+func zoteroRows(ctx context.Context, db *sql.DB, query string, fn func(ref *reference, a, b string), refs map[int64]*reference) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("reading Zotero library: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var id int64
+		var a, b sql.NullString
+		if err := rows.Scan(&id, &a, &b); err != nil {
+			return fmt.Errorf("reading Zotero library: %w", err)
+		}
+		if ref := refs[id]; ref != nil {
+			fn(ref, a.String, b.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reading Zotero library: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestReferenceSourceBibTeX(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "library.bib")
+	bib := `@article{lovelace1843,
+  title = {Notes on the Analytical Engine},
+  author = {Lovelace, Ada},
+  year = {1843},
+  abstract = {Bernoulli numbers by machine.},
+  file = {paper.pdf},
+}
+@misc{lovelace1843, title = {Second printing}}
+`
+	if err := os.WriteFile(path, []byte(bib), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewReferenceSource([]string{dir}, nil)
+	if !src.MatchesPath(path) || src.MatchesPath(filepath.Join(dir, "other.sqlite")) {
+		t.Error("MatchesPath() should accept .bib files and zotero.sqlite only")
+	}
+	doc, err := src.Parse(context.Background(), FileInfo{Path: path})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if doc.Source != storage.SourceReference || doc.Metadata["references"] != "2" {
+		t.Errorf("library = source %q metadata %v", doc.Source, doc.Metadata)
+	}
+
+	refs := References(doc)
+	if len(refs) != 2 {
+		t.Fatalf("got %d references, want 2", len(refs))
+	}
+	ref := refs[0]
+	if ref.Title != "Notes on the Analytical Engine" || ref.Path != path+"#lovelace1843" || ref.FilePath() != path {
+		t.Errorf("reference = title %q path %q", ref.Title, ref.Path)
+	}
+	want := map[string]string{
+		"citekey":    "lovelace1843",
+		"type":       "article",
+		"authors":    "Ada Lovelace",
+		"date":       "1843",
+		"pdf":        filepath.Join(dir, "paper.pdf"),
+		"section_of": doc.ID,
+		"anchor":     "lovelace1843",
+	}
+	for k, v := range want {
+		if ref.Metadata[k] != v {
+			t.Errorf("metadata[%q] = %q, want %q", k, ref.Metadata[k], v)
+		}
+	}
+	if ref.DocumentDate.Year() != 1843 {
+		t.Errorf("DocumentDate = %v, want the publication year", ref.DocumentDate)
+	}
+	if refs[1].ID != doc.ID+"#lovelace1843-1" {
+		t.Errorf("duplicate key got ID %q, want a numbered anchor", refs[1].ID)
+	}
+
+	if got := References(&storage.Document{Source: storage.SourceMarkdown, Content: "## Heading"}); got != nil {
+		t.Errorf("markdown document got %d references, want none", len(got))
+	}
+}
+
+func TestReadZotero(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, zoteroDBName)
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts := []string{
+		`CREATE TABLE itemTypes (itemTypeID INTEGER PRIMARY KEY, typeName TEXT)`,
+		`CREATE TABLE items (itemID INTEGER PRIMARY KEY, itemTypeID INT, key TEXT)`,
+		`CREATE TABLE deletedItems (itemID INTEGER PRIMARY KEY)`,
+		`CREATE TABLE fieldsCombined (fieldID INTEGER PRIMARY KEY, fieldName TEXT)`,
+		`CREATE TABLE itemDataValues (valueID INTEGER PRIMARY KEY, value)`,
+		`CREATE TABLE itemData (itemID INT, fieldID INT, valueID INT)`,
+		`CREATE TABLE creators (creatorID INTEGER PRIMARY KEY, firstName TEXT, lastName TEXT)`,
+		`CREATE TABLE itemCreators (itemID INT, creatorID INT, creatorTypeID INT, orderIndex INT)`,
+		`CREATE TABLE itemAttachments (itemID INTEGER PRIMARY KEY, parentItemID INT, contentType TEXT, path TEXT)`,
+		`INSERT INTO itemTypes VALUES (1, 'journalArticle'), (2, 'attachment'), (3, 'note')`,
+		`INSERT INTO items VALUES (1, 1, 'ABCD1234'), (2, 2, 'PDFKEY01'), (3, 1, 'TRASHED1'), (4, 3, 'NOTE0001')`,
+		`INSERT INTO deletedItems VALUES (3)`,
+		`INSERT INTO fieldsCombined VALUES (1, 'title'), (2, 'date'), (3, 'abstractNote'), (4, 'publicationTitle'), (5, 'DOI')`,
+		`INSERT INTO itemDataValues VALUES (1, 'On Computable Numbers'), (2, '1936-11-12 1936'), (3, 'Machines that compute.'), (4, 'Proc. LMS'), (5, 'Deleted paper'), (6, '10.1112/plms/s2-42.1.230')`,
+		`INSERT INTO itemData VALUES (1, 1, 1), (1, 2, 2), (1, 3, 3), (1, 4, 4), (1, 5, 6), (3, 1, 5)`,
+		`INSERT INTO creators VALUES (1, 'Alan', 'Turing'), (2, 'Alonzo', 'Church')`,
+		`INSERT INTO itemCreators VALUES (1, 2, 1, 1), (1, 1, 1, 0)`,
+		`INSERT INTO itemAttachments VALUES (2, 1, 'application/pdf', 'storage:turing.pdf')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := readZotero(context.Background(), path)
+	if err != nil {
+		t.Fatalf("readZotero() error = %v", err)
+	}
+	if len(refs) != 1 {
+		t.Fatalf("got %d references, want 1 (trash, notes and attachments skipped)", len(refs))
+	}
+	ref := refs[0]
+	if ref.Key != "ABCD1234" || ref.Type != "journalArticle" || ref.Title != "On Computable Numbers" ||
+		ref.Year != "1936" || ref.Venue != "Proc. LMS" || ref.DOI != "10.1112/plms/s2-42.1.230" || ref.Abstract != "Machines that compute." {
+		t.Errorf("reference = %+v", ref)
+	}
+	if !slices.Equal(ref.Authors, []string{"Alan Turing", "Alonzo Church"}) {
+		t.Errorf("Authors = %v, want creators in order", ref.Authors)
+	}
+	if want := []string{filepath.Join(dir, "storage", "PDFKEY01", "turing.pdf")}; !slices.Equal(ref.Attachments, want) {
+		t.Errorf("Attachments = %v, want %v", ref.Attachments, want)
+	}
+}
@@ -0,0 +1,52 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	lockRetryAttempts = 4
+	lockRetryDelay    = 250 * time.Millisecond
+)
+
+// retryTransient calls fn until it succeeds, fails with a non-transient
+// error, or attempts run out, doubling delay between attempts. It returns
+// fn's last error.
+func retryTransient(ctx context.Context, attempts int, delay time.Duration, fn func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil || !isTransient(err) {
+			return err
+		}
+		if i == attempts-1 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return err
+}
+
+// isTransient reports whether err is likely caused by another process
+// holding or writing the database at the same moment, in which case reading
+// it again shortly afterwards may succeed.
+func isTransient(err error) bool {
+	var sqlErr sqlite3.Error
+	if !errors.As(err, &sqlErr) {
+		return false
+	}
+	switch sqlErr.Code {
+	case sqlite3.ErrBusy, sqlite3.ErrLocked, sqlite3.ErrCorrupt, sqlite3.ErrNotADB:
+		// A copy taken mid-write can look corrupt; a fresh copy usually isn't.
+		return true
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{fmt.Errorf("querying chrome history: %w", sqlite3.Error{Code: sqlite3.ErrLocked}), true},
+		{sqlite3.Error{Code: sqlite3.ErrNotADB}, true},
+		{sqlite3.Error{Code: sqlite3.ErrPerm}, false},
+		{errors.New("database is locked"), false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryTransient(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	err := retryTransient(ctx, 4, 0, func() error {
+		calls++
+		if calls < 3 {
+			return sqlite3.Error{Code: sqlite3.ErrBusy}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("transient failures: err = %v, calls = %d, want nil after 3", err, calls)
+	}
+
+	calls = 0
+	permanent := errors.New("no such table")
+	err = retryTransient(ctx, 4, 0, func() error {
+		calls++
+		return permanent
+	})
+	if !errors.Is(err, permanent) || calls != 1 {
+		t.Errorf("permanent failure: err = %v, calls = %d, want no retry", err, calls)
+	}
+
+	calls = 0
+	err = retryTransient(ctx, 3, 0, func() error {
+		calls++
+		return sqlite3.Error{Code: sqlite3.ErrLocked}
+	})
+	if !isTransient(err) || calls != 3 {
+		t.Errorf("exhausted retries: err = %v, calls = %d, want last error after 3", err, calls)
+	}
+}
@@ -38,6 +38,15 @@ func NewScanner(config ScanConfig) *Scanner {
 	}
 }
 
+// Roots returns the configured paths with the home directory expanded.
+func (s *Scanner) Roots() []string {
+	roots := make([]string, 0, len(s.config.Paths))
+	for _, p := range s.config.Paths {
+		roots = append(roots, expandPath(p))
+	}
+	return roots
+}
+
 // Scan walks all configured paths and sends file info to the returned channel.
 func (s *Scanner) Scan(ctx context.Context) (<-chan FileInfo, <-chan error) {
 	files := make(chan FileInfo, 100)
@@ -157,15 +166,67 @@ func (s *Scanner) MatchesPath(path string) bool {
 		return false
 	}
 
+	return s.Contains(filePath)
+}
+
+// Contains reports whether path is within one of the scanner's paths,
+// whatever its extension and ignore lists.
+func (s *Scanner) Contains(path string) bool {
+	filePath := normalizePath(path)
 	for _, p := range s.config.Paths {
 		if pathWithin(filePath, normalizePath(expandPath(p))) {
 			return true
 		}
 	}
-
 	return false
 }
 
+// scanAll runs the scanners one after another, sending the files for which
+// keep returns true to one channel and every scan error once the last file
+// is sent. keep is given the index of the scanner that found the file.
+func scanAll(ctx context.Context, scanners []*Scanner, keep func(i int, f FileInfo) bool) (<-chan FileInfo, <-chan error) {
+	out := make(chan FileInfo, 100)
+	outErrs := make(chan error)
+	go func() {
+		defer close(outErrs)
+		var scanErrs []error
+		for i, s := range scanners {
+			files, errs := s.Scan(ctx)
+			for files != nil || errs != nil {
+				select {
+				case f, ok := <-files:
+					if !ok {
+						files = nil
+						continue
+					}
+					if !keep(i, f) {
+						continue
+					}
+					select {
+					case out <- f:
+					case <-ctx.Done():
+					}
+				case err, ok := <-errs:
+					if !ok {
+						errs = nil
+						continue
+					}
+					scanErrs = append(scanErrs, err)
+				}
+			}
+		}
+		close(out)
+		for _, err := range scanErrs {
+			select {
+			case outErrs <- err:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, outErrs
+}
+
 func (s *Scanner) matchesExtension(path string) bool {
 	if len(s.extMap) == 0 {
 		return true // No filter means all files
@@ -176,3 +176,21 @@ func TestExpandPath(t *testing.T) {
 		}
 	}
 }
+
+func TestScanner_Roots(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	s := NewScanner(ScanConfig{Paths: []string{"~/notes", "/abs/path"}})
+	roots := s.Roots()
+	want := []string{filepath.Join(home, "notes"), "/abs/path"}
+	if len(roots) != len(want) {
+		t.Fatalf("Roots() = %v, want %v", roots, want)
+	}
+	for i := range want {
+		if roots[i] != want[i] {
+			t.Errorf("Roots()[%d] = %q, want %q", i, roots[i], want[i])
+		}
+	}
+}
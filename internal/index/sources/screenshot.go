@@ -0,0 +1,177 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// ScreenshotSource indexes screenshots by the text an OCR command finds in
+// them. Screenshots older than the retention period are not indexed, and
+// Expired tells the indexer which indexed ones to drop.
+type ScreenshotSource struct {
+	scanner    *Scanner
+	ocrCommand []string
+	retention  time.Duration
+	now        func() time.Time
+}
+
+// NewScreenshotSource creates a new screenshot source. ocrCommand is a
+// command line whose {path} argument is replaced by the image path, or which
+// gets the path appended when it has none; it must print the text to stdout.
+// retentionDays <= 0 keeps screenshots indexed forever.
+func NewScreenshotSource(paths, extensions, ignore []string, ocrCommand string, retentionDays int) *ScreenshotSource {
+	if len(extensions) == 0 {
+		extensions = []string{".png", ".jpg", ".jpeg"}
+	}
+	s := &ScreenshotSource{
+		scanner: NewScanner(ScanConfig{
+			Paths:      paths,
+			Extensions: extensions,
+			Ignore:     ignore,
+		}),
+		ocrCommand: strings.Fields(ocrCommand),
+		now:        time.Now,
+	}
+	if retentionDays > 0 {
+		s.retention = time.Duration(retentionDays) * 24 * time.Hour
+	}
+	return s
+}
+
+// Name returns the source name.
+func (s *ScreenshotSource) Name() storage.Source {
+	return storage.SourceScreenshot
+}
+
+// Scan walks configured paths and returns screenshots taken within the
+// retention period.
+func (s *ScreenshotSource) Scan(ctx context.Context) (<-chan FileInfo, <-chan error) {
+	files, errs := s.scanner.Scan(ctx)
+	out := make(chan FileInfo, 10)
+	go func() {
+		defer close(out)
+		for f := range files {
+			if s.expired(time.Unix(f.ModifiedAt, 0)) {
+				continue
+			}
+			select {
+			case out <- f:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, errs
+}
+
+// MatchesPath reports whether this source is configured to handle the path.
+func (s *ScreenshotSource) MatchesPath(path string) bool {
+	return s.scanner.MatchesPath(path)
+}
+
+// WatchPaths returns the configured screenshot folders.
+func (s *ScreenshotSource) WatchPaths() []string {
+	return s.scanner.Roots()
+}
+
+// Expired reports whether doc was taken before the retention period.
+func (s *ScreenshotSource) Expired(doc *storage.Document) bool {
+	return doc.Source == storage.SourceScreenshot && s.expired(doc.ModifiedAt)
+}
+
+func (s *ScreenshotSource) expired(taken time.Time) bool {
+	return s.retention > 0 && taken.Before(s.now().Add(-s.retention))
+}
+
+// Parse runs OCR on a screenshot and returns its text as a document. A
+// screenshot without text is still indexed, by its file name.
+func (s *ScreenshotSource) Parse(ctx context.Context, file FileInfo) (*storage.Document, error) {
+	text, err := s.ocr(ctx, file.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(file.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	pathHash := sha256.Sum256([]byte(file.Path))
+	contentHash := sha256.Sum256([]byte(text))
+	return &storage.Document{
+		ID:           hex.EncodeToString(pathHash[:16]),
+		Source:       storage.SourceScreenshot,
+		Path:         file.Path,
+		Title:        strings.TrimSuffix(filepath.Base(file.Path), filepath.Ext(file.Path)),
+		Content:      text,
+		Preview:      generatePreview(text, previewLen()),
+		ContentHash:  hex.EncodeToString(contentHash[:]),
+		Metadata:     map[string]string{"image": file.Path},
+		IndexedAt:    time.Now(),
+		ModifiedAt:   info.ModTime(),
+		DocumentDate: info.ModTime(),
+	}, nil
+}
+
+// ocr runs the OCR command on the image at path and returns the recognized
+// text with blank lines and trailing spaces tidied up.
+func (s *ScreenshotSource) ocr(ctx context.Context, path string) (string, error) {
+	if len(s.ocrCommand) == 0 {
+		return "", fmt.Errorf("no OCR command configured")
+	}
+	args := make([]string, 0, len(s.ocrCommand))
+	substituted := false
+	for _, arg := range s.ocrCommand[1:] {
+		if strings.Contains(arg, "{path}") {
+			arg = strings.ReplaceAll(arg, "{path}", path)
+			substituted = true
+		}
+		args = append(args, arg)
+	}
+	if !substituted {
+		args = append(args, path)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.ocrCommand[0], args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("running OCR: %w: %s", err, firstLine(msg))
+		}
+		return "", fmt.Errorf("running OCR: %w", err)
+	}
+	return cleanOCRText(stdout.String()), nil
+}
+
+// cleanOCRText trims each line and collapses runs of blank lines, which
+// OCR tools emit between text blocks, into one.
+func cleanOCRText(text string) string {
+	text = strings.ReplaceAll(text, "\f", "\n")
+	var out []string
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
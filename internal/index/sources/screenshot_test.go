@@ -0,0 +1,82 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestScreenshotSourceParse(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Screenshot 2024-05-01.png")
+	if err := os.WriteFile(path, []byte("  Build failed  \n\n\n\fexit status 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// cat stands in for the OCR tool: the path is appended when the command
+	// has no {path} placeholder.
+	src := NewScreenshotSource([]string{dir}, nil, nil, "cat", 30)
+	if !src.MatchesPath(path) || src.MatchesPath(filepath.Join(dir, "notes.txt")) {
+		t.Error("MatchesPath() should accept images only")
+	}
+	doc, err := src.Parse(context.Background(), FileInfo{Path: path})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if doc.Source != storage.SourceScreenshot || doc.Title != "Screenshot 2024-05-01" {
+		t.Errorf("doc = source %q title %q", doc.Source, doc.Title)
+	}
+	if doc.Content != "Build failed\n\nexit status 1" {
+		t.Errorf("Content = %q, want the tidied OCR text", doc.Content)
+	}
+	if doc.Metadata["image"] != path || doc.DocumentDate.IsZero() {
+		t.Errorf("metadata = %v date %v, want the image path and capture time", doc.Metadata, doc.DocumentDate)
+	}
+
+	src = NewScreenshotSource([]string{dir}, nil, nil, "sh -c false {path}", 30)
+	if _, err := src.Parse(context.Background(), FileInfo{Path: path}); err == nil {
+		t.Error("Parse() should fail when the OCR command fails")
+	}
+}
+
+func TestScreenshotSourceRetention(t *testing.T) {
+	dir := t.TempDir()
+	recent := filepath.Join(dir, "recent.png")
+	old := filepath.Join(dir, "old.jpg")
+	for _, p := range []string{recent, old} {
+		if err := os.WriteFile(p, []byte("text"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	longAgo := time.Now().AddDate(0, 0, -10)
+	if err := os.Chtimes(old, longAgo, longAgo); err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewScreenshotSource([]string{dir}, nil, nil, "cat", 7)
+	files, errs := src.Scan(context.Background())
+	var got []string
+	for f := range files {
+		got = append(got, f.Path)
+	}
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != recent {
+		t.Errorf("Scan() = %v, want only the screenshot within the retention period", got)
+	}
+
+	if !src.Expired(&storage.Document{Source: storage.SourceScreenshot, ModifiedAt: longAgo}) {
+		t.Error("Expired() = false for a screenshot past retention")
+	}
+	if src.Expired(&storage.Document{Source: storage.SourceScreenshot, ModifiedAt: time.Now()}) {
+		t.Error("Expired() = true for a recent screenshot")
+	}
+	if NewScreenshotSource([]string{dir}, nil, nil, "cat", 0).Expired(&storage.Document{Source: storage.SourceScreenshot, ModifiedAt: longAgo}) {
+		t.Error("Expired() = true with no retention period")
+	}
+}
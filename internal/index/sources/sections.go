@@ -0,0 +1,111 @@
+package sources
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// maxSectionLevel is the deepest heading that starts a section; deeper
+// headings stay inside the section above them.
+const maxSectionLevel = 3
+
+// Sections splits a long markdown note into one document per heading, so a
+// search can land on the part of the note that matters. Each section is
+// titled "Note Title » Heading" and its path is the note's path plus a
+// "#anchor". Notes shorter than minChars, or with fewer than two sections,
+// return nil; a minChars of 0 disables sections.
+func Sections(doc *storage.Document, minChars int) []*storage.Document {
+	if doc.Source != storage.SourceMarkdown || minChars <= 0 || len(doc.Content) < minChars {
+		return nil
+	}
+
+	// Find headings in the prose only, so "# comments" in code don't split.
+	lines := strings.SplitAfter(doc.Content, "\n")
+	prose := strings.SplitAfter(withoutFencedCode(doc.Content), "\n")
+	type heading struct {
+		line int
+		text string
+	}
+	var starts []heading
+	for i := 0; i < len(lines) && i < len(prose); i++ {
+		m := headingRegex.FindStringSubmatch(strings.TrimRight(prose[i], "\r\n"))
+		if m == nil || len(m[1]) > maxSectionLevel {
+			continue
+		}
+		// The title heading starts the note, not a section of it.
+		if text := cleanHeading(m[2]); !strings.EqualFold(text, doc.Title) {
+			starts = append(starts, heading{line: i, text: text})
+		}
+	}
+
+	seen := make(map[string]int)
+	var sections []*storage.Document
+	for i, h := range starts {
+		end := len(lines)
+		if i+1 < len(starts) {
+			end = starts[i+1].line
+		}
+		body := strings.TrimSpace(strings.Join(lines[h.line+1:end], ""))
+		if body == "" {
+			continue
+		}
+		content := strings.TrimSpace(strings.Join(lines[h.line:end], ""))
+
+		// Repeated headings get numbered anchors, as on GitHub.
+		base := headingAnchor(h.text)
+		anchor := base
+		if n := seen[base]; n > 0 {
+			anchor = fmt.Sprintf("%s-%d", base, n)
+		}
+		seen[base]++
+
+		metadata := map[string]string{
+			"section_of": doc.ID,
+			"section":    h.text,
+			"anchor":     anchor,
+		}
+		for _, key := range []string{"tags", "vault"} {
+			if v := doc.Metadata[key]; v != "" {
+				metadata[key] = v
+			}
+		}
+		sections = append(sections, &storage.Document{
+			ID:           doc.ID + "#" + anchor,
+			Source:       doc.Source,
+			Path:         doc.Path + "#" + anchor,
+			Title:        doc.Title + " » " + h.text,
+			Content:      content,
+			Preview:      createPreview(body, previewLen()),
+			Metadata:     metadata,
+			ContentHash:  hashContent(content),
+			IndexedAt:    doc.IndexedAt,
+			ModifiedAt:   doc.ModifiedAt,
+			DocumentDate: doc.DocumentDate,
+		})
+	}
+	if len(sections) < 2 {
+		return nil
+	}
+	return sections
+}
+
+// headingAnchor turns a heading into a link anchor: lowercase, spaces as
+// dashes, punctuation dropped.
+func headingAnchor(heading string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			sb.WriteRune(r)
+		case unicode.IsSpace(r):
+			sb.WriteByte('-')
+		}
+	}
+	if sb.Len() == 0 {
+		return "section"
+	}
+	return sb.String()
+}
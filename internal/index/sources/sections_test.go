@@ -0,0 +1,75 @@
+package sources
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestSections(t *testing.T) {
+	doc := &storage.Document{
+		ID:       "note",
+		Source:   storage.SourceMarkdown,
+		Path:     "/notes/plan.md",
+		Title:    "Plan",
+		Metadata: map[string]string{"tags": "work", "vault": "work", "fm_aliases": "roadmap"},
+		Content: "# Plan\n\nIntro.\n\n## Goals #q3\n\nShip it.\n\n```sh\n# not a heading\n```\n\n" +
+			"### Notes\n\nFirst.\n\n### Notes\n\nSecond.\n\n#### Detail\n\nStays in Notes.\n\n## Empty\n",
+	}
+
+	sections := Sections(doc, 10)
+	var titles, paths []string
+	for _, s := range sections {
+		titles = append(titles, s.Title)
+		paths = append(paths, s.Path)
+	}
+	wantTitles := []string{"Plan » Goals", "Plan » Notes", "Plan » Notes"}
+	wantPaths := []string{"/notes/plan.md#goals", "/notes/plan.md#notes", "/notes/plan.md#notes-1"}
+	if !slicesEqual(titles, wantTitles) || !slicesEqual(paths, wantPaths) {
+		t.Fatalf("sections = %v %v, want %v %v", titles, paths, wantTitles, wantPaths)
+	}
+
+	goals := sections[0]
+	if !strings.Contains(goals.Content, "# not a heading") || !strings.HasPrefix(goals.Content, "## Goals") {
+		t.Errorf("Goals content = %q, want the heading and its code block", goals.Content)
+	}
+	if !strings.Contains(sections[2].Content, "Stays in Notes.") {
+		t.Errorf("second Notes content = %q, want its deeper heading kept", sections[2].Content)
+	}
+	if goals.ID != "note#goals" || goals.Metadata["section_of"] != "note" || goals.Metadata["tags"] != "work" ||
+		goals.Metadata["vault"] != "work" {
+		t.Errorf("Goals = id %q metadata %v", goals.ID, goals.Metadata)
+	}
+	if _, ok := goals.Metadata["fm_aliases"]; ok {
+		t.Error("sections must not copy aliases, or wiki links could resolve to them")
+	}
+	if !goals.IsSection() || goals.FilePath() != doc.Path {
+		t.Errorf("IsSection() = %v, FilePath() = %q", goals.IsSection(), goals.FilePath())
+	}
+
+	if got := Sections(doc, len(doc.Content)+1); got != nil {
+		t.Errorf("short note got %d sections, want none", len(got))
+	}
+	if got := Sections(doc, 0); got != nil {
+		t.Errorf("minChars 0 got %d sections, want none", len(got))
+	}
+	single := &storage.Document{Source: storage.SourceMarkdown, Title: "One", Content: "## Only\n\nJust one section here."}
+	if got := Sections(single, 1); got != nil {
+		t.Errorf("single heading got %d sections, want none", len(got))
+	}
+}
+
+func TestHeadingAnchor(t *testing.T) {
+	tests := map[string]string{
+		"Goals":             "goals",
+		"Next Steps (Q3)!":  "next-steps-q3",
+		"Über_cool-ideas 2": "über_cool-ideas-2",
+		"???":               "section",
+	}
+	for heading, want := range tests {
+		if got := headingAnchor(heading); got != want {
+			t.Errorf("headingAnchor(%q) = %q, want %q", heading, got, want)
+		}
+	}
+}
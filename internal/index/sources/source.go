@@ -3,6 +3,8 @@ package sources
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/J-1000/mindcli/internal/storage"
 )
@@ -22,9 +24,85 @@ type Source interface {
 	Parse(ctx context.Context, file FileInfo) (*storage.Document, error)
 }
 
+// Watchable is implemented by sources backed by files that can be watched
+// for changes. WatchPaths returns the directories or files to watch.
+type Watchable interface {
+	WatchPaths() []string
+}
+
+// Poller is implemented by sources that have no files to watch and must be
+// re-scanned periodically instead (e.g. the system clipboard).
+type Poller interface {
+	PollInterval() time.Duration
+}
+
+// Expiring is implemented by sources whose documents are only kept for a
+// retention period (e.g. screenshots and clipboard clips). Expired reports whether an indexed
+// document has outlived it and should be removed.
+type Expiring interface {
+	Expired(doc *storage.Document) bool
+}
+
+// Revisable is implemented by sources whose documents can go stale without
+// the modification time of their files advancing (e.g. notes whose git
+// history changed). Outdated reports whether doc, indexed earlier from path,
+// must be parsed again.
+type Revisable interface {
+	Outdated(ctx context.Context, path string, doc *storage.Document) bool
+}
+
+// TrimReport describes what was trimmed from a source to fit its budget, or
+// removed from it for being past its retention period.
+type TrimReport struct {
+	Source  storage.Source
+	Path    string    // the file entries were dropped from; "" for whole documents
+	Removed int       // documents removed, or entries dropped from Path
+	Bytes   int64     // bytes of text removed
+	Newest  time.Time // date of the newest document or entry removed
+}
+
+// Trimming is implemented by sources that drop the least valuable entries
+// of a file to keep its document within a budget (e.g. browser history).
+// TakeTrimmed returns what was dropped since it was last called.
+type Trimming interface {
+	TakeTrimmed() []TrimReport
+}
+
 // FileInfo contains information about a file to be indexed.
 type FileInfo struct {
 	Path       string
 	ModifiedAt int64 // Unix timestamp
 	Size       int64
 }
+
+// PartialError is returned by Parse together with a non-nil document when a
+// file could only be read in part. The document holds whatever parsed and
+// should still be indexed; Warnings describes what was skipped.
+type PartialError struct {
+	Warnings []string
+}
+
+func (e *PartialError) Error() string {
+	return "partially parsed: " + strings.Join(e.Warnings, "; ")
+}
+
+// partial returns doc along with a *PartialError when warnings is non-empty.
+// The warnings are also kept in the document's metadata so they show up
+// alongside it.
+func partial(doc *storage.Document, warnings []string) (*storage.Document, error) {
+	if len(warnings) == 0 {
+		return doc, nil
+	}
+	if doc.Metadata == nil {
+		doc.Metadata = make(map[string]string)
+	}
+	doc.Metadata["parse_warnings"] = strings.Join(warnings, "; ")
+	return doc, &PartialError{Warnings: warnings}
+}
+
+// Aging is implemented by sources that leave the entries of a file past
+// their retention period out of its document (e.g. browser history).
+// TakeExpired returns what was left out since it was last called.
+type Aging interface {
+	TakeExpired() []TrimReport
+}
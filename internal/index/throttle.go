@@ -0,0 +1,182 @@
+package index
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+)
+
+// idleLoadFactor is the per-CPU load average below which the machine is
+// considered idle.
+const idleLoadFactor = 0.5
+
+// idlePollInterval is how often a paused indexer re-checks for idleness.
+const idlePollInterval = 10 * time.Second
+
+// loadWindow is the time constant of the one-minute load average, which
+// ownLoad is damped with to match.
+const loadWindow = time.Minute
+
+// throttle paces indexing work according to the configured limits.
+type throttle struct {
+	mu         sync.Mutex
+	interval   time.Duration // minimum spacing between files; 0 = unlimited
+	next       time.Time     // earliest start of the next file
+	embedPause time.Duration // pause after each embedding batch
+
+	idle     func() bool // when set, work is paused until it reports true
+	idlePoll time.Duration
+}
+
+func newThrottle(cfg config.ThrottleConfig) *throttle {
+	t := &throttle{idlePoll: idlePollInterval}
+	t.configure(cfg)
+	return t
+}
+
+// configure applies cfg's limits, taking effect from the next file.
+func (t *throttle) configure(cfg config.ThrottleConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.embedPause = time.Duration(cfg.EmbedPauseMs) * time.Millisecond
+	t.interval = 0
+	if cfg.MaxFilesPerSecond > 0 {
+		t.interval = time.Duration(float64(time.Second) / cfg.MaxFilesPerSecond)
+	}
+}
+
+// wait blocks until the next file may be processed. A nil throttle never
+// waits.
+func (t *throttle) wait(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	if err := t.waitIdle(ctx); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	if t.interval <= 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	t.mu.Unlock()
+
+	return sleepContext(ctx, delay)
+}
+
+// afterEmbed pauses after an embedding batch to give the embedding service
+// some room.
+func (t *throttle) afterEmbed(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	pause := t.embedPause
+	t.mu.Unlock()
+	return sleepContext(ctx, pause)
+}
+
+// waitIdle blocks while the idle check reports the machine as busy.
+func (t *throttle) waitIdle(ctx context.Context) error {
+	if t.idle == nil {
+		return nil
+	}
+	for !t.idle() {
+		if err := sleepContext(ctx, t.idlePoll); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sleepContext sleeps for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// systemLoad watches the load of this process for SystemIdle.
+var systemLoad = &loadMonitor{
+	loadAverage: loadAverage,
+	cpuTime:     processCPUTime,
+	cpus:        runtime.NumCPU(),
+}
+
+// SystemIdle reports whether the machine is lightly loaded by anything but
+// this process, based on the one-minute load average less the CPUs that
+// mindcli itself kept busy, so that indexing doesn't pause for its own load.
+// It always reports true where the load average is unavailable; see
+// IdleDetectionSupported.
+func SystemIdle() bool {
+	return systemLoad.idle(time.Now())
+}
+
+// loadMonitor tells the load of other processes from the load average by
+// discounting the CPU time of this one.
+type loadMonitor struct {
+	loadAverage func() (float64, bool)
+	cpuTime     func() (time.Duration, bool) // CPU time used by this process so far
+	cpus        int
+
+	mu      sync.Mutex
+	lastCPU time.Duration
+	lastAt  time.Time
+	ownLoad float64 // CPUs kept busy by this process, averaged like the load
+}
+
+// idle reports whether the load of other processes at now is below
+// idleLoadFactor per CPU.
+func (m *loadMonitor) idle(now time.Time) bool {
+	load, ok := m.loadAverage()
+	if !ok {
+		return true
+	}
+	return load-m.own(now) < float64(m.cpus)*idleLoadFactor
+}
+
+// own updates and returns the load this process accounts for: the CPUs it
+// kept busy since the last call, folded into an exponentially damped average
+// with the load average's time constant.
+func (m *loadMonitor) own(now time.Time) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cpu, ok := m.cpuTime()
+	if !ok {
+		return 0
+	}
+	if !m.lastAt.IsZero() {
+		if elapsed := now.Sub(m.lastAt); elapsed > 0 {
+			busy := float64(cpu-m.lastCPU) / float64(elapsed)
+			decay := math.Exp(-float64(elapsed) / float64(loadWindow))
+			m.ownLoad = m.ownLoad*decay + busy*(1-decay)
+		}
+	}
+	m.lastCPU, m.lastAt = cpu, now
+	return m.ownLoad
+}
+
+// IdleDetectionSupported reports whether SystemIdle can observe system load
+// on this platform.
+func IdleDetectionSupported() bool {
+	_, ok := loadAverage()
+	return ok
+}
@@ -0,0 +1,127 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+)
+
+func TestNewThrottle(t *testing.T) {
+	th := newThrottle(config.ThrottleConfig{MaxFilesPerSecond: 4, EmbedPauseMs: 250})
+	if th.interval != 250*time.Millisecond {
+		t.Errorf("interval = %v, want 250ms", th.interval)
+	}
+	if th.embedPause != 250*time.Millisecond {
+		t.Errorf("embedPause = %v, want 250ms", th.embedPause)
+	}
+
+	if unlimited := newThrottle(config.ThrottleConfig{}); unlimited.interval != 0 || unlimited.embedPause != 0 {
+		t.Errorf("zero config should not throttle, got %+v", unlimited)
+	}
+}
+
+func TestThrottleReconfigured(t *testing.T) {
+	th := newThrottle(config.ThrottleConfig{MaxFilesPerSecond: 4, EmbedPauseMs: 250})
+	th.configure(config.ThrottleConfig{MaxFilesPerSecond: 10})
+	if th.interval != 100*time.Millisecond || th.embedPause != 0 {
+		t.Errorf("after configure interval = %v, embedPause = %v; want 100ms and none", th.interval, th.embedPause)
+	}
+	th.configure(config.ThrottleConfig{})
+	if th.interval != 0 {
+		t.Errorf("removing the limit left interval = %v", th.interval)
+	}
+}
+
+func TestThrottleWaitSpacesFiles(t *testing.T) {
+	th := newThrottle(config.ThrottleConfig{MaxFilesPerSecond: 50}) // 20ms apart
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := th.wait(ctx); err != nil {
+			t.Fatalf("wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("4 files took %v, want at least 60ms", elapsed)
+	}
+}
+
+func TestThrottleWaitsForIdle(t *testing.T) {
+	th := newThrottle(config.ThrottleConfig{})
+	th.idlePoll = time.Millisecond
+	checks := 0
+	th.idle = func() bool {
+		checks++
+		return checks >= 3
+	}
+
+	if err := th.wait(context.Background()); err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+	if checks != 3 {
+		t.Errorf("idle checked %d times, want 3", checks)
+	}
+}
+
+func TestThrottleWaitCancelled(t *testing.T) {
+	th := newThrottle(config.ThrottleConfig{})
+	th.idle = func() bool { return false }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := th.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestNilThrottleNeverWaits(t *testing.T) {
+	var th *throttle
+	if err := th.wait(context.Background()); err != nil {
+		t.Errorf("wait() error = %v", err)
+	}
+	if err := th.afterEmbed(context.Background()); err != nil {
+		t.Errorf("afterEmbed() error = %v", err)
+	}
+}
+
+func TestLoadMonitorDiscountsOwnLoad(t *testing.T) {
+	var cpu time.Duration
+	m := &loadMonitor{
+		loadAverage: func() (float64, bool) { return 3, true },
+		cpuTime:     func() (time.Duration, bool) { return cpu, true },
+		cpus:        4,
+	}
+	now := time.Now()
+	if m.idle(now) {
+		t.Fatal("a load of 3 on 4 CPUs from other processes should not be idle")
+	}
+
+	// The indexer itself keeps three CPUs busy for five minutes, making up
+	// all of the load.
+	for range 30 {
+		now = now.Add(10 * time.Second)
+		cpu += 30 * time.Second
+		m.idle(now)
+	}
+	if !m.idle(now) {
+		t.Errorf("load caused by the indexer itself (own load %.2f) should count as idle", m.ownLoad)
+	}
+
+	// Paused for as long, its share decays like the load average.
+	for range 30 {
+		now = now.Add(10 * time.Second)
+		m.idle(now)
+	}
+	if m.idle(now) {
+		t.Errorf("the same load with the indexer paused (own load %.2f) should not be idle", m.ownLoad)
+	}
+
+	unsupported := &loadMonitor{loadAverage: func() (float64, bool) { return 0, false }, cpus: 1}
+	if !unsupported.idle(now) {
+		t.Error("without a load average the machine should count as idle")
+	}
+}
@@ -0,0 +1,219 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// VerifyReport is what Verify found when cross-checking the database, the
+// search index and the vector store.
+type VerifyReport struct {
+	Documents     int // documents in the database, sections included
+	SearchEntries int // documents in the search index
+	Chunks        int
+	Vectors       int // vectors in the store; -1 without a vector store
+	VectorDim     int // dimension recorded for the vector store
+
+	// MissingFromSearch are documents the search index doesn't have.
+	MissingFromSearch []string
+	// StaleInSearch are search index entries without a document.
+	StaleInSearch []string
+	// OrphanChunks are chunks whose document is gone.
+	OrphanChunks []*storage.Chunk
+	// MissingVectors are documents with chunks that have no vector.
+	MissingVectors []string
+	// WrongDimension are documents with vectors whose dimension differs
+	// from the store's.
+	WrongDimension []string
+	// ExtraVectors is the number of vectors no chunk accounts for. They
+	// can't be told apart from the others; a reindex drops them.
+	ExtraVectors int
+}
+
+// OK reports whether no inconsistencies were found.
+func (r *VerifyReport) OK() bool {
+	return len(r.MissingFromSearch) == 0 && len(r.StaleInSearch) == 0 && len(r.OrphanChunks) == 0 &&
+		len(r.MissingVectors) == 0 && len(r.WrongDimension) == 0 && r.ExtraVectors == 0
+}
+
+// Repairable reports whether Repair can fix anything in the report.
+func (r *VerifyReport) Repairable() bool {
+	return len(r.MissingFromSearch) > 0 || len(r.StaleInSearch) > 0 || len(r.OrphanChunks) > 0 ||
+		len(r.MissingVectors) > 0 || len(r.WrongDimension) > 0
+}
+
+// Verify cross-checks the documents in the database against the search
+// index, their chunks and the vector store. It changes nothing.
+func (idx *Indexer) Verify(ctx context.Context) (*VerifyReport, error) {
+	docs, err := idx.db.ListDocumentSummaries(ctx, "", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("listing documents: %w", err)
+	}
+	searchIDs, err := idx.search.IDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing search index: %w", err)
+	}
+	chunks, err := idx.db.ListChunkSummaries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing chunks: %w", err)
+	}
+
+	r := &VerifyReport{
+		Documents:     len(docs),
+		SearchEntries: len(searchIDs),
+		Chunks:        len(chunks),
+		Vectors:       -1,
+	}
+
+	known := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		known[doc.ID] = true
+	}
+	indexed := make(map[string]bool, len(searchIDs))
+	for _, id := range searchIDs {
+		indexed[id] = true
+		if !known[id] {
+			r.StaleInSearch = append(r.StaleInSearch, id)
+		}
+	}
+	for _, doc := range docs {
+		if !indexed[doc.ID] {
+			r.MissingFromSearch = append(r.MissingFromSearch, doc.ID)
+		}
+	}
+
+	var withVectors int
+	missing := make(map[string]bool)
+	wrongDim := make(map[string]bool)
+	if idx.vectors != nil {
+		r.Vectors = idx.vectors.Len()
+		r.VectorDim = idx.vectors.Dim()
+	}
+	for _, chunk := range chunks {
+		if !known[chunk.DocumentID] {
+			r.OrphanChunks = append(r.OrphanChunks, chunk)
+			continue
+		}
+		if idx.vectors == nil {
+			continue
+		}
+		vec, ok := idx.vectors.Lookup(chunk.ID)
+		if !ok {
+			missing[chunk.DocumentID] = true
+			continue
+		}
+		withVectors++
+		if r.VectorDim > 0 && len(vec) != r.VectorDim {
+			wrongDim[chunk.DocumentID] = true
+		}
+	}
+	if idx.vectors != nil {
+		// Vectors of orphan chunks go with the chunks, since Repair can
+		// remove them by key.
+		for _, chunk := range r.OrphanChunks {
+			if _, ok := idx.vectors.Lookup(chunk.ID); ok {
+				withVectors++
+			}
+		}
+		r.ExtraVectors = max(0, r.Vectors-withVectors)
+	}
+	r.MissingVectors = sortedKeys(missing)
+	r.WrongDimension = sortedKeys(wrongDim)
+	return r, nil
+}
+
+// RepairStats counts what Repair fixed.
+type RepairStats struct {
+	Reindexed  int // documents added back to the search index
+	Removed    int // stale search entries removed
+	Orphans    int // orphan chunks removed, with their vectors
+	Reembedded int // documents whose vectors were regenerated
+}
+
+// Repair fixes the inconsistencies in r: documents missing from the search
+// index are indexed again from the database, stale entries and orphan
+// chunks are removed, and documents with missing or mismatched vectors are
+// re-embedded, which needs an embedder. Documents that can't be repaired
+// are reported to the progress reporter and skipped. Call SaveVectors
+// afterwards.
+func (idx *Indexer) Repair(ctx context.Context, r *VerifyReport) (*RepairStats, error) {
+	stats := &RepairStats{}
+	for _, id := range r.MissingFromSearch {
+		doc, err := idx.db.GetDocument(ctx, id)
+		if err != nil {
+			idx.repairError(id, err)
+			continue
+		}
+		if err := idx.search.Index(ctx, doc); err != nil {
+			idx.repairError(doc.Path, err)
+			continue
+		}
+		stats.Reindexed++
+	}
+	for _, id := range r.StaleInSearch {
+		if err := idx.search.Delete(ctx, id); err != nil {
+			idx.repairError(id, err)
+			continue
+		}
+		stats.Removed++
+	}
+
+	orphaned := make(map[string]bool)
+	for _, chunk := range r.OrphanChunks {
+		if idx.vectors != nil {
+			idx.vectors.Delete(chunk.ID)
+		}
+		orphaned[chunk.DocumentID] = true
+	}
+	for _, docID := range sortedKeys(orphaned) {
+		if err := idx.db.DeleteChunksByDocument(ctx, docID); err != nil {
+			return stats, err
+		}
+	}
+	stats.Orphans = len(r.OrphanChunks)
+
+	reembed := append(append([]string{}, r.MissingVectors...), r.WrongDimension...)
+	if len(reembed) == 0 {
+		return stats, nil
+	}
+	if idx.vectors == nil || idx.embedder == nil {
+		return stats, errors.New("embeddings unavailable: can't regenerate vectors")
+	}
+	done := make(map[string]bool, len(reembed))
+	for _, id := range reembed {
+		if done[id] {
+			continue
+		}
+		done[id] = true
+		doc, err := idx.db.GetDocument(ctx, id)
+		if err != nil {
+			idx.repairError(id, err)
+			continue
+		}
+		if err := idx.storeDocument(ctx, doc, true); err != nil {
+			idx.repairError(doc.Path, err)
+			continue
+		}
+		stats.Reembedded++
+	}
+	return stats, nil
+}
+
+func (idx *Indexer) repairError(path string, err error) {
+	if idx.progress != nil {
+		idx.progress.OnError("repair", path, err)
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,149 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestIndexer_VerifyAndRepair(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	vectors, err := storage.NewVectorStore(filepath.Join(tmpDir, "vectors.graph"))
+	if err != nil {
+		t.Fatalf("creating vector store: %v", err)
+	}
+	defer closeIndexerTestVectors(t, vectors)
+
+	cfg := &config.Config{Indexing: config.IndexingConfig{Workers: 1}}
+	indexer := NewIndexer(db, searchIdx, vectors, &testEmbedder{}, cfg)
+	ctx := context.Background()
+
+	for _, name := range []string{"a", "b", "c"} {
+		doc := &storage.Document{
+			ID:          "doc-" + name,
+			Source:      storage.SourceMarkdown,
+			Path:        filepath.Join(tmpDir, name+".md"),
+			Title:       name,
+			Content:     "content of " + name,
+			ContentHash: name,
+			IndexedAt:   time.Now(),
+			ModifiedAt:  time.Now(),
+		}
+		mustIndexerTestSucceed(t, indexer.storeDocument(ctx, doc, true))
+	}
+
+	report, err := indexer.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("Verify() on a fresh index = %+v, want no problems", report)
+	}
+	if report.Documents != 3 || report.SearchEntries != 3 || report.Chunks != 3 || report.Vectors != 3 {
+		t.Errorf("counts = %d docs, %d search, %d chunks, %d vectors, want 3 each",
+			report.Documents, report.SearchEntries, report.Chunks, report.Vectors)
+	}
+
+	// Break each store in a different way: a document dropped from the
+	// search index, an entry for a document that's gone, a lost vector and
+	// a chunk left behind by a deleted document.
+	mustIndexerTestSucceed(t, searchIdx.Delete(ctx, "doc-a"))
+	mustIndexerTestSucceed(t, searchIdx.Index(ctx, &storage.Document{ID: "doc-gone", Title: "gone"}))
+	vectors.Delete("doc-b:0")
+	raw, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("opening raw database: %v", err)
+	}
+	if _, err := raw.Exec(`INSERT INTO chunks (id, document_id, content, start_pos, end_pos) VALUES ('doc-old:0', 'doc-old', 'old', 0, 3)`); err != nil {
+		t.Fatalf("inserting orphan chunk: %v", err)
+	}
+	_ = raw.Close()
+	mustIndexerTestSucceed(t, vectors.Add("doc-old:0", []float32{1, 1}))
+
+	report, err = indexer.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !slices.Equal(report.MissingFromSearch, []string{"doc-a"}) {
+		t.Errorf("MissingFromSearch = %v, want [doc-a]", report.MissingFromSearch)
+	}
+	if !slices.Equal(report.StaleInSearch, []string{"doc-gone"}) {
+		t.Errorf("StaleInSearch = %v, want [doc-gone]", report.StaleInSearch)
+	}
+	if len(report.OrphanChunks) != 1 || report.OrphanChunks[0].ID != "doc-old:0" {
+		t.Errorf("OrphanChunks = %v, want doc-old:0", report.OrphanChunks)
+	}
+	if !slices.Equal(report.MissingVectors, []string{"doc-b"}) {
+		t.Errorf("MissingVectors = %v, want [doc-b]", report.MissingVectors)
+	}
+	if report.ExtraVectors != 0 {
+		t.Errorf("ExtraVectors = %d, want 0", report.ExtraVectors)
+	}
+
+	stats, err := indexer.Repair(ctx, report)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	if stats.Reindexed != 1 || stats.Removed != 1 || stats.Orphans != 1 || stats.Reembedded != 1 {
+		t.Errorf("Repair() = %+v, want one of each", stats)
+	}
+	report, err = indexer.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Verify() after Repair() = %+v, want no problems", report)
+	}
+}
+
+func TestIndexer_VerifyCountsExtraVectors(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := storage.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer closeIndexerTestDB(t, db)
+
+	searchIdx, err := search.NewBleveIndex(filepath.Join(tmpDir, "test.bleve"))
+	if err != nil {
+		t.Fatalf("creating search index: %v", err)
+	}
+	defer closeIndexerTestSearch(t, searchIdx)
+
+	vectors, err := storage.NewVectorStore(filepath.Join(tmpDir, "vectors.graph"))
+	if err != nil {
+		t.Fatalf("creating vector store: %v", err)
+	}
+	defer closeIndexerTestVectors(t, vectors)
+	mustIndexerTestSucceed(t, vectors.Add("nowhere:0", []float32{1, 2}))
+
+	indexer := NewIndexer(db, searchIdx, vectors, nil, &config.Config{Indexing: config.IndexingConfig{Workers: 1}})
+	report, err := indexer.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if report.ExtraVectors != 1 || report.OK() || report.Repairable() {
+		t.Errorf("ExtraVectors = %d, OK = %v, Repairable = %v; want 1, false, false",
+			report.ExtraVectors, report.OK(), report.Repairable())
+	}
+}
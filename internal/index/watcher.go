@@ -2,6 +2,7 @@ package index
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"path/filepath"
@@ -9,9 +10,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/J-1000/mindcli/internal/index/sources"
+	"github.com/J-1000/mindcli/internal/storage"
 	"github.com/fsnotify/fsnotify"
 )
 
+// expireInterval is how often documents past their source's retention
+// period are removed while watching.
+const expireInterval = time.Hour
+
 // Watcher monitors directories for file changes and triggers re-indexing.
 type Watcher struct {
 	indexer      *Indexer
@@ -21,6 +28,12 @@ type Watcher struct {
 	mu           sync.Mutex
 	pending      map[string]time.Time
 	done         chan struct{}
+
+	// files holds roots that name single files. Their parent directory is
+	// watched (so replacing the file doesn't drop the watch) but unrelated
+	// entries in that directory are ignored.
+	files map[string]bool
+	dirs  map[string]bool // directories watched recursively
 }
 
 // NewWatcher creates a file system watcher for the given paths.
@@ -37,6 +50,8 @@ func NewWatcher(indexer *Indexer, paths []string) (*Watcher, error) {
 		debounceTime: 500 * time.Millisecond,
 		pending:      make(map[string]time.Time),
 		done:         make(chan struct{}),
+		files:        make(map[string]bool),
+		dirs:         make(map[string]bool),
 	}, nil
 }
 
@@ -53,6 +68,12 @@ func (w *Watcher) Start(ctx context.Context) error {
 	// Start debounce goroutine.
 	go w.debounceLoop(ctx)
 
+	// Sources without files to watch are re-scanned on their own schedule.
+	for _, src := range w.indexer.Pollers() {
+		go w.pollLoop(ctx, src)
+	}
+	go w.expireLoop(ctx)
+
 	// Process events.
 	for {
 		select {
@@ -81,33 +102,73 @@ func (w *Watcher) Start(ctx context.Context) error {
 
 // handleEvent processes a file system event.
 func (w *Watcher) handleEvent(ctx context.Context, event fsnotify.Event) {
-	// Only care about writes, creates, and renames.
-	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
-		if event.Op&fsnotify.Remove != 0 {
-			// File removed - schedule removal.
-			w.mu.Lock()
-			w.pending[event.Name] = time.Now()
-			w.mu.Unlock()
-		}
+	if isEditorTempFile(event.Name) || !w.isWatched(event.Name) {
 		return
 	}
 
-	// For new directories, start watching them.
+	// Removals and renames (the old name of a move) are resolved once the
+	// path settles: if nothing exists there anymore, it is dropped from the
+	// index.
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+		return
+	}
+
+	// For new directories (created or moved in), start watching them and
+	// queue the files they already contain, which may have been written
+	// before the watch was in place.
 	if event.Op&fsnotify.Create != 0 {
 		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
 			if err := w.addRecursive(event.Name); err != nil {
 				log.Printf("watching new directory %s: %v", event.Name, err)
 			}
+			w.queueDir(event.Name)
 			return
 		}
 	}
 
-	// Queue file for re-indexing with debounce.
+	// Queue path with debounce. Editors that save atomically (write a temp
+	// file, then rename it over the original) produce a burst of events for
+	// the same path; they collapse into a single re-index here.
+	w.queue(event.Name)
+}
+
+// queue schedules path for processing after the debounce window.
+func (w *Watcher) queue(path string) {
 	w.mu.Lock()
-	w.pending[event.Name] = time.Now()
+	w.pending[path] = time.Now()
 	w.mu.Unlock()
 }
 
+// queueDir schedules every file below dir for processing.
+func (w *Watcher) queueDir(dir string) {
+	_ = filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if skipWatchDir(d.Name()) && p != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isEditorTempFile(p) {
+			w.queue(p)
+		}
+		return nil
+	})
+}
+
+// isWatched reports whether events for path should be handled. Events from
+// the parent directory of a single-file root only matter for that file.
+func (w *Watcher) isWatched(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.files[path] || w.dirs[path] {
+		return true
+	}
+	return w.dirs[filepath.Dir(path)]
+}
+
 // debounceLoop periodically processes pending files.
 func (w *Watcher) debounceLoop(ctx context.Context) {
 	ticker := time.NewTicker(w.debounceTime)
@@ -125,6 +186,57 @@ func (w *Watcher) debounceLoop(ctx context.Context) {
 	}
 }
 
+// pollLoop periodically re-scans a source that cannot be watched.
+func (w *Watcher) pollLoop(ctx context.Context, src sources.Source) {
+	ticker := time.NewTicker(src.(sources.Poller).PollInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.done:
+			return
+		case <-ticker.C:
+			n, err := w.indexer.PollSource(ctx, src)
+			if err != nil {
+				log.Printf("polling %s: %v", src.Name(), err)
+			}
+			if n > 0 {
+				if err := w.indexer.SaveVectors(); err != nil {
+					log.Printf("saving vectors: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// expireLoop periodically removes documents past their source's retention
+// period, which would otherwise only go on the next full index.
+func (w *Watcher) expireLoop(ctx context.Context) {
+	ticker := time.NewTicker(expireInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.done:
+			return
+		case <-ticker.C:
+			expired, err := w.indexer.Expire(ctx)
+			if err != nil {
+				log.Printf("expiring documents: %v", err)
+			}
+			if len(expired) > 0 {
+				if err := w.indexer.SaveVectors(); err != nil {
+					log.Printf("saving vectors: %v", err)
+				}
+			}
+		}
+	}
+}
+
 // processPending re-indexes files that have settled (no changes within debounce window).
 func (w *Watcher) processPending(ctx context.Context) {
 	w.mu.Lock()
@@ -144,16 +256,21 @@ func (w *Watcher) processPending(ctx context.Context) {
 
 	changed := false
 	for _, path := range ready {
-		// Check if file still exists.
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			// File was removed.
-			if err := w.indexer.RemoveFile(ctx, path); err != nil {
-				log.Printf("removing %s from index: %v", path, err)
-			} else {
+		// Check if the path still exists.
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			if w.removePath(ctx, path) {
 				changed = true
 			}
 			continue
 		}
+		if err != nil {
+			log.Printf("checking %s: %v", path, err)
+			continue
+		}
+		if info.IsDir() || !w.indexer.handles(path) {
+			continue
+		}
 
 		// Re-index the file.
 		if err := w.indexer.IndexFile(ctx, path); err != nil {
@@ -172,24 +289,85 @@ func (w *Watcher) processPending(ctx context.Context) {
 	}
 }
 
-// addRecursive adds a directory and all subdirectories to the watcher.
+// removePath drops a path that no longer exists from the index. The path may
+// have been a single document or a directory that was deleted or moved away.
+// It reports whether anything was removed.
+func (w *Watcher) removePath(ctx context.Context, path string) bool {
+	err := w.indexer.RemoveFile(ctx, path)
+	if err == nil {
+		return true
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("removing %s from index: %v", path, err)
+		return false
+	}
+
+	w.mu.Lock()
+	for dir := range w.dirs {
+		if dir == path || strings.HasPrefix(dir, path+string(filepath.Separator)) {
+			delete(w.dirs, dir)
+		}
+	}
+	w.mu.Unlock()
+
+	n, err := w.indexer.RemoveDir(ctx, path)
+	if err != nil {
+		log.Printf("removing %s from index: %v", path, err)
+	}
+	return n > 0
+}
+
+// addRecursive adds a directory and all subdirectories to the watcher. A path
+// that names a single file (e.g. a browser history database) is watched as is.
 func (w *Watcher) addRecursive(path string) error {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		w.mu.Lock()
+		w.files[path] = true
+		w.mu.Unlock()
+		return w.watcher.Add(filepath.Dir(path))
+	}
 	return filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
 		if d.IsDir() {
-			name := d.Name()
 			// Skip common directories that shouldn't be watched.
-			if name == ".git" || name == "node_modules" || name == ".obsidian" {
+			if skipWatchDir(d.Name()) {
 				return filepath.SkipDir
 			}
-			return w.watcher.Add(p)
+			if err := w.watcher.Add(p); err != nil {
+				return err
+			}
+			w.mu.Lock()
+			w.dirs[p] = true
+			w.mu.Unlock()
 		}
 		return nil
 	})
 }
 
+// skipWatchDir reports whether a directory should never be watched.
+func skipWatchDir(name string) bool {
+	return name == ".git" || name == "node_modules" || name == ".obsidian"
+}
+
+// isEditorTempFile reports whether path looks like a scratch file written by
+// an editor while saving (vim swap/backup files, emacs lock files, ...).
+func isEditorTempFile(path string) bool {
+	name := filepath.Base(path)
+	switch {
+	case name == "4913": // vim's write-permission probe
+		return true
+	case strings.HasSuffix(name, "~"):
+		return true
+	case strings.HasPrefix(name, ".#"), strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#"):
+		return true
+	case strings.HasPrefix(name, ".") && (strings.HasSuffix(name, ".swp") || strings.HasSuffix(name, ".swo") || strings.HasSuffix(name, ".swx")):
+		return true
+	}
+	return false
+}
+
 // expandWatchPath expands ~ to home directory.
 func expandWatchPath(path string) string {
 	if strings.HasPrefix(path, "~/") {
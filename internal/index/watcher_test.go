@@ -5,12 +5,14 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/J-1000/mindcli/internal/config"
 	"github.com/J-1000/mindcli/internal/search"
 	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/fsnotify/fsnotify"
 )
 
 func TestWatcher_IndexesAndRemoves(t *testing.T) {
@@ -134,3 +136,207 @@ func TestWatcherCreation(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// newEventTestWatcher returns a watcher over a temporary notes directory whose
+// events are fed by hand, so event sequences can be tested deterministically.
+func newEventTestWatcher(t *testing.T) (*Watcher, *storage.DB, string) {
+	t.Helper()
+
+	tmp := t.TempDir()
+	notesDir := filepath.Join(tmp, "notes")
+	mustIndexerTestSucceed(t, os.MkdirAll(notesDir, 0755))
+
+	db, err := storage.Open(filepath.Join(tmp, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { closeIndexerTestDB(t, db) })
+	bleve, err := search.NewBleveIndex(filepath.Join(tmp, "test.bleve"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { closeIndexerTestSearch(t, bleve) })
+
+	cfg := &config.Config{
+		Sources:  config.SourcesConfig{Markdown: config.MarkdownSourceConfig{Enabled: true, Paths: []string{notesDir}, Extensions: []string{".md"}}},
+		Indexing: config.IndexingConfig{Workers: 1},
+	}
+	w, err := NewWatcher(NewIndexer(db, bleve, nil, nil, cfg), []string{notesDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.watcher.Close() })
+	w.debounceTime = 0
+	mustIndexerTestSucceed(t, w.addRecursive(notesDir))
+
+	return w, db, notesDir
+}
+
+func sendEvents(w *Watcher, events ...fsnotify.Event) {
+	for _, e := range events {
+		w.handleEvent(context.Background(), e)
+	}
+	w.processPending(context.Background())
+}
+
+func TestWatcher_RenameReplacesDocument(t *testing.T) {
+	w, db, notesDir := newEventTestWatcher(t)
+	ctx := context.Background()
+
+	oldPath := filepath.Join(notesDir, "old.md")
+	newPath := filepath.Join(notesDir, "new.md")
+	mustIndexerTestSucceed(t, os.WriteFile(oldPath, []byte("# Moved\n\nbody"), 0644))
+	sendEvents(w, fsnotify.Event{Name: oldPath, Op: fsnotify.Create})
+
+	mustIndexerTestSucceed(t, os.Rename(oldPath, newPath))
+	sendEvents(w,
+		fsnotify.Event{Name: oldPath, Op: fsnotify.Rename},
+		fsnotify.Event{Name: newPath, Op: fsnotify.Create},
+	)
+
+	if doc, _ := db.GetDocumentByPath(ctx, oldPath); doc != nil {
+		t.Error("old path should be removed after rename")
+	}
+	if doc, _ := db.GetDocumentByPath(ctx, newPath); doc == nil {
+		t.Error("new path should be indexed after rename")
+	}
+	if n, _ := db.CountDocuments(ctx); n != 1 {
+		t.Errorf("CountDocuments() = %d, want 1", n)
+	}
+}
+
+func TestWatcher_NewDirectoryIsWatchedAndIndexed(t *testing.T) {
+	w, db, notesDir := newEventTestWatcher(t)
+
+	// Files written before the directory's watch exists must not be missed.
+	sub := filepath.Join(notesDir, "projects")
+	deep := filepath.Join(sub, "2024")
+	mustIndexerTestSucceed(t, os.MkdirAll(deep, 0755))
+	notePath := filepath.Join(deep, "plan.md")
+	mustIndexerTestSucceed(t, os.WriteFile(notePath, []byte("# Plan"), 0644))
+
+	sendEvents(w, fsnotify.Event{Name: sub, Op: fsnotify.Create})
+
+	if doc, _ := db.GetDocumentByPath(context.Background(), notePath); doc == nil {
+		t.Error("file inside new directory should be indexed")
+	}
+	if !w.dirs[deep] {
+		t.Error("nested directory should be added to the watch set")
+	}
+}
+
+func TestWatcher_RemovedDirectoryDropsDocuments(t *testing.T) {
+	w, db, notesDir := newEventTestWatcher(t)
+	ctx := context.Background()
+
+	sub := filepath.Join(notesDir, "archive")
+	mustIndexerTestSucceed(t, os.MkdirAll(sub, 0755))
+	for _, name := range []string{"a.md", "b.md"} {
+		mustIndexerTestSucceed(t, os.WriteFile(filepath.Join(sub, name), []byte("# "+name), 0644))
+	}
+	keep := filepath.Join(notesDir, "archive-notes.md")
+	mustIndexerTestSucceed(t, os.WriteFile(keep, []byte("# Keep"), 0644))
+	sendEvents(w,
+		fsnotify.Event{Name: sub, Op: fsnotify.Create},
+		fsnotify.Event{Name: keep, Op: fsnotify.Create},
+	)
+	if n, _ := db.CountDocuments(ctx); n != 3 {
+		t.Fatalf("CountDocuments() = %d, want 3", n)
+	}
+
+	mustIndexerTestSucceed(t, os.RemoveAll(sub))
+	sendEvents(w, fsnotify.Event{Name: sub, Op: fsnotify.Remove})
+
+	if n, _ := db.CountDocuments(ctx); n != 1 {
+		t.Errorf("CountDocuments() = %d, want 1", n)
+	}
+	if doc, _ := db.GetDocumentByPath(ctx, keep); doc == nil {
+		t.Error("sibling file sharing the directory prefix should be kept")
+	}
+	if w.dirs[sub] {
+		t.Error("removed directory should leave the watch set")
+	}
+}
+
+func TestWatcher_AtomicSave(t *testing.T) {
+	w, db, notesDir := newEventTestWatcher(t)
+	ctx := context.Background()
+
+	notePath := filepath.Join(notesDir, "note.md")
+	mustIndexerTestSucceed(t, os.WriteFile(notePath, []byte("# Note\n\nfirst"), 0644))
+	sendEvents(w, fsnotify.Event{Name: notePath, Op: fsnotify.Create})
+
+	// vim with backupcopy=no: probe file, swap file, move the original to a
+	// backup, write a fresh file, then delete the backup.
+	probe := filepath.Join(notesDir, "4913")
+	swap := filepath.Join(notesDir, ".note.md.swp")
+	backup := notePath + "~"
+	mustIndexerTestSucceed(t, os.WriteFile(notePath, []byte("# Note\n\nsecond"), 0644))
+	for _, e := range []fsnotify.Event{
+		{Name: swap, Op: fsnotify.Create},
+		{Name: probe, Op: fsnotify.Create},
+		{Name: probe, Op: fsnotify.Remove},
+		{Name: notePath, Op: fsnotify.Rename},
+		{Name: backup, Op: fsnotify.Create},
+		{Name: notePath, Op: fsnotify.Create},
+		{Name: notePath, Op: fsnotify.Write},
+		{Name: backup, Op: fsnotify.Remove},
+	} {
+		w.handleEvent(ctx, e)
+	}
+	if len(w.pending) != 1 {
+		t.Fatalf("pending = %v, want only %s", w.pending, notePath)
+	}
+	w.processPending(ctx)
+
+	doc, err := db.GetDocumentByPath(ctx, notePath)
+	if err != nil {
+		t.Fatalf("GetDocumentByPath() error = %v", err)
+	}
+	if !strings.Contains(doc.Content, "second") {
+		t.Errorf("content = %q, want updated content", doc.Content)
+	}
+	if n, _ := db.CountDocuments(ctx); n != 1 {
+		t.Errorf("CountDocuments() = %d, want 1", n)
+	}
+}
+
+func TestWatcher_SingleFileRootIgnoresSiblings(t *testing.T) {
+	w, _, notesDir := newEventTestWatcher(t)
+
+	other := t.TempDir()
+	dbFile := filepath.Join(other, "History")
+	mustIndexerTestSucceed(t, os.WriteFile(dbFile, []byte("x"), 0644))
+	mustIndexerTestSucceed(t, w.addRecursive(dbFile))
+
+	if !w.isWatched(dbFile) {
+		t.Error("single-file root should be watched")
+	}
+	if w.isWatched(filepath.Join(other, "History-journal")) {
+		t.Error("siblings of a single-file root should be ignored")
+	}
+	if !w.isWatched(filepath.Join(notesDir, "x.md")) {
+		t.Error("files in watched directories should be handled")
+	}
+}
+
+func TestIsEditorTempFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/n/note.md", false},
+		{"/n/.hidden.md", false},
+		{"/n/4913", true},
+		{"/n/note.md~", true},
+		{"/n/.note.md.swp", true},
+		{"/n/.note.md.swx", true},
+		{"/n/.#note.md", true},
+		{"/n/#note.md#", true},
+	}
+	for _, tt := range tests {
+		if got := isEditorTempFile(tt.path); got != tt.want {
+			t.Errorf("isEditorTempFile(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
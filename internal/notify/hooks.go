@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/index"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// Index events, as named in hook payloads and MINDCLI_EVENT.
+const (
+	EventDocumentIndexed = "document_indexed"
+	EventDocumentRemoved = "document_removed"
+	EventIndexComplete   = "index_complete"
+)
+
+// hookTimeout bounds one run of a hook command.
+const hookTimeout = time.Minute
+
+// hookQueueSize is how many events may wait for slow hooks before indexing
+// waits too.
+const hookQueueSize = 256
+
+// HookPayload is the JSON a hook receives.
+type HookPayload struct {
+	Event    string            `json:"event"`
+	Time     time.Time         `json:"time"`
+	Document *storage.Document `json:"document,omitempty"`
+	Stats    *HookStats        `json:"stats,omitempty"`
+}
+
+// HookStats summarizes a finished index run.
+type HookStats struct {
+	TotalFiles   int64            `json:"total_files"`
+	IndexedFiles int64            `json:"indexed_files"`
+	Errors       int64            `json:"errors"`
+	Embedded     int64            `json:"embedded"`
+	BySource     map[string]int64 `json:"by_source"`
+}
+
+type hookRun struct {
+	hook    config.HookConfig
+	payload HookPayload
+}
+
+// Hooks runs the configured hooks for index events. It implements
+// index.EventHandler. Hooks run one at a time in the order of their events,
+// on a goroutine of their own so indexing isn't held up by them; Close waits
+// for the ones still queued.
+type Hooks struct {
+	cfg    config.HooksConfig
+	report func(event string, err error)
+
+	mu     sync.Mutex
+	closed bool
+	queue  chan hookRun
+	done   chan struct{}
+}
+
+var _ index.EventHandler = (*Hooks)(nil)
+
+// NewHooks starts running the hooks in cfg. Failed hooks are passed to
+// report and don't stop later ones.
+func NewHooks(cfg config.HooksConfig, report func(event string, err error)) *Hooks {
+	h := &Hooks{
+		cfg:    cfg,
+		report: report,
+		queue:  make(chan hookRun, hookQueueSize),
+		done:   make(chan struct{}),
+	}
+	go h.loop()
+	return h
+}
+
+// OnDocumentIndexed runs the on_document_indexed hook.
+func (h *Hooks) OnDocumentIndexed(doc *storage.Document) {
+	h.enqueue(h.cfg.OnDocumentIndexed, HookPayload{Event: EventDocumentIndexed, Document: doc})
+}
+
+// OnDocumentRemoved runs the on_document_removed hook.
+func (h *Hooks) OnDocumentRemoved(doc *storage.Document) {
+	h.enqueue(h.cfg.OnDocumentRemoved, HookPayload{Event: EventDocumentRemoved, Document: doc})
+}
+
+// OnIndexComplete runs the on_index_complete hook.
+func (h *Hooks) OnIndexComplete(stats *index.Stats) {
+	h.enqueue(h.cfg.OnIndexComplete, HookPayload{Event: EventIndexComplete, Stats: &HookStats{
+		TotalFiles:   stats.TotalFiles,
+		IndexedFiles: stats.IndexedFiles,
+		Errors:       stats.Errors,
+		Embedded:     stats.Embedded,
+		BySource:     stats.BySource,
+	}})
+}
+
+func (h *Hooks) enqueue(hook config.HookConfig, payload HookPayload) {
+	if !hook.Enabled() {
+		return
+	}
+	payload.Time = time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.queue <- hookRun{hook: hook, payload: payload}
+	}
+}
+
+func (h *Hooks) loop() {
+	defer close(h.done)
+	for run := range h.queue {
+		if err := runHook(run.hook, run.payload); err != nil && h.report != nil {
+			h.report(run.payload.Event, err)
+		}
+	}
+}
+
+// Close waits for queued hooks to finish. Later events are dropped.
+func (h *Hooks) Close() {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	h.mu.Unlock()
+	<-h.done
+}
+
+// runHook hands payload to the hook's command, with MINDCLI_EVENT and, for
+// document events, MINDCLI_DOCUMENT_PATH set, and POSTs it to its webhook.
+func runHook(hook config.HookConfig, payload HookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	if hook.Command != "" {
+		env := []string{"MINDCLI_EVENT=" + payload.Event}
+		if payload.Document != nil {
+			env = append(env, "MINDCLI_DOCUMENT_PATH="+payload.Document.Path)
+		}
+		if err := runCommand(ctx, hook.Command, env, string(body)); err != nil {
+			return fmt.Errorf("running hook command: %w", err)
+		}
+	}
+	if hook.Webhook != "" {
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		if err := post(ctx, hook.Webhook, header, string(body)); err != nil {
+			return fmt.Errorf("posting to hook webhook: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/config"
+	"github.com/J-1000/mindcli/internal/index"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestHooks(t *testing.T) {
+	var mu sync.Mutex
+	var posted []HookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p HookPayload
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Errorf("webhook got invalid JSON %q: %v", body, err)
+		}
+		mu.Lock()
+		posted = append(posted, p)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	log := filepath.Join(t.TempDir(), "hooks.log")
+	var reported []string
+	hooks := NewHooks(config.HooksConfig{
+		OnDocumentIndexed: config.HookConfig{Command: `echo "$MINDCLI_EVENT $MINDCLI_DOCUMENT_PATH" >> "` + log + `"`},
+		OnDocumentRemoved: config.HookConfig{Command: "echo failed >&2; exit 1"},
+		OnIndexComplete:   config.HookConfig{Webhook: srv.URL},
+	}, func(event string, err error) {
+		reported = append(reported, event+": "+err.Error())
+	})
+
+	doc := &storage.Document{ID: "1", Source: storage.SourceMarkdown, Path: "/notes/plan.md", Title: "Plan"}
+	hooks.OnDocumentIndexed(doc)
+	hooks.OnDocumentRemoved(doc)
+	hooks.OnIndexComplete(&index.Stats{TotalFiles: 3, IndexedFiles: 2, BySource: map[string]int64{"markdown": 2}})
+	hooks.Close()
+	hooks.OnDocumentIndexed(doc) // dropped after Close
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "document_indexed /notes/plan.md\n" {
+		t.Errorf("command ran with %q, want the event and path once", data)
+	}
+	if len(reported) != 1 || !strings.HasPrefix(reported[0], "document_removed: ") || !strings.Contains(reported[0], "failed") {
+		t.Errorf("reported = %v, want the failing removed hook", reported)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(posted) != 1 || posted[0].Event != EventIndexComplete || posted[0].Stats == nil || posted[0].Stats.IndexedFiles != 2 {
+		t.Errorf("webhook got %+v, want the index_complete stats", posted)
+	}
+}
@@ -0,0 +1,188 @@
+// Package notify tells the outside world about the index. Digests deliver
+// the results of a saved query, rendered as markdown, on a schedule; hooks
+// report documents as they are indexed and removed. Both hand their payload
+// to a shell command or POST it to a webhook.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/pkg/chunker"
+)
+
+// webhookTimeout bounds a webhook request.
+const webhookTimeout = 30 * time.Second
+
+// Digest is a saved query whose results are delivered on a schedule.
+type Digest struct {
+	Name     string
+	Query    string
+	Schedule Schedule
+	Limit    int
+	Command  string // run by the shell with the digest on stdin
+	Webhook  string // URL the digest is POSTed to
+
+	OnThisDay bool // also list documents from this day in earlier years
+}
+
+// Render formats the results of a digest's query as markdown: a heading with
+// the digest's name and date, the period the query covers unless it is
+// empty, then one entry per result with its preview cut to snippetLen
+// characters.
+func Render(name, query, period string, results storage.SearchResults, now time.Time, snippetLen int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s — %s\n\n", name, now.Format("Monday, 2 January 2006"))
+	if period != "" {
+		fmt.Fprintf(&sb, "Covering %s.\n\n", period)
+	}
+	switch len(results) {
+	case 0:
+		fmt.Fprintf(&sb, "Nothing matches `%s`.\n", query)
+		return sb.String()
+	case 1:
+		fmt.Fprintf(&sb, "1 result for `%s`:\n\n", query)
+	default:
+		fmt.Fprintf(&sb, "%d results for `%s`:\n\n", len(results), query)
+	}
+	for _, r := range results {
+		writeEntry(&sb, r.Document, string(r.Document.Source), snippetLen)
+	}
+	return sb.String()
+}
+
+// RenderOnThisDay formats documents from this day in earlier years as a
+// markdown section to follow Render's, each labeled with its year. It
+// returns "" when there are none.
+func RenderOnThisDay(docs []*storage.Document, snippetLen int) string {
+	if len(docs) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n## On this day\n\n")
+	for _, doc := range docs {
+		writeEntry(&sb, doc, fmt.Sprintf("%d, %s", doc.Date().Year(), doc.Source), snippetLen)
+	}
+	return sb.String()
+}
+
+// writeEntry writes a document as a list item: its title, label and path,
+// then its preview cut to snippetLen characters.
+func writeEntry(sb *strings.Builder, doc *storage.Document, label string, snippetLen int) {
+	title := doc.Title
+	if title == "" {
+		title = doc.Path
+	}
+	fmt.Fprintf(sb, "- **%s** (%s) — %s\n", title, label, doc.Path)
+	if preview := strings.Join(strings.Fields(doc.Preview), " "); preview != "" {
+		fmt.Fprintf(sb, "  %s\n", chunker.Truncate(preview, snippetLen))
+	}
+}
+
+// Deliver hands a rendered digest to its command and webhook. The command
+// runs through the shell with the digest on stdin and MINDCLI_DIGEST set to
+// the digest's name, so it can be as simple as "sendmail me@example.com"
+// or "curl -d @- ntfy.sh/my-topic". The webhook gets the digest as the body
+// of a POST with a text/markdown content type.
+func Deliver(ctx context.Context, d Digest, body string) error {
+	if d.Command != "" {
+		if err := runCommand(ctx, d.Command, []string{"MINDCLI_DIGEST=" + d.Name}, body); err != nil {
+			return fmt.Errorf("running digest command: %w", err)
+		}
+	}
+	if d.Webhook != "" {
+		header := http.Header{}
+		header.Set("Content-Type", "text/markdown; charset=utf-8")
+		header.Set("Title", d.Name) // shown by ntfy
+		if err := post(ctx, d.Webhook, header, body); err != nil {
+			return fmt.Errorf("posting digest: %w", err)
+		}
+	}
+	return nil
+}
+
+// runCommand runs command through the shell with body on stdin and env
+// added to the environment. Its stderr is included in the error.
+func runCommand(ctx context.Context, command string, env []string, body string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = strings.NewReader(body)
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// post sends body to url with the given headers. A response other than 2xx
+// is an error carrying the start of the response body.
+func post(ctx context.Context, url string, header http.Header, body string) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Run sends each digest whenever its schedule fires until ctx is done. send
+// renders and delivers one digest; its errors go to report and don't stop
+// later runs. Runs missed while Run wasn't running are skipped.
+func Run(ctx context.Context, digests []Digest, send func(context.Context, Digest) error, report func(Digest, error)) {
+	if len(digests) == 0 {
+		return
+	}
+	next := make([]time.Time, len(digests))
+	for i, d := range digests {
+		next[i] = d.Schedule.Next(time.Now())
+	}
+	for {
+		soonest := 0
+		for i := range next {
+			if next[i].Before(next[soonest]) {
+				soonest = i
+			}
+		}
+		timer := time.NewTimer(time.Until(next[soonest]))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		d := digests[soonest]
+		if err := send(ctx, d); err != nil && ctx.Err() == nil {
+			report(d, err)
+		}
+		next[soonest] = d.Schedule.Next(time.Now())
+	}
+}
@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestRender(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 8, 0, 0, 0, time.UTC)
+	results := storage.SearchResults{
+		{Document: &storage.Document{Title: "Standup", Source: storage.SourceMarkdown, Path: "/notes/standup.md", Preview: "Ship the\n  release"}},
+		{Document: &storage.Document{Source: storage.SourcePDF, Path: "/docs/untitled.pdf"}},
+	}
+	got := Render("inbox", "tag:inbox", "12 October 2026 – 19 October 2026", results, now, 300)
+	for _, want := range []string{
+		"# inbox — Monday, 19 October 2026\n",
+		"Covering 12 October 2026 – 19 October 2026.\n",
+		"2 results for `tag:inbox`",
+		"- **Standup** (markdown) — /notes/standup.md\n  Ship the release\n",
+		"- **/docs/untitled.pdf** (pdf) — /docs/untitled.pdf\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() missing %q in:\n%s", want, got)
+		}
+	}
+	if got := Render("inbox", "tag:inbox", "", nil, now, 300); !strings.Contains(got, "Nothing matches") || strings.Contains(got, "Covering") {
+		t.Errorf("Render() with no results = %q", got)
+	}
+}
+
+func TestRenderOnThisDay(t *testing.T) {
+	docs := []*storage.Document{
+		{Title: "Trip", Source: storage.SourceMarkdown, Path: "/journal/trip.md", Preview: "Arrived in Lisbon",
+			ModifiedAt: time.Date(2023, time.October, 19, 9, 0, 0, 0, time.UTC)},
+	}
+	want := "\n## On this day\n\n- **Trip** (2023, markdown) — /journal/trip.md\n  Arrived in Lisbon\n"
+	if got := RenderOnThisDay(docs, 300); got != want {
+		t.Errorf("RenderOnThisDay() = %q, want %q", got, want)
+	}
+	if got := RenderOnThisDay(nil, 300); got != "" {
+		t.Errorf("RenderOnThisDay(nil) = %q, want nothing", got)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	var mu sync.Mutex
+	var posted, contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		posted, contentType = string(body), r.Header.Get("Content-Type")
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	out := filepath.Join(t.TempDir(), "digest.md")
+	d := Digest{
+		Name:    "inbox",
+		Command: `cat > "` + out + `"; echo "$MINDCLI_DIGEST" >> "` + out + `"`,
+		Webhook: srv.URL,
+	}
+	if err := Deliver(context.Background(), d, "# inbox\n"); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "# inbox\ninbox\n" {
+		t.Errorf("command got %q, want the digest and its name", data)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if posted != "# inbox\n" || !strings.HasPrefix(contentType, "text/markdown") {
+		t.Errorf("webhook got %q (%s)", posted, contentType)
+	}
+
+	if err := Deliver(context.Background(), Digest{Command: "echo broken >&2; exit 3"}, ""); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Deliver() error = %v, want the command's stderr", err)
+	}
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such topic", http.StatusNotFound)
+	}))
+	defer failing.Close()
+	if err := Deliver(context.Background(), Digest{Webhook: failing.URL}, ""); err == nil || !strings.Contains(err.Error(), "no such topic") {
+		t.Errorf("Deliver() error = %v, want the webhook's response", err)
+	}
+}
+
+func TestRunStopsWithContext(t *testing.T) {
+	sched, err := ParseSchedule("hourly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(ctx, []Digest{{Name: "inbox", Schedule: sched}}, func(context.Context, Digest) error {
+			t.Error("digest sent before its schedule")
+			return nil
+		}, func(Digest, error) {})
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after the context was canceled")
+	}
+}
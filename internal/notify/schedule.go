@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schedule says when a digest runs.
+type Schedule struct {
+	every   time.Duration // fixed interval, for "hourly" and "every <duration>"
+	weekday *time.Weekday // day of the week, for "weekly"
+	hour    int
+	minute  int
+}
+
+// ParseSchedule parses a schedule written as one of:
+//
+//	hourly
+//	every 6h             any Go duration of at least a minute
+//	daily 08:00
+//	weekly monday 08:00  weekday names may be shortened to three letters
+//
+// Times are in local time.
+func ParseSchedule(s string) (Schedule, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 {
+		return Schedule{}, fmt.Errorf("empty schedule")
+	}
+	switch {
+	case fields[0] == "hourly" && len(fields) == 1:
+		return Schedule{every: time.Hour}, nil
+
+	case fields[0] == "every" && len(fields) == 2:
+		d, err := time.ParseDuration(fields[1])
+		if err != nil || d < time.Minute {
+			return Schedule{}, fmt.Errorf("schedule %q: want a duration of at least 1m, such as 6h", s)
+		}
+		return Schedule{every: d}, nil
+
+	case fields[0] == "daily" && len(fields) == 2:
+		sched := Schedule{}
+		if err := sched.setTime(fields[1]); err != nil {
+			return Schedule{}, fmt.Errorf("schedule %q: %w", s, err)
+		}
+		return sched, nil
+
+	case fields[0] == "weekly" && len(fields) == 3:
+		day, ok := parseWeekday(fields[1])
+		if !ok {
+			return Schedule{}, fmt.Errorf("schedule %q: unknown weekday %q", s, fields[1])
+		}
+		sched := Schedule{weekday: &day}
+		if err := sched.setTime(fields[2]); err != nil {
+			return Schedule{}, fmt.Errorf("schedule %q: %w", s, err)
+		}
+		return sched, nil
+	}
+	return Schedule{}, fmt.Errorf("schedule %q: use hourly, every <duration>, daily HH:MM or weekly <day> HH:MM", s)
+}
+
+func (s *Schedule) setTime(hhmm string) error {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return fmt.Errorf("want a time such as 08:00, got %q", hhmm)
+	}
+	s.hour, s.minute = t.Hour(), t.Minute()
+	return nil
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || name == full[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// Next returns the first time the schedule fires after t.
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Truncate(time.Minute).Add(s.every)
+	}
+	next := time.Date(t.Year(), t.Month(), t.Day(), s.hour, s.minute, 0, 0, t.Location())
+	for !next.After(t) || s.weekday != nil && next.Weekday() != *s.weekday {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
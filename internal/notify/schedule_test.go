@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	for _, s := range []string{"", "hourly now", "every 30s", "every soon", "daily 8am", "daily 25:00", "weekly funday 08:00", "monthly 1 08:00"} {
+		if _, err := ParseSchedule(s); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want an error", s)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Wednesday 14 October 2026, 09:30:15.
+	now := time.Date(2026, time.October, 14, 9, 30, 15, 0, time.Local)
+	tests := []struct {
+		schedule string
+		want     time.Time
+	}{
+		{"hourly", time.Date(2026, time.October, 14, 10, 30, 0, 0, time.Local)},
+		{"every 15m", time.Date(2026, time.October, 14, 9, 45, 0, 0, time.Local)},
+		{"daily 08:00", time.Date(2026, time.October, 15, 8, 0, 0, 0, time.Local)},
+		{"daily 18:45", time.Date(2026, time.October, 14, 18, 45, 0, 0, time.Local)},
+		{"weekly monday 08:00", time.Date(2026, time.October, 19, 8, 0, 0, 0, time.Local)},
+		{"Weekly Wed 09:30", time.Date(2026, time.October, 21, 9, 30, 0, 0, time.Local)},
+		{"weekly wednesday 10:00", time.Date(2026, time.October, 14, 10, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		sched, err := ParseSchedule(tt.schedule)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) error = %v", tt.schedule, err)
+		}
+		if got := sched.Next(now); !got.Equal(tt.want) {
+			t.Errorf("%q: Next() = %v, want %v", tt.schedule, got, tt.want)
+		}
+	}
+}
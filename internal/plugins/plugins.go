@@ -0,0 +1,210 @@
+// Package plugins finds and runs external plugins: executables in the
+// plugins directory that extend mindcli without changes to it.
+//
+// An exporter, named export-<format>, adds an output format to mindcli
+// export. It reads the results as JSON, in the shape of --format json, on
+// stdin and writes the export to stdout.
+//
+// A post-processor, named process-<name>, sees every document as it is
+// indexed. It reads the document as JSON on stdin and may write a JSON
+// object to stdout whose "metadata" entries are set on the document and
+// whose "tags" are added to its tags. Writing nothing leaves the document
+// as it is.
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+const (
+	exporterPrefix  = "export-"
+	processorPrefix = "process-"
+
+	// processTimeout bounds one run of a post-processor.
+	processTimeout = 30 * time.Second
+)
+
+// Plugins are the plugins found in a directory.
+type Plugins struct {
+	Exporters  map[string]*Exporter // by format
+	Processors []*Processor         // in name order
+}
+
+// Discover finds the plugins in dir, skipping those whose file names are in
+// disabled. A missing directory has no plugins.
+func Discover(dir string, disabled []string) (*Plugins, error) {
+	p := &Plugins{Exporters: make(map[string]*Exporter)}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading plugins: %w", err)
+	}
+	for _, entry := range entries {
+		if slices.Contains(disabled, entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !isExecutable(info) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), executableExt(entry.Name()))
+		switch {
+		case strings.HasPrefix(name, exporterPrefix) && len(name) > len(exporterPrefix):
+			format := strings.TrimPrefix(name, exporterPrefix)
+			p.Exporters[format] = &Exporter{Format: format, Path: path}
+		case strings.HasPrefix(name, processorPrefix) && len(name) > len(processorPrefix):
+			p.Processors = append(p.Processors, &Processor{name: strings.TrimPrefix(name, processorPrefix), path: path})
+		}
+	}
+	sort.Slice(p.Processors, func(i, j int) bool { return p.Processors[i].name < p.Processors[j].name })
+	return p, nil
+}
+
+// Formats returns the export formats plugins add, sorted.
+func (p *Plugins) Formats() []string {
+	formats := make([]string, 0, len(p.Exporters))
+	for f := range p.Exporters {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// isExecutable reports whether a directory entry can be run as a plugin.
+func isExecutable(info os.FileInfo) bool {
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return executableExt(info.Name()) != ""
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// executableExt returns name's extension when it marks a Windows
+// executable, which isn't part of the plugin's name.
+func executableExt(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	switch ext {
+	case ".exe", ".bat", ".cmd":
+		return filepath.Ext(name)
+	}
+	return ""
+}
+
+// Exporter is an export format added by a plugin.
+type Exporter struct {
+	Format string
+	Path   string
+}
+
+// Export runs the exporter with results, as JSON, on stdin and copies its
+// output to w. query is passed in MINDCLI_QUERY.
+func (e *Exporter) Export(ctx context.Context, w io.Writer, query string, results []byte) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.Path)
+	cmd.Stdin = bytes.NewReader(results)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "MINDCLI_QUERY="+query)
+	if err := cmd.Run(); err != nil {
+		return pluginError(filepath.Base(e.Path), err, stderr.String())
+	}
+	return nil
+}
+
+// Processor is a post-processor plugin. It implements index.PostProcessor.
+type Processor struct {
+	name string
+	path string
+}
+
+// Name returns the processor's name, without the process- prefix.
+func (p *Processor) Name() string {
+	return p.name
+}
+
+// processorOutput is what a post-processor may write to stdout.
+type processorOutput struct {
+	Metadata map[string]string `json:"metadata"`
+	Tags     []string          `json:"tags"`
+}
+
+// Process runs the processor on doc and applies its changes.
+func (p *Processor) Process(ctx context.Context, doc *storage.Document) error {
+	in, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, processTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return pluginError(filepath.Base(p.path), err, stderr.String())
+	}
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil
+	}
+	var out processorOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return fmt.Errorf("plugin %s: reading output: %w", filepath.Base(p.path), err)
+	}
+	apply(doc, out)
+	return nil
+}
+
+// apply sets a processor's metadata on doc and adds its tags to the
+// document's comma-separated tags.
+func apply(doc *storage.Document, out processorOutput) {
+	if len(out.Metadata) == 0 && len(out.Tags) == 0 {
+		return
+	}
+	if doc.Metadata == nil {
+		doc.Metadata = make(map[string]string)
+	}
+	for k, v := range out.Metadata {
+		doc.Metadata[k] = v
+	}
+	var tags []string
+	if existing := doc.Metadata["tags"]; existing != "" {
+		tags = strings.Split(existing, ",")
+	}
+	for _, tag := range out.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		doc.Metadata["tags"] = strings.Join(tags, ",")
+	}
+}
+
+func pluginError(name string, err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("plugin %s: %w: %s", name, err, msg)
+	}
+	return fmt.Errorf("plugin %s: %w", name, err)
+}
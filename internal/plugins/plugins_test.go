@@ -0,0 +1,77 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func writePlugin(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	dir := t.TempDir()
+	writePlugin(t, dir, "export-org", `echo "* $MINDCLI_QUERY"; cat`)
+	writePlugin(t, dir, "process-tagger", `echo '{"metadata": {"lang": "en"}, "tags": ["go", "draft", ""]}'`)
+	writePlugin(t, dir, "process-auditor", `cat > /dev/null`)
+	writePlugin(t, dir, "process-broken", `echo oops >&2; exit 2`)
+	writePlugin(t, dir, "export-", `cat`)
+	if err := os.WriteFile(filepath.Join(dir, "export-text"), []byte("not executable"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Discover(dir, []string{"process-broken"})
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if got := strings.Join(p.Formats(), ","); got != "org" {
+		t.Errorf("Formats() = %q, want org only", got)
+	}
+	if len(p.Processors) != 2 || p.Processors[0].Name() != "auditor" || p.Processors[1].Name() != "tagger" {
+		t.Fatalf("Processors = %+v, want auditor and tagger in order", p.Processors)
+	}
+
+	var out bytes.Buffer
+	if err := p.Exporters["org"].Export(context.Background(), &out, "golang", []byte(`[]`)); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if out.String() != "* golang\n[]" {
+		t.Errorf("Export() wrote %q", out.String())
+	}
+
+	doc := &storage.Document{Path: "/notes/go.md", Metadata: map[string]string{"tags": "go,notes"}}
+	for _, proc := range p.Processors {
+		if err := proc.Process(context.Background(), doc); err != nil {
+			t.Fatalf("%s: Process() error = %v", proc.Name(), err)
+		}
+	}
+	if doc.Metadata["lang"] != "en" || doc.Metadata["tags"] != "go,notes,draft" {
+		t.Errorf("Metadata = %v, want lang set and draft added to tags", doc.Metadata)
+	}
+
+	all, err := Discover(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := all.Processors[1]
+	if err := broken.Process(context.Background(), doc); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("Process() error = %v, want the plugin's stderr", err)
+	}
+
+	if p, err := Discover(filepath.Join(dir, "missing"), nil); err != nil || len(p.Exporters)+len(p.Processors) != 0 {
+		t.Errorf("Discover(missing) = %+v, %v; want no plugins", p, err)
+	}
+}
@@ -0,0 +1,172 @@
+package query
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// Maximal marginal relevance settings for AssembleContexts.
+const (
+	// mmrLambda weighs a chunk's relevance to the question against its
+	// similarity to the chunks already picked: 1 ignores redundancy.
+	mmrLambda = 0.7
+	// duplicateSimilarity is the similarity above which a chunk is taken to
+	// repeat one already picked and is dropped.
+	duplicateSimilarity = 0.95
+	// candidateFactor is how many documents per context are searched for
+	// chunks, so a document repeating a better one can be passed over.
+	candidateFactor = 2
+)
+
+// contextChunk is a chunk considered as answer context.
+type contextChunk struct {
+	rank      int // of its document among the candidates
+	chunk     *storage.Chunk
+	vector    []float32
+	relevance float64
+	length    int
+}
+
+// AssembleContexts picks the answer context for question from the chunks of
+// the best ranked docs rather than the start of each one. Chunks are chosen
+// by maximal marginal relevance over their embeddings: each pick is the
+// chunk most similar to the question and least similar to those already
+// picked, so near-identical passages aren't passed twice. Picks stop at the
+// budget's character limit and number of documents.
+//
+// It returns one context per document used, in rank order, with its chunks
+// in document order. Without vectors for the chunks of docs, when the
+// question can't be embedded, or when some of docs were embedded by another
+// model than the question, it falls back to BuildAnswerContexts.
+func (h *HybridSearcher) AssembleContexts(ctx context.Context, question string, docs []*storage.Document, budget ContextBudget) []AnswerContext {
+	budget = budget.withDefaults()
+	fallback := func() []AnswerContext {
+		return BuildAnswerContexts(docs, budget)
+	}
+	if h == nil || !h.SemanticAvailable() {
+		return fallback()
+	}
+	terms, _ := splitFilters(question)
+	embedCtx, comparable := h.routeQuery(ctx, terms)
+	queryEmb, err := h.embedder.Embed(embedCtx, terms)
+	if err != nil {
+		return fallback()
+	}
+
+	wide := budget
+	wide.MaxContexts *= candidateFactor
+	candidates := ContextDocuments(docs, wide)
+	var chunks []contextChunk
+	for rank, doc := range candidates {
+		if comparable != nil && !comparable(doc) {
+			// Its chunks were embedded by another model and can't be
+			// ranked against the question.
+			return fallback()
+		}
+		docChunks, err := h.db.GetChunksByDocument(ctx, doc.ID)
+		if err != nil {
+			continue
+		}
+		for _, c := range docChunks {
+			vec, ok := h.vectors.Lookup(c.ID)
+			if !ok || strings.TrimSpace(c.Content) == "" {
+				continue
+			}
+			// A chunk with nothing in common with the question only
+			// takes up room.
+			relevance := cosineSimilarity(queryEmb, vec)
+			if relevance <= 0 {
+				continue
+			}
+			chunks = append(chunks, contextChunk{
+				rank:      rank,
+				chunk:     c,
+				vector:    vec,
+				relevance: relevance,
+				length:    utf8.RuneCountInString(c.Content),
+			})
+		}
+	}
+
+	picked := pickDiverse(chunks, budget)
+	if len(picked) == 0 {
+		return fallback()
+	}
+	slices.SortFunc(picked, func(a, b contextChunk) int {
+		if a.rank != b.rank {
+			return a.rank - b.rank
+		}
+		return a.chunk.StartPos - b.chunk.StartPos
+	})
+	var contexts []AnswerContext
+	for i, c := range picked {
+		if i == 0 || picked[i-1].rank != c.rank {
+			contexts = append(contexts, AnswerContext{Document: candidates[c.rank]})
+		}
+		last := &contexts[len(contexts)-1]
+		last.Excerpts = append(last.Excerpts, Excerpt{
+			Text:  strings.TrimSpace(c.chunk.Content),
+			Start: c.chunk.StartPos,
+			End:   c.chunk.EndPos,
+		})
+	}
+	return contexts
+}
+
+// pickDiverse chooses chunks by maximal marginal relevance until no other
+// fits the budget.
+func pickDiverse(chunks []contextChunk, budget ContextBudget) []contextChunk {
+	var picked []contextChunk
+	used := make([]bool, len(chunks))
+	docs := make(map[int]bool)
+	remaining := budget.MaxChars
+	for {
+		best, bestScore := -1, math.Inf(-1)
+		for i, c := range chunks {
+			if used[i] || c.length > remaining || (!docs[c.rank] && len(docs) == budget.MaxContexts) {
+				continue
+			}
+			redundancy := 0.0
+			for _, p := range picked {
+				redundancy = max(redundancy, cosineSimilarity(c.vector, p.vector))
+			}
+			if redundancy >= duplicateSimilarity {
+				used[i] = true
+				continue
+			}
+			if score := mmrLambda*c.relevance - (1-mmrLambda)*redundancy; score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			return picked
+		}
+		used[best] = true
+		docs[chunks[best].rank] = true
+		remaining -= chunks[best].length
+		picked = append(picked, chunks[best])
+	}
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 when
+// either is zero or their lengths differ.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
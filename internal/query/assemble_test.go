@@ -0,0 +1,122 @@
+package query
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// tableEmbedder embeds the texts it knows as given, and anything else as
+// the zero vector.
+type tableEmbedder map[string][]float32
+
+func (e tableEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	if v, ok := e[text]; ok {
+		return v, nil
+	}
+	return make([]float32, 3), nil
+}
+
+func (e tableEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		out[i], _ = e.Embed(ctx, t)
+	}
+	return out, nil
+}
+
+func (tableEmbedder) Dimensions() int { return 3 }
+
+func TestAssembleContexts(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	vectors, err := storage.NewVectorStore(filepath.Join(dir, "vectors.graph"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = vectors.Close() })
+
+	ctx := context.Background()
+	now := time.Now()
+	chunks := map[string][]struct {
+		text string
+		vec  []float32
+	}{
+		"guide": {
+			{"Goroutines are cheap threads.", []float32{1, 0, 0}},
+			{"Unrelated recipe for bread.", []float32{0, 0, 1}},
+			{"Goroutines are cheap threads!", []float32{1, 0, 0.01}},
+			{"Channels connect goroutines.", []float32{0.9, 0.3, 0}},
+		},
+		"copy": {
+			{"Goroutines are cheap threads.", []float32{1, 0.01, 0}},
+		},
+		"other": {
+			{"Select waits on several channels.", []float32{0.6, 0.8, 0}},
+		},
+	}
+	var docs []*storage.Document
+	for _, id := range []string{"guide", "copy", "other"} {
+		doc := &storage.Document{ID: id, Source: storage.SourceMarkdown, Path: "/" + id + ".md", Title: id,
+			Content: "whole " + id, ContentHash: id, IndexedAt: now, ModifiedAt: now}
+		if err := db.UpsertDocument(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+		pos := 0
+		for i, c := range chunks[id] {
+			chunk := &storage.Chunk{ID: id + ":" + string(rune('0'+i)), DocumentID: id, Content: c.text, StartPos: pos, EndPos: pos + len(c.text)}
+			pos += len(c.text)
+			if err := db.InsertChunk(ctx, chunk); err != nil {
+				t.Fatal(err)
+			}
+			if err := vectors.Add(chunk.ID, c.vec); err != nil {
+				t.Fatal(err)
+			}
+		}
+		docs = append(docs, doc)
+	}
+
+	embedder := tableEmbedder{"how do goroutines work?": {1, 0, 0}}
+	h := NewHybridSearcher(nil, vectors, embedder, db, 0.5)
+	assembled := h.AssembleContexts(ctx, "how do goroutines work?", docs, ContextBudget{MaxContexts: 2, MaxChars: 1000})
+	contexts, sources := ContextTexts(assembled), ContextSources(assembled)
+
+	if len(sources) != 2 || sources[0].ID != "guide" || sources[1].ID != "other" {
+		t.Fatalf("sources = %v, want guide and other, skipping the copy", sources)
+	}
+	if len(contexts) != 2 {
+		t.Fatalf("got %d contexts, want one per source", len(contexts))
+	}
+	if want := "Goroutines are cheap threads.\n\nChannels connect goroutines."; contexts[0] != want {
+		t.Errorf("guide context = %q, want %q", contexts[0], want)
+	}
+	if strings.Contains(contexts[0], "bread") || strings.Contains(contexts[0], "threads!") {
+		t.Error("irrelevant and near-identical chunks should be left out")
+	}
+	if e := assembled[0].Excerpts[1]; e.Start != 85 || e.End != 113 {
+		t.Errorf("second guide excerpt at %d-%d, want the chunk's offsets 85-113", e.Start, e.End)
+	}
+
+	// The character budget limits the picks to what fits.
+	assembled = h.AssembleContexts(ctx, "how do goroutines work?", docs, ContextBudget{MaxContexts: 2, MaxChars: 40})
+	contexts, sources = ContextTexts(assembled), ContextSources(assembled)
+	if len(sources) != 1 || contexts[0] != "Goroutines are cheap threads." {
+		t.Errorf("with 40 characters, got %q from %d sources, want the best chunk alone", contexts, len(sources))
+	}
+
+	// Without vectors, each document's opening is used as before.
+	var none *HybridSearcher
+	assembled = none.AssembleContexts(ctx, "how do goroutines work?", docs, ContextBudget{MaxContexts: 2})
+	contexts, sources = ContextTexts(assembled), ContextSources(assembled)
+	if len(sources) != 2 || contexts[0] != "whole guide" {
+		t.Errorf("fallback = %q, want the start of the first two documents", contexts)
+	}
+}
@@ -0,0 +1,246 @@
+package query
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/pkg/chunker"
+)
+
+// Default context budget, used for unset ContextBudget fields.
+const (
+	DefaultMaxContexts     = 5
+	DefaultMaxContextChars = 5000
+)
+
+// ContextBudget limits how much of the retrieved documents is passed to the
+// LLM as answer context.
+type ContextBudget struct {
+	MaxContexts int // number of top documents used
+	MaxChars    int // total characters across all excerpts
+
+	// ExcludedSources are never used as context, such as browser history
+	// and clipboard clips, which add noise to answers.
+	ExcludedSources []storage.Source
+}
+
+// Allowing returns b with sources no longer excluded, for a question that
+// asks for them by name.
+func (b ContextBudget) Allowing(sources []storage.Source) ContextBudget {
+	b.ExcludedSources = slices.DeleteFunc(slices.Clone(b.ExcludedSources), func(s storage.Source) bool {
+		return slices.Contains(sources, s)
+	})
+	return b
+}
+
+func (b ContextBudget) withDefaults() ContextBudget {
+	if b.MaxContexts < 1 {
+		b.MaxContexts = DefaultMaxContexts
+	}
+	if b.MaxChars < 1 {
+		b.MaxChars = DefaultMaxContextChars
+	}
+	return b
+}
+
+// ContextDocuments returns the documents BuildContexts uses, i.e. the
+// sources an answer is based on. Only the best ranked of a note and its
+// heading sections is used, so the same text isn't passed twice. Documents
+// from excluded sources are skipped.
+func ContextDocuments(docs []*storage.Document, budget ContextBudget) []*storage.Document {
+	budget = budget.withDefaults()
+	seen := make(map[string]bool, len(docs))
+	out := make([]*storage.Document, 0, min(len(docs), budget.MaxContexts))
+	for _, doc := range docs {
+		if len(out) == budget.MaxContexts {
+			break
+		}
+		if slices.Contains(budget.ExcludedSources, doc.Source) {
+			continue
+		}
+		note := doc.ID
+		if doc.IsSection() {
+			note = doc.Metadata["section_of"]
+		}
+		if note == "" || !seen[note] {
+			seen[note] = true
+			out = append(out, doc)
+		}
+	}
+	return out
+}
+
+// TopRelevance returns the best vector score among results: how close the
+// closest document came to the question, from 0 to 1. It reports false when
+// no result has a vector score, as without embeddings or for an exact
+// search, and relevance can't be told.
+func TopRelevance(results storage.SearchResults) (float64, bool) {
+	best := 0.0
+	for _, r := range results {
+		best = max(best, r.VectorScore)
+	}
+	return best, best > 0
+}
+
+// Excerpt is a passage of a document passed to the LLM, with where it is in
+// the document's content as byte offsets. Start is -1 when the passage can't
+// be found there.
+type Excerpt struct {
+	Text       string
+	Start, End int
+}
+
+// AnswerContext is what of one document is passed to the LLM as context: its
+// excerpts, in document order.
+type AnswerContext struct {
+	Document *storage.Document
+	Excerpts []Excerpt
+}
+
+// NewAnswerContext returns the context of doc made of texts, locating each in
+// the document's content.
+func NewAnswerContext(doc *storage.Document, texts ...string) AnswerContext {
+	c := AnswerContext{Document: doc}
+	for _, text := range texts {
+		e := Excerpt{Text: text, Start: -1, End: -1}
+		if i := strings.Index(doc.Content, text); i >= 0 {
+			e.Start, e.End = i, i+len(text)
+		}
+		c.Excerpts = append(c.Excerpts, e)
+	}
+	return c
+}
+
+// Text returns the context as passed to the LLM: its excerpts, separated by
+// blank lines.
+func (c AnswerContext) Text() string {
+	texts := make([]string, len(c.Excerpts))
+	for i, e := range c.Excerpts {
+		texts[i] = e.Text
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// ContextTexts returns the text of each context, as passed to the LLM.
+func ContextTexts(contexts []AnswerContext) []string {
+	texts := make([]string, len(contexts))
+	for i, c := range contexts {
+		texts[i] = c.Text()
+	}
+	return texts
+}
+
+// ContextSources returns the document of each context, the sources an
+// answer is based on.
+func ContextSources(contexts []AnswerContext) []*storage.Document {
+	docs := make([]*storage.Document, len(contexts))
+	for i, c := range contexts {
+		docs[i] = c.Document
+	}
+	return docs
+}
+
+// BuildContexts returns one excerpt per document in ContextDocuments. The
+// character budget is shared evenly, and room left by short documents goes
+// to the ones after them. Long documents are cut at a paragraph or sentence
+// boundary rather than mid-word.
+func BuildContexts(docs []*storage.Document, budget ContextBudget) []string {
+	return ContextTexts(BuildAnswerContexts(docs, budget))
+}
+
+// BuildAnswerContexts is BuildContexts with where each excerpt comes from.
+func BuildAnswerContexts(docs []*storage.Document, budget ContextBudget) []AnswerContext {
+	budget = budget.withDefaults()
+	docs = ContextDocuments(docs, budget)
+
+	contexts := make([]AnswerContext, 0, len(docs))
+	remaining := budget.MaxChars
+	for i, doc := range docs {
+		excerpt := excerpt(doc.Content, remaining/(len(docs)-i))
+		remaining -= utf8.RuneCountInString(excerpt)
+		contexts = append(contexts, NewAnswerContext(doc, excerpt))
+	}
+	return contexts
+}
+
+// DocumentContexts returns the excerpts of a single document to answer
+// question from, for focused Q&A on one note or paper. A document that fits
+// the character budget is used whole. A longer one is split into chunks, and
+// those sharing the most words with the question are used, in the order they
+// appear in the document.
+func DocumentContexts(doc *storage.Document, question string, budget ContextBudget) []string {
+	budget = budget.withDefaults()
+	content := strings.TrimSpace(doc.Content)
+	if content == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(content) <= budget.MaxChars {
+		return []string{content}
+	}
+
+	size := max(budget.MaxChars/budget.MaxContexts, chunker.DefaultChunkSize)
+	chunks := chunker.Split(content, chunker.Options{ChunkSize: size})
+	questionTokens := tokenize(question)
+	scores := make([]float64, len(chunks))
+	for i, c := range chunks {
+		scores[i] = tokenOverlap(questionTokens, tokenize(c.Content))
+	}
+	ranked := make([]int, len(chunks))
+	for i := range ranked {
+		ranked[i] = i
+	}
+	// Stable, so chunks that match equally well keep document order.
+	slices.SortStableFunc(ranked, func(a, b int) int {
+		switch {
+		case scores[a] > scores[b]:
+			return -1
+		case scores[a] < scores[b]:
+			return 1
+		}
+		return 0
+	})
+
+	var picked []int
+	remaining := budget.MaxChars
+	for _, i := range ranked {
+		if len(picked) == budget.MaxContexts {
+			break
+		}
+		if n := utf8.RuneCountInString(chunks[i].Content); n <= remaining {
+			picked = append(picked, i)
+			remaining -= n
+		}
+	}
+	slices.Sort(picked)
+
+	contexts := make([]string, len(picked))
+	for j, i := range picked {
+		contexts[j] = chunks[i].Content
+	}
+	return contexts
+}
+
+// excerpt returns the leading part of content that fits in limit characters.
+func excerpt(content string, limit int) string {
+	content = strings.TrimSpace(content)
+	if utf8.RuneCountInString(content) <= limit {
+		return content
+	}
+	if limit <= 0 {
+		return ""
+	}
+	chunks := chunker.Split(content, chunker.Options{ChunkSize: limit})
+	if len(chunks) > 0 && utf8.RuneCountInString(chunks[0].Content) <= limit {
+		return chunks[0].Content
+	}
+	// A single sentence longer than the limit: cut at the last space, or
+	// mid-word when there is none.
+	runes := []rune(content)[:limit]
+	cut := string(runes)
+	if i := strings.LastIndexAny(cut, " \t\n"); i > 0 && utf8.RuneCountInString(cut[:i]) > limit/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut)
+}
@@ -0,0 +1,155 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func docsWithContent(contents ...string) []*storage.Document {
+	docs := make([]*storage.Document, len(contents))
+	for i, c := range contents {
+		docs[i] = &storage.Document{ID: string(rune('a' + i)), Content: c}
+	}
+	return docs
+}
+
+func TestBuildContextsLimitsDocuments(t *testing.T) {
+	docs := docsWithContent("one", "two", "three", "four")
+	contexts := BuildContexts(docs, ContextBudget{MaxContexts: 2})
+	if len(contexts) != 2 || contexts[0] != "one" || contexts[1] != "two" {
+		t.Errorf("contexts = %q, want the first two documents", contexts)
+	}
+	if got := ContextDocuments(docs, ContextBudget{MaxContexts: 2}); len(got) != 2 || got[1] != docs[1] {
+		t.Errorf("ContextDocuments returned %d documents, want the first 2", len(got))
+	}
+	if got := BuildContexts(docs, ContextBudget{}); len(got) != 4 {
+		t.Errorf("default budget kept %d contexts, want 4", len(got))
+	}
+}
+
+func TestBuildContextsCutsAtSentences(t *testing.T) {
+	content := "Go has goroutines. Channels connect them. The scheduler multiplexes goroutines onto threads."
+	contexts := BuildContexts(docsWithContent(content), ContextBudget{MaxChars: 50})
+	if contexts[0] != "Go has goroutines. Channels connect them." {
+		t.Errorf("excerpt = %q, want it cut after a whole sentence", contexts[0])
+	}
+}
+
+func TestBuildContextsSharesBudget(t *testing.T) {
+	long := strings.Repeat("This sentence is filler. ", 40)
+	contexts := BuildContexts(docsWithContent("Short note.", long), ContextBudget{MaxChars: 400})
+
+	if contexts[0] != "Short note." {
+		t.Errorf("short document = %q, want it whole", contexts[0])
+	}
+	// The short document leaves most of its half for the long one.
+	if n := len(contexts[1]); n <= 200 || n > 400-len("Short note.") {
+		t.Errorf("long document excerpt has %d chars, want it to use the leftover budget", n)
+	}
+	if !strings.HasSuffix(contexts[1], ".") {
+		t.Errorf("long document excerpt %q should end at a sentence boundary", contexts[1])
+	}
+}
+
+func TestExcerptCutsLongSentenceAtWord(t *testing.T) {
+	got := excerpt("averyveryverylongword and then some more words without any full stop", 30)
+	if len(got) > 30 || strings.HasSuffix(got, "wi") {
+		t.Errorf("excerpt = %q, want at most 30 chars ending at a word", got)
+	}
+	if got := excerpt("héllo wörld", 2); got != "hé" {
+		t.Errorf("excerpt = %q, want two characters", got)
+	}
+	if got := excerpt("日本語の文章です。続きの文章です。", 10); got != "日本語の文章です。" {
+		t.Errorf("excerpt = %q, want the first sentence", got)
+	}
+}
+
+func TestContextDocumentsSkipsExcludedSources(t *testing.T) {
+	note := &storage.Document{ID: "note", Source: storage.SourceMarkdown, Content: "note"}
+	visit := &storage.Document{ID: "visit", Source: storage.SourceBrowser, Content: "visited page"}
+	clip := &storage.Document{ID: "clip", Source: storage.SourceClipboard, Content: "copied text"}
+	docs := []*storage.Document{visit, clip, note}
+	budget := ContextBudget{ExcludedSources: []storage.Source{storage.SourceBrowser, storage.SourceClipboard}}
+
+	if got := ContextDocuments(docs, budget); len(got) != 1 || got[0] != note {
+		t.Errorf("ContextDocuments kept %d documents, want only the note", len(got))
+	}
+	allowed := budget.Allowing([]storage.Source{storage.SourceBrowser})
+	if got := ContextDocuments(docs, allowed); len(got) != 2 || got[0] != visit || got[1] != note {
+		t.Errorf("ContextDocuments allowing browser kept %d documents, want the visit and the note", len(got))
+	}
+	if len(budget.ExcludedSources) != 2 {
+		t.Error("Allowing changed the budget it was called on")
+	}
+}
+
+func TestContextDocumentsSkipsSectionsOfUsedNotes(t *testing.T) {
+	note := &storage.Document{ID: "note", Content: "whole note"}
+	section := &storage.Document{ID: "note#goals", Content: "goals", Metadata: map[string]string{"section_of": "note"}}
+	otherSection := &storage.Document{ID: "other#risks", Content: "risks", Metadata: map[string]string{"section_of": "other"}}
+	other := &storage.Document{ID: "other", Content: "other note"}
+
+	got := ContextDocuments([]*storage.Document{section, note, otherSection, other}, ContextBudget{MaxContexts: 3})
+	if len(got) != 2 || got[0] != section || got[1] != otherSection {
+		t.Errorf("ContextDocuments kept %d documents, want only the best ranked entry of each note", len(got))
+	}
+}
+
+func TestDocumentContexts(t *testing.T) {
+	short := &storage.Document{Content: "  Go has goroutines.  "}
+	if got := DocumentContexts(short, "goroutines", ContextBudget{}); len(got) != 1 || got[0] != "Go has goroutines." {
+		t.Errorf("DocumentContexts() on a short document = %q, want it whole", got)
+	}
+	if got := DocumentContexts(&storage.Document{}, "anything", ContextBudget{}); got != nil {
+		t.Errorf("DocumentContexts() on an empty document = %q, want none", got)
+	}
+
+	filler := strings.Repeat("The weather was mild and the garden needed water. ", 12)
+	paragraphs := []string{
+		"Intro. " + filler,
+		"Kubernetes deployments roll out new pods gradually. " + filler,
+		"Lunch. " + filler,
+		"Rollbacks restore the previous deployment when pods fail. " + filler,
+		"Outro. " + filler,
+	}
+	doc := &storage.Document{Content: strings.Join(paragraphs, "\n\n")}
+	got := DocumentContexts(doc, "how do kubernetes deployments rollbacks work?", ContextBudget{MaxContexts: 2, MaxChars: 1400})
+	if len(got) != 2 {
+		t.Fatalf("DocumentContexts() returned %d contexts, want 2", len(got))
+	}
+	if !strings.HasPrefix(got[0], "Kubernetes deployments") || !strings.HasPrefix(got[1], "Rollbacks restore") {
+		t.Errorf("DocumentContexts() = %q..., want the chunks about deployments, in document order", []string{got[0][:20], got[1][:20]})
+	}
+}
+
+func TestNewAnswerContext(t *testing.T) {
+	doc := &storage.Document{Content: "# Title\n\nGo has goroutines. Channels connect them."}
+	c := NewAnswerContext(doc, "Go has goroutines.", "Channels connect them.", "not in the document")
+
+	want := []Excerpt{{"Go has goroutines.", 9, 27}, {"Channels connect them.", 28, 50}, {"not in the document", -1, -1}}
+	for i, e := range c.Excerpts {
+		if e != want[i] {
+			t.Errorf("excerpt %d = %+v, want %+v", i, e, want[i])
+		}
+	}
+	if got := c.Text(); got != "Go has goroutines.\n\nChannels connect them.\n\nnot in the document" {
+		t.Errorf("Text() = %q", got)
+	}
+
+	built := BuildAnswerContexts([]*storage.Document{doc}, ContextBudget{MaxChars: 20})
+	if len(built) != 1 || built[0].Excerpts[0].Start != 0 || built[0].Excerpts[0].Text != "# Title" {
+		t.Errorf("BuildAnswerContexts = %+v, want the opening of the document at offset 0", built)
+	}
+}
+
+func TestTopRelevance(t *testing.T) {
+	results := storage.SearchResults{{Score: 0.03, VectorScore: 0.62}, {Score: 0.02, VectorScore: 0.81}, {Score: 0.01}}
+	if best, ok := TopRelevance(results); !ok || best != 0.81 {
+		t.Errorf("TopRelevance = %v, %v; want 0.81, true", best, ok)
+	}
+	if _, ok := TopRelevance(storage.SearchResults{{Score: 4.2, BM25Score: 4.2}}); ok {
+		t.Error("TopRelevance of keyword-only results should report that relevance can't be told")
+	}
+}
@@ -0,0 +1,160 @@
+package query
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// Qrel is a labeled query: the search text and the paths of the documents
+// that should be found for it.
+type Qrel struct {
+	Query    string
+	Relevant []string
+}
+
+// ParseQrels reads labeled queries, one "query<TAB>path" pair per line. A
+// query with several relevant documents repeats the query on several lines.
+// Paths may be absolute or a trailing part of the path, like "go/channels.md".
+// Blank lines and lines starting with # are ignored.
+func ParseQrels(r io.Reader) ([]Qrel, error) {
+	var qrels []Qrel
+	index := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		q, path, ok := strings.Cut(line, "\t")
+		q, path = strings.TrimSpace(q), strings.TrimSpace(path)
+		if !ok || q == "" || path == "" {
+			return nil, fmt.Errorf("qrels line %d: want \"query<TAB>path\"", n)
+		}
+		i, seen := index[q]
+		if !seen {
+			i = len(qrels)
+			index[q] = i
+			qrels = append(qrels, Qrel{Query: q})
+		}
+		qrels[i].Relevant = append(qrels[i].Relevant, path)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading qrels: %w", err)
+	}
+	if len(qrels) == 0 {
+		return nil, fmt.Errorf("qrels contain no queries")
+	}
+	return qrels, nil
+}
+
+// WeightScore is retrieval quality at one hybrid weight, averaged over the
+// labeled queries.
+type WeightScore struct {
+	Weight float64
+	MRR    float64 // mean reciprocal rank of the first relevant result
+	Recall float64 // share of relevant documents in the top k
+	NDCG   float64 // normalized discounted cumulative gain at k
+}
+
+// DefaultEvalWeights are the hybrid weights swept by EvaluateWeights.
+var DefaultEvalWeights = []float64{0, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1}
+
+// EvaluateWeights runs every labeled query at each weight and scores the top
+// k results against the relevant documents.
+func (h *HybridSearcher) EvaluateWeights(ctx context.Context, qrels []Qrel, weights []float64, k int) ([]WeightScore, error) {
+	scores := make([]WeightScore, 0, len(weights))
+	for _, w := range weights {
+		score := WeightScore{Weight: w}
+		for _, q := range qrels {
+			results, err := h.SearchWithWeight(ctx, q.Query, k, w)
+			if err != nil {
+				return nil, fmt.Errorf("searching %q: %w", q.Query, err)
+			}
+			paths := make([]string, 0, len(results))
+			for _, r := range results {
+				paths = append(paths, r.Document.Path)
+			}
+			mrr, recall, ndcg := rankMetrics(paths, q.Relevant, k)
+			score.MRR += mrr
+			score.Recall += recall
+			score.NDCG += ndcg
+		}
+		n := float64(len(qrels))
+		score.MRR /= n
+		score.Recall /= n
+		score.NDCG /= n
+		scores = append(scores, score)
+	}
+	return scores, nil
+}
+
+// BestWeight returns the score with the highest nDCG, breaking ties by MRR
+// and then by closeness to the current weight, so an equally good setting
+// doesn't suggest a change.
+func BestWeight(scores []WeightScore, current float64) WeightScore {
+	var best WeightScore
+	for i, s := range scores {
+		if i == 0 || better(s, best, current) {
+			best = s
+		}
+	}
+	return best
+}
+
+func better(a, b WeightScore, current float64) bool {
+	const eps = 1e-9
+	switch {
+	case math.Abs(a.NDCG-b.NDCG) > eps:
+		return a.NDCG > b.NDCG
+	case math.Abs(a.MRR-b.MRR) > eps:
+		return a.MRR > b.MRR
+	}
+	return math.Abs(a.Weight-current) < math.Abs(b.Weight-current)
+}
+
+// rankMetrics scores ranked result paths against the relevant paths, with
+// binary relevance.
+func rankMetrics(paths, relevant []string, k int) (mrr, recall, ndcg float64) {
+	if len(paths) > k {
+		paths = paths[:k]
+	}
+	found := make(map[int]bool)
+	var dcg float64
+	for rank, p := range paths {
+		for i, rel := range relevant {
+			if found[i] || !matchesPath(p, rel) {
+				continue
+			}
+			found[i] = true
+			if mrr == 0 {
+				mrr = 1 / float64(rank+1)
+			}
+			dcg += 1 / math.Log2(float64(rank+2))
+			break
+		}
+	}
+
+	var ideal float64
+	for rank := range min(len(relevant), k) {
+		ideal += 1 / math.Log2(float64(rank+2))
+	}
+	if len(relevant) > 0 {
+		recall = float64(len(found)) / float64(len(relevant))
+		ndcg = dcg / ideal
+	}
+	return mrr, recall, ndcg
+}
+
+// matchesPath reports whether a document path is the labeled path, or ends
+// with it at a directory boundary.
+func matchesPath(docPath, labeled string) bool {
+	if filepath.IsAbs(labeled) {
+		return docPath == filepath.Clean(labeled)
+	}
+	return strings.HasSuffix(filepath.ToSlash(docPath), "/"+filepath.ToSlash(filepath.Clean(labeled)))
+}
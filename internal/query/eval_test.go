@@ -0,0 +1,114 @@
+package query
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQrels(t *testing.T) {
+	in := "# query<TAB>path\n" +
+		"go concurrency\tnotes/go.md\n" +
+		"\n" +
+		"go concurrency\t/abs/channels.md\n" +
+		"rust\trust.md\n"
+	qrels, err := ParseQrels(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ParseQrels() error = %v", err)
+	}
+	if len(qrels) != 2 {
+		t.Fatalf("got %d queries, want 2", len(qrels))
+	}
+	if qrels[0].Query != "go concurrency" || len(qrels[0].Relevant) != 2 {
+		t.Errorf("first query = %+v, want two relevant paths", qrels[0])
+	}
+
+	if _, err := ParseQrels(strings.NewReader("no tab here\n")); err == nil {
+		t.Error("expected an error for a line without a tab")
+	}
+	if _, err := ParseQrels(strings.NewReader("# only comments\n")); err == nil {
+		t.Error("expected an error for qrels without queries")
+	}
+}
+
+func TestRankMetrics(t *testing.T) {
+	paths := []string{"/n/a.md", "/n/go.md", "/n/b.md", "/n/channels.md"}
+	mrr, recall, ndcg := rankMetrics(paths, []string{"go.md", "/n/channels.md", "missing.md"}, 10)
+
+	if mrr != 0.5 {
+		t.Errorf("MRR = %v, want 0.5 (first hit at rank 2)", mrr)
+	}
+	if math.Abs(recall-2.0/3) > 1e-9 {
+		t.Errorf("recall = %v, want 2/3", recall)
+	}
+	wantNDCG := (1/math.Log2(3) + 1/math.Log2(5)) / (1 + 1/math.Log2(3) + 1/math.Log2(4))
+	if math.Abs(ndcg-wantNDCG) > 1e-9 {
+		t.Errorf("nDCG = %v, want %v", ndcg, wantNDCG)
+	}
+
+	// Hits past k don't count.
+	if mrr, _, _ := rankMetrics(paths, []string{"go.md"}, 1); mrr != 0 {
+		t.Errorf("MRR at k=1 = %v, want 0", mrr)
+	}
+}
+
+func TestMatchesPath(t *testing.T) {
+	tests := []struct {
+		doc, labeled string
+		want         bool
+	}{
+		{"/home/u/notes/go.md", "go.md", true},
+		{"/home/u/notes/go.md", "notes/go.md", true},
+		{"/home/u/notes/go.md", "/home/u/notes/go.md", true},
+		{"/home/u/notes/ergo.md", "go.md", false},
+		{"/home/u/notes/go.md", "/go.md", false},
+	}
+	for _, tt := range tests {
+		if got := matchesPath(tt.doc, tt.labeled); got != tt.want {
+			t.Errorf("matchesPath(%q, %q) = %v, want %v", tt.doc, tt.labeled, got, tt.want)
+		}
+	}
+}
+
+func TestBestWeight(t *testing.T) {
+	scores := []WeightScore{
+		{Weight: 0, NDCG: 0.5, MRR: 0.5},
+		{Weight: 0.3, NDCG: 0.8, MRR: 0.6},
+		{Weight: 0.5, NDCG: 0.8, MRR: 0.6},
+		{Weight: 1, NDCG: 0.8, MRR: 0.4},
+	}
+	if got := BestWeight(scores, 0.5); got.Weight != 0.5 {
+		t.Errorf("BestWeight() = %v, want the current 0.5 among equal scores", got.Weight)
+	}
+	if got := BestWeight(scores, 0); got.Weight != 0.3 {
+		t.Errorf("BestWeight() = %v, want 0.3, the closest of the best", got.Weight)
+	}
+}
+
+func TestEvaluateWeights(t *testing.T) {
+	db, bleve, vectors := newHybridTestStores(t)
+	h := NewHybridSearcher(bleve, vectors, keywordEmbedder{}, db, 0.5)
+	ctx := context.Background()
+	for i := 0; i < 30; i++ {
+		if r, _ := h.Search(ctx, "rust", 10); len(r) > 0 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	qrels := []Qrel{{Query: "ownership", Relevant: []string{"b.md"}}}
+	scores, err := h.EvaluateWeights(ctx, qrels, []float64{0, 1}, 10)
+	if err != nil {
+		t.Fatalf("EvaluateWeights() error = %v", err)
+	}
+	if len(scores) != 2 {
+		t.Fatalf("got %d scores, want 2", len(scores))
+	}
+	for _, s := range scores {
+		if s.MRR != 1 || s.Recall != 1 {
+			t.Errorf("weight %v: MRR %v, recall %v, want the Rust note ranked first", s.Weight, s.MRR, s.Recall)
+		}
+	}
+}
@@ -13,7 +13,7 @@ import (
 
 // HybridSearcher combines BM25 full-text search with vector similarity search.
 type HybridSearcher struct {
-	bleve    *search.BleveIndex
+	fulltext search.Backend
 	vectors  *storage.VectorStore
 	embedder embeddings.Embedder
 	db       *storage.DB
@@ -25,14 +25,14 @@ type HybridSearcher struct {
 // NewHybridSearcher creates a hybrid searcher. The vector store and embedder
 // may be nil, in which case only BM25 search is used.
 func NewHybridSearcher(
-	bleve *search.BleveIndex,
+	fulltext search.Backend,
 	vectors *storage.VectorStore,
 	embedder embeddings.Embedder,
 	db *storage.DB,
 	hybridWeight float64,
 ) *HybridSearcher {
 	return &HybridSearcher{
-		bleve:        bleve,
+		fulltext:     fulltext,
 		vectors:      vectors,
 		embedder:     embedder,
 		db:           db,
@@ -42,10 +42,20 @@ func NewHybridSearcher(
 
 // Search performs a hybrid search combining BM25 and vector results.
 func (h *HybridSearcher) Search(ctx context.Context, queryStr string, limit int) (storage.SearchResults, error) {
+	return h.SearchWithWeight(ctx, queryStr, limit, h.HybridWeight)
+}
+
+// SearchWithWeight is Search with a per-query hybrid weight in place of
+// HybridWeight. A weight of 0 skips vector search entirely, and a weight of
+// 1 skips BM25 (see SemanticSearch).
+func (h *HybridSearcher) SearchWithWeight(ctx context.Context, queryStr string, limit int, weight float64) (storage.SearchResults, error) {
 	// If no vector search available, fall back to BM25 only.
-	if h.vectors == nil || h.embedder == nil || h.vectors.Len() == 0 {
+	if weight <= 0 || h.vectors == nil || h.embedder == nil || h.vectors.Len() == 0 {
 		return h.bm25Only(ctx, queryStr, limit)
 	}
+	if weight >= 1 {
+		return h.SemanticSearch(ctx, queryStr, limit)
+	}
 
 	// Run BM25 and vector search in parallel.
 	type bm25Result struct {
@@ -59,20 +69,25 @@ func (h *HybridSearcher) Search(ctx context.Context, queryStr string, limit int)
 
 	bm25Ch := make(chan bm25Result, 1)
 	vecCh := make(chan vecResult, 1)
+	terms, filter := splitFilters(queryStr)
 
 	go func() {
-		results, err := h.bleve.Search(ctx, queryStr, limit*2)
+		results, err := h.fulltext.Search(ctx, queryStr, limit*2)
 		bm25Ch <- bm25Result{results, err}
 	}()
 
 	go func() {
-		// Generate embedding for the query.
-		queryEmb, err := h.embedder.Embed(ctx, queryStr)
+		// Generate embedding for the query, without its filters.
+		embedCtx, comparable := h.routeQuery(ctx, terms)
+		queryEmb, err := h.embedder.Embed(embedCtx, terms)
 		if err != nil {
 			vecCh <- vecResult{nil, err}
 			return
 		}
 		results := h.vectors.Search(queryEmb, limit*2)
+		if comparable != nil {
+			results = h.comparableHits(ctx, results, comparable)
+		}
 		vecCh <- vecResult{results, nil}
 	}()
 
@@ -88,12 +103,193 @@ func (h *HybridSearcher) Search(ctx context.Context, queryStr string, limit int)
 	}
 
 	// Fuse results using Reciprocal Rank Fusion.
-	fused := h.fuseResults(bm25Res.results, vecRes.results)
+	fused := fuseResults(bm25Res.results, vecRes.results, weight)
+
+	// Fetch full documents and build results. BM25 applied the source and
+	// path filters itself, but vector hits still need them.
+	return h.buildResults(ctx, fused, limit, filter)
+}
+
+// SemanticAvailable reports whether vector search is possible: there is an
+// embedder and a vector index with something in it.
+func (h *HybridSearcher) SemanticAvailable() bool {
+	return h.vectors != nil && h.embedder != nil && h.vectors.Len() > 0
+}
+
+// SemanticSearch ranks documents purely by vector similarity, without BM25.
+// Each document appears once, scored by its best-matching chunk, whose text
+// is returned as the result's highlight. "source:" and "path:" terms in
+// queryStr filter the results instead of being embedded; "source:markdown,pdf"
+// keeps either source. Without vector search it falls back to BM25.
+func (h *HybridSearcher) SemanticSearch(ctx context.Context, queryStr string, limit int) (storage.SearchResults, error) {
+	if !h.SemanticAvailable() {
+		return h.bm25Only(ctx, queryStr, limit)
+	}
+
+	terms, filter := splitFilters(queryStr)
+	embedCtx, comparable := h.routeQuery(ctx, terms)
+	queryEmb, err := h.embedder.Embed(embedCtx, terms)
+	if err != nil {
+		return h.bm25Only(ctx, queryStr, limit)
+	}
+	filter.comparable = comparable
+	return h.nearestDocuments(ctx, [][]float32{queryEmb}, limit, filter)
+}
+
+// routeQuery returns the context to embed text with and, when documents in
+// some languages are embedded by other models (see
+// embeddings.LanguageRouter), a func reporting whether a document was
+// embedded by the same model as text. Vectors from different models can't
+// be compared, so vector hits on other documents must be dropped.
+func (h *HybridSearcher) routeQuery(ctx context.Context, text string) (context.Context, func(*storage.Document) bool) {
+	router, ok := h.embedder.(*embeddings.LanguageRouter)
+	if !ok {
+		return ctx, nil
+	}
+	language := router.Language(ctx, text)
+	return embeddings.WithLanguage(ctx, language), func(doc *storage.Document) bool {
+		return router.SameModel(language, doc.Metadata["lang"])
+	}
+}
+
+// comparableHits returns the hits on chunks of documents that comparable
+// keeps.
+func (h *HybridSearcher) comparableHits(ctx context.Context, hits []storage.VectorResult, comparable func(*storage.Document) bool) []storage.VectorResult {
+	keep := make(map[string]bool)
+	kept := hits[:0]
+	for _, hit := range hits {
+		docID := extractDocID(hit.Key)
+		ok, seen := keep[docID]
+		if !seen {
+			doc, err := h.db.GetDocument(ctx, docID)
+			ok = err == nil && doc != nil && comparable(doc)
+			keep[docID] = ok
+		}
+		if ok {
+			kept = append(kept, hit)
+		}
+	}
+	return kept
+}
+
+// docFilter restricts results to some sources, directories and ranges, as
+// the full-text backends do for "source:", "path:" and range terms such as
+// "after:". The zero value keeps every document.
+type docFilter struct {
+	sources []storage.Source
+	dirs    []string
+	ranges  []search.RangeFilter
+	// comparable, if set, keeps only documents whose vectors can be
+	// compared with the query's (see routeQuery).
+	comparable func(*storage.Document) bool
+}
+
+func (f docFilter) matches(doc *storage.Document) bool {
+	if !storage.MatchesSources(f.sources, doc.Source) {
+		return false
+	}
+	if f.comparable != nil && !f.comparable(doc) {
+		return false
+	}
+	for _, r := range f.ranges {
+		if !r.Matches(doc) {
+			return false
+		}
+	}
+	if len(f.dirs) == 0 {
+		return true
+	}
+	for _, dir := range f.dirs {
+		if storage.InDirectory(doc.Path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitFilters separates the "source:", "path:", "folder:" and range
+// filters in queryStr from the search terms, which lose their boolean
+// operators and excluded terms for embedding. A source filter may list
+// several sources, comma-separated, and a quoted path may contain spaces.
+func splitFilters(queryStr string) (terms string, filter docFilter) {
+	queryStr = pathFilterRe.ReplaceAllStringFunc(queryStr, func(m string) string {
+		if dir := pathFilterValue(m); dir != "" {
+			filter.dirs = append(filter.dirs, dir)
+		}
+		return " "
+	})
+	var rest []string
+	for _, f := range strings.Fields(queryStr) {
+		if r, ok := search.ParseRangeFilter(f); ok {
+			filter.ranges = append(filter.ranges, r)
+			continue
+		}
+		list, ok := strings.CutPrefix(f, "source:")
+		if !ok {
+			rest = append(rest, f)
+			continue
+		}
+		for _, s := range strings.Split(list, ",") {
+			if s != "" {
+				filter.sources = append(filter.sources, storage.Source(s))
+			}
+		}
+	}
+	return search.MatchTerms(strings.Join(rest, " ")), filter
+}
+
+// nearestDocuments ranks documents by their best chunk similarity to any of
+// the query embeddings, keeping only those that pass filter.
+func (h *HybridSearcher) nearestDocuments(ctx context.Context, queryEmbs [][]float32, limit int, filter docFilter) (storage.SearchResults, error) {
+	// A document can own several of the nearest chunks, so oversample to
+	// still fill limit distinct documents.
+	best := make(map[string]storage.VectorResult)
+	for _, emb := range queryEmbs {
+		for _, hit := range h.vectors.Search(emb, limit*semanticOversample) {
+			docID := extractDocID(hit.Key)
+			if prev, ok := best[docID]; !ok || hit.Score > prev.Score {
+				best[docID] = hit
+			}
+		}
+	}
+	hits := make([]storage.VectorResult, 0, len(best))
+	for _, hit := range best {
+		hits = append(hits, hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Key < hits[j].Key
+	})
 
-	// Fetch full documents and build results.
-	return h.buildResults(ctx, fused, limit)
+	results := make(storage.SearchResults, 0, limit)
+	for _, hit := range hits {
+		doc, err := h.db.GetDocument(ctx, extractDocID(hit.Key))
+		if err != nil || doc == nil || !filter.matches(doc) {
+			continue
+		}
+		r := &storage.SearchResult{
+			Document:    doc,
+			Score:       hit.Score,
+			VectorScore: hit.Score,
+			ChunkID:     hit.Key,
+		}
+		if chunk, err := h.db.GetChunk(ctx, hit.Key); err == nil {
+			r.Highlights = []string{chunk.Content}
+		}
+		results = append(results, r)
+		if len(results) == limit {
+			break
+		}
+	}
+	return results, nil
 }
 
+// semanticOversample is how many nearest chunks SemanticSearch considers per
+// requested result.
+const semanticOversample = 4
+
 // fusedEntry holds the combined RRF score for a document.
 type fusedEntry struct {
 	docID      string
@@ -106,13 +302,15 @@ type fusedEntry struct {
 
 // fuseResults combines BM25 and vector results using Reciprocal Rank Fusion.
 // RRF score = sum(1 / (k + rank)) for each result list.
-func (h *HybridSearcher) fuseResults(bm25Results []search.SearchResult, vecResults []storage.VectorResult) []fusedEntry {
+// Each list's contribution is scaled by its weight: 1-weight for BM25 and
+// weight for vectors.
+func fuseResults(bm25Results []search.SearchResult, vecResults []storage.VectorResult, weight float64) []fusedEntry {
 	const k = 60 // Standard RRF constant.
 
 	entries := make(map[string]*fusedEntry)
 
-	bm25Weight := 1.0 - h.HybridWeight
-	vecWeight := h.HybridWeight
+	bm25Weight := 1.0 - weight
+	vecWeight := weight
 
 	// Score BM25 results by rank.
 	for rank, r := range bm25Results {
@@ -152,10 +350,12 @@ func (h *HybridSearcher) fuseResults(bm25Results []search.SearchResult, vecResul
 		}
 	}
 
-	// Sort by RRF score.
+	// Sort by RRF score, dropping entries only a zero-weight list found.
 	result := make([]fusedEntry, 0, len(entries))
 	for _, e := range entries {
-		result = append(result, *e)
+		if e.rrfScore > 0 {
+			result = append(result, *e)
+		}
 	}
 	sort.Slice(result, func(i, j int) bool {
 		return result[i].rrfScore > result[j].rrfScore
@@ -164,16 +364,16 @@ func (h *HybridSearcher) fuseResults(bm25Results []search.SearchResult, vecResul
 	return result
 }
 
-// buildResults fetches full documents for the fused results.
-func (h *HybridSearcher) buildResults(ctx context.Context, fused []fusedEntry, limit int) (storage.SearchResults, error) {
-	if len(fused) > limit {
-		fused = fused[:limit]
-	}
-
-	results := make(storage.SearchResults, 0, len(fused))
+// buildResults fetches full documents for the fused results, keeping up to
+// limit that pass filter.
+func (h *HybridSearcher) buildResults(ctx context.Context, fused []fusedEntry, limit int, filter docFilter) (storage.SearchResults, error) {
+	results := make(storage.SearchResults, 0, min(len(fused), limit))
 	for _, f := range fused {
+		if len(results) == limit {
+			break
+		}
 		doc, err := h.db.GetDocument(ctx, f.docID)
-		if err != nil || doc == nil {
+		if err != nil || doc == nil || !filter.matches(doc) {
 			continue
 		}
 
@@ -199,13 +399,13 @@ func (h *HybridSearcher) buildResults(ctx context.Context, fused []fusedEntry, l
 
 // bm25Only performs BM25-only search and returns full results.
 func (h *HybridSearcher) bm25Only(ctx context.Context, queryStr string, limit int) (storage.SearchResults, error) {
-	bleveResults, err := h.bleve.Search(ctx, queryStr, limit)
+	textResults, err := h.fulltext.Search(ctx, queryStr, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	results := make(storage.SearchResults, 0, len(bleveResults))
-	for _, r := range bleveResults {
+	results := make(storage.SearchResults, 0, len(textResults))
+	for _, r := range textResults {
 		doc, err := h.db.GetDocument(ctx, r.ID)
 		if err != nil || doc == nil {
 			continue
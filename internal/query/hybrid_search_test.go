@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/J-1000/mindcli/internal/embeddings"
 	"github.com/J-1000/mindcli/internal/search"
 	"github.com/J-1000/mindcli/internal/storage"
 )
@@ -157,3 +158,217 @@ func TestHybridSearch_FallsBackToBM25WhenNoVectors(t *testing.T) {
 		t.Errorf("top result = %s, want doc2", results[0].Document.ID)
 	}
 }
+
+func TestHybridSearch_PerQueryWeight(t *testing.T) {
+	db, bleve, vectors := newHybridTestStores(t)
+	h := NewHybridSearcher(bleve, vectors, keywordEmbedder{}, db, 0.5)
+
+	ctx := context.Background()
+	for i := 0; i < 30; i++ {
+		if r, _ := h.SearchWithWeight(ctx, "ownership", 10, 0); len(r) > 0 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// Only the Rust document mentions ownership, but both documents are
+	// vector neighbours of the query.
+	exact, err := h.SearchWithWeight(ctx, "ownership", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exact) != 1 || exact[0].Document.ID != "doc2" {
+		t.Errorf("weight 0 returned %d results, want only the BM25 match doc2", len(exact))
+	}
+	semantic, err := h.SearchWithWeight(ctx, "ownership", 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(semantic) != 2 || semantic[0].Document.ID != "doc2" {
+		t.Errorf("weight 1 returned %d results, want both vector neighbours with doc2 first", len(semantic))
+	}
+}
+
+func TestSemanticSearch_ShowsBestChunkPerDocument(t *testing.T) {
+	db, bleve, vectors := newHybridTestStores(t)
+	ctx := context.Background()
+	for _, c := range []*storage.Chunk{
+		{ID: "doc1:0", DocumentID: "doc1", Content: "go programming concurrency"},
+		{ID: "doc1:1", DocumentID: "doc1", Content: "goroutines and channels"},
+	} {
+		if err := db.InsertChunk(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := vectors.AddBatch([]string{"doc1:1"}, [][]float32{{0.9, 0.1}}); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHybridSearcher(bleve, vectors, keywordEmbedder{}, db, 0.5)
+
+	// "golang" has no BM25 match, but its embedding is close to doc1's.
+	results, err := h.SemanticSearch(ctx, "golang", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want one per document", len(results))
+	}
+	top := results[0]
+	if top.Document.ID != "doc1" || top.ChunkID != "doc1:0" {
+		t.Errorf("top result = %s (chunk %s), want doc1 via its best chunk doc1:0", top.Document.ID, top.ChunkID)
+	}
+	if len(top.Highlights) != 1 || top.Highlights[0] != "go programming concurrency" {
+		t.Errorf("highlights = %q, want the matching chunk's text", top.Highlights)
+	}
+	if top.BM25Score != 0 || top.Score != top.VectorScore {
+		t.Errorf("scores = %+v, want pure vector similarity", top)
+	}
+
+	filtered, err := h.SemanticSearch(ctx, "golang source:email", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 0 {
+		t.Errorf("source:email returned %d markdown results, want none", len(filtered))
+	}
+}
+
+// germanEmbedder embeds like keywordEmbedder, but stands for another model.
+type germanEmbedder struct{ keywordEmbedder }
+
+func TestSemanticSearch_ComparesVectorsOfOneModel(t *testing.T) {
+	db, bleve, vectors := newHybridTestStores(t)
+	ctx := context.Background()
+	german := &storage.Document{ID: "doc3", Source: storage.SourceMarkdown, Path: "/c.md", Title: "Go Notizen",
+		Content: "Programmierung mit Go", ContentHash: "h3", IndexedAt: time.Now(), ModifiedAt: time.Now(),
+		Metadata: map[string]string{"lang": "de"}}
+	if err := db.UpsertDocument(ctx, german); err != nil {
+		t.Fatal(err)
+	}
+	if err := vectors.AddBatch([]string{"doc3:0"}, [][]float32{{1, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	router := embeddings.NewLanguageRouter(keywordEmbedder{}, map[string]embeddings.Embedder{"de": germanEmbedder{}})
+	h := NewHybridSearcher(bleve, vectors, router, db, 0.5)
+
+	// doc3's vector is as close as doc1's, but comes from the German model.
+	results, err := h.SemanticSearch(ctx, "golang", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Document.ID == "doc3" {
+			t.Errorf("English query matched the vectors of German doc3")
+		}
+	}
+	if len(results) == 0 || results[0].Document.ID != "doc1" {
+		t.Errorf("results = %v, want doc1 first", results)
+	}
+}
+
+func TestHybridSearch_FiltersVectorHitsBySource(t *testing.T) {
+	db, bleve, vectors := newHybridTestStores(t)
+	ctx := context.Background()
+	pdf := &storage.Document{ID: "doc3", Source: storage.SourcePDF, Path: "/c.pdf", Title: "Go paper",
+		Content: "concurrency in practice", ContentHash: "h3", IndexedAt: time.Now(), ModifiedAt: time.Now()}
+	if err := db.UpsertDocument(ctx, pdf); err != nil {
+		t.Fatal(err)
+	}
+	if err := bleve.Index(ctx, pdf); err != nil {
+		t.Fatal(err)
+	}
+	if err := vectors.AddBatch([]string{"doc3:0"}, [][]float32{{0.9, 0.1}}); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHybridSearcher(bleve, vectors, keywordEmbedder{}, db, 0.5)
+
+	// "golang" has no BM25 match; the markdown vector hit must be dropped.
+	results, err := h.Search(ctx, "golang source:pdf", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Document.ID != "doc3" {
+		t.Errorf("source:pdf returned %v, want only the PDF", results)
+	}
+
+	for _, weight := range []float64{0.5, 1} {
+		results, err = h.SearchWithWeight(ctx, "golang source:markdown,pdf", 10, weight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := map[string]bool{}
+		for _, r := range results {
+			ids[r.Document.ID] = true
+		}
+		if !ids["doc1"] || !ids["doc3"] {
+			t.Errorf("weight %v: source:markdown,pdf returned %v, want doc1 and doc3", weight, ids)
+		}
+	}
+}
+
+func TestSplitFilters(t *testing.T) {
+	terms, filter := splitFilters(`go source:markdown,pdf channels path:"/notes/my projects" source:email folder:/work minsize:1kb`)
+	if terms != "go channels" {
+		t.Errorf("terms = %q, want the query without filters", terms)
+	}
+	if storage.JoinSources(filter.sources) != "markdown,pdf,email" {
+		t.Errorf("sources = %v", filter.sources)
+	}
+	if len(filter.dirs) != 2 || filter.dirs[0] != "/notes/my projects" || filter.dirs[1] != "/work" {
+		t.Errorf("dirs = %q", filter.dirs)
+	}
+	if terms, _ := splitFilters("(rust OR go) AND NOT python source:pdf"); terms != "rust go" {
+		t.Errorf("terms of a boolean query = %q, want the terms to match", terms)
+	}
+	if want := (search.RangeFilter{Field: search.FieldSize, Bound: 1024, Lower: true}); len(filter.ranges) != 1 || filter.ranges[0] != want {
+		t.Errorf("ranges = %+v, want %+v", filter.ranges, want)
+	}
+}
+
+func TestHybridSearch_FiltersVectorHitsByPath(t *testing.T) {
+	db, bleve, vectors := newHybridTestStores(t)
+	ctx := context.Background()
+	h := NewHybridSearcher(bleve, vectors, keywordEmbedder{}, db, 0.5)
+
+	// "golang" only matches by vector; doc1 lives at /a.md.
+	for _, weight := range []float64{0.5, 1} {
+		results, err := h.SearchWithWeight(ctx, "golang path:/elsewhere", 10, weight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 0 {
+			t.Errorf("weight %v: path:/elsewhere returned %d results, want none", weight, len(results))
+		}
+		results, err = h.SearchWithWeight(ctx, "golang path:/", 10, weight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) == 0 || results[0].Document.ID != "doc1" {
+			t.Errorf("weight %v: path:/ returned %v, want doc1 first", weight, results)
+		}
+	}
+}
+
+func TestHybridSearch_FiltersVectorHitsByRange(t *testing.T) {
+	db, bleve, vectors := newHybridTestStores(t)
+	ctx := context.Background()
+	h := NewHybridSearcher(bleve, vectors, keywordEmbedder{}, db, 0.5)
+
+	// "golang" only matches by vector, so the range filter must apply there.
+	for _, weight := range []float64{0.5, 1} {
+		results, err := h.SearchWithWeight(ctx, "golang maxsize:1", 10, weight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 0 {
+			t.Errorf("weight %v: maxsize:1 returned %d results, want none", weight, len(results))
+		}
+		results, err = h.SearchWithWeight(ctx, "golang minsize:1", 10, weight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) == 0 || results[0].Document.ID != "doc1" {
+			t.Errorf("weight %v: minsize:1 returned %v, want doc1 first", weight, results)
+		}
+	}
+}
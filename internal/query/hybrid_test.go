@@ -27,7 +27,7 @@ func TestExtractDocID(t *testing.T) {
 }
 
 func TestFuseResults(t *testing.T) {
-	h := &HybridSearcher{HybridWeight: 0.5}
+	const weight = 0.5
 
 	bm25Results := []search.SearchResult{
 		{ID: "doc1", Score: 1.5},
@@ -41,7 +41,7 @@ func TestFuseResults(t *testing.T) {
 		{Key: "doc4:0", Score: 0.7},
 	}
 
-	fused := h.fuseResults(bm25Results, vecResults)
+	fused := fuseResults(bm25Results, vecResults, weight)
 
 	if len(fused) != 4 {
 		t.Fatalf("expected 4 fused entries, got %d", len(fused))
@@ -64,7 +64,7 @@ func TestFuseResults(t *testing.T) {
 }
 
 func TestFuseResultsPureBM25(t *testing.T) {
-	h := &HybridSearcher{HybridWeight: 0.0} // Pure BM25
+	const weight = 0.0 // Pure BM25
 
 	bm25Results := []search.SearchResult{
 		{ID: "doc1", Score: 1.5},
@@ -76,7 +76,7 @@ func TestFuseResultsPureBM25(t *testing.T) {
 		{Key: "doc3:0", Score: 0.8},
 	}
 
-	fused := h.fuseResults(bm25Results, vecResults)
+	fused := fuseResults(bm25Results, vecResults, weight)
 
 	// With weight=0 (pure BM25), vector results should have 0 contribution.
 	// doc1 should be first since it's rank 1 in BM25.
@@ -86,7 +86,7 @@ func TestFuseResultsPureBM25(t *testing.T) {
 }
 
 func BenchmarkFuseResults(b *testing.B) {
-	h := &HybridSearcher{HybridWeight: 0.5}
+	const weight = 0.5
 	bm25 := make([]search.SearchResult, 100)
 	vec := make([]storage.VectorResult, 100)
 	for i := range bm25 {
@@ -96,12 +96,12 @@ func BenchmarkFuseResults(b *testing.B) {
 	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = h.fuseResults(bm25, vec)
+		_ = fuseResults(bm25, vec, weight)
 	}
 }
 
 func TestFuseResultsPureVector(t *testing.T) {
-	h := &HybridSearcher{HybridWeight: 1.0} // Pure vector
+	const weight = 1.0 // Pure vector
 
 	bm25Results := []search.SearchResult{
 		{ID: "doc1", Score: 1.5},
@@ -113,11 +113,17 @@ func TestFuseResultsPureVector(t *testing.T) {
 		{Key: "doc3:0", Score: 0.8},
 	}
 
-	fused := h.fuseResults(bm25Results, vecResults)
+	fused := fuseResults(bm25Results, vecResults, weight)
 
 	// With weight=1 (pure vector), BM25 results should have 0 contribution.
 	// doc2 should be first since it's rank 1 in vector results.
 	if fused[0].docID != "doc2" {
 		t.Errorf("expected doc2 first with pure vector weight, got %s", fused[0].docID)
 	}
+	// doc1 was only found by BM25, which has no weight.
+	for _, f := range fused {
+		if f.docID == "doc1" {
+			t.Error("expected BM25-only doc1 to be dropped with pure vector weight")
+		}
+	}
 }
@@ -0,0 +1,182 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+const (
+	// minParseConfidence is the confidence below which the LLM's reading of
+	// a query is ignored in favor of the heuristics.
+	minParseConfidence = 0.6
+	// maxParseCache caps the queries whose LLM reading is remembered.
+	maxParseCache = 256
+	// parseTimeout bounds the LLM call, so a slow model delays a search by
+	// at most this long.
+	parseTimeout = 10 * time.Second
+)
+
+// llmParse is the LLM's reading of a query.
+type llmParse struct {
+	Intent     string  `json:"intent"`
+	Confidence float64 `json:"confidence"`
+	Terms      string  `json:"terms"`
+	Source     string  `json:"source"`
+	Time       string  `json:"time"`
+}
+
+// LLMParser refines ParseQuery's heuristic reading of a query with an LLM:
+// it catches questions and summary requests phrased without the keywords
+// ParseQuery looks for, e.g. "go testing how to", and source and time
+// filters phrased freely. Readings are cached per query, and the heuristic
+// result is kept whenever the LLM fails or isn't confident.
+type LLMParser struct {
+	llm *LLMClient
+
+	mu    sync.Mutex
+	cache map[string]llmParse
+}
+
+// NewLLMParser creates a parser asking llm.
+func NewLLMParser(llm *LLMClient) *LLMParser {
+	return &LLMParser{llm: llm, cache: make(map[string]llmParse)}
+}
+
+// Refine returns parsed with the intent, search terms and filters the LLM
+// reads in parsed.Original. Filters already set, e.g. from flags or explicit
+// "field:value" terms, are kept. Queries the heuristics are sure about, those
+// starting with a question or summary keyword and those of one or two words,
+// are returned unchanged without asking the LLM, as are queries using
+// boolean operators, which say exactly what to match.
+func (p *LLMParser) Refine(ctx context.Context, parsed ParsedQuery) ParsedQuery {
+	if p == nil || parsed.Intent != IntentSearch || len(strings.Fields(parsed.SearchTerms)) < 3 ||
+		search.HasBooleanSyntax(parsed.SearchTerms) {
+		return parsed
+	}
+	reading, err := p.read(ctx, parsed.Original)
+	if err != nil || reading.Confidence < minParseConfidence {
+		return parsed
+	}
+	return reading.apply(parsed, time.Now())
+}
+
+// read returns the LLM's reading of q, from the cache when q was read before.
+func (p *LLMParser) read(ctx context.Context, q string) (llmParse, error) {
+	key := strings.Join(strings.Fields(strings.ToLower(q)), " ")
+	p.mu.Lock()
+	reading, ok := p.cache[key]
+	p.mu.Unlock()
+	if ok {
+		return reading, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, parseTimeout)
+	defer cancel()
+	out, err := p.llm.Generate(ctx, buildParsePrompt(q))
+	if err != nil {
+		return llmParse{}, err
+	}
+	reading, err = parseLLMReading(out)
+	if err != nil {
+		return llmParse{}, err
+	}
+
+	p.mu.Lock()
+	if len(p.cache) >= maxParseCache {
+		clear(p.cache)
+	}
+	p.cache[key] = reading
+	p.mu.Unlock()
+	return reading, nil
+}
+
+func buildParsePrompt(q string) string {
+	return `Classify this search query for a personal knowledge base. Reply with JSON only, in this form:
+{"intent": "search|answer|summarize", "confidence": 0.0-1.0, "terms": "...", "source": "...", "time": "..."}
+
+- intent: "answer" for a question to answer from the notes, "summarize" for a request to summarize a topic, "search" for anything else.
+- terms: the words to search for, taken from the query, without question words or filler.
+- source: one of ` + storage.JoinSources(storage.AllSources) + ` if the query limits where to look, else "".
+- time: the period the query names, as one of today, yesterday, this week, last week, this month, last month, this year, last year, "past N days" (or weeks, months, years), "in <month>", "in <year>", or "since", "after", "before" or "on" a YYYY-MM-DD date; else "".
+
+Query: ` + q
+}
+
+// parseLLMReading extracts the JSON object from an LLM reply, which may wrap
+// it in prose or a code fence.
+func parseLLMReading(out string) (llmParse, error) {
+	start, end := strings.Index(out, "{"), strings.LastIndex(out, "}")
+	if start < 0 || end < start {
+		return llmParse{}, fmt.Errorf("no JSON in LLM reply %q", out)
+	}
+	var reading llmParse
+	if err := json.Unmarshal([]byte(out[start:end+1]), &reading); err != nil {
+		return llmParse{}, fmt.Errorf("parsing LLM reply: %w", err)
+	}
+	return reading, nil
+}
+
+// apply merges the reading into parsed, skipping the values that aren't
+// valid, so a confused model can't break a search.
+func (r llmParse) apply(parsed ParsedQuery, now time.Time) ParsedQuery {
+	switch intent := QueryIntent(strings.ToLower(r.Intent)); intent {
+	case IntentSearch, IntentAnswer, IntentSummarize:
+		parsed.Intent = intent
+	}
+	if terms := r.searchTerms(parsed.Original); terms != "" {
+		parsed.SearchTerms = strings.Join(append([]string{terms}, fieldTerms(parsed.SearchTerms)...), " ")
+	}
+	if source := storage.Source(strings.ToLower(r.Source)); parsed.SourceFilter == "" && slices.Contains(storage.AllSources, source) {
+		parsed.SourceFilter = string(source)
+	}
+	if parsed.TimeFilter == "" && r.Time != "" {
+		_ = parsed.SetTimeFilter(r.Time, now)
+	}
+	return parsed
+}
+
+// searchTerms returns the reading's search terms if every word of them is
+// in the original query, or "" otherwise. Words with a colon are dropped:
+// "field:value" filters are already taken out by ParseQuery.
+func (r llmParse) searchTerms(original string) string {
+	inQuery := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(original), isTermSeparator) {
+		inQuery[w] = true
+	}
+	var terms []string
+	for _, w := range strings.Fields(strings.ToLower(r.Terms)) {
+		w = strings.Trim(w, `.,;!?"'`)
+		if w == "" || strings.Contains(w, ":") {
+			continue
+		}
+		if !inQuery[w] {
+			return ""
+		}
+		terms = append(terms, w)
+	}
+	return strings.Join(terms, " ")
+}
+
+// fieldTerms returns the "field:value" terms left in search terms, such as
+// "source:" and range filters, which the search backends apply.
+func fieldTerms(terms string) []string {
+	var fields []string
+	for _, w := range strings.Fields(terms) {
+		if strings.Contains(w, ":") {
+			fields = append(fields, w)
+		}
+	}
+	return fields
+}
+
+func isTermSeparator(r rune) bool {
+	return strings.ContainsRune(" \t\n.,;!?\"'", r)
+}
@@ -0,0 +1,111 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// parseServer is a mock Ollama server answering every prompt with reply.
+func parseServer(t *testing.T, reply string, calls *atomic.Int32) *LLMClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_ = json.NewEncoder(w).Encode(ollamaGenerateResponse{Response: reply, Done: true})
+	}))
+	t.Cleanup(server.Close)
+	return NewLLMClient(server.URL, "test-model")
+}
+
+func TestLLMParserRefine(t *testing.T) {
+	var calls atomic.Int32
+	reply := "Sure:\n```json\n{\"intent\": \"answer\", \"confidence\": 0.9, \"terms\": \"go testing\", \"source\": \"markdown\", \"time\": \"last week\"}\n```"
+	parser := NewLLMParser(parseServer(t, reply, &calls))
+	ctx := context.Background()
+
+	got := parser.Refine(ctx, ParseQuery("go testing how to"))
+	if got.Intent != IntentAnswer || got.SearchTerms != "go testing" || got.SourceFilter != "markdown" || got.TimeFilter != "last week" {
+		t.Errorf("Refine() = %+v, want an answer for \"go testing\" in markdown last week", got)
+	}
+
+	// The reading is cached per query.
+	parser.Refine(ctx, ParseQuery("Go  testing how to"))
+	if calls.Load() != 1 {
+		t.Errorf("LLM called %d times for the same query, want 1", calls.Load())
+	}
+
+	// Queries the heuristics are sure about don't ask the LLM.
+	parser.Refine(ctx, ParseQuery("how do I test in go"))
+	parser.Refine(ctx, ParseQuery("go testing"))
+	parser.Refine(ctx, ParseQuery("rust OR go testing"))
+	if calls.Load() != 1 {
+		t.Errorf("LLM called %d times, want no calls for clear queries", calls.Load())
+	}
+
+	// A nil parser leaves the heuristic result.
+	var none *LLMParser
+	if got := none.Refine(ctx, ParseQuery("go testing how to")); got.Intent != IntentSearch {
+		t.Errorf("nil parser Refine() intent = %q, want search", got.Intent)
+	}
+}
+
+func TestLLMParserFallback(t *testing.T) {
+	ctx := context.Background()
+	for name, reply := range map[string]string{
+		"unconfident": `{"intent": "summarize", "confidence": 0.3}`,
+		"not json":    "I think they want a summary.",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int32
+			got := NewLLMParser(parseServer(t, reply, &calls)).Refine(ctx, ParseQuery("notes on the garden project"))
+			if got.Intent != IntentSearch || got.SearchTerms != "notes on the garden project" {
+				t.Errorf("Refine() = %+v, want the heuristic result", got)
+			}
+		})
+	}
+
+	unreachable := NewLLMParser(NewLLMClient("http://localhost:1", "none"))
+	if got := unreachable.Refine(ctx, ParseQuery("notes on the garden project")); got.Intent != IntentSearch {
+		t.Errorf("Refine() without an LLM intent = %q, want search", got.Intent)
+	}
+}
+
+func TestLLMParseApply(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	parsed := ParseQuery("sum up the garden notes in my emails author:smith")
+	reading := llmParse{
+		Intent:     "Summarize",
+		Confidence: 1,
+		Terms:      "garden notes author:smith",
+		Source:     "markdown",
+		Time:       "next century",
+	}
+	got := reading.apply(parsed, now)
+	if got.Intent != IntentSummarize {
+		t.Errorf("Intent = %q, want summarize", got.Intent)
+	}
+	if got.SearchTerms != "garden notes" {
+		t.Errorf("SearchTerms = %q, want the terms without the filter", got.SearchTerms)
+	}
+	if got.SourceFilter != "email" {
+		t.Errorf("SourceFilter = %q, want the heuristic's email kept", got.SourceFilter)
+	}
+	if got.TimeFilter != "" {
+		t.Errorf("TimeFilter = %q, want an unknown period ignored", got.TimeFilter)
+	}
+
+	// Range filters stay with the LLM's terms for the backends to apply.
+	ranged := ParseQuery("notes about the garden project minsize:10kb")
+	if got := (llmParse{Terms: "garden project"}).apply(ranged, now); got.SearchTerms != "garden project minsize:10kb" {
+		t.Errorf("SearchTerms = %q, want the range filter kept", got.SearchTerms)
+	}
+
+	// Terms that aren't in the query are made up, and ignored.
+	if got := (llmParse{Terms: "kubernetes"}).apply(parsed, now); got.SearchTerms != parsed.SearchTerms {
+		t.Errorf("SearchTerms = %q, want the heuristic's %q", got.SearchTerms, parsed.SearchTerms)
+	}
+}
@@ -0,0 +1,180 @@
+package query
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPhraseWords is the longest phrase ExtractKeywords considers.
+const maxPhraseWords = 3
+
+// keywordWordRe matches a word: a run of letters and digits.
+var keywordWordRe = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// keywordStopwords are common English words that make poor keywords, on top
+// of the query stopwords.
+var keywordStopwords = map[string]struct{}{
+	"all": {}, "also": {}, "any": {}, "are": {}, "because": {}, "been": {}, "before": {},
+	"being": {}, "both": {}, "but": {}, "can": {}, "could": {}, "does": {}, "doing": {},
+	"done": {}, "each": {}, "even": {}, "every": {}, "few": {}, "get": {}, "gets": {},
+	"getting": {}, "got": {}, "had": {}, "has": {}, "her": {}, "here": {}, "him": {},
+	"his": {}, "its": {}, "just": {}, "like": {}, "make": {}, "makes": {}, "many": {},
+	"may": {}, "might": {}, "more": {}, "most": {}, "much": {}, "must": {}, "not": {},
+	"now": {}, "off": {}, "one": {}, "only": {}, "other": {}, "our": {}, "out": {},
+	"over": {}, "same": {}, "see": {}, "she": {}, "should": {}, "some": {}, "such": {},
+	"than": {}, "their": {}, "them": {}, "then": {}, "there": {}, "these": {}, "they": {},
+	"those": {}, "through": {}, "too": {}, "under": {}, "use": {}, "used": {}, "using": {},
+	"very": {}, "was": {}, "way": {}, "well": {}, "while": {}, "will": {}, "would": {},
+	"yes": {}, "yet": {}, "you": {}, "after": {}, "again": {}, "against": {}, "between": {},
+	"down": {}, "during": {}, "further": {}, "once": {}, "own": {}, "since": {}, "until": {},
+	"upon": {}, "want": {}, "whom": {}, "whose": {}, "within": {}, "without": {},
+}
+
+// Keyword is a word or phrase that characterises a document.
+type Keyword struct {
+	Phrase string
+	Count  int // occurrences in the document
+	Score  float64
+}
+
+// TermStats counts the documents of a corpus each word appears in, so words
+// common to every document can be told from those that set one apart.
+type TermStats struct {
+	docs int
+	df   map[string]int
+}
+
+// NewTermStats returns empty corpus statistics.
+func NewTermStats() *TermStats {
+	return &TermStats{df: make(map[string]int)}
+}
+
+// Add counts the words of one document.
+func (s *TermStats) Add(text string) {
+	s.docs++
+	seen := make(map[string]bool)
+	for _, w := range keywordWordRe.FindAllString(strings.ToLower(text), -1) {
+		if !seen[w] && keywordCandidate(w) {
+			seen[w] = true
+			s.df[w]++
+		}
+	}
+}
+
+// Documents returns the number of documents counted.
+func (s *TermStats) Documents() int {
+	if s == nil {
+		return 0
+	}
+	return s.docs
+}
+
+// idf returns the inverse document frequency of word, or 1 for every word
+// when there are no statistics.
+func (s *TermStats) idf(word string) float64 {
+	if s == nil || s.docs == 0 {
+		return 1
+	}
+	return math.Log(float64(s.docs+1)/float64(s.df[word]+1)) + 1
+}
+
+// ExtractKeywords returns up to n keywords of text, best first. Candidates
+// are the words and phrases of up to three words found between stopwords and
+// punctuation, scored by TF-IDF: how often they occur in text, weighted by
+// how rare their words are across the corpus stats describe. With nil stats
+// every word weighs the same. A phrase must occur at least twice to count,
+// and a keyword contained in a better one is left out.
+func ExtractKeywords(text string, stats *TermStats, n int) []Keyword {
+	counts := make(map[string]int)
+	var order []string
+	for _, run := range keywordRuns(text) {
+		for size := 1; size <= maxPhraseWords; size++ {
+			for i := 0; i+size <= len(run); i++ {
+				phrase := strings.Join(run[i:i+size], " ")
+				if counts[phrase] == 0 {
+					order = append(order, phrase)
+				}
+				counts[phrase]++
+			}
+		}
+	}
+
+	var candidates []Keyword
+	for _, phrase := range order {
+		count := counts[phrase]
+		words := strings.Fields(phrase)
+		if len(words) > 1 && count < 2 {
+			continue
+		}
+		weight := 0.0
+		for _, w := range words {
+			weight += stats.idf(w)
+		}
+		candidates = append(candidates, Keyword{Phrase: phrase, Count: count, Score: float64(count) * weight})
+	}
+	// Stable, so equally scored keywords keep their order in the text.
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+
+	var keywords []Keyword
+	for _, c := range candidates {
+		if len(keywords) == n {
+			break
+		}
+		covered := false
+		for _, k := range keywords {
+			if strings.Contains(" "+k.Phrase+" ", " "+c.Phrase+" ") {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			keywords = append(keywords, c)
+		}
+	}
+	return keywords
+}
+
+// keywordRuns splits text into runs of candidate words, lowercased, broken
+// at stopwords and at anything between two words other than spaces.
+func keywordRuns(text string) [][]string {
+	text = strings.ToLower(text)
+	var runs [][]string
+	var run []string
+	end := 0
+	for _, loc := range keywordWordRe.FindAllStringIndex(text, -1) {
+		w := text[loc[0]:loc[1]]
+		if strings.TrimLeft(text[end:loc[0]], " \t") != "" || !keywordCandidate(w) {
+			if len(run) > 0 {
+				runs = append(runs, run)
+			}
+			run = nil
+		}
+		if keywordCandidate(w) {
+			run = append(run, w)
+		}
+		end = loc[1]
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// keywordCandidate reports whether the lowercased word w can be part of a
+// keyword: at least three characters, not a stopword and not a number.
+func keywordCandidate(w string) bool {
+	if utf8.RuneCountInString(w) < 3 {
+		return false
+	}
+	if _, skip := stopwords[w]; skip {
+		return false
+	}
+	if _, skip := keywordStopwords[w]; skip {
+		return false
+	}
+	return strings.IndexFunc(w, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0
+}
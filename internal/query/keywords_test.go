@@ -0,0 +1,53 @@
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+func phrases(keywords []Keyword) string {
+	var out []string
+	for _, k := range keywords {
+		out = append(out, k.Phrase)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestExtractKeywords(t *testing.T) {
+	text := `Vector databases store embeddings. Vector databases answer nearest
+neighbour queries, and the embeddings come from a model. Notes about the
+garden: tomatoes, tomatoes and more tomatoes. The year was 2024.`
+
+	got := ExtractKeywords(text, nil, 4)
+	if want := "vector databases,tomatoes,embeddings,store"; phrases(got) != want {
+		t.Errorf("keywords = %s, want %s", phrases(got), want)
+	}
+	if got[0].Count != 2 {
+		t.Errorf("count of %q = %d, want 2", got[0].Phrase, got[0].Count)
+	}
+	for _, k := range ExtractKeywords(text, nil, 50) {
+		if k.Phrase == "vector" || k.Phrase == "2024" || k.Phrase == "the" || k.Phrase == "queries model" {
+			t.Errorf("unexpected keyword %q", k.Phrase)
+		}
+	}
+
+	// Words in every document of the corpus drop below those that set this
+	// one apart.
+	stats := NewTermStats()
+	stats.Add(text)
+	for range 5 {
+		stats.Add("tomatoes grow in the garden")
+	}
+	if got, want := phrases(ExtractKeywords(text, stats, 3)), "vector databases,embeddings,tomatoes"; got != want {
+		t.Errorf("with corpus stats = %s, want %s", got, want)
+	}
+	if stats.Documents() != 6 {
+		t.Errorf("Documents() = %d, want 6", stats.Documents())
+	}
+}
+
+func TestExtractKeywordsEmpty(t *testing.T) {
+	if got := ExtractKeywords("the and of 42", nil, 5); len(got) != 0 {
+		t.Errorf("keywords = %v, want none", got)
+	}
+}
@@ -0,0 +1,223 @@
+package query
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// MetadataFilter restricts results to documents whose metadata matches a
+// field:value term in the query, e.g. author:smith.
+type MetadataFilter struct {
+	Field string // query field name, e.g. "author"
+	Value string // substring to match, case-insensitively
+}
+
+// metadataOversample is how many times more full-text results to fetch when
+// metadata filters will discard some of them afterwards.
+const metadataOversample = 5
+
+// SearchLimit returns how many results to request from the search backends so
+// that limit results remain after metadata filtering.
+func (p ParsedQuery) SearchLimit(limit int) int {
+	if len(p.MetadataFilters) > 0 {
+		return limit * metadataOversample
+	}
+	return limit
+}
+
+// metadataFields maps query field names to the metadata keys they search.
+// Frontmatter fields are stored with an "fm_" prefix; email headers and
+// reference fields use their own names.
+var metadataFields = map[string][]string{
+	"author":  {"fm_author", "from", "authors", "git_author"},
+	"from":    {"from"},
+	"to":      {"to"},
+	"url":     {"fm_url", "url"},
+	"date":    {"fm_date", "date"},
+	"browser": {"browser"},
+	"lang":    {"lang"}, // detected while indexing, e.g. lang:de
+	"vault":   {"vault"},
+
+	// Projects of the projects source, also matched as entities.
+	"project": {"project"},
+
+	// Senders of imported chats (mindcli import chat).
+	"participant": {"fm_participants"},
+}
+
+// participantFields maps the email fields to the participant role they
+// search. Addresses and display names are matched in the participants
+// table as well as in the raw headers, which is all there is for emails
+// indexed before it existed.
+var participantFields = map[string]storage.ParticipantRole{
+	"from": storage.RoleFrom,
+	"to":   storage.RoleTo,
+}
+
+// entityFields maps query field names to the kinds of entity they search,
+// found while indexing with indexing.entities.
+var entityFields = map[string]storage.EntityKind{
+	"person":  storage.EntityPerson,
+	"org":     storage.EntityOrganization,
+	"project": storage.EntityProject,
+}
+
+// entityFilterRe matches entity filter terms, whose value is quoted when it
+// contains spaces, as in person:"Jane Doe".
+var entityFilterRe = regexp.MustCompile(`(?i)(?:^|\s)(person|org|project):("[^"]*"|\S+)`)
+
+// extractEntityFilters removes entity filter terms from query and returns
+// them as filters. It runs before the query is lowercased, so quoted names
+// keep their spaces.
+func extractEntityFilters(query string) (string, []MetadataFilter) {
+	var filters []MetadataFilter
+	query = entityFilterRe.ReplaceAllStringFunc(query, func(m string) string {
+		field, value, _ := strings.Cut(strings.TrimSpace(m), ":")
+		if value = strings.Trim(value, `"`); value != "" {
+			filters = append(filters, MetadataFilter{Field: strings.ToLower(field), Value: value})
+		}
+		return " "
+	})
+	return strings.TrimSpace(query), filters
+}
+
+// extractMetadataFilters removes recognized field:value terms from terms and
+// returns them as filters. Unknown fields (including source: and tag:, which
+// the full-text backends handle) are left in place.
+func extractMetadataFilters(terms string) (string, []MetadataFilter) {
+	var kept []string
+	var filters []MetadataFilter
+	for _, tok := range strings.Fields(terms) {
+		field, value, ok := strings.Cut(tok, ":")
+		if _, known := metadataFields[strings.ToLower(field)]; ok && known && value != "" {
+			filters = append(filters, MetadataFilter{Field: strings.ToLower(field), Value: value})
+			continue
+		}
+		kept = append(kept, tok)
+	}
+	return strings.Join(kept, " "), filters
+}
+
+// metadataMatches returns the IDs of the documents satisfying every filter
+// in parsed. ok is false when the query has no metadata filters.
+func metadataMatches(ctx context.Context, db *storage.DB, parsed ParsedQuery) (matches map[string]bool, ok bool, err error) {
+	if len(parsed.MetadataFilters) == 0 {
+		return nil, false, nil
+	}
+	for _, f := range parsed.MetadataFilters {
+		matched, err := filterMatches(ctx, db, f)
+		if err != nil {
+			return nil, true, err
+		}
+		if matches == nil {
+			matches = matched
+			continue
+		}
+		for id := range matches {
+			if !matched[id] {
+				delete(matches, id)
+			}
+		}
+	}
+	return matches, true, nil
+}
+
+// filterMatches returns the IDs of the documents satisfying a single filter.
+func filterMatches(ctx context.Context, db *storage.DB, f MetadataFilter) (map[string]bool, error) {
+	matched := make(map[string]bool)
+	if kind, ok := entityFields[f.Field]; ok {
+		ids, err := db.FindIDsByEntity(ctx, kind, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			matched[id] = true
+		}
+	}
+	if role, ok := participantFields[f.Field]; ok {
+		ids, err := db.FindIDsByParticipant(ctx, role, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			matched[id] = true
+		}
+	}
+	for _, key := range metadataFields[f.Field] {
+		ids, err := db.FindIDsByMetadata(ctx, key, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			matched[id] = true
+		}
+	}
+	return matched, nil
+}
+
+// FilterByMetadata drops search results that don't satisfy the parsed
+// query's metadata filters. Results are returned unchanged when there are
+// none.
+func FilterByMetadata(ctx context.Context, db *storage.DB, results storage.SearchResults, parsed ParsedQuery) (storage.SearchResults, error) {
+	matches, ok, err := metadataMatches(ctx, db, parsed)
+	if !ok || err != nil {
+		return results, err
+	}
+	filtered := make(storage.SearchResults, 0, len(results))
+	for _, r := range results {
+		if r.Document != nil && matches[r.Document.ID] {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
+
+// FilterDocumentsByMetadata is the document-slice equivalent of
+// FilterByMetadata.
+func FilterDocumentsByMetadata(ctx context.Context, db *storage.DB, docs []*storage.Document, parsed ParsedQuery) ([]*storage.Document, error) {
+	matches, ok, err := metadataMatches(ctx, db, parsed)
+	if !ok || err != nil {
+		return docs, err
+	}
+	filtered := make([]*storage.Document, 0, len(docs))
+	for _, d := range docs {
+		if d != nil && matches[d.ID] {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered, nil
+}
+
+// DocumentsByMetadata lists up to limit documents satisfying the parsed
+// query's metadata, source and path filters, newest first. It serves queries made
+// only of filters, such as "author:smith", which have no terms to search for.
+func DocumentsByMetadata(ctx context.Context, db *storage.DB, parsed ParsedQuery, limit int) ([]*storage.Document, error) {
+	matches, ok, err := metadataMatches(ctx, db, parsed)
+	if !ok || err != nil {
+		return nil, err
+	}
+	docs := make([]*storage.Document, 0, len(matches))
+	for id := range matches {
+		doc, err := db.GetDocument(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.Matches(doc) {
+			docs = append(docs, doc)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if di, dj := docs[i].Date(), docs[j].Date(); !di.Equal(dj) {
+			return di.After(dj)
+		}
+		return docs[i].ID < docs[j].ID
+	})
+	if limit > 0 && len(docs) > limit {
+		docs = docs[:limit]
+	}
+	return docs, nil
+}
@@ -0,0 +1,216 @@
+package query
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestExtractMetadataFilters(t *testing.T) {
+	terms, filters := extractMetadataFilters("meeting Author:smith tag:go date:2024-03 notes")
+	if terms != "meeting tag:go notes" {
+		t.Errorf("terms = %q, want filters removed and tag: kept", terms)
+	}
+	want := []MetadataFilter{{Field: "author", Value: "smith"}, {Field: "date", Value: "2024-03"}}
+	if !reflect.DeepEqual(filters, want) {
+		t.Errorf("filters = %+v, want %+v", filters, want)
+	}
+
+	if _, filters := extractMetadataFilters("standup participant:alice"); len(filters) != 1 || filters[0].Field != "participant" {
+		t.Errorf("filters = %+v, want a participant filter", filters)
+	}
+
+	if terms, filters := extractMetadataFilters("Urlaub lang:de"); terms != "Urlaub" || len(filters) != 1 || filters[0] != (MetadataFilter{Field: "lang", Value: "de"}) {
+		t.Errorf("got %q, %+v; want a lang filter", terms, filters)
+	}
+
+	if terms, filters := extractMetadataFilters("roadmap vault:work"); terms != "roadmap" || len(filters) != 1 || filters[0] != (MetadataFilter{Field: "vault", Value: "work"}) {
+		t.Errorf("got %q, %+v; want a vault filter", terms, filters)
+	}
+
+	// A bare field name is a search term, not a filter.
+	if terms, filters := extractMetadataFilters("author: notes"); terms != "author: notes" || filters != nil {
+		t.Errorf("got %q, %+v; want the query unchanged", terms, filters)
+	}
+}
+
+func TestParseQueryMetadataFilters(t *testing.T) {
+	parsed := ParseQuery("budget author:smith")
+	if parsed.SearchTerms != "budget" {
+		t.Errorf("SearchTerms = %q, want %q", parsed.SearchTerms, "budget")
+	}
+	if len(parsed.MetadataFilters) != 1 || parsed.MetadataFilters[0].Field != "author" {
+		t.Errorf("MetadataFilters = %+v, want author filter", parsed.MetadataFilters)
+	}
+	if got := parsed.SearchLimit(10); got != 10*metadataOversample {
+		t.Errorf("SearchLimit(10) = %d, want %d", got, 10*metadataOversample)
+	}
+	if got := ParseQuery("budget").SearchLimit(10); got != 10 {
+		t.Errorf("SearchLimit(10) without filters = %d, want 10", got)
+	}
+}
+
+func TestMetadataFiltering(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	now := time.Now()
+	docs := []*storage.Document{
+		{ID: "note", Source: storage.SourceMarkdown, Path: "/note.md", Metadata: map[string]string{"fm_author": "Jane Smith", "vault": "work"}, ContentHash: "h1", IndexedAt: now, ModifiedAt: now},
+		{ID: "mail", Source: storage.SourceEmail, Path: "/mail.eml", Metadata: map[string]string{"from": "smith@example.com"}, ContentHash: "h2", IndexedAt: now, ModifiedAt: now.Add(-time.Hour)},
+		{ID: "other", Source: storage.SourceMarkdown, Path: "/other.md", Metadata: map[string]string{"fm_author": "Doe"}, ContentHash: "h3", IndexedAt: now, ModifiedAt: now},
+	}
+	for _, d := range docs {
+		if err := db.InsertDocument(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parsed := ParseQuery("author:smith")
+	got, err := DocumentsByMetadata(ctx, db, parsed, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "note" || got[1].ID != "mail" {
+		t.Errorf("DocumentsByMetadata = %v, want note then mail", docIDs(got))
+	}
+
+	parsed.SourceFilter = "email"
+	got, _ = DocumentsByMetadata(ctx, db, parsed, 10)
+	if len(got) != 1 || got[0].ID != "mail" {
+		t.Errorf("DocumentsByMetadata with source filter = %v, want mail", docIDs(got))
+	}
+
+	results := storage.SearchResults{{Document: docs[0]}, {Document: docs[2]}}
+	filtered, err := FilterByMetadata(ctx, db, results, ParseQuery("author:smith"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0].Document.ID != "note" {
+		t.Errorf("FilterByMetadata kept %d results, want only note", len(filtered))
+	}
+
+	// Every filter must match.
+	kept, _ := FilterDocumentsByMetadata(ctx, db, docs, ParseQuery("author:smith from:example"))
+	if len(kept) != 1 || kept[0].ID != "mail" {
+		t.Errorf("FilterDocumentsByMetadata = %v, want mail", docIDs(kept))
+	}
+
+	if got, _ := DocumentsByMetadata(ctx, db, ParseQuery("vault:work"), 10); len(got) != 1 || got[0].ID != "note" {
+		t.Errorf("DocumentsByMetadata(vault:work) = %v, want note", docIDs(got))
+	}
+}
+
+func TestParseQueryEntityFilters(t *testing.T) {
+	parsed := ParseQuery(`Budget person:"Jane Doe" Project:Apollo`)
+	if parsed.SearchTerms != "Budget" {
+		t.Errorf("SearchTerms = %q, want %q", parsed.SearchTerms, "Budget")
+	}
+	want := []MetadataFilter{{Field: "person", Value: "Jane Doe"}, {Field: "project", Value: "Apollo"}}
+	if !reflect.DeepEqual(parsed.MetadataFilters, want) {
+		t.Errorf("MetadataFilters = %+v, want %+v", parsed.MetadataFilters, want)
+	}
+	if parsed := ParseQuery(`org:""`); parsed.MetadataFilters != nil {
+		t.Errorf("empty org filter = %+v, want none", parsed.MetadataFilters)
+	}
+}
+
+func TestEntityFiltering(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	now := time.Now()
+	for _, d := range []*storage.Document{
+		{ID: "standup", Source: storage.SourceMarkdown, Path: "/standup.md", ContentHash: "h1", IndexedAt: now, ModifiedAt: now},
+		{ID: "retro", Source: storage.SourceMarkdown, Path: "/retro.md", ContentHash: "h2", IndexedAt: now, ModifiedAt: now},
+		{ID: "readme", Source: storage.SourceProject, Path: "/src/jane-doe/README.md", ContentHash: "h3", IndexedAt: now, ModifiedAt: now,
+			Metadata: map[string]string{"project": "jane-doe"}},
+	} {
+		if err := db.InsertDocument(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.SetEntities(ctx, "standup", []storage.Entity{{Kind: storage.EntityPerson, Name: "Jane Doe"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetEntities(ctx, "retro", []storage.Entity{{Kind: storage.EntityProject, Name: "Jane Doe"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := DocumentsByMetadata(ctx, db, ParseQuery(`person:"jane doe"`), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "standup" {
+		t.Errorf("person filter = %v, want only standup", docIDs(got))
+	}
+
+	// project: matches project entities and the projects source's documents.
+	got, err = DocumentsByMetadata(ctx, db, ParseQuery(`project:jane`), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := docIDs(got); len(ids) != 2 || !slices.Contains(ids, "retro") || !slices.Contains(ids, "readme") {
+		t.Errorf("project filter = %v, want retro and readme", ids)
+	}
+}
+
+func docIDs(docs []*storage.Document) []string {
+	ids := make([]string, len(docs))
+	for i, d := range docs {
+		ids[i] = d.ID
+	}
+	return ids
+}
+
+func TestParticipantFiltering(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	now := time.Now()
+	for _, d := range []*storage.Document{
+		{ID: "indexed", Source: storage.SourceEmail, Path: "/a.eml", Metadata: map[string]string{"from": "=?utf-8?q?Alice?= <ALICE@EXAMPLE.COM>"}, ContentHash: "h1", IndexedAt: now, ModifiedAt: now},
+		{ID: "legacy", Source: storage.SourceEmail, Path: "/b.eml", Metadata: map[string]string{"from": "alice@example.com"}, ContentHash: "h2", IndexedAt: now, ModifiedAt: now.Add(-time.Hour)},
+		{ID: "other", Source: storage.SourceEmail, Path: "/c.eml", Metadata: map[string]string{"from": "bob@example.com"}, ContentHash: "h3", IndexedAt: now, ModifiedAt: now},
+	} {
+		if err := db.InsertDocument(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.SetParticipants(ctx, "indexed", []storage.Participant{{Role: storage.RoleFrom, Address: "alice@example.com", Name: "Alice Liddell"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Participants match by address or display name, and emails without
+	// participants still match on their header.
+	got, err := DocumentsByMetadata(ctx, db, ParseQuery("from:alice@example.com"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "indexed" || got[1].ID != "legacy" {
+		t.Errorf("from:alice@example.com = %v, want indexed then legacy", docIDs(got))
+	}
+	if got, _ := DocumentsByMetadata(ctx, db, ParseQuery("from:liddell"), 10); len(got) != 1 || got[0].ID != "indexed" {
+		t.Errorf("from:liddell = %v, want the email with that display name", docIDs(got))
+	}
+	if got, _ := DocumentsByMetadata(ctx, db, ParseQuery("to:alice"), 10); len(got) != 0 {
+		t.Errorf("to:alice = %v, want none", docIDs(got))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -30,7 +31,91 @@ type ParsedQuery struct {
 	Intent       QueryIntent // What the user wants
 	SearchTerms  string      // Terms for BM25/vector search
 	TimeFilter   string      // Extracted time reference (e.g., "last week")
-	SourceFilter string      // Extracted source filter (e.g., "emails")
+	TimeFrom     time.Time   // Start of TimeFilter's range when the query was parsed; zero if open
+	TimeTo       time.Time   // End of TimeFilter's range when the query was parsed
+	SourceFilter string      // Source filter (e.g., "email", or "markdown,pdf" for either)
+	PathPrefix   string      // Only documents in this directory or below it (from "path:")
+
+	MetadataFilters []MetadataFilter // field:value terms (e.g., "author:smith")
+
+	// HybridWeight overrides the configured hybrid weight for this query,
+	// set by a "semantic:" (1) or "exact:" (0) prefix. Nil uses the default.
+	HybridWeight *float64
+}
+
+// Sources returns the sources in SourceFilter; none means every source.
+func (p ParsedQuery) Sources() []storage.Source {
+	_, filter := splitFilters("source:" + p.SourceFilter)
+	return filter.sources
+}
+
+// NamedSources returns the sources the query asks for, in SourceFilter or a
+// "source:" term of its search terms.
+func (p ParsedQuery) NamedSources() []storage.Source {
+	_, filter := splitFilters(p.SearchTerms + " " + p.Filters())
+	return filter.sources
+}
+
+// Filters returns the query's source and path filters in the search syntax
+// of the full-text backends, to append to SearchTerms.
+func (p ParsedQuery) Filters() string {
+	var filters []string
+	if p.SourceFilter != "" {
+		filters = append(filters, "source:"+p.SourceFilter)
+	}
+	if p.PathPrefix != "" {
+		filters = append(filters, `path:"`+p.PathPrefix+`"`)
+	}
+	return strings.Join(filters, " ")
+}
+
+// Matches reports whether doc passes the query's source and path filters.
+func (p ParsedQuery) Matches(doc *storage.Document) bool {
+	_, filter := splitFilters(p.Filters())
+	return filter.matches(doc)
+}
+
+// pathFilterRe matches "path:" and "folder:" terms, whose value is quoted
+// when it contains spaces.
+var pathFilterRe = regexp.MustCompile(`(?i)(?:^|\s)(?:path|folder):("[^"]*"|\S+)`)
+
+// pathFilterValue returns the directory of a term matched by pathFilterRe.
+func pathFilterValue(term string) string {
+	_, value, _ := strings.Cut(strings.TrimSpace(term), ":")
+	return NormalizePathPrefix(value)
+}
+
+// NormalizePathPrefix turns a directory given by the user, such as
+// "~/notes/projects/" or a quoted path, into a clean absolute path for
+// "path:" filters. Relative paths are taken from the working directory.
+func NormalizePathPrefix(dir string) string {
+	dir = strings.Trim(strings.TrimSpace(dir), `"`)
+	if dir == "" {
+		return ""
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = home + dir[1:]
+		}
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return filepath.Clean(dir)
+}
+
+// Weight returns the query's hybrid weight, or def when it has none.
+func (p ParsedQuery) Weight(def float64) float64 {
+	if p.HybridWeight != nil {
+		return *p.HybridWeight
+	}
+	return def
+}
+
+// weightPrefixes map query prefixes to the hybrid weight they select.
+var weightPrefixes = map[string]float64{
+	"semantic:": 1,
+	"exact:":    0,
 }
 
 // AnswerConfidence represents a simple confidence estimate for generated answers.
@@ -48,6 +133,8 @@ type LLMClient struct {
 	model    string
 	apiKey   string
 	client   *http.Client
+
+	answerLength string // "short" (default), "medium" or "long"
 }
 
 // NewLLMClient creates a client for Ollama text generation.
@@ -75,6 +162,12 @@ func NewOpenAILLMClient(apiKey, model string) *LLMClient {
 	}
 }
 
+// SetAnswerLength sets the target length of generated answers: "short",
+// "medium" or "long". Unknown values fall back to short answers.
+func (c *LLMClient) SetAnswerLength(length string) {
+	c.answerLength = length
+}
+
 // ollamaGenerateRequest is the request body for /api/generate.
 type ollamaGenerateRequest struct {
 	Model  string `json:"model"`
@@ -138,17 +231,32 @@ func (c *LLMClient) Generate(ctx context.Context, prompt string) (string, error)
 func ParseQuery(query string) ParsedQuery {
 	query = strings.TrimSpace(query)
 	parsed := ParsedQuery{
-		Original:    query,
-		Intent:      IntentSearch,
-		SearchTerms: query,
+		Original: query,
+		Intent:   IntentSearch,
 	}
+	for prefix, weight := range weightPrefixes {
+		if len(query) >= len(prefix) && strings.EqualFold(query[:len(prefix)], prefix) {
+			query = strings.TrimSpace(query[len(prefix):])
+			parsed.HybridWeight = &weight
+			break
+		}
+	}
+	// Take out path filters before anything lowercases the query.
+	query = strings.TrimSpace(pathFilterRe.ReplaceAllStringFunc(query, func(m string) string {
+		if dir := pathFilterValue(m); dir != "" {
+			parsed.PathPrefix = dir
+		}
+		return " "
+	}))
+	query, entityFilters := extractEntityFilters(query)
+	parsed.SearchTerms = query
 
 	lower := strings.ToLower(query)
 
 	// Detect intent from keywords.
-	if strings.HasPrefix(lower, "summarize ") || strings.HasPrefix(lower, "summary of ") {
+	if prefix, ok := summaryPrefix(lower); ok {
 		parsed.Intent = IntentSummarize
-		parsed.SearchTerms = strings.TrimPrefix(strings.TrimPrefix(lower, "summarize "), "summary of ")
+		parsed.SearchTerms = query[len(prefix):]
 	} else if strings.HasPrefix(lower, "what ") || strings.HasPrefix(lower, "how ") ||
 		strings.HasPrefix(lower, "why ") || strings.HasPrefix(lower, "when ") ||
 		strings.HasPrefix(lower, "who ") || strings.HasPrefix(lower, "tell me ") {
@@ -157,101 +265,78 @@ func ParseQuery(query string) ParsedQuery {
 
 	// Extract source filters.
 	sourceKeywords := map[string]string{
-		"in my notes":    "markdown",
-		"in my emails":   "email",
-		"in emails":      "email",
-		"from browser":   "browser",
-		"in browser":     "browser",
-		"from clipboard": "clipboard",
-		"in pdfs":        "pdf",
-		"in pdf":         "pdf",
+		"in my notes":       "markdown",
+		"in my emails":      "email",
+		"in emails":         "email",
+		"from browser":      "browser",
+		"in browser":        "browser",
+		"from clipboard":    "clipboard",
+		"in pdfs":           "pdf",
+		"in pdf":            "pdf",
+		"in screenshots":    "screenshot",
+		"in my screenshots": "screenshot",
+		"in my projects":    "project",
+		"in project docs":   "project",
 	}
 	for keyword, source := range sourceKeywords {
-		if strings.Contains(lower, keyword) {
+		// Cut the keyword out without lowercasing the rest, whose capitals
+		// can matter, as in "rust OR go".
+		terms := strings.ToLower(parsed.SearchTerms)
+		if i := strings.Index(terms, keyword); i >= 0 {
+			if len(terms) == len(parsed.SearchTerms) {
+				terms = parsed.SearchTerms
+			}
 			parsed.SourceFilter = source
-			parsed.SearchTerms = strings.Replace(lower, keyword, "", 1)
+			parsed.SearchTerms = terms[:i] + terms[i+len(keyword):]
 			break
 		}
 	}
 
 	// Extract time references.
-	timeKeywords := []string{
-		"last week", "last month", "yesterday", "today",
-		"this week", "this month", "last year",
-	}
-	for _, kw := range timeKeywords {
-		if strings.Contains(lower, kw) {
-			parsed.TimeFilter = kw
-			parsed.SearchTerms = strings.Replace(parsed.SearchTerms, kw, "", 1)
-			break
+	if loc := timeExprRe.FindStringIndex(parsed.SearchTerms); loc != nil {
+		if parsed.SetTimeFilter(parsed.SearchTerms[loc[0]:loc[1]], time.Now()) == nil {
+			parsed.SearchTerms = parsed.SearchTerms[:loc[0]] + parsed.SearchTerms[loc[1]:]
 		}
 	}
 
+	parsed.SearchTerms, parsed.MetadataFilters = extractMetadataFilters(parsed.SearchTerms)
+	parsed.MetadataFilters = append(parsed.MetadataFilters, entityFilters...)
 	parsed.SearchTerms = strings.TrimSpace(parsed.SearchTerms)
 	return parsed
 }
 
-// TimeRange converts a parsed time-filter keyword into an inclusive [start,end]
-// range relative to now. ok is false when there is no recognized filter.
-func TimeRange(filter string, now time.Time) (start, end time.Time, ok bool) {
-	startOfDay := func(t time.Time) time.Time {
-		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
-	}
-	startOfWeek := func(t time.Time) time.Time {
-		d := startOfDay(t)
-		// ISO-ish: treat Monday as the first day of the week.
-		offset := (int(d.Weekday()) + 6) % 7
-		return d.AddDate(0, 0, -offset)
-	}
-	firstOfMonth := func(t time.Time) time.Time {
-		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
-	}
-
-	end = now
-	switch filter {
-	case "today":
-		start = startOfDay(now)
-	case "yesterday":
-		start = startOfDay(now.AddDate(0, 0, -1))
-		end = startOfDay(now)
-	case "this week":
-		start = startOfWeek(now)
-	case "last week":
-		end = startOfWeek(now)
-		start = end.AddDate(0, 0, -7)
-	case "this month":
-		start = firstOfMonth(now)
-	case "last month":
-		end = firstOfMonth(now)
-		start = end.AddDate(0, -1, 0)
-	case "last year":
-		start = now.AddDate(-1, 0, 0)
-	default:
-		return time.Time{}, time.Time{}, false
-	}
-	return start, end, true
+// summaryPrefix returns the prefix of a lowercased query asking for a
+// summary, if it has one.
+func summaryPrefix(lower string) (string, bool) {
+	for _, prefix := range []string{"summarize ", "summary of "} {
+		if strings.HasPrefix(lower, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
 }
 
 // inTimeRange reports whether t falls within the parsed query's time filter.
 // When there is no time filter it always returns true.
 func inTimeRange(t time.Time, parsed ParsedQuery, now time.Time) bool {
-	start, end, ok := TimeRange(parsed.TimeFilter, now)
+	start, end, ok := parsed.TimeRange(now)
 	if !ok {
 		return true
 	}
 	return !t.Before(start) && !t.After(end)
 }
 
-// FilterByTime drops search results whose document modification time falls
-// outside the parsed query's time filter. Results are returned unchanged when
+// FilterByTime drops search results whose document date falls outside the
+// parsed query's time filter. A document's own date, such as a frontmatter
+// "date:", is preferred over its modification time. Results are returned unchanged when
 // there is no time filter.
 func FilterByTime(results storage.SearchResults, parsed ParsedQuery, now time.Time) storage.SearchResults {
-	if _, _, ok := TimeRange(parsed.TimeFilter, now); !ok {
+	if _, _, ok := parsed.TimeRange(now); !ok {
 		return results
 	}
 	filtered := make(storage.SearchResults, 0, len(results))
 	for _, r := range results {
-		if r.Document != nil && inTimeRange(r.Document.ModifiedAt, parsed, now) {
+		if r.Document != nil && inTimeRange(r.Document.Date(), parsed, now) {
 			filtered = append(filtered, r)
 		}
 	}
@@ -260,12 +345,12 @@ func FilterByTime(results storage.SearchResults, parsed ParsedQuery, now time.Ti
 
 // FilterDocumentsByTime is the document-slice equivalent of FilterByTime.
 func FilterDocumentsByTime(docs []*storage.Document, parsed ParsedQuery, now time.Time) []*storage.Document {
-	if _, _, ok := TimeRange(parsed.TimeFilter, now); !ok {
+	if _, _, ok := parsed.TimeRange(now); !ok {
 		return docs
 	}
 	filtered := make([]*storage.Document, 0, len(docs))
 	for _, d := range docs {
-		if d != nil && inTimeRange(d.ModifiedAt, parsed, now) {
+		if d != nil && inTimeRange(d.Date(), parsed, now) {
 			filtered = append(filtered, d)
 		}
 	}
@@ -278,19 +363,24 @@ type ConversationTurn struct {
 	Answer   string
 }
 
+// answerInstructions tells the LLM how long an answer should be, by target
+// answer length.
+var answerInstructions = map[string]string{
+	"short":  "answer the question concisely",
+	"medium": "answer the question in one or two paragraphs",
+	"long":   "answer the question thoroughly, using several paragraphs where the documents support it",
+}
+
 // buildRAGPrompt constructs the prompt for RAG-style answer generation.
-func buildRAGPrompt(question string, contexts []string) string {
-	return buildRAGPromptWithHistory(question, contexts, nil)
+func buildRAGPrompt(question string, contexts []string, length string) string {
+	return buildRAGPromptWithHistory(question, contexts, nil, length)
 }
 
 // buildRAGPromptWithHistory is buildRAGPrompt with prior conversation turns
 // prepended so follow-up questions ("tell me more") retain context.
-func buildRAGPromptWithHistory(question string, contexts []string, history []ConversationTurn) string {
+func buildRAGPromptWithHistory(question string, contexts []string, history []ConversationTurn, length string) string {
 	var contextStr strings.Builder
 	for i, ctx := range contexts {
-		if i >= 5 {
-			break
-		}
 		fmt.Fprintf(&contextStr, "--- Document %d ---\n%s\n\n", i+1, ctx)
 	}
 
@@ -303,12 +393,24 @@ func buildRAGPromptWithHistory(question string, contexts []string, history []Con
 		conversation = "Conversation so far:\n" + historyStr.String() + "\n"
 	}
 
-	return fmt.Sprintf(`Based on the following documents from the user's personal knowledge base, answer the question concisely. Cite the documents you rely on inline as [1], [2], etc., matching the document numbers below. If the documents do not contain the answer, say so.
+	instruction, ok := answerInstructions[length]
+	if !ok {
+		instruction = answerInstructions["short"]
+	}
+
+	return fmt.Sprintf(`Based on the following documents from the user's personal knowledge base, %s. Cite the documents you rely on inline as [1], [2], etc., matching the document numbers below. If the documents do not contain the answer, say so.
 
 %s%s
 Question: %s
 
-Answer:`, conversation, contextStr.String(), question)
+Answer:`, instruction, conversation, contextStr.String(), question)
+}
+
+// BuildAnswerPrompt returns the prompt an answer to question is generated
+// from, with contexts numbered for citation and an answer of length ("short",
+// "medium" or "long").
+func BuildAnswerPrompt(question string, contexts []string, length string) string {
+	return buildRAGPrompt(question, contexts, length)
 }
 
 // GenerateAnswer creates a RAG-style answer from search results using an LLM.
@@ -316,7 +418,7 @@ func (c *LLMClient) GenerateAnswer(ctx context.Context, query string, contexts [
 	if len(contexts) == 0 {
 		return "No relevant documents found.", nil
 	}
-	return c.Generate(ctx, buildRAGPrompt(query, contexts))
+	return c.Generate(ctx, buildRAGPrompt(query, contexts, c.answerLength))
 }
 
 // GenerateStream sends a streaming request and calls onChunk for each token.
@@ -382,7 +484,7 @@ func (c *LLMClient) GenerateAnswerStreamWithHistory(ctx context.Context, questio
 		onChunk("No relevant documents found.", true)
 		return nil
 	}
-	return c.GenerateStream(ctx, buildRAGPromptWithHistory(question, contexts, history), onChunk)
+	return c.GenerateStream(ctx, buildRAGPromptWithHistory(question, contexts, history, c.answerLength), onChunk)
 }
 
 // EstimateAnswerConfidence estimates answer confidence from question/context coverage.
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -38,6 +40,25 @@ func TestFilterByTime(t *testing.T) {
 	}
 }
 
+func TestFilterByTimePrefersDocumentDate(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	// Edited today, but the frontmatter dates it two years back.
+	old := &storage.SearchResult{Document: &storage.Document{
+		ModifiedAt:   now,
+		DocumentDate: now.AddDate(-2, 0, 0),
+	}}
+	// Last touched months ago, but dated this month.
+	recent := &storage.SearchResult{Document: &storage.Document{
+		ModifiedAt:   now.AddDate(0, -3, 0),
+		DocumentDate: now.AddDate(0, 0, -2),
+	}}
+
+	got := FilterByTime(storage.SearchResults{old, recent}, ParseQuery("notes this month"), now)
+	if len(got) != 1 || got[0] != recent {
+		t.Errorf("this month: got %d results, want only the document dated this month", len(got))
+	}
+}
+
 func TestTimeRange(t *testing.T) {
 	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
 	if _, _, ok := TimeRange("", now); ok {
@@ -88,6 +109,16 @@ func TestParseQuery(t *testing.T) {
 			wantIntent: IntentAnswer,
 			wantSource: "pdf",
 		},
+		{
+			query:      "error dialog in my screenshots",
+			wantIntent: IntentSearch,
+			wantSource: "screenshot",
+		},
+		{
+			query:      "release process in my projects",
+			wantIntent: IntentSearch,
+			wantSource: "project",
+		},
 	}
 
 	for _, tt := range tests {
@@ -119,8 +150,77 @@ func TestParseQueryOriginalPreserved(t *testing.T) {
 	}
 }
 
+func TestParseQueryWeightPrefixes(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantTerms  string
+		wantWeight float64
+	}{
+		{"semantic: ideas about focus", "ideas about focus", 1},
+		{"Exact:ERR_CONN_RESET", "ERR_CONN_RESET", 0},
+		{"plain query", "plain query", 0.5},
+	}
+	for _, tt := range tests {
+		parsed := ParseQuery(tt.query)
+		if parsed.SearchTerms != tt.wantTerms {
+			t.Errorf("ParseQuery(%q).SearchTerms = %q, want %q", tt.query, parsed.SearchTerms, tt.wantTerms)
+		}
+		if got := parsed.Weight(0.5); got != tt.wantWeight {
+			t.Errorf("ParseQuery(%q).Weight(0.5) = %v, want %v", tt.query, got, tt.wantWeight)
+		}
+	}
+}
+
+func TestParseQueryPathFilter(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	tests := []struct {
+		query    string
+		wantTerm string
+		wantPath string
+	}{
+		{"roadmap path:~/Notes/Projects/Alpha/", "roadmap", filepath.Join(home, "Notes/Projects/Alpha")},
+		{`Folder:"/notes/my projects" roadmap in my notes`, "roadmap", "/notes/my projects"},
+		{"roadmap", "roadmap", ""},
+	}
+	for _, tt := range tests {
+		parsed := ParseQuery(tt.query)
+		if parsed.SearchTerms != tt.wantTerm || parsed.PathPrefix != tt.wantPath {
+			t.Errorf("ParseQuery(%q) = terms %q, path %q; want %q, %q", tt.query, parsed.SearchTerms, parsed.PathPrefix, tt.wantTerm, tt.wantPath)
+		}
+	}
+
+	parsed := ParseQuery(`roadmap path:"/notes/my projects" in my notes`)
+	if got := parsed.Filters(); got != `source:markdown path:"/notes/my projects"` {
+		t.Errorf("Filters() = %q", got)
+	}
+	if !parsed.Matches(&storage.Document{Source: storage.SourceMarkdown, Path: "/notes/my projects/a.md"}) ||
+		parsed.Matches(&storage.Document{Source: storage.SourceMarkdown, Path: "/notes/other/a.md"}) ||
+		parsed.Matches(&storage.Document{Source: storage.SourcePDF, Path: "/notes/my projects/a.pdf"}) {
+		t.Error("Matches() should require both the source and the directory")
+	}
+}
+
+func TestParsedQueryNamedSources(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"what did I read about Go from browser", "browser"},
+		{"what is Go? source:browser,clipboard", "browser,clipboard"},
+		{"what is Go?", ""},
+	}
+	for _, tt := range tests {
+		if got := storage.JoinSources(ParseQuery(tt.query).NamedSources()); got != tt.want {
+			t.Errorf("ParseQuery(%q).NamedSources() = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
 func TestBuildRAGPrompt(t *testing.T) {
-	prompt := buildRAGPrompt("What is Go?", []string{"Go is a language", "Go has goroutines"})
+	prompt := buildRAGPrompt("What is Go?", []string{"Go is a language", "Go has goroutines"}, "")
 
 	if !strings.Contains(prompt, "What is Go?") {
 		t.Error("prompt should contain the question")
@@ -136,11 +236,21 @@ func TestBuildRAGPrompt(t *testing.T) {
 	}
 }
 
+func TestBuildAnswerPrompt(t *testing.T) {
+	prompt := BuildAnswerPrompt("what is Go?", []string{"Go is a language."}, "long")
+	if prompt != buildRAGPrompt("what is Go?", []string{"Go is a language."}, "long") {
+		t.Error("BuildAnswerPrompt should return the prompt answers are generated from")
+	}
+	if !strings.Contains(prompt, "--- Document 1 ---\nGo is a language.") {
+		t.Errorf("prompt missing the numbered context:\n%s", prompt)
+	}
+}
+
 func TestBuildRAGPromptWithHistory(t *testing.T) {
 	history := []ConversationTurn{
 		{Question: "What is Go?", Answer: "A programming language."},
 	}
-	prompt := buildRAGPromptWithHistory("Tell me more", []string{"Go is fast"}, history)
+	prompt := buildRAGPromptWithHistory("Tell me more", []string{"Go is fast"}, history, "")
 	if !strings.Contains(prompt, "Conversation so far") {
 		t.Error("prompt should include conversation history header")
 	}
@@ -151,20 +261,34 @@ func TestBuildRAGPromptWithHistory(t *testing.T) {
 		t.Error("prompt should include the follow-up question")
 	}
 	// Without history there should be no conversation header.
-	if strings.Contains(buildRAGPrompt("q", []string{"ctx"}), "Conversation so far") {
+	if strings.Contains(buildRAGPrompt("q", []string{"ctx"}, ""), "Conversation so far") {
 		t.Error("plain prompt should not include conversation header")
 	}
 }
 
-func TestBuildRAGPromptLimitsContexts(t *testing.T) {
-	contexts := make([]string, 10)
+func TestBuildRAGPromptIncludesEveryContext(t *testing.T) {
+	contexts := make([]string, 8)
 	for i := range contexts {
 		contexts[i] = "doc content"
 	}
-	prompt := buildRAGPrompt("question", contexts)
-	// Should only include 5 documents
-	if strings.Contains(prompt, "Document 6") {
-		t.Error("prompt should only include up to 5 documents")
+	// The context budget is applied by BuildContexts, not the prompt.
+	prompt := buildRAGPrompt("question", contexts, "")
+	if !strings.Contains(prompt, "Document 8") {
+		t.Error("prompt should include every context it is given")
+	}
+}
+
+func TestBuildRAGPromptAnswerLength(t *testing.T) {
+	for length, want := range map[string]string{
+		"":        "concisely",
+		"short":   "concisely",
+		"medium":  "one or two paragraphs",
+		"long":    "several paragraphs",
+		"unknown": "concisely",
+	} {
+		if prompt := buildRAGPrompt("q", []string{"ctx"}, length); !strings.Contains(prompt, want) {
+			t.Errorf("answer length %q: prompt should contain %q", length, want)
+		}
 	}
 }
 
@@ -363,3 +487,18 @@ func TestEstimateAnswerConfidence(t *testing.T) {
 		})
 	}
 }
+
+func TestParseQueryKeepsBooleanOperators(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantTerms string
+	}{
+		{"(Rust OR Go) AND NOT java in my notes", "(Rust OR Go) AND NOT java"},
+		{"Summarize rust OR go", "rust OR go"},
+	}
+	for _, tt := range tests {
+		if got := ParseQuery(tt.query).SearchTerms; got != tt.wantTerms {
+			t.Errorf("ParseQuery(%q).SearchTerms = %q, want %q", tt.query, got, tt.wantTerms)
+		}
+	}
+}
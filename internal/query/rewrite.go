@@ -0,0 +1,47 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRewriteGrowth bounds how much longer than the question a rewrite may
+// be, so a model answering the question instead of rewriting it is caught.
+const maxRewriteGrowth = 4
+
+// RewriteFollowUp turns a follow-up question that leans on the conversation
+// so far, such as "what about its performance?", into one that stands on
+// its own, such as "what is the performance of Go channels?", so it
+// retrieves the documents it is about. A question that already stands on
+// its own comes back as it is. Without history, or when the LLM fails or
+// replies with something other than a question, question is returned.
+func (c *LLMClient) RewriteFollowUp(ctx context.Context, question string, history []ConversationTurn) string {
+	if c == nil || len(history) == 0 || strings.TrimSpace(question) == "" {
+		return question
+	}
+	ctx, cancel := context.WithTimeout(ctx, parseTimeout)
+	defer cancel()
+	out, err := c.Generate(ctx, buildRewritePrompt(question, history))
+	if err != nil {
+		return question
+	}
+	rewritten, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	rewritten = strings.Trim(strings.TrimSpace(rewritten), "\"'`")
+	if rewritten == "" || utf8.RuneCountInString(rewritten) > maxRewriteGrowth*utf8.RuneCountInString(question)+50 {
+		return question
+	}
+	return rewritten
+}
+
+func buildRewritePrompt(question string, history []ConversationTurn) string {
+	var conversation strings.Builder
+	for _, turn := range history {
+		fmt.Fprintf(&conversation, "Q: %s\nA: %s\n\n", turn.Question, excerpt(turn.Answer, 500))
+	}
+	return `Rewrite the follow-up question so it can be understood without the conversation, replacing words like "it", "they" or "that" with what they refer to. Keep any words that limit where or when to look, such as "in my notes" or "last week". If it already stands on its own, repeat it unchanged. Reply with the question only, on one line.
+
+Conversation:
+` + conversation.String() + `Follow-up question: ` + question
+}
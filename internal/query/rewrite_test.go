@@ -0,0 +1,39 @@
+package query
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRewriteFollowUp(t *testing.T) {
+	ctx := context.Background()
+	history := []ConversationTurn{{Question: "what are Go channels?", Answer: "Channels connect goroutines [1]."}}
+
+	var calls atomic.Int32
+	llm := parseServer(t, "\"What is the performance of Go channels?\"\n", &calls)
+	if got := llm.RewriteFollowUp(ctx, "what about its performance?", history); got != "What is the performance of Go channels?" {
+		t.Errorf("RewriteFollowUp() = %q, want the standalone question", got)
+	}
+
+	// Without history there is nothing to rewrite from.
+	if got := llm.RewriteFollowUp(ctx, "what about its performance?", nil); got != "what about its performance?" || calls.Load() != 1 {
+		t.Errorf("RewriteFollowUp() without history = %q after %d calls, want the question unchanged and no call", got, calls.Load())
+	}
+
+	// An answer in place of a rewrite, or an empty reply, is ignored.
+	for name, reply := range map[string]string{
+		"answer": "Channels are fast enough for most programs, though a mutex can be quicker for guarding shared state under heavy contention, as benchmarks show.",
+		"empty":  "  ",
+	} {
+		llm := parseServer(t, reply, &calls)
+		if got := llm.RewriteFollowUp(ctx, "its speed?", history); got != "its speed?" {
+			t.Errorf("%s: RewriteFollowUp() = %q, want the question unchanged", name, got)
+		}
+	}
+
+	var none *LLMClient
+	if got := none.RewriteFollowUp(ctx, "its speed?", history); got != "its speed?" {
+		t.Errorf("nil client RewriteFollowUp() = %q", got)
+	}
+}
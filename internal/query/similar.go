@@ -0,0 +1,81 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/pkg/chunker"
+)
+
+// maxSimilarChunks caps how many chunks of a passage are embedded, so a
+// very long paste doesn't turn into hundreds of embedding calls.
+const maxSimilarChunks = 16
+
+// similarFallbackTerms is how many key terms the BM25 fallback searches for.
+const similarFallbackTerms = 12
+
+// SimilarToText finds documents related to an arbitrary passage, such as text
+// pasted from something you're reading. The passage is chunked like indexed
+// documents, and documents are ranked by their best similarity to any chunk.
+// Without vector search it falls back to BM25 on the passage's key terms.
+func (h *HybridSearcher) SimilarToText(ctx context.Context, text string, limit int) (storage.SearchResults, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("no text to compare")
+	}
+	if h.vectors == nil || h.embedder == nil || h.vectors.Len() == 0 {
+		return h.similarByTerms(ctx, text, limit)
+	}
+
+	parts := chunker.Split(text, chunker.DefaultOptions())
+	if len(parts) > maxSimilarChunks {
+		parts = parts[:maxSimilarChunks]
+	}
+	texts := make([]string, len(parts))
+	for i, p := range parts {
+		texts[i] = p.Content
+	}
+	// All chunks are embedded as the language of the whole passage.
+	embedCtx, comparable := h.routeQuery(ctx, text)
+	embs, err := h.embedder.EmbedBatch(embedCtx, texts)
+	if err != nil {
+		return h.similarByTerms(ctx, text, limit)
+	}
+	return h.nearestDocuments(ctx, embs, limit, docFilter{comparable: comparable})
+}
+
+func (h *HybridSearcher) similarByTerms(ctx context.Context, text string, limit int) (storage.SearchResults, error) {
+	terms := keyTerms(text, similarFallbackTerms)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	return h.bm25Only(ctx, strings.Join(terms, " "), limit)
+}
+
+// keyTerms returns the n most frequent non-stopword terms in text, most
+// frequent first.
+func keyTerms(text string, n int) []string {
+	counts := make(map[string]int)
+	var order []string
+	for _, p := range tokenSplitRe.Split(strings.ToLower(text), -1) {
+		if len(p) < 3 {
+			continue
+		}
+		if _, skip := stopwords[p]; skip {
+			continue
+		}
+		if counts[p] == 0 {
+			order = append(order, p)
+		}
+		counts[p]++
+	}
+	// Stable, so equally frequent terms keep their order in the text.
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+	if len(order) > n {
+		order = order[:n]
+	}
+	return order
+}
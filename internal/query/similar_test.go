@@ -0,0 +1,54 @@
+package query
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSimilarToText(t *testing.T) {
+	db, bleve, vectors := newHybridTestStores(t)
+	h := NewHybridSearcher(bleve, vectors, keywordEmbedder{}, db, 0.5)
+	ctx := context.Background()
+
+	// Shares no words with doc1, but embeds close to it.
+	results, err := h.SimilarToText(ctx, "Goroutines make it easy to fan work out across cores.", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) == 0 || results[0].Document.ID != "doc1" {
+		t.Fatalf("results = %v, want doc1 first", results)
+	}
+	if results[0].VectorScore <= 0 {
+		t.Errorf("top result has vector score %v, want a similarity", results[0].VectorScore)
+	}
+
+	if _, err := h.SimilarToText(ctx, "   ", 10); err == nil {
+		t.Error("expected an error for empty text")
+	}
+}
+
+func TestSimilarToText_FallsBackToKeyTerms(t *testing.T) {
+	db, bleve, _ := newHybridTestStores(t)
+	h := NewHybridSearcher(bleve, nil, nil, db, 0.5)
+
+	results, err := h.SimilarToText(context.Background(),
+		"The borrow checker enforces ownership: each value has one owner, and ownership moves on assignment.", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Document.ID != "doc2" {
+		t.Errorf("results = %v, want doc2 matched on \"ownership\"", results)
+	}
+}
+
+func TestKeyTerms(t *testing.T) {
+	got := keyTerms("The cache is fast. The cache is small, and a fast cache is cheap.", 3)
+	want := []string{"cache", "fast", "small"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("keyTerms() = %v, want %v", got, want)
+	}
+	if got := keyTerms("a an the of", 5); len(got) != 0 {
+		t.Errorf("keyTerms(stopwords) = %v, want none", got)
+	}
+}
@@ -0,0 +1,191 @@
+package query
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/search"
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// maxNearest caps the documents suggested for being nearest in meaning.
+const maxNearest = 3
+
+// Suggestions are alternatives offered for a query that found nothing.
+type Suggestions struct {
+	// Spelling is the query with its unknown words replaced by the closest
+	// indexed terms, when that finds something; "" otherwise.
+	Spelling string
+	// Broader are the filters which, dropped one at a time, let the query
+	// find something.
+	Broader []Broadening
+	// Nearest are the documents nearest in meaning to the query's terms,
+	// when vector search is available.
+	Nearest storage.SearchResults
+}
+
+// Broadening is a query with one of its filters dropped.
+type Broadening struct {
+	Dropped string // the filter as written in a query, e.g. "source:pdf" or "last week"
+	Found   int    // documents found without it, up to the search limit
+}
+
+// Empty reports whether there is nothing to suggest.
+func (s Suggestions) Empty() bool {
+	return s.Spelling == "" && len(s.Broader) == 0 && len(s.Nearest) == 0
+}
+
+// SearchFunc runs a query the way the caller searches, filters and all, and
+// returns how many documents it finds.
+type SearchFunc func(ctx context.Context, parsed ParsedQuery) (int, error)
+
+// Suggest offers alternatives to parsed, a query that found nothing: its
+// words respelled after the closest terms in fulltext, the query without
+// each of its filters, and, when hybrid has vectors, the documents nearest
+// in meaning. Spellings and broader queries are only offered when run finds
+// something with them. Errors are ignored, as suggestions are only hints;
+// hybrid may be nil.
+func Suggest(ctx context.Context, fulltext search.Backend, hybrid *HybridSearcher, parsed ParsedQuery, run SearchFunc) Suggestions {
+	var s Suggestions
+	s.Spelling = respell(ctx, fulltext, parsed, run)
+	for _, b := range broadenings(parsed) {
+		if n, err := run(ctx, b.query); err == nil && n > 0 {
+			s.Broader = append(s.Broader, Broadening{Dropped: b.dropped, Found: n})
+		}
+	}
+	if hybrid != nil && hybrid.SemanticAvailable() {
+		if terms := search.MatchTerms(parsed.SearchTerms); terms != "" {
+			s.Nearest, _ = hybrid.SemanticSearch(ctx, terms, maxNearest)
+		}
+	}
+	return s
+}
+
+var (
+	// tokenRe matches the terms of a query.
+	tokenRe = regexp.MustCompile(`\S+`)
+	// wordRe matches the words of a term that spelling suggestions correct.
+	wordRe = regexp.MustCompile(`\p{L}+`)
+)
+
+// respell returns parsed.Original with the words fulltext doesn't know
+// replaced by the closest terms it does, if the query finds something that
+// way, or "".
+func respell(ctx context.Context, fulltext search.Backend, parsed ParsedQuery, run SearchFunc) string {
+	if fulltext == nil {
+		return ""
+	}
+	fixes := make(map[string]string)
+	for _, w := range words(parsed.SearchTerms) {
+		w = strings.ToLower(w)
+		if _, done := fixes[w]; done || len([]rune(w)) < 3 {
+			continue
+		}
+		similar, err := fulltext.SimilarTerms(ctx, w, 1)
+		if err == nil && len(similar) > 0 && similar[0] != w {
+			fixes[w] = similar[0]
+		} else {
+			fixes[w] = ""
+		}
+	}
+	fix := func(w string) string {
+		if f := fixes[strings.ToLower(w)]; f != "" {
+			return f
+		}
+		return w
+	}
+
+	corrected := parsed
+	corrected.SearchTerms = eachWord(parsed.SearchTerms, fix)
+	if corrected.SearchTerms == parsed.SearchTerms {
+		return ""
+	}
+	if n, err := run(ctx, corrected); err != nil || n == 0 {
+		return ""
+	}
+	return eachWord(parsed.Original, fix)
+}
+
+// eachWord replaces each word of text by f's result, skipping boolean
+// operators and "field:value" terms.
+func eachWord(text string, f func(string) string) string {
+	return tokenRe.ReplaceAllStringFunc(text, func(tok string) string {
+		if !isWordTerm(tok) {
+			return tok
+		}
+		return wordRe.ReplaceAllStringFunc(tok, f)
+	})
+}
+
+// words returns the words of text that eachWord replaces.
+func words(text string) []string {
+	var out []string
+	for _, tok := range strings.Fields(text) {
+		if isWordTerm(tok) {
+			out = append(out, wordRe.FindAllString(tok, -1)...)
+		}
+	}
+	return out
+}
+
+func isWordTerm(tok string) bool {
+	switch tok {
+	case "AND", "OR", "NOT":
+		return false
+	}
+	return !strings.Contains(tok, ":")
+}
+
+// broadening is a query with one of its filters dropped.
+type broadening struct {
+	dropped string
+	query   ParsedQuery
+}
+
+// broadenings returns parsed without each of its filters in turn.
+func broadenings(parsed ParsedQuery) []broadening {
+	var out []broadening
+	if parsed.SourceFilter != "" {
+		q := parsed
+		q.SourceFilter = ""
+		out = append(out, broadening{"source:" + parsed.SourceFilter, q})
+	}
+	if parsed.PathPrefix != "" {
+		q := parsed
+		q.PathPrefix = ""
+		dropped := "path:" + parsed.PathPrefix
+		if strings.ContainsAny(parsed.PathPrefix, " \t") {
+			dropped = `path:"` + parsed.PathPrefix + `"`
+		}
+		out = append(out, broadening{dropped, q})
+	}
+	if _, _, ok := parsed.TimeRange(time.Now()); ok {
+		q := parsed
+		q.TimeFilter, q.TimeFrom, q.TimeTo = "", time.Time{}, time.Time{}
+		dropped := parsed.TimeFilter
+		if dropped == "" {
+			dropped = "the time range"
+		}
+		out = append(out, broadening{dropped, q})
+	}
+	for i, f := range parsed.MetadataFilters {
+		q := parsed
+		q.MetadataFilters = slices.Delete(slices.Clone(parsed.MetadataFilters), i, i+1)
+		out = append(out, broadening{f.Field + ":" + f.Value, q})
+	}
+	// "source:", "tag:" and range filters stay in the search terms for the
+	// backends to apply.
+	terms := strings.Fields(parsed.SearchTerms)
+	for i, tok := range terms {
+		_, isRange := search.ParseRangeFilter(tok)
+		if isRange || strings.HasPrefix(tok, "source:") || strings.HasPrefix(tok, "tag:") {
+			q := parsed
+			q.SearchTerms = strings.Join(slices.Delete(slices.Clone(terms), i, i+1), " ")
+			out = append(out, broadening{tok, q})
+		}
+	}
+	return out
+}
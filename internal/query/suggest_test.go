@@ -0,0 +1,63 @@
+package query
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSuggest(t *testing.T) {
+	db, bleve, vectors := newHybridTestStores(t)
+	ctx := context.Background()
+	h := NewHybridSearcher(bleve, vectors, keywordEmbedder{}, db, 0.5)
+	run := func(ctx context.Context, q ParsedQuery) (int, error) {
+		results, err := h.SearchWithWeight(ctx, strings.TrimSpace(q.SearchTerms+" "+q.Filters()), 10, 0)
+		return len(FilterByTime(results, q, time.Now())), err
+	}
+
+	got := Suggest(ctx, bleve, nil, ParseQuery("Programing concurency"), run)
+	if got.Spelling != "programming concurrency" || len(got.Broader) != 0 || len(got.Nearest) != 0 {
+		t.Errorf("Suggest() for misspelled words = %+v, want the spelling corrected", got)
+	}
+
+	got = Suggest(ctx, bleve, nil, ParseQuery("rust source:pdf"), run)
+	if got.Spelling != "" || !slices.Equal(got.Broader, []Broadening{{Dropped: "source:pdf", Found: 1}}) {
+		t.Errorf("Suggest() for a filtered query = %+v, want source:pdf dropped", got)
+	}
+
+	// "golang" isn't indexed, but is nearest to the Go note in meaning.
+	got = Suggest(ctx, bleve, h, ParseQuery("golang"), run)
+	if len(got.Nearest) == 0 || got.Nearest[0].Document.ID != "doc1" {
+		t.Errorf("Suggest().Nearest = %v, want doc1 first", got.Nearest)
+	}
+
+	if !(Suggestions{}).Empty() || got.Empty() {
+		t.Error("Empty() should be true only without suggestions")
+	}
+}
+
+func TestBroadenings(t *testing.T) {
+	parsed := ParseQuery(`go minsize:1kb tag:work author:smith last week`)
+	parsed.SourceFilter = "pdf"
+	parsed.PathPrefix = "/notes/my projects"
+	var dropped []string
+	for _, b := range broadenings(parsed) {
+		dropped = append(dropped, b.dropped)
+		if b.query.SearchTerms == parsed.SearchTerms && b.dropped == "minsize:1kb" {
+			t.Errorf("dropping %s left the search terms %q", b.dropped, b.query.SearchTerms)
+		}
+	}
+	want := []string{"source:pdf", `path:"/notes/my projects"`, "last week", "author:smith", "minsize:1kb", "tag:work"}
+	if !slices.Equal(dropped, want) {
+		t.Errorf("broadenings() dropped %q, want %q", dropped, want)
+	}
+}
+
+func TestEachWord(t *testing.T) {
+	got := eachWord("(Kubernets OR go) author:smiht", strings.ToUpper)
+	if want := "(KUBERNETS OR GO) author:smiht"; got != want {
+		t.Errorf("eachWord() = %q, want %q", got, want)
+	}
+}
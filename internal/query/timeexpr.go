@@ -0,0 +1,163 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var months = []string{"january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december"}
+
+// timeExprRe finds a time expression in a query: "today", "last week",
+// "past 3 days", "in March", "in March 2024", "in 2023", "since 2023-01-01",
+// "after", "before" or "on" a date.
+var timeExprRe = regexp.MustCompile(`(?i)\b(?:(?:past|last)\s+\d+\s+(?:days?|weeks?|months?|years?)|(?:since|after|before|on)\s+\d{4}-\d{2}-\d{2}|in\s+(?:` +
+	strings.Join(months, "|") + `)(?:\s+(?:19|20)\d{2})?|in\s+(?:19|20)\d{2}|today|yesterday|(?:this|last)\s+(?:week|month|year))\b`)
+
+var (
+	pastRe  = regexp.MustCompile(`^(?:past|last) (\d+) (day|week|month|year)s?$`)
+	dateRe  = regexp.MustCompile(`^(since|after|before|on) (\d{4}-\d{2}-\d{2})$`)
+	monthRe = regexp.MustCompile(`^in (` + strings.Join(months, "|") + `)(?: (\d{4}))?$`)
+	yearRe  = regexp.MustCompile(`^in (\d{4})$`)
+)
+
+// TimeRange converts a time expression into an inclusive [start,end] range
+// relative to now. It understands "today", "yesterday", "this week/month/
+// year", "last week/month/year", "past N days/weeks/months/years", "in
+// March" (the latest March), "in March 2024", "in 2023", and "since",
+// "after", "before" or "on" a YYYY-MM-DD date. "before" ranges start at the
+// zero time. ok is false when the expression isn't recognized.
+func TimeRange(filter string, now time.Time) (start, end time.Time, ok bool) {
+	filter = strings.Join(strings.Fields(strings.ToLower(filter)), " ")
+	startOfDay := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	startOfWeek := func(t time.Time) time.Time {
+		d := startOfDay(t)
+		// ISO-ish: treat Monday as the first day of the week.
+		offset := (int(d.Weekday()) + 6) % 7
+		return d.AddDate(0, 0, -offset)
+	}
+	firstOfMonth := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+
+	end = now
+	switch filter {
+	case "today":
+		start = startOfDay(now)
+	case "yesterday":
+		start = startOfDay(now.AddDate(0, 0, -1))
+		end = startOfDay(now)
+	case "this week":
+		start = startOfWeek(now)
+	case "last week":
+		end = startOfWeek(now)
+		start = end.AddDate(0, 0, -7)
+	case "this month":
+		start = firstOfMonth(now)
+	case "last month":
+		end = firstOfMonth(now)
+		start = end.AddDate(0, -1, 0)
+	case "this year":
+		start = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+	case "last year":
+		start = now.AddDate(-1, 0, 0)
+	default:
+		return relativeRange(filter, now)
+	}
+	return start, end, true
+}
+
+// relativeRange handles the expressions of TimeRange that carry a number,
+// a month or a date.
+func relativeRange(filter string, now time.Time) (start, end time.Time, ok bool) {
+	if m := pastRe.FindStringSubmatch(filter); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 {
+			return time.Time{}, time.Time{}, false
+		}
+		switch m[2] {
+		case "day":
+			start = now.AddDate(0, 0, -n)
+		case "week":
+			start = now.AddDate(0, 0, -7*n)
+		case "month":
+			start = now.AddDate(0, -n, 0)
+		case "year":
+			start = now.AddDate(-n, 0, 0)
+		}
+		return start, now, true
+	}
+	if m := dateRe.FindStringSubmatch(filter); m != nil {
+		day, err := time.ParseInLocation("2006-01-02", m[2], now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		switch m[1] {
+		case "since":
+			return day, now, true
+		case "after":
+			return day.AddDate(0, 0, 1), now, true
+		case "before":
+			return time.Time{}, day.Add(-time.Nanosecond), true
+		default: // on
+			return day, day.AddDate(0, 0, 1).Add(-time.Nanosecond), true
+		}
+	}
+	if m := monthRe.FindStringSubmatch(filter); m != nil {
+		month := time.Month(1)
+		for i, name := range months {
+			if name == m[1] {
+				month = time.Month(i + 1)
+			}
+		}
+		year := now.Year()
+		if m[2] != "" {
+			year, _ = strconv.Atoi(m[2])
+		} else if month > now.Month() {
+			// A month that hasn't come yet this year means last year's.
+			year--
+		}
+		start = time.Date(year, month, 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, 1, 0).Add(-time.Nanosecond), true
+	}
+	if m := yearRe.FindStringSubmatch(filter); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		start = time.Date(year, 1, 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(1, 0, 0).Add(-time.Nanosecond), true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// SetTimeFilter sets the query's time filter to a time expression (see
+// TimeRange), along with TimeFrom and TimeTo resolved relative to now.
+func (p *ParsedQuery) SetTimeFilter(expr string, now time.Time) error {
+	start, end, ok := TimeRange(expr, now)
+	if !ok {
+		return fmt.Errorf("unknown time expression %q (try \"last week\", \"past 3 days\", \"in March\" or \"since 2024-01-01\")", expr)
+	}
+	p.TimeFilter = strings.Join(strings.Fields(strings.ToLower(expr)), " ")
+	p.TimeFrom, p.TimeTo = start, end
+	return nil
+}
+
+// TimeRange returns the query's time range relative to now. TimeFilter is
+// resolved again, so a query kept around, such as in the TUI, stays current;
+// without one, TimeFrom and TimeTo are used as set, with a zero TimeTo meaning
+// now. ok is false when the query has no time filter.
+func (p ParsedQuery) TimeRange(now time.Time) (start, end time.Time, ok bool) {
+	if p.TimeFilter != "" {
+		return TimeRange(p.TimeFilter, now)
+	}
+	if p.TimeFrom.IsZero() && p.TimeTo.IsZero() {
+		return time.Time{}, time.Time{}, false
+	}
+	end = p.TimeTo
+	if end.IsZero() {
+		end = now
+	}
+	return p.TimeFrom, end, true
+}
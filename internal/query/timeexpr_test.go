@@ -0,0 +1,103 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeRangeExpressions(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC) // a Monday
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	endOf := func(t time.Time) time.Time { return t.Add(-time.Nanosecond) }
+	tests := []struct {
+		expr       string
+		start, end time.Time
+	}{
+		{"this year", day(2026, 1, 1), now},
+		{"past 3 days", now.AddDate(0, 0, -3), now},
+		{"Last  2 weeks", now.AddDate(0, 0, -14), now},
+		{"past 1 month", now.AddDate(0, -1, 0), now},
+		{"in March", day(2026, 3, 1), endOf(day(2026, 4, 1))},
+		{"in september", day(2025, 9, 1), endOf(day(2025, 10, 1))},
+		{"in march 2024", day(2024, 3, 1), endOf(day(2024, 4, 1))},
+		{"in 2023", day(2023, 1, 1), endOf(day(2024, 1, 1))},
+		{"since 2023-01-01", day(2023, 1, 1), now},
+		{"after 2026-06-01", day(2026, 6, 2), now},
+		{"before 2024-02-01", time.Time{}, endOf(day(2024, 2, 1))},
+		{"on 2026-06-10", day(2026, 6, 10), endOf(day(2026, 6, 11))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			start, end, ok := TimeRange(tt.expr, now)
+			if !ok {
+				t.Fatalf("TimeRange(%q) not ok", tt.expr)
+			}
+			if !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Errorf("TimeRange(%q) = [%s, %s], want [%s, %s]", tt.expr, start, end, tt.start, tt.end)
+			}
+		})
+	}
+
+	for _, expr := range []string{"past 0 days", "since yesterday", "on 2026-13-40", "in smarch", "soon"} {
+		if _, _, ok := TimeRange(expr, now); ok {
+			t.Errorf("TimeRange(%q) ok, want it rejected", expr)
+		}
+	}
+}
+
+func TestParseQueryTimeExpressions(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantTime  string
+		wantTerms string
+	}{
+		{"standup notes past 3 days", "past 3 days", "standup notes"},
+		{"Budget In March", "in march", "Budget"},
+		{"releases since 2023-01-01", "since 2023-01-01", "releases"},
+		{"what changed this year in my notes", "this year", "what changed"},
+		{"on call runbook", "", "on call runbook"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			parsed := ParseQuery(tt.query)
+			if parsed.TimeFilter != tt.wantTime {
+				t.Errorf("TimeFilter = %q, want %q", parsed.TimeFilter, tt.wantTime)
+			}
+			if parsed.SearchTerms != tt.wantTerms {
+				t.Errorf("SearchTerms = %q, want %q", parsed.SearchTerms, tt.wantTerms)
+			}
+			if tt.wantTime != "" && parsed.TimeTo.IsZero() {
+				t.Error("TimeTo is zero, want the resolved range")
+			}
+		})
+	}
+}
+
+func TestParsedQueryTimeRange(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	var p ParsedQuery
+	if _, _, ok := p.TimeRange(now); ok {
+		t.Error("a query without a time filter has no range")
+	}
+	if err := p.SetTimeFilter("soon", now); err == nil {
+		t.Error("SetTimeFilter(soon) should fail")
+	}
+
+	// An explicit range without a filter is used as is, open ended.
+	p.TimeFrom = now.AddDate(0, 0, -1)
+	start, end, ok := p.TimeRange(now)
+	if !ok || !start.Equal(p.TimeFrom) || !end.Equal(now) {
+		t.Errorf("TimeRange() = [%s, %s, %v], want [yesterday, now]", start, end, ok)
+	}
+
+	// A filter is resolved against the given time.
+	if err := p.SetTimeFilter("Past 2 Days", now.AddDate(-1, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if p.TimeFilter != "past 2 days" {
+		t.Errorf("TimeFilter = %q, want it normalized", p.TimeFilter)
+	}
+	if start, _, _ := p.TimeRange(now); !start.Equal(now.AddDate(0, 0, -2)) {
+		t.Errorf("TimeRange() start = %s, want two days before now", start)
+	}
+}
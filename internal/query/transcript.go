@@ -0,0 +1,74 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// Transcript is a generated answer together with the question that prompted
+// it and the documents it was based on, in citation order.
+type Transcript struct {
+	Question   string
+	Answer     string
+	Sources    []*storage.Document
+	Confidence AnswerConfidence
+	CreatedAt  time.Time
+}
+
+// Markdown renders the transcript as a markdown note with YAML frontmatter so
+// it can be saved alongside other notes and indexed.
+func (t Transcript) Markdown() string {
+	var sb strings.Builder
+
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "title: %q\n", t.Question)
+	fmt.Fprintf(&sb, "created: %s\n", t.CreatedAt.Format(time.RFC3339))
+	sb.WriteString("tags: [mindcli, answer]\n")
+	sb.WriteString("---\n\n")
+
+	fmt.Fprintf(&sb, "# %s\n\n", t.Question)
+	sb.WriteString(strings.TrimSpace(t.Answer))
+	sb.WriteString("\n\n")
+	fmt.Fprintf(&sb, "Confidence: %s (%.2f)\n", strings.ToUpper(t.Confidence.Level), t.Confidence.Score)
+
+	if len(t.Sources) > 0 {
+		sb.WriteString("\n## Sources\n\n")
+		for i, doc := range t.Sources {
+			if doc.Source == storage.SourceMarkdown {
+				fmt.Fprintf(&sb, "%d. [[%s]] (`%s`)\n", i+1, doc.Title, doc.Path)
+			} else {
+				fmt.Fprintf(&sb, "%d. %s (`%s`)\n", i+1, doc.Title, doc.Path)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// FileName returns a file name for the transcript derived from its creation
+// date and question, e.g. "2024-03-01-how-do-i-deploy.md".
+func (t Transcript) FileName() string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(t.Question) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+			dash = false
+		case !dash && sb.Len() > 0:
+			sb.WriteByte('-')
+			dash = true
+		}
+		if sb.Len() >= 60 {
+			break
+		}
+	}
+	slug := strings.Trim(sb.String(), "-")
+	if slug == "" {
+		slug = "answer"
+	}
+	return t.CreatedAt.Format("2006-01-02") + "-" + slug + ".md"
+}
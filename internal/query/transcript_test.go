@@ -0,0 +1,65 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestTranscriptMarkdown(t *testing.T) {
+	tr := Transcript{
+		Question: "How do I deploy?",
+		Answer:   "  Run make deploy.  ",
+		Sources: []*storage.Document{
+			{Title: "Deploy Notes", Path: "/notes/deploy.md", Source: storage.SourceMarkdown},
+			{Title: "Runbook", Path: "/docs/runbook.pdf", Source: storage.SourcePDF},
+		},
+		Confidence: AnswerConfidence{Score: 0.8, Level: "high"},
+		CreatedAt:  time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	md := tr.Markdown()
+	for _, want := range []string{
+		"title: \"How do I deploy?\"",
+		"created: 2024-03-01T10:00:00Z",
+		"# How do I deploy?\n\nRun make deploy.\n",
+		"Confidence: HIGH (0.80)",
+		"1. [[Deploy Notes]] (`/notes/deploy.md`)",
+		"2. Runbook (`/docs/runbook.pdf`)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q in:\n%s", want, md)
+		}
+	}
+	if !strings.HasPrefix(md, "---\n") {
+		t.Error("Markdown() should start with frontmatter")
+	}
+}
+
+func TestTranscriptMarkdownWithoutSources(t *testing.T) {
+	md := Transcript{Question: "q", Answer: "a"}.Markdown()
+	if strings.Contains(md, "## Sources") {
+		t.Errorf("Markdown() should omit sources section, got:\n%s", md)
+	}
+}
+
+func TestTranscriptFileName(t *testing.T) {
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		question string
+		want     string
+	}{
+		{"How do I deploy?", "2024-03-01-how-do-i-deploy.md"},
+		{"  What's   new -- in Go 1.22?  ", "2024-03-01-what-s-new-in-go-1-22.md"},
+		{"???", "2024-03-01-answer.md"},
+		{strings.Repeat("a", 100), "2024-03-01-" + strings.Repeat("a", 60) + ".md"},
+	}
+	for _, tt := range tests {
+		got := Transcript{Question: tt.question, CreatedAt: at}.FileName()
+		if got != tt.want {
+			t.Errorf("FileName(%q) = %q, want %q", tt.question, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package readlater
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// instapaperFolders are Instapaper's built-in folders. Articles in other
+// folders are tagged with the folder's name.
+var instapaperFolders = map[string]bool{"unread": true, "archive": true, "starred": true}
+
+// parseInstapaperFile reads Instapaper's CSV export, whose columns are URL,
+// Title, Selection, Folder, Timestamp and, in newer exports, Tags as a JSON
+// list. Articles in the Archive folder have been read.
+func parseInstapaperFile(path string) ([]Article, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	rows, err := readCSV(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var articles []Article
+	for _, row := range rows {
+		url := row["url"]
+		if url == "" {
+			continue
+		}
+		title := row["title"]
+		if title == "" {
+			title = url
+		}
+		var tags []string
+		if raw := row["tags"]; raw != "" {
+			if err := json.Unmarshal([]byte(raw), &tags); err != nil {
+				tags = splitTags(raw, ",")
+			}
+		}
+		folder := row["folder"]
+		if folder != "" && !instapaperFolders[strings.ToLower(folder)] {
+			tags = append(tags, folder)
+		}
+		articles = append(articles, Article{
+			Service: "Instapaper",
+			Title:   title,
+			URL:     url,
+			Excerpt: row["selection"],
+			Saved:   unixTime(row["timestamp"]),
+			Tags:    tags,
+			Read:    strings.EqualFold(folder, "archive"),
+		})
+	}
+	return articles, nil
+}
@@ -0,0 +1,36 @@
+package readlater
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseInstapaper(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instapaper-export.csv")
+	export := "URL,Title,Selection,Folder,Timestamp,Tags\n" +
+		`https://example.com/a,Essay,"A highlighted line",Unread,1714557600,"[""writing""]"` + "\n" +
+		"https://example.com/b,Paper,,Archive,1714000000,[]\n" +
+		"https://example.com/c,Recipe,,Cooking,1713000000,\n"
+	if err := os.WriteFile(path, []byte(export), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	articles, err := parseInstapaperFile(path)
+	if err != nil {
+		t.Fatalf("parseInstapaperFile() error = %v", err)
+	}
+	if len(articles) != 3 {
+		t.Fatalf("got %d articles, want 3", len(articles))
+	}
+	if a := articles[0]; a.Read || a.Excerpt != "A highlighted line" || strings.Join(a.Tags, ",") != "writing" {
+		t.Errorf("first article = %+v", a)
+	}
+	if !articles[1].Read {
+		t.Error("archived articles should be read")
+	}
+	if a := articles[2]; a.Read || strings.Join(a.Tags, ",") != "Cooking" {
+		t.Errorf("article in a custom folder = %+v, want it unread and tagged with the folder", a)
+	}
+}
@@ -0,0 +1,144 @@
+package readlater
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// omnivoreItem is a saved item of an Omnivore export's metadata files.
+type omnivoreItem struct {
+	Title           string          `json:"title"`
+	Description     string          `json:"description"`
+	Author          string          `json:"author"`
+	URL             string          `json:"url"`
+	OriginalURL     string          `json:"originalUrl"`
+	State           string          `json:"state"`
+	ReadingProgress float64         `json:"readingProgress"`
+	SavedAt         string          `json:"savedAt"`
+	Labels          []omnivoreLabel `json:"labels"`
+}
+
+// omnivoreLabel is a label, exported either as its name or as an object.
+type omnivoreLabel string
+
+func (l *omnivoreLabel) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = omnivoreLabel(s)
+		return nil
+	}
+	var label struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &label); err != nil {
+		return err
+	}
+	*l = omnivoreLabel(label.Name)
+	return nil
+}
+
+// parseOmnivoreExport reads an Omnivore export: a single metadata JSON
+// file, or the .zip or folder holding metadata_*.json files.
+func parseOmnivoreExport(p string) ([]Article, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	if info.IsDir() {
+		return parseOmnivore(os.DirFS(p))
+	}
+	if strings.EqualFold(filepath.Ext(p), ".zip") {
+		zr, err := zip.OpenReader(p)
+		if err != nil {
+			return nil, fmt.Errorf("opening export zip: %w", err)
+		}
+		defer func() { _ = zr.Close() }()
+		return parseOmnivore(zr)
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	return parseOmnivoreJSON(data)
+}
+
+// parseOmnivore parses every metadata_*.json file in fsys, in name order.
+func parseOmnivore(fsys fs.FS) ([]Article, error) {
+	var names []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasPrefix(d.Name(), "metadata") && strings.HasSuffix(d.Name(), ".json") {
+			names = append(names, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no metadata_*.json files in the export")
+	}
+	sort.Strings(names)
+
+	var articles []Article
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		parsed, err := parseOmnivoreJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		articles = append(articles, parsed...)
+	}
+	return articles, nil
+}
+
+// parseOmnivoreJSON parses a metadata file: a list of saved items. Archived
+// items and those read to the end have been read.
+func parseOmnivoreJSON(data []byte) ([]Article, error) {
+	var items []omnivoreItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("parsing Omnivore export: %w", err)
+	}
+	articles := make([]Article, 0, len(items))
+	for _, it := range items {
+		url := it.OriginalURL
+		if url == "" {
+			url = it.URL
+		}
+		if url == "" {
+			continue
+		}
+		title := it.Title
+		if title == "" {
+			title = url
+		}
+		saved, _ := time.Parse(time.RFC3339, it.SavedAt)
+		tags := make([]string, 0, len(it.Labels))
+		for _, l := range it.Labels {
+			tags = append(tags, string(l))
+		}
+		articles = append(articles, Article{
+			Service: "Omnivore",
+			Title:   title,
+			URL:     url,
+			Author:  it.Author,
+			Excerpt: it.Description,
+			Saved:   saved,
+			Tags:    tags,
+			Read:    strings.EqualFold(it.State, "archived") || it.ReadingProgress >= 100,
+		})
+	}
+	return articles, nil
+}
@@ -0,0 +1,43 @@
+package readlater
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestParseOmnivore(t *testing.T) {
+	fsys := fstest.MapFS{
+		"metadata_0_to_2.json": {Data: []byte(`[
+			{"title": "Deep Work", "author": "Cal Newport", "description": "Focus.", "url": "https://omnivore.app/me/deep-work",
+			 "originalUrl": "https://example.com/deep-work", "state": "Succeeded", "readingProgress": 40,
+			 "savedAt": "2024-05-01T09:30:00.000Z", "labels": ["productivity", {"name": "books"}]},
+			{"title": "Finished", "url": "https://example.com/done", "state": "Succeeded", "readingProgress": 100}
+		]`)},
+		"metadata_2_to_3.json":   {Data: []byte(`[{"title": "Old", "url": "https://example.com/old", "state": "Archived"}]`)},
+		"content/deep-work.html": {Data: []byte("<p>ignored</p>")},
+	}
+
+	articles, err := parseOmnivore(fsys)
+	if err != nil {
+		t.Fatalf("parseOmnivore() error = %v", err)
+	}
+	if len(articles) != 3 {
+		t.Fatalf("got %d articles, want 3", len(articles))
+	}
+	a := articles[0]
+	if a.URL != "https://example.com/deep-work" || a.Author != "Cal Newport" || a.Excerpt != "Focus." || a.Read {
+		t.Errorf("first article = %+v", a)
+	}
+	if !a.Saved.Equal(time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)) || strings.Join(a.Tags, ",") != "productivity,books" {
+		t.Errorf("saved %v labels %v", a.Saved, a.Tags)
+	}
+	if !articles[1].Read || !articles[2].Read {
+		t.Error("finished and archived items should be read")
+	}
+
+	if _, err := parseOmnivore(fstest.MapFS{"content/x.html": {}}); err == nil {
+		t.Error("an export without metadata files should be an error")
+	}
+}
@@ -0,0 +1,150 @@
+package readlater
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// pocketSectionRegex matches the headings of the HTML export, which
+	// list unread articles before the read archive.
+	pocketSectionRegex = regexp.MustCompile(`(?i)<h1>([^<]*)</h1>`)
+
+	// pocketLinkRegex matches an article link of the HTML export.
+	pocketLinkRegex = regexp.MustCompile(`(?is)<a\s+([^>]*)>(.*?)</a>`)
+
+	// pocketAttrRegex matches an attribute of an article link.
+	pocketAttrRegex = regexp.MustCompile(`([a-z_]+)="([^"]*)"`)
+)
+
+// parsePocketFile reads a Pocket export: the CSV of the current export, or
+// the HTML of the older one.
+func parsePocketFile(path string) ([]Article, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return parsePocketCSV(f)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	return parsePocketHTML(string(data)), nil
+}
+
+// parsePocketHTML parses the HTML export: a list of links under an "Unread"
+// heading and another under "Read Archive", each with the time it was
+// added and its comma-separated tags as attributes.
+func parsePocketHTML(doc string) []Article {
+	var articles []Article
+	read := false
+	sections := pocketSectionRegex.FindAllStringSubmatchIndex(doc, -1)
+	for i := -1; i < len(sections); i++ {
+		start, end := 0, len(doc)
+		if i >= 0 {
+			start = sections[i][1]
+			read = strings.Contains(strings.ToLower(doc[sections[i][2]:sections[i][3]]), "archive")
+		}
+		if i+1 < len(sections) {
+			end = sections[i+1][0]
+		}
+		for _, link := range pocketLinkRegex.FindAllStringSubmatch(doc[start:end], -1) {
+			attrs := make(map[string]string)
+			for _, attr := range pocketAttrRegex.FindAllStringSubmatch(link[1], -1) {
+				attrs[strings.ToLower(attr[1])] = html.UnescapeString(attr[2])
+			}
+			url := attrs["href"]
+			if url == "" {
+				continue
+			}
+			title := strings.TrimSpace(html.UnescapeString(link[2]))
+			if title == "" {
+				title = url
+			}
+			articles = append(articles, Article{
+				Service: "Pocket",
+				Title:   title,
+				URL:     url,
+				Saved:   unixTime(attrs["time_added"]),
+				Tags:    splitTags(attrs["tags"], ","),
+				Read:    read,
+			})
+		}
+	}
+	return articles
+}
+
+// parsePocketCSV parses the CSV export, whose columns are title, url,
+// time_added, tags (separated by "|") and status ("unread" or "archive").
+func parsePocketCSV(r io.Reader) ([]Article, error) {
+	rows, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	var articles []Article
+	for _, row := range rows {
+		url := row["url"]
+		if url == "" {
+			continue
+		}
+		title := row["title"]
+		if title == "" {
+			title = url
+		}
+		articles = append(articles, Article{
+			Service: "Pocket",
+			Title:   title,
+			URL:     url,
+			Saved:   unixTime(row["time_added"]),
+			Tags:    splitTags(row["tags"], "|"),
+			Read:    strings.EqualFold(row["status"], "archive"),
+		})
+	}
+	return articles, nil
+}
+
+// readCSV reads a CSV file with a header row into one map per row, keyed by
+// the lowercased column names.
+func readCSV(r io.Reader) ([]map[string]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(rec) {
+				row[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(rec[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// splitTags splits a list of tags, dropping empty ones.
+func splitTags(s, sep string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, sep) {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
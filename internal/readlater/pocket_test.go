@@ -0,0 +1,62 @@
+package readlater
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePocketHTML(t *testing.T) {
+	doc := `<!DOCTYPE html>
+<html><head><title>Pocket Export</title></head>
+<body>
+<h1>Unread</h1>
+<ul>
+<li><a href="https://example.com/go" time_added="1714557600" tags="go,programming">Go &amp; You</a></li>
+<li><a href="https://example.com/untitled" time_added="1714557700" tags=""></a></li>
+</ul>
+
+<h1>Read Archive</h1>
+<ul>
+<li><a href="https://example.com/rust" time_added="1714000000" tags="">Rust</a></li>
+</ul>
+</body></html>`
+
+	articles := parsePocketHTML(doc)
+	if len(articles) != 3 {
+		t.Fatalf("got %d articles, want 3: %+v", len(articles), articles)
+	}
+	goArticle := articles[0]
+	if goArticle.Title != "Go & You" || goArticle.URL != "https://example.com/go" || goArticle.Read {
+		t.Errorf("first article = %+v", goArticle)
+	}
+	if !goArticle.Saved.Equal(time.Unix(1714557600, 0)) || strings.Join(goArticle.Tags, ",") != "go,programming" {
+		t.Errorf("saved %v tags %v", goArticle.Saved, goArticle.Tags)
+	}
+	if articles[1].Title != "https://example.com/untitled" {
+		t.Errorf("untitled article title = %q, want its URL", articles[1].Title)
+	}
+	if !articles[2].Read {
+		t.Error("articles in the read archive should be read")
+	}
+}
+
+func TestParsePocketCSV(t *testing.T) {
+	export := "title,url,time_added,tags,status\n" +
+		"Go,https://example.com/go,1714557600,go|programming,unread\n" +
+		"\"Rust, again\",https://example.com/rust,1714000000,,archive\n"
+
+	articles, err := parsePocketCSV(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("parsePocketCSV() error = %v", err)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("got %d articles, want 2", len(articles))
+	}
+	if articles[0].Read || strings.Join(articles[0].Tags, ",") != "go,programming" {
+		t.Errorf("first article = %+v", articles[0])
+	}
+	if articles[1].Title != "Rust, again" || !articles[1].Read {
+		t.Errorf("second article = %+v, want archived", articles[1])
+	}
+}
@@ -0,0 +1,143 @@
+// Package readlater converts the exports of read-it-later services (Pocket,
+// Instapaper and Omnivore) into markdown notes, one per saved article, so
+// that what was saved to read can be searched with everything else.
+package readlater
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Formats lists the supported export formats.
+var Formats = []string{"pocket", "instapaper", "omnivore"}
+
+// Article is one saved article.
+type Article struct {
+	Service string // "Pocket", "Instapaper" or "Omnivore"
+	Title   string
+	URL     string
+	Author  string
+	Excerpt string // a description or highlighted selection, when exported
+	Saved   time.Time
+	Tags    []string
+	Read    bool // archived or finished, rather than still to read
+}
+
+// Parse reads the export at path in the given format. Pocket exports are the
+// ril_export.html of the old export or the part_*.csv of the new one,
+// Instapaper exports are its CSV, and Omnivore exports are a metadata_*.json
+// file, or the .zip or folder holding them.
+func Parse(format, path string) ([]Article, error) {
+	switch strings.ToLower(format) {
+	case "pocket":
+		return parsePocketFile(path)
+	case "instapaper":
+		return parseInstapaperFile(path)
+	case "omnivore":
+		return parseOmnivoreExport(path)
+	default:
+		return nil, fmt.Errorf("unknown read-later format %q (want one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Status returns "read" or "unread".
+func (a Article) Status() string {
+	if a.Read {
+		return "read"
+	}
+	return "unread"
+}
+
+// Markdown renders the article as a markdown note whose frontmatter holds
+// its URL, service, save date, tags and read state. The body is the title,
+// any excerpt and a link to the article.
+func (a Article) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "title: %q\n", a.Title)
+	fmt.Fprintf(&sb, "url: %q\n", a.URL)
+	if a.Author != "" {
+		fmt.Fprintf(&sb, "author: %q\n", a.Author)
+	}
+	fmt.Fprintf(&sb, "service: %s\n", strings.ToLower(a.Service))
+	if !a.Saved.IsZero() {
+		fmt.Fprintf(&sb, "date: %s\n", a.Saved.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&sb, "status: %s\n", a.Status())
+	tags := []string{"article", strings.ToLower(a.Service)}
+	for _, t := range a.Tags {
+		if t = tagName(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	fmt.Fprintf(&sb, "tags: [%s]\n", strings.Join(tags, ", "))
+	sb.WriteString("---\n\n")
+	fmt.Fprintf(&sb, "# %s\n\n", a.Title)
+	if excerpt := strings.TrimSpace(a.Excerpt); excerpt != "" {
+		// Quote every line so an excerpt can't start a heading.
+		fmt.Fprintf(&sb, "> %s\n\n", strings.ReplaceAll(excerpt, "\n", "\n> "))
+	}
+	fmt.Fprintf(&sb, "<%s>\n", a.URL)
+	return sb.String()
+}
+
+// FileName returns a file name for the note derived from the title, e.g.
+// "the-case-for-boring-tech.md". Importing the same export again produces
+// the same names.
+func (a Article) FileName() string {
+	name := slug(a.Title)
+	if name == "" {
+		name = slug(strings.TrimPrefix(strings.TrimPrefix(a.URL, "https://"), "http://"))
+	}
+	if name == "" {
+		name = "article"
+	}
+	return name + ".md"
+}
+
+// slug lowercases s and joins its runs of letters and digits with dashes,
+// keeping it under 60 bytes.
+func slug(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if sb.Len() >= 60 {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+			dash = false
+		case !dash && sb.Len() > 0:
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.Trim(sb.String(), "-")
+}
+
+// tagName turns a service's tag or label into a markdown tag: spaces become
+// dashes and characters tags can't hold are dropped.
+func tagName(tag string) string {
+	var sb strings.Builder
+	for _, r := range strings.TrimSpace(tag) {
+		switch {
+		case r == ' ' || r == '-' || r == '_':
+			sb.WriteRune('-')
+		case r == '/' || r == '#' || r == ',' || r == '[' || r == ']':
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return strings.Trim(sb.String(), "-")
+}
+
+// unixTime parses a Unix timestamp in seconds, as the services export them.
+func unixTime(s string) time.Time {
+	var secs int64
+	if _, err := fmt.Sscan(strings.TrimSpace(s), &secs); err != nil || secs <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
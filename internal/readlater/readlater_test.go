@@ -0,0 +1,61 @@
+package readlater
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArticleMarkdown(t *testing.T) {
+	a := Article{
+		Service: "Pocket",
+		Title:   "The Case for Boring Tech",
+		URL:     "https://example.com/boring",
+		Excerpt: "Choose boring technology.\n# not a heading",
+		Saved:   time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+		Tags:    []string{"engineering", "tech debt"},
+	}
+	md := a.Markdown()
+	for _, want := range []string{
+		`title: "The Case for Boring Tech"`,
+		`url: "https://example.com/boring"`,
+		"service: pocket",
+		"date: 2024-05-01T09:30:00Z",
+		"status: unread",
+		"tags: [article, pocket, engineering, tech-debt]",
+		"# The Case for Boring Tech\n",
+		"> Choose boring technology.\n> # not a heading",
+		"<https://example.com/boring>",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q:\n%s", want, md)
+		}
+	}
+
+	a.Read = true
+	if !strings.Contains(a.Markdown(), "status: read") {
+		t.Error("a read article should say so")
+	}
+}
+
+func TestArticleFileName(t *testing.T) {
+	tests := []struct {
+		article Article
+		want    string
+	}{
+		{Article{Title: "The Case for Boring Tech!"}, "the-case-for-boring-tech.md"},
+		{Article{Title: "¿¿", URL: "https://example.com/a"}, "example-com-a.md"},
+		{Article{}, "article.md"},
+	}
+	for _, tt := range tests {
+		if got := tt.article.FileName(); got != tt.want {
+			t.Errorf("FileName(%+v) = %q, want %q", tt.article, got, tt.want)
+		}
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	if _, err := Parse("delicious", "export.html"); err == nil || !strings.Contains(err.Error(), "pocket, instapaper, omnivore") {
+		t.Errorf("Parse() error = %v, want the supported formats listed", err)
+	}
+}
@@ -0,0 +1,71 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// Backend is a full-text index over documents. BleveIndex and FTSIndex
+// implement it; which one is used is chosen by search.backend.
+type Backend interface {
+	// Index adds or updates a document.
+	Index(ctx context.Context, doc *storage.Document) error
+	// Delete removes a document by ID.
+	Delete(ctx context.Context, id string) error
+	// Search returns matching document IDs with scores and highlights,
+	// best match first.
+	Search(ctx context.Context, queryStr string, limit int) ([]SearchResult, error)
+	// Count returns the number of indexed documents.
+	Count() (uint64, error)
+	// IDs returns the IDs of all indexed documents.
+	IDs(ctx context.Context) ([]string, error)
+	// SimilarTerms returns up to limit indexed terms within an edit or two
+	// of term, closest and most common first, for spelling suggestions. The
+	// term itself comes first when it is indexed.
+	SimilarTerms(ctx context.Context, term string, limit int) ([]string, error)
+	// Facets counts the documents matching queryStr by source, tag and
+	// month, keeping the size most common sources and tags and the size
+	// newest months.
+	Facets(ctx context.Context, queryStr string, size int) (*Facets, error)
+	// Compact merges the index's segments and reclaims the space of
+	// deleted documents.
+	Compact(ctx context.Context) error
+	// Close releases the index.
+	Close() error
+}
+
+var (
+	_ Backend = (*BleveIndex)(nil)
+	_ Backend = (*FTSIndex)(nil)
+)
+
+// addSources appends the sources named by a "source:" filter value, a
+// comma-separated list such as "markdown,pdf", to sources. Documents from
+// any of the listed sources match.
+func addSources(sources []string, value string) []string {
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			sources = append(sources, s)
+		}
+	}
+	return sources
+}
+
+// pathPrefix returns the directory named by a "path:" or "folder:" filter
+// token, such as path:/notes/projects or path:"/notes/my projects". Matching
+// documents are that directory's files and everything below it.
+func pathPrefix(tok string) (string, bool) {
+	for _, field := range []string{"path:", "folder:"} {
+		if value, ok := strings.CutPrefix(tok, field); ok {
+			value = strings.Trim(value, `"`)
+			if value == "" {
+				return "", false
+			}
+			return filepath.Clean(value), true
+		}
+	}
+	return "", false
+}
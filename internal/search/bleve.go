@@ -6,13 +6,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/J-1000/mindcli/internal/storage"
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/index/scorch"
+	"github.com/blevesearch/bleve/v2/index/scorch/mergeplan"
 	"github.com/blevesearch/bleve/v2/mapping"
 	"github.com/blevesearch/bleve/v2/search/query"
+	index "github.com/blevesearch/bleve_index_api"
 )
 
 // BleveIndex wraps a Bleve index for document search.
@@ -30,6 +35,14 @@ type bleveDocument struct {
 	Path     string `json:"path"`
 	Tags     string `json:"tags"`
 	Headings string `json:"headings"`
+
+	DocumentDate time.Time `json:"document_date"`
+	ModifiedAt   time.Time `json:"modified_at"`
+	Size         float64   `json:"size"`
+	VisitCount   *float64  `json:"visit_count,omitempty"` // only for browser history
+
+	TagList []string `json:"tag_list,omitempty"`
+	Month   string   `json:"month"`
 }
 
 // NewBleveIndex creates or opens a Bleve index at the given path.
@@ -81,6 +94,20 @@ func buildIndexMapping() mapping.IndexMapping {
 	docMapping.AddFieldMappingsAt("path", keywordFieldMapping)
 	docMapping.AddFieldMappingsAt("id", keywordFieldMapping)
 
+	// Date and numeric fields for range filters such as after: and minsize:.
+	dateFieldMapping := bleve.NewDateTimeFieldMapping()
+	numericFieldMapping := bleve.NewNumericFieldMapping()
+	docMapping.AddFieldMappingsAt(FieldDocumentDate, dateFieldMapping)
+	docMapping.AddFieldMappingsAt(FieldModifiedAt, dateFieldMapping)
+	docMapping.AddFieldMappingsAt(FieldSize, numericFieldMapping)
+	docMapping.AddFieldMappingsAt(FieldVisitCount, numericFieldMapping)
+
+	// Facet fields, counted but not searched
+	facetFieldMapping := bleve.NewKeywordFieldMapping()
+	facetFieldMapping.IncludeInAll = false
+	docMapping.AddFieldMappingsAt(FieldTagList, facetFieldMapping)
+	docMapping.AddFieldMappingsAt(FieldMonth, facetFieldMapping)
+
 	// Create index mapping
 	indexMapping := bleve.NewIndexMapping()
 	indexMapping.DefaultMapping = docMapping
@@ -100,6 +127,17 @@ func (b *BleveIndex) Index(ctx context.Context, doc *storage.Document) error {
 		Path:     doc.Path,
 		Tags:     doc.Metadata["tags"],
 		Headings: doc.Metadata["headings"],
+
+		DocumentDate: doc.Date(),
+		ModifiedAt:   doc.ModifiedAt,
+		Size:         float64(len(doc.Content)),
+
+		TagList: splitTags(doc.Metadata["tags"]),
+		Month:   docMonth(doc),
+	}
+	if n, err := strconv.Atoi(doc.Metadata["visit_count"]); err == nil {
+		visits := float64(n)
+		bleveDoc.VisitCount = &visits
 	}
 
 	if err := b.index.Index(doc.ID, bleveDoc); err != nil {
@@ -127,7 +165,10 @@ type SearchResult struct {
 // Search performs a full-text search and returns matching document IDs with scores.
 func (b *BleveIndex) Search(ctx context.Context, queryStr string, limit int) ([]SearchResult, error) {
 	// Build query
-	q := buildQuery(queryStr)
+	q, err := buildQuery(queryStr)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create search request
 	req := bleve.NewSearchRequestOptions(q, limit, 0, false)
@@ -162,46 +203,85 @@ func (b *BleveIndex) Search(ctx context.Context, queryStr string, limit int) ([]
 	return results, nil
 }
 
-// buildQuery builds a Bleve query from a query string.
-func buildQuery(queryStr string) query.Query {
-	queryStr = strings.TrimSpace(queryStr)
-	if queryStr == "" {
-		return bleve.NewMatchAllQuery()
+// Facets counts the documents matching queryStr by source, tag and month,
+// keeping the size most common sources and tags and the size newest months.
+func (b *BleveIndex) Facets(ctx context.Context, queryStr string, size int) (*Facets, error) {
+	q, err := buildQuery(queryStr)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check for special operators
-	parts := strings.Fields(queryStr)
+	req := bleve.NewSearchRequestOptions(q, 0, 0, false)
+	req.AddFacet("source", bleve.NewFacetRequest("source", size))
+	req.AddFacet("tags", bleve.NewFacetRequest(FieldTagList, size))
+	// Bleve keeps the most common terms; all months are asked for so the
+	// newest can be kept.
+	req.AddFacet("months", bleve.NewFacetRequest(FieldMonth, maxFacetMonths))
 
-	// Check for source filter (source:markdown)
-	var sourceFilter string
-	var searchTerms []string
+	result, err := b.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("counting facets: %w", err)
+	}
 
-	for _, part := range parts {
-		if strings.HasPrefix(part, "source:") {
-			sourceFilter = strings.TrimPrefix(part, "source:")
-		} else if strings.HasPrefix(part, "tag:") {
-			// Tag search
-			tag := strings.TrimPrefix(part, "tag:")
-			searchTerms = append(searchTerms, "tags:"+tag)
-		} else {
-			searchTerms = append(searchTerms, part)
+	counters := make(map[string]facetCounter)
+	for name, facet := range result.Facets {
+		c := make(facetCounter)
+		for _, t := range facet.Terms.Terms() {
+			c[t.Term] += t.Count
 		}
+		counters[name] = c
 	}
+	return &Facets{
+		Total:   int(result.Total),
+		Sources: counters["source"].top(size),
+		Tags:    counters["tags"].top(size),
+		Months:  counters["months"].newest(size),
+	}, nil
+}
+
+// buildQuery builds a Bleve query from a query string.
+func buildQuery(queryStr string) (query.Query, error) {
+	queryStr = strings.TrimSpace(queryStr)
+	if queryStr == "" {
+		return bleve.NewMatchAllQuery(), nil
+	}
+
+	// Take out source filters (source:markdown or source:markdown,pdf), path
+	// filters (path:/notes/projects) and range filters (after:2024-01-01).
+	parsed, err := parseQuery(queryStr)
+	if err != nil {
+		return nil, err
+	}
+	sources, dirs, ranges := parsed.sources, parsed.dirs, parsed.ranges
 
 	// Build main query
 	var mainQuery query.Query
-	if len(searchTerms) > 0 {
+	switch {
+	case parsed.expr != nil:
+		mainQuery = parsed.expr.bleveQuery()
+	case len(parsed.terms) > 0:
 		// Use query string query for flexibility
-		qsQuery := bleve.NewQueryStringQuery(strings.Join(searchTerms, " "))
-		mainQuery = qsQuery
-	} else {
+		searchTerms := make([]string, len(parsed.terms))
+		for i, term := range parsed.terms {
+			searchTerms[i] = tagField(term)
+		}
+		mainQuery = bleve.NewQueryStringQuery(strings.Join(searchTerms, " "))
+	default:
 		mainQuery = bleve.NewMatchAllQuery()
 	}
 
-	// Apply source filter if present
-	if sourceFilter != "" {
-		sourceQuery := bleve.NewTermQuery(sourceFilter)
-		sourceQuery.SetField("source")
+	// Apply source filter if present: any of the listed sources matches.
+	if len(sources) > 0 {
+		terms := make([]query.Query, len(sources))
+		for i, source := range sources {
+			tq := bleve.NewTermQuery(source)
+			tq.SetField("source")
+			terms[i] = tq
+		}
+		sourceQuery := terms[0]
+		if len(terms) > 1 {
+			sourceQuery = bleve.NewDisjunctionQuery(terms...)
+		}
 
 		boolQuery := bleve.NewBooleanQuery()
 		boolQuery.AddMust(mainQuery)
@@ -209,7 +289,67 @@ func buildQuery(queryStr string) query.Query {
 		mainQuery = boolQuery
 	}
 
-	return mainQuery
+	// Apply path filters: documents under any of the directories match.
+	if len(dirs) > 0 {
+		var pathQueries []query.Query
+		for _, dir := range dirs {
+			pathQueries = append(pathQueries, pathQueriesFor(dir)...)
+		}
+		boolQuery := bleve.NewBooleanQuery()
+		boolQuery.AddMust(mainQuery)
+		boolQuery.AddMust(bleve.NewDisjunctionQuery(pathQueries...))
+		mainQuery = boolQuery
+	}
+
+	// Apply range filters: documents must be within every bound.
+	if len(ranges) > 0 {
+		boolQuery := bleve.NewBooleanQuery()
+		boolQuery.AddMust(mainQuery)
+		for _, r := range ranges {
+			boolQuery.AddMust(rangeQuery(r))
+		}
+		mainQuery = boolQuery
+	}
+
+	return mainQuery, nil
+}
+
+// rangeQuery returns the Bleve query for a range filter.
+func rangeQuery(r RangeFilter) query.Query {
+	inclusive, exclusive := true, false
+	if r.IsDate() {
+		var q *query.DateRangeQuery
+		if r.Lower {
+			q = bleve.NewDateRangeInclusiveQuery(r.Time(), time.Time{}, &inclusive, nil)
+		} else {
+			q = bleve.NewDateRangeInclusiveQuery(time.Time{}, r.Time(), nil, &exclusive)
+		}
+		q.SetField(r.Field)
+		return q
+	}
+	var q *query.NumericRangeQuery
+	if r.Lower {
+		q = bleve.NewNumericRangeInclusiveQuery(&r.Bound, nil, &inclusive, nil)
+	} else {
+		q = bleve.NewNumericRangeInclusiveQuery(nil, &r.Bound, nil, &inclusive)
+	}
+	q.SetField(r.Field)
+	return q
+}
+
+// pathQueriesFor returns queries on the path field matching dir itself and
+// the paths below it, but not siblings sharing its name as a prefix.
+func pathQueriesFor(dir string) []query.Query {
+	if strings.HasSuffix(dir, "/") {
+		below := bleve.NewPrefixQuery(dir)
+		below.SetField("path")
+		return []query.Query{below}
+	}
+	exact := bleve.NewTermQuery(dir)
+	exact.SetField("path")
+	below := bleve.NewPrefixQuery(dir + "/")
+	below.SetField("path")
+	return []query.Query{exact, below}
 }
 
 // Count returns the total number of documents in the index.
@@ -217,6 +357,89 @@ func (b *BleveIndex) Count() (uint64, error) {
 	return b.index.DocCount()
 }
 
+// IDs returns the IDs of all documents in the index.
+func (b *BleveIndex) IDs(ctx context.Context) ([]string, error) {
+	n, err := b.index.DocCount()
+	if err != nil {
+		return nil, fmt.Errorf("counting documents: %w", err)
+	}
+	req := bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), int(n), 0, false)
+	res, err := b.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("listing documents: %w", err)
+	}
+	ids := make([]string, len(res.Hits))
+	for i, hit := range res.Hits {
+		ids[i] = hit.ID
+	}
+	return ids, nil
+}
+
+// SimilarTerms returns indexed terms close to term from the term
+// dictionaries of the title and content fields. Stop words, which aren't
+// indexed, have none.
+func (b *BleveIndex) SimilarTerms(ctx context.Context, term string, limit int) ([]string, error) {
+	term = normalizeTerm(term)
+	if analyzer := b.index.Mapping().AnalyzerNamed(standard.Name); term == "" || len(analyzer.Analyze([]byte(term))) == 0 {
+		return nil, nil
+	}
+	adv, err := b.index.Advanced()
+	if err != nil {
+		return nil, fmt.Errorf("opening index: %w", err)
+	}
+	reader, err := adv.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("opening index reader: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+	fuzzy, ok := reader.(index.IndexReaderFuzzy)
+	if !ok {
+		return nil, nil
+	}
+
+	var matches []termMatch
+	for _, field := range spellFields {
+		dict, err := fuzzy.FieldDictFuzzy(field, term, maxEdits(term), "")
+		if err != nil {
+			return nil, fmt.Errorf("reading terms: %w", err)
+		}
+		for {
+			entry, err := dict.Next()
+			if err != nil || entry == nil {
+				_ = dict.Close()
+				if err != nil {
+					return nil, fmt.Errorf("reading terms: %w", err)
+				}
+				break
+			}
+			matches = append(matches, termMatch{term: entry.Term, distance: editDistance(term, entry.Term), docs: entry.Count})
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return rankTerms(matches, limit), nil
+}
+
+// Compact merges the index's segments into one, dropping deleted documents.
+// Indexes not stored with scorch, which older versions created, are left
+// as they are.
+func (b *BleveIndex) Compact(ctx context.Context) error {
+	adv, err := b.index.Advanced()
+	if err != nil {
+		return fmt.Errorf("opening index: %w", err)
+	}
+	s, ok := adv.(*scorch.Scorch)
+	if !ok {
+		return nil
+	}
+	options := mergeplan.SingleSegmentMergePlanOptions
+	if err := s.ForceMerge(ctx, &options); err != nil {
+		return fmt.Errorf("merging index segments: %w", err)
+	}
+	return nil
+}
+
 // Close closes the index.
 func (b *BleveIndex) Close() error {
 	return b.index.Close()
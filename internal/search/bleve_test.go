@@ -2,6 +2,7 @@ package search
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
 	"testing"
 	"time"
@@ -141,6 +142,9 @@ func TestBleveIndex_Delete(t *testing.T) {
 	if len(results) != 1 {
 		t.Fatalf("expected 1 result before delete, got %d", len(results))
 	}
+	if ids, err := idx.IDs(ctx); err != nil || len(ids) != 1 || ids[0] != "test-doc" {
+		t.Fatalf("IDs() = %v, %v; want [test-doc]", ids, err)
+	}
 
 	// Delete document
 	if err := idx.Delete(ctx, "test-doc"); err != nil {
@@ -155,6 +159,9 @@ func TestBleveIndex_Delete(t *testing.T) {
 	if len(results) != 0 {
 		t.Errorf("expected 0 results after delete, got %d", len(results))
 	}
+	if ids, err := idx.IDs(ctx); err != nil || len(ids) != 0 {
+		t.Errorf("IDs() after delete = %v, %v; want none", ids, err)
+	}
 }
 
 func TestBleveIndex_SourceFilter(t *testing.T) {
@@ -199,6 +206,24 @@ func TestBleveIndex_SourceFilter(t *testing.T) {
 			t.Errorf("unexpected result ID: %s", r.ID)
 		}
 	}
+
+	// Several sources match any of them.
+	for _, q := range []string{"test source:markdown,pdf", "test source:pdf source:markdown"} {
+		results, err = idx.Search(ctx, q, 10)
+		if err != nil {
+			t.Fatalf("searching: %v", err)
+		}
+		if len(results) != 3 {
+			t.Errorf("%q: got %d results, want 3", q, len(results))
+		}
+	}
+	results, err = idx.Search(ctx, "test source:pdf,email", 10)
+	if err != nil {
+		t.Fatalf("searching: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "2" {
+		t.Errorf("source:pdf,email got %+v, want only the PDF", results)
+	}
 }
 
 func TestBleveIndex_Persistence(t *testing.T) {
@@ -283,3 +308,41 @@ func TestBleveIndex_Highlights(t *testing.T) {
 		t.Log("Note: No highlights returned (this may be expected)")
 	}
 }
+
+func TestBleveIndex_Compact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.bleve")
+	idx, err := NewBleveIndex(path)
+	if err != nil {
+		t.Fatalf("creating index: %v", err)
+	}
+	defer closeTestIndex(t, idx)
+
+	ctx := context.Background()
+	// Each document indexed on its own starts a segment of its own.
+	for i := range 20 {
+		doc := &storage.Document{ID: fmt.Sprint(i), Source: storage.SourceMarkdown, Title: fmt.Sprintf("Note %d", i),
+			Content: fmt.Sprintf("compaction note number%d", i), ModifiedAt: time.Now()}
+		if err := idx.Index(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range 10 {
+		if err := idx.Delete(ctx, fmt.Sprint(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := idx.Compact(ctx); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if n, _ := idx.Count(); n != 10 {
+		t.Errorf("Count() = %d after Compact, want 10", n)
+	}
+	results, err := idx.Search(ctx, "compaction", 50)
+	if err != nil || len(results) != 10 {
+		t.Errorf("Search after Compact = %d results, %v; want 10", len(results), err)
+	}
+	if results, _ := idx.Search(ctx, "number3", 10); len(results) != 0 {
+		t.Errorf("deleted document found after Compact: %v", results)
+	}
+}
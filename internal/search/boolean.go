@@ -0,0 +1,482 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// ErrInvalidQuery is returned by Search for a query whose boolean operators
+// or parentheses don't parse, such as "rust OR" or "(go OR rust".
+var ErrInvalidQuery = errors.New("invalid query")
+
+// queryFilters are the source, path and range filters of a query. Wherever
+// they appear, even inside parentheses, they apply to the whole query.
+type queryFilters struct {
+	sources, dirs []string
+	ranges        []RangeFilter
+}
+
+// parsedQuery is a search string split into its filters and its terms.
+type parsedQuery struct {
+	queryFilters
+	// terms are the other tokens of a query without boolean syntax, with
+	// their +required and -excluded prefixes.
+	terms []string
+	// expr is the boolean expression of a query using AND, OR or NOT, or
+	// nil.
+	expr *boolExpr
+}
+
+// parseQuery splits queryStr into its filters and terms, parsing the terms
+// as a boolean expression when the query uses AND, OR or NOT (in capitals).
+// There, parentheses group, and terms side by side without an operator are
+// optional as elsewhere: +term must match and -term means NOT term. Without
+// operators parentheses are plain text, so "what is Go (the language)?"
+// stays an ordinary search. The error, wrapping ErrInvalidQuery, says what
+// doesn't parse.
+func parseQuery(queryStr string) (parsedQuery, error) {
+	tokens := lexQuery(queryStr)
+	if !isBoolean(tokens) {
+		var q parsedQuery
+		for _, tok := range tokens {
+			if tok == "(" || tok == ")" {
+				continue
+			}
+			if !q.addFilter(tok) {
+				q.terms = append(q.terms, tok)
+			}
+		}
+		return q, nil
+	}
+	p := &boolParser{tokens: tokens}
+	expr, err := p.parseSeq()
+	if err == nil && p.pos < len(tokens) {
+		err = fmt.Errorf(`%w: unexpected ")" without a matching "("`, ErrInvalidQuery)
+	}
+	if err != nil {
+		return parsedQuery{}, err
+	}
+	return parsedQuery{queryFilters: p.filters, expr: expr}, nil
+}
+
+// addFilter adds tok to the filters if it is one, reporting whether it was.
+func (f *queryFilters) addFilter(tok string) bool {
+	if dir, ok := pathPrefix(tok); ok {
+		f.dirs = append(f.dirs, dir)
+	} else if r, ok := ParseRangeFilter(tok); ok {
+		f.ranges = append(f.ranges, r)
+	} else if value, ok := strings.CutPrefix(tok, "source:"); ok {
+		f.sources = addSources(f.sources, value)
+	} else {
+		return false
+	}
+	return true
+}
+
+// lexQuery splits a query on whitespace, keeping "quoted phrases" (with an
+// optional +, - or field: prefix) together. Each parenthesis outside quotes
+// is a token of its own. Other punctuation standing alone, like the ":" of
+// ":)", can't match anything and is dropped.
+func lexQuery(s string) []string {
+	var tokens []string
+	var cur strings.Builder
+	inQuote := false
+	flush := func() {
+		if strings.IndexFunc(cur.String(), isWordRune) >= 0 {
+			tokens = append(tokens, cur.String())
+		}
+		cur.Reset()
+	}
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			cur.WriteRune(r)
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		case !inQuote && (r == '(' || r == ')'):
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// HasBooleanSyntax reports whether queryStr uses AND, OR or NOT.
+func HasBooleanSyntax(queryStr string) bool {
+	return isBoolean(lexQuery(queryStr))
+}
+
+func isBoolean(tokens []string) bool {
+	for _, tok := range tokens {
+		switch tok {
+		case "AND", "OR", "NOT":
+			return true
+		}
+	}
+	return false
+}
+
+type exprKind int
+
+const (
+	exprTerm exprKind = iota
+	exprAnd
+	exprOr
+	exprNot
+	exprSeq
+)
+
+// boolExpr is a node of a boolean query: a term, or AND, OR or NOT of its
+// args, or a sequence of args side by side without an operator, of which
+// the NOT and required ones count and the others are optional. A NOT has
+// one arg.
+type boolExpr struct {
+	kind     exprKind
+	term     string
+	required bool // a +term
+	args     []*boolExpr
+}
+
+// boolParser parses boolean queries by recursive descent:
+//
+//	seq   = or { or }
+//	or    = and { "OR" and }
+//	and   = unary { "AND" unary }
+//	unary = "NOT" unary | "(" seq ")" | term
+//
+// Filters are taken out into filters as they are met; a group holding only
+// filters parses to a nil expression.
+type boolParser struct {
+	tokens  []string
+	pos     int
+	filters queryFilters
+}
+
+func (p *boolParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *boolParser) parseSeq() (*boolExpr, error) {
+	var args []*boolExpr
+	for next := p.peek(); next != "" && next != ")"; next = p.peek() {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if arg != nil {
+			args = append(args, arg)
+		}
+	}
+	switch len(args) {
+	case 0:
+		return nil, nil
+	case 1:
+		if !args[0].required {
+			return args[0], nil
+		}
+	}
+	return &boolExpr{kind: exprSeq, args: args}, nil
+}
+
+func (p *boolParser) parseOr() (*boolExpr, error) {
+	var args []*boolExpr
+	for {
+		if p.peek() == "OR" {
+			return nil, fmt.Errorf(`%w: "OR" needs a term before it`, ErrInvalidQuery)
+		}
+		arg, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if arg == nil && (len(args) > 0 || p.peek() == "OR") {
+			return nil, fmt.Errorf(`%w: a filter can't be one side of "OR"; filters apply to the whole query`, ErrInvalidQuery)
+		}
+		args = append(args, arg)
+		if p.peek() != "OR" {
+			break
+		}
+		p.pos++
+		if next := p.peek(); next == "" || next == ")" || next == "OR" || next == "AND" {
+			return nil, fmt.Errorf(`%w: "OR" needs a term after it`, ErrInvalidQuery)
+		}
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	return &boolExpr{kind: exprOr, args: args}, nil
+}
+
+func (p *boolParser) parseAnd() (*boolExpr, error) {
+	var args []*boolExpr
+	for first := true; first || p.peek() == "AND"; first = false {
+		if p.peek() == "AND" {
+			if len(args) == 0 && !p.afterFilter() {
+				return nil, fmt.Errorf(`%w: "AND" needs a term before it`, ErrInvalidQuery)
+			}
+			p.pos++
+			if next := p.peek(); next == "" || next == ")" || next == "OR" || next == "AND" {
+				return nil, fmt.Errorf(`%w: "AND" needs a term after it`, ErrInvalidQuery)
+			}
+		}
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if arg != nil {
+			args = append(args, arg)
+		}
+	}
+	switch len(args) {
+	case 0:
+		return nil, nil
+	case 1:
+		return args[0], nil
+	}
+	for _, a := range args {
+		// Every arg of an AND is required anyway.
+		a.required = false
+	}
+	return &boolExpr{kind: exprAnd, args: args}, nil
+}
+
+// afterFilter reports whether the token before the current one is a filter,
+// so "source:pdf AND go" isn't taken for a missing term.
+func (p *boolParser) afterFilter() bool {
+	if p.pos == 0 {
+		return false
+	}
+	var f queryFilters
+	return f.addFilter(p.tokens[p.pos-1])
+}
+
+func (p *boolParser) parseUnary() (*boolExpr, error) {
+	tok := p.peek()
+	p.pos++
+	switch tok {
+	case "NOT":
+		switch p.peek() {
+		case "", ")", "OR", "AND":
+			return nil, fmt.Errorf(`%w: "NOT" needs a term after it`, ErrInvalidQuery)
+		}
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if arg == nil {
+			return nil, fmt.Errorf(`%w: "NOT" can't apply to a filter; filters apply to the whole query`, ErrInvalidQuery)
+		}
+		return &boolExpr{kind: exprNot, args: []*boolExpr{arg}}, nil
+	case "(":
+		if p.peek() == ")" {
+			return nil, fmt.Errorf(`%w: empty parentheses "()"`, ErrInvalidQuery)
+		}
+		expr, err := p.parseSeq()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf(`%w: missing ")" to close a "("`, ErrInvalidQuery)
+		}
+		p.pos++
+		return expr, nil
+	}
+	if p.filters.addFilter(tok) {
+		return nil, nil
+	}
+	if rest, ok := strings.CutPrefix(tok, "-"); ok && rest != "" {
+		return &boolExpr{kind: exprNot, args: []*boolExpr{{kind: exprTerm, term: rest}}}, nil
+	}
+	if rest, ok := strings.CutPrefix(tok, "+"); ok && rest != "" {
+		return &boolExpr{kind: exprTerm, term: rest, required: true}, nil
+	}
+	return &boolExpr{kind: exprTerm, term: tok}, nil
+}
+
+// tagField rewrites a "tag:" term to the tags field it searches.
+func tagField(tok string) string {
+	if tag, ok := strings.CutPrefix(tok, "tag:"); ok {
+		return "tags:" + tag
+	}
+	return tok
+}
+
+// bleveQuery returns the Bleve query for the expression.
+func (e *boolExpr) bleveQuery() query.Query {
+	switch e.kind {
+	case exprAnd, exprNot:
+		args := e.args
+		if e.kind == exprNot {
+			args = []*boolExpr{e}
+		}
+		bq := bleve.NewBooleanQuery()
+		hasMust := false
+		for _, a := range args {
+			if a.kind == exprNot {
+				bq.AddMustNot(a.args[0].bleveQuery())
+			} else {
+				bq.AddMust(a.bleveQuery())
+				hasMust = true
+			}
+		}
+		if !hasMust {
+			// Bleve matches nothing with only excluded terms.
+			bq.AddMust(bleve.NewMatchAllQuery())
+		}
+		return bq
+	case exprOr:
+		qs := make([]query.Query, len(e.args))
+		for i, a := range e.args {
+			qs[i] = a.bleveQuery()
+		}
+		return bleve.NewDisjunctionQuery(qs...)
+	case exprSeq:
+		bq := bleve.NewBooleanQuery()
+		hasMatch := false
+		for _, a := range e.args {
+			switch {
+			case a.kind == exprNot:
+				bq.AddMustNot(a.args[0].bleveQuery())
+			case a.required:
+				bq.AddMust(a.bleveQuery())
+				hasMatch = true
+			default:
+				bq.AddShould(a.bleveQuery())
+				hasMatch = true
+			}
+		}
+		if !hasMatch {
+			bq.AddMust(bleve.NewMatchAllQuery())
+		}
+		return bq
+	default:
+		return bleve.NewQueryStringQuery(tagField(e.term))
+	}
+}
+
+// ftsMatch returns the FTS5 MATCH expression for the expression, or "" for
+// every document. FTS5 can't express "everything except", so a NOT that
+// isn't ANDed with a term matches every document.
+func (e *boolExpr) ftsMatch() string {
+	switch e.kind {
+	case exprAnd:
+		var must, not []string
+		for _, a := range e.args {
+			if a.kind == exprNot {
+				if m := a.args[0].ftsMatch(); m != "" {
+					not = append(not, m)
+				}
+			} else if m := a.ftsMatch(); m != "" {
+				must = append(must, m)
+			}
+		}
+		if len(must) == 0 {
+			return ""
+		}
+		match := "(" + strings.Join(must, " AND ") + ")"
+		if len(not) > 0 {
+			match = "(" + match + " NOT (" + strings.Join(not, " OR ") + "))"
+		}
+		return match
+	case exprOr:
+		var alts []string
+		for _, a := range e.args {
+			m := a.ftsMatch()
+			if m == "" {
+				return ""
+			}
+			alts = append(alts, m)
+		}
+		return "(" + strings.Join(alts, " OR ") + ")"
+	case exprSeq:
+		// As in Bleve, optional args only matter when nothing is required.
+		var must, should, not []string
+		everything := false
+		for _, a := range e.args {
+			switch m := a.ftsMatch(); {
+			case a.kind == exprNot:
+				if m := a.args[0].ftsMatch(); m != "" {
+					not = append(not, m)
+				}
+			case a.required:
+				if m != "" {
+					must = append(must, m)
+				}
+			case m == "":
+				everything = true
+			default:
+				should = append(should, m)
+			}
+		}
+		var match string
+		switch {
+		case len(must) > 0:
+			match = "(" + strings.Join(must, " AND ") + ")"
+		case len(should) > 0 && !everything:
+			match = "(" + strings.Join(should, " OR ") + ")"
+		default:
+			return ""
+		}
+		if len(not) > 0 {
+			match = "(" + match + " NOT (" + strings.Join(not, " OR ") + "))"
+		}
+		return match
+	case exprNot:
+		return ""
+	default:
+		return ftsTerm(tagField(e.term))
+	}
+}
+
+// MatchTerms returns the terms of queryStr that matching documents contain,
+// such as for embedding the query: without its filters, boolean operators,
+// parentheses and excluded or negated terms. A query that doesn't parse
+// keeps all its words except the operators.
+func MatchTerms(queryStr string) string {
+	q, err := parseQuery(queryStr)
+	if err != nil {
+		var words []string
+		for _, tok := range lexQuery(queryStr) {
+			if !isBoolean([]string{tok}) && tok != "(" && tok != ")" {
+				words = append(words, tok)
+			}
+		}
+		return strings.Join(words, " ")
+	}
+	var words []string
+	for _, tok := range q.terms {
+		if strings.HasPrefix(tok, "-") && len(tok) > 1 {
+			continue
+		}
+		words = append(words, strings.TrimPrefix(tok, "+"))
+	}
+	var walk func(e *boolExpr)
+	walk = func(e *boolExpr) {
+		switch {
+		case e == nil || e.kind == exprNot:
+		case e.kind == exprTerm:
+			words = append(words, e.term)
+		default:
+			for _, a := range e.args {
+				walk(a)
+			}
+		}
+	}
+	walk(q.expr)
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,164 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestBuildFTSQuery_Boolean(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantMatch string
+	}{
+		{"rust OR go", `("rust" OR "go")`},
+		{"(rust OR go) AND concurrency", `(("rust" OR "go") AND "concurrency")`},
+		{"(rust OR go) concurrency", `(("rust" OR "go") OR "concurrency")`},
+		{"(rust OR go) +concurrency", `("concurrency")`},
+		{"go OR rust -python", `((("go" OR "rust")) NOT ("python"))`},
+		{"go AND NOT python", `(("go") NOT ("python"))`},
+		{"(go -python) OR tag:rust", `((("go") NOT ("python")) OR tags : "rust")`},
+		{`"error handling" OR title:go*`, `("error handling" OR title : "go"*)`},
+		{"NOT python", ""},
+		{"go OR NOT python", ""},
+		{"(go OR rust) source:markdown", `("go" OR "rust")`},
+	}
+	for _, tt := range tests {
+		match, _, err := buildFTSQuery(tt.query)
+		if err != nil {
+			t.Errorf("buildFTSQuery(%q): %v", tt.query, err)
+			continue
+		}
+		if match != tt.wantMatch {
+			t.Errorf("buildFTSQuery(%q) = %q, want %q", tt.query, match, tt.wantMatch)
+		}
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{"rust OR", `"OR" needs a term after it`},
+		{"OR rust", `"OR" needs a term before it`},
+		{"rust AND", `"AND" needs a term after it`},
+		{"AND rust", `"AND" needs a term before it`},
+		{"rust NOT", `"NOT" needs a term after it`},
+		{"(rust OR go", `missing ")"`},
+		{"rust OR go)", `unexpected ")"`},
+		{"rust OR ()", "empty parentheses"},
+		{"rust OR source:pdf", "filters apply to the whole query"},
+		{"rust NOT source:pdf", "filters apply to the whole query"},
+	}
+	for _, tt := range tests {
+		_, err := parseQuery(tt.query)
+		if !errors.Is(err, ErrInvalidQuery) || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseQuery(%q) error = %v, want one saying %s", tt.query, err, tt.wantErr)
+		}
+	}
+
+	// Filters may sit next to operators and in groups.
+	for _, q := range []string{"source:pdf AND go", "(source:pdf) go OR rust", "go (path:/notes minsize:1kb)"} {
+		if _, err := parseQuery(q); err != nil {
+			t.Errorf("parseQuery(%q): %v", q, err)
+		}
+	}
+}
+
+func TestMatchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"go channels", "go channels"},
+		{"go +channels -python source:pdf", "go channels"},
+		{"(rust OR go) AND NOT (python OR java) after:2024-01-01", "rust go"},
+		{`"error handling" -"panic"`, `"error handling"`},
+		{"(rust OR", "rust"},
+		{"what is go (the language)?", "what is go the language"},
+	}
+	for _, tt := range tests {
+		if got := MatchTerms(tt.query); got != tt.want {
+			t.Errorf("MatchTerms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestBooleanSearch(t *testing.T) {
+	backends := map[string]func(t *testing.T) Backend{
+		"bleve": func(t *testing.T) Backend {
+			idx, err := NewBleveIndex(filepath.Join(t.TempDir(), "test.bleve"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return idx
+		},
+		"fts5": func(t *testing.T) Backend {
+			idx, err := NewFTSIndex(filepath.Join(t.TempDir(), "test.db"))
+			if errors.Is(err, ErrFTS5Unavailable) {
+				t.Skip("sqlite built without FTS5")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			return idx
+		},
+	}
+	docs := []*storage.Document{
+		{ID: "rust", Source: storage.SourceMarkdown, Path: "/notes/rust.md", Title: "Rust", Content: "rust concurrency with threads"},
+		{ID: "go", Source: storage.SourceMarkdown, Path: "/notes/go.md", Title: "Go", Content: "go concurrency with channels"},
+		{ID: "gopy", Source: storage.SourcePDF, Path: "/papers/go.pdf", Title: "Go and Python", Content: "go and python interop"},
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"rust OR python", "gopy,rust"},
+		{"(rust OR go) AND concurrency", "go,rust"},
+		{"go NOT python", "go"},
+		{"go -python", "go"},
+		{"(rust OR go) source:pdf", "gopy"},
+		{"concurrency AND NOT (threads OR interop)", "go"},
+		{"interop rust OR threads", "gopy,rust"},
+		{"python (rust OR go) AND threads", "gopy,rust"},
+		// Without AND, OR or NOT parentheses are just text.
+		{"what is rust (the language)?", "rust"},
+		{"python :)", "gopy"},
+		{"(python", "gopy"},
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			idx := open(t)
+			defer func() { _ = idx.Close() }()
+			ctx := context.Background()
+			for _, d := range docs {
+				if err := idx.Index(ctx, d); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, tt := range tests {
+				results, err := idx.Search(ctx, tt.query, 10)
+				if err != nil {
+					t.Fatalf("Search(%q): %v", tt.query, err)
+				}
+				var ids []string
+				for _, r := range results {
+					ids = append(ids, r.ID)
+				}
+				sort.Strings(ids)
+				if got := strings.Join(ids, ","); got != tt.want {
+					t.Errorf("Search(%q) = %s, want %s", tt.query, got, tt.want)
+				}
+			}
+			if _, err := idx.Search(ctx, "(rust OR go", 10); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("Search() with an open parenthesis error = %v, want ErrInvalidQuery", err)
+			}
+		})
+	}
+}
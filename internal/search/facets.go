@@ -0,0 +1,106 @@
+package search
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// Fields that facets count, as named in the Bleve mapping.
+const (
+	FieldTagList = "tag_list" // each tag of a document as a keyword
+	FieldMonth   = "month"    // the month of the document's date, as YYYY-MM
+)
+
+const (
+	// monthLayout formats the months that facets count.
+	monthLayout = "2006-01"
+	// maxFacetMonths caps the months counted before the newest are kept.
+	maxFacetMonths = 1200
+)
+
+// FacetCount is the number of matching documents with a value of a field.
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+// Facets are the counts of the documents matching a query, by source, tag
+// and month, showing how the matches are spread before narrowing them.
+type Facets struct {
+	Total   int          // documents matching the query
+	Sources []FacetCount // most matches first
+	Tags    []FacetCount // most matches first
+	Months  []FacetCount // newest first
+}
+
+// splitTags returns the tags of a document's comma-separated "tags"
+// metadata.
+func splitTags(tags string) []string {
+	var out []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// docMonth returns the month a document is counted in.
+func docMonth(doc *storage.Document) string {
+	return monthOf(doc.Date())
+}
+
+func monthOf(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(monthLayout)
+}
+
+// facetCounter counts values of a field, keeping the size most common or,
+// for months, the size newest.
+type facetCounter map[string]int
+
+func (c facetCounter) add(value string) {
+	if value != "" {
+		c[value]++
+	}
+}
+
+// top returns the size values with the most matches, ties by value.
+func (c facetCounter) top(size int) []FacetCount {
+	counts := c.counts()
+	slices.SortFunc(counts, func(a, b FacetCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return cmp.Compare(a.Value, b.Value)
+	})
+	return limitFacets(counts, size)
+}
+
+// newest returns the size greatest values, which for months are the newest.
+func (c facetCounter) newest(size int) []FacetCount {
+	counts := c.counts()
+	slices.SortFunc(counts, func(a, b FacetCount) int { return cmp.Compare(b.Value, a.Value) })
+	return limitFacets(counts, size)
+}
+
+func (c facetCounter) counts() []FacetCount {
+	counts := make([]FacetCount, 0, len(c))
+	for value, n := range c {
+		counts = append(counts, FacetCount{Value: value, Count: n})
+	}
+	return counts
+}
+
+func limitFacets(counts []FacetCount, size int) []FacetCount {
+	if size > 0 && len(counts) > size {
+		counts = counts[:size]
+	}
+	return counts
+}
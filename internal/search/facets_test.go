@@ -0,0 +1,103 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestFacets(t *testing.T) {
+	backends := map[string]func(t *testing.T) Backend{
+		"bleve": func(t *testing.T) Backend {
+			idx, err := NewBleveIndex(filepath.Join(t.TempDir(), "test.bleve"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return idx
+		},
+		"fts5": func(t *testing.T) Backend {
+			idx, err := NewFTSIndex(filepath.Join(t.TempDir(), "test.db"))
+			if errors.Is(err, ErrFTS5Unavailable) {
+				t.Skip("sqlite built without FTS5")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			return idx
+		},
+	}
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 12, 0, 0, 0, time.Local) }
+	tags := func(t string) map[string]string { return map[string]string{"tags": t} }
+	docs := []*storage.Document{
+		{ID: "a", Source: storage.SourceMarkdown, Path: "/notes/a.md", Title: "A", Content: "kubernetes deploy",
+			ModifiedAt: day(2024, 1, 15), Metadata: tags("ops, k8s")},
+		{ID: "b", Source: storage.SourceMarkdown, Path: "/notes/b.md", Title: "B", Content: "kubernetes upgrade",
+			ModifiedAt: day(2024, 3, 1), Metadata: tags("ops")},
+		{ID: "c", Source: storage.SourcePDF, Path: "/docs/c.pdf", Title: "C", Content: "kubernetes book",
+			ModifiedAt: day(2024, 3, 9)},
+		{ID: "d", Source: storage.SourceEmail, Path: "/mail/d", Title: "D", Content: "lunch plans",
+			ModifiedAt: day(2024, 2, 2), Metadata: tags("ops")},
+	}
+	tests := []struct {
+		query, sources, tags, months string
+		size, total                  int
+	}{
+		{"kubernetes", "markdown:2 pdf:1", "ops:2 k8s:1", "2024-03:2 2024-01:1", 10, 3},
+		{"kubernetes", "markdown:2", "ops:2", "2024-03:2", 1, 3},
+		{"kubernetes source:pdf", "pdf:1", "", "2024-03:1", 10, 1},
+		{"", "markdown:2 email:1 pdf:1", "ops:3 k8s:1", "2024-03:2 2024-02:1 2024-01:1", 10, 4},
+		{"nothing", "", "", "", 10, 0},
+	}
+	show := func(counts []FacetCount) string {
+		var s []string
+		for _, c := range counts {
+			s = append(s, fmt.Sprintf("%s:%d", c.Value, c.Count))
+		}
+		return strings.Join(s, " ")
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			idx := open(t)
+			defer func() { _ = idx.Close() }()
+			ctx := context.Background()
+			for _, d := range docs {
+				if err := idx.Index(ctx, d); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, tt := range tests {
+				f, err := idx.Facets(ctx, tt.query, tt.size)
+				if err != nil {
+					t.Fatalf("Facets(%q): %v", tt.query, err)
+				}
+				if f.Total != tt.total {
+					t.Errorf("Facets(%q).Total = %d, want %d", tt.query, f.Total, tt.total)
+				}
+				if got := show(f.Sources); got != tt.sources {
+					t.Errorf("Facets(%q, %d).Sources = %q, want %q", tt.query, tt.size, got, tt.sources)
+				}
+				if got := show(f.Tags); got != tt.tags {
+					t.Errorf("Facets(%q, %d).Tags = %q, want %q", tt.query, tt.size, got, tt.tags)
+				}
+				if got := show(f.Months); got != tt.months {
+					t.Errorf("Facets(%q, %d).Months = %q, want %q", tt.query, tt.size, got, tt.months)
+				}
+			}
+			if _, err := idx.Facets(ctx, "(go OR", 10); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("Facets(%q) error = %v, want ErrInvalidQuery", "(go OR", err)
+			}
+		})
+	}
+}
+
+func TestSplitTags(t *testing.T) {
+	if got := strings.Join(splitTags(" ops, k8s,,ops "), "|"); got != "ops|k8s" {
+		t.Errorf("splitTags = %q, want %q", got, "ops|k8s")
+	}
+}
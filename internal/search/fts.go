@@ -0,0 +1,402 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/J-1000/mindcli/internal/storage"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ErrFTS5Unavailable is returned by NewFTSIndex when the SQLite library was
+// built without FTS5 (build mindcli with -tags sqlite_fts5).
+var ErrFTS5Unavailable = errors.New("sqlite was built without FTS5 support (build with -tags sqlite_fts5)")
+
+// ftsColumns are the searchable columns of documents_fts, in table order
+// after id and source. Field prefixes in queries (title:go) map onto them.
+var ftsColumns = []string{"title", "content", "tags", "headings"}
+
+// ftsSchema creates documents_fts. path and the range filter columns come
+// last so that column numbers used by bm25() and snippet() stay the same as
+// before they were added. Dates are Unix seconds.
+const ftsSchema = `CREATE VIRTUAL TABLE IF NOT EXISTS documents_fts USING fts5(
+	id UNINDEXED,
+	source UNINDEXED,
+	title,
+	content,
+	tags,
+	headings,
+	path UNINDEXED,
+	document_date UNINDEXED,
+	modified_at UNINDEXED,
+	size UNINDEXED,
+	visit_count UNINDEXED,
+	tokenize = 'unicode61 remove_diacritics 2'
+)`
+
+// FTSIndex is a full-text index stored as an FTS5 table inside the main
+// SQLite database, so no separate on-disk index is needed.
+type FTSIndex struct {
+	db *sql.DB
+}
+
+// NewFTSIndex opens the SQLite database at dbPath and creates the FTS5 table
+// if needed. The table lives outside the versioned migrations because FTS5
+// support depends on how SQLite was built.
+func NewFTSIndex(dbPath string) (*FTSIndex, error) {
+	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(ftsSchema)
+	if err == nil {
+		err = upgradeFTSTable(db)
+	}
+	if err == nil {
+		_, err = db.Exec(ftsVocabSchema)
+	}
+	if err != nil {
+		_ = db.Close()
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			return nil, ErrFTS5Unavailable
+		}
+		return nil, fmt.Errorf("creating fts table: %w", err)
+	}
+
+	return &FTSIndex{db: db}, nil
+}
+
+// ftsVocabSchema exposes the terms of documents_fts, with the number of
+// documents containing each, for spelling suggestions.
+const ftsVocabSchema = `CREATE VIRTUAL TABLE IF NOT EXISTS documents_fts_vocab USING fts5vocab(documents_fts, row)`
+
+// upgradeFTSTable recreates a documents_fts table from before path or range
+// filters, which lacks their columns. FTS5 tables can't be altered, so the
+// table starts out empty and is refilled by the next reindex.
+func upgradeFTSTable(db *sql.DB) error {
+	if _, err := db.Exec(`SELECT path, visit_count FROM documents_fts LIMIT 0`); err == nil {
+		return nil
+	}
+	if _, err := db.Exec(`DROP TABLE documents_fts`); err != nil {
+		return err
+	}
+	_, err := db.Exec(ftsSchema)
+	return err
+}
+
+// Index adds or updates a document in the index.
+func (f *FTSIndex) Index(ctx context.Context, doc *storage.Document) error {
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("indexing document: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM documents_fts WHERE id = ?`, doc.ID); err != nil {
+		return fmt.Errorf("indexing document: %w", err)
+	}
+	var visits any // NULL for documents without visits, so no bound matches them
+	if n, err := strconv.Atoi(doc.Metadata["visit_count"]); err == nil {
+		visits = n
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO documents_fts (id, source, title, content, tags, headings, path, document_date, modified_at, size, visit_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		doc.ID, string(doc.Source), doc.Title, doc.Content, doc.Metadata["tags"], doc.Metadata["headings"], doc.Path,
+		doc.Date().Unix(), doc.ModifiedAt.Unix(), len(doc.Content), visits,
+	)
+	if err != nil {
+		return fmt.Errorf("indexing document: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Delete removes a document from the index.
+func (f *FTSIndex) Delete(ctx context.Context, id string) error {
+	if _, err := f.db.ExecContext(ctx, `DELETE FROM documents_fts WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("deleting document: %w", err)
+	}
+	return nil
+}
+
+// Search performs a full-text search ranked by BM25. It accepts the same
+// query syntax as BleveIndex: source: and tag: filters, field prefixes,
+// "quoted phrases", +required and -excluded terms, trailing * prefixes, and
+// AND, OR and NOT with parentheses.
+func (f *FTSIndex) Search(ctx context.Context, queryStr string, limit int) ([]SearchResult, error) {
+	match, filters, err := buildFTSQuery(queryStr)
+	if err != nil {
+		return nil, err
+	}
+	where, args := ftsWhere(match, filters)
+
+	sqlQuery := `SELECT id, 1.0, '', '' FROM documents_fts`
+	if match != "" {
+		// bm25() is lower-is-better; weights follow the column order.
+		sqlQuery = `SELECT id, -bm25(documents_fts, 0, 0, 2.0, 1.0, 1.5, 1.5),
+			snippet(documents_fts, 2, '<mark>', '</mark>', '…', 12),
+			snippet(documents_fts, 3, '<mark>', '</mark>', '…', 24)
+			FROM documents_fts`
+	}
+	if len(where) > 0 {
+		sqlQuery += " WHERE " + strings.Join(where, " AND ")
+	}
+	if match != "" {
+		sqlQuery += " ORDER BY bm25(documents_fts, 0, 0, 2.0, 1.0, 1.5, 1.5)"
+	}
+	sqlQuery += " LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := f.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []SearchResult
+	for rows.Next() {
+		var sr SearchResult
+		var title, content string
+		if err := rows.Scan(&sr.ID, &sr.Score, &title, &content); err != nil {
+			return nil, fmt.Errorf("scanning result: %w", err)
+		}
+		sr.Highlights = make(map[string][]string)
+		if strings.Contains(title, "<mark>") {
+			sr.Highlights["title"] = []string{title}
+		}
+		if strings.Contains(content, "<mark>") {
+			sr.Highlights["content"] = []string{content}
+		}
+		results = append(results, sr)
+	}
+	return results, rows.Err()
+}
+
+// ftsWhere returns the conditions, to be ANDed, and their arguments that
+// select the documents matching match and filters.
+func ftsWhere(match string, filters queryFilters) (where []string, args []any) {
+	sources, dirs, ranges := filters.sources, filters.dirs, filters.ranges
+	if match != "" {
+		where = append(where, "documents_fts MATCH ?")
+		args = append(args, match)
+	}
+	if len(sources) > 0 {
+		where = append(where, "source IN (?"+strings.Repeat(", ?", len(sources)-1)+")")
+		for _, s := range sources {
+			args = append(args, s)
+		}
+	}
+	if len(dirs) > 0 {
+		var under []string
+		for _, dir := range dirs {
+			below := strings.TrimSuffix(dir, "/") + "/"
+			under = append(under, "path = ? OR substr(path, 1, length(?)) = ?")
+			args = append(args, dir, below, below)
+		}
+		where = append(where, "("+strings.Join(under, " OR ")+")")
+	}
+	for _, r := range ranges {
+		op := "<="
+		switch {
+		case r.Lower:
+			op = ">="
+		case r.IsDate():
+			op = "<"
+		}
+		where = append(where, r.Field+" "+op+" ?")
+		args = append(args, r.Bound)
+	}
+	return where, args
+}
+
+// Facets counts the documents matching queryStr by source, tag and month,
+// keeping the size most common sources and tags and the size newest months.
+func (f *FTSIndex) Facets(ctx context.Context, queryStr string, size int) (*Facets, error) {
+	match, filters, err := buildFTSQuery(queryStr)
+	if err != nil {
+		return nil, err
+	}
+	where, args := ftsWhere(match, filters)
+	sqlQuery := `SELECT source, tags, document_date FROM documents_fts`
+	if len(where) > 0 {
+		sqlQuery += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	rows, err := f.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("counting facets: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	facets := &Facets{}
+	sources, tags, months := make(facetCounter), make(facetCounter), make(facetCounter)
+	for rows.Next() {
+		var source, tagList string
+		var date sql.NullInt64
+		if err := rows.Scan(&source, &tagList, &date); err != nil {
+			return nil, fmt.Errorf("scanning facets: %w", err)
+		}
+		facets.Total++
+		sources.add(source)
+		for _, tag := range splitTags(tagList) {
+			tags.add(tag)
+		}
+		if date.Valid {
+			months.add(monthOf(time.Unix(date.Int64, 0)))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("counting facets: %w", err)
+	}
+	facets.Sources = sources.top(size)
+	facets.Tags = tags.top(size)
+	facets.Months = months.newest(size)
+	return facets, nil
+}
+
+// buildFTSQuery translates a search string into an FTS5 MATCH expression,
+// and the sources, directories and ranges to filter by, if any. An empty
+// expression matches every document.
+func buildFTSQuery(queryStr string) (match string, filters queryFilters, err error) {
+	parsed, err := parseQuery(queryStr)
+	if err != nil {
+		return "", queryFilters{}, err
+	}
+	if parsed.expr != nil {
+		return parsed.expr.ftsMatch(), parsed.queryFilters, nil
+	}
+
+	var should, must, not []string
+	for _, tok := range parsed.terms {
+		list := &should
+		if strings.HasPrefix(tok, "+") {
+			list, tok = &must, tok[1:]
+		} else if strings.HasPrefix(tok, "-") {
+			list, tok = &not, tok[1:]
+		}
+		if term := ftsTerm(tagField(tok)); term != "" {
+			*list = append(*list, term)
+		}
+	}
+
+	// As in Bleve, optional terms only matter when nothing is required.
+	switch {
+	case len(must) > 0:
+		match = strings.Join(must, " AND ")
+	case len(should) > 0:
+		match = strings.Join(should, " OR ")
+	default:
+		// FTS5 cannot express "everything except": fall back to all.
+		return "", parsed.queryFilters, nil
+	}
+	if len(not) > 0 {
+		match = "(" + match + ") NOT (" + strings.Join(not, " OR ") + ")"
+	}
+	return match, parsed.queryFilters, nil
+}
+
+// ftsTerm quotes a single query token for FTS5, keeping a known column
+// prefix and a trailing * for prefix matches.
+func ftsTerm(tok string) string {
+	column := ""
+	if field, rest, ok := strings.Cut(tok, ":"); ok {
+		for _, c := range ftsColumns {
+			if field == c {
+				column, tok = c+" : ", rest
+				break
+			}
+		}
+	}
+
+	prefix := strings.HasSuffix(tok, "*")
+	tok = strings.Trim(strings.TrimSuffix(tok, "*"), `"`)
+	if strings.TrimSpace(tok) == "" {
+		return ""
+	}
+
+	term := column + `"` + strings.ReplaceAll(tok, `"`, `""`) + `"`
+	if prefix {
+		term += "*"
+	}
+	return term
+}
+
+// SimilarTerms returns indexed terms close to term, from the vocabulary of
+// the whole table.
+func (f *FTSIndex) SimilarTerms(ctx context.Context, term string, limit int) ([]string, error) {
+	term = normalizeTerm(term)
+	if term == "" {
+		return nil, nil
+	}
+	edits := maxEdits(term)
+	n := utf8.RuneCountInString(term)
+	rows, err := f.db.QueryContext(ctx,
+		`SELECT term, doc FROM documents_fts_vocab WHERE length(term) BETWEEN ? AND ?`, n-edits, n+edits)
+	if err != nil {
+		return nil, fmt.Errorf("reading terms: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var matches []termMatch
+	for rows.Next() {
+		var m termMatch
+		if err := rows.Scan(&m.term, &m.docs); err != nil {
+			return nil, fmt.Errorf("reading terms: %w", err)
+		}
+		if m.distance = editDistance(term, m.term); m.distance <= edits {
+			matches = append(matches, m)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading terms: %w", err)
+	}
+	return rankTerms(matches, limit), nil
+}
+
+// Count returns the total number of documents in the index.
+func (f *FTSIndex) Count() (uint64, error) {
+	var n uint64
+	if err := f.db.QueryRow(`SELECT COUNT(*) FROM documents_fts`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting documents: %w", err)
+	}
+	return n, nil
+}
+
+// IDs returns the IDs of all documents in the index.
+func (f *FTSIndex) IDs(ctx context.Context) ([]string, error) {
+	rows, err := f.db.QueryContext(ctx, `SELECT id FROM documents_fts`)
+	if err != nil {
+		return nil, fmt.Errorf("listing documents: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("listing documents: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Compact merges the FTS5 index's b-trees into one. The space it frees is
+// returned to the file system by vacuuming the database it lives in.
+func (f *FTSIndex) Compact(ctx context.Context) error {
+	if _, err := f.db.ExecContext(ctx, `INSERT INTO documents_fts(documents_fts) VALUES('optimize')`); err != nil {
+		return fmt.Errorf("optimizing fts index: %w", err)
+	}
+	return nil
+}
+
+// Close closes the index's database connection.
+func (f *FTSIndex) Close() error {
+	return f.db.Close()
+}
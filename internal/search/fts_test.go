@@ -0,0 +1,179 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestBuildFTSQuery(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantMatch  string
+		wantSource string
+	}{
+		{"", "", ""},
+		{"go concurrency", `"go" OR "concurrency"`, ""},
+		{"go source:markdown", `"go"`, "markdown"},
+		{"source:pdf", "", "pdf"},
+		{"go source:markdown,pdf", `"go"`, "markdown,pdf"},
+		{"source:pdf source:email", "", "pdf,email"},
+		{"tag:work meeting", `tags : "work" OR "meeting"`, ""},
+		{"title:go*", `title : "go"*`, ""},
+		{`"error handling" go`, `"error handling" OR "go"`, ""},
+		{"+go +channels select", `"go" AND "channels"`, ""},
+		{"go -python", `("go") NOT ("python")`, ""},
+		{`say "hi"there`, `"say" OR "hi""there"`, ""},
+		{"http://example.com", `"http://example.com"`, ""},
+	}
+	for _, tt := range tests {
+		match, filters, err := buildFTSQuery(tt.query)
+		if err != nil {
+			t.Fatalf("buildFTSQuery(%q): %v", tt.query, err)
+		}
+		if source := strings.Join(filters.sources, ","); match != tt.wantMatch || source != tt.wantSource {
+			t.Errorf("buildFTSQuery(%q) = (%q, %q), want (%q, %q)", tt.query, match, source, tt.wantMatch, tt.wantSource)
+		}
+	}
+}
+
+func TestFTSIndex_BasicOperations(t *testing.T) {
+	idx, err := NewFTSIndex(filepath.Join(t.TempDir(), "test.db"))
+	if errors.Is(err, ErrFTS5Unavailable) {
+		t.Skip("sqlite built without FTS5")
+	}
+	if err != nil {
+		t.Fatalf("creating index: %v", err)
+	}
+	defer func() {
+		if err := idx.Close(); err != nil {
+			t.Errorf("closing index: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	now := time.Now()
+	docs := []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Title: "Go Programming Guide",
+			Content: "Go is a statically typed language with goroutines and channels.", ModifiedAt: now},
+		{ID: "2", Source: storage.SourceMarkdown, Title: "Python Basics",
+			Content: "Python is a dynamically typed language.", Metadata: map[string]string{"tags": "work"}, ModifiedAt: now},
+		{ID: "3", Source: storage.SourcePDF, Title: "Channels in Depth",
+			Content: "Buffered and unbuffered channels in Go.", ModifiedAt: now},
+	}
+	for _, doc := range docs {
+		if err := idx.Index(ctx, doc); err != nil {
+			t.Fatalf("indexing %s: %v", doc.ID, err)
+		}
+	}
+	// Re-indexing replaces rather than duplicates.
+	if err := idx.Index(ctx, docs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := idx.Count(); err != nil || n != 3 {
+		t.Fatalf("Count() = %d, %v; want 3", n, err)
+	}
+	if ids, err := idx.IDs(ctx); err != nil || len(ids) != 3 {
+		t.Fatalf("IDs() = %v, %v; want 3 IDs", ids, err)
+	}
+
+	results, err := idx.Search(ctx, "channels", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results for channels, want 2", len(results))
+	}
+	if results[0].ID != "3" {
+		t.Errorf("title match should rank first, got %s", results[0].ID)
+	}
+	if len(results[0].Highlights) == 0 {
+		t.Error("expected highlights")
+	}
+
+	results, err = idx.Search(ctx, "channels source:pdf", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "3" {
+		t.Errorf("source filter: got %+v", results)
+	}
+
+	results, err = idx.Search(ctx, "channels source:pdf,email", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "3" {
+		t.Errorf("source list filter: got %+v", results)
+	}
+	results, err = idx.Search(ctx, "language source:markdown,pdf", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Errorf("source list filter: got %d results, want both markdown documents", len(results))
+	}
+
+	results, err = idx.Search(ctx, "tag:work", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "2" {
+		t.Errorf("tag filter: got %+v", results)
+	}
+
+	results, err = idx.Search(ctx, "prog*", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "1" {
+		t.Errorf("prefix search: got %+v", results)
+	}
+
+	if err := idx.Delete(ctx, "3"); err != nil {
+		t.Fatal(err)
+	}
+	results, err = idx.Search(ctx, "channels", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "1" {
+		t.Errorf("after delete: got %+v", results)
+	}
+}
+
+func TestFTSIndex_Compact(t *testing.T) {
+	idx, err := NewFTSIndex(filepath.Join(t.TempDir(), "test.db"))
+	if errors.Is(err, ErrFTS5Unavailable) {
+		t.Skip("sqlite built without FTS5")
+	}
+	if err != nil {
+		t.Fatalf("creating index: %v", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	ctx := context.Background()
+	for _, id := range []string{"1", "2", "3"} {
+		doc := &storage.Document{ID: id, Source: storage.SourceMarkdown, Title: "Note " + id,
+			Content: "compaction note", ModifiedAt: time.Now()}
+		if err := idx.Index(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := idx.Delete(ctx, "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := idx.Compact(ctx); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	results, err := idx.Search(ctx, "compaction", 10)
+	if err != nil || len(results) != 2 {
+		t.Errorf("Search after Compact = %d results, %v; want 2", len(results), err)
+	}
+}
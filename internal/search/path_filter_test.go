@@ -0,0 +1,129 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestPathPrefix(t *testing.T) {
+	tests := []struct {
+		tok    string
+		want   string
+		wantOK bool
+	}{
+		{"path:/notes/alpha", "/notes/alpha", true},
+		{"folder:/notes/alpha/", "/notes/alpha", true},
+		{`path:"/notes/my projects"`, "/notes/my projects", true},
+		{"path:", "", false},
+		{"source:pdf", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := pathPrefix(tt.tok)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("pathPrefix(%q) = %q, %v; want %q, %v", tt.tok, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestPathFilter(t *testing.T) {
+	backends := map[string]func(t *testing.T) Backend{
+		"bleve": func(t *testing.T) Backend {
+			idx, err := NewBleveIndex(filepath.Join(t.TempDir(), "test.bleve"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return idx
+		},
+		"fts5": func(t *testing.T) Backend {
+			idx, err := NewFTSIndex(filepath.Join(t.TempDir(), "test.db"))
+			if errors.Is(err, ErrFTS5Unavailable) {
+				t.Skip("sqlite built without FTS5")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			return idx
+		},
+	}
+	docs := []*storage.Document{
+		{ID: "a", Source: storage.SourceMarkdown, Path: "/notes/projects/alpha/plan.md", Title: "Plan", Content: "roadmap"},
+		{ID: "b", Source: storage.SourceMarkdown, Path: "/notes/projects/alpha2/plan.md", Title: "Plan", Content: "roadmap"},
+		{ID: "c", Source: storage.SourceMarkdown, Path: "/notes/my projects/beta.md", Title: "Beta", Content: "roadmap"},
+		{ID: "d", Source: storage.SourcePDF, Path: "/notes/projects/alpha/spec.pdf", Title: "Spec", Content: "roadmap"},
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"roadmap path:/notes/projects/alpha", "a,d"},
+		{"roadmap folder:/notes/projects/alpha/", "a,d"},
+		{"roadmap path:/notes/projects", "a,b,d"},
+		{`roadmap path:"/notes/my projects"`, "c"},
+		{"path:/notes/projects/alpha source:pdf", "d"},
+		{"roadmap path:/notes/projects/alpha2 path:/notes/my none", "b"},
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			idx := open(t)
+			defer func() { _ = idx.Close() }()
+			ctx := context.Background()
+			for _, d := range docs {
+				if err := idx.Index(ctx, d); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, tt := range tests {
+				results, err := idx.Search(ctx, tt.query, 10)
+				if err != nil {
+					t.Fatalf("Search(%q): %v", tt.query, err)
+				}
+				var ids []string
+				for _, r := range results {
+					ids = append(ids, r.ID)
+				}
+				sort.Strings(ids)
+				if got := strings.Join(ids, ","); got != tt.want {
+					t.Errorf("Search(%q) = %s, want %s", tt.query, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFTSIndexAddsPathColumn(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The table as created before path filters existed.
+	_, err = db.Exec(`CREATE VIRTUAL TABLE documents_fts USING fts5(id UNINDEXED, source UNINDEXED, title, content, tags, headings)`)
+	_ = db.Close()
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			t.Skip("sqlite built without FTS5")
+		}
+		t.Fatal(err)
+	}
+
+	idx, err := NewFTSIndex(dbPath)
+	if err != nil {
+		t.Fatalf("NewFTSIndex() on an old table: %v", err)
+	}
+	defer func() { _ = idx.Close() }()
+	doc := &storage.Document{ID: "a", Source: storage.SourceMarkdown, Path: "/notes/a.md", Title: "A", Content: "roadmap"}
+	if err := idx.Index(context.Background(), doc); err != nil {
+		t.Fatalf("indexing after the upgrade: %v", err)
+	}
+	results, err := idx.Search(context.Background(), "roadmap path:/notes", 10)
+	if err != nil || len(results) != 1 {
+		t.Errorf("path search after the upgrade = %v, %v", results, err)
+	}
+}
@@ -0,0 +1,140 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+// Fields that range filters bound, as named in the Bleve mapping.
+const (
+	FieldDocumentDate = "document_date" // the document's own date, else its modification time
+	FieldModifiedAt   = "modified_at"
+	FieldSize         = "size"        // bytes of text
+	FieldVisitCount   = "visit_count" // visits of browser history
+)
+
+// rangeTerms maps the range filter terms to the field they bound and whether
+// they give its lower bound.
+var rangeTerms = map[string]struct {
+	field string
+	lower bool
+}{
+	"after":           {FieldDocumentDate, true},
+	"before":          {FieldDocumentDate, false},
+	"modified-after":  {FieldModifiedAt, true},
+	"modified-before": {FieldModifiedAt, false},
+	"minsize":         {FieldSize, true},
+	"maxsize":         {FieldSize, false},
+	"minvisits":       {FieldVisitCount, true},
+	"maxvisits":       {FieldVisitCount, false},
+}
+
+// RangeFilter bounds a date or numeric field of documents, from a term such
+// as "after:2024-01-01", "before:2024-02-01" or "minsize:10kb". Dates are
+// held as Unix seconds. A lower bound is inclusive; an upper bound is
+// exclusive for dates, so "before:2024-02-01" ends with January, and
+// inclusive for numbers.
+type RangeFilter struct {
+	Field string
+	Bound float64
+	Lower bool
+}
+
+// ParseRangeFilter parses a range filter term: after:, before:,
+// modified-after: and modified-before: with a YYYY-MM-DD date in local time,
+// minsize: and maxsize: with a size such as 500, 10kb or 2mb, and minvisits:
+// and maxvisits: with a count. ok is false for any other term, or a value
+// that doesn't parse.
+func ParseRangeFilter(tok string) (f RangeFilter, ok bool) {
+	name, value, found := strings.Cut(strings.ToLower(tok), ":")
+	term, known := rangeTerms[name]
+	if !found || !known {
+		return RangeFilter{}, false
+	}
+	f = RangeFilter{Field: term.field, Lower: term.lower}
+	switch term.field {
+	case FieldDocumentDate, FieldModifiedAt:
+		day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return RangeFilter{}, false
+		}
+		f.Bound = float64(day.Unix())
+	case FieldSize:
+		size, ok := parseSize(value)
+		if !ok {
+			return RangeFilter{}, false
+		}
+		f.Bound = size
+	default:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return RangeFilter{}, false
+		}
+		f.Bound = float64(n)
+	}
+	return f, true
+}
+
+// parseSize parses a byte size with an optional b, k(b), m(b) or g(b) unit,
+// in multiples of 1024.
+func parseSize(s string) (float64, bool) {
+	s = strings.TrimSuffix(s, "b")
+	mult := 1.0
+	for unit, m := range map[string]float64{"k": 1 << 10, "m": 1 << 20, "g": 1 << 30} {
+		if rest, ok := strings.CutSuffix(s, unit); ok {
+			s, mult = rest, m
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n * mult, true
+}
+
+// IsDate reports whether the filter bounds a date field.
+func (f RangeFilter) IsDate() bool {
+	return f.Field == FieldDocumentDate || f.Field == FieldModifiedAt
+}
+
+// Time returns a date filter's bound.
+func (f RangeFilter) Time() time.Time {
+	return time.Unix(int64(f.Bound), 0)
+}
+
+// Matches reports whether doc's field is within the bound. A document
+// without the field, such as a note for a visit count, doesn't match.
+func (f RangeFilter) Matches(doc *storage.Document) bool {
+	v, ok := rangeValue(doc, f.Field)
+	switch {
+	case !ok:
+		return false
+	case f.Lower:
+		return v >= f.Bound
+	case f.IsDate():
+		return v < f.Bound
+	default:
+		return v <= f.Bound
+	}
+}
+
+// rangeValue returns the value of a range field of doc, with dates as Unix
+// seconds. ok is false when doc has no such value.
+func rangeValue(doc *storage.Document, field string) (v float64, ok bool) {
+	switch field {
+	case FieldDocumentDate:
+		return float64(doc.Date().Unix()), true
+	case FieldModifiedAt:
+		return float64(doc.ModifiedAt.Unix()), true
+	case FieldSize:
+		return float64(len(doc.Content)), true
+	case FieldVisitCount:
+		n, err := strconv.Atoi(doc.Metadata["visit_count"])
+		return float64(n), err == nil
+	}
+	return 0, false
+}
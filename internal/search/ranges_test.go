@@ -0,0 +1,156 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestParseRangeFilter(t *testing.T) {
+	jan := float64(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local).Unix())
+	tests := []struct {
+		tok  string
+		want RangeFilter
+	}{
+		{"after:2024-01-01", RangeFilter{Field: FieldDocumentDate, Bound: jan, Lower: true}},
+		{"before:2024-01-01", RangeFilter{Field: FieldDocumentDate, Bound: jan}},
+		{"Modified-After:2024-01-01", RangeFilter{Field: FieldModifiedAt, Bound: jan, Lower: true}},
+		{"minsize:10kb", RangeFilter{Field: FieldSize, Bound: 10 << 10, Lower: true}},
+		{"maxsize:1.5M", RangeFilter{Field: FieldSize, Bound: 1.5 * (1 << 20)}},
+		{"maxsize:500", RangeFilter{Field: FieldSize, Bound: 500}},
+		{"minvisits:3", RangeFilter{Field: FieldVisitCount, Bound: 3, Lower: true}},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRangeFilter(tt.tok)
+		if !ok || got != tt.want {
+			t.Errorf("ParseRangeFilter(%q) = %+v, %v; want %+v", tt.tok, got, ok, tt.want)
+		}
+	}
+	for _, tok := range []string{"go", "after:yesterday", "minsize:big", "maxvisits:-1", "author:smith", "after"} {
+		if _, ok := ParseRangeFilter(tok); ok {
+			t.Errorf("ParseRangeFilter(%q) ok, want it rejected", tok)
+		}
+	}
+}
+
+func TestRangeFilter(t *testing.T) {
+	backends := map[string]func(t *testing.T) Backend{
+		"bleve": func(t *testing.T) Backend {
+			idx, err := NewBleveIndex(filepath.Join(t.TempDir(), "test.bleve"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return idx
+		},
+		"fts5": func(t *testing.T) Backend {
+			idx, err := NewFTSIndex(filepath.Join(t.TempDir(), "test.db"))
+			if errors.Is(err, ErrFTS5Unavailable) {
+				t.Skip("sqlite built without FTS5")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			return idx
+		},
+	}
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 12, 0, 0, 0, time.Local) }
+	docs := []*storage.Document{
+		{ID: "a", Source: storage.SourceMarkdown, Path: "/notes/a.md", Title: "A", Content: "roadmap", ModifiedAt: day(2024, 1, 15)},
+		{ID: "b", Source: storage.SourceMarkdown, Path: "/notes/b.md", Title: "B", Content: "roadmap " + strings.Repeat("x", 20<<10),
+			ModifiedAt: day(2024, 3, 1), DocumentDate: day(2024, 1, 31)},
+		{ID: "c", Source: storage.SourceBrowser, Path: "/history", Title: "C", Content: "roadmap", ModifiedAt: day(2024, 2, 1),
+			Metadata: map[string]string{"visit_count": "7"}},
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"roadmap after:2024-01-01 before:2024-02-01", "a,b"},
+		{"roadmap before:2024-01-31", "a"},
+		{"after:2024-02-01", "c"},
+		{"roadmap modified-after:2024-02-15", "b"},
+		{"roadmap minsize:10kb", "b"},
+		{"roadmap maxsize:1kb", "a,c"},
+		{"roadmap minvisits:5", "c"},
+		{"roadmap maxvisits:5", ""},
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			idx := open(t)
+			defer func() { _ = idx.Close() }()
+			ctx := context.Background()
+			for _, d := range docs {
+				if err := idx.Index(ctx, d); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, tt := range tests {
+				results, err := idx.Search(ctx, tt.query, 10)
+				if err != nil {
+					t.Fatalf("Search(%q): %v", tt.query, err)
+				}
+				var ids []string
+				for _, r := range results {
+					ids = append(ids, r.ID)
+				}
+				sort.Strings(ids)
+				if got := strings.Join(ids, ","); got != tt.want {
+					t.Errorf("Search(%q) = %s, want %s", tt.query, got, tt.want)
+				}
+				for _, d := range docs {
+					if matches, found := matchesAll(tt.query, d), strings.Contains(tt.want, d.ID); matches != found {
+						t.Errorf("RangeFilter.Matches(%s) for %q = %v, want %v", d.ID, tt.query, matches, found)
+					}
+				}
+			}
+		})
+	}
+}
+
+// matchesAll reports whether doc passes every range filter in query.
+func matchesAll(query string, doc *storage.Document) bool {
+	for _, tok := range strings.Fields(query) {
+		if r, ok := ParseRangeFilter(tok); ok && !r.Matches(doc) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFTSIndexAddsRangeColumns(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The table as created before range filters existed.
+	_, err = db.Exec(`CREATE VIRTUAL TABLE documents_fts USING fts5(id UNINDEXED, source UNINDEXED, title, content, tags, headings, path UNINDEXED)`)
+	_ = db.Close()
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			t.Skip("sqlite built without FTS5")
+		}
+		t.Fatal(err)
+	}
+
+	idx, err := NewFTSIndex(dbPath)
+	if err != nil {
+		t.Fatalf("NewFTSIndex() on an old table: %v", err)
+	}
+	defer func() { _ = idx.Close() }()
+	doc := &storage.Document{ID: "a", Source: storage.SourceMarkdown, Path: "/notes/a.md", Title: "A", Content: "roadmap"}
+	if err := idx.Index(context.Background(), doc); err != nil {
+		t.Fatalf("indexing after the upgrade: %v", err)
+	}
+	results, err := idx.Search(context.Background(), "roadmap maxsize:1kb", 10)
+	if err != nil || len(results) != 1 {
+		t.Errorf("range search after the upgrade = %v, %v", results, err)
+	}
+}
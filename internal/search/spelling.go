@@ -0,0 +1,91 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// spellFields are the fields whose terms spelling suggestions come from.
+var spellFields = []string{"title", "content"}
+
+// termMatch is an indexed term close to a searched one.
+type termMatch struct {
+	term     string
+	distance int
+	docs     uint64 // documents containing it
+}
+
+// maxEdits returns how many edits a term may be from the one searched for:
+// one for short terms, where two would match almost anything, else two.
+func maxEdits(term string) int {
+	if utf8.RuneCountInString(term) <= 4 {
+		return 1
+	}
+	return 2
+}
+
+// rankTerms orders matches by distance, then by the documents containing
+// them, merging those of the same term from several fields, and returns the
+// first limit terms.
+func rankTerms(matches []termMatch, limit int) []string {
+	best := make(map[string]termMatch)
+	for _, m := range matches {
+		prev, ok := best[m.term]
+		if !ok || m.distance < prev.distance {
+			prev.term, prev.distance = m.term, m.distance
+		}
+		prev.docs += m.docs
+		best[m.term] = prev
+	}
+	ranked := make([]termMatch, 0, len(best))
+	for _, m := range best {
+		ranked = append(ranked, m)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.docs != b.docs {
+			return a.docs > b.docs
+		}
+		return a.term < b.term
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	terms := make([]string, len(ranked))
+	for i, m := range ranked {
+		terms[i] = m.term
+	}
+	return terms
+}
+
+// editDistance returns the Levenshtein distance between a and b, counting
+// runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// normalizeTerm lowercases term, as both backends index text.
+func normalizeTerm(term string) string {
+	return strings.ToLower(strings.TrimSpace(term))
+}
@@ -0,0 +1,88 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/storage"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"go", "go", 0},
+		{"kubernetes", "kuberntes", 1},
+		{"concurency", "concurrency", 1},
+		{"teh", "the", 2},
+		{"café", "cafe", 1},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarTerms(t *testing.T) {
+	backends := map[string]func(t *testing.T) Backend{
+		"bleve": func(t *testing.T) Backend {
+			idx, err := NewBleveIndex(filepath.Join(t.TempDir(), "test.bleve"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return idx
+		},
+		"fts5": func(t *testing.T) Backend {
+			idx, err := NewFTSIndex(filepath.Join(t.TempDir(), "test.db"))
+			if errors.Is(err, ErrFTS5Unavailable) {
+				t.Skip("sqlite built without FTS5")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			return idx
+		},
+	}
+	docs := []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Title: "Kubernetes", Content: "kubernetes deployment notes"},
+		{ID: "2", Source: storage.SourceMarkdown, Title: "Deploys", Content: "kubernetes deployments and rollbacks"},
+		{ID: "3", Source: storage.SourceMarkdown, Title: "Cooking", Content: "the best pasta"},
+	}
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"kubernets", []string{"kubernetes"}},
+		{"Deploymnt", []string{"deployment", "deployments"}},
+		{"pasta", []string{"pasta"}},
+		{"xylophone", nil},
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			idx := open(t)
+			defer func() { _ = idx.Close() }()
+			ctx := context.Background()
+			for _, d := range docs {
+				if err := idx.Index(ctx, d); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, tt := range tests {
+				got, err := idx.SimilarTerms(ctx, tt.term, 2)
+				if err != nil {
+					t.Fatalf("SimilarTerms(%q): %v", tt.term, err)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("SimilarTerms(%q) = %q, want %q", tt.term, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// Vacuum rebuilds the database file without the free pages left by deleted
+// documents and truncates the write-ahead log, shrinking both on disk. It
+// needs about as much free space as the database takes, and blocks writers
+// while it runs.
+func (d *DB) Vacuum(ctx context.Context) error {
+	if _, err := d.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return fmt.Errorf("vacuuming database: %w", err)
+	}
+	if _, err := d.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("truncating write-ahead log: %w", err)
+	}
+	return nil
+}
+
+// ListChunkContents returns the distinct texts of all chunks, which are the
+// texts the embedding cache holds vectors for.
+func (d *DB) ListChunkContents(ctx context.Context) ([]string, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, `SELECT DISTINCT content FROM chunks`)
+	if err != nil {
+		return nil, fmt.Errorf("querying chunks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var contents []string
+	for rows.Next() {
+		var content string
+		if err := rows.Scan(&content); err != nil {
+			return nil, fmt.Errorf("scanning chunk: %w", err)
+		}
+		contents = append(contents, content)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating chunks: %w", err)
+	}
+	return contents, nil
+}
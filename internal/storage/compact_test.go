@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVacuum(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+	now := time.Now()
+	big := strings.Repeat("filler text ", 20000)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		mustSucceed(t, db.InsertDocument(ctx, &Document{ID: id, Source: SourceMarkdown, Path: "/" + id + ".md",
+			Content: big, ContentHash: id, IndexedAt: now, ModifiedAt: now}))
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		mustSucceed(t, db.DeleteDocument(ctx, id))
+	}
+	size := func() int64 {
+		var n int64
+		for _, p := range []string{dbPath, dbPath + "-wal"} {
+			if info, err := os.Stat(p); err == nil {
+				n += info.Size()
+			}
+		}
+		return n
+	}
+	before := size()
+
+	if err := db.Vacuum(ctx); err != nil {
+		t.Fatalf("Vacuum() error = %v", err)
+	}
+	if after := size(); after >= before {
+		t.Errorf("database takes %d bytes after Vacuum, %d before", after, before)
+	}
+	if _, err := db.GetDocument(ctx, "d"); err != nil {
+		t.Errorf("Vacuum lost a document: %v", err)
+	}
+}
+
+func TestListChunkContents(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+	now := time.Now()
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "doc", Source: SourceMarkdown, Path: "/doc.md", ContentHash: "h", IndexedAt: now, ModifiedAt: now}))
+	for i, content := range []string{"first", "second", "first"} {
+		mustSucceed(t, db.InsertChunk(ctx, &Chunk{ID: "doc:" + string(rune('0'+i)), DocumentID: "doc", Content: content}))
+	}
+
+	contents, err := db.ListChunkContents(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(contents)
+	if got := strings.Join(contents, ","); got != "first,second" {
+		t.Errorf("ListChunkContents() = %s, want first,second", got)
+	}
+}
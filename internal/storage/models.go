@@ -3,20 +3,67 @@ package storage
 
 import (
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 )
 
 // Source represents the type of document source.
 type Source string
 
 const (
-	SourceMarkdown  Source = "markdown"
-	SourcePDF       Source = "pdf"
-	SourceEmail     Source = "email"
-	SourceBrowser   Source = "browser"
-	SourceClipboard Source = "clipboard"
+	SourceMarkdown   Source = "markdown"
+	SourcePDF        Source = "pdf"
+	SourceEmail      Source = "email"
+	SourceBrowser    Source = "browser"
+	SourceClipboard  Source = "clipboard"
+	SourceData       Source = "data"
+	SourceReference  Source = "reference"
+	SourceScreenshot Source = "screenshot"
+	SourceProject    Source = "project"
 )
 
+// AllSources lists every document source.
+var AllSources = []Source{SourceMarkdown, SourcePDF, SourceEmail, SourceBrowser, SourceClipboard, SourceData, SourceReference, SourceScreenshot, SourceProject}
+
+// ParseSources parses a comma-separated list of sources, such as
+// "markdown,pdf". Unknown sources are an error and duplicates are dropped.
+func ParseSources(list string) ([]Source, error) {
+	var sources []Source
+	for _, name := range strings.Split(list, ",") {
+		s := Source(strings.ToLower(strings.TrimSpace(name)))
+		if s == "" {
+			continue
+		}
+		if !slices.Contains(AllSources, s) {
+			return nil, fmt.Errorf("unknown source %q (want one of %s)", s, JoinSources(AllSources))
+		}
+		if !slices.Contains(sources, s) {
+			sources = append(sources, s)
+		}
+	}
+	return sources, nil
+}
+
+// JoinSources formats sources as a comma-separated list, as read by
+// ParseSources and "source:" query filters.
+func JoinSources(sources []Source) string {
+	names := make([]string, len(sources))
+	for i, s := range sources {
+		names[i] = string(s)
+	}
+	return strings.Join(names, ",")
+}
+
+// MatchesSources reports whether s is one of sources. An empty list matches
+// every source.
+func MatchesSources(sources []Source, s Source) bool {
+	return len(sources) == 0 || slices.Contains(sources, s)
+}
+
 // Document represents an indexed document.
 type Document struct {
 	ID          string            `json:"id"`
@@ -29,6 +76,62 @@ type Document struct {
 	ContentHash string            `json:"content_hash"`
 	IndexedAt   time.Time         `json:"indexed_at"`
 	ModifiedAt  time.Time         `json:"modified_at"`
+	// DocumentDate is the date the document itself gives, such as a
+	// frontmatter "date:" or an email's Date header. Zero when it has none.
+	DocumentDate time.Time `json:"document_date,omitzero"`
+}
+
+// IsSection reports whether d is one part of a larger document, such as a
+// heading section of a long note, a row of a data file or a reference in a
+// library, indexed alongside the document itself.
+func (d *Document) IsSection() bool {
+	return d.Metadata["section_of"] != ""
+}
+
+// FilePath returns the file d was read from. A section's path is its
+// document's path with a "#anchor" suffix, which FilePath removes.
+func (d *Document) FilePath() string {
+	if anchor := d.Metadata["anchor"]; d.IsSection() && anchor != "" {
+		return strings.TrimSuffix(d.Path, "#"+anchor)
+	}
+	return d.Path
+}
+
+// Date returns the date a document is about: its DocumentDate when it has
+// one, otherwise its modification time. Time filters and recency use it.
+func (d *Document) Date() time.Time {
+	if !d.DocumentDate.IsZero() {
+		return d.DocumentDate
+	}
+	return d.ModifiedAt
+}
+
+// WordCount returns the number of words in d counted when it was indexed,
+// or 0 for a document indexed before words were counted.
+func (d *Document) WordCount() int {
+	n, _ := strconv.Atoi(d.Metadata["words"])
+	return n
+}
+
+// wordsPerMinute is the reading speed ReadingTime assumes.
+const wordsPerMinute = 200
+
+// ReadingTime estimates how long words take to read, in whole minutes and
+// at least one.
+func ReadingTime(words int) time.Duration {
+	return time.Duration(max(1, (words+wordsPerMinute/2)/wordsPerMinute)) * time.Minute
+}
+
+// CountWords counts the words in text: runs of non-space characters with at
+// least one letter or digit, so markup such as "#" or "-" isn't counted.
+func CountWords(text string) int {
+	n := 0
+	for _, field := range strings.Fields(text) {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			n++
+		}
+	}
+	return n
 }
 
 // MetadataJSON returns the metadata as a JSON string.
@@ -67,6 +170,177 @@ type Collection struct {
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// DocumentRef identifies a document portably: by path, with the content hash
+// as a fallback when the path differs between machines.
+type DocumentRef struct {
+	ID          string `json:"-"`
+	Path        string `json:"path"`
+	ContentHash string `json:"content_hash,omitempty"`
+}
+
+// DocumentFootprint is the space a document takes in the index, and whether
+// the user has kept it by tagging it by hand, adding it to a collection or
+// giving it an alias.
+type DocumentFootprint struct {
+	ID    string
+	Path  string
+	Title string
+	Date  time.Time // the document's own date, else its modification time
+	Size  int64     // bytes of content
+	Kept  bool
+}
+
+// DocumentTitle names a document, for finding one by its title.
+type DocumentTitle struct {
+	ID    string
+	Path  string
+	Title string
+}
+
+// WordStats sums up the words of the indexed documents.
+type WordStats struct {
+	Documents int   // documents whose words were counted
+	Words     int64 // words in them
+	Largest   []DocumentWords
+}
+
+// DocumentWords is a document with its word count.
+type DocumentWords struct {
+	DocumentTitle
+	Words int
+}
+
+// TagAssignment is a manual tag on a document.
+type TagAssignment struct {
+	Document DocumentRef
+	Tag      string
+}
+
+// CollectionMember is a document's membership of a named collection.
+type CollectionMember struct {
+	Collection string
+	Document   DocumentRef
+	AddedAt    time.Time
+}
+
+// Alias is a short name that jumps straight to a document, as in
+// "mindcli open standup".
+type Alias struct {
+	Name       string    `json:"name"`
+	DocumentID string    `json:"document_id"`
+	Path       string    `json:"path"`
+	Title      string    `json:"title"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NormalizeAlias returns the stored form of an alias name: trimmed and
+// lowercased, so aliases match however they are typed.
+func NormalizeAlias(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// IndexError records the most recent failure to index a file.
+type IndexError struct {
+	Path     string    `json:"path"`
+	Source   Source    `json:"source"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// JournalEntry is a document the indexer started to write or remove. An
+// entry still present when the indexer next starts marks work a crash cut
+// short.
+type JournalEntry struct {
+	Path       string
+	Source     Source
+	DocumentID string
+	StartedAt  time.Time
+}
+
+// EntityKind is the kind of a named entity found in documents.
+type EntityKind string
+
+const (
+	EntityPerson       EntityKind = "person"
+	EntityOrganization EntityKind = "org"
+	EntityProject      EntityKind = "project"
+)
+
+// EntityKinds lists the entity kinds in display order.
+var EntityKinds = []EntityKind{EntityPerson, EntityOrganization, EntityProject}
+
+// Entity is a person, organization or project named in a document.
+type Entity struct {
+	Kind EntityKind
+	Name string
+}
+
+// EntityCount is an entity with the number of documents naming it.
+type EntityCount struct {
+	Entity
+	Documents int
+}
+
+// ParticipantRole is how an address took part in an email.
+type ParticipantRole string
+
+const (
+	RoleFrom ParticipantRole = "from"
+	RoleTo   ParticipantRole = "to"
+)
+
+// Participant is an address that sent or received an email. Address is
+// lowercased; Name is the display name given with it, if any.
+type Participant struct {
+	Role    ParticipantRole
+	Address string
+	Name    string
+}
+
+// Contact is an email correspondent with the number of messages they sent
+// and received.
+type Contact struct {
+	Address  string
+	Name     string
+	Messages int
+	Sent     int
+	Received int
+}
+
+// RelationKind is the kind of a link from a document to something outside
+// the index.
+type RelationKind string
+
+// RelationAsset links a note to a local file it embeds or links to, such as
+// an image or an attached PDF.
+const RelationAsset RelationKind = "asset"
+
+// InteractionKind is something the user did with a document.
+type InteractionKind string
+
+// InteractionOpened records opening a document in its app.
+const InteractionOpened InteractionKind = "opened"
+
+// CachedAnswer is a generated answer kept to answer the same question again
+// without retrieval or generation.
+type CachedAnswer struct {
+	Key             string // identifies the question and the settings it was asked with
+	Question        string
+	Answer          string
+	Sources         []CachedSource
+	ConfidenceLevel string
+	ConfidenceScore float64
+	CreatedAt       time.Time
+	Generation      int64 // IndexGeneration when the answer was generated
+}
+
+// CachedSource is a document a cached answer drew on, with its content hash
+// when the answer was generated.
+type CachedSource struct {
+	DocumentID  string `json:"id"`
+	ContentHash string `json:"hash"`
+}
+
 // SearchResult represents a search result with scoring information.
 type SearchResult struct {
 	Document    *Document `json:"document"`
@@ -3,6 +3,7 @@ package storage
 import (
 	"sort"
 	"testing"
+	"time"
 )
 
 func TestDocumentMetadataJSON(t *testing.T) {
@@ -152,3 +153,64 @@ func TestSourceConstants(t *testing.T) {
 		t.Errorf("SourceClipboard = %q, want 'clipboard'", SourceClipboard)
 	}
 }
+
+func TestParseSources(t *testing.T) {
+	got, err := ParseSources(" markdown, PDF,,markdown ")
+	if err != nil {
+		t.Fatalf("ParseSources() error = %v", err)
+	}
+	if JoinSources(got) != "markdown,pdf" {
+		t.Errorf("ParseSources() = %v, want markdown,pdf", got)
+	}
+	if _, err := ParseSources("markdown,notes"); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+	if got, err := ParseSources(""); err != nil || len(got) != 0 {
+		t.Errorf("ParseSources(\"\") = %v, %v, want none", got, err)
+	}
+}
+
+func TestMatchesSources(t *testing.T) {
+	if !MatchesSources(nil, SourceEmail) {
+		t.Error("no sources should match every source")
+	}
+	sources := []Source{SourceMarkdown, SourcePDF}
+	if !MatchesSources(sources, SourcePDF) || MatchesSources(sources, SourceEmail) {
+		t.Error("MatchesSources() should match only listed sources")
+	}
+}
+
+func TestDocumentFilePath(t *testing.T) {
+	note := &Document{Path: "/notes/C#.md"}
+	if note.IsSection() || note.FilePath() != "/notes/C#.md" {
+		t.Errorf("note: IsSection() = %v, FilePath() = %q", note.IsSection(), note.FilePath())
+	}
+	section := &Document{
+		Path:     "/notes/C#.md#goals",
+		Metadata: map[string]string{"section_of": "note", "anchor": "goals"},
+	}
+	if !section.IsSection() || section.FilePath() != "/notes/C#.md" {
+		t.Errorf("section: IsSection() = %v, FilePath() = %q", section.IsSection(), section.FilePath())
+	}
+}
+
+func TestDocumentWordCount(t *testing.T) {
+	doc := &Document{Metadata: map[string]string{"words": "1234"}}
+	if got := doc.WordCount(); got != 1234 {
+		t.Errorf("WordCount() = %d, want 1234", got)
+	}
+	if got := (&Document{}).WordCount(); got != 0 {
+		t.Errorf("WordCount() without a count = %d, want 0", got)
+	}
+	for words, want := range map[int]time.Duration{0: time.Minute, 250: time.Minute, 1234: 6 * time.Minute} {
+		if got := ReadingTime(words); got != want {
+			t.Errorf("ReadingTime(%d) = %v, want %v", words, got, want)
+		}
+	}
+}
+
+func TestCountWords(t *testing.T) {
+	if got := CountWords("# Title\n\n- one, two  -- three 4\n"); got != 5 {
+		t.Errorf("CountWords() = %d, want 5", got)
+	}
+}
@@ -0,0 +1,302 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Document paths are stored portably so a database keeps working when the
+// notes live at a different location, e.g. a synced folder mounted
+// elsewhere on another machine:
+//
+//	@label/rel/path  relative to the configured root with that label
+//	~/rel/path       relative to the home directory
+//	anything else    stored as given (paths outside both, URLs, ...)
+//
+// Writes convert absolute paths to the stored form and reads resolve them
+// back, so callers only ever see local absolute paths.
+
+// homeLabel records the home directory among the applied roots.
+const homeLabel = "~"
+
+// pathRoot is a labeled directory paths are stored relative to.
+type pathRoot struct {
+	label string
+	dir   string
+}
+
+// pathMapper converts between local and stored paths. The zero value stores
+// every path as given.
+type pathMapper struct {
+	home  string
+	roots []pathRoot // longest directory first, so nested roots win
+}
+
+func newPathMapper(home string, roots map[string]string) (*pathMapper, error) {
+	m := &pathMapper{}
+	// A home directory of / would make every path home-relative.
+	if home != "" && filepath.Dir(home) != home {
+		m.home = filepath.Clean(home)
+	}
+	for label, dir := range roots {
+		if label == "" || label == homeLabel || strings.ContainsAny(label, `/\`) {
+			return nil, fmt.Errorf("invalid path root label %q", label)
+		}
+		if !filepath.IsAbs(dir) || filepath.Dir(dir) == dir {
+			return nil, fmt.Errorf("path root %q: %q is not an absolute directory below /", label, dir)
+		}
+		m.roots = append(m.roots, pathRoot{label: label, dir: filepath.Clean(dir)})
+	}
+	sort.Slice(m.roots, func(i, j int) bool {
+		if len(m.roots[i].dir) != len(m.roots[j].dir) {
+			return len(m.roots[i].dir) > len(m.roots[j].dir)
+		}
+		return m.roots[i].label < m.roots[j].label
+	})
+	return m, nil
+}
+
+// store returns the stored form of a local path.
+func (m *pathMapper) store(path string) string {
+	if m == nil || !filepath.IsAbs(path) {
+		return path
+	}
+	for _, r := range m.roots {
+		if rel, ok := relativeTo(r.dir, path); ok {
+			return joinStored("@"+r.label, rel)
+		}
+	}
+	if m.home != "" {
+		if rel, ok := relativeTo(m.home, path); ok {
+			return joinStored(homeLabel, rel)
+		}
+	}
+	return path
+}
+
+// resolve returns the local path for a stored one. Paths under a label that
+// isn't configured are returned unchanged.
+func (m *pathMapper) resolve(stored string) string {
+	if m == nil {
+		return stored
+	}
+	prefix, rel, _ := strings.Cut(stored, "/")
+	if label, ok := strings.CutPrefix(prefix, "@"); ok {
+		if r := m.root(label); r != nil {
+			return filepath.Join(r.dir, filepath.FromSlash(rel))
+		}
+		return stored
+	}
+	if prefix == homeLabel && m.home != "" {
+		return filepath.Join(m.home, filepath.FromSlash(rel))
+	}
+	return stored
+}
+
+func (m *pathMapper) root(label string) *pathRoot {
+	for i := range m.roots {
+		if m.roots[i].label == label {
+			return &m.roots[i]
+		}
+	}
+	return nil
+}
+
+// relativeTo returns path relative to dir if path is dir or below it.
+func relativeTo(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// InDirectory reports whether path is the directory dir or lies below it.
+func InDirectory(path, dir string) bool {
+	_, ok := relativeTo(dir, path)
+	return ok
+}
+
+func joinStored(prefix, rel string) string {
+	if rel == "." {
+		return prefix
+	}
+	return prefix + "/" + filepath.ToSlash(rel)
+}
+
+// storedPrefixesUnder returns the stored-path prefixes that cover every path
+// below the local directory dir.
+func (m *pathMapper) storedPrefixesUnder(dir string) []string {
+	dir = filepath.Clean(dir)
+	stored := m.store(dir)
+	sep := string(filepath.Separator)
+	if stored != dir {
+		sep = "/" // stored forms always use forward slashes
+	}
+	prefixes := []string{strings.TrimSuffix(stored, sep) + sep}
+	if m == nil || !filepath.IsAbs(dir) {
+		return prefixes
+	}
+	for _, r := range m.roots {
+		if rel, ok := relativeTo(dir, r.dir); ok && rel != "." {
+			prefixes = append(prefixes, "@"+r.label+"/")
+		}
+	}
+	if m.home != "" {
+		if rel, ok := relativeTo(dir, m.home); ok && rel != "." {
+			prefixes = append(prefixes, homeLabel+"/")
+		}
+	}
+	return prefixes
+}
+
+func (d *DB) storedPath(path string) string {
+	return d.paths.Load().store(path)
+}
+
+func (d *DB) localPath(stored string) string {
+	return d.paths.Load().resolve(stored)
+}
+
+// SetPathRoots stores document paths below each root relative to it, keyed
+// by label (label → absolute directory). Existing documents are converted
+// the first time a root is configured, so the same database resolves paths
+// correctly wherever the roots are mounted. Labels must stay stable: paths
+// under a label that is removed can no longer be resolved.
+func (d *DB) SetPathRoots(ctx context.Context, roots map[string]string) error {
+	home, _ := os.UserHomeDir()
+	m, err := newPathMapper(home, roots)
+	if err != nil {
+		return err
+	}
+	// A read-only database keeps its paths as stored.
+	if !d.readOnly {
+		if err := d.convertPaths(ctx, m); err != nil {
+			return err
+		}
+	}
+	d.paths.Store(m)
+	return nil
+}
+
+// convertPaths rewrites stored paths for a new root configuration. Paths
+// under labels that are still configured are already portable and stay
+// untouched. Absolute paths, home-relative paths and paths under removed
+// labels are rewritten to the new stored form. The applied roots are
+// recorded in path_roots so unchanged configurations cost one query.
+func (d *DB) convertPaths(ctx context.Context, m *pathMapper) error {
+	applied, err := d.appliedRoots(ctx)
+	if err != nil {
+		return err
+	}
+	want := map[string]string{homeLabel: m.home}
+	for _, r := range m.roots {
+		want[r.label] = r.dir
+	}
+	if fmt.Sprint(applied) == fmt.Sprint(want) {
+		return nil
+	}
+
+	// The previous mapper resolves paths under labels that are gone.
+	prevRoots := make(map[string]string)
+	for label, dir := range applied {
+		if label != homeLabel {
+			prevRoots[label] = dir
+		}
+	}
+	prev, err := newPathMapper(m.home, prevRoots)
+	if err != nil {
+		return err
+	}
+
+	var ranges []string
+	for _, r := range m.roots {
+		ranges = append(ranges, r.dir+string(filepath.Separator))
+	}
+	if m.home != "" {
+		ranges = append(ranges, m.home+string(filepath.Separator))
+	}
+	ranges = append(ranges, homeLabel+"/")
+	for label := range prevRoots {
+		if m.root(label) == nil {
+			ranges = append(ranges, "@"+label+"/")
+		}
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	updates := make(map[string]string)
+	for _, prefix := range ranges {
+		rows, err := tx.QueryContext(ctx, `SELECT id, path FROM documents WHERE path >= ? AND path < ?`, prefix, prefixUpperBound(prefix))
+		if err != nil {
+			return fmt.Errorf("reading document paths: %w", err)
+		}
+		for rows.Next() {
+			var id, path string
+			if err := rows.Scan(&id, &path); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("scanning document path: %w", err)
+			}
+			if stored := m.store(prev.resolve(path)); stored != path {
+				updates[id] = stored
+			}
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return fmt.Errorf("reading document paths: %w", err)
+		}
+	}
+	for id, path := range updates {
+		if _, err := tx.ExecContext(ctx, `UPDATE documents SET path = ? WHERE id = ?`, path, id); err != nil {
+			return fmt.Errorf("converting document path: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM path_roots`); err != nil {
+		return fmt.Errorf("recording path roots: %w", err)
+	}
+	for label, dir := range want {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO path_roots (label, dir) VALUES (?, ?)`, label, dir); err != nil {
+			return fmt.Errorf("recording path roots: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing path conversion: %w", err)
+	}
+	return nil
+}
+
+// appliedRoots returns the roots recorded by the last conversion.
+func (d *DB) appliedRoots(ctx context.Context) (map[string]string, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT label, dir FROM path_roots`)
+	if err != nil {
+		return nil, fmt.Errorf("reading path roots: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	roots := make(map[string]string)
+	for rows.Next() {
+		var label, dir string
+		if err := rows.Scan(&label, &dir); err != nil {
+			return nil, fmt.Errorf("scanning path root: %w", err)
+		}
+		roots[label] = dir
+	}
+	return roots, rows.Err()
+}
+
+// prefixUpperBound returns the smallest string greater than every string
+// starting with prefix, whose last byte is a path separator. Range scans on
+// [prefix, bound) use the path index and need no LIKE escaping.
+func prefixUpperBound(prefix string) string {
+	return prefix[:len(prefix)-1] + string(rune(prefix[len(prefix)-1]+1))
+}
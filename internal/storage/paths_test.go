@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathMapper(t *testing.T) {
+	m, err := newPathMapper("/home/me", map[string]string{
+		"notes": "/home/me/Dropbox/notes",
+		"inner": "/home/me/Dropbox/notes/inner",
+		"mnt":   "/mnt/docs",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		local, stored string
+	}{
+		{"/home/me/Dropbox/notes/a.md", "@notes/a.md"},
+		{"/home/me/Dropbox/notes/inner/b.md", "@inner/b.md"},
+		{"/home/me/Dropbox/notes", "@notes"},
+		{"/mnt/docs/x/y.pdf", "@mnt/x/y.pdf"},
+		{"/home/me/other.md", "~/other.md"},
+		{"/home/meme/x.md", "/home/meme/x.md"},
+		{"/etc/hosts", "/etc/hosts"},
+		{"https://example.com/page", "https://example.com/page"},
+	}
+	for _, tt := range tests {
+		if got := m.store(tt.local); got != tt.stored {
+			t.Errorf("store(%q) = %q, want %q", tt.local, got, tt.stored)
+		}
+		if got := m.resolve(tt.stored); got != tt.local {
+			t.Errorf("resolve(%q) = %q, want %q", tt.stored, got, tt.local)
+		}
+	}
+
+	if got := m.resolve("@gone/a.md"); got != "@gone/a.md" {
+		t.Errorf("resolve() of an unknown label = %q, want it unchanged", got)
+	}
+}
+
+func TestNewPathMapperRejectsBadRoots(t *testing.T) {
+	for _, roots := range []map[string]string{
+		{"": "/a"},
+		{"~": "/a"},
+		{"a/b": "/a"},
+		{"rel": "notes"},
+		{"top": "/"},
+	} {
+		if _, err := newPathMapper("", roots); err == nil {
+			t.Errorf("newPathMapper(%v) succeeded, want error", roots)
+		}
+	}
+}
+
+func TestSetPathRootsConvertsExistingPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	notes := filepath.Join(home, "notes")
+	createTestDoc(t, db, "d1", filepath.Join(notes, "a.md"))
+	createTestDoc(t, db, "d2", "/elsewhere/b.md")
+
+	if got := rawPath(t, db, "d1"); got != "~/notes/a.md" {
+		t.Errorf("stored path without roots = %q, want home-relative", got)
+	}
+
+	mustSucceed(t, db.SetPathRoots(ctx, map[string]string{"notes": notes}))
+	if got := rawPath(t, db, "d1"); got != "@notes/a.md" {
+		t.Errorf("stored path with root = %q, want @notes/a.md", got)
+	}
+	if got := rawPath(t, db, "d2"); got != "/elsewhere/b.md" {
+		t.Errorf("stored path outside roots = %q, want it unchanged", got)
+	}
+
+	// Removing the root converts its documents back.
+	mustSucceed(t, db.SetPathRoots(ctx, nil))
+	if got := rawPath(t, db, "d1"); got != "~/notes/a.md" {
+		t.Errorf("stored path after removing root = %q, want home-relative", got)
+	}
+}
+
+func TestPathRootsRelocated(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	mustSucceed(t, db.SetPathRoots(ctx, map[string]string{"notes": "/mnt/laptop/notes"}))
+	createTestDoc(t, db, "d1", "/mnt/laptop/notes/sub/a.md")
+
+	// The same database on a machine that mounts the notes elsewhere.
+	mustSucceed(t, db.SetPathRoots(ctx, map[string]string{"notes": "/media/desktop/notes"}))
+	doc, err := db.GetDocumentByPath(ctx, "/media/desktop/notes/sub/a.md")
+	mustSucceed(t, err)
+	if doc.ID != "d1" || doc.Path != "/media/desktop/notes/sub/a.md" {
+		t.Errorf("GetDocumentByPath() = %s at %s, want d1 at the new location", doc.ID, doc.Path)
+	}
+
+	paths, err := db.ListPathsUnder(ctx, "/media/desktop")
+	mustSucceed(t, err)
+	if fmt.Sprint(paths) != "[/media/desktop/notes/sub/a.md]" {
+		t.Errorf("ListPathsUnder() = %v, want the relocated document", paths)
+	}
+}
+
+func rawPath(t *testing.T, db *DB, id string) string {
+	t.Helper()
+	var path string
+	if err := db.db.QueryRow(`SELECT path FROM documents WHERE id = ?`, id).Scan(&path); err != nil {
+		t.Fatalf("reading stored path: %v", err)
+	}
+	return path
+}
+
+func TestInDirectory(t *testing.T) {
+	tests := []struct {
+		path, dir string
+		want      bool
+	}{
+		{"/notes/alpha/plan.md", "/notes/alpha", true},
+		{"/notes/alpha", "/notes/alpha/", true},
+		{"/notes/alpha2/plan.md", "/notes/alpha", false},
+		{"/notes/plan.md", "/notes/alpha", false},
+		{"https://example.com/notes/alpha", "/notes/alpha", false},
+	}
+	for _, tt := range tests {
+		if got := InDirectory(tt.path, tt.dir); got != tt.want {
+			t.Errorf("InDirectory(%q, %q) = %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
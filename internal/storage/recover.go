@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrCorrupt is returned when the database file is damaged or isn't a
+// SQLite database at all.
+var ErrCorrupt = errors.New("database file is damaged")
+
+// isCorrupt reports whether err is SQLite finding the database damaged.
+func isCorrupt(err error) bool {
+	var sqlErr sqlite3.Error
+	return errors.As(err, &sqlErr) && (sqlErr.Code == sqlite3.ErrCorrupt || sqlErr.Code == sqlite3.ErrNotADB)
+}
+
+// corruptErr marks err with ErrCorrupt when it reports a damaged database.
+func corruptErr(err error) error {
+	if isCorrupt(err) {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return err
+}
+
+// OpenReadOnly opens an existing database without writing to it: no
+// migrations run and stored paths aren't converted. It is the fallback when
+// Open fails on a damaged or unwritable database, so what can still be read
+// is searchable; every write fails. It returns ErrCorrupt when not even the
+// documents can be read.
+func OpenReadOnly(path string) (*DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	ro, err := sql.Open("sqlite3", "file:"+path+"?mode=ro&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	ro.SetMaxOpenConns(readerConns)
+	ro.SetMaxIdleConns(readerConns)
+	if _, err := ro.Exec(`SELECT COUNT(*) FROM documents`); err != nil {
+		_ = ro.Close()
+		return nil, fmt.Errorf("reading documents: %w", corruptErr(err))
+	}
+
+	store := &DB{db: ro, ro: ro, readOnly: true}
+	home, _ := os.UserHomeDir()
+	m, err := newPathMapper(home, nil)
+	if err != nil {
+		_ = ro.Close()
+		return nil, err
+	}
+	store.paths.Store(m)
+	return store, nil
+}
+
+// ReadOnly reports whether the database was opened with OpenReadOnly.
+func (d *DB) ReadOnly() bool {
+	return d.readOnly
+}
+
+// QuickCheck scans the database for damage that opening it doesn't reveal,
+// such as broken pages in the middle of the file, and returns ErrCorrupt
+// describing the first problem found.
+func (d *DB) QuickCheck(ctx context.Context) error {
+	var result string
+	if err := d.db.QueryRowContext(ctx, `PRAGMA quick_check(1)`).Scan(&result); err != nil {
+		return fmt.Errorf("checking database: %w", corruptErr(err))
+	}
+	if result != "ok" {
+		return fmt.Errorf("%w: %s", ErrCorrupt, result)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenDamagedDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	if err := os.WriteFile(path, []byte("this is not a sqlite database, just some text long enough"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Open() error = %v, want ErrCorrupt", err)
+	}
+	if _, err := OpenReadOnly(path); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("OpenReadOnly() error = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	ctx := context.Background()
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	home, _ := os.UserHomeDir()
+	notePath := filepath.Join(home, "notes", "a.md")
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "a", Source: SourceMarkdown, Path: notePath, ContentHash: "h", IndexedAt: now, ModifiedAt: now}))
+	// An answer drawn on an older version of the document, so stale.
+	generation, err := db.IndexGeneration(ctx)
+	mustSucceed(t, err)
+	mustSucceed(t, db.PutCachedAnswer(ctx, &CachedAnswer{Key: "k", Question: "q", Answer: "x", Sources: []CachedSource{{DocumentID: "a", ContentHash: "old"}}, Generation: generation}))
+	mustSucceed(t, db.Close())
+
+	ro, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly() error = %v", err)
+	}
+	defer func() { mustSucceed(t, ro.Close()) }()
+	if !ro.ReadOnly() {
+		t.Error("ReadOnly() = false")
+	}
+	mustSucceed(t, ro.SetPathRoots(ctx, nil))
+	doc, err := ro.GetDocument(ctx, "a")
+	if err != nil || doc.Path != notePath {
+		t.Errorf("GetDocument() = %+v, %v; want the document at %s", doc, err, notePath)
+	}
+	if err := ro.InsertDocument(ctx, &Document{ID: "b", Source: SourceMarkdown, Path: "/b.md", ContentHash: "h", IndexedAt: now, ModifiedAt: now}); err == nil {
+		t.Error("InsertDocument() on a read-only database succeeded")
+	}
+	if _, err := ro.GetCachedAnswer(ctx, "k"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetCachedAnswer() of a stale answer: error = %v, want ErrNotFound", err)
+	}
+	if err := ro.QuickCheck(ctx); err != nil {
+		t.Errorf("QuickCheck() error = %v", err)
+	}
+
+	if _, err := OpenReadOnly(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("OpenReadOnly() of a missing database succeeded")
+	}
+}
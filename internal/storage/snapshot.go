@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotTimeFormat is the timestamp prefix of snapshot file names. It sorts
+// lexically in creation order.
+const snapshotTimeFormat = "20060102-150405.000"
+
+// SnapshotManual is the reason recorded for user-requested snapshots. Manual
+// snapshots are never pruned automatically.
+const SnapshotManual = "manual"
+
+// SnapshotPolicy controls automatic snapshots. The zero value disables them.
+type SnapshotPolicy struct {
+	Dir      string        // directory snapshots are written to
+	Keep     int           // automatic snapshots retained; 0 disables them
+	Interval time.Duration // minimum time between periodic snapshots
+}
+
+func (p SnapshotPolicy) enabled() bool {
+	return p.Keep > 0 && p.Dir != ""
+}
+
+// Snapshot describes a database snapshot file.
+type Snapshot struct {
+	Name      string // file name, e.g. "20240301-120000.000-periodic.db"
+	Path      string
+	Reason    string // why it was taken, e.g. "manual" or "pre-migration"
+	CreatedAt time.Time
+	Size      int64
+}
+
+// OpenWithSnapshots opens the database like Open and takes snapshots
+// according to policy, starting with one before any pending migration.
+func OpenWithSnapshots(path string, policy SnapshotPolicy) (*DB, error) {
+	return open(path, policy)
+}
+
+// CreateSnapshot writes a consistent copy of the database into dir using
+// VACUUM INTO. It is safe to call while the database is in use.
+func (d *DB) CreateSnapshot(ctx context.Context, dir, reason string) (*Snapshot, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+	now := time.Now()
+	name := now.Format(snapshotTimeFormat) + "-" + reason + ".db"
+	path := filepath.Join(dir, name)
+	if _, err := d.db.ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return nil, fmt.Errorf("writing snapshot: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+	return &Snapshot{Name: name, Path: path, Reason: reason, CreatedAt: now, Size: info.Size()}, nil
+}
+
+// AutoSnapshot takes an automatic snapshot, e.g. before a full re-index, and
+// prunes old automatic snapshots. It returns nil when snapshots are disabled.
+func (d *DB) AutoSnapshot(ctx context.Context, reason string) (*Snapshot, error) {
+	if !d.snapshots.enabled() {
+		return nil, nil
+	}
+	snap, err := d.CreateSnapshot(ctx, d.snapshots.Dir, reason)
+	if err != nil {
+		return nil, err
+	}
+	if err := pruneSnapshots(d.snapshots.Dir, d.snapshots.Keep); err != nil {
+		return snap, err
+	}
+	return snap, nil
+}
+
+// PeriodicSnapshot takes an automatic snapshot when none has been taken
+// within the policy interval. It returns nil when no snapshot was due.
+func (d *DB) PeriodicSnapshot(ctx context.Context) (*Snapshot, error) {
+	if !d.snapshots.enabled() {
+		return nil, nil
+	}
+	snaps, err := ListSnapshots(d.snapshots.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range snaps {
+		if s.Reason != SnapshotManual && time.Since(s.CreatedAt) < d.snapshots.Interval {
+			return nil, nil
+		}
+	}
+	return d.AutoSnapshot(ctx, "periodic")
+}
+
+// ListSnapshots returns the snapshots in dir, newest first. A missing
+// directory means there are none.
+func ListSnapshots(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot directory: %w", err)
+	}
+
+	var snaps []Snapshot
+	for _, e := range entries {
+		snap, ok := parseSnapshotName(e.Name())
+		if !ok || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		snap.Path = filepath.Join(dir, e.Name())
+		snap.Size = info.Size()
+		snaps = append(snaps, snap)
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Name > snaps[j].Name })
+	return snaps, nil
+}
+
+// parseSnapshotName extracts the timestamp and reason from a snapshot file
+// name. ok is false for files that aren't snapshots.
+func parseSnapshotName(name string) (Snapshot, bool) {
+	stem, ok := strings.CutSuffix(name, ".db")
+	if !ok || len(stem) < len(snapshotTimeFormat)+2 || stem[len(snapshotTimeFormat)] != '-' {
+		return Snapshot{}, false
+	}
+	created, err := time.ParseInLocation(snapshotTimeFormat, stem[:len(snapshotTimeFormat)], time.Local)
+	if err != nil {
+		return Snapshot{}, false
+	}
+	return Snapshot{Name: name, Reason: stem[len(snapshotTimeFormat)+1:], CreatedAt: created}, true
+}
+
+// pruneSnapshots deletes all but the newest keep automatic snapshots in dir.
+func pruneSnapshots(dir string, keep int) error {
+	snaps, err := ListSnapshots(dir)
+	if err != nil {
+		return err
+	}
+	kept := 0
+	for _, s := range snaps {
+		if s.Reason == SnapshotManual {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		if err := os.Remove(s.Path); err != nil {
+			return fmt.Errorf("pruning snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// RestoreSnapshot replaces the database at dbPath with the snapshot at
+// snapshotPath. The database must not be open.
+func RestoreSnapshot(dbPath, snapshotPath string) error {
+	src, err := os.Open(snapshotPath)
+	if err != nil {
+		return fmt.Errorf("opening snapshot: %w", err)
+	}
+	defer func() { _ = src.Close() }()
+
+	// Copy next to the database first so the final rename is atomic.
+	tmp, err := os.CreateTemp(filepath.Dir(dbPath), ".mindcli-restore-*")
+	if err != nil {
+		return fmt.Errorf("creating restore file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := io.Copy(tmp, src); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("copying snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("copying snapshot: %w", err)
+	}
+
+	// A leftover WAL belongs to the old database and must not be replayed
+	// onto the restored one.
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing %s: %w", suffix, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), dbPath); err != nil {
+		return fmt.Errorf("replacing database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCreateAndRestoreSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+	snapDir := filepath.Join(dir, "snapshots")
+	ctx := context.Background()
+
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "keep", Source: SourceMarkdown, Path: "/keep.md", ContentHash: "h1", IndexedAt: now, ModifiedAt: now}))
+
+	snap, err := db.CreateSnapshot(ctx, snapDir, SnapshotManual)
+	if err != nil {
+		t.Fatalf("CreateSnapshot() error = %v", err)
+	}
+	if snap.Reason != SnapshotManual || snap.Size == 0 {
+		t.Errorf("snapshot = %+v, want manual with a size", snap)
+	}
+
+	// A bad index run after the snapshot.
+	mustSucceed(t, db.DeleteDocument(ctx, "keep"))
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "junk", Source: SourceMarkdown, Path: "/junk.md", ContentHash: "h2", IndexedAt: now, ModifiedAt: now}))
+	mustSucceed(t, db.Close())
+
+	if err := RestoreSnapshot(dbPath, snap.Path); err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+
+	db, err = Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	if _, err := db.GetDocument(ctx, "keep"); err != nil {
+		t.Errorf("restored database is missing the snapshotted document: %v", err)
+	}
+	if _, err := db.GetDocument(ctx, "junk"); err != ErrNotFound {
+		t.Errorf("restored database still has the later document (err = %v)", err)
+	}
+}
+
+func TestAutoSnapshotPrunesOldSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	snapDir := filepath.Join(dir, "snapshots")
+	ctx := context.Background()
+
+	db, err := OpenWithSnapshots(filepath.Join(dir, "test.db"), SnapshotPolicy{Dir: snapDir, Keep: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	if _, err := db.CreateSnapshot(ctx, snapDir, SnapshotManual); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		time.Sleep(2 * time.Millisecond) // distinct timestamps
+		if _, err := db.AutoSnapshot(ctx, "pre-reindex"); err != nil {
+			t.Fatalf("AutoSnapshot() error = %v", err)
+		}
+	}
+
+	snaps, err := ListSnapshots(snapDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var auto, manual int
+	for _, s := range snaps {
+		if s.Reason == SnapshotManual {
+			manual++
+		} else {
+			auto++
+		}
+	}
+	if auto != 2 || manual != 1 {
+		t.Errorf("kept %d automatic and %d manual snapshots, want 2 and 1", auto, manual)
+	}
+	for i := 1; i < len(snaps); i++ {
+		if snaps[i].CreatedAt.After(snaps[i-1].CreatedAt) {
+			t.Errorf("snapshots not newest first: %v before %v", snaps[i-1].Name, snaps[i].Name)
+		}
+	}
+}
+
+func TestPeriodicSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	snapDir := filepath.Join(dir, "snapshots")
+	ctx := context.Background()
+
+	db, err := OpenWithSnapshots(filepath.Join(dir, "test.db"), SnapshotPolicy{Dir: snapDir, Keep: 3, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	first, err := db.PeriodicSnapshot(ctx)
+	if err != nil || first == nil {
+		t.Fatalf("first PeriodicSnapshot() = %v, %v; want a snapshot", first, err)
+	}
+	second, err := db.PeriodicSnapshot(ctx)
+	if err != nil || second != nil {
+		t.Errorf("second PeriodicSnapshot() = %v, %v; want none within the interval", second, err)
+	}
+}
+
+func TestSnapshotsDisabled(t *testing.T) {
+	dir := t.TempDir()
+	snapDir := filepath.Join(dir, "snapshots")
+
+	db, err := OpenWithSnapshots(filepath.Join(dir, "test.db"), SnapshotPolicy{Dir: snapDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	if snap, err := db.AutoSnapshot(context.Background(), "pre-reindex"); snap != nil || err != nil {
+		t.Errorf("AutoSnapshot() = %v, %v; want nothing when Keep is 0", snap, err)
+	}
+	if _, err := os.Stat(snapDir); !os.IsNotExist(err) {
+		t.Error("snapshot directory created while snapshots are disabled")
+	}
+}
+
+func TestSnapshotBeforeMigration(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+	snapDir := filepath.Join(dir, "snapshots")
+	policy := SnapshotPolicy{Dir: snapDir, Keep: 3}
+
+	db, err := OpenWithSnapshots(dbPath, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A fresh database has nothing worth saving.
+	if snaps, _ := ListSnapshots(snapDir); len(snaps) != 0 {
+		t.Errorf("got %d snapshots for a new database, want 0", len(snaps))
+	}
+	// Pretend the latest migration hasn't run yet.
+	latest := migrationList()[len(migrationList())-1].version
+	if _, err := db.db.Exec(`DELETE FROM schema_version WHERE version = ?`, latest); err != nil {
+		t.Fatal(err)
+	}
+	mustSucceed(t, db.Close())
+
+	db, err = OpenWithSnapshots(dbPath, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	snaps, err := ListSnapshots(snapDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 1 || snaps[0].Reason != "pre-migration" {
+		t.Errorf("snapshots = %+v, want one pre-migration snapshot", snaps)
+	}
+}
+
+func TestParseSnapshotName(t *testing.T) {
+	snap, ok := parseSnapshotName("20240301-120000.000-pre-reindex.db")
+	if !ok {
+		t.Fatal("expected snapshot name to parse")
+	}
+	if snap.Reason != "pre-reindex" {
+		t.Errorf("Reason = %q, want pre-reindex", snap.Reason)
+	}
+	if want := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local); !snap.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v", snap.CreatedAt, want)
+	}
+
+	for _, name := range []string{"mindcli.db", "notes.txt", "20240301-120000.000.db", "20240301-120000.000-manual"} {
+		if _, ok := parseSnapshotName(name); ok {
+			t.Errorf("parseSnapshotName(%q) accepted a non-snapshot", name)
+		}
+	}
+}
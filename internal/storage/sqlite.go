@@ -5,9 +5,13 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -19,13 +23,32 @@ var ErrNotFound = errors.New("document not found")
 // ErrCollectionExists is returned when a collection name already exists.
 var ErrCollectionExists = errors.New("collection already exists")
 
-// DB wraps a SQLite database connection.
+const (
+	// readerConns is the size of the read-only pool. In WAL mode readers
+	// never block on the writer, so searches keep working during indexing.
+	readerConns = 4
+
+	// readTimeout bounds reads whose context has no deadline of its own.
+	readTimeout = 30 * time.Second
+)
+
+// DB wraps a SQLite database with a single writer connection and a pool of
+// read-only connections.
 type DB struct {
-	db *sql.DB
+	db *sql.DB // writer: all writes and migrations
+	ro *sql.DB // read-only pool for queries
+
+	snapshots SnapshotPolicy
+	paths     atomic.Pointer[pathMapper]
+	readOnly  bool // opened with OpenReadOnly; db and ro are the same pool
 }
 
 // Open opens a SQLite database at the given path.
 func Open(path string) (*DB, error) {
+	return open(path, SnapshotPolicy{})
+}
+
+func open(path string, snapshots SnapshotPolicy) (*DB, error) {
 	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=1")
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %w", err)
@@ -36,18 +59,50 @@ func Open(path string) (*DB, error) {
 	db.SetMaxIdleConns(1)
 	db.SetConnMaxLifetime(0)
 
-	store := &DB{db: db}
+	store := &DB{db: db, snapshots: snapshots}
 	if err := store.migrate(); err != nil {
 		_ = db.Close()
-		return nil, fmt.Errorf("running migrations: %w", err)
+		return nil, fmt.Errorf("running migrations: %w", corruptErr(err))
+	}
+
+	// Open readers only after migrations so they see the final schema.
+	ro, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_query_only=1")
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("opening read pool: %w", err)
+	}
+	ro.SetMaxOpenConns(readerConns)
+	ro.SetMaxIdleConns(readerConns)
+	store.ro = ro
+
+	// Store paths under the home directory portably until the caller
+	// configures its roots.
+	if err := store.SetPathRoots(context.Background(), nil); err != nil {
+		_ = store.Close()
+		return nil, fmt.Errorf("converting document paths: %w", corruptErr(err))
 	}
 
 	return store, nil
 }
 
-// Close closes the database connection.
+// Close closes the database connections.
 func (d *DB) Close() error {
-	return d.db.Close()
+	if d.readOnly {
+		return d.db.Close()
+	}
+	roErr := d.ro.Close()
+	if err := d.db.Close(); err != nil {
+		return err
+	}
+	return roErr
+}
+
+// readContext bounds ctx by readTimeout unless it already has a deadline.
+func (d *DB) readContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, readTimeout)
 }
 
 // migration is an ordered, versioned set of schema statements applied in a
@@ -70,7 +125,14 @@ func (d *DB) migrate() error {
 		return err
 	}
 
-	for _, m := range migrationList() {
+	migrations := migrationList()
+	if current > 0 && current < migrations[len(migrations)-1].version {
+		if _, err := d.AutoSnapshot(context.Background(), "pre-migration"); err != nil {
+			return fmt.Errorf("snapshot before migration: %w", err)
+		}
+	}
+
+	for _, m := range migrations {
 		if m.version <= current {
 			continue
 		}
@@ -79,7 +141,7 @@ func (d *DB) migrate() error {
 			return fmt.Errorf("starting migration %d: %w", m.version, err)
 		}
 		for _, stmt := range m.stmts {
-			if _, err := tx.Exec(stmt); err != nil {
+			if _, err := tx.Exec(stmt); err != nil && !columnExists(stmt, err) {
 				_ = tx.Rollback()
 				return fmt.Errorf("applying migration %d: %w", m.version, err)
 			}
@@ -96,6 +158,13 @@ func (d *DB) migrate() error {
 	return nil
 }
 
+// columnExists reports whether err is an ADD COLUMN statement failing
+// because the column is already there, so column migrations are as safe to
+// re-run as the IF NOT EXISTS ones.
+func columnExists(stmt string, err error) bool {
+	return strings.Contains(stmt, "ADD COLUMN") && strings.Contains(err.Error(), "duplicate column name")
+}
+
 // schemaVersion returns the highest applied migration version (0 if none).
 func (d *DB) schemaVersion() (int, error) {
 	var version int
@@ -157,19 +226,123 @@ func migrationList() []migration {
 			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_collection_documents_doc ON collection_documents(document_id)`,
+	}}, {version: 2, stmts: []string{
+		`CREATE TABLE IF NOT EXISTS index_errors (
+			path TEXT PRIMARY KEY,
+			source TEXT NOT NULL,
+			error TEXT NOT NULL,
+			failed_at DATETIME NOT NULL
+		)`,
+	}}, {version: 3, stmts: []string{
+		`CREATE INDEX IF NOT EXISTS idx_documents_modified_at ON documents(modified_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_source_modified_at ON documents(source, modified_at)`,
+	}}, {version: 4, stmts: []string{
+		`CREATE TABLE IF NOT EXISTS sync_records (
+			key TEXT PRIMARY KEY,
+			data TEXT NOT NULL
+		)`,
+	}}, {version: 5, stmts: []string{
+		`CREATE TABLE IF NOT EXISTS path_roots (
+			label TEXT PRIMARY KEY,
+			dir TEXT NOT NULL
+		)`,
+	}}, {version: 6, stmts: []string{
+		`ALTER TABLE documents ADD COLUMN document_date DATETIME`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_document_date ON documents(document_date)`,
+	}}, {version: 7, stmts: []string{
+		`CREATE TABLE IF NOT EXISTS aliases (
+			name TEXT PRIMARY KEY,
+			document_id TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_aliases_document_id ON aliases(document_id)`,
+	}}, {version: 8, stmts: []string{
+		`CREATE TABLE IF NOT EXISTS index_journal (
+			path TEXT PRIMARY KEY,
+			source TEXT NOT NULL,
+			document_id TEXT NOT NULL DEFAULT '',
+			started_at DATETIME NOT NULL
+		)`,
+	}}, {version: 9, stmts: []string{
+		`CREATE TABLE IF NOT EXISTS entities (
+			document_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			name TEXT NOT NULL,
+			PRIMARY KEY (document_id, kind, name),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_entities_kind_name ON entities(kind, name)`,
+	}}, {version: 10, stmts: []string{
+		`CREATE TABLE IF NOT EXISTS participants (
+			document_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			address TEXT NOT NULL,
+			name TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (document_id, role, address),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_participants_address ON participants(address)`,
+	}}, {version: 11, stmts: []string{
+		`CREATE TABLE IF NOT EXISTS document_relations (
+			document_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			target TEXT NOT NULL,
+			PRIMARY KEY (document_id, kind, target),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		)`,
+	}}, {version: 12, stmts: []string{
+		`CREATE TABLE IF NOT EXISTS answer_cache (
+			key TEXT PRIMARY KEY,
+			question TEXT NOT NULL,
+			answer TEXT NOT NULL,
+			sources TEXT NOT NULL,
+			confidence_level TEXT NOT NULL DEFAULT '',
+			confidence_score REAL NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL,
+			generation INTEGER NOT NULL DEFAULT 0
+		)`,
+		`CREATE TABLE IF NOT EXISTS index_generation (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			generation INTEGER NOT NULL
+		)`,
+		`INSERT OR IGNORE INTO index_generation (id, generation) VALUES (1, 0)`,
+		`CREATE TRIGGER IF NOT EXISTS documents_generation_insert AFTER INSERT ON documents
+		BEGIN UPDATE index_generation SET generation = generation + 1; END`,
+		`CREATE TRIGGER IF NOT EXISTS documents_generation_update AFTER UPDATE ON documents
+		BEGIN UPDATE index_generation SET generation = generation + 1; END`,
+		`CREATE TRIGGER IF NOT EXISTS documents_generation_delete AFTER DELETE ON documents
+		BEGIN UPDATE index_generation SET generation = generation + 1; END`,
+	}}, {version: 13, stmts: []string{
+		`CREATE TABLE IF NOT EXISTS interactions (
+			document_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			at DATETIME NOT NULL,
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_interactions_kind ON interactions(kind, document_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_indexed_at ON documents(indexed_at)`,
 	}}}
 }
 
+// nullTime stores a zero time as NULL.
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
 // InsertDocument inserts a new document into the database.
 func (d *DB) InsertDocument(ctx context.Context, doc *Document) error {
 	query := `
-		INSERT INTO documents (id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO documents (id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	_, err := d.db.ExecContext(ctx, query,
 		doc.ID,
 		doc.Source,
-		doc.Path,
+		d.storedPath(doc.Path),
 		doc.Title,
 		doc.Content,
 		doc.Preview,
@@ -177,6 +350,7 @@ func (d *DB) InsertDocument(ctx context.Context, doc *Document) error {
 		doc.ContentHash,
 		doc.IndexedAt.UTC(),
 		doc.ModifiedAt.UTC(),
+		nullTime(doc.DocumentDate),
 	)
 	if err != nil {
 		return fmt.Errorf("inserting document: %w", err)
@@ -189,12 +363,13 @@ func (d *DB) UpdateDocument(ctx context.Context, doc *Document) error {
 	query := `
 		UPDATE documents
 		SET source = ?, path = ?, title = ?, content = ?, preview = ?,
-			metadata = ?, content_hash = ?, indexed_at = ?, modified_at = ?
+			metadata = ?, content_hash = ?, indexed_at = ?, modified_at = ?,
+			document_date = ?
 		WHERE id = ?
 	`
 	result, err := d.db.ExecContext(ctx, query,
 		doc.Source,
-		doc.Path,
+		d.storedPath(doc.Path),
 		doc.Title,
 		doc.Content,
 		doc.Preview,
@@ -202,6 +377,7 @@ func (d *DB) UpdateDocument(ctx context.Context, doc *Document) error {
 		doc.ContentHash,
 		doc.IndexedAt.UTC(),
 		doc.ModifiedAt.UTC(),
+		nullTime(doc.DocumentDate),
 		doc.ID,
 	)
 	if err != nil {
@@ -218,11 +394,58 @@ func (d *DB) UpdateDocument(ctx context.Context, doc *Document) error {
 	return nil
 }
 
+// execer is satisfied by both *sql.DB and *sql.Tx so statements can be
+// shared between single writes and transactions.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
 // UpsertDocument inserts or updates a document.
 func (d *DB) UpsertDocument(ctx context.Context, doc *Document) error {
+	return d.upsertDocument(ctx, d.db, doc)
+}
+
+// SaveDocument upserts doc and replaces its chunks in a single transaction,
+// so a crash never leaves a document with a partial set of chunks. A nil
+// chunks slice leaves the existing chunks untouched; an empty one removes
+// them.
+func (d *DB) SaveDocument(ctx context.Context, doc *Document, chunks []*Chunk) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := d.upsertDocument(ctx, tx, doc); err != nil {
+		return err
+	}
+
+	if chunks != nil {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM chunks WHERE document_id = ?", doc.ID); err != nil {
+			return fmt.Errorf("deleting chunks: %w", err)
+		}
+		stmt, err := tx.PrepareContext(ctx, `INSERT INTO chunks (id, document_id, content, start_pos, end_pos) VALUES (?, ?, ?, ?, ?)`)
+		if err != nil {
+			return fmt.Errorf("preparing chunk insert: %w", err)
+		}
+		defer func() { _ = stmt.Close() }()
+		for _, c := range chunks {
+			if _, err := stmt.ExecContext(ctx, c.ID, doc.ID, c.Content, c.StartPos, c.EndPos); err != nil {
+				return fmt.Errorf("inserting chunk: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing document: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) upsertDocument(ctx context.Context, ex execer, doc *Document) error {
 	query := `
-		INSERT INTO documents (id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO documents (id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			source = excluded.source,
 			path = excluded.path,
@@ -232,12 +455,13 @@ func (d *DB) UpsertDocument(ctx context.Context, doc *Document) error {
 			metadata = excluded.metadata,
 			content_hash = excluded.content_hash,
 			indexed_at = excluded.indexed_at,
-			modified_at = excluded.modified_at
+			modified_at = excluded.modified_at,
+			document_date = excluded.document_date
 	`
-	_, err := d.db.ExecContext(ctx, query,
+	_, err := ex.ExecContext(ctx, query,
 		doc.ID,
 		doc.Source,
-		doc.Path,
+		d.storedPath(doc.Path),
 		doc.Title,
 		doc.Content,
 		doc.Preview,
@@ -245,6 +469,7 @@ func (d *DB) UpsertDocument(ctx context.Context, doc *Document) error {
 		doc.ContentHash,
 		doc.IndexedAt.UTC(),
 		doc.ModifiedAt.UTC(),
+		nullTime(doc.DocumentDate),
 	)
 	if err != nil {
 		return fmt.Errorf("upserting document: %w", err)
@@ -254,24 +479,151 @@ func (d *DB) UpsertDocument(ctx context.Context, doc *Document) error {
 
 // GetDocument retrieves a document by ID.
 func (d *DB) GetDocument(ctx context.Context, id string) (*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at
+		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date
 		FROM documents WHERE id = ?
 	`
-	row := d.db.QueryRowContext(ctx, query, id)
+	row := d.ro.QueryRowContext(ctx, query, id)
+	return d.scanDocument(row)
+}
+
+// GetDocumentByContentHash retrieves the most recently modified document with
+// the given content hash.
+func (d *DB) GetDocumentByContentHash(ctx context.Context, hash string) (*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date
+		FROM documents WHERE content_hash = ? ORDER BY modified_at DESC LIMIT 1
+	`
+	row := d.ro.QueryRowContext(ctx, query, hash)
 	return d.scanDocument(row)
 }
 
 // GetDocumentByPath retrieves a document by its path.
 func (d *DB) GetDocumentByPath(ctx context.Context, path string) (*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at
+		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date
 		FROM documents WHERE path = ?
 	`
-	row := d.db.QueryRowContext(ctx, query, path)
+	row := d.ro.QueryRowContext(ctx, query, d.storedPath(path))
 	return d.scanDocument(row)
 }
 
+// ResolveLink finds the markdown document a wiki link target refers to. The
+// target is matched case-insensitively against titles, then file names
+// (without extension), then frontmatter aliases. Any "|display" suffix or
+// "#heading" anchor on the target is ignored.
+func (d *DB) ResolveLink(ctx context.Context, target string) (*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	if i := strings.Index(target, "|"); i >= 0 {
+		target = target[:i]
+	}
+	if i := strings.Index(target, "#"); i >= 0 {
+		target = target[:i]
+	}
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, ErrNotFound
+	}
+
+	const cols = `SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date FROM documents`
+
+	row := d.ro.QueryRowContext(ctx,
+		cols+` WHERE source = ? AND title = ? COLLATE NOCASE ORDER BY modified_at DESC LIMIT 1`,
+		SourceMarkdown, target,
+	)
+	if doc, err := d.scanDocument(row); err != ErrNotFound {
+		return doc, err
+	}
+
+	// Obsidian-style links name the file, optionally with a folder prefix.
+	base := strings.ToLower(target)
+	rows, err := d.ro.QueryContext(ctx,
+		cols+` WHERE source = ? AND (lower(path) LIKE ? OR metadata LIKE ?) ORDER BY modified_at DESC`,
+		SourceMarkdown, "%"+base+".%", "%"+base+"%",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("resolving link: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var aliasMatch *Document
+	for rows.Next() {
+		doc, err := d.scanDocumentRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		p := strings.ToLower(filepath.ToSlash(doc.Path))
+		stem := strings.TrimSuffix(p, filepath.Ext(p))
+		if stem == base || strings.HasSuffix(stem, "/"+base) {
+			return doc, nil
+		}
+		if aliasMatch == nil && hasAlias(doc.Metadata["fm_aliases"], target) {
+			aliasMatch = doc
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating link candidates: %w", err)
+	}
+	if aliasMatch != nil {
+		return aliasMatch, nil
+	}
+	return nil, ErrNotFound
+}
+
+// hasAlias reports whether a comma-separated alias list contains name.
+func hasAlias(aliases, name string) bool {
+	for _, a := range strings.Split(aliases, ",") {
+		if strings.EqualFold(strings.Trim(strings.TrimSpace(a), `"'`), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListSections returns the sections (heading sections, data rows or
+// references) indexed for the document with the given ID and path.
+func (d *DB) ListSections(ctx context.Context, id, path string) ([]*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	// Section paths are the document's path plus "#anchor".
+	prefix := d.storedPath(path) + "#"
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date
+		FROM documents WHERE path >= ? AND path < ?
+	`, prefix, prefixUpperBound(prefix))
+	if err != nil {
+		return nil, fmt.Errorf("listing sections: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var sections []*Document
+	for rows.Next() {
+		doc, err := d.scanDocumentRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		if doc.Metadata["section_of"] == id {
+			sections = append(sections, doc)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing sections: %w", err)
+	}
+	return sections, nil
+}
+
 // DeleteDocument deletes a document by ID.
 func (d *DB) DeleteDocument(ctx context.Context, id string) error {
 	result, err := d.db.ExecContext(ctx, "DELETE FROM documents WHERE id = ?", id)
@@ -291,7 +643,7 @@ func (d *DB) DeleteDocument(ctx context.Context, id string) error {
 
 // DeleteDocumentByPath deletes a document by its path.
 func (d *DB) DeleteDocumentByPath(ctx context.Context, path string) error {
-	result, err := d.db.ExecContext(ctx, "DELETE FROM documents WHERE path = ?", path)
+	result, err := d.db.ExecContext(ctx, "DELETE FROM documents WHERE path = ?", d.storedPath(path))
 	if err != nil {
 		return fmt.Errorf("deleting document: %w", err)
 	}
@@ -306,25 +658,134 @@ func (d *DB) DeleteDocumentByPath(ctx context.Context, path string) error {
 	return nil
 }
 
-// ListDocuments returns all documents, optionally filtered by source.
-func (d *DB) ListDocuments(ctx context.Context, source Source) ([]*Document, error) {
-	var query string
-	var args []interface{}
+// ListPathsUnder returns the paths of all documents stored below dir.
+func (d *DB) ListPathsUnder(ctx context.Context, dir string) ([]string, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	var paths []string
+	// Byte-wise range scans avoid LIKE wildcard escaping; each prefix ends
+	// in a separator, see prefixUpperBound.
+	for _, prefix := range d.paths.Load().storedPrefixesUnder(dir) {
+		rows, err := d.ro.QueryContext(ctx,
+			"SELECT path FROM documents WHERE path >= ? AND path < ?", prefix, prefixUpperBound(prefix))
+		if err != nil {
+			return nil, fmt.Errorf("listing paths: %w", err)
+		}
+		for rows.Next() {
+			var p string
+			if err := rows.Scan(&p); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("scanning path: %w", err)
+			}
+			paths = append(paths, d.localPath(p))
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("listing paths: %w", err)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// ListDocuments returns documents ordered by modification time (newest
+// first), optionally filtered by source. limit <= 0 returns every document
+// from offset onwards.
+func (d *DB) ListDocuments(ctx context.Context, source Source, offset, limit int) ([]*Document, error) {
+	return d.listDocuments(ctx, "content", sourceList(source), offset, limit)
+}
+
+// ListDocumentSummaries is like ListDocuments but leaves Content empty, which
+// keeps browsing large libraries cheap. Fetch the full document with
+// GetDocument when its content is needed.
+func (d *DB) ListDocumentSummaries(ctx context.Context, source Source, offset, limit int) ([]*Document, error) {
+	return d.listDocuments(ctx, "''", sourceList(source), offset, limit)
+}
+
+// ListDocumentSummariesIn is ListDocumentSummaries for documents from any of
+// several sources. An empty list lists every source.
+func (d *DB) ListDocumentSummariesIn(ctx context.Context, sources []Source, offset, limit int) ([]*Document, error) {
+	return d.listDocuments(ctx, "''", sources, offset, limit)
+}
+
+// ListDocumentFootprints returns the size of every document of source and
+// whether the user kept it, oldest first.
+func (d *DB) ListDocumentFootprints(ctx context.Context, source Source) ([]DocumentFootprint, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT d.id, d.path, d.title, d.modified_at, d.document_date, length(CAST(d.content AS BLOB)),
+			EXISTS (SELECT 1 FROM document_tags t WHERE t.document_id = d.id AND t.manual = 1)
+			OR EXISTS (SELECT 1 FROM collection_documents c WHERE c.document_id = d.id)
+			OR EXISTS (SELECT 1 FROM aliases a WHERE a.document_id = d.id)
+		FROM documents d
+		WHERE d.source = ?
+		ORDER BY d.id
+	`, source)
+	if err != nil {
+		return nil, fmt.Errorf("listing document footprints: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var footprints []DocumentFootprint
+	for rows.Next() {
+		var f DocumentFootprint
+		var modifiedAt time.Time
+		var documentDate sql.NullTime
+		if err := rows.Scan(&f.ID, &f.Path, &f.Title, &modifiedAt, &documentDate, &f.Size, &f.Kept); err != nil {
+			return nil, fmt.Errorf("scanning document footprint: %w", err)
+		}
+		f.Path = d.localPath(f.Path)
+		f.Date = modifiedAt
+		if documentDate.Valid && !documentDate.Time.IsZero() {
+			f.Date = documentDate.Time
+		}
+		footprints = append(footprints, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating document footprints: %w", err)
+	}
+	sort.SliceStable(footprints, func(i, j int) bool { return footprints[i].Date.Before(footprints[j].Date) })
+	return footprints, nil
+}
 
+func sourceList(source Source) []Source {
 	if source == "" {
-		query = `
-			SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at
-			FROM documents ORDER BY modified_at DESC
-		`
-	} else {
-		query = `
-			SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at
-			FROM documents WHERE source = ? ORDER BY modified_at DESC
-		`
-		args = append(args, source)
+		return nil
+	}
+	return []Source{source}
+}
+
+// listDocuments selects a page of documents, using contentExpr for the
+// content column.
+func (d *DB) listDocuments(ctx context.Context, contentExpr string, sources []Source, offset, limit int) ([]*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `SELECT id, source, path, title, ` + contentExpr + `, preview, metadata, content_hash, indexed_at, modified_at, document_date
+		FROM documents`
+	var args []interface{}
+	if len(sources) > 0 {
+		query += ` WHERE source IN (?` + strings.Repeat(`, ?`, len(sources)-1) + `)`
+		for _, s := range sources {
+			args = append(args, s)
+		}
 	}
+	// id breaks ties so pages don't overlap when modification times match.
+	query += ` ORDER BY modified_at DESC, id LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
 
-	rows, err := d.db.QueryContext(ctx, query, args...)
+	rows, err := d.ro.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying documents: %w", err)
 	}
@@ -348,35 +809,187 @@ func (d *DB) ListDocuments(ctx context.Context, source Source) ([]*Document, err
 
 // CountDocuments returns the total number of documents.
 func (d *DB) CountDocuments(ctx context.Context) (int, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
 	var count int
-	err := d.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM documents").Scan(&count)
+	err := d.ro.QueryRowContext(ctx, "SELECT COUNT(*) FROM documents").Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("counting documents: %w", err)
 	}
 	return count, nil
 }
 
+// IndexGeneration returns a counter that grows whenever a document is
+// added, changed or removed, by this process or any other, so a long-running
+// reader can tell its results are stale.
+func (d *DB) IndexGeneration(ctx context.Context) (int64, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	var generation int64
+	err := d.ro.QueryRowContext(ctx, "SELECT generation FROM index_generation WHERE id = 1").Scan(&generation)
+	if err != nil {
+		return 0, fmt.Errorf("reading index generation: %w", err)
+	}
+	return generation, nil
+}
+
 // CountDocumentsBySource returns the number of documents by source.
 func (d *DB) CountDocumentsBySource(ctx context.Context, source Source) (int, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	var count int
+	err := d.ro.QueryRowContext(ctx, "SELECT COUNT(*) FROM documents WHERE source = ?", source).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("counting documents: %w", err)
+	}
+	return count, nil
+}
+
+// CountDocumentsWithMetadata returns the number of documents that have the
+// given metadata key set.
+func (d *DB) CountDocumentsWithMetadata(ctx context.Context, key string) (int, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
 	var count int
-	err := d.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM documents WHERE source = ?", source).Scan(&count)
+	err := d.ro.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM documents WHERE json_extract(metadata, ?) IS NOT NULL`,
+		metadataPath(key),
+	).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("counting documents: %w", err)
 	}
 	return count, nil
 }
 
+// CountDocumentWords sums up the words counted in the documents, and lists the
+// largest n. Sections are left out, their words being counted with their
+// documents', as are documents indexed before words were counted.
+func (d *DB) CountDocumentWords(ctx context.Context, n int) (WordStats, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	const counted = `FROM documents
+		WHERE json_extract(metadata, '$.words') IS NOT NULL AND json_extract(metadata, '$.section_of') IS NULL`
+	var stats WordStats
+	err := d.ro.QueryRowContext(ctx,
+		`SELECT COUNT(*), COALESCE(SUM(CAST(json_extract(metadata, '$.words') AS INTEGER)), 0) `+counted,
+	).Scan(&stats.Documents, &stats.Words)
+	if err != nil {
+		return stats, fmt.Errorf("counting words: %w", err)
+	}
+
+	rows, err := d.ro.QueryContext(ctx,
+		`SELECT id, path, title, CAST(json_extract(metadata, '$.words') AS INTEGER) AS words `+counted+`
+		ORDER BY words DESC, path LIMIT ?`, n,
+	)
+	if err != nil {
+		return stats, fmt.Errorf("finding the largest documents: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var w DocumentWords
+		if err := rows.Scan(&w.ID, &w.Path, &w.Title, &w.Words); err != nil {
+			return stats, fmt.Errorf("scanning word count: %w", err)
+		}
+		w.Path = d.localPath(w.Path)
+		stats.Largest = append(stats.Largest, w)
+	}
+	return stats, rows.Err()
+}
+
+// FindByMetadata returns documents whose metadata value for key contains
+// value, ignoring ASCII case. An empty value matches any document that has
+// the key set.
+func (d *DB) FindByMetadata(ctx context.Context, key, value string) ([]*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	sqlQuery := `
+		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date
+		FROM documents
+		WHERE json_extract(metadata, ?) LIKE ? ESCAPE '\'
+		ORDER BY modified_at DESC, id
+	`
+	rows, err := d.ro.QueryContext(ctx, sqlQuery, metadataPath(key), "%"+escapeLike(value)+"%")
+	if err != nil {
+		return nil, fmt.Errorf("finding by metadata: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var docs []*Document
+	for rows.Next() {
+		doc, err := d.scanDocumentRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// FindIDsByMetadata is FindByMetadata returning only the IDs of the
+// documents, in no particular order.
+func (d *DB) FindIDsByMetadata(ctx context.Context, key, value string) ([]string, error) {
+	ids, err := d.documentIDs(ctx, `json_extract(metadata, ?) LIKE ? ESCAPE '\'`, metadataPath(key), "%"+escapeLike(value)+"%")
+	if err != nil {
+		return nil, fmt.Errorf("finding by metadata: %w", err)
+	}
+	return ids, nil
+}
+
+// documentIDs returns the IDs of the documents satisfying the SQL condition
+// where, whose placeholders take args.
+func (d *DB) documentIDs(ctx context.Context, where string, args ...any) ([]string, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, "SELECT id FROM documents WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// metadataPath returns the JSON path selecting key in the metadata object.
+// The key is quoted so names containing dots or spaces are taken literally.
+func metadataPath(key string) string {
+	quoted, _ := json.Marshal(key)
+	return "$." + string(quoted)
+}
+
+// escapeLike escapes LIKE wildcards in s for use with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // SearchDocuments performs a simple text search on title and content.
 func (d *DB) SearchDocuments(ctx context.Context, query string, limit int) ([]*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
 	sqlQuery := `
-		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at
+		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date
 		FROM documents
 		WHERE title LIKE ? OR content LIKE ?
 		ORDER BY modified_at DESC
 		LIMIT ?
 	`
 	pattern := "%" + query + "%"
-	rows, err := d.db.QueryContext(ctx, sqlQuery, pattern, pattern, limit)
+	rows, err := d.ro.QueryContext(ctx, sqlQuery, pattern, pattern, limit)
 	if err != nil {
 		return nil, fmt.Errorf("searching documents: %w", err)
 	}
@@ -410,8 +1023,11 @@ func (d *DB) InsertChunk(ctx context.Context, chunk *Chunk) error {
 
 // GetChunksByDocument retrieves all chunks for a document.
 func (d *DB) GetChunksByDocument(ctx context.Context, documentID string) ([]*Chunk, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
 	query := `SELECT id, document_id, content, start_pos, end_pos FROM chunks WHERE document_id = ? ORDER BY start_pos`
-	rows, err := d.db.QueryContext(ctx, query, documentID)
+	rows, err := d.ro.QueryContext(ctx, query, documentID)
 	if err != nil {
 		return nil, fmt.Errorf("querying chunks: %w", err)
 	}
@@ -433,33 +1049,79 @@ func (d *DB) GetChunksByDocument(ctx context.Context, documentID string) ([]*Chu
 	return chunks, nil
 }
 
-// DeleteChunksByDocument deletes all chunks for a document.
-func (d *DB) DeleteChunksByDocument(ctx context.Context, documentID string) error {
-	_, err := d.db.ExecContext(ctx, "DELETE FROM chunks WHERE document_id = ?", documentID)
+// GetChunk retrieves a chunk by its ID ("docID:index"). It returns
+// ErrNotFound when there is no such chunk.
+func (d *DB) GetChunk(ctx context.Context, id string) (*Chunk, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	var chunk Chunk
+	err := d.ro.QueryRowContext(ctx, `SELECT id, document_id, content, start_pos, end_pos FROM chunks WHERE id = ?`, id).
+		Scan(&chunk.ID, &chunk.DocumentID, &chunk.Content, &chunk.StartPos, &chunk.EndPos)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
 	if err != nil {
-		return fmt.Errorf("deleting chunks: %w", err)
+		return nil, fmt.Errorf("querying chunk: %w", err)
 	}
-	return nil
+	return &chunk, nil
 }
 
-// scanDocument scans a single row into a Document.
-func (d *DB) scanDocument(row *sql.Row) (*Document, error) {
-	var doc Document
-	var metadataJSON string
-	var indexedAt, modifiedAt time.Time
+// ListChunkSummaries returns every chunk without its content, ordered by
+// document.
+func (d *DB) ListChunkSummaries(ctx context.Context) ([]*Chunk, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
 
-	err := row.Scan(
-		&doc.ID,
-		&doc.Source,
-		&doc.Path,
-		&doc.Title,
-		&doc.Content,
-		&doc.Preview,
-		&metadataJSON,
-		&doc.ContentHash,
-		&indexedAt,
-		&modifiedAt,
-	)
+	rows, err := d.ro.QueryContext(ctx, `SELECT id, document_id, start_pos, end_pos FROM chunks ORDER BY document_id, start_pos`)
+	if err != nil {
+		return nil, fmt.Errorf("querying chunks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var chunks []*Chunk
+	for rows.Next() {
+		var chunk Chunk
+		if err := rows.Scan(&chunk.ID, &chunk.DocumentID, &chunk.StartPos, &chunk.EndPos); err != nil {
+			return nil, fmt.Errorf("scanning chunk: %w", err)
+		}
+		chunks = append(chunks, &chunk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+// DeleteChunksByDocument deletes all chunks for a document.
+func (d *DB) DeleteChunksByDocument(ctx context.Context, documentID string) error {
+	_, err := d.db.ExecContext(ctx, "DELETE FROM chunks WHERE document_id = ?", documentID)
+	if err != nil {
+		return fmt.Errorf("deleting chunks: %w", err)
+	}
+	return nil
+}
+
+// scanDocument scans a single row into a Document.
+func (d *DB) scanDocument(row *sql.Row) (*Document, error) {
+	var doc Document
+	var metadataJSON string
+	var indexedAt, modifiedAt time.Time
+	var documentDate sql.NullTime
+
+	err := row.Scan(
+		&doc.ID,
+		&doc.Source,
+		&doc.Path,
+		&doc.Title,
+		&doc.Content,
+		&doc.Preview,
+		&metadataJSON,
+		&doc.ContentHash,
+		&indexedAt,
+		&modifiedAt,
+		&documentDate,
+	)
 	if err == sql.ErrNoRows {
 		return nil, ErrNotFound
 	}
@@ -467,8 +1129,10 @@ func (d *DB) scanDocument(row *sql.Row) (*Document, error) {
 		return nil, fmt.Errorf("scanning document: %w", err)
 	}
 
+	doc.Path = d.localPath(doc.Path)
 	doc.IndexedAt = indexedAt
 	doc.ModifiedAt = modifiedAt
+	doc.DocumentDate = documentDate.Time
 	if err := doc.SetMetadataFromJSON(metadataJSON); err != nil {
 		return nil, fmt.Errorf("parsing metadata: %w", err)
 	}
@@ -481,6 +1145,7 @@ func (d *DB) scanDocumentRows(rows *sql.Rows) (*Document, error) {
 	var doc Document
 	var metadataJSON string
 	var indexedAt, modifiedAt time.Time
+	var documentDate sql.NullTime
 
 	err := rows.Scan(
 		&doc.ID,
@@ -493,13 +1158,16 @@ func (d *DB) scanDocumentRows(rows *sql.Rows) (*Document, error) {
 		&doc.ContentHash,
 		&indexedAt,
 		&modifiedAt,
+		&documentDate,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("scanning document: %w", err)
 	}
 
+	doc.Path = d.localPath(doc.Path)
 	doc.IndexedAt = indexedAt
 	doc.ModifiedAt = modifiedAt
+	doc.DocumentDate = documentDate.Time
 	if err := doc.SetMetadataFromJSON(metadataJSON); err != nil {
 		return nil, fmt.Errorf("parsing metadata: %w", err)
 	}
@@ -552,7 +1220,10 @@ func (d *DB) RemoveTag(ctx context.Context, docID, tag string) error {
 
 // GetTags returns all tags for a document (both manual and auto-extracted).
 func (d *DB) GetTags(ctx context.Context, docID string) ([]string, error) {
-	rows, err := d.db.QueryContext(ctx,
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx,
 		`SELECT tag FROM document_tags WHERE document_id = ? ORDER BY tag`,
 		docID,
 	)
@@ -574,7 +1245,10 @@ func (d *DB) GetTags(ctx context.Context, docID string) ([]string, error) {
 
 // ListAllTags returns all unique tags across all documents.
 func (d *DB) ListAllTags(ctx context.Context) ([]string, error) {
-	rows, err := d.db.QueryContext(ctx,
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx,
 		`SELECT DISTINCT tag FROM document_tags ORDER BY tag`,
 	)
 	if err != nil {
@@ -595,14 +1269,17 @@ func (d *DB) ListAllTags(ctx context.Context) ([]string, error) {
 
 // FindByTag returns all documents with a given tag.
 func (d *DB) FindByTag(ctx context.Context, tag string) ([]*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
 	sqlQuery := `
-		SELECT d.id, d.source, d.path, d.title, d.content, d.preview, d.metadata, d.content_hash, d.indexed_at, d.modified_at
+		SELECT d.id, d.source, d.path, d.title, d.content, d.preview, d.metadata, d.content_hash, d.indexed_at, d.modified_at, d.document_date
 		FROM documents d
 		INNER JOIN document_tags dt ON d.id = dt.document_id
 		WHERE dt.tag = ?
 		ORDER BY d.modified_at DESC
 	`
-	rows, err := d.db.QueryContext(ctx, sqlQuery, tag)
+	rows, err := d.ro.QueryContext(ctx, sqlQuery, tag)
 	if err != nil {
 		return nil, fmt.Errorf("finding by tag: %w", err)
 	}
@@ -619,6 +1296,60 @@ func (d *DB) FindByTag(ctx context.Context, tag string) ([]*Document, error) {
 	return docs, rows.Err()
 }
 
+// RandomDocument returns a document picked at random: one tagged tag when
+// tag isn't empty, dated before before when it isn't zero. It returns
+// ErrNotFound when no document qualifies.
+func (d *DB) RandomDocument(ctx context.Context, tag string, before time.Time) (*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	query := `SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date
+		FROM documents d WHERE 1 = 1`
+	var args []any
+	if tag != "" {
+		query += ` AND EXISTS (SELECT 1 FROM document_tags t WHERE t.document_id = d.id AND t.tag = ? COLLATE NOCASE)`
+		args = append(args, tag)
+	}
+	if !before.IsZero() {
+		// julianday compares the stored times whatever their zone.
+		query += ` AND julianday(COALESCE(document_date, modified_at)) < julianday(?)`
+		args = append(args, before.UTC())
+	}
+	query += ` ORDER BY random() LIMIT 1`
+	return d.scanDocument(d.ro.QueryRowContext(ctx, query, args...))
+}
+
+// OnThisDay returns up to limit documents dated on the month and day of
+// date in earlier years, newest first. Dates are compared as recorded, in
+// the zone they were stored with.
+func (d *DB) OnThisDay(ctx context.Context, date time.Time, limit int) ([]*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date
+		FROM documents
+		WHERE substr(COALESCE(document_date, modified_at), 6, 5) = ?
+			AND substr(COALESCE(document_date, modified_at), 1, 4) < ?
+		ORDER BY COALESCE(document_date, modified_at) DESC, id
+		LIMIT ?
+	`, date.Format("01-02"), date.Format("2006"), limit)
+	if err != nil {
+		return nil, fmt.Errorf("finding documents on this day: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var docs []*Document
+	for rows.Next() {
+		doc, err := d.scanDocumentRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
 // generateID generates a random 16-byte hex ID.
 func generateID() string {
 	b := make([]byte, 16)
@@ -664,7 +1395,10 @@ func (d *DB) CreateCollection(ctx context.Context, c *Collection) error {
 
 // GetCollection retrieves a collection by ID.
 func (d *DB) GetCollection(ctx context.Context, id string) (*Collection, error) {
-	row := d.db.QueryRowContext(ctx,
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	row := d.ro.QueryRowContext(ctx,
 		`SELECT id, name, description, query, created_at FROM collections WHERE id = ?`, id,
 	)
 	return d.scanCollection(row)
@@ -672,7 +1406,10 @@ func (d *DB) GetCollection(ctx context.Context, id string) (*Collection, error)
 
 // GetCollectionByName retrieves a collection by name.
 func (d *DB) GetCollectionByName(ctx context.Context, name string) (*Collection, error) {
-	row := d.db.QueryRowContext(ctx,
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	row := d.ro.QueryRowContext(ctx,
 		`SELECT id, name, description, query, created_at FROM collections WHERE name = ?`, name,
 	)
 	return d.scanCollection(row)
@@ -680,7 +1417,10 @@ func (d *DB) GetCollectionByName(ctx context.Context, name string) (*Collection,
 
 // ListCollections returns all collections ordered by name.
 func (d *DB) ListCollections(ctx context.Context) ([]*Collection, error) {
-	rows, err := d.db.QueryContext(ctx,
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx,
 		`SELECT id, name, description, query, created_at FROM collections ORDER BY name`,
 	)
 	if err != nil {
@@ -782,14 +1522,17 @@ func (d *DB) RemoveFromCollection(ctx context.Context, collectionID, documentID
 
 // GetCollectionDocuments returns all documents in a collection.
 func (d *DB) GetCollectionDocuments(ctx context.Context, collectionID string) ([]*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
 	sqlQuery := `
-		SELECT d.id, d.source, d.path, d.title, d.content, d.preview, d.metadata, d.content_hash, d.indexed_at, d.modified_at
+		SELECT d.id, d.source, d.path, d.title, d.content, d.preview, d.metadata, d.content_hash, d.indexed_at, d.modified_at, d.document_date
 		FROM documents d
 		INNER JOIN collection_documents cd ON d.id = cd.document_id
 		WHERE cd.collection_id = ?
 		ORDER BY cd.added_at DESC
 	`
-	rows, err := d.db.QueryContext(ctx, sqlQuery, collectionID)
+	rows, err := d.ro.QueryContext(ctx, sqlQuery, collectionID)
 	if err != nil {
 		return nil, fmt.Errorf("getting collection documents: %w", err)
 	}
@@ -808,8 +1551,11 @@ func (d *DB) GetCollectionDocuments(ctx context.Context, collectionID string) ([
 
 // CountCollectionDocuments returns the number of documents in a collection.
 func (d *DB) CountCollectionDocuments(ctx context.Context, collectionID string) (int, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
 	var count int
-	err := d.db.QueryRowContext(ctx,
+	err := d.ro.QueryRowContext(ctx,
 		`SELECT COUNT(*) FROM collection_documents WHERE collection_id = ?`, collectionID,
 	).Scan(&count)
 	if err != nil {
@@ -818,8 +1564,41 @@ func (d *DB) CountCollectionDocuments(ctx context.Context, collectionID string)
 	return count, nil
 }
 
+// ListManualTags returns every manually added tag with the document's path
+// and content hash, ordered by path and tag.
+func (d *DB) ListManualTags(ctx context.Context) ([]TagAssignment, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT d.id, d.path, d.content_hash, dt.tag
+		FROM document_tags dt
+		INNER JOIN documents d ON d.id = dt.document_id
+		WHERE dt.manual = 1
+		ORDER BY d.path, dt.tag
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("listing manual tags: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tags []TagAssignment
+	for rows.Next() {
+		var t TagAssignment
+		if err := rows.Scan(&t.Document.ID, &t.Document.Path, &t.Document.ContentHash, &t.Tag); err != nil {
+			return nil, fmt.Errorf("scanning tag: %w", err)
+		}
+		t.Document.Path = d.localPath(t.Document.Path)
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
 // GetDocumentCollections returns all collections a document belongs to.
 func (d *DB) GetDocumentCollections(ctx context.Context, documentID string) ([]*Collection, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
 	sqlQuery := `
 		SELECT c.id, c.name, c.description, c.query, c.created_at
 		FROM collections c
@@ -827,7 +1606,7 @@ func (d *DB) GetDocumentCollections(ctx context.Context, documentID string) ([]*
 		WHERE cd.document_id = ?
 		ORDER BY c.name
 	`
-	rows, err := d.db.QueryContext(ctx, sqlQuery, documentID)
+	rows, err := d.ro.QueryContext(ctx, sqlQuery, documentID)
 	if err != nil {
 		return nil, fmt.Errorf("getting document collections: %w", err)
 	}
@@ -846,6 +1625,54 @@ func (d *DB) GetDocumentCollections(ctx context.Context, documentID string) ([]*
 	return collections, rows.Err()
 }
 
+// UpdateCollectionQuery updates a collection's saved query.
+func (d *DB) UpdateCollectionQuery(ctx context.Context, id, query string) error {
+	result, err := d.db.ExecContext(ctx,
+		`UPDATE collections SET query = ? WHERE id = ?`, query, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating collection query: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListCollectionMembers returns every collection membership with the
+// document's path and content hash, ordered by collection name and path.
+func (d *DB) ListCollectionMembers(ctx context.Context) ([]CollectionMember, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT c.name, d.id, d.path, d.content_hash, cd.added_at
+		FROM collection_documents cd
+		INNER JOIN collections c ON c.id = cd.collection_id
+		INNER JOIN documents d ON d.id = cd.document_id
+		ORDER BY c.name, d.path
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("listing collection members: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var members []CollectionMember
+	for rows.Next() {
+		var m CollectionMember
+		if err := rows.Scan(&m.Collection, &m.Document.ID, &m.Document.Path, &m.Document.ContentHash, &m.AddedAt); err != nil {
+			return nil, fmt.Errorf("scanning collection member: %w", err)
+		}
+		m.Document.Path = d.localPath(m.Document.Path)
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
 // DeleteCollectionByName deletes a collection by name.
 func (d *DB) DeleteCollectionByName(ctx context.Context, name string) error {
 	result, err := d.db.ExecContext(ctx, "DELETE FROM collections WHERE name = ?", name)
@@ -861,3 +1688,670 @@ func (d *DB) DeleteCollectionByName(ctx context.Context, name string) error {
 	}
 	return nil
 }
+
+// SetAlias points the alias name at a document, replacing whatever the
+// name pointed at before. Names are case-insensitive.
+func (d *DB) SetAlias(ctx context.Context, name, documentID string) error {
+	_, err := d.db.ExecContext(ctx, `
+		INSERT INTO aliases (name, document_id, created_at) VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET document_id = excluded.document_id, created_at = excluded.created_at
+	`, NormalizeAlias(name), documentID, time.Now())
+	if err != nil {
+		return fmt.Errorf("setting alias: %w", err)
+	}
+	return nil
+}
+
+// ResolveAlias returns the document an alias points at, or ErrNotFound.
+func (d *DB) ResolveAlias(ctx context.Context, name string) (*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	row := d.ro.QueryRowContext(ctx, `
+		SELECT d.id, d.source, d.path, d.title, d.content, d.preview, d.metadata, d.content_hash, d.indexed_at, d.modified_at, d.document_date
+		FROM aliases a
+		INNER JOIN documents d ON d.id = a.document_id
+		WHERE a.name = ?
+	`, NormalizeAlias(name))
+	return d.scanDocument(row)
+}
+
+// ListAliases returns every alias with its document's path and title,
+// ordered by name.
+func (d *DB) ListAliases(ctx context.Context) ([]Alias, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT a.name, d.id, d.path, d.title, a.created_at
+		FROM aliases a
+		INNER JOIN documents d ON d.id = a.document_id
+		ORDER BY a.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("listing aliases: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var aliases []Alias
+	for rows.Next() {
+		var a Alias
+		if err := rows.Scan(&a.Name, &a.DocumentID, &a.Path, &a.Title, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning alias: %w", err)
+		}
+		a.Path = d.localPath(a.Path)
+		aliases = append(aliases, a)
+	}
+	return aliases, rows.Err()
+}
+
+// ListTitles returns the title and path of every document.
+func (d *DB) ListTitles(ctx context.Context) ([]DocumentTitle, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, `SELECT id, path, title FROM documents ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("listing titles: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var titles []DocumentTitle
+	for rows.Next() {
+		var t DocumentTitle
+		if err := rows.Scan(&t.ID, &t.Path, &t.Title); err != nil {
+			return nil, fmt.Errorf("scanning title: %w", err)
+		}
+		t.Path = d.localPath(t.Path)
+		titles = append(titles, t)
+	}
+	return titles, rows.Err()
+}
+
+// DeleteAlias removes an alias, or returns ErrNotFound.
+func (d *DB) DeleteAlias(ctx context.Context, name string) error {
+	result, err := d.db.ExecContext(ctx, `DELETE FROM aliases WHERE name = ?`, NormalizeAlias(name))
+	if err != nil {
+		return fmt.Errorf("deleting alias: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetEntities replaces the entities recorded for a document.
+func (d *DB) SetEntities(ctx context.Context, docID string, entities []Entity) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM entities WHERE document_id = ?`, docID); err != nil {
+		return fmt.Errorf("deleting entities: %w", err)
+	}
+	for _, e := range entities {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO entities (document_id, kind, name) VALUES (?, ?, ?)`,
+			docID, e.Kind, e.Name,
+		); err != nil {
+			return fmt.Errorf("inserting entity: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing entities: %w", err)
+	}
+	return nil
+}
+
+// GetEntities returns the entities recorded for a document, by kind and
+// name.
+func (d *DB) GetEntities(ctx context.Context, docID string) ([]Entity, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx,
+		`SELECT kind, name FROM entities WHERE document_id = ? ORDER BY kind, name`, docID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying entities: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entities []Entity
+	for rows.Next() {
+		var e Entity
+		if err := rows.Scan(&e.Kind, &e.Name); err != nil {
+			return nil, fmt.Errorf("scanning entity: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, rows.Err()
+}
+
+// ListEntities returns the entities of a kind, or of every kind when kind
+// is empty, with the number of documents naming each, most named first.
+func (d *DB) ListEntities(ctx context.Context, kind EntityKind) ([]EntityCount, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT kind, name, COUNT(*) AS n
+		FROM entities
+		WHERE ? = '' OR kind = ?
+		GROUP BY kind, name
+		ORDER BY n DESC, kind, name
+	`, kind, kind)
+	if err != nil {
+		return nil, fmt.Errorf("listing entities: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var counts []EntityCount
+	for rows.Next() {
+		var c EntityCount
+		if err := rows.Scan(&c.Kind, &c.Name, &c.Documents); err != nil {
+			return nil, fmt.Errorf("scanning entity: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// FindByEntity returns the documents naming an entity of the given kind
+// whose name contains name, ignoring ASCII case, newest first.
+func (d *DB) FindByEntity(ctx context.Context, kind EntityKind, name string) ([]*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date
+		FROM documents
+		WHERE id IN (SELECT document_id FROM entities WHERE kind = ? AND name LIKE ? ESCAPE '\')
+		ORDER BY modified_at DESC, id
+	`, kind, "%"+escapeLike(name)+"%")
+	if err != nil {
+		return nil, fmt.Errorf("finding by entity: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var docs []*Document
+	for rows.Next() {
+		doc, err := d.scanDocumentRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// FindIDsByEntity is FindByEntity returning only the IDs of the documents,
+// in no particular order.
+func (d *DB) FindIDsByEntity(ctx context.Context, kind EntityKind, name string) ([]string, error) {
+	ids, err := d.documentIDs(ctx, `id IN (SELECT document_id FROM entities WHERE kind = ? AND name LIKE ? ESCAPE '\')`,
+		kind, "%"+escapeLike(name)+"%")
+	if err != nil {
+		return nil, fmt.Errorf("finding by entity: %w", err)
+	}
+	return ids, nil
+}
+
+// SetRelations replaces the targets of a kind recorded for a document.
+func (d *DB) SetRelations(ctx context.Context, docID string, kind RelationKind, targets []string) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM document_relations WHERE document_id = ? AND kind = ?`, docID, kind); err != nil {
+		return fmt.Errorf("deleting relations: %w", err)
+	}
+	for _, target := range targets {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO document_relations (document_id, kind, target) VALUES (?, ?, ?)`,
+			docID, kind, target,
+		); err != nil {
+			return fmt.Errorf("inserting relation: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing relations: %w", err)
+	}
+	return nil
+}
+
+// GetRelations returns the targets of a kind recorded for a document, in
+// the order they were set.
+func (d *DB) GetRelations(ctx context.Context, docID string, kind RelationKind) ([]string, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx,
+		`SELECT target FROM document_relations WHERE document_id = ? AND kind = ? ORDER BY rowid`, docID, kind,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying relations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var targets []string
+	for rows.Next() {
+		var target string
+		if err := rows.Scan(&target); err != nil {
+			return nil, fmt.Errorf("scanning relation: %w", err)
+		}
+		targets = append(targets, target)
+	}
+	return targets, rows.Err()
+}
+
+// PutCachedAnswer stores an answer, replacing any cached under its key.
+func (d *DB) PutCachedAnswer(ctx context.Context, a *CachedAnswer) error {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	sources, err := json.Marshal(a.Sources)
+	if err != nil {
+		return fmt.Errorf("encoding answer sources: %w", err)
+	}
+	_, err = d.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO answer_cache (key, question, answer, sources, confidence_level, confidence_score, created_at, generation)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, a.Key, a.Question, a.Answer, string(sources), a.ConfidenceLevel, a.ConfidenceScore, a.CreatedAt.UTC(), a.Generation)
+	if err != nil {
+		return fmt.Errorf("caching answer: %w", err)
+	}
+	return nil
+}
+
+// GetCachedAnswer returns the answer cached under key. It returns
+// ErrNotFound if there is none, or if the index has changed since the answer
+// was generated: a document it drew on changed or was removed, or documents
+// were added that might answer better. A stale answer is left for the next
+// PutCachedAnswer under its key to replace.
+func (d *DB) GetCachedAnswer(ctx context.Context, key string) (*CachedAnswer, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	a := &CachedAnswer{Key: key}
+	var sources string
+	var generation int64
+	err := d.ro.QueryRowContext(ctx, `
+		SELECT a.question, a.answer, a.sources, a.confidence_level, a.confidence_score, a.created_at, a.generation, g.generation
+		FROM answer_cache a, index_generation g WHERE a.key = ? AND g.id = 1
+	`, key).Scan(&a.Question, &a.Answer, &sources, &a.ConfidenceLevel, &a.ConfidenceScore, &a.CreatedAt, &a.Generation, &generation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying answer cache: %w", err)
+	}
+	if a.Generation != generation {
+		return nil, ErrNotFound
+	}
+	if err := json.Unmarshal([]byte(sources), &a.Sources); err != nil {
+		return nil, fmt.Errorf("decoding answer sources: %w", err)
+	}
+
+	for _, src := range a.Sources {
+		var hash string
+		err := d.ro.QueryRowContext(ctx, `SELECT content_hash FROM documents WHERE id = ?`, src.DocumentID).Scan(&hash)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("checking answer source: %w", err)
+		}
+		if hash != src.ContentHash {
+			return nil, ErrNotFound
+		}
+	}
+	return a, nil
+}
+
+// RecordInteraction notes that the user did kind with a document, now.
+func (d *DB) RecordInteraction(ctx context.Context, docID string, kind InteractionKind) error {
+	_, err := d.db.ExecContext(ctx,
+		`INSERT INTO interactions (document_id, kind, at) VALUES (?, ?, ?)`, docID, kind, time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("recording interaction: %w", err)
+	}
+	return nil
+}
+
+// RecentInteractions returns up to limit documents the user last did kind
+// with, most recent first, without their content.
+func (d *DB) RecentInteractions(ctx context.Context, kind InteractionKind, limit int) ([]*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	// rowid orders interactions recorded within the same clock tick.
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT d.id, d.source, d.path, d.title, '', d.preview, d.metadata, d.content_hash, d.indexed_at, d.modified_at, d.document_date
+		FROM documents d
+		INNER JOIN (SELECT document_id, MAX(rowid) AS last FROM interactions WHERE kind = ? GROUP BY document_id) i
+			ON d.id = i.document_id
+		ORDER BY i.last DESC
+		LIMIT ?
+	`, kind, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing recent interactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var docs []*Document
+	for rows.Next() {
+		doc, err := d.scanDocumentRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// RecentlyIndexed returns up to limit documents, most recently indexed
+// first, without their content.
+func (d *DB) RecentlyIndexed(ctx context.Context, limit int) ([]*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT id, source, path, title, '', preview, metadata, content_hash, indexed_at, modified_at, document_date
+		FROM documents
+		ORDER BY indexed_at DESC, id
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing recently indexed documents: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var docs []*Document
+	for rows.Next() {
+		doc, err := d.scanDocumentRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// SetParticipants replaces the senders and recipients recorded for an
+// email document.
+func (d *DB) SetParticipants(ctx context.Context, docID string, participants []Participant) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM participants WHERE document_id = ?`, docID); err != nil {
+		return fmt.Errorf("deleting participants: %w", err)
+	}
+	for _, p := range participants {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO participants (document_id, role, address, name) VALUES (?, ?, ?, ?)`,
+			docID, p.Role, p.Address, p.Name,
+		); err != nil {
+			return fmt.Errorf("inserting participant: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing participants: %w", err)
+	}
+	return nil
+}
+
+// FindByParticipant returns the emails with a participant in the given role
+// whose address or display name contains value, ignoring ASCII case, newest
+// first.
+func (d *DB) FindByParticipant(ctx context.Context, role ParticipantRole, value string) ([]*Document, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	pattern := "%" + escapeLike(value) + "%"
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT id, source, path, title, content, preview, metadata, content_hash, indexed_at, modified_at, document_date
+		FROM documents
+		WHERE id IN (
+			SELECT document_id FROM participants
+			WHERE role = ? AND (address LIKE ? ESCAPE '\' OR name LIKE ? ESCAPE '\')
+		)
+		ORDER BY modified_at DESC, id
+	`, role, pattern, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("finding by participant: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var docs []*Document
+	for rows.Next() {
+		doc, err := d.scanDocumentRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// FindIDsByParticipant is FindByParticipant returning only the IDs of the
+// emails, in no particular order.
+func (d *DB) FindIDsByParticipant(ctx context.Context, role ParticipantRole, value string) ([]string, error) {
+	pattern := "%" + escapeLike(value) + "%"
+	ids, err := d.documentIDs(ctx, `id IN (
+		SELECT document_id FROM participants
+		WHERE role = ? AND (address LIKE ? ESCAPE '\' OR name LIKE ? ESCAPE '\')
+	)`, role, pattern, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("finding by participant: %w", err)
+	}
+	return ids, nil
+}
+
+// ListContacts returns up to limit correspondents, those in the most emails
+// first. A limit of 0 or less returns all of them.
+func (d *DB) ListContacts(ctx context.Context, limit int) ([]Contact, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := d.ro.QueryContext(ctx, `
+		SELECT address, MAX(name),
+			COUNT(DISTINCT document_id) AS n,
+			SUM(role = 'from'), SUM(role = 'to')
+		FROM participants
+		GROUP BY address
+		ORDER BY n DESC, address
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing contacts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var contacts []Contact
+	for rows.Next() {
+		var c Contact
+		if err := rows.Scan(&c.Address, &c.Name, &c.Messages, &c.Sent, &c.Received); err != nil {
+			return nil, fmt.Errorf("scanning contact: %w", err)
+		}
+		contacts = append(contacts, c)
+	}
+	return contacts, rows.Err()
+}
+
+// BeginJournal records that the indexer is about to write or remove the
+// document for e.Path, so the work can be redone if it is interrupted.
+func (d *DB) BeginJournal(ctx context.Context, e *JournalEntry) error {
+	if e.StartedAt.IsZero() {
+		e.StartedAt = time.Now()
+	}
+	_, err := d.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO index_journal (path, source, document_id, started_at) VALUES (?, ?, ?, ?)`,
+		e.Path, e.Source, e.DocumentID, e.StartedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("recording journal entry: %w", err)
+	}
+	return nil
+}
+
+// EndJournal removes the journal entries for paths once their work is
+// durable.
+func (d *DB) EndJournal(ctx context.Context, paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("clearing journal entries: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, path := range paths {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM index_journal WHERE path = ?`, path); err != nil {
+			return fmt.Errorf("clearing journal entries: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListJournal returns the journal entries left behind, oldest first.
+func (d *DB) ListJournal(ctx context.Context) ([]*JournalEntry, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx,
+		`SELECT path, source, document_id, started_at FROM index_journal ORDER BY started_at, path`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying journal: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []*JournalEntry
+	for rows.Next() {
+		var e JournalEntry
+		if err := rows.Scan(&e.Path, &e.Source, &e.DocumentID, &e.StartedAt); err != nil {
+			return nil, fmt.Errorf("scanning journal entry: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// RecordIndexError stores the most recent indexing failure for path,
+// replacing any earlier failure recorded for it.
+func (d *DB) RecordIndexError(ctx context.Context, e *IndexError) error {
+	if e.FailedAt.IsZero() {
+		e.FailedAt = time.Now()
+	}
+	_, err := d.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO index_errors (path, source, error, failed_at) VALUES (?, ?, ?, ?)`,
+		e.Path, e.Source, e.Error, e.FailedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("recording index error: %w", err)
+	}
+	return nil
+}
+
+// ClearIndexError forgets the failure recorded for path, if any.
+func (d *DB) ClearIndexError(ctx context.Context, path string) error {
+	if _, err := d.db.ExecContext(ctx, `DELETE FROM index_errors WHERE path = ?`, path); err != nil {
+		return fmt.Errorf("clearing index error: %w", err)
+	}
+	return nil
+}
+
+// ClearIndexErrors removes all recorded failures and returns how many were
+// removed.
+func (d *DB) ClearIndexErrors(ctx context.Context) (int, error) {
+	result, err := d.db.ExecContext(ctx, `DELETE FROM index_errors`)
+	if err != nil {
+		return 0, fmt.Errorf("clearing index errors: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("checking rows affected: %w", err)
+	}
+	return int(rows), nil
+}
+
+// ListIndexErrors returns all recorded failures, most recent first.
+func (d *DB) ListIndexErrors(ctx context.Context) ([]*IndexError, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx,
+		`SELECT path, source, error, failed_at FROM index_errors ORDER BY failed_at DESC, path`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing index errors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var errs []*IndexError
+	for rows.Next() {
+		var e IndexError
+		if err := rows.Scan(&e.Path, &e.Source, &e.Error, &e.FailedAt); err != nil {
+			return nil, fmt.Errorf("scanning index error: %w", err)
+		}
+		errs = append(errs, &e)
+	}
+	return errs, rows.Err()
+}
+
+// LoadSyncState returns the records saved by the last sync, keyed by record
+// key. The record encoding is owned by the sync package.
+func (d *DB) LoadSyncState(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	rows, err := d.ro.QueryContext(ctx, `SELECT key, data FROM sync_records`)
+	if err != nil {
+		return nil, fmt.Errorf("loading sync state: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	state := make(map[string]string)
+	for rows.Next() {
+		var key, data string
+		if err := rows.Scan(&key, &data); err != nil {
+			return nil, fmt.Errorf("scanning sync record: %w", err)
+		}
+		state[key] = data
+	}
+	return state, rows.Err()
+}
+
+// SaveSyncState replaces the saved sync records with state.
+func (d *DB) SaveSyncState(ctx context.Context, state map[string]string) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM sync_records`); err != nil {
+		return fmt.Errorf("clearing sync state: %w", err)
+	}
+	for key, data := range state {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO sync_records (key, data) VALUES (?, ?)`, key, data); err != nil {
+			return fmt.Errorf("saving sync record: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing sync state: %w", err)
+	}
+	return nil
+}
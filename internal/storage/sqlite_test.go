@@ -2,9 +2,12 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -70,8 +73,9 @@ func TestMigrationVersionAndIdempotency(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if v != 1 {
-		t.Errorf("schemaVersion = %d, want 1", v)
+	want := migrationList()[len(migrationList())-1].version
+	if v != want {
+		t.Errorf("schemaVersion = %d, want %d", v, want)
 	}
 	if err := db.Close(); err != nil {
 		t.Fatal(err)
@@ -87,8 +91,8 @@ func TestMigrationVersionAndIdempotency(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if v2 != 1 {
-		t.Errorf("schemaVersion after re-open = %d, want 1", v2)
+	if v2 != want {
+		t.Errorf("schemaVersion after re-open = %d, want %d", v2, want)
 	}
 }
 
@@ -313,6 +317,156 @@ func TestUpsertDocument(t *testing.T) {
 	}
 }
 
+func TestDocumentDateRoundTrip(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	date := time.Date(2021, 3, 14, 9, 0, 0, 0, time.UTC)
+
+	dated := &Document{ID: "dated", Source: SourceMarkdown, Path: "/notes/dated.md", ContentHash: "a", IndexedAt: now, ModifiedAt: now, DocumentDate: date}
+	undated := &Document{ID: "undated", Source: SourceMarkdown, Path: "/notes/undated.md", ContentHash: "b", IndexedAt: now, ModifiedAt: now}
+	mustSucceed(t, db.InsertDocument(ctx, dated))
+	mustSucceed(t, db.UpsertDocument(ctx, undated))
+
+	got, err := db.GetDocument(ctx, "dated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.DocumentDate.Equal(date) || !got.Date().Equal(date) {
+		t.Errorf("DocumentDate = %v, Date() = %v, want %v", got.DocumentDate, got.Date(), date)
+	}
+
+	got, err = db.GetDocument(ctx, "undated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.DocumentDate.IsZero() {
+		t.Errorf("DocumentDate = %v, want zero", got.DocumentDate)
+	}
+	if !got.Date().Equal(now) {
+		t.Errorf("Date() = %v, want the modification time %v", got.Date(), now)
+	}
+
+	// Clearing the date on update stores NULL again.
+	dated.DocumentDate = time.Time{}
+	mustSucceed(t, db.UpdateDocument(ctx, dated))
+	got, err = db.GetDocument(ctx, "dated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.DocumentDate.IsZero() {
+		t.Errorf("DocumentDate after clearing = %v, want zero", got.DocumentDate)
+	}
+}
+
+func TestListSections(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	add := func(id, path string, metadata map[string]string) {
+		t.Helper()
+		mustSucceed(t, db.InsertDocument(ctx, &Document{ID: id, Source: SourceMarkdown, Path: path, Metadata: metadata, ContentHash: id, IndexedAt: now, ModifiedAt: now}))
+	}
+	add("note", "/notes/plan.md", nil)
+	add("note#goals", "/notes/plan.md#goals", map[string]string{"section_of": "note", "anchor": "goals"})
+	add("note#risks", "/notes/plan.md#risks", map[string]string{"section_of": "note", "anchor": "risks"})
+	// A file whose name merely starts like a section path.
+	add("other", "/notes/plan.md#draft.md", nil)
+	add("plans", "/notes/plans.md#goals", map[string]string{"section_of": "plans", "anchor": "goals"})
+
+	sections, err := db.ListSections(ctx, "note", "/notes/plan.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, s := range sections {
+		ids = append(ids, s.ID)
+	}
+	if len(ids) != 2 || ids[0] != "note#goals" || ids[1] != "note#risks" {
+		t.Errorf("ListSections() = %v, want note#goals and note#risks", ids)
+	}
+}
+
+func TestResolveLink(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	docs := []*Document{
+		{ID: "title", Source: SourceMarkdown, Path: "/notes/a.md", Title: "Project Plan"},
+		{ID: "file", Source: SourceMarkdown, Path: "/notes/daily/2024-01-02.md", Title: "Tuesday"},
+		{ID: "alias", Source: SourceMarkdown, Path: "/notes/ml.md", Title: "Machine Learning",
+			Metadata: map[string]string{"fm_aliases": "ML, AI basics"}},
+		{ID: "pdf", Source: SourcePDF, Path: "/docs/Report.pdf", Title: "Report"},
+	}
+	for _, doc := range docs {
+		doc.ContentHash = "h"
+		doc.IndexedAt = now
+		doc.ModifiedAt = now
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatalf("InsertDocument() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"Project Plan", "title"},
+		{"project plan", "title"},
+		{"Project Plan|the plan", "title"},
+		{"Project Plan#Goals", "title"},
+		{"2024-01-02", "file"},
+		{"daily/2024-01-02", "file"},
+		{"ai basics", "alias"},
+		{"ML", "alias"},
+	}
+	for _, tt := range tests {
+		got, err := db.ResolveLink(ctx, tt.target)
+		if err != nil {
+			t.Errorf("ResolveLink(%q) error = %v", tt.target, err)
+			continue
+		}
+		if got.ID != tt.want {
+			t.Errorf("ResolveLink(%q) = %q, want %q", tt.target, got.ID, tt.want)
+		}
+	}
+
+	for _, target := range []string{"Report", "missing note", "  ", "#Heading"} {
+		if _, err := db.ResolveLink(ctx, target); err != ErrNotFound {
+			t.Errorf("ResolveLink(%q) error = %v, want ErrNotFound", target, err)
+		}
+	}
+}
+
+func TestListPathsUnder(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i, p := range []string{"/notes/a.md", "/notes/sub/b.md", "/notes-old/c.md", "/notes.md", "/other/d.md"} {
+		createTestDoc(t, db, fmt.Sprintf("doc-%d", i), p)
+	}
+
+	got, err := db.ListPathsUnder(context.Background(), "/notes/")
+	if err != nil {
+		t.Fatalf("ListPathsUnder() error = %v", err)
+	}
+	want := []string{"/notes/a.md", "/notes/sub/b.md"}
+	if len(got) != len(want) {
+		t.Fatalf("ListPathsUnder() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ListPathsUnder()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
 func TestDeleteDocument(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -409,7 +563,7 @@ func TestListDocuments(t *testing.T) {
 	}
 
 	// List all
-	all, err := db.ListDocuments(ctx, "")
+	all, err := db.ListDocuments(ctx, "", 0, 0)
 	if err != nil {
 		t.Fatalf("ListDocuments() error = %v", err)
 	}
@@ -418,7 +572,7 @@ func TestListDocuments(t *testing.T) {
 	}
 
 	// List by source
-	mdDocs, err := db.ListDocuments(ctx, SourceMarkdown)
+	mdDocs, err := db.ListDocuments(ctx, SourceMarkdown, 0, 0)
 	if err != nil {
 		t.Fatalf("ListDocuments(markdown) error = %v", err)
 	}
@@ -426,13 +580,193 @@ func TestListDocuments(t *testing.T) {
 		t.Errorf("ListDocuments(markdown) returned %d documents, want 2", len(mdDocs))
 	}
 
-	pdfDocs, err := db.ListDocuments(ctx, SourcePDF)
+	pdfDocs, err := db.ListDocuments(ctx, SourcePDF, 0, 0)
 	if err != nil {
 		t.Fatalf("ListDocuments(pdf) error = %v", err)
 	}
 	if len(pdfDocs) != 1 {
 		t.Errorf("ListDocuments(pdf) returned %d documents, want 1", len(pdfDocs))
 	}
+
+	// List by several sources
+	some, err := db.ListDocumentSummariesIn(ctx, []Source{SourcePDF, SourceEmail}, 0, 0)
+	if err != nil {
+		t.Fatalf("ListDocumentSummariesIn(pdf,email) error = %v", err)
+	}
+	if len(some) != 1 || some[0].ID != "pdf1" {
+		t.Errorf("ListDocumentSummariesIn(pdf,email) = %v, want pdf1", some)
+	}
+	both, err := db.ListDocumentSummariesIn(ctx, []Source{SourceMarkdown, SourcePDF}, 0, 0)
+	if err != nil {
+		t.Fatalf("ListDocumentSummariesIn(markdown,pdf) error = %v", err)
+	}
+	if len(both) != 3 {
+		t.Errorf("ListDocumentSummariesIn(markdown,pdf) returned %d documents, want 3", len(both))
+	}
+}
+
+func TestListDocumentsPagination(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for i := 0; i < 5; i++ {
+		mustSucceed(t, db.InsertDocument(ctx, &Document{
+			ID:          fmt.Sprintf("doc%d", i),
+			Source:      SourceMarkdown,
+			Path:        fmt.Sprintf("/doc%d.md", i),
+			Content:     "body",
+			ContentHash: fmt.Sprintf("h%d", i),
+			IndexedAt:   now,
+			ModifiedAt:  now.Add(time.Duration(i) * time.Hour),
+		}))
+	}
+
+	first, err := db.ListDocuments(ctx, "", 0, 2)
+	if err != nil {
+		t.Fatalf("ListDocuments() error = %v", err)
+	}
+	second, err := db.ListDocuments(ctx, "", 2, 2)
+	if err != nil {
+		t.Fatalf("ListDocuments() error = %v", err)
+	}
+	rest, err := db.ListDocuments(ctx, "", 4, 0)
+	if err != nil {
+		t.Fatalf("ListDocuments() error = %v", err)
+	}
+
+	var ids []string
+	for _, page := range [][]*Document{first, second, rest} {
+		for _, doc := range page {
+			ids = append(ids, doc.ID)
+		}
+	}
+	want := []string{"doc4", "doc3", "doc2", "doc1", "doc0"}
+	if fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Errorf("paged IDs = %v, want %v", ids, want)
+	}
+	if first[0].Content != "body" {
+		t.Errorf("ListDocuments content = %q, want full content", first[0].Content)
+	}
+}
+
+func TestListDocumentSummaries(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	mustSucceed(t, db.InsertDocument(ctx, &Document{
+		ID: "md1", Source: SourceMarkdown, Path: "/md1.md", Title: "Note", Content: "long body", Preview: "long",
+		Metadata: map[string]string{"tags": "go"}, ContentHash: "h1", IndexedAt: now, ModifiedAt: now,
+	}))
+	mustSucceed(t, db.InsertDocument(ctx, &Document{
+		ID: "pdf1", Source: SourcePDF, Path: "/doc.pdf", ContentHash: "h2", IndexedAt: now, ModifiedAt: now,
+	}))
+
+	docs, err := db.ListDocumentSummaries(ctx, SourceMarkdown, 0, 10)
+	if err != nil {
+		t.Fatalf("ListDocumentSummaries() error = %v", err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("ListDocumentSummaries(markdown) returned %d documents, want 1", len(docs))
+	}
+	doc := docs[0]
+	if doc.Content != "" {
+		t.Errorf("Content = %q, want empty", doc.Content)
+	}
+	if doc.Title != "Note" || doc.Preview != "long" || doc.Metadata["tags"] != "go" {
+		t.Errorf("summary = %+v, want title, preview and metadata populated", doc)
+	}
+}
+
+func TestListDocumentFootprints(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	for _, doc := range []*Document{
+		{ID: "tagged", Content: "é", ModifiedAt: day(4)},
+		{ID: "collected", Content: "clip", ModifiedAt: day(3)},
+		{ID: "aliased", Content: "clip", ModifiedAt: day(2)},
+		{ID: "plain", Content: "a longer clip", ModifiedAt: day(5), DocumentDate: day(1)},
+	} {
+		doc.Source, doc.Path, doc.ContentHash, doc.IndexedAt = SourceClipboard, "clipboard:"+doc.ID, doc.ID, day(6)
+		mustSucceed(t, db.InsertDocument(ctx, doc))
+	}
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "note", Source: SourceMarkdown, Path: "/note.md", ContentHash: "n", IndexedAt: day(1), ModifiedAt: day(1)}))
+	mustSucceed(t, db.AddTag(ctx, "tagged", "keep"))
+	col := &Collection{Name: "saved"}
+	mustSucceed(t, db.CreateCollection(ctx, col))
+	mustSucceed(t, db.AddToCollection(ctx, col.ID, "collected"))
+	mustSucceed(t, db.SetAlias(ctx, "short", "aliased"))
+
+	footprints, err := db.ListDocumentFootprints(ctx, SourceClipboard)
+	if err != nil {
+		t.Fatalf("ListDocumentFootprints() error = %v", err)
+	}
+	var got []string
+	for _, f := range footprints {
+		got = append(got, fmt.Sprintf("%s:%d:%v", f.ID, f.Size, f.Kept))
+	}
+	if want := "plain:13:false aliased:4:true collected:4:true tagged:2:true"; strings.Join(got, " ") != want {
+		t.Errorf("ListDocumentFootprints() = %s, want %s (oldest first)", strings.Join(got, " "), want)
+	}
+	if !footprints[0].Date.Equal(day(1)) {
+		t.Errorf("Date = %v, want the document date", footprints[0].Date)
+	}
+}
+
+func TestFindByMetadata(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	docs := []*Document{
+		{ID: "a", Source: SourceMarkdown, Path: "/a.md", Metadata: map[string]string{"fm_author": "Jane Smith"}, ContentHash: "h1", IndexedAt: now, ModifiedAt: now},
+		{ID: "b", Source: SourceMarkdown, Path: "/b.md", Metadata: map[string]string{"fm_author": "100% Doe"}, ContentHash: "h2", IndexedAt: now, ModifiedAt: now},
+		{ID: "c", Source: SourceMarkdown, Path: "/c.md", Metadata: map[string]string{"fm.author": "smith"}, ContentHash: "h3", IndexedAt: now, ModifiedAt: now},
+		{ID: "d", Source: SourceMarkdown, Path: "/d.md", ContentHash: "h4", IndexedAt: now, ModifiedAt: now},
+	}
+	for _, doc := range docs {
+		mustSucceed(t, db.InsertDocument(ctx, doc))
+	}
+
+	tests := []struct {
+		key, value string
+		want       []string
+	}{
+		{"fm_author", "smith", []string{"a"}}, // case-insensitive substring
+		{"fm_author", "100%", []string{"b"}},  // wildcards are literal
+		{"fm_author", "1_0", nil},             // _ is literal too
+		{"fm.author", "smith", []string{"c"}}, // dotted keys are not paths
+		{"fm_author", "", []string{"a", "b"}}, // any value
+		{"missing", "", nil},
+	}
+	for _, tt := range tests {
+		got, err := db.FindByMetadata(ctx, tt.key, tt.value)
+		if err != nil {
+			t.Fatalf("FindByMetadata(%q, %q) error = %v", tt.key, tt.value, err)
+		}
+		var ids []string
+		for _, doc := range got {
+			ids = append(ids, doc.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+			t.Errorf("FindByMetadata(%q, %q) = %v, want %v", tt.key, tt.value, ids, tt.want)
+		}
+		onlyIDs, err := db.FindIDsByMetadata(ctx, tt.key, tt.value)
+		if err != nil {
+			t.Fatalf("FindIDsByMetadata(%q, %q) error = %v", tt.key, tt.value, err)
+		}
+		slices.Sort(onlyIDs)
+		if fmt.Sprint(onlyIDs) != fmt.Sprint(tt.want) {
+			t.Errorf("FindIDsByMetadata(%q, %q) = %v, want %v", tt.key, tt.value, onlyIDs, tt.want)
+		}
+	}
 }
 
 func TestCountDocuments(t *testing.T) {
@@ -475,6 +809,54 @@ func TestCountDocuments(t *testing.T) {
 	}
 }
 
+func TestIndexGeneration(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	generation := func() int64 {
+		t.Helper()
+		g, err := db.IndexGeneration(ctx)
+		if err != nil {
+			t.Fatalf("IndexGeneration() error = %v", err)
+		}
+		return g
+	}
+
+	start := generation()
+	doc := &Document{ID: "gen", Source: SourceMarkdown, Path: "/notes/gen.md", Title: "Gen", ContentHash: "a"}
+	if err := db.InsertDocument(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	inserted := generation()
+	if inserted <= start {
+		t.Errorf("generation after insert = %d, want more than %d", inserted, start)
+	}
+
+	// Tagging doesn't change what the index holds.
+	if err := db.AddTag(ctx, doc.ID, "go"); err != nil {
+		t.Fatal(err)
+	}
+	if g := generation(); g != inserted {
+		t.Errorf("generation after tagging = %d, want %d", g, inserted)
+	}
+
+	doc.ContentHash = "b"
+	if err := db.UpdateDocument(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	updated := generation()
+	if updated <= inserted {
+		t.Errorf("generation after update = %d, want more than %d", updated, inserted)
+	}
+	if err := db.DeleteDocument(ctx, doc.ID); err != nil {
+		t.Fatal(err)
+	}
+	if g := generation(); g <= updated {
+		t.Errorf("generation after delete = %d, want more than %d", g, updated)
+	}
+}
+
 func TestCountDocumentsBySource(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -591,6 +973,14 @@ func TestChunks(t *testing.T) {
 		}
 	}
 
+	summaries, err := db.ListChunkSummaries(ctx)
+	if err != nil {
+		t.Fatalf("ListChunkSummaries() error = %v", err)
+	}
+	if len(summaries) != 3 || summaries[0].ID != "c1" || summaries[0].DocumentID != doc.ID || summaries[0].Content != "" {
+		t.Errorf("ListChunkSummaries() = %d chunks, first %+v; want 3 without content", len(summaries), summaries[0])
+	}
+
 	// Get chunks
 	retrieved, err := db.GetChunksByDocument(ctx, doc.ID)
 	if err != nil {
@@ -608,6 +998,17 @@ func TestChunks(t *testing.T) {
 		t.Errorf("Second chunk StartPos = %d, want 100", retrieved[1].StartPos)
 	}
 
+	chunk, err := db.GetChunk(ctx, "c2")
+	if err != nil {
+		t.Fatalf("GetChunk() error = %v", err)
+	}
+	if chunk.Content != "Second chunk" || chunk.DocumentID != doc.ID {
+		t.Errorf("GetChunk() = %+v, want the second chunk", chunk)
+	}
+	if _, err := db.GetChunk(ctx, "missing"); err != ErrNotFound {
+		t.Errorf("GetChunk(missing) error = %v, want ErrNotFound", err)
+	}
+
 	// Delete chunks
 	err = db.DeleteChunksByDocument(ctx, doc.ID)
 	if err != nil {
@@ -790,6 +1191,98 @@ func TestFindByTag(t *testing.T) {
 	}
 }
 
+func TestAliases(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	standup := &Document{ID: "a1", Source: SourceMarkdown, Path: "/notes/standup.md", Title: "Standup", ContentHash: "h", IndexedAt: now, ModifiedAt: now}
+	retro := &Document{ID: "a2", Source: SourceMarkdown, Path: "/notes/retro.md", Title: "Retro", ContentHash: "h", IndexedAt: now, ModifiedAt: now}
+	mustSucceed(t, db.InsertDocument(ctx, standup))
+	mustSucceed(t, db.InsertDocument(ctx, retro))
+
+	mustSucceed(t, db.SetAlias(ctx, " Standup ", retro.ID))
+	mustSucceed(t, db.SetAlias(ctx, "standup", standup.ID)) // re-pointed
+	mustSucceed(t, db.SetAlias(ctx, "retro", retro.ID))
+	if err := db.SetAlias(ctx, "ghost", "missing"); err == nil {
+		t.Error("SetAlias() to a missing document should fail")
+	}
+
+	doc, err := db.ResolveAlias(ctx, "STANDUP")
+	if err != nil || doc.ID != standup.ID {
+		t.Errorf("ResolveAlias() = %v, %v; want the standup note", doc, err)
+	}
+	if _, err := db.ResolveAlias(ctx, "nope"); err != ErrNotFound {
+		t.Errorf("ResolveAlias(unknown) error = %v, want ErrNotFound", err)
+	}
+
+	aliases, err := db.ListAliases(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aliases) != 2 || aliases[0].Name != "retro" || aliases[1].Name != "standup" || aliases[1].Path != standup.Path {
+		t.Errorf("ListAliases() = %+v", aliases)
+	}
+
+	// Aliases go with their document.
+	mustSucceed(t, db.DeleteDocument(ctx, retro.ID))
+	mustSucceed(t, db.DeleteAlias(ctx, "standup"))
+	if aliases, _ := db.ListAliases(ctx); len(aliases) != 0 {
+		t.Errorf("aliases left = %+v, want none", aliases)
+	}
+	if err := db.DeleteAlias(ctx, "standup"); err != ErrNotFound {
+		t.Errorf("DeleteAlias(removed) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListTitles(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Now()
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "b", Source: SourceMarkdown, Path: "/notes/b.md", Title: "Beta", Content: "long text", ContentHash: "b", IndexedAt: now, ModifiedAt: now}))
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "a", Source: SourceMarkdown, Path: "/notes/a.md", Title: "Alpha", ContentHash: "a", IndexedAt: now, ModifiedAt: now}))
+
+	titles, err := db.ListTitles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DocumentTitle{{ID: "a", Path: "/notes/a.md", Title: "Alpha"}, {ID: "b", Path: "/notes/b.md", Title: "Beta"}}
+	if !slices.Equal(titles, want) {
+		t.Errorf("ListTitles() = %+v, want %+v", titles, want)
+	}
+}
+
+func TestCountDocumentWords(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Now()
+	for _, doc := range []*Document{
+		{ID: "a", Path: "/a.md", Title: "A", Metadata: map[string]string{"words": "120"}},
+		{ID: "b", Path: "/b.md", Title: "B", Metadata: map[string]string{"words": "900"}},
+		{ID: "b1", Path: "/b.md#intro", Title: "B intro", Metadata: map[string]string{"words": "300", "section_of": "b"}},
+		{ID: "old", Path: "/old.md", Title: "Old"},
+	} {
+		doc.Source, doc.ContentHash, doc.IndexedAt, doc.ModifiedAt = SourceMarkdown, doc.ID, now, now
+		mustSucceed(t, db.InsertDocument(ctx, doc))
+	}
+
+	stats, err := db.CountDocumentWords(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Documents != 2 || stats.Words != 1020 {
+		t.Errorf("CountDocumentWords() = %d documents, %d words, want 2 and 1020", stats.Documents, stats.Words)
+	}
+	if len(stats.Largest) != 1 || stats.Largest[0].ID != "b" || stats.Largest[0].Words != 900 || stats.Largest[0].Path != "/b.md" {
+		t.Errorf("CountDocumentWords().Largest = %+v, want b", stats.Largest)
+	}
+}
+
 func TestCreateCollection(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -1210,3 +1703,616 @@ func TestDocumentDeleteCascade(t *testing.T) {
 		t.Errorf("after document delete, collection count = %d, want 0", count)
 	}
 }
+
+func TestIndexErrors(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	older := time.Now().Add(-time.Hour).Truncate(time.Second)
+	mustSucceed(t, db.RecordIndexError(ctx, &IndexError{Path: "/a.md", Source: SourceMarkdown, Error: "bad yaml", FailedAt: older}))
+	mustSucceed(t, db.RecordIndexError(ctx, &IndexError{Path: "/b.pdf", Source: SourcePDF, Error: "encrypted"}))
+	// A second failure for the same path replaces the first.
+	mustSucceed(t, db.RecordIndexError(ctx, &IndexError{Path: "/a.md", Source: SourceMarkdown, Error: "still bad", FailedAt: older}))
+
+	errs, err := db.ListIndexErrors(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("got %d errors, want 2", len(errs))
+	}
+	if errs[0].Path != "/b.pdf" {
+		t.Errorf("most recent error first: got %q", errs[0].Path)
+	}
+	if errs[1].Error != "still bad" || errs[1].Source != SourceMarkdown {
+		t.Errorf("errs[1] = %+v", errs[1])
+	}
+
+	mustSucceed(t, db.ClearIndexError(ctx, "/a.md"))
+	mustSucceed(t, db.ClearIndexError(ctx, "/missing.md"))
+	errs, err = db.ListIndexErrors(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("after clear got %d errors, want 1", len(errs))
+	}
+
+	n, err := db.ClearIndexErrors(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("ClearIndexErrors removed %d, want 1", n)
+	}
+}
+
+func TestCountDocumentsWithMetadata(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Now()
+	docs := []*Document{
+		{ID: "a", Source: SourceEmail, Path: "/a.mbox", ContentHash: "a", IndexedAt: now, ModifiedAt: now,
+			Metadata: map[string]string{"parse_warnings": "skipped 1 malformed messages"}},
+		{ID: "b", Source: SourceEmail, Path: "/b.mbox", ContentHash: "b", IndexedAt: now, ModifiedAt: now,
+			Metadata: map[string]string{"subject": "parse_warnings"}},
+		{ID: "c", Source: SourceMarkdown, Path: "/c.md", ContentHash: "c", IndexedAt: now, ModifiedAt: now},
+	}
+	for _, doc := range docs {
+		mustSucceed(t, db.InsertDocument(ctx, doc))
+	}
+
+	n, err := db.CountDocumentsWithMetadata(ctx, "parse_warnings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("CountDocumentsWithMetadata = %d, want 1 (values must not match)", n)
+	}
+}
+
+func TestSaveDocumentReplacesChunks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Now()
+	doc := &Document{ID: "doc", Source: SourceMarkdown, Path: "/doc.md", Content: "v1", ContentHash: "h1", IndexedAt: now, ModifiedAt: now}
+	mustSucceed(t, db.SaveDocument(ctx, doc, []*Chunk{
+		{ID: "doc:0", Content: "a", StartPos: 0, EndPos: 1},
+		{ID: "doc:1", Content: "b", StartPos: 1, EndPos: 2},
+	}))
+
+	chunks, err := db.GetChunksByDocument(ctx, "doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 2 || chunks[0].DocumentID != "doc" {
+		t.Fatalf("chunks = %+v, want 2 for doc", chunks)
+	}
+
+	// nil keeps the chunks, e.g. when only metadata changed.
+	doc.Title = "Renamed"
+	mustSucceed(t, db.SaveDocument(ctx, doc, nil))
+	chunks, _ = db.GetChunksByDocument(ctx, "doc")
+	if len(chunks) != 2 {
+		t.Errorf("nil chunks: got %d chunks, want 2 kept", len(chunks))
+	}
+	if got, _ := db.GetDocument(ctx, "doc"); got.Title != "Renamed" {
+		t.Errorf("title = %q, want Renamed", got.Title)
+	}
+
+	mustSucceed(t, db.SaveDocument(ctx, doc, []*Chunk{{ID: "doc:0", Content: "c", StartPos: 0, EndPos: 1}}))
+	chunks, _ = db.GetChunksByDocument(ctx, "doc")
+	if len(chunks) != 1 || chunks[0].Content != "c" {
+		t.Errorf("replaced chunks = %+v, want just c", chunks)
+	}
+
+	mustSucceed(t, db.SaveDocument(ctx, doc, []*Chunk{}))
+	chunks, _ = db.GetChunksByDocument(ctx, "doc")
+	if len(chunks) != 0 {
+		t.Errorf("empty chunks: got %d, want 0", len(chunks))
+	}
+}
+
+func TestSaveDocumentRollsBackOnChunkError(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Now()
+	doc := &Document{ID: "doc", Source: SourceMarkdown, Path: "/doc.md", Content: "v1", ContentHash: "h1", IndexedAt: now, ModifiedAt: now}
+	mustSucceed(t, db.SaveDocument(ctx, doc, []*Chunk{{ID: "doc:0", Content: "a"}}))
+
+	// Duplicate chunk IDs violate the primary key part way through.
+	doc.Content = "v2"
+	err := db.SaveDocument(ctx, doc, []*Chunk{{ID: "dup", Content: "x"}, {ID: "dup", Content: "y"}})
+	if err == nil {
+		t.Fatal("expected error for duplicate chunk IDs")
+	}
+
+	got, err := db.GetDocument(ctx, "doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Content != "v1" {
+		t.Errorf("content = %q after failed save, want v1 (rolled back)", got.Content)
+	}
+	chunks, _ := db.GetChunksByDocument(ctx, "doc")
+	if len(chunks) != 1 || chunks[0].ID != "doc:0" {
+		t.Errorf("chunks = %+v after failed save, want the original chunk", chunks)
+	}
+}
+
+func TestReadsDoNotWaitForWriter(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Now()
+	doc := &Document{ID: "doc", Source: SourceMarkdown, Path: "/doc.md", Content: "committed", ContentHash: "h1", IndexedAt: now, ModifiedAt: now}
+	mustSucceed(t, db.InsertDocument(ctx, doc))
+
+	// Hold the only writer connection in an open transaction, as a long
+	// indexing batch would.
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, `UPDATE documents SET content = 'pending' WHERE id = 'doc'`); err != nil {
+		t.Fatal(err)
+	}
+
+	readCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	got, err := db.GetDocument(readCtx, "doc")
+	if err != nil {
+		t.Fatalf("GetDocument during write: %v", err)
+	}
+	if got.Content != "committed" {
+		t.Errorf("content = %q, want the last committed value", got.Content)
+	}
+}
+
+func TestReadPoolIsQueryOnly(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.ro.Exec(`DELETE FROM documents`); err == nil {
+		t.Error("expected write through the read pool to fail")
+	}
+}
+
+func TestReadContext(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := db.readContext(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("expected a deadline on a context without one")
+	}
+	if remaining := time.Until(deadline); remaining > readTimeout || remaining < readTimeout-time.Second {
+		t.Errorf("deadline in %v, want about %v", remaining, readTimeout)
+	}
+
+	want := time.Now().Add(time.Minute)
+	parent, cancelParent := context.WithDeadline(context.Background(), want)
+	defer cancelParent()
+	ctx, cancel = db.readContext(parent)
+	defer cancel()
+	if got, _ := ctx.Deadline(); !got.Equal(want) {
+		t.Errorf("deadline = %v, want caller's %v", got, want)
+	}
+}
+
+func TestListManualTagsAndMembers(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	doc := createTestDoc(t, db, "d1", "/d1.md")
+	mustSucceed(t, db.AddTag(ctx, doc.ID, "go"))
+	mustSucceed(t, db.AddAutoTag(ctx, doc.ID, "auto"))
+	col := &Collection{Name: "reading"}
+	mustSucceed(t, db.CreateCollection(ctx, col))
+	mustSucceed(t, db.AddToCollection(ctx, col.ID, doc.ID))
+
+	tags, err := db.ListManualTags(ctx)
+	mustSucceed(t, err)
+	if len(tags) != 1 || tags[0].Tag != "go" || tags[0].Document.Path != "/d1.md" || tags[0].Document.ContentHash != "h" {
+		t.Errorf("ListManualTags() = %+v, want only the manual tag go on /d1.md", tags)
+	}
+
+	members, err := db.ListCollectionMembers(ctx)
+	mustSucceed(t, err)
+	if len(members) != 1 || members[0].Collection != "reading" || members[0].Document.ID != "d1" {
+		t.Errorf("ListCollectionMembers() = %+v, want d1 in reading", members)
+	}
+}
+
+func TestGetDocumentByContentHash(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	createTestDoc(t, db, "d1", "/d1.md")
+	doc, err := db.GetDocumentByContentHash(ctx, "h")
+	mustSucceed(t, err)
+	if doc.ID != "d1" {
+		t.Errorf("GetDocumentByContentHash() = %q, want d1", doc.ID)
+	}
+	if _, err := db.GetDocumentByContentHash(ctx, "missing"); err != ErrNotFound {
+		t.Errorf("GetDocumentByContentHash(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateCollectionQuery(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	col := &Collection{Name: "smart"}
+	mustSucceed(t, db.CreateCollection(ctx, col))
+	mustSucceed(t, db.UpdateCollectionQuery(ctx, col.ID, "tag:go"))
+
+	got, err := db.GetCollection(ctx, col.ID)
+	mustSucceed(t, err)
+	if got.Query != "tag:go" {
+		t.Errorf("Query = %q, want tag:go", got.Query)
+	}
+	if err := db.UpdateCollectionQuery(ctx, "nope", "x"); err != ErrNotFound {
+		t.Errorf("UpdateCollectionQuery(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSyncState(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	mustSucceed(t, db.SaveSyncState(ctx, map[string]string{"a": "1", "b": "2"}))
+	mustSucceed(t, db.SaveSyncState(ctx, map[string]string{"b": "3"}))
+
+	state, err := db.LoadSyncState(ctx)
+	mustSucceed(t, err)
+	if fmt.Sprint(state) != "map[b:3]" {
+		t.Errorf("LoadSyncState() = %v, want map[b:3]", state)
+	}
+}
+
+func TestJournal(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	older := time.Now().Add(-time.Minute)
+	mustSucceed(t, db.BeginJournal(ctx, &JournalEntry{Path: "/b.md", Source: SourceMarkdown, DocumentID: "b"}))
+	mustSucceed(t, db.BeginJournal(ctx, &JournalEntry{Path: "/a.pdf", Source: SourcePDF, StartedAt: older}))
+	// Starting again on a path replaces its entry.
+	mustSucceed(t, db.BeginJournal(ctx, &JournalEntry{Path: "/b.md", Source: SourceMarkdown, DocumentID: "b2"}))
+
+	entries, err := db.ListJournal(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Path != "/a.pdf" || entries[1].DocumentID != "b2" {
+		t.Fatalf("ListJournal() = %+v, want /a.pdf then /b.md with document b2", entries)
+	}
+
+	mustSucceed(t, db.EndJournal(ctx))
+	mustSucceed(t, db.EndJournal(ctx, "/a.pdf", "/b.md", "/missing.md"))
+	if entries, err := db.ListJournal(ctx); err != nil || len(entries) != 0 {
+		t.Errorf("ListJournal() after EndJournal = %v, %v; want none", entries, err)
+	}
+}
+
+func TestEntities(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	for i, id := range []string{"a", "b"} {
+		mustSucceed(t, db.InsertDocument(ctx, &Document{ID: id, Source: SourceMarkdown, Path: "/" + id + ".md", ContentHash: id, IndexedAt: now, ModifiedAt: now.Add(time.Duration(i) * time.Hour)}))
+	}
+	jane := Entity{Kind: EntityPerson, Name: "Jane Doe"}
+	mustSucceed(t, db.SetEntities(ctx, "a", []Entity{jane, {Kind: EntityOrganization, Name: "Acme Inc"}, jane}))
+	mustSucceed(t, db.SetEntities(ctx, "b", []Entity{jane, {Kind: EntityProject, Name: "Zeppelin"}}))
+
+	counts, err := db.ListEntities(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 3 || counts[0].Entity != jane || counts[0].Documents != 2 {
+		t.Fatalf("ListEntities() = %+v, want Jane Doe in 2 documents first, then 2 more", counts)
+	}
+	if people, _ := db.ListEntities(ctx, EntityPerson); len(people) != 1 {
+		t.Errorf("ListEntities(person) = %+v, want only Jane Doe", people)
+	}
+
+	docs, err := db.FindByEntity(ctx, EntityPerson, "jane")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].ID != "b" {
+		t.Errorf("FindByEntity(person, jane) = %d docs, want b then a", len(docs))
+	}
+	if docs, _ := db.FindByEntity(ctx, EntityOrganization, "jane"); len(docs) != 0 {
+		t.Errorf("FindByEntity(org, jane) = %d docs, want none", len(docs))
+	}
+	if ids, err := db.FindIDsByEntity(ctx, EntityPerson, "jane"); err != nil || len(ids) != 2 {
+		t.Errorf("FindIDsByEntity(person, jane) = %v, %v; want a and b", ids, err)
+	}
+
+	// Setting replaces, and deleting the document drops its entities.
+	mustSucceed(t, db.SetEntities(ctx, "b", nil))
+	if got, _ := db.GetEntities(ctx, "b"); len(got) != 0 {
+		t.Errorf("GetEntities(b) after clearing = %+v, want none", got)
+	}
+	mustSucceed(t, db.DeleteDocument(ctx, "a"))
+	if counts, _ := db.ListEntities(ctx, ""); len(counts) != 0 {
+		t.Errorf("ListEntities() after deleting a = %+v, want none", counts)
+	}
+}
+
+func TestParticipants(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	for i, id := range []string{"m1", "m2", "m3"} {
+		mustSucceed(t, db.InsertDocument(ctx, &Document{ID: id, Source: SourceEmail, Path: "/" + id + ".eml", ContentHash: id, IndexedAt: now, ModifiedAt: now.Add(time.Duration(i) * time.Hour)}))
+	}
+	alice := func(role ParticipantRole) Participant {
+		return Participant{Role: role, Address: "alice@example.com", Name: "Alice Smith"}
+	}
+	me := func(role ParticipantRole) Participant { return Participant{Role: role, Address: "me@example.org"} }
+	mustSucceed(t, db.SetParticipants(ctx, "m1", []Participant{alice(RoleFrom), me(RoleTo)}))
+	mustSucceed(t, db.SetParticipants(ctx, "m2", []Participant{me(RoleFrom), alice(RoleTo)}))
+	mustSucceed(t, db.SetParticipants(ctx, "m3", []Participant{alice(RoleFrom), me(RoleTo)}))
+
+	docs, err := db.FindByParticipant(ctx, RoleFrom, "ALICE@example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].ID != "m3" || docs[1].ID != "m1" {
+		t.Errorf("FindByParticipant(from, alice) = %d docs, want m3 then m1", len(docs))
+	}
+	if docs, _ := db.FindByParticipant(ctx, RoleTo, "smith"); len(docs) != 1 || docs[0].ID != "m2" {
+		t.Errorf("FindByParticipant(to, smith) = %d docs, want m2 by display name", len(docs))
+	}
+	if ids, err := db.FindIDsByParticipant(ctx, RoleTo, "smith"); err != nil || len(ids) != 1 || ids[0] != "m2" {
+		t.Errorf("FindIDsByParticipant(to, smith) = %v, %v; want m2", ids, err)
+	}
+
+	contacts, err := db.ListContacts(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Contact{Address: "alice@example.com", Name: "Alice Smith", Messages: 3, Sent: 2, Received: 1}
+	if len(contacts) != 1 || contacts[0] != want {
+		t.Errorf("ListContacts(1) = %+v, want %+v", contacts, want)
+	}
+	if all, _ := db.ListContacts(ctx, 0); len(all) != 2 {
+		t.Errorf("ListContacts(0) = %d contacts, want 2", len(all))
+	}
+
+	mustSucceed(t, db.DeleteDocument(ctx, "m1"))
+	if docs, _ := db.FindByParticipant(ctx, RoleFrom, "alice"); len(docs) != 1 {
+		t.Errorf("FindByParticipant after deleting m1 = %d docs, want 1", len(docs))
+	}
+}
+
+func TestRelations(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "a", Source: SourceMarkdown, Path: "/a.md", ContentHash: "a", IndexedAt: now, ModifiedAt: now}))
+	mustSucceed(t, db.SetRelations(ctx, "a", RelationAsset, []string{"/img/z.png", "/img/a.png", "/img/z.png"}))
+
+	got, err := db.GetRelations(ctx, "a", RelationAsset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/img/z.png", "/img/a.png"}; !slices.Equal(got, want) {
+		t.Errorf("GetRelations() = %v, want %v in the order set", got, want)
+	}
+
+	mustSucceed(t, db.SetRelations(ctx, "a", RelationAsset, []string{"/doc.pdf"}))
+	if got, _ := db.GetRelations(ctx, "a", RelationAsset); len(got) != 1 || got[0] != "/doc.pdf" {
+		t.Errorf("GetRelations() after replacing = %v, want [/doc.pdf]", got)
+	}
+	mustSucceed(t, db.DeleteDocument(ctx, "a"))
+	if got, _ := db.GetRelations(ctx, "a", RelationAsset); len(got) != 0 {
+		t.Errorf("GetRelations() after deleting = %v, want none", got)
+	}
+}
+
+func TestRecentDocuments(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	base := time.Now().UTC().Add(-time.Hour)
+	for i, id := range []string{"a", "b", "c"} {
+		at := base.Add(time.Duration(i) * time.Minute)
+		mustSucceed(t, db.InsertDocument(ctx, &Document{ID: id, Source: SourceMarkdown, Path: "/" + id + ".md", Content: "text", ContentHash: id, IndexedAt: at, ModifiedAt: base}))
+	}
+	ids := func(docs []*Document) string {
+		var s []string
+		for _, d := range docs {
+			s = append(s, d.ID)
+		}
+		return strings.Join(s, " ")
+	}
+
+	indexed, err := db.RecentlyIndexed(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(indexed); got != "c b" {
+		t.Errorf("RecentlyIndexed(2) = %q, want %q", got, "c b")
+	}
+	if indexed[0].Content != "" {
+		t.Errorf("RecentlyIndexed() Content = %q, want it left out", indexed[0].Content)
+	}
+
+	for _, id := range []string{"a", "c", "a", "b"} {
+		mustSucceed(t, db.RecordInteraction(ctx, id, InteractionOpened))
+	}
+	opened, err := db.RecentInteractions(ctx, InteractionOpened, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(opened); got != "b a c" {
+		t.Errorf("RecentInteractions(opened) = %q, want %q, each document once", got, "b a c")
+	}
+
+	mustSucceed(t, db.DeleteDocument(ctx, "a"))
+	if opened, _ := db.RecentInteractions(ctx, InteractionOpened, 10); ids(opened) != "b c" {
+		t.Errorf("RecentInteractions() after deleting a = %q, want %q", ids(opened), "b c")
+	}
+}
+
+func TestRandomDocument(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Now()
+	old := now.AddDate(-1, 0, 0)
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "new", Source: SourceMarkdown, Path: "/new.md", ContentHash: "n", IndexedAt: now, ModifiedAt: now}))
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "old", Source: SourceMarkdown, Path: "/old.md", ContentHash: "o", IndexedAt: now, ModifiedAt: old.In(time.FixedZone("east", 5*3600))}))
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "dated", Source: SourceMarkdown, Path: "/dated.md", ContentHash: "d", IndexedAt: now, ModifiedAt: now, DocumentDate: old}))
+	mustSucceed(t, db.AddTag(ctx, "new", "Ideas"))
+
+	seen := map[string]bool{}
+	for range 50 {
+		doc, err := db.RandomDocument(ctx, "", time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[doc.ID] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("RandomDocument() picked %v in 50 tries, want all 3", seen)
+	}
+
+	if doc, err := db.RandomDocument(ctx, "ideas", time.Time{}); err != nil || doc.ID != "new" {
+		t.Errorf("RandomDocument(tag ideas) = %v, %v, want new", doc, err)
+	}
+	for range 10 {
+		doc, err := db.RandomDocument(ctx, "", now.AddDate(0, -6, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc.ID == "new" {
+			t.Fatal("RandomDocument(older than 6 months) picked a new document")
+		}
+	}
+	if _, err := db.RandomDocument(ctx, "ideas", now.AddDate(0, -6, 0)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RandomDocument() with nothing matching: error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestOnThisDay(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	at := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 23, 30, 0, 0, time.Local) }
+	now := at(2026, 10, 16)
+	for _, doc := range []*Document{
+		{ID: "2024", ModifiedAt: at(2024, 10, 16)},
+		{ID: "2019", ModifiedAt: at(2019, 10, 16)},
+		{ID: "dated", ModifiedAt: at(2025, 1, 2), DocumentDate: at(2021, 10, 16)},
+		{ID: "redated", ModifiedAt: at(2022, 10, 16), DocumentDate: at(2022, 10, 15)},
+		{ID: "today", ModifiedAt: now},
+		{ID: "other", ModifiedAt: at(2024, 10, 17)},
+	} {
+		doc.Source, doc.Path, doc.ContentHash, doc.IndexedAt = SourceMarkdown, "/"+doc.ID+".md", doc.ID, now
+		mustSucceed(t, db.InsertDocument(ctx, doc))
+	}
+
+	docs, err := db.OnThisDay(ctx, now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	if got, want := strings.Join(ids, " "), "2024 dated 2019"; got != want {
+		t.Errorf("OnThisDay() = %q, want %q", got, want)
+	}
+	if docs, _ := db.OnThisDay(ctx, now, 1); len(docs) != 1 {
+		t.Errorf("OnThisDay(limit 1) = %d documents", len(docs))
+	}
+}
+
+func TestAnswerCache(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	for _, id := range []string{"a", "b"} {
+		mustSucceed(t, db.InsertDocument(ctx, &Document{ID: id, Source: SourceMarkdown, Path: "/" + id + ".md", ContentHash: "h-" + id, IndexedAt: now, ModifiedAt: now}))
+	}
+	answer := &CachedAnswer{
+		Key:             "k1",
+		Question:        "what is go?",
+		Answer:          "A language.",
+		Sources:         []CachedSource{{DocumentID: "a", ContentHash: "h-a"}, {DocumentID: "b", ContentHash: "h-b"}},
+		ConfidenceLevel: "high",
+		ConfidenceScore: 0.8,
+	}
+	generation, err := db.IndexGeneration(ctx)
+	mustSucceed(t, err)
+	answer.Generation = generation
+	mustSucceed(t, db.PutCachedAnswer(ctx, answer))
+
+	got, err := db.GetCachedAnswer(ctx, "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Answer != "A language." || got.ConfidenceLevel != "high" || !slices.Equal(got.Sources, answer.Sources) {
+		t.Errorf("GetCachedAnswer() = %+v, want the stored answer", got)
+	}
+	if _, err := db.GetCachedAnswer(ctx, "other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetCachedAnswer(other) error = %v, want ErrNotFound", err)
+	}
+
+	// A new document might answer better, so it invalidates the answer.
+	mustSucceed(t, db.InsertDocument(ctx, &Document{ID: "c", Source: SourceMarkdown, Path: "/c.md", ContentHash: "h-c", IndexedAt: now, ModifiedAt: now}))
+	if _, err := db.GetCachedAnswer(ctx, "k1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetCachedAnswer() after a document was added: error = %v, want ErrNotFound", err)
+	}
+
+	// So does changing a source, even when cached at the new generation.
+	generation, err = db.IndexGeneration(ctx)
+	mustSucceed(t, err)
+	answer.Generation = generation
+	mustSucceed(t, db.PutCachedAnswer(ctx, answer))
+	mustSucceed(t, db.UpsertDocument(ctx, &Document{ID: "b", Source: SourceMarkdown, Path: "/b.md", ContentHash: "h-b2", IndexedAt: now, ModifiedAt: now}))
+	if _, err := db.GetCachedAnswer(ctx, "k1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetCachedAnswer() after a source changed: error = %v, want ErrNotFound", err)
+	}
+
+	generation, err = db.IndexGeneration(ctx)
+	mustSucceed(t, err)
+	mustSucceed(t, db.PutCachedAnswer(ctx, &CachedAnswer{Key: "k2", Question: "q", Answer: "x", Sources: []CachedSource{{DocumentID: "a", ContentHash: "h-a"}}, Generation: generation}))
+	mustSucceed(t, db.DeleteDocument(ctx, "a"))
+	if _, err := db.GetCachedAnswer(ctx, "k2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetCachedAnswer() after a source was removed: error = %v, want ErrNotFound", err)
+	}
+}
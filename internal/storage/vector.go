@@ -178,6 +178,13 @@ func (v *VectorStore) Search(query []float32, k int) []VectorResult {
 	return results
 }
 
+// Lookup returns the vector stored for key.
+func (v *VectorStore) Lookup(key string) ([]float32, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.graph.Lookup(key)
+}
+
 // Delete removes a vector by key.
 func (v *VectorStore) Delete(key string) {
 	v.mu.Lock()
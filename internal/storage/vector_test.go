@@ -163,6 +163,12 @@ func TestVectorStoreDelete(t *testing.T) {
 	if store.Len() != 1 {
 		t.Errorf("expected 1 after delete, got %d", store.Len())
 	}
+	if _, ok := store.Lookup("key1"); ok {
+		t.Error("Lookup() found a deleted key")
+	}
+	if v, ok := store.Lookup("key2"); !ok || len(v) != 2 || v[1] != 1.0 {
+		t.Errorf("Lookup(key2) = %v, %v; want [0 1]", v, ok)
+	}
 }
 
 func TestVectorStoreAddBatch(t *testing.T) {
@@ -2,17 +2,24 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/J-1000/mindcli/internal/privacy"
 	"github.com/J-1000/mindcli/internal/query"
 	"github.com/J-1000/mindcli/internal/search"
 	"github.com/J-1000/mindcli/internal/storage"
 	"github.com/J-1000/mindcli/internal/tui/styles"
+	"github.com/J-1000/mindcli/pkg/chunker"
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -21,6 +28,32 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// docPageSize is the number of documents loaded at a time while browsing;
+// further pages load as the cursor approaches the end of the list.
+const docPageSize = 200
+
+// defaultResultsLimit caps search results unless SetResultsLimit is called.
+const defaultResultsLimit = 50
+
+// defaultPreviewLength is how many characters of a document the preview
+// panel shows unless SetPreviewLength is called.
+const defaultPreviewLength = 2000
+
+// The preview lists up to previewKeywords keywords for documents of at least
+// keywordMinWords words; shorter ones are read faster than summarised.
+const (
+	previewKeywords = 6
+	keywordMinWords = 100
+)
+
+// The facets sidebar lists up to facetLimit sources, tags and months, and
+// is shown when the window is at least minFacetsWidth columns wide.
+const (
+	facetLimit     = 8
+	facetsWidth    = 24
+	minFacetsWidth = 120
+)
+
 // Panel represents which panel is focused.
 type Panel int
 
@@ -34,36 +67,73 @@ const (
 type Model struct {
 	// Database and search
 	db     *storage.DB
-	search *search.BleveIndex
+	search search.Backend
 	hybrid *query.HybridSearcher
 	llm    *query.LLMClient
+	parser *query.LLMParser // refines committed queries; nil uses the heuristics only
+
+	loadSemantic func() *query.HybridSearcher // sets up hybrid search after startup
+	hybridWeight *float64                     // overrides the loaded searcher's weight when set
+	warming      bool                         // loadSemantic is still running
+	generation   int64                        // index generation the results reflect
+	staleIndex   bool                         // the index changed since the results loaded
+	polling      bool                         // the index generation is being polled
 
 	// UI Components
 	searchInput textinput.Model
 	preview     viewport.Model
 
 	// State
-	panel        Panel
-	results      []*storage.Document
-	cursor       int
-	showHelp     bool
-	statusMsg    string
-	statusIsErr  bool
-	answerText   string // LLM-generated answer for the current query
-	tagging      bool   // true when tag input mode is active
-	tagInput     textinput.Model
-	collecting   bool // true when collection input mode is active
-	collectInput textinput.Model
-	redactor     privacy.Redactor
-
-	highlights    map[string][]string // matching snippets per document ID
-	searchVersion int                 // increments per keystroke for debouncing
-	sourceFilter  storage.Source      // active source filter ("" = all sources)
+	panel         Panel
+	results       []*storage.Document
+	cursor        int
+	showHelp      bool
+	statusMsg     string
+	statusIsErr   bool
+	answerText    string // LLM-generated answer for the current query
+	tagging       bool   // true when tag input mode is active
+	tagInput      textinput.Model
+	collecting    bool // true when collection input mode is active
+	collectInput  textinput.Model
+	finding       bool // true when find-in-preview input is active
+	findInput     textinput.Model
+	palette       bool // true when the command palette is open
+	comparing     bool // true when two documents are shown side by side
+	compare       [2]compareSide
+	compareOffset int               // line both compared documents are scrolled to
+	compareMark   *storage.Document // result marked to compare with another
+	paletteInput  textinput.Model
+	paletteCursor int    // selected command among the matches
+	findTerm      string // term found and highlighted in the preview
+	findMatches   []int  // preview line of each match of findTerm
+	findCursor    int    // current match in findMatches
+	redactor      privacy.Redactor
+
+	notifying           bool           // true when the notifications log is open
+	notifications       []notification // warnings and errors of the session
+	unseenNotifications int            // notifications logged since the log was last opened
+	notificationOffset  int            // newest notifications scrolled past
+
+	highlights    map[string][]string    // matching snippets per document ID
+	suggestions   query.Suggestions      // alternatives to a search that found nothing
+	facets        *search.Facets         // match counts of the search, for the sidebar
+	alias         string                 // alias the search matched, its document pinned first
+	searchVersion int                    // increments per keystroke for debouncing
+	sourceFilters []storage.Source       // sources searched and listed (none = all)
+	sourceCounts  map[storage.Source]int // indexed documents per source, for the header
+	resultsLimit  int                    // maximum search results shown
+	previewLength int                    // characters of a document previewed (0 = all)
+	contextBudget query.ContextBudget    // excerpts passed to the LLM
+	moreDocs      bool                   // browsing and further pages remain
+	loadingMore   bool                   // a further page is being fetched
 
 	browsingCollections bool                  // true when browsing collections list
 	collections         []*storage.Collection // loaded collections
 	collectionCounts    map[string]int        // doc count per collection ID
 	collectionCursor    int                   // cursor in collections list
+	browsingEntities    bool                  // true when browsing people, orgs and projects
+	entities            []storage.EntityCount // loaded entities
+	entityCursor        int                   // cursor in entities list
 	prevResults         []*storage.Document   // saved results before browsing
 	streaming           bool                  // true while streaming LLM answer
 	streamCh            chan streamChunkMsg   // channel for streaming tokens
@@ -73,9 +143,25 @@ type Model struct {
 	reindex  func(context.Context) (indexed int, errs int, err error)
 	indexing bool // true while an in-app index pass is running
 
+	// saveAnswer stores an answer transcript as a note; nil disables saving.
+	saveAnswer func(context.Context, query.Transcript) (string, error)
+
+	// reloadConfig rereads the config and returns how to apply it; nil
+	// disables reloading.
+	reloadConfig func() (func(*Model), error)
+
 	currentQuestion string                   // question currently being answered
+	answerBudget    query.ContextBudget      // contextBudget allowing the sources the question names
+	answerContext   []query.AnswerContext    // what the current answer draws on, once assembled
 	conversation    []query.ConversationTurn // recent Q&A turns for follow-ups
 
+	previewDoc *storage.Document   // document followed via a wiki link (nil = selected result)
+	contentDoc *storage.Document   // result whose content ensureContent fetched
+	navStack   []*storage.Document // documents to return to with Back
+	links      []string            // wiki link targets in the previewed document
+	assets     []string            // local files the previewed note embeds or links to
+	linkCursor int                 // selected link, then asset; -1 when none is selected
+
 	// Dimensions
 	width  int
 	height int
@@ -87,7 +173,7 @@ type Model struct {
 // New creates a new Model with the given database and search index.
 // The hybrid searcher and LLM client are optional; if nil, those features are
 // skipped. reindex, when non-nil, enables the in-app "index now" action.
-func New(db *storage.DB, searchIndex *search.BleveIndex, hybrid *query.HybridSearcher, llm *query.LLMClient, redactor privacy.Redactor, reindex func(context.Context) (int, int, error)) Model {
+func New(db *storage.DB, searchIndex search.Backend, hybrid *query.HybridSearcher, llm *query.LLMClient, redactor privacy.Redactor, reindex func(context.Context) (int, int, error)) Model {
 	ti := textinput.New()
 	ti.Placeholder = "Search your knowledge base..."
 	ti.PromptStyle = styles.SearchPromptStyle
@@ -107,119 +193,418 @@ func New(db *storage.DB, searchIndex *search.BleveIndex, hybrid *query.HybridSea
 	collectTi.Placeholder = "Enter collection name..."
 	collectTi.CharLimit = 64
 
+	findTi := textinput.New()
+	findTi.Placeholder = "Find in document..."
+	findTi.CharLimit = 128
+
+	paletteTi := textinput.New()
+	paletteTi.Placeholder = "Type a command..."
+	paletteTi.CharLimit = 64
+
 	return Model{
-		db:           db,
-		search:       searchIndex,
-		hybrid:       hybrid,
-		llm:          llm,
-		searchInput:  ti,
-		preview:      vp,
-		tagInput:     tagTi,
-		collectInput: collectTi,
-		panel:        PanelSearch,
-		keys:         DefaultKeyMap(),
-		redactor:     redactor,
-		reindex:      reindex,
+		db:            db,
+		search:        searchIndex,
+		hybrid:        hybrid,
+		llm:           llm,
+		searchInput:   ti,
+		preview:       vp,
+		tagInput:      tagTi,
+		collectInput:  collectTi,
+		findInput:     findTi,
+		paletteInput:  paletteTi,
+		panel:         PanelSearch,
+		keys:          DefaultKeyMap(),
+		redactor:      redactor,
+		reindex:       reindex,
+		linkCursor:    -1,
+		resultsLimit:  defaultResultsLimit,
+		previewLength: defaultPreviewLength,
+	}
+}
+
+// SetResultsLimit sets the maximum number of search results shown.
+func (m *Model) SetResultsLimit(n int) {
+	if n > 0 {
+		m.resultsLimit = n
+	}
+}
+
+// SetPreviewLength sets how many characters of a document the preview panel
+// shows; 0 shows the whole document.
+func (m *Model) SetPreviewLength(n int) {
+	if n >= 0 {
+		m.previewLength = n
+	}
+}
+
+// SetContextBudget sets how much of the results is passed to the LLM when
+// answering a question, and which sources are left out of answers.
+func (m *Model) SetContextBudget(b query.ContextBudget) {
+	m.contextBudget = b
+	m.answerBudget = b
+}
+
+// SetQueryParser makes searches committed with Enter ask the LLM for the
+// query's intent and filters. Live searches, run as the user types, keep to
+// the heuristics.
+func (m *Model) SetQueryParser(p *query.LLMParser) {
+	m.parser = p
+}
+
+// SetAnswerSaver enables the "save answer" action. save writes the transcript
+// somewhere persistent and returns where it was stored.
+func (m *Model) SetAnswerSaver(save func(context.Context, query.Transcript) (string, error)) {
+	m.saveAnswer = save
+}
+
+// SetHybridWeight sets how much vector similarity counts in hybrid search,
+// from 0 (full-text only) to 1, whether the semantic searcher has loaded yet
+// or not.
+func (m *Model) SetHybridWeight(w float64) {
+	m.hybridWeight = &w
+	m.hybrid = weighted(m.hybrid, m.hybridWeight)
+}
+
+// weighted returns h searching with weight, or h itself when either is nil.
+// It copies h rather than changing it, as searches in flight may be using it.
+func weighted(h *query.HybridSearcher, weight *float64) *query.HybridSearcher {
+	if h == nil || weight == nil {
+		return h
 	}
+	c := *h
+	c.HybridWeight = *weight
+	return &c
+}
+
+// SetRedactor sets the patterns masked in everything the TUI shows.
+func (m *Model) SetRedactor(r privacy.Redactor) {
+	m.redactor = r
+}
+
+// SetConfigReloader enables the "reload config" action. reload rereads the
+// config and returns how to apply it to the model, or why it can't: a config
+// that fails to load or validate leaves the running settings alone.
+func (m *Model) SetConfigReloader(reload func() (func(*Model), error)) {
+	m.reloadConfig = reload
+}
+
+// SetSemanticLoader defers semantic search until after the UI is up: load
+// runs in the background from Init and returns the hybrid searcher, or nil
+// when there are no vectors to search. Until it returns, searches are
+// full-text only and the status bar says semantic search is warming up.
+func (m *Model) SetSemanticLoader(load func() *query.HybridSearcher) {
+	m.loadSemantic = load
+	m.warming = load != nil
 }
 
 // Init initializes the model.
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
-		textinput.Blink,
-		m.loadDocuments(),
-	)
+	cmds := []tea.Cmd{textinput.Blink, m.loadDocuments(), m.loadSourceCounts(), m.syncGeneration()}
+	if m.loadSemantic != nil {
+		load := m.loadSemantic
+		cmds = append(cmds, func() tea.Msg {
+			return semanticReadyMsg{hybrid: load()}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// loadSourceCounts counts the indexed documents of each source.
+func (m Model) loadSourceCounts() tea.Cmd {
+	db := m.db
+	return func() tea.Msg {
+		counts := make(sourceCountsMsg)
+		for _, s := range storage.AllSources {
+			n, err := db.CountDocumentsBySource(context.Background(), s)
+			if err != nil {
+				return errMsg{err}
+			}
+			counts[s] = n
+		}
+		return counts
+	}
+}
+
+// generationPollInterval is how often the TUI checks whether another
+// process, such as the watcher or mindcli index, changed the index.
+const generationPollInterval = 5 * time.Second
+
+// reloadConfigCmd rereads the config in the background.
+func (m *Model) reloadConfigCmd() tea.Cmd {
+	if m.reloadConfig == nil {
+		m.statusMsg = "Reloading the config is not available"
+		m.statusIsErr = true
+		return nil
+	}
+	reload := m.reloadConfig
+	m.statusMsg = "Reloading config..."
+	m.statusIsErr = false
+	return func() tea.Msg {
+		apply, err := reload()
+		return configReloadedMsg{apply: apply, err: err}
+	}
+}
+
+// readGeneration returns a tick function reading the index generation.
+func (m Model) readGeneration(baseline bool) func(time.Time) tea.Msg {
+	db := m.db
+	return func(time.Time) tea.Msg {
+		generation, err := db.IndexGeneration(context.Background())
+		if err != nil {
+			return errMsg{err}
+		}
+		return generationMsg{generation: generation, baseline: baseline}
+	}
+}
+
+// syncGeneration records the index generation the results being loaded
+// reflect.
+func (m Model) syncGeneration() tea.Cmd {
+	read := m.readGeneration(true)
+	return func() tea.Msg { return read(time.Now()) }
 }
 
-// loadDocuments loads documents from the database.
+// pollGeneration checks the index generation after generationPollInterval.
+func (m Model) pollGeneration() tea.Cmd {
+	return tea.Tick(generationPollInterval, m.readGeneration(false))
+}
+
+// loadDocuments loads the first page of documents from the database.
 func (m Model) loadDocuments() tea.Cmd {
-	source := m.sourceFilter
+	return m.loadDocumentPage(0)
+}
+
+// loadDocumentPage loads one page of document summaries starting at offset.
+// Content is fetched lazily when a document is previewed.
+func (m Model) loadDocumentPage(offset int) tea.Cmd {
+	db := m.db
+	sources := m.sourceFilters
 	return func() tea.Msg {
 		ctx := context.Background()
-		docs, err := m.db.ListDocuments(ctx, source)
+		// Fetch one extra row to learn whether another page follows.
+		docs, err := db.ListDocumentSummariesIn(ctx, sources, offset, docPageSize+1)
 		if err != nil {
 			return errMsg{err}
 		}
-		return docsLoadedMsg{docs}
+		more := len(docs) > docPageSize
+		if more {
+			docs = docs[:docPageSize]
+		}
+		return docsLoadedMsg{docs: docs, offset: offset, more: more}
+	}
+}
+
+// maybeLoadMore starts loading the next page of documents once the cursor
+// gets within a page step of the end of the browse list.
+func (m *Model) maybeLoadMore() tea.Cmd {
+	if !m.moreDocs || m.loadingMore || m.cursor < len(m.results)-m.pageStep() {
+		return nil
 	}
+	m.loadingMore = true
+	return m.loadDocumentPage(len(m.results))
 }
 
 // searchDocuments searches using hybrid search (BM25 + vector) when available.
-// It uses the query parser to extract intent, source filters, and time filters.
+// It uses the query parser to extract intent and source, time and metadata
+// filters. A committed query during a conversation is first rewritten to
+// stand on its own, so a follow-up such as "what about its performance?"
+// finds what "it" is.
 func (m Model) searchDocuments(q string, live bool) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
 		parsed := query.ParseQuery(q)
+		var rewritten string
+		if !live {
+			asked := parsed
+			if len(m.conversation) > 0 && m.llm != nil {
+				if r := m.llm.RewriteFollowUp(ctx, q, m.conversation); r != q {
+					rewritten = r
+					parsed = query.ParseQuery(r)
+				}
+			}
+			parsed = m.parser.Refine(ctx, parsed)
+			// The answer is to the question as asked, in the conversation
+			// it belongs to, even if the rewrite reads as a plain search.
+			parsed.Original = q
+			if parsed.Intent == query.IntentSearch {
+				parsed.Intent = asked.Intent
+			}
+		}
 
 		// Build search query with source filter (from the NL query, or the
-		// active filter toggled with 'f').
-		searchQ := parsed.SearchTerms
-		if parsed.SourceFilter != "" {
-			searchQ = searchQ + " source:" + parsed.SourceFilter
-		} else if m.sourceFilter != "" {
-			searchQ = searchQ + " source:" + string(m.sourceFilter)
+		// active filters toggled with 'f' and the number keys).
+		filterQ := parsed
+		if filterQ.SourceFilter == "" {
+			filterQ.SourceFilter = storage.JoinSources(m.sourceFilters)
+		}
+		docs, highlights, err := m.find(ctx, filterQ)
+		if err != nil {
+			return errMsg{err}
 		}
 
-		var docs []*storage.Document
-		highlights := make(map[string][]string)
+		// A query naming an alias jumps to its document: pin it first.
+		var alias string
+		if doc, err := m.db.ResolveAlias(ctx, q); err == nil {
+			alias = storage.NormalizeAlias(q)
+			docs = slices.DeleteFunc(docs, func(d *storage.Document) bool { return d.ID == doc.ID })
+			docs = append([]*storage.Document{doc}, docs...)
+		}
+
+		// Offer alternatives to a committed query that found nothing.
+		var suggestions query.Suggestions
+		if len(docs) == 0 && !live {
+			suggestions = query.Suggest(ctx, m.search, m.hybrid, filterQ, func(ctx context.Context, q query.ParsedQuery) (int, error) {
+				found, _, err := m.find(ctx, q)
+				return len(found), err
+			})
+		}
+
+		return searchResultsMsg{docs: docs, highlights: highlights, alias: alias, parsed: parsed, live: live,
+			rewritten: rewritten, suggestions: suggestions, facets: m.searchFacets(ctx, filterQ)}
+	}
+}
+
+// searchFacets counts the full-text matches of parsed by source, tag and
+// month, or returns nil without a full-text index or search terms. Errors
+// are ignored, as the counts are only a guide.
+func (m Model) searchFacets(ctx context.Context, parsed query.ParsedQuery) *search.Facets {
+	if m.search == nil || parsed.SearchTerms == "" {
+		return nil
+	}
+	f, err := m.search.Facets(ctx, strings.TrimSpace(parsed.SearchTerms+" "+parsed.Filters()), facetLimit)
+	if err != nil {
+		return nil
+	}
+	return f
+}
+
+// find runs a parsed query, filters and all, through the best search
+// available, returning the documents found and their matching snippets.
+func (m Model) find(ctx context.Context, parsed query.ParsedQuery) ([]*storage.Document, map[string][]string, error) {
+	searchQ := strings.TrimSpace(parsed.SearchTerms + " " + parsed.Filters())
 
+	var docs []*storage.Document
+	highlights := make(map[string][]string)
+	limit := parsed.SearchLimit(m.resultsLimit)
+
+	if parsed.SearchTerms == "" && len(parsed.MetadataFilters) > 0 {
+		// Only field filters (e.g. "author:smith"): list matches directly.
+		var err error
+		docs, err = query.DocumentsByMetadata(ctx, m.db, parsed, m.resultsLimit)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if m.hybrid != nil {
 		// Use hybrid search if available
-		if m.hybrid != nil {
-			results, err := m.hybrid.Search(ctx, searchQ, 50)
-			if err != nil {
-				return errMsg{err}
-			}
-			docs = make([]*storage.Document, 0, len(results))
-			for _, r := range results {
-				docs = append(docs, r.Document)
-				if len(r.Highlights) > 0 {
-					highlights[r.Document.ID] = r.Highlights
-				}
+		results, err := m.hybrid.SearchWithWeight(ctx, searchQ, limit, parsed.Weight(m.hybrid.HybridWeight))
+		if err != nil {
+			return nil, nil, err
+		}
+		docs = make([]*storage.Document, 0, len(results))
+		for _, r := range results {
+			docs = append(docs, r.Document)
+			if len(r.Highlights) > 0 {
+				highlights[r.Document.ID] = r.Highlights
 			}
-		} else if m.search != nil {
-			// Use Bleve, fall back to SQLite LIKE search
-			results, err := m.search.Search(ctx, searchQ, 50)
+		}
+	} else if m.search != nil {
+		// Use the full-text index, fall back to SQLite LIKE search
+		results, err := m.search.Search(ctx, searchQ, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		docs = make([]*storage.Document, 0, len(results))
+		for _, r := range results {
+			doc, err := m.db.GetDocument(ctx, r.ID)
 			if err != nil {
-				return errMsg{err}
+				continue
 			}
-
-			docs = make([]*storage.Document, 0, len(results))
-			for _, r := range results {
-				doc, err := m.db.GetDocument(ctx, r.ID)
-				if err != nil {
-					continue
-				}
-				docs = append(docs, doc)
-				for _, frags := range r.Highlights {
-					highlights[doc.ID] = append(highlights[doc.ID], frags...)
-				}
+			docs = append(docs, doc)
+			for _, frags := range r.Highlights {
+				highlights[doc.ID] = append(highlights[doc.ID], frags...)
 			}
-		} else {
-			// Fallback to simple SQLite search
-			var err error
-			docs, err = m.db.SearchDocuments(ctx, parsed.SearchTerms, 50)
-			if err != nil {
-				return errMsg{err}
+		}
+	} else {
+		// Fallback to simple SQLite search, which has no filters.
+		found, err := m.db.SearchDocuments(ctx, parsed.SearchTerms, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, doc := range found {
+			if parsed.Matches(doc) {
+				docs = append(docs, doc)
 			}
 		}
+	}
 
-		// Apply any parsed time filter (e.g. "last week").
-		docs = query.FilterDocumentsByTime(docs, parsed, time.Now())
+	// Apply any parsed metadata (e.g. "author:smith") and time (e.g.
+	// "last week") filters.
+	docs, err := query.FilterDocumentsByMetadata(ctx, m.db, docs, parsed)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(docs) > m.resultsLimit {
+		docs = docs[:m.resultsLimit]
+	}
+	return query.FilterDocumentsByTime(docs, parsed, time.Now()), highlights, nil
+}
+
+// minPastedPassage is the length from which a paste into the search box is
+// taken as a passage to find related notes for, rather than a query.
+const minPastedPassage = 80
 
-		return searchResultsMsg{docs: docs, highlights: highlights, parsed: parsed, live: live}
+// isPastedPassage reports whether pasted text looks like a passage: long, or
+// spanning several lines.
+func isPastedPassage(text string) bool {
+	text = strings.TrimSpace(text)
+	return len(text) >= minPastedPassage || strings.Contains(text, "\n")
+}
+
+// similarDocuments finds documents related to a pasted passage, by vector
+// similarity when available and by its key terms otherwise.
+func (m Model) similarDocuments(text string) tea.Cmd {
+	return func() tea.Msg {
+		h := m.hybrid
+		if h == nil {
+			h = query.NewHybridSearcher(m.search, nil, nil, m.db, 0)
+		}
+		results, err := h.SimilarToText(context.Background(), text, m.resultsLimit)
+		if err != nil {
+			return errMsg{err}
+		}
+		docs := make([]*storage.Document, 0, len(results))
+		highlights := make(map[string][]string)
+		for _, r := range results {
+			docs = append(docs, r.Document)
+			if len(r.Highlights) > 0 {
+				highlights[r.Document.ID] = r.Highlights
+			}
+		}
+		return searchResultsMsg{docs: docs, highlights: highlights, similar: true}
 	}
 }
 
 // Message types
+type sourceCountsMsg map[storage.Source]int
+
 type docsLoadedMsg struct {
-	docs []*storage.Document
+	docs   []*storage.Document
+	offset int  // position of docs in the browse list
+	more   bool // further pages remain
 }
 
 type searchResultsMsg struct {
 	docs       []*storage.Document
 	highlights map[string][]string
+	alias      string // alias the query named, whose document is docs[0]
 	parsed     query.ParsedQuery
-	live       bool // from search-as-you-type (suppresses LLM streaming)
+	live       bool   // from search-as-you-type (suppresses LLM streaming)
+	similar    bool   // related to a pasted passage rather than a query
+	rewritten  string // a follow-up question as searched, standing on its own
+
+	suggestions query.Suggestions // alternatives when nothing was found
+	facets      *search.Facets    // match counts, nil when not counted
 }
 
 type searchDebounceMsg struct {
@@ -231,19 +616,48 @@ type errMsg struct {
 	err error
 }
 
+// semanticReadyMsg carries the hybrid searcher set up by the semantic
+// loader; hybrid is nil when semantic search is unavailable.
+type semanticReadyMsg struct {
+	hybrid *query.HybridSearcher
+}
+
 type collectionsLoadedMsg struct {
 	collections []*storage.Collection
 	counts      map[string]int
 }
 
+type entitiesLoadedMsg struct {
+	entities []storage.EntityCount
+}
+
 type collectionDocsLoadedMsg struct {
 	docs []*storage.Document
 }
 
+type randomDocLoadedMsg struct {
+	doc *storage.Document // nil when there are no documents
+}
+
+type recentDocsLoadedMsg struct {
+	docs   []*storage.Document
+	opened bool // recently opened rather than recently indexed
+}
+
 type streamChunkMsg struct {
 	token string
 	done  bool
 	err   error
+
+	// contexts, sent before the first token, are the excerpts of documents
+	// passed to the LLM.
+	contexts []query.AnswerContext
+}
+
+// configReloadedMsg carries how to apply a reread config, or why it wasn't.
+type configReloadedMsg struct {
+	apply func(*Model)
+	err   error
 }
 
 type reindexDoneMsg struct {
@@ -252,6 +666,13 @@ type reindexDoneMsg struct {
 	err     error
 }
 
+// generationMsg carries the index generation. A baseline is the generation
+// the shown results reflect; a poll that finds another means they're stale.
+type generationMsg struct {
+	generation int64
+	baseline   bool
+}
+
 // Update handles messages and updates the model.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
@@ -265,6 +686,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.collecting {
 			return m.updateCollectInput(msg)
 		}
+		if m.finding {
+			return m.updateFindInput(msg)
+		}
+		if m.palette {
+			return m.updatePalette(msg)
+		}
+		if m.comparing {
+			return m.updateCompare(msg)
+		}
+		if m.notifying {
+			return m.updateNotifications(msg)
+		}
 
 		// Handle global keys first
 		switch {
@@ -282,6 +715,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.showHelp = !m.showHelp
 			return m, nil
 
+		case key.Matches(msg, m.keys.ReloadConfig):
+			cmd := m.reloadConfigCmd()
+			return m, cmd
+		case key.Matches(msg, m.keys.Palette):
+			return m.openPalette()
+
 		case key.Matches(msg, m.keys.Tab):
 			m.nextPanel()
 			return m, nil
@@ -291,6 +730,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case key.Matches(msg, m.keys.Escape):
+			if m.panel == PanelPreview && m.findTerm != "" {
+				m.setFindTerm("")
+				m.statusMsg = ""
+				return m, nil
+			}
 			if m.panel == PanelSearch && m.searchInput.Value() != "" {
 				m.searchInput.SetValue("")
 				m.conversation = nil
@@ -318,26 +762,77 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case docsLoadedMsg:
+		if msg.offset > 0 {
+			// Drop pages that arrive after the list was replaced.
+			if !m.loadingMore || msg.offset != len(m.results) {
+				return m, nil
+			}
+			m.loadingMore = false
+			m.results = append(m.results, msg.docs...)
+			m.moreDocs = msg.more
+			m.statusMsg = documentCountStatus(len(m.results), m.moreDocs)
+			return m, m.maybeLoadMore()
+		}
 		m.results = msg.docs
+		m.moreDocs = msg.more
+		m.loadingMore = false
 		m.highlights = nil
+		m.alias = ""
+		m.suggestions = query.Suggestions{}
+		m.facets = nil
 		m.cursor = 0
-		m.statusMsg = fmt.Sprintf("%d documents", len(m.results))
+		m.statusMsg = documentCountStatus(len(m.results), m.moreDocs)
 		m.statusIsErr = false
 		m.updatePreviewContent()
 		return m, nil
 
+	case semanticReadyMsg:
+		m.warming = false
+		if msg.hybrid == nil {
+			return m, nil
+		}
+		m.hybrid = weighted(msg.hybrid, m.hybridWeight)
+		// Redo a full-text-only search so its results include semantic
+		// matches, unless an answer to it is being read.
+		if q := m.searchInput.Value(); q != "" && !m.streaming && m.answerText == "" {
+			return m, m.searchDocuments(q, true)
+		}
+		return m, nil
+
 	case searchResultsMsg:
-		m.results = msg.docs
+		m.results = summaries(msg.docs)
+		m.moreDocs, m.loadingMore = false, false
 		m.highlights = msg.highlights
+		m.alias = msg.alias
+		m.suggestions = msg.suggestions
+		m.facets = msg.facets
 		m.cursor = 0
 		m.answerText = ""
+		if msg.similar {
+			m.statusMsg = fmt.Sprintf("%d notes similar to pasted text", len(m.results))
+			m.statusIsErr = false
+			m.updatePreviewContent()
+			return m, nil
+		}
 		status := fmt.Sprintf("%d results", len(m.results))
+		if msg.rewritten != "" {
+			status += fmt.Sprintf(" for %q", msg.rewritten)
+		}
+		if msg.alias != "" {
+			status = fmt.Sprintf("Alias %q → %s · %s", msg.alias, m.results[0].Title, status)
+		}
 		if msg.parsed.SourceFilter != "" {
 			status += fmt.Sprintf(" [source:%s]", msg.parsed.SourceFilter)
 		}
+		if msg.parsed.PathPrefix != "" {
+			status += fmt.Sprintf(" [path:%s]", msg.parsed.PathPrefix)
+		}
 		if msg.parsed.TimeFilter != "" {
 			status += fmt.Sprintf(" [%s]", msg.parsed.TimeFilter)
 		}
+		for _, f := range msg.parsed.MetadataFilters {
+			status += fmt.Sprintf(" [%s:%s]", f.Field, f.Value)
+		}
 		m.statusMsg = status
 		m.statusIsErr = false
 		// Start streaming if intent is answer/summarize (not for live,
@@ -345,6 +840,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if !msg.live && m.llm != nil && len(m.results) > 0 &&
 			(msg.parsed.Intent == query.IntentAnswer || msg.parsed.Intent == query.IntentSummarize) {
 			m.currentQuestion = msg.parsed.Original
+			m.answerBudget = m.contextBudget.Allowing(msg.parsed.NamedSources())
 			m.showAnswer() // Shows "Thinking..."
 			return m, m.startStreaming(msg.parsed.Original, m.results)
 		}
@@ -352,10 +848,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case streamChunkMsg:
+		if msg.contexts != nil {
+			m.answerContext = msg.contexts
+			m.showAnswer()
+			return m, m.readNextChunk()
+		}
 		if msg.err != nil {
 			m.streaming = false
-			m.statusMsg = fmt.Sprintf("Answer generation failed: %v", msg.err)
-			m.statusIsErr = true
+			m.notifyError(fmt.Sprintf("Answer generation failed: %v", msg.err))
 			m.updatePreviewContent()
 			return m, nil
 		}
@@ -382,15 +882,63 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statusIsErr = false
 		return m, nil
 
+	case entitiesLoadedMsg:
+		m.entities = msg.entities
+		m.entityCursor = 0
+		if len(msg.entities) == 0 {
+			m.statusMsg = "No people or projects found; set indexing.entities and reindex"
+		} else {
+			m.statusMsg = fmt.Sprintf("%d people, organizations and projects", len(msg.entities))
+		}
+		m.statusIsErr = false
+		return m, nil
+
 	case collectionDocsLoadedMsg:
 		m.browsingCollections = false
-		m.results = msg.docs
+		m.results = summaries(msg.docs)
+		m.alias = ""
+		m.moreDocs, m.loadingMore = false, false
 		m.cursor = 0
 		m.statusMsg = fmt.Sprintf("%d documents in collection", len(msg.docs))
 		m.statusIsErr = false
 		m.updatePreviewContent()
 		return m, nil
 
+	case recentDocsLoadedMsg:
+		m.results = summaries(msg.docs)
+		m.alias = ""
+		m.highlights = nil
+		m.suggestions = query.Suggestions{}
+		m.facets = nil
+		m.moreDocs, m.loadingMore = false, false
+		m.cursor = 0
+		if msg.opened {
+			m.statusMsg = fmt.Sprintf("%d recently opened", len(msg.docs))
+		} else {
+			m.statusMsg = fmt.Sprintf("%d recently indexed", len(msg.docs))
+		}
+		m.statusIsErr = false
+		m.updatePreviewContent()
+		return m, nil
+
+	case randomDocLoadedMsg:
+		if msg.doc == nil {
+			m.statusMsg = "No documents to pick from"
+			m.statusIsErr = true
+			return m, nil
+		}
+		m.results = []*storage.Document{msg.doc}
+		m.alias = ""
+		m.highlights = nil
+		m.suggestions = query.Suggestions{}
+		m.facets = nil
+		m.moreDocs, m.loadingMore = false, false
+		m.cursor = 0
+		m.statusMsg = "Random note from " + msg.doc.Date().Local().Format("2006-01-02") + " · R for another"
+		m.statusIsErr = false
+		m.updatePreviewContent()
+		return m, nil
+
 	case searchDebounceMsg:
 		// Only act on the latest keystroke and only while editing the search.
 		if msg.version != m.searchVersion || m.panel != PanelSearch {
@@ -401,20 +949,92 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, m.searchDocuments(msg.query, true)
 
+	case generationMsg:
+		if msg.baseline {
+			m.generation = msg.generation
+			if m.polling {
+				return m, nil
+			}
+			// The first baseline starts the polling.
+			m.polling = true
+			return m, m.pollGeneration()
+		}
+		next := m.pollGeneration()
+		if msg.generation == m.generation || m.indexing {
+			return m, next
+		}
+		// Counts update at once; results wait for r, so they don't shift
+		// under the cursor.
+		m.generation = msg.generation
+		m.staleIndex = true
+		return m, tea.Batch(next, m.loadSourceCounts())
+
+	case configReloadedMsg:
+		if msg.err != nil {
+			m.notifyError("Config not reloaded: " + msg.err.Error())
+			return m, nil
+		}
+		msg.apply(&m)
+		m.contentDoc = nil
+		m.updatePreviewContent()
+		m.statusMsg = "Reloaded config; changes to sources, storage and embeddings apply after a restart"
+		m.statusIsErr = false
+		return m, m.refreshForSources()
+
 	case reindexDoneMsg:
 		m.indexing = false
 		if msg.err != nil {
-			m.statusMsg = "Index failed: " + msg.err.Error()
-			m.statusIsErr = true
+			m.notifyError("Index failed: " + msg.err.Error())
 			return m, nil
 		}
 		m.statusMsg = fmt.Sprintf("Indexed %d documents (%d errors)", msg.indexed, msg.errs)
 		m.statusIsErr = false
-		return m, m.loadDocuments()
+		if msg.errs > 0 {
+			m.logNotification(m.statusMsg, false)
+		}
+		m.staleIndex = false
+		return m, tea.Batch(m.loadDocuments(), m.loadSourceCounts(), m.syncGeneration())
+
+	case compareLoadedMsg:
+		m.openCompare(msg.left, msg.right)
+		return m, nil
+
+	case sourceCountsMsg:
+		m.sourceCounts = msg
+		return m, nil
+
+	case answerSavedMsg:
+		if msg.err != nil {
+			m.notifyError("Saving answer failed: " + msg.err.Error())
+			return m, nil
+		}
+		m.statusMsg = "Saved answer to " + msg.path
+		m.statusIsErr = false
+		return m, nil
+
+	case linkFollowedMsg:
+		if msg.err != nil {
+			if errors.Is(msg.err, storage.ErrNotFound) {
+				m.statusMsg = fmt.Sprintf("No document found for [[%s]]", msg.target)
+				m.statusIsErr = true
+			} else {
+				m.notifyError("Following link failed: " + msg.err.Error())
+			}
+			return m, nil
+		}
+		if cur := m.currentDoc(); cur != nil {
+			m.navStack = append(m.navStack, cur)
+		}
+		m.previewDoc = msg.doc
+		m.linkCursor = -1
+		m.renderPreview(msg.doc)
+		m.preview.GotoTop()
+		m.statusMsg = "Opened " + msg.doc.Title
+		m.statusIsErr = false
+		return m, nil
 
 	case errMsg:
-		m.statusMsg = msg.err.Error()
-		m.statusIsErr = true
+		m.notifyError(msg.err.Error())
 		return m, nil
 	}
 
@@ -422,6 +1042,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) updateSearch(msg tea.KeyMsg) (Model, tea.Cmd) {
+	// Pasting a passage finds related notes instead of searching for it.
+	if msg.Paste && (m.hybrid != nil || m.search != nil) && isPastedPassage(string(msg.Runes)) {
+		m.cancelStream()
+		m.searchVersion++ // drop any pending search-as-you-type query
+		m.searchInput.SetValue("")
+		m.statusMsg = "Finding similar notes..."
+		m.statusIsErr = false
+		return m, m.similarDocuments(string(msg.Runes))
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.Enter):
 		m.cancelStream()
@@ -458,6 +1088,9 @@ func (m Model) updateResults(msg tea.KeyMsg) (Model, tea.Cmd) {
 	if m.browsingCollections {
 		return m.updateBrowseCollections(msg)
 	}
+	if m.browsingEntities {
+		return m.updateBrowseEntities(msg)
+	}
 
 	switch {
 	case key.Matches(msg, m.keys.Up):
@@ -476,11 +1109,11 @@ func (m Model) updateResults(msg tea.KeyMsg) (Model, tea.Cmd) {
 			m.cursor++
 			m.updatePreviewContent()
 		}
-		return m, nil
+		return m, m.maybeLoadMore()
 
 	case key.Matches(msg, m.keys.PageDown):
 		m.moveCursor(m.pageStep())
-		return m, nil
+		return m, m.maybeLoadMore()
 
 	case key.Matches(msg, m.keys.PageUp):
 		m.moveCursor(-m.pageStep())
@@ -488,7 +1121,7 @@ func (m Model) updateResults(msg tea.KeyMsg) (Model, tea.Cmd) {
 
 	case key.Matches(msg, m.keys.HalfDown):
 		m.moveCursor(m.pageStep() / 2)
-		return m, nil
+		return m, m.maybeLoadMore()
 
 	case key.Matches(msg, m.keys.HalfUp):
 		m.moveCursor(-m.pageStep() / 2)
@@ -513,15 +1146,21 @@ func (m Model) updateResults(msg tea.KeyMsg) (Model, tea.Cmd) {
 			m.cursor = len(m.results) - 1
 			m.updatePreviewContent()
 		}
-		return m, nil
+		return m, m.maybeLoadMore()
 
 	case key.Matches(msg, m.keys.Open):
 		if m.cursor < len(m.results) {
 			doc := m.results[m.cursor]
 			if doc.Path != "" && !strings.HasPrefix(doc.Path, "clipboard:") {
-				go openFile(doc.Path)
-				m.statusMsg = "Opening: " + doc.Path
+				path := doc.FilePath()
+				// References open their attached PDF rather than the library.
+				if pdf := doc.Metadata["pdf"]; pdf != "" {
+					path = pdf
+				}
+				go func() { _ = OpenFile(path) }()
+				m.statusMsg = "Opening: " + path
 				m.statusIsErr = false
+				return m, m.recordOpened(doc.ID)
 			}
 		}
 		return m, nil
@@ -530,8 +1169,7 @@ func (m Model) updateResults(msg tea.KeyMsg) (Model, tea.Cmd) {
 		if m.cursor < len(m.results) {
 			doc := m.results[m.cursor]
 			if err := clipboard.WriteAll(doc.Path); err != nil {
-				m.statusMsg = "Copy failed: " + err.Error()
-				m.statusIsErr = true
+				m.notifyError("Copy failed: " + err.Error())
 			} else {
 				m.statusMsg = "Copied: " + doc.Path
 				m.statusIsErr = false
@@ -568,20 +1206,60 @@ func (m Model) updateResults(msg tea.KeyMsg) (Model, tea.Cmd) {
 			return collectionsLoadedMsg{collections: cols, counts: counts}
 		}
 
-	case key.Matches(msg, m.keys.Collection):
-		if m.cursor < len(m.results) {
-			m.collecting = true
-			m.collectInput.SetValue("")
-			m.collectInput.Focus()
-			m.statusMsg = "Enter collection name:"
-			m.statusIsErr = false
-		}
-		return m, nil
-
-	case key.Matches(msg, m.keys.Refresh):
-		m.statusMsg = "Refreshing..."
+	case key.Matches(msg, m.keys.BrowseEntities):
+		m.browsingEntities = true
+		m.entityCursor = 0
+		m.prevResults = m.results
+		m.statusMsg = "Loading people and projects..."
 		m.statusIsErr = false
-		return m, m.loadDocuments()
+		return m, func() tea.Msg {
+			entities, err := m.db.ListEntities(context.Background(), "")
+			if err != nil {
+				return errMsg{err}
+			}
+			return entitiesLoadedMsg{entities}
+		}
+
+	case key.Matches(msg, m.keys.RecentlyOpened):
+		return m, m.loadRecent(true)
+
+	case key.Matches(msg, m.keys.RecentlyIndexed):
+		return m, m.loadRecent(false)
+
+	case key.Matches(msg, m.keys.Random):
+		db := m.db
+		return m, func() tea.Msg {
+			doc, err := db.RandomDocument(context.Background(), "", time.Time{})
+			if err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return errMsg{err}
+			}
+			return randomDocLoadedMsg{doc}
+		}
+
+	case key.Matches(msg, m.keys.Notifications):
+		return m.openNotifications()
+
+	case key.Matches(msg, m.keys.Compare):
+		return m.markOrCompare()
+
+	case key.Matches(msg, m.keys.Collection):
+		if m.cursor < len(m.results) {
+			m.collecting = true
+			m.collectInput.SetValue("")
+			m.collectInput.Focus()
+			m.statusMsg = "Enter collection name:"
+			m.statusIsErr = false
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Refresh):
+		m.statusMsg = "Refreshing..."
+		m.statusIsErr = false
+		m.staleIndex = false
+		return m, tea.Batch(m.loadDocuments(), m.loadSourceCounts(), m.syncGeneration())
+
+	case key.Matches(msg, m.keys.SaveAnswer):
+		return m, m.saveCurrentAnswer()
 
 	case key.Matches(msg, m.keys.Index):
 		if m.reindex != nil && !m.indexing {
@@ -593,20 +1271,59 @@ func (m Model) updateResults(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m, nil
 
 	case key.Matches(msg, m.keys.Filter):
-		m.sourceFilter = nextSourceFilter(m.sourceFilter)
-		if q := strings.TrimSpace(m.searchInput.Value()); q != "" {
-			return m, m.searchDocuments(q, false)
+		// Cycle through single sources; from a mix of sources, go back to all.
+		var next storage.Source
+		switch len(m.sourceFilters) {
+		case 0:
+			next = nextSourceFilter("")
+		case 1:
+			next = nextSourceFilter(m.sourceFilters[0])
 		}
-		return m, m.loadDocuments()
+		m.sourceFilters = nil
+		if next != "" {
+			m.sourceFilters = []storage.Source{next}
+		}
+		return m, m.refreshForSources()
+
+	case key.Matches(msg, m.keys.ToggleSource):
+		i := int(msg.String()[0] - '1')
+		if i < 0 || i >= len(storage.AllSources) {
+			return m, nil
+		}
+		m.sourceFilters = toggleSource(m.sourceFilters, storage.AllSources[i])
+		return m, m.refreshForSources()
 	}
 
 	return m, nil
 }
 
+// refreshForSources reruns the current search, or reloads the document
+// list, after the source filters change.
+func (m Model) refreshForSources() tea.Cmd {
+	if q := strings.TrimSpace(m.searchInput.Value()); q != "" {
+		return m.searchDocuments(q, false)
+	}
+	return m.loadDocuments()
+}
+
+// toggleSource adds source to the filters, or removes it when present. The
+// result keeps the order of storage.AllSources.
+func toggleSource(filters []storage.Source, source storage.Source) []storage.Source {
+	on := !slices.Contains(filters, source)
+	var out []storage.Source
+	for _, s := range storage.AllSources {
+		if s == source && on || s != source && slices.Contains(filters, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
 // sourceFilterCycle is the order the 'f' key rotates through ("" = all).
 var sourceFilterCycle = []storage.Source{
 	"", storage.SourceMarkdown, storage.SourcePDF, storage.SourceEmail,
-	storage.SourceBrowser, storage.SourceClipboard,
+	storage.SourceBrowser, storage.SourceClipboard, storage.SourceData,
+	storage.SourceReference, storage.SourceScreenshot, storage.SourceProject,
 }
 
 func nextSourceFilter(current storage.Source) storage.Source {
@@ -635,8 +1352,7 @@ func (m Model) updateTagInput(msg tea.KeyMsg) (Model, tea.Cmd) {
 			doc := m.results[m.cursor]
 			ctx := context.Background()
 			if err := m.db.AddTag(ctx, doc.ID, tag); err != nil {
-				m.statusMsg = "Tag error: " + err.Error()
-				m.statusIsErr = true
+				m.notifyError("Tag error: " + err.Error())
 			} else {
 				m.statusMsg = fmt.Sprintf("Added tag %q to %s", tag, doc.Title)
 				m.statusIsErr = false
@@ -715,6 +1431,43 @@ func (m Model) updateBrowseCollections(msg tea.KeyMsg) (Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m Model) updateBrowseEntities(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.entityCursor > 0 {
+			m.entityCursor--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		if m.entityCursor < len(m.entities)-1 {
+			m.entityCursor++
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		if m.entityCursor < len(m.entities) {
+			// Search for the entity's documents with a filter the user can
+			// refine, e.g. person:"Jane Doe" budget.
+			e := m.entities[m.entityCursor]
+			m.browsingEntities = false
+			q := fmt.Sprintf("%s:%q", e.Kind, e.Name)
+			m.searchInput.SetValue(q)
+			return m, m.searchDocuments(q, false)
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Escape):
+		m.browsingEntities = false
+		m.results = m.prevResults
+		m.cursor = 0
+		m.statusMsg = ""
+		m.updatePreviewContent()
+		return m, nil
+	}
+	return m, nil
+}
+
 func (m Model) updateCollectInput(msg tea.KeyMsg) (Model, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyEnter:
@@ -729,8 +1482,7 @@ func (m Model) updateCollectInput(msg tea.KeyMsg) (Model, tea.Cmd) {
 				// Collection doesn't exist, create it.
 				col = &storage.Collection{Name: name}
 				if createErr := m.db.CreateCollection(ctx, col); createErr != nil {
-					m.statusMsg = "Collection error: " + createErr.Error()
-					m.statusIsErr = true
+					m.notifyError("Collection error: " + createErr.Error())
 					m.collecting = false
 					m.collectInput.Blur()
 					return m, nil
@@ -738,8 +1490,7 @@ func (m Model) updateCollectInput(msg tea.KeyMsg) (Model, tea.Cmd) {
 			}
 
 			if err := m.db.AddToCollection(ctx, col.ID, doc.ID); err != nil {
-				m.statusMsg = "Collection error: " + err.Error()
-				m.statusIsErr = true
+				m.notifyError("Collection error: " + err.Error())
 			} else {
 				m.statusMsg = fmt.Sprintf("Added to collection %q", name)
 				m.statusIsErr = false
@@ -762,6 +1513,35 @@ func (m Model) updateCollectInput(msg tea.KeyMsg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
+// loadRecent lists the documents opened, or else indexed, most recently.
+func (m Model) loadRecent(opened bool) tea.Cmd {
+	db, limit := m.db, m.resultsLimit
+	return func() tea.Msg {
+		ctx := context.Background()
+		var docs []*storage.Document
+		var err error
+		if opened {
+			docs, err = db.RecentInteractions(ctx, storage.InteractionOpened, limit)
+		} else {
+			docs, err = db.RecentlyIndexed(ctx, limit)
+		}
+		if err != nil {
+			return errMsg{err}
+		}
+		return recentDocsLoadedMsg{docs: docs, opened: opened}
+	}
+}
+
+// recordOpened notes that a document was opened, for the recently opened
+// list. Failing to is not worth interrupting the user over.
+func (m Model) recordOpened(id string) tea.Cmd {
+	db := m.db
+	return func() tea.Msg {
+		_ = db.RecordInteraction(context.Background(), id, storage.InteractionOpened)
+		return nil
+	}
+}
+
 // stripHighlightTags removes Bleve's HTML highlight markers from a fragment.
 func stripHighlightTags(s string) string {
 	s = strings.ReplaceAll(s, "<mark>", "")
@@ -769,8 +1549,8 @@ func stripHighlightTags(s string) string {
 	return s
 }
 
-// openFile opens a file with the system's default application.
-func openFile(path string) {
+// OpenFile opens a file with the system's default application.
+func OpenFile(path string) error {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
 	case "darwin":
@@ -778,16 +1558,80 @@ func openFile(path string) {
 	case "linux":
 		cmd = exec.Command("xdg-open", path)
 	default:
-		return
+		return fmt.Errorf("opening files is not supported on %s", runtime.GOOS)
 	}
-	_ = cmd.Run()
+	return cmd.Run()
 }
 
 func (m Model) updatePreview(msg tea.KeyMsg) (Model, tea.Cmd) {
 	switch {
 	case key.Matches(msg, m.keys.Search):
-		m.panel = PanelSearch
-		m.searchInput.Focus()
+		// In the preview, / finds within the document.
+		if m.currentDoc() == nil {
+			return m, nil
+		}
+		m.finding = true
+		m.findInput.SetValue(m.findTerm)
+		m.findInput.CursorEnd()
+		m.findInput.Focus()
+		return m, nil
+
+	case key.Matches(msg, m.keys.FindNext):
+		m.stepFind(1)
+		return m, nil
+
+	case key.Matches(msg, m.keys.FindPrev):
+		m.stepFind(-1)
+		return m, nil
+
+	case key.Matches(msg, m.keys.NextLink):
+		total := len(m.links) + len(m.assets)
+		if total == 0 {
+			m.statusMsg = "No links in this document"
+			m.statusIsErr = false
+			return m, nil
+		}
+		m.linkCursor = (m.linkCursor + 1) % total
+		if m.linkCursor < len(m.links) {
+			m.statusMsg = "Link: " + m.links[m.linkCursor]
+		} else {
+			m.statusMsg = assetLabel(m.currentDoc()) + ": " + m.assets[m.linkCursor-len(m.links)]
+		}
+		m.statusIsErr = false
+		m.renderPreview(m.currentDoc())
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		switch {
+		case m.linkCursor < 0:
+			return m, nil
+		case m.linkCursor < len(m.links):
+			return m, m.followLink(m.links[m.linkCursor])
+		case m.linkCursor < len(m.links)+len(m.assets):
+			path := m.assets[m.linkCursor-len(m.links)]
+			go func() { _ = OpenFile(path) }()
+			m.statusMsg = "Opening: " + path
+			m.statusIsErr = false
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.SaveAnswer):
+		return m, m.saveCurrentAnswer()
+
+	case key.Matches(msg, m.keys.Back):
+		if len(m.navStack) == 0 {
+			return m, nil
+		}
+		prev := m.navStack[len(m.navStack)-1]
+		m.navStack = m.navStack[:len(m.navStack)-1]
+		if len(m.navStack) == 0 {
+			m.previewDoc = nil
+		} else {
+			m.previewDoc = prev
+		}
+		m.linkCursor = -1
+		m.renderPreview(prev)
+		m.preview.GotoTop()
 		return m, nil
 	}
 
@@ -796,6 +1640,73 @@ func (m Model) updatePreview(msg tea.KeyMsg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m Model) updateFindInput(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.finding = false
+		m.findInput.Blur()
+		m.setFindTerm(strings.TrimSpace(m.findInput.Value()))
+		m.findCursor = 0
+		m.jumpToMatch()
+		return m, nil
+
+	case tea.KeyEsc:
+		m.finding = false
+		m.findInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.findInput, cmd = m.findInput.Update(msg)
+	return m, cmd
+}
+
+// setFindTerm highlights term in the preview, or clears the find when term
+// is empty.
+func (m *Model) setFindTerm(term string) {
+	m.findTerm = term
+	m.findCursor = 0
+	if doc := m.currentDoc(); doc != nil {
+		offset := m.preview.YOffset
+		m.renderPreview(doc)
+		m.preview.SetYOffset(offset)
+	}
+}
+
+// stepFind moves to the next (delta 1) or previous (delta -1) match,
+// wrapping around at either end.
+func (m *Model) stepFind(delta int) {
+	if m.findTerm == "" {
+		m.statusMsg = "Press / to find in this document"
+		m.statusIsErr = false
+		return
+	}
+	if n := len(m.findMatches); n > 0 {
+		m.findCursor = (m.findCursor + delta + n) % n
+	}
+	m.jumpToMatch()
+}
+
+// jumpToMatch scrolls the preview to the current match and reports it in
+// the status bar.
+func (m *Model) jumpToMatch() {
+	if m.findTerm == "" {
+		m.statusMsg = ""
+		m.statusIsErr = false
+		return
+	}
+	if len(m.findMatches) == 0 {
+		m.statusMsg = fmt.Sprintf("No matches for %q", m.findTerm)
+		m.statusIsErr = true
+		return
+	}
+	m.findCursor = min(m.findCursor, len(m.findMatches)-1)
+	// Keep a couple of lines above the match in view.
+	m.preview.SetYOffset(max(0, m.findMatches[m.findCursor]-2))
+	m.statusMsg = fmt.Sprintf("%q: match %d of %d (n/N)", m.findTerm, m.findCursor+1, len(m.findMatches))
+	m.statusIsErr = false
+}
+
 func (m *Model) nextPanel() {
 	m.panel = (m.panel + 1) % 3
 	m.updateFocus()
@@ -869,7 +1780,7 @@ func (m *Model) showAnswer() {
 		fmt.Sprintf("Confidence: %s (%.2f)", strings.ToUpper(conf.Level), conf.Score),
 	))
 	sb.WriteString("\n")
-	sb.WriteString(styles.ResultSourceStyle.Render(fmt.Sprintf("Based on %d sources", min(5, len(m.results)))))
+	sb.WriteString(styles.ResultSourceStyle.Render(fmt.Sprintf("Based on %d sources", len(m.answerSources()))))
 	m.preview.SetContent(sb.String())
 }
 
@@ -886,13 +1797,27 @@ func (m *Model) startStreaming(question string, docs []*storage.Document) tea.Cm
 
 	ch := make(chan streamChunkMsg, 64)
 	m.streamCh = ch
+	m.answerContext = nil
 
-	contexts := buildAnswerContexts(docs)
+	hybrid, budget, db := m.hybrid, m.answerBudget, m.db
 	history := m.conversation
+	// Results hold summaries, and the previewed one's content is released
+	// as the cursor moves, so the answer reads copies, fetching the content
+	// of those it may quote.
+	docs = copyDocuments(docs)
 
 	go func() {
 		defer close(ch)
-		err := m.llm.GenerateAnswerStreamWithHistory(ctx, question, contexts, history, func(token string, done bool) {
+		fillContent(ctx, db, query.ContextDocuments(docs, budget))
+		// Picking the least redundant passages embeds the question, so it
+		// is done here rather than holding up the UI.
+		contexts := hybrid.AssembleContexts(ctx, question, docs, budget)
+		select {
+		case ch <- streamChunkMsg{contexts: contexts}:
+		case <-ctx.Done():
+			return
+		}
+		err := m.llm.GenerateAnswerStreamWithHistory(ctx, question, query.ContextTexts(contexts), history, func(token string, done bool) {
 			select {
 			case ch <- streamChunkMsg{token: token, done: done}:
 			case <-ctx.Done():
@@ -925,23 +1850,52 @@ func (m *Model) recordConversationTurn() {
 	}
 }
 
+// answerSavedMsg reports the outcome of saving an answer transcript.
+type answerSavedMsg struct {
+	path string
+	err  error
+}
+
+// saveCurrentAnswer saves the last completed answer with its sources.
+func (m *Model) saveCurrentAnswer() tea.Cmd {
+	switch {
+	case m.saveAnswer == nil:
+		m.statusMsg = "Saving answers is not available"
+		m.statusIsErr = true
+		return nil
+	case m.streaming || m.answerText == "":
+		m.statusMsg = "No answer to save"
+		m.statusIsErr = true
+		return nil
+	}
+
+	t := query.Transcript{
+		Question:   m.currentQuestion,
+		Answer:     m.redactor.Redact(m.answerText),
+		Sources:    m.answerSources(),
+		Confidence: query.EstimateAnswerConfidence(m.currentQuestion, m.answerContexts()),
+		CreatedAt:  time.Now(),
+	}
+	save := m.saveAnswer
+	return func() tea.Msg {
+		path, err := save(context.Background(), t)
+		return answerSavedMsg{path: path, err: err}
+	}
+}
+
 func (m *Model) answerContexts() []string {
-	return buildAnswerContexts(m.results)
+	if m.answerContext != nil {
+		return query.ContextTexts(m.answerContext)
+	}
+	return query.BuildContexts(m.results, m.answerBudget)
 }
 
-func buildAnswerContexts(docs []*storage.Document) []string {
-	contexts := make([]string, 0, 5)
-	for i, doc := range docs {
-		if i >= 5 {
-			break
-		}
-		content := doc.Content
-		if len(content) > 1000 {
-			content = content[:1000]
-		}
-		contexts = append(contexts, content)
+// answerSources returns the results the current answer is based on.
+func (m *Model) answerSources() []*storage.Document {
+	if m.answerContext != nil {
+		return query.ContextSources(m.answerContext)
 	}
-	return contexts
+	return query.ContextDocuments(m.results, m.answerBudget)
 }
 
 func (m *Model) cancelStream() {
@@ -962,13 +1916,95 @@ func (m *Model) readNextChunk() tea.Cmd {
 	}
 }
 
+// wikiLinkRegex matches [[target]] style links in markdown content.
+var wikiLinkRegex = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
+
+// linkFollowedMsg carries the document a followed wiki link resolved to.
+type linkFollowedMsg struct {
+	target string
+	doc    *storage.Document
+	err    error
+}
+
+// followLink resolves a wiki link target to a document in the background.
+func (m Model) followLink(target string) tea.Cmd {
+	db := m.db
+	return func() tea.Msg {
+		doc, err := db.ResolveLink(context.Background(), target)
+		return linkFollowedMsg{target: target, doc: doc, err: err}
+	}
+}
+
+// currentDoc returns the document shown in the preview: the one reached by
+// following links, or else the selected result.
+func (m Model) currentDoc() *storage.Document {
+	if m.previewDoc != nil {
+		return m.previewDoc
+	}
+	if len(m.results) == 0 || m.cursor >= len(m.results) {
+		return nil
+	}
+	return m.results[m.cursor]
+}
+
+// extractWikiLinks returns the unique wiki link targets in content, in order
+// of appearance. Embeds of files other than notes, such as ![[image.png]],
+// are listed as assets instead.
+func extractWikiLinks(content string) []string {
+	var links []string
+	seen := make(map[string]bool)
+	for _, loc := range wikiLinkRegex.FindAllStringSubmatchIndex(content, -1) {
+		target := strings.TrimSpace(content[loc[2]:loc[3]])
+		if target == "" || seen[target] {
+			continue
+		}
+		if loc[0] > 0 && content[loc[0]-1] == '!' && isAssetEmbed(target) {
+			continue
+		}
+		seen[target] = true
+		links = append(links, target)
+	}
+	return links
+}
+
+// isAssetEmbed reports whether an embed target names a file other than a
+// note, such as "diagram.png|300".
+func isAssetEmbed(target string) bool {
+	name, _, _ := strings.Cut(target, "|")
+	name, _, _ = strings.Cut(name, "#")
+	ext := strings.ToLower(filepath.Ext(strings.TrimSpace(name)))
+	return ext != "" && ext != ".md" && ext != ".markdown"
+}
+
+// updatePreviewContent shows the selected result, discarding any link
+// navigation history.
 func (m *Model) updatePreviewContent() {
+	m.previewDoc = nil
+	m.navStack = nil
+	m.linkCursor = -1
+	m.findTerm = ""
+	m.findMatches = nil
+	m.findCursor = 0
 	if len(m.results) == 0 || m.cursor >= len(m.results) {
+		m.links = nil
 		m.preview.SetContent("No document selected")
 		return
 	}
+	m.renderPreview(m.results[m.cursor])
+}
+
+// renderPreview renders doc into the preview viewport.
+func (m *Model) renderPreview(doc *storage.Document) {
+	m.ensureContent(doc)
+	m.links, m.assets = nil, nil
+	switch doc.Source {
+	case storage.SourceMarkdown:
+		m.links = extractWikiLinks(doc.Content)
+		m.assets, _ = m.db.GetRelations(context.Background(), doc.ID, storage.RelationAsset)
+	case storage.SourceBrowser:
+		m.assets = browserPages(doc.Content)
+	}
 
-	doc := m.results[m.cursor]
 	var sb strings.Builder
 
 	sb.WriteString(styles.PreviewTitleStyle.Render(doc.Title))
@@ -977,9 +2013,28 @@ func (m *Model) updatePreviewContent() {
 	sb.WriteString(" • ")
 	sb.WriteString(styles.PreviewMetadataStyle.Render(doc.Path))
 	sb.WriteString("\n")
+	if fields := sourceFields(doc); len(fields) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderSourceFields(fields))
+	}
+	if words := doc.WordCount(); words > 0 {
+		sb.WriteString(styles.PreviewMetadataStyle.Render(fmt.Sprintf("%d words • %d min read",
+			words, int(storage.ReadingTime(words).Minutes()))))
+		sb.WriteString("\n")
+	}
 	if tags := doc.Metadata["tags"]; tags != "" {
 		sb.WriteString("Tags: " + tags + "\n")
 	}
+	if storage.CountWords(doc.Content) >= keywordMinWords {
+		if keywords := query.ExtractKeywords(doc.Content, nil, previewKeywords); len(keywords) > 0 {
+			phrases := make([]string, len(keywords))
+			for i, k := range keywords {
+				phrases[i] = k.Phrase
+			}
+			sb.WriteString(styles.PreviewMetadataStyle.Render("Keywords: " + m.redactor.Redact(strings.Join(phrases, ", "))))
+			sb.WriteString("\n")
+		}
+	}
 	// Show collection memberships.
 	if cols, err := m.db.GetDocumentCollections(context.Background(), doc.ID); err == nil && len(cols) > 0 {
 		for i, c := range cols {
@@ -1007,16 +2062,149 @@ func (m *Model) updatePreviewContent() {
 		sb.WriteString("\n")
 	}
 
+	if len(m.links) > 0 {
+		sb.WriteString(styles.ResultSourceStyle.Render("Links:"))
+		sb.WriteString("\n")
+		for i, link := range m.links {
+			if i == m.linkCursor {
+				sb.WriteString(styles.SelectedResultStyle.Render("→ [[" + link + "]]"))
+			} else {
+				sb.WriteString(styles.PreviewMetadataStyle.Render("  [[" + link + "]]"))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+	if len(m.assets) > 0 {
+		sb.WriteString(styles.ResultSourceStyle.Render(assetLabel(doc) + "s:"))
+		sb.WriteString("\n")
+		for i, asset := range m.assets {
+			if len(m.links)+i == m.linkCursor {
+				sb.WriteString(styles.SelectedResultStyle.Render("→ " + asset))
+			} else {
+				sb.WriteString(styles.PreviewMetadataStyle.Render("  " + asset))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	// Long documents are cut short, except while finding within them so
+	// that every match can be reached.
 	content := doc.Content
-	if len(content) > 2000 {
-		content = content[:2000] + "..."
+	if m.findTerm == "" {
+		content = chunker.Truncate(content, m.previewLength)
 	}
 	content = m.redactor.Redact(content)
-	sb.WriteString(styles.PreviewContentStyle.Render(content))
+	m.findMatches = nil
+	if m.findTerm == "" {
+		sb.WriteString(styles.PreviewContentStyle.Render(content))
+	} else {
+		offset := strings.Count(sb.String(), "\n")
+		for i, line := range strings.Split(content, "\n") {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			rendered, n := highlightMatches(line, m.findTerm)
+			for range n {
+				m.findMatches = append(m.findMatches, offset+i)
+			}
+			sb.WriteString(rendered)
+		}
+	}
 
 	m.preview.SetContent(sb.String())
 }
 
+// highlightMatches renders line with each case-insensitive occurrence of
+// term highlighted, and returns the number of occurrences.
+func highlightMatches(line, term string) (string, int) {
+	var sb strings.Builder
+	n, start := 0, 0
+	for i := 0; i+len(term) <= len(line); {
+		if !strings.EqualFold(line[i:i+len(term)], term) {
+			_, size := utf8.DecodeRuneInString(line[i:])
+			i += size
+			continue
+		}
+		if start < i {
+			sb.WriteString(styles.PreviewContentStyle.Render(line[start:i]))
+		}
+		sb.WriteString(styles.PreviewFindMatchStyle.Render(line[i : i+len(term)]))
+		n++
+		i += len(term)
+		start = i
+	}
+	if n == 0 {
+		return styles.PreviewContentStyle.Render(line), 0
+	}
+	if start < len(line) {
+		sb.WriteString(styles.PreviewContentStyle.Render(line[start:]))
+	}
+	return sb.String(), n
+}
+
+// ensureContent fills in the content of a document that was listed as a
+// summary. The document is updated in place so the content is fetched once.
+func (m *Model) ensureContent(doc *storage.Document) {
+	// Only the previewed result holds its content, however long the list.
+	if m.contentDoc != nil && m.contentDoc != doc {
+		m.contentDoc.Content = ""
+	}
+	m.contentDoc = nil
+	if doc.Content != "" || doc.ID == "" {
+		return
+	}
+	full, err := m.db.GetDocument(context.Background(), doc.ID)
+	if err != nil {
+		return
+	}
+	doc.Content = full.Content
+	m.contentDoc = doc
+}
+
+// summaries returns copies of docs without their content, which is fetched
+// for the previewed one and for answers, so a long result list holds only
+// what it shows.
+func summaries(docs []*storage.Document) []*storage.Document {
+	out := copyDocuments(docs)
+	for _, doc := range out {
+		doc.Content = ""
+	}
+	return out
+}
+
+// copyDocuments returns shallow copies of docs.
+func copyDocuments(docs []*storage.Document) []*storage.Document {
+	out := make([]*storage.Document, len(docs))
+	for i, doc := range docs {
+		c := *doc
+		out[i] = &c
+	}
+	return out
+}
+
+// fillContent fetches the content of those docs that have none. Documents
+// that can't be read are left empty.
+func fillContent(ctx context.Context, db *storage.DB, docs []*storage.Document) {
+	for _, doc := range docs {
+		if doc.Content != "" {
+			continue
+		}
+		if full, err := db.GetDocument(ctx, doc.ID); err == nil {
+			doc.Content = full.Content
+		}
+	}
+}
+
+// documentCountStatus describes the size of the browse list.
+func documentCountStatus(n int, more bool) string {
+	if more {
+		return fmt.Sprintf("%d+ documents", n)
+	}
+	return fmt.Sprintf("%d documents", n)
+}
+
 // View renders the UI.
 func (m Model) View() string {
 	if m.width == 0 {
@@ -1032,9 +2220,17 @@ func (m Model) View() string {
 	previewWidth := m.width*40/100 - 4
 	contentHeight := m.height - 6 // Header, search, status
 
-	// Header
-	header := styles.TitleStyle.Render("MindCLI") +
-		styles.SubtitleStyle.Render(" - Personal Knowledge Search")
+	// Header, with the source counts when they fit
+	title := styles.TitleStyle.Render("MindCLI")
+	subtitle := styles.SubtitleStyle.Render(" - Personal Knowledge Search")
+	counts := m.renderSourceCounts()
+	header := lipgloss.JoinHorizontal(lipgloss.Top, title, subtitle)
+	switch {
+	case lipgloss.Width(header)+lipgloss.Width(counts) <= m.width:
+		header = lipgloss.JoinHorizontal(lipgloss.Top, title, subtitle, counts)
+	case lipgloss.Width(title)+lipgloss.Width(counts) <= m.width:
+		header = lipgloss.JoinHorizontal(lipgloss.Top, title, counts)
+	}
 
 	// Search input
 	searchStyle := styles.PanelStyle
@@ -1043,18 +2239,31 @@ func (m Model) View() string {
 	}
 	searchBox := searchStyle.Width(m.width - 4).Render(m.searchInput.View())
 
+	// Facets sidebar, taking its width from the results
+	var facetsPanel string
+	if m.showFacets() {
+		resultsWidth -= facetsWidth + 2
+		facetsPanel = styles.PanelStyle.Width(facetsWidth).Height(contentHeight).Render(
+			styles.PanelTitleStyle.Render("Matches") + "\n" + m.renderFacets(facetsWidth-2, contentHeight-2),
+		)
+	}
+
 	// Results panel
 	resultsStyle := styles.PanelStyle.Width(resultsWidth).Height(contentHeight)
 	if m.panel == PanelResults {
 		resultsStyle = styles.FocusedPanelStyle.Width(resultsWidth).Height(contentHeight)
 	}
 	resultsContent := m.renderResults(resultsWidth-2, contentHeight-2)
-	resultsPanelTitle := "Results"
+	resultsPanelTitle := styles.PanelTitleStyle.Render("Results")
 	if m.browsingCollections {
-		resultsPanelTitle = "Collections"
+		resultsPanelTitle = styles.PanelTitleStyle.Render("Collections")
+	} else if m.browsingEntities {
+		resultsPanelTitle = styles.PanelTitleStyle.Render("People & projects")
+	} else {
+		resultsPanelTitle += m.renderSourceChips(resultsWidth - 2 - lipgloss.Width(resultsPanelTitle))
 	}
 	resultsPanel := resultsStyle.Render(
-		styles.PanelTitleStyle.Render(resultsPanelTitle) + "\n" + resultsContent,
+		resultsPanelTitle + "\n" + resultsContent,
 	)
 
 	// Preview panel
@@ -1070,6 +2279,25 @@ func (m Model) View() string {
 
 	// Content area (results + preview side by side)
 	content := lipgloss.JoinHorizontal(lipgloss.Top, resultsPanel, previewPanel)
+	if facetsPanel != "" {
+		content = lipgloss.JoinHorizontal(lipgloss.Top, facetsPanel, resultsPanel, previewPanel)
+	}
+
+	// Compared documents, the notifications log and the command palette
+	// take the place of the panels while open.
+	if m.comparing {
+		content = m.renderCompare(m.width, contentHeight)
+	}
+	if m.notifying {
+		content = styles.FocusedPanelStyle.Width(m.width - 4).Height(contentHeight).Render(
+			styles.PanelTitleStyle.Render("Notifications") + "\n" + m.renderNotifications(m.width-6, contentHeight-2),
+		)
+	}
+	if m.palette {
+		content = styles.FocusedPanelStyle.Width(m.width - 4).Height(contentHeight).Render(
+			styles.PanelTitleStyle.Render("Commands") + "\n" + m.renderPalette(m.width-6, contentHeight-2),
+		)
+	}
 
 	// Status bar
 	statusBar := m.renderStatusBar()
@@ -1083,15 +2311,165 @@ func (m Model) View() string {
 	)
 }
 
+// showFacets reports whether the facets sidebar is shown: for search
+// results with matches, in a wide enough window.
+func (m Model) showFacets() bool {
+	return m.facets != nil && m.facets.Total > 0 && m.width >= minFacetsWidth &&
+		!m.browsingCollections && !m.browsingEntities
+}
+
+// renderFacets renders the match counts of the search by source, tag and
+// month. Sources show the number key that toggles them as a filter, and
+// the active ones are highlighted.
+func (m Model) renderFacets(width, height int) string {
+	total := fmt.Sprintf("%d matches", m.facets.Total)
+	if m.facets.Total == 1 {
+		total = "1 match"
+	}
+	lines := []string{styles.ResultSourceStyle.Render(total)}
+	row := func(key, value string, style lipgloss.Style, count int) string {
+		n := fmt.Sprint(count)
+		value = chunker.TruncateWidth(value, width-lipgloss.Width(key)-len(n)-1)
+		gap := max(width-lipgloss.Width(key)-lipgloss.Width(value)-len(n), 1)
+		return key + style.Render(value) + strings.Repeat(" ", gap) + styles.StatusValueStyle.Render(n)
+	}
+	section := func(title string, counts []search.FacetCount, render func(search.FacetCount) string) {
+		if len(counts) == 0 {
+			return
+		}
+		lines = append(lines, "", styles.PanelTitleStyle.Render(title))
+		for _, c := range counts {
+			lines = append(lines, render(c))
+		}
+	}
+	plain := lipgloss.NewStyle()
+	section("Sources", m.facets.Sources, func(c search.FacetCount) string {
+		key, style := "  ", plain
+		if i := slices.Index(storage.AllSources, storage.Source(c.Value)); i >= 0 {
+			key = styles.HelpKeyStyle.Render(fmt.Sprint(i+1)) + " "
+		}
+		if slices.Contains(m.sourceFilters, storage.Source(c.Value)) {
+			style = styles.SourceBadge(c.Value).Padding(0)
+		}
+		return row(key, c.Value, style, c.Count)
+	})
+	section("Tags", m.facets.Tags, func(c search.FacetCount) string { return row("  ", c.Value, plain, c.Count) })
+	section("Months", m.facets.Months, func(c search.FacetCount) string { return row("  ", c.Value, plain, c.Count) })
+	if len(lines) > height {
+		lines = lines[:max(height, 0)]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSourceChips renders the source filters as numbered chips, the
+// active ones highlighted, toggled with the number keys. When all chips
+// don't fit in width, only the active ones are shown.
+func (m Model) renderSourceChips(width int) string {
+	var all, active strings.Builder
+	for i, s := range storage.AllSources {
+		on := slices.Contains(m.sourceFilters, s)
+		chip := " " + styles.SourceChip(fmt.Sprintf("%d %s", i+1, s), string(s), on)
+		all.WriteString(chip)
+		if on {
+			active.WriteString(chip)
+		}
+	}
+	if lipgloss.Width(all.String()) <= width {
+		return all.String()
+	}
+	if lipgloss.Width(active.String()) <= width {
+		return active.String()
+	}
+	return ""
+}
+
+// sourceAbbrevs are the short names the header gives sources.
+var sourceAbbrevs = map[storage.Source]string{
+	storage.SourceMarkdown:   "md",
+	storage.SourceClipboard:  "clip",
+	storage.SourceReference:  "ref",
+	storage.SourceScreenshot: "shot",
+	storage.SourceProject:    "proj",
+}
+
+// renderSourceCounts renders the number of documents of each indexed source,
+// as "1 md 1.2k · 2 pdf 300", numbered by the key that toggles it as a
+// filter and highlighted when active. Sources with no documents are left
+// out.
+func (m Model) renderSourceCounts() string {
+	var parts []string
+	for i, s := range storage.AllSources {
+		n := m.sourceCounts[s]
+		if n == 0 {
+			continue
+		}
+		name := sourceAbbrevs[s]
+		if name == "" {
+			name = string(s)
+		}
+		label := fmt.Sprintf("%d %s %s", i+1, name, compactCount(n))
+		parts = append(parts, styles.SourceChip(label, string(s), slices.Contains(m.sourceFilters, s)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "  " + strings.Join(parts, lipgloss.NewStyle().Foreground(styles.ColorMuted).Render("·"))
+}
+
+// compactCount formats n in at most four characters, as 950, 1.2k, 12k or
+// 3.4M.
+func compactCount(n int) string {
+	short := func(v float64, unit string) string {
+		if v = math.Floor(v*10) / 10; v < 10 {
+			return strings.TrimSuffix(fmt.Sprintf("%.1f", v), ".0") + unit
+		}
+		return fmt.Sprintf("%d", int(v)) + unit
+	}
+	switch {
+	case n < 1000:
+		return fmt.Sprint(n)
+	case n < 1000000:
+		return short(float64(n)/1000, "k")
+	default:
+		return short(float64(n)/1000000, "M")
+	}
+}
+
+// suggestionHint describes the alternatives to a search that found
+// nothing, one per line, or returns "" when there are none.
+func suggestionHint(sugg query.Suggestions) string {
+	var lines []string
+	if sugg.Spelling != "" {
+		lines = append(lines, fmt.Sprintf("Did you mean %q?", sugg.Spelling))
+	}
+	for _, b := range sugg.Broader {
+		lines = append(lines, fmt.Sprintf("Without %s: %d found", b.Dropped, b.Found))
+	}
+	if len(sugg.Nearest) > 0 {
+		titles := make([]string, len(sugg.Nearest))
+		for i, r := range sugg.Nearest {
+			titles[i] = r.Document.Title
+		}
+		lines = append(lines, "Nearest in meaning: "+strings.Join(titles, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (m Model) renderResults(width, height int) string {
 	if m.browsingCollections {
 		return m.renderCollectionsList(width, height)
 	}
+	if m.browsingEntities {
+		return m.renderEntitiesList(width, height)
+	}
 
 	if len(m.results) == 0 {
 		if m.searchInput.Value() == "" && m.reindex != nil {
 			return styles.ResultPreviewStyle.Render("No documents yet. Press i to index your sources, or / to search.")
 		}
+		if hint := suggestionHint(m.suggestions); hint != "" {
+			return styles.ResultPreviewStyle.Render("No results.\n" + hint)
+		}
 		return styles.ResultPreviewStyle.Render("No results. Press / to search.")
 	}
 
@@ -1117,9 +2495,7 @@ func (m Model) renderResults(width, height int) string {
 		if title == "" {
 			title = doc.Path
 		}
-		if len(title) > width-4 {
-			title = title[:width-7] + "..."
-		}
+		title = chunker.TruncateWidth(title, width-4)
 
 		var line string
 		if i == m.cursor {
@@ -1129,6 +2505,12 @@ func (m Model) renderResults(width, height int) string {
 		}
 
 		source := styles.SourceBadge(string(doc.Source)).Render(string(doc.Source))
+		if vault := doc.Metadata["vault"]; vault != "" {
+			source += " " + styles.VaultBadge(vault)
+		}
+		if i == 0 && m.alias != "" {
+			source = styles.AliasBadge(m.alias) + " " + source
+		}
 		var tagStr string
 		if tags := doc.Metadata["tags"]; tags != "" {
 			for _, t := range strings.Split(tags, ",") {
@@ -1169,9 +2551,7 @@ func (m Model) renderCollectionsList(width, height int) string {
 	for i := start; i < end; i++ {
 		col := m.collections[i]
 		label := fmt.Sprintf("%s (%d docs)", col.Name, m.collectionCounts[col.ID])
-		if len(label) > width-4 {
-			label = label[:width-7] + "..."
-		}
+		label = chunker.TruncateWidth(label, width-4)
 
 		var line string
 		if i == m.collectionCursor {
@@ -1189,6 +2569,44 @@ func (m Model) renderCollectionsList(width, height int) string {
 	return sb.String()
 }
 
+func (m Model) renderEntitiesList(width, height int) string {
+	if len(m.entities) == 0 {
+		return styles.ResultPreviewStyle.Render("No people or projects. Set indexing.entities: true and reindex.")
+	}
+
+	var sb strings.Builder
+	visibleCount := height / 2
+	if visibleCount < 1 {
+		visibleCount = 1
+	}
+
+	start := 0
+	if m.entityCursor >= visibleCount {
+		start = m.entityCursor - visibleCount + 1
+	}
+	end := min(start+visibleCount, len(m.entities))
+
+	for i := start; i < end; i++ {
+		e := m.entities[i]
+		label := fmt.Sprintf("%-7s %s (%d docs)", e.Kind, e.Name, e.Documents)
+		label = chunker.TruncateWidth(label, width-4)
+
+		var line string
+		if i == m.entityCursor {
+			line = styles.SelectedResultStyle.Render(label)
+		} else {
+			line = styles.ResultItemStyle.Render(label)
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	if len(m.entities) > visibleCount {
+		fmt.Fprintf(&sb, "\n%d/%d", m.entityCursor+1, len(m.entities))
+	}
+
+	return sb.String()
+}
+
 func (m Model) renderStatusBar() string {
 	if m.tagging {
 		return styles.StatusBarStyle.Render(
@@ -1202,10 +2620,28 @@ func (m Model) renderStatusBar() string {
 				styles.HelpDescStyle.Render("  (enter to save, esc to cancel)"),
 		)
 	}
+	if m.finding {
+		return styles.StatusBarStyle.Render(
+			styles.HelpKeyStyle.Render("Find: ") + m.findInput.View() +
+				styles.HelpDescStyle.Render("  (enter to find, esc to cancel)"),
+		)
+	}
+	if m.palette {
+		return styles.StatusBarStyle.Render(
+			styles.HelpKeyStyle.Render("Command: ") + m.paletteInput.View() +
+				styles.HelpDescStyle.Render("  (↑/↓ to select, enter to run, esc to cancel)"),
+		)
+	}
 
 	statusText := m.statusMsg
-	if m.sourceFilter != "" {
-		statusText = fmt.Sprintf("[%s] %s", m.sourceFilter, statusText)
+	if m.warming {
+		statusText += " · semantic search warming up…"
+	}
+	if m.staleIndex {
+		statusText += " · new documents available — press r"
+	}
+	if len(m.sourceFilters) > 0 {
+		statusText = fmt.Sprintf("[%s] %s", storage.JoinSources(m.sourceFilters), statusText)
 	}
 
 	var status string
@@ -1220,9 +2656,14 @@ func (m Model) renderStatusBar() string {
 		styles.HelpSeparatorStyle.Render(" • ") +
 		styles.HelpKeyStyle.Render("q") +
 		styles.HelpDescStyle.Render(" quit")
+	if m.unseenNotifications > 0 {
+		help = styles.HelpKeyStyle.Render("N") +
+			styles.StatusErrorStyle.Render(fmt.Sprintf(" %d new", m.unseenNotifications)) +
+			styles.HelpSeparatorStyle.Render(" • ") + help
+	}
 
 	return styles.StatusBarStyle.Render(
-		status + strings.Repeat(" ", max(0, m.width-lipgloss.Width(statusText)-lipgloss.Width(" help • q quit")-10)) + help,
+		status + strings.Repeat(" ", max(0, m.width-lipgloss.Width(statusText)-lipgloss.Width(help)-10)) + help,
 	)
 }
 
@@ -1236,8 +2677,12 @@ func (m Model) renderHelp() string {
 		key  string
 		desc string
 	}{
-		{"/", "Focus search"},
+		{"/", "Focus search / Find in preview"},
+		{"n/N", "Next/previous match (preview)"},
+		{"N", "Notifications: warnings and errors this session"},
+		{"Ctrl+r", "Reload config (limits, weights, answer budget, redaction)"},
 		{"Enter", "Execute search / Select item"},
+		{"Paste", "Find notes similar to a pasted passage"},
 		{"j/k or ↑/↓", "Navigate results"},
 		{"Tab", "Cycle panels"},
 		{"Shift+Tab", "Cycle panels (reverse)"},
@@ -1246,12 +2691,23 @@ func (m Model) renderHelp() string {
 		{"r", "Refresh list"},
 		{"i", "Index sources now"},
 		{"f", "Cycle source filter"},
+		{"1-9", "Toggle a source filter chip"},
 		{"t", "Add tag"},
 		{"c", "Add to collection"},
 		{"C", "Browse collections"},
+		{"E", "Browse people, organizations and projects"},
+		{"O", "Recently opened documents"},
+		{"I", "Recently indexed documents"},
+		{"R", "Random note, to rediscover old ones"},
+		{"s", "Save answer as a note"},
+		{"x", "Mark for comparison; on another, compare side by side"},
+		{"L", "Select next link or asset (preview)"},
+		{"Enter", "Follow selected link or open asset (preview)"},
+		{"Backspace", "Back to previous note"},
 		{"g/G", "Go to start/end"},
 		{"Ctrl+u/d", "Half page up/down"},
 		{"Esc", "Cancel / Clear search"},
+		{"Ctrl+k", "Command palette: run any action by name"},
 		{"?", "Toggle help"},
 		{"q", "Quit"},
 	}
@@ -2,15 +2,22 @@ package tui
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/J-1000/mindcli/internal/privacy"
 	"github.com/J-1000/mindcli/internal/query"
+	"github.com/J-1000/mindcli/internal/search"
 	"github.com/J-1000/mindcli/internal/storage"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -79,8 +86,161 @@ func TestNextSourceFilter(t *testing.T) {
 		t.Errorf("after all, got %q, want markdown", got)
 	}
 	// Cycling from the last source wraps back to all.
-	if got := nextSourceFilter(storage.SourceClipboard); got != "" {
-		t.Errorf("after clipboard, got %q, want \"\" (all)", got)
+	if got := nextSourceFilter(storage.SourceProject); got != "" {
+		t.Errorf("after project, got %q, want \"\" (all)", got)
+	}
+}
+
+func TestToggleSource(t *testing.T) {
+	filters := toggleSource(nil, storage.SourcePDF)
+	filters = toggleSource(filters, storage.SourceMarkdown)
+	if storage.JoinSources(filters) != "markdown,pdf" {
+		t.Errorf("filters = %v, want markdown,pdf in source order", filters)
+	}
+	if filters = toggleSource(filters, storage.SourceMarkdown); storage.JoinSources(filters) != "pdf" {
+		t.Errorf("toggling markdown off left %v, want pdf", filters)
+	}
+}
+
+func TestSourceFilterKeys(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := t.Context()
+	now := time.Now()
+	for i, src := range []storage.Source{storage.SourceMarkdown, storage.SourcePDF, storage.SourceEmail} {
+		doc := &storage.Document{ID: fmt.Sprint(i), Source: src, Path: fmt.Sprintf("/doc%d", i), Title: "Go " + string(src), Content: "Learn Go", ContentHash: fmt.Sprint(i), IndexedAt: now, ModifiedAt: now}
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	m.panel = PanelResults
+	press := func(k string) tea.Msg {
+		t.Helper()
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = updated.(Model)
+		return cmd()
+	}
+
+	press("1")
+	msg := press("2")
+	if storage.JoinSources(m.sourceFilters) != "markdown,pdf" {
+		t.Fatalf("after 1 and 2, filters = %v", m.sourceFilters)
+	}
+	loaded, ok := msg.(docsLoadedMsg)
+	if !ok || len(loaded.docs) != 2 {
+		t.Errorf("browse list = %#v, want the markdown and PDF documents", msg)
+	}
+
+	m.searchInput.SetValue("go")
+	found, ok := press("2").(searchResultsMsg)
+	if !ok || len(found.docs) != 1 || found.docs[0].Source != storage.SourceMarkdown {
+		t.Errorf("search after toggling pdf off = %#v, want only markdown", found)
+	}
+
+	// From a single source, f moves to the next one.
+	press("f")
+	if storage.JoinSources(m.sourceFilters) != "pdf" {
+		t.Errorf("f from markdown gave %v, want pdf", m.sourceFilters)
+	}
+	press("3")
+	press("f")
+	if len(m.sourceFilters) != 0 {
+		t.Errorf("f from several sources gave %v, want all", m.sourceFilters)
+	}
+}
+
+func TestSearchPathFilter(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := t.Context()
+	now := time.Now()
+	for i, path := range []string{"/notes/alpha/go.md", "/notes/alpha2/go.md", "/notes/beta/go.md"} {
+		doc := &storage.Document{ID: fmt.Sprint(i), Source: storage.SourceMarkdown, Path: path, Title: "Go notes", Content: "Learn Go", ContentHash: fmt.Sprint(i), IndexedAt: now, ModifiedAt: now}
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	msg, ok := model.searchDocuments("go folder:/notes/alpha", false)().(searchResultsMsg)
+	if !ok {
+		t.Fatal("searchDocuments() did not return searchResultsMsg")
+	}
+	if len(msg.docs) != 1 || msg.docs[0].Path != "/notes/alpha/go.md" {
+		t.Errorf("folder:/notes/alpha found %v, want only /notes/alpha/go.md", msg.docs)
+	}
+	updated, _ := model.Update(msg)
+	if status := updated.(Model).statusMsg; !strings.Contains(status, "[path:/notes/alpha]") {
+		t.Errorf("status = %q, want the path filter shown", status)
+	}
+}
+
+func TestSearchSuggestsAlternatives(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	doc := &storage.Document{ID: "1", Source: storage.SourceMarkdown, Path: "/notes/alpha/go.md", Title: "Go notes", Content: "Learn Go", ContentHash: "h1", IndexedAt: now, ModifiedAt: now}
+	if err := db.InsertDocument(t.Context(), doc); err != nil {
+		t.Fatal(err)
+	}
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	if msg := model.searchDocuments("go folder:/notes/beta", true)().(searchResultsMsg); !msg.suggestions.Empty() {
+		t.Errorf("live search suggested %+v, want suggestions only for committed queries", msg.suggestions)
+	}
+	msg := model.searchDocuments("go folder:/notes/beta", false)().(searchResultsMsg)
+	updated, _ := model.Update(msg)
+	if got := updated.(Model).renderResults(80, 20); !strings.Contains(got, "Without path:/notes/beta: 1 found") {
+		t.Errorf("results without matches = %q, want the broader query suggested", got)
+	}
+}
+
+func TestSearchShowsFacets(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	index, err := search.NewBleveIndex(filepath.Join(t.TempDir(), "test.bleve"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = index.Close() }()
+
+	ctx := t.Context()
+	now := time.Date(2024, 3, 9, 12, 0, 0, 0, time.Local)
+	for _, doc := range []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Path: "/go.md", Title: "Go", Content: "goroutines", ContentHash: "h1",
+			IndexedAt: now, ModifiedAt: now, Metadata: map[string]string{"tags": "golang"}},
+		{ID: "2", Source: storage.SourcePDF, Path: "/go.pdf", Title: "Go book", Content: "goroutines", ContentHash: "h2", IndexedAt: now, ModifiedAt: now},
+	} {
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+		if err := index.Index(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	model := New(db, index, nil, nil, privacy.Redactor{}, nil)
+	msg := model.searchDocuments("goroutines", false)().(searchResultsMsg)
+	if msg.facets == nil || msg.facets.Total != 2 {
+		t.Fatalf("facets = %+v, want 2 matches counted", msg.facets)
+	}
+	updated, _ := model.Update(msg)
+	updated, _ = updated.Update(tea.WindowSizeMsg{Width: minFacetsWidth, Height: 40})
+	view := updated.(Model).View()
+	for _, want := range []string{"2 matches", "Sources", "markdown", "golang", "2024-03"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view doesn't show %q in the facets sidebar:\n%s", want, view)
+		}
+	}
+
+	narrow, _ := updated.Update(tea.WindowSizeMsg{Width: minFacetsWidth - 1, Height: 40})
+	if view := narrow.(Model).View(); strings.Contains(view, "2 matches") {
+		t.Errorf("narrow view shows the facets sidebar:\n%s", view)
 	}
 }
 
@@ -388,204 +548,721 @@ func TestSearchResultsIntegration(t *testing.T) {
 	}
 }
 
-func TestMaxFunction(t *testing.T) {
-	tests := []struct {
-		a, b, want int
-	}{
-		{1, 2, 2},
-		{2, 1, 2},
-		{0, 0, 0},
-		{-1, 1, 1},
-		{-5, -3, -3},
+func TestSemanticLoaderRunsAfterStartup(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	hybrid := query.NewHybridSearcher(nil, nil, nil, db, 0.5)
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.SetSemanticLoader(func() *query.HybridSearcher { return hybrid })
+	model.statusMsg = "0 documents"
+	if status := model.renderStatusBar(); !strings.Contains(status, "semantic search warming up") {
+		t.Errorf("status bar = %q, want it to say semantic search is warming up", status)
 	}
 
-	for _, tt := range tests {
-		got := max(tt.a, tt.b)
-		if got != tt.want {
-			t.Errorf("max(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+	// Init loads the semantic searcher in the background.
+	var ready *semanticReadyMsg
+	for _, cmd := range model.Init()().(tea.BatchMsg) {
+		if msg, ok := cmd().(semanticReadyMsg); ok {
+			ready = &msg
 		}
 	}
-}
+	if ready == nil || ready.hybrid != hybrid {
+		t.Fatalf("Init() did not load the hybrid searcher: %v", ready)
+	}
 
-func TestMinFunction(t *testing.T) {
-	tests := []struct {
-		a, b, want int
-	}{
-		{1, 2, 1},
-		{2, 1, 1},
-		{0, 0, 0},
-		{-1, 1, -1},
-		{-5, -3, -5},
+	// Once it is ready, a full-text-only search is redone with it.
+	model.searchInput.SetValue("go")
+	updated, cmd := model.Update(*ready)
+	m := updated.(Model)
+	if m.hybrid != hybrid || m.warming {
+		t.Errorf("after semanticReadyMsg hybrid = %v, warming = %v", m.hybrid, m.warming)
+	}
+	if cmd == nil {
+		t.Error("semanticReadyMsg did not redo the search")
+	}
+	if status := m.renderStatusBar(); strings.Contains(status, "warming up") {
+		t.Errorf("status bar = %q, still warming up", status)
 	}
 
-	for _, tt := range tests {
-		got := min(tt.a, tt.b)
-		if got != tt.want {
-			t.Errorf("min(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
-		}
+	// Without vectors, searches stay full-text only.
+	updated, cmd = model.Update(semanticReadyMsg{})
+	if m := updated.(Model); m.hybrid != nil || m.warming || cmd != nil {
+		t.Errorf("semanticReadyMsg{nil}: hybrid = %v, warming = %v, cmd = %v", m.hybrid, m.warming, cmd != nil)
 	}
 }
 
-func TestNewWithLLMClient(t *testing.T) {
+func TestSearchRespectsResultsLimit(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	llm := query.NewLLMClient("http://localhost:11434", "llama3.2")
-	model := New(db, nil, nil, llm, privacy.Redactor{}, nil)
+	ctx := t.Context()
+	now := time.Now()
+	for i := range 5 {
+		doc := &storage.Document{ID: fmt.Sprint(i), Source: storage.SourceMarkdown, Path: fmt.Sprintf("/go%d.md", i), Title: "Go notes", Content: "Learn Go", ContentHash: fmt.Sprint(i), IndexedAt: now, ModifiedAt: now}
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+	}
 
-	if model.llm != llm {
-		t.Error("New() did not set LLM client")
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.SetResultsLimit(3)
+	msg, ok := model.searchDocuments("go", false)().(searchResultsMsg)
+	if !ok {
+		t.Fatal("searchDocuments() did not return searchResultsMsg")
+	}
+	if len(msg.docs) != 3 {
+		t.Errorf("search returned %d documents, want the limit of 3", len(msg.docs))
+	}
+
+	model.SetResultsLimit(0)
+	if model.resultsLimit != 3 {
+		t.Errorf("SetResultsLimit(0) changed the limit to %d, want it ignored", model.resultsLimit)
 	}
 }
 
-func TestSearchResultsWithAnswer(t *testing.T) {
+func TestSearchRefinesCommittedQueries(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"response": `{"intent": "search", "confidence": 0.9, "terms": "go notes", "source": "email"}`,
+			"done":     true,
+		})
+	}))
+	defer server.Close()
+
 	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
-	model.width = 120
-	model.height = 40
+	model.SetQueryParser(query.NewLLMParser(query.NewLLMClient(server.URL, "test-model")))
 
-	docs := []*storage.Document{
-		{ID: "1", Title: "Go Guide", Source: storage.SourceMarkdown, Content: "Learn Go"},
-		{ID: "2", Title: "Go Tips", Source: storage.SourceMarkdown, Content: "Go tips"},
+	msg, ok := model.searchDocuments("go notes people mailed me", false)().(searchResultsMsg)
+	if !ok {
+		t.Fatal("searchDocuments() did not return searchResultsMsg")
 	}
-
-	msg := searchResultsMsg{
-		docs: docs,
-		parsed: query.ParsedQuery{
-			Original:    "what is Go?",
-			Intent:      query.IntentAnswer,
-			SearchTerms: "Go",
-		},
+	if msg.parsed.SourceFilter != "email" || msg.parsed.SearchTerms != "go notes" {
+		t.Errorf("committed search parsed as %+v, want the LLM's reading", msg.parsed)
 	}
-	updated, _ := model.Update(msg)
-	m := updated.(Model)
 
-	// Without LLM client, no streaming should start; answerText stays empty
-	if m.answerText != "" {
-		t.Errorf("answerText = %q, want empty (no LLM client)", m.answerText)
-	}
-	if len(m.results) != 2 {
-		t.Errorf("results len = %d, want 2", len(m.results))
+	msg = model.searchDocuments("go notes people mailed me", true)().(searchResultsMsg)
+	if msg.parsed.SourceFilter != "" {
+		t.Errorf("live search SourceFilter = %q, want the heuristics only", msg.parsed.SourceFilter)
 	}
 }
 
-func TestSearchResultsWithSourceFilter(t *testing.T) {
+func TestSearchRewritesFollowUps(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	ctx := t.Context()
+	now := time.Now()
+	doc := &storage.Document{ID: "1", Source: storage.SourceMarkdown, Path: "/channels.md", Title: "Channels",
+		Content: "channel performance benchmarks", ContentHash: "1", IndexedAt: now, ModifiedAt: now}
+	if err := db.InsertDocument(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"response": "channel performance", "done": true})
+	}))
+	defer server.Close()
 
-	msg := searchResultsMsg{
-		docs: []*storage.Document{
-			{ID: "1", Title: "Email 1", Source: storage.SourceEmail},
-		},
-		parsed: query.ParsedQuery{
-			Original:     "meetings in my emails",
-			Intent:       query.IntentSearch,
-			SearchTerms:  "meetings",
-			SourceFilter: "email",
-		},
+	model := New(db, nil, nil, query.NewLLMClient(server.URL, "test-model"), privacy.Redactor{}, nil)
+	msg := model.searchDocuments("what about its performance?", false)().(searchResultsMsg)
+	if msg.rewritten != "" {
+		t.Errorf("rewritten = %q without a conversation, want no rewrite", msg.rewritten)
 	}
-	updated, _ := model.Update(msg)
-	m := updated.(Model)
 
-	if !strings.Contains(m.statusMsg, "[source:email]") {
-		t.Errorf("statusMsg = %q, want it to contain '[source:email]'", m.statusMsg)
+	model.conversation = []query.ConversationTurn{{Question: "what are channels?", Answer: "They connect goroutines."}}
+	msg = model.searchDocuments("what about its performance?", false)().(searchResultsMsg)
+	if msg.rewritten != "channel performance" || len(msg.docs) != 1 {
+		t.Errorf("follow-up searched as %q and found %d documents, want the rewrite to find the note", msg.rewritten, len(msg.docs))
+	}
+	if msg.parsed.Original != "what about its performance?" || msg.parsed.Intent != query.IntentAnswer {
+		t.Errorf("parsed = %+v, want an answer to the question as asked", msg.parsed)
+	}
+
+	// Search-as-you-type doesn't wait for the LLM.
+	if msg := model.searchDocuments("what about its performance?", true)().(searchResultsMsg); msg.rewritten != "" {
+		t.Errorf("live search rewritten = %q, want none", msg.rewritten)
 	}
 }
 
-func TestSearchResultsWithTimeFilter(t *testing.T) {
+func TestSearchPinsAlias(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	ctx := t.Context()
+	now := time.Now()
+	docs := []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Path: "/standup.md", Title: "Daily standup", Content: "blockers", ContentHash: "1", IndexedAt: now, ModifiedAt: now},
+		{ID: "2", Source: storage.SourceMarkdown, Path: "/retro.md", Title: "Retro", Content: "the standup ran long", ContentHash: "2", IndexedAt: now, ModifiedAt: now},
+	}
+	for _, doc := range docs {
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.SetAlias(ctx, "standup", "1"); err != nil {
+		t.Fatal(err)
+	}
 
-	msg := searchResultsMsg{
-		docs: []*storage.Document{
-			{ID: "1", Title: "Note", Source: storage.SourceMarkdown},
-		},
-		parsed: query.ParsedQuery{
-			Original:    "notes from last week",
-			Intent:      query.IntentSearch,
-			SearchTerms: "notes",
-			TimeFilter:  "last week",
-		},
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	msg, ok := model.searchDocuments("Standup ", false)().(searchResultsMsg)
+	if !ok {
+		t.Fatal("searchDocuments() did not return searchResultsMsg")
+	}
+	if msg.alias != "standup" || len(msg.docs) != 2 || msg.docs[0].ID != "1" || msg.docs[1].ID != "2" {
+		t.Errorf("alias %q docs %d, want the aliased note pinned above the other match", msg.alias, len(msg.docs))
 	}
 	updated, _ := model.Update(msg)
-	m := updated.(Model)
+	if m := updated.(Model); !strings.Contains(m.renderResults(80, 20), "→ standup") {
+		t.Error("pinned result not marked with its alias")
+	}
 
-	if !strings.Contains(m.statusMsg, "[last week]") {
-		t.Errorf("statusMsg = %q, want it to contain '[last week]'", m.statusMsg)
+	if msg := model.searchDocuments("retro", false)().(searchResultsMsg); msg.alias != "" {
+		t.Errorf("query without an alias pinned %q", msg.alias)
 	}
 }
 
-func TestShowAnswer(t *testing.T) {
+func TestRenderResultsTruncatesWideTitles(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
-	model.width = 120
-	model.height = 40
-	model.updateViewportSize()
-	model.answerText = "This is the LLM answer."
 	model.results = []*storage.Document{
-		{ID: "1", Title: "Source Doc", Source: storage.SourceMarkdown},
-		{ID: "2", Title: "Source Doc 2", Source: storage.SourceMarkdown},
+		{ID: "1", Source: storage.SourceMarkdown, Path: "/jp.md", Title: "日本語のメモとプロジェクトの計画"},
+		{ID: "2", Source: storage.SourceMarkdown, Path: "/party.md", Title: "🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉"},
 	}
-
-	model.showAnswer()
-
-	content := model.preview.View()
-	if content == "" {
-		t.Error("showAnswer() did not set preview content")
+	out := model.renderResults(20, 10)
+	if !utf8.ValidString(out) {
+		t.Fatalf("renderResults() produced invalid UTF-8: %q", out)
+	}
+	for _, want := range []string{"日本語のメモ...", "🎉🎉🎉🎉🎉🎉..."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("renderResults() = %q, want it to contain %q", out, want)
+		}
 	}
 }
 
-func TestAnswerClearedOnNavigation(t *testing.T) {
+func TestRenderResultsShowsVault(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
-	model.width = 120
-	model.height = 40
+	model.results = []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Path: "/work/plan.md", Title: "Plan", Metadata: map[string]string{"vault": "work"}},
+		{ID: "2", Source: storage.SourceMarkdown, Path: "/notes/todo.md", Title: "Todo"},
+	}
+	out := model.renderResults(80, 10)
+	if strings.Count(out, "⌂") != 1 || !strings.Contains(out, "⌂ work") {
+		t.Errorf("renderResults() = %q, want one vault badge, for work", out)
+	}
+}
 
-	// Simulate receiving search results (no LLM set so no streaming)
-	docs := []*storage.Document{
-		{ID: "1", Title: "Doc 1", Source: storage.SourceMarkdown, Content: "Content 1", Path: "/a.md"},
-		{ID: "2", Title: "Doc 2", Source: storage.SourceMarkdown, Content: "Content 2", Path: "/b.md"},
+func TestPasteFindsSimilarNotes(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	index, err := search.NewBleveIndex(filepath.Join(t.TempDir(), "test.bleve"))
+	if err != nil {
+		t.Fatal(err)
 	}
-	msg := searchResultsMsg{
-		docs:   docs,
-		parsed: query.ParsedQuery{Intent: query.IntentAnswer, SearchTerms: "test"},
+	defer func() { _ = index.Close() }()
+
+	ctx := t.Context()
+	now := time.Now()
+	for _, doc := range []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Path: "/rust.md", Title: "Rust", Content: "ownership and borrowing", ContentHash: "h1", IndexedAt: now, ModifiedAt: now},
+		{ID: "2", Source: storage.SourceMarkdown, Path: "/garden.md", Title: "Garden", Content: "tomatoes and basil", ContentHash: "h2", IndexedAt: now, ModifiedAt: now},
+	} {
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+		if err := index.Index(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
 	}
-	updated, _ := model.Update(msg)
-	m := updated.(Model)
 
-	// Without LLM, no streaming should start — answerText stays empty
-	if m.answerText != "" {
-		t.Fatal("answerText should be empty without LLM client")
+	model := New(db, index, nil, nil, privacy.Redactor{}, nil)
+	pasted := "Each value in Rust has a single owner, and ownership moves when the value is assigned elsewhere."
+	updated, cmd := model.updateSearch(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(pasted), Paste: true})
+	if updated.searchInput.Value() != "" {
+		t.Errorf("search input = %q, want the passage kept out of it", updated.searchInput.Value())
 	}
-	if m.streaming {
-		t.Fatal("should not be streaming without LLM client")
+	msg, ok := cmd().(searchResultsMsg)
+	if !ok || !msg.similar {
+		t.Fatalf("paste returned %#v, want similar results", msg)
+	}
+	if len(msg.docs) != 1 || msg.docs[0].ID != "1" {
+		t.Errorf("similar docs = %v, want the Rust note", msg.docs)
 	}
 
-	// Navigate to results panel and move cursor
-	m.panel = PanelResults
-	downMsg := tea.KeyMsg{Type: tea.KeyDown}
-	updated, _ = m.Update(downMsg)
-	m = updated.(Model)
+	next, _ := updated.Update(msg)
+	if got := next.(Model).statusMsg; got != "1 notes similar to pasted text" {
+		t.Errorf("status = %q", got)
+	}
 
-	if m.cursor != 1 {
-		t.Fatalf("cursor = %d, want 1", m.cursor)
+	// A short paste is typed into the search box as usual.
+	updated, _ = model.updateSearch(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("rust"), Paste: true})
+	if updated.searchInput.Value() != "rust" {
+		t.Errorf("search input = %q, want the short paste", updated.searchInput.Value())
 	}
+}
 
-	// Test streamChunkMsg handling
-	m.streaming = true
-	m.answerText = ""
-	chunkUpdated, _ := m.Update(streamChunkMsg{token: "Hello", done: false})
-	mc := chunkUpdated.(Model)
+func TestFindInPreview(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var lines []string
+	for i := range 60 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines[10] = "Channels connect goroutines; a nil channel blocks"
+	lines[50] = "close the CHANNEL when done"
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m := updated.(Model)
+	updated, _ = m.Update(docsLoadedMsg{docs: []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Path: "/go.md", Title: "Go", Content: strings.Join(lines, "\n")},
+	}})
+	m = updated.(Model)
+	m.panel = PanelPreview
+
+	press := func(msg tea.KeyMsg) {
+		t.Helper()
+		updated, _ := m.Update(msg)
+		m = updated.(Model)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	if !m.finding || m.panel != PanelPreview {
+		t.Fatal("/ in the preview should start finding within the document")
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("channel")})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if len(m.findMatches) != 3 {
+		t.Fatalf("found %d matches, want 3", len(m.findMatches))
+	}
+	if m.statusMsg != `"channel": match 1 of 3 (n/N)` {
+		t.Errorf("status = %q", m.statusMsg)
+	}
+	first := m.preview.YOffset
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if m.findCursor != 2 || m.preview.YOffset <= first {
+		t.Errorf("after n n: match %d at offset %d, want the last match further down than %d", m.findCursor, m.preview.YOffset, first)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if m.findCursor != 0 {
+		t.Errorf("n past the last match = match %d, want it to wrap to the first", m.findCursor)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	if m.findCursor != 2 {
+		t.Errorf("N from the first match = match %d, want the last", m.findCursor)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.findTerm != "" || m.panel != PanelPreview {
+		t.Error("esc should clear the find and stay in the preview")
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("rust")})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.statusIsErr || m.statusMsg != `No matches for "rust"` {
+		t.Errorf("status = %q, want no matches reported", m.statusMsg)
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	if _, n := highlightMatches("Go go GO gopher", "go"); n != 4 {
+		t.Errorf("highlightMatches() = %d matches, want 4", n)
+	}
+	if _, n := highlightMatches("naïve café", "café"); n != 1 {
+		t.Errorf("highlightMatches() = %d matches, want 1 across multibyte text", n)
+	}
+	if _, n := highlightMatches("short", "longer term"); n != 0 {
+		t.Errorf("highlightMatches() = %d matches, want 0", n)
+	}
+}
+
+func TestAnswerUsesContextBudget(t *testing.T) {
+	model := New(nil, nil, nil, nil, privacy.Redactor{}, nil)
+	for i := range 4 {
+		model.results = append(model.results, &storage.Document{ID: fmt.Sprint(i), Content: "Go has goroutines. Channels connect them."})
+	}
+	model.SetContextBudget(query.ContextBudget{MaxContexts: 2, MaxChars: 40})
+
+	if got := len(model.answerSources()); got != 2 {
+		t.Errorf("answer is based on %d sources, want 2", got)
+	}
+	for _, c := range model.answerContexts() {
+		if c != "Go has goroutines." {
+			t.Errorf("context = %q, want it cut at the first sentence", c)
+		}
+	}
+}
+
+func TestAnswerSkipsExcludedSources(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, query.NewLLMClient("http://127.0.0.1:0", "test"), privacy.Redactor{}, nil)
+	model.SetContextBudget(query.ContextBudget{ExcludedSources: []storage.Source{storage.SourceBrowser}})
+	model.width, model.height = 120, 40
+	docs := []*storage.Document{
+		{ID: "visit", Source: storage.SourceBrowser, Path: "/history", Content: "A visited page about Go."},
+		{ID: "note", Source: storage.SourceMarkdown, Path: "/notes/go.md", Content: "A note about Go."},
+	}
+	for _, doc := range docs {
+		if err := db.InsertDocument(t.Context(), doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	updated, _ := model.Update(searchResultsMsg{docs: docs, parsed: query.ParseQuery("what is Go?")})
+	m := updated.(Model)
+	m.cancelStream()
+	if got := m.answerSources(); len(got) != 1 || got[0].ID != "note" {
+		t.Errorf("answer is based on %v, want only the note", got)
+	}
+
+	updated, _ = model.Update(searchResultsMsg{docs: docs, parsed: query.ParseQuery("what is Go? source:browser")})
+	m = updated.(Model)
+	m.cancelStream()
+	if got := m.answerSources(); len(got) != 2 {
+		t.Errorf("answer is based on %d sources, want the visit too when browser is asked for", len(got))
+	}
+}
+
+func TestAnswerUsesAssembledContexts(t *testing.T) {
+	model := New(nil, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width, model.height = 120, 40
+	model.streaming = true
+	model.streamCh = make(chan streamChunkMsg)
+	for i := range 3 {
+		model.results = append(model.results, &storage.Document{ID: fmt.Sprint(i), Content: "Go has goroutines."})
+	}
+
+	picked := []*storage.Document{model.results[2]}
+	updated, cmd := model.Update(streamChunkMsg{contexts: []query.AnswerContext{
+		{Document: picked[0], Excerpts: []query.Excerpt{{Text: "Channels connect goroutines."}}},
+	}})
+	m := updated.(Model)
+	if got := m.answerSources(); len(got) != 1 || got[0] != picked[0] {
+		t.Errorf("answer is based on %v, want the assembled source", got)
+	}
+	if got := m.answerContexts(); len(got) != 1 || got[0] != "Channels connect goroutines." {
+		t.Errorf("answer contexts = %q, want the assembled excerpt", got)
+	}
+	if cmd == nil || !m.streaming {
+		t.Error("the stream should go on after its sources arrive")
+	}
+}
+
+func TestDocumentPaging(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := t.Context()
+	now := time.Now()
+	total := docPageSize + 5
+	for i := 0; i < total; i++ {
+		doc := &storage.Document{
+			ID: fmt.Sprintf("doc%03d", i), Source: storage.SourceMarkdown, Path: fmt.Sprintf("/doc%03d.md", i),
+			Title: fmt.Sprintf("Doc %d", i), Content: "full content", ContentHash: fmt.Sprintf("h%d", i),
+			IndexedAt: now, ModifiedAt: now.Add(-time.Duration(i) * time.Minute),
+		}
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatalf("InsertDocument() error = %v", err)
+		}
+	}
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.height = 40
+	updated, _ := model.Update(model.loadDocuments()())
+	m := updated.(Model)
+	if len(m.results) != docPageSize || !m.moreDocs {
+		t.Fatalf("first page: %d results, more=%v; want %d, true", len(m.results), m.moreDocs, docPageSize)
+	}
+	if m.results[0].Content != "full content" {
+		t.Errorf("previewed document content = %q, want it loaded lazily", m.results[0].Content)
+	}
+	if m.results[1].Content != "" {
+		t.Errorf("unpreviewed document content = %q, want summary only", m.results[1].Content)
+	}
+
+	m.panel = PanelResults
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("jumping to the end should load the next page")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if len(m.results) != total || m.moreDocs {
+		t.Errorf("after paging: %d results, more=%v; want %d, false", len(m.results), m.moreDocs, total)
+	}
+	if m.statusMsg != fmt.Sprintf("%d documents", total) {
+		t.Errorf("status = %q", m.statusMsg)
+	}
+}
+
+func TestStalePageIgnored(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	updated, _ := model.Update(searchResultsMsg{docs: []*storage.Document{{ID: "hit", Title: "Hit"}}})
+	m := updated.(Model)
+
+	updated, _ = m.Update(docsLoadedMsg{docs: []*storage.Document{{ID: "late"}}, offset: 1})
+	m = updated.(Model)
+	if len(m.results) != 1 || m.results[0].ID != "hit" {
+		t.Errorf("results = %v, want search results untouched", m.results)
+	}
+}
+
+func TestMaxFunction(t *testing.T) {
+	tests := []struct {
+		a, b, want int
+	}{
+		{1, 2, 2},
+		{2, 1, 2},
+		{0, 0, 0},
+		{-1, 1, 1},
+		{-5, -3, -3},
+	}
+
+	for _, tt := range tests {
+		got := max(tt.a, tt.b)
+		if got != tt.want {
+			t.Errorf("max(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMinFunction(t *testing.T) {
+	tests := []struct {
+		a, b, want int
+	}{
+		{1, 2, 1},
+		{2, 1, 1},
+		{0, 0, 0},
+		{-1, 1, -1},
+		{-5, -3, -5},
+	}
+
+	for _, tt := range tests {
+		got := min(tt.a, tt.b)
+		if got != tt.want {
+			t.Errorf("min(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNewWithLLMClient(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	llm := query.NewLLMClient("http://localhost:11434", "llama3.2")
+	model := New(db, nil, nil, llm, privacy.Redactor{}, nil)
+
+	if model.llm != llm {
+		t.Error("New() did not set LLM client")
+	}
+}
+
+func TestSearchResultsWithAnswer(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width = 120
+	model.height = 40
+
+	docs := []*storage.Document{
+		{ID: "1", Title: "Go Guide", Source: storage.SourceMarkdown, Content: "Learn Go"},
+		{ID: "2", Title: "Go Tips", Source: storage.SourceMarkdown, Content: "Go tips"},
+	}
+
+	msg := searchResultsMsg{
+		docs: docs,
+		parsed: query.ParsedQuery{
+			Original:    "what is Go?",
+			Intent:      query.IntentAnswer,
+			SearchTerms: "Go",
+		},
+	}
+	updated, _ := model.Update(msg)
+	m := updated.(Model)
+
+	// Without LLM client, no streaming should start; answerText stays empty
+	if m.answerText != "" {
+		t.Errorf("answerText = %q, want empty (no LLM client)", m.answerText)
+	}
+	if len(m.results) != 2 {
+		t.Errorf("results len = %d, want 2", len(m.results))
+	}
+}
+
+func TestSearchResultsWithSourceFilter(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+
+	msg := searchResultsMsg{
+		docs: []*storage.Document{
+			{ID: "1", Title: "Email 1", Source: storage.SourceEmail},
+		},
+		parsed: query.ParsedQuery{
+			Original:     "meetings in my emails",
+			Intent:       query.IntentSearch,
+			SearchTerms:  "meetings",
+			SourceFilter: "email",
+		},
+	}
+	updated, _ := model.Update(msg)
+	m := updated.(Model)
+
+	if !strings.Contains(m.statusMsg, "[source:email]") {
+		t.Errorf("statusMsg = %q, want it to contain '[source:email]'", m.statusMsg)
+	}
+}
+
+func TestSearchResultsWithTimeFilter(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+
+	msg := searchResultsMsg{
+		docs: []*storage.Document{
+			{ID: "1", Title: "Note", Source: storage.SourceMarkdown},
+		},
+		parsed: query.ParsedQuery{
+			Original:    "notes from last week",
+			Intent:      query.IntentSearch,
+			SearchTerms: "notes",
+			TimeFilter:  "last week",
+		},
+	}
+	updated, _ := model.Update(msg)
+	m := updated.(Model)
+
+	if !strings.Contains(m.statusMsg, "[last week]") {
+		t.Errorf("statusMsg = %q, want it to contain '[last week]'", m.statusMsg)
+	}
+}
+
+func TestShowAnswer(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width = 120
+	model.height = 40
+	model.updateViewportSize()
+	model.answerText = "This is the LLM answer."
+	model.results = []*storage.Document{
+		{ID: "1", Title: "Source Doc", Source: storage.SourceMarkdown},
+		{ID: "2", Title: "Source Doc 2", Source: storage.SourceMarkdown},
+	}
+
+	model.showAnswer()
+
+	content := model.preview.View()
+	if content == "" {
+		t.Error("showAnswer() did not set preview content")
+	}
+}
+
+func TestPreviewShowsReadingTime(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width = 120
+	model.height = 40
+	model.updateViewportSize()
+	model.renderPreview(&storage.Document{
+		ID: "1", Title: "Long Note", Source: storage.SourceMarkdown, Path: "/notes/long.md",
+		Content: "Some content", Metadata: map[string]string{"words": "1234"},
+	})
+
+	if content := model.preview.View(); !strings.Contains(content, "1234 words • 6 min read") {
+		t.Errorf("preview missing reading time:\n%s", content)
+	}
+}
+
+func TestPreviewShowsKeywords(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width = 120
+	model.height = 40
+	model.updateViewportSize()
+	model.renderPreview(&storage.Document{
+		ID: "1", Title: "Paper", Source: storage.SourcePDF, Path: "/papers/rag.pdf",
+		Content: strings.Repeat("Retrieval augmented generation grounds answers in documents. ", 20),
+	})
+	if content := model.preview.View(); !strings.Contains(content, "Keywords: retrieval augmented generation") {
+		t.Errorf("preview missing keywords:\n%s", content)
+	}
+
+	model.renderPreview(&storage.Document{
+		ID: "2", Title: "Short", Source: storage.SourceMarkdown, Path: "/notes/short.md",
+		Content: "Retrieval augmented generation, twice: retrieval augmented generation.",
+	})
+	if content := model.preview.View(); strings.Contains(content, "Keywords:") {
+		t.Errorf("short document shouldn't list keywords:\n%s", content)
+	}
+}
+
+func TestAnswerClearedOnNavigation(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width = 120
+	model.height = 40
+
+	// Simulate receiving search results (no LLM set so no streaming)
+	docs := []*storage.Document{
+		{ID: "1", Title: "Doc 1", Source: storage.SourceMarkdown, Content: "Content 1", Path: "/a.md"},
+		{ID: "2", Title: "Doc 2", Source: storage.SourceMarkdown, Content: "Content 2", Path: "/b.md"},
+	}
+	msg := searchResultsMsg{
+		docs:   docs,
+		parsed: query.ParsedQuery{Intent: query.IntentAnswer, SearchTerms: "test"},
+	}
+	updated, _ := model.Update(msg)
+	m := updated.(Model)
+
+	// Without LLM, no streaming should start — answerText stays empty
+	if m.answerText != "" {
+		t.Fatal("answerText should be empty without LLM client")
+	}
+	if m.streaming {
+		t.Fatal("should not be streaming without LLM client")
+	}
+
+	// Navigate to results panel and move cursor
+	m.panel = PanelResults
+	downMsg := tea.KeyMsg{Type: tea.KeyDown}
+	updated, _ = m.Update(downMsg)
+	m = updated.(Model)
+
+	if m.cursor != 1 {
+		t.Fatalf("cursor = %d, want 1", m.cursor)
+	}
+
+	// Test streamChunkMsg handling
+	m.streaming = true
+	m.answerText = ""
+	chunkUpdated, _ := m.Update(streamChunkMsg{token: "Hello", done: false})
+	mc := chunkUpdated.(Model)
 	if mc.answerText != "Hello" {
 		t.Errorf("answerText = %q, want %q", mc.answerText, "Hello")
 	}
@@ -621,3 +1298,450 @@ func TestStreamingErrorUpdatesStatus(t *testing.T) {
 		t.Fatalf("status = %q, want it to contain %q", got.statusMsg, wantErr)
 	}
 }
+
+func TestExtractWikiLinks(t *testing.T) {
+	got := extractWikiLinks("See [[Project Plan]] and [[ideas|my ideas]], again [[Project Plan]]. [[ ]]")
+	want := []string{"Project Plan", "ideas|my ideas"}
+	if len(got) != len(want) {
+		t.Fatalf("extractWikiLinks() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("links[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExtractWikiLinksSkipsAssetEmbeds(t *testing.T) {
+	got := extractWikiLinks("![[diagram.png|300]] ![[Meeting Notes]] [[chart.png]] ![[notes.md#Intro]]")
+	want := []string{"Meeting Notes", "chart.png", "notes.md#Intro"}
+	if !slices.Equal(got, want) {
+		t.Errorf("extractWikiLinks() = %v, want %v", got, want)
+	}
+}
+
+func TestPreviewListsAssets(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := t.Context()
+	now := time.Now()
+	doc := &storage.Document{
+		ID: "report", Source: storage.SourceMarkdown, Path: "/notes/report.md", Title: "Report",
+		Content: "See [[Plan]] and ![[chart.png]].", ContentHash: "h", IndexedAt: now, ModifiedAt: now,
+	}
+	if err := db.InsertDocument(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetRelations(ctx, doc.ID, storage.RelationAsset, []string{"/notes/chart.png"}); err != nil {
+		t.Fatal(err)
+	}
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	updated, _ = updated.(Model).Update(docsLoadedMsg{docs: []*storage.Document{doc}})
+	m := updated.(Model)
+	m.panel = PanelPreview
+	if !slices.Equal(m.assets, []string{"/notes/chart.png"}) || !slices.Equal(m.links, []string{"Plan"}) {
+		t.Fatalf("links = %v, assets = %v; want [Plan] and the chart", m.links, m.assets)
+	}
+
+	// L steps through the links, then the assets.
+	nextLink := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'L'}}
+	for range 2 {
+		updated, _ = m.Update(nextLink)
+		m = updated.(Model)
+	}
+	if m.linkCursor != 1 || m.statusMsg != "Asset: /notes/chart.png" {
+		t.Errorf("linkCursor = %d, status = %q; want the asset selected", m.linkCursor, m.statusMsg)
+	}
+	if view := m.preview.View(); !strings.Contains(view, "Assets:") || !strings.Contains(view, "→ /notes/chart.png") {
+		t.Errorf("preview doesn't show the selected asset:\n%s", view)
+	}
+}
+
+func TestWikiLinkNavigation(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := t.Context()
+	now := time.Now()
+	target := &storage.Document{
+		ID: "target", Source: storage.SourceMarkdown, Path: "/notes/plan.md", Title: "Project Plan",
+		Content: "The plan.", ContentHash: "h2", IndexedAt: now, ModifiedAt: now,
+	}
+	if err := db.InsertDocument(ctx, target); err != nil {
+		t.Fatalf("InsertDocument() error = %v", err)
+	}
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	updated, _ := model.Update(docsLoadedMsg{docs: []*storage.Document{
+		{ID: "start", Source: storage.SourceMarkdown, Title: "Start", Content: "Read [[Project Plan]] and [[Missing]]."},
+	}})
+	m := updated.(Model)
+	m.panel = PanelPreview
+
+	if len(m.links) != 2 {
+		t.Fatalf("links = %v, want 2 entries", m.links)
+	}
+
+	nextLink := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'L'}}
+	updated, _ = m.Update(nextLink)
+	m = updated.(Model)
+	if m.linkCursor != 0 {
+		t.Fatalf("linkCursor = %d, want 0", m.linkCursor)
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Enter on a selected link should return a command")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if doc := m.currentDoc(); doc == nil || doc.ID != "target" {
+		t.Fatalf("currentDoc() = %v, want target", doc)
+	}
+	if len(m.navStack) != 1 {
+		t.Fatalf("navStack length = %d, want 1", len(m.navStack))
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = updated.(Model)
+	if doc := m.currentDoc(); doc == nil || doc.ID != "start" {
+		t.Fatalf("after Back, currentDoc() = %v, want start", doc)
+	}
+	if m.previewDoc != nil || len(m.navStack) != 0 {
+		t.Error("Back to the selected result should clear navigation state")
+	}
+
+	// An unresolvable link reports an error and stays put.
+	m.linkCursor = 1
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if !m.statusIsErr || !strings.Contains(m.statusMsg, "Missing") {
+		t.Errorf("statusMsg = %q (err=%v), want not-found error", m.statusMsg, m.statusIsErr)
+	}
+	if doc := m.currentDoc(); doc == nil || doc.ID != "start" {
+		t.Errorf("currentDoc() = %v, want start", doc)
+	}
+}
+
+func TestSaveAnswer(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.panel = PanelResults
+	model.results = []*storage.Document{{ID: "1", Title: "Doc 1", Source: storage.SourceMarkdown, Content: "deploy steps"}}
+
+	saveKey := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}}
+
+	// Without a saver the action is unavailable.
+	updated, cmd := model.Update(saveKey)
+	m := updated.(Model)
+	if cmd != nil || !m.statusIsErr {
+		t.Fatalf("save without saver: cmd = %v, status = %q", cmd, m.statusMsg)
+	}
+
+	var saved query.Transcript
+	m.SetAnswerSaver(func(_ context.Context, tr query.Transcript) (string, error) {
+		saved = tr
+		return "/notes/answer.md", nil
+	})
+
+	// Nothing to save before an answer exists.
+	updated, cmd = m.Update(saveKey)
+	m = updated.(Model)
+	if cmd != nil || m.statusMsg != "No answer to save" {
+		t.Fatalf("save without answer: status = %q", m.statusMsg)
+	}
+
+	m.currentQuestion = "how do I deploy?"
+	m.answerText = "Run make deploy."
+	updated, cmd = m.Update(saveKey)
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("save with answer should return a command")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+
+	if saved.Question != "how do I deploy?" || saved.Answer != "Run make deploy." || len(saved.Sources) != 1 {
+		t.Errorf("saved transcript = %+v", saved)
+	}
+	if m.statusIsErr || !strings.Contains(m.statusMsg, "/notes/answer.md") {
+		t.Errorf("statusMsg = %q", m.statusMsg)
+	}
+}
+
+func TestBrowseEntities(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := t.Context()
+	now := time.Now()
+	doc := &storage.Document{ID: "standup", Source: storage.SourceMarkdown, Path: "/standup.md", Title: "Standup", ContentHash: "h", IndexedAt: now, ModifiedAt: now}
+	if err := db.InsertDocument(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetEntities(ctx, doc.ID, []storage.Entity{{Kind: storage.EntityPerson, Name: "Jane Doe"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.panel = PanelResults
+	model, cmd := model.updateResults(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	if !model.browsingEntities || cmd == nil {
+		t.Fatal("E should start browsing entities")
+	}
+	updated, _ := model.Update(cmd())
+	model = updated.(Model)
+	if len(model.entities) != 1 || model.entities[0].Documents != 1 {
+		t.Fatalf("entities = %+v, want Jane Doe in one document", model.entities)
+	}
+	if view := model.renderEntitiesList(60, 10); !strings.Contains(view, "Jane Doe (1 docs)") {
+		t.Errorf("entities list = %q, want Jane Doe with a count", view)
+	}
+
+	model, cmd = model.updateResults(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.browsingEntities || cmd == nil {
+		t.Fatal("Enter should leave the browser and search")
+	}
+	if got := model.searchInput.Value(); got != `person:"Jane Doe"` {
+		t.Errorf("search input = %q, want the person filter", got)
+	}
+	msg, ok := cmd().(searchResultsMsg)
+	if !ok || len(msg.docs) != 1 || msg.docs[0].ID != "standup" {
+		t.Errorf("entity search = %+v, want the standup note", msg)
+	}
+}
+
+func TestRecentViews(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := t.Context()
+	now := time.Now()
+	for i, id := range []string{"old", "new"} {
+		doc := &storage.Document{ID: id, Source: storage.SourceMarkdown, Path: "/" + id + ".md", Title: id, ContentHash: id,
+			IndexedAt: now.Add(time.Duration(i) * time.Minute), ModifiedAt: now}
+		if err := db.InsertDocument(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.panel = PanelResults
+	if msg := model.recordOpened("old")(); msg != nil {
+		t.Fatalf("recordOpened() = %v, want nil", msg)
+	}
+
+	for _, tt := range []struct {
+		key, want, status string
+	}{
+		{"O", "old", "1 recently opened"},
+		{"I", "new old", "2 recently indexed"},
+	} {
+		m, cmd := model.updateResults(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
+		if cmd == nil {
+			t.Fatalf("%s returned no command", tt.key)
+		}
+		updated, _ := m.Update(cmd())
+		m = updated.(Model)
+		var ids []string
+		for _, d := range m.results {
+			ids = append(ids, d.ID)
+		}
+		if got := strings.Join(ids, " "); got != tt.want {
+			t.Errorf("%s results = %q, want %q", tt.key, got, tt.want)
+		}
+		if m.statusMsg != tt.status {
+			t.Errorf("%s status = %q, want %q", tt.key, m.statusMsg, tt.status)
+		}
+	}
+}
+
+func TestRandomNote(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.panel = PanelResults
+	m, cmd := model.updateResults(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	updated, _ := m.Update(cmd())
+	if m := updated.(Model); !m.statusIsErr || len(m.results) != 0 {
+		t.Errorf("R with no documents: status = %q, results = %d", m.statusMsg, len(m.results))
+	}
+
+	date := time.Date(2021, 6, 1, 12, 0, 0, 0, time.Local)
+	doc := &storage.Document{ID: "n", Source: storage.SourceMarkdown, Path: "/n.md", Title: "Forgotten", ContentHash: "n", IndexedAt: date, ModifiedAt: date}
+	if err := db.InsertDocument(t.Context(), doc); err != nil {
+		t.Fatal(err)
+	}
+	m, cmd = model.updateResults(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if len(m.results) != 1 || m.results[0].ID != "n" {
+		t.Fatalf("R results = %v, want the one note", m.results)
+	}
+	if !strings.Contains(m.statusMsg, "2021-06-01") {
+		t.Errorf("status = %q, want the note's date", m.statusMsg)
+	}
+}
+
+func TestSourceCountsHeader(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, doc := range []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Path: "/notes/a.md", Title: "A"},
+		{ID: "2", Source: storage.SourceMarkdown, Path: "/notes/b.md", Title: "B"},
+		{ID: "3", Source: storage.SourceEmail, Path: "/mail/c.eml", Title: "C"},
+	} {
+		if err := db.InsertDocument(t.Context(), doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width = 160
+	model.height = 40
+	updated, _ := model.Update(model.loadSourceCounts()())
+	model = updated.(Model)
+
+	header := strings.SplitN(model.View(), "\n", 2)[0]
+	for _, want := range []string{"1 md 2", "3 email 1"} {
+		if !strings.Contains(header, want) {
+			t.Errorf("header missing %q: %s", want, header)
+		}
+	}
+	if strings.Contains(header, "pdf") {
+		t.Errorf("header lists a source with no documents: %s", header)
+	}
+}
+
+func TestStaleIndexHint(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width, model.height = 120, 40
+	model.panel = PanelResults
+	updated, cmd := model.Update(model.syncGeneration()())
+	m := updated.(Model)
+	if !m.polling || cmd == nil {
+		t.Fatal("the first baseline should start polling the index generation")
+	}
+
+	// Unchanged, a poll just schedules the next one.
+	updated, _ = m.Update(m.readGeneration(false)(time.Now()))
+	if m = updated.(Model); m.staleIndex {
+		t.Fatal("an unchanged index shouldn't make results stale")
+	}
+
+	// Another process indexes a document.
+	doc := &storage.Document{ID: "1", Source: storage.SourceMarkdown, Path: "/notes/new.md", Title: "New"}
+	if err := db.InsertDocument(t.Context(), doc); err != nil {
+		t.Fatal(err)
+	}
+	updated, _ = m.Update(m.readGeneration(false)(time.Now()))
+	m = updated.(Model)
+	if !m.staleIndex || !strings.Contains(m.renderStatusBar(), "new documents available — press r") {
+		t.Fatalf("status bar = %q, want the new documents hinted", m.renderStatusBar())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if updated.(Model).staleIndex {
+		t.Error("r should refresh the results and clear the hint")
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width, model.height = 120, 40
+	reloadKey := tea.KeyMsg{Type: tea.KeyCtrlR}
+	if _, cmd := model.Update(reloadKey); cmd != nil {
+		t.Error("without a reloader, ctrl+r should do nothing but say so")
+	}
+
+	fail := true
+	model.SetConfigReloader(func() (func(*Model), error) {
+		if fail {
+			return nil, errors.New("invalid configuration: search.hybrid_weight must be between 0 and 1")
+		}
+		return func(m *Model) {
+			m.SetResultsLimit(7)
+			m.SetHybridWeight(0.9)
+		}, nil
+	})
+	updated, cmd := model.Update(reloadKey)
+	updated, _ = updated.(Model).Update(cmd())
+	m := updated.(Model)
+	if m.resultsLimit != defaultResultsLimit || !m.statusIsErr || len(m.notifications) != 1 {
+		t.Errorf("limit = %d, status = %q; want the invalid config reported, not applied", m.resultsLimit, m.statusMsg)
+	}
+
+	fail = false
+	updated, cmd = m.Update(reloadKey)
+	updated, _ = updated.(Model).Update(cmd())
+	m = updated.(Model)
+	if m.resultsLimit != 7 || m.statusIsErr {
+		t.Errorf("limit = %d, status = %q; want the reloaded config applied", m.resultsLimit, m.statusMsg)
+	}
+
+	// A searcher loaded after the reload searches with the reloaded weight.
+	updated, _ = m.Update(semanticReadyMsg{hybrid: query.NewHybridSearcher(nil, nil, nil, db, 0.5)})
+	if w := updated.(Model).hybrid.HybridWeight; w != 0.9 {
+		t.Errorf("hybrid weight = %v, want the reloaded 0.9", w)
+	}
+}
+
+func TestCompactCount(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 950: "950", 1000: "1k", 1234: "1.2k", 9999: "9.9k", 12500: "12k", 3_400_000: "3.4M"} {
+		if got := compactCount(n); got != want {
+			t.Errorf("compactCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestResultsHoldSummaries(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var docs []*storage.Document
+	for i := range 3 {
+		doc := &storage.Document{ID: fmt.Sprint(i), Source: storage.SourceMarkdown, Path: fmt.Sprintf("/notes/%d.md", i),
+			Title: fmt.Sprintf("Note %d", i), Content: fmt.Sprintf("Content of note %d.", i)}
+		if err := db.InsertDocument(t.Context(), doc); err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, doc)
+	}
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width, model.height = 120, 40
+	model.updateViewportSize()
+	updated, _ := model.Update(searchResultsMsg{docs: docs, parsed: query.ParseQuery("note"), live: true})
+	m := updated.(Model)
+	if docs[0].Content == "" {
+		t.Fatal("the search's own documents shouldn't be changed")
+	}
+	if m.results[0].Content == "" || m.results[1].Content != "" || m.results[2].Content != "" {
+		t.Errorf("only the previewed result should hold its content")
+	}
+
+	m.cursor = 1
+	m.updatePreviewContent()
+	if m.results[0].Content != "" || m.results[1].Content != "Content of note 1." {
+		t.Errorf("moving on should release the last preview's content and fetch the next")
+	}
+	if !strings.Contains(m.preview.View(), "Content of note 1.") {
+		t.Errorf("preview = %q, want the fetched content", m.preview.View())
+	}
+}
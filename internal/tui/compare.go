@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/internal/tui/styles"
+	"github.com/J-1000/mindcli/pkg/chunker"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// compareSide is one of the two documents in the compare view.
+type compareSide struct {
+	doc   *storage.Document
+	lines []string
+	only  []bool // lines the other document doesn't have
+}
+
+// compareLoadedMsg carries the two documents to compare, with content.
+type compareLoadedMsg struct {
+	left, right *storage.Document
+}
+
+// markOrCompare marks the selected result for comparison, or, with another
+// one marked, opens the compare view of the two.
+func (m Model) markOrCompare() (Model, tea.Cmd) {
+	if m.cursor >= len(m.results) {
+		return m, nil
+	}
+	doc := m.results[m.cursor]
+	if m.compareMark == nil || m.compareMark.ID == doc.ID {
+		m.compareMark = doc
+		m.statusMsg = fmt.Sprintf("Marked %s; press x on another document to compare", doc.Title)
+		m.statusIsErr = false
+		return m, nil
+	}
+	left := m.compareMark
+	m.compareMark = nil
+	db := m.db
+	return m, func() tea.Msg {
+		ctx := context.Background()
+		l, err := db.GetDocument(ctx, left.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		r, err := db.GetDocument(ctx, doc.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		return compareLoadedMsg{left: l, right: r}
+	}
+}
+
+// openCompare shows left and right side by side.
+func (m *Model) openCompare(left, right *storage.Document) {
+	l := strings.Split(m.redactor.Redact(left.Content), "\n")
+	r := strings.Split(m.redactor.Redact(right.Content), "\n")
+	m.compare = [2]compareSide{
+		{doc: left, lines: l, only: linesMissingFrom(l, r)},
+		{doc: right, lines: r, only: linesMissingFrom(r, l)},
+	}
+	m.comparing = true
+	m.compareOffset = 0
+	m.statusMsg = compareStatus(m.compare)
+	m.statusIsErr = false
+}
+
+// compareLineKey normalizes a line for comparison: case and spacing don't
+// make two lines differ.
+func compareLineKey(line string) string {
+	return strings.ToLower(strings.Join(strings.Fields(line), " "))
+}
+
+// linesMissingFrom reports, for each line of a, whether b lacks it. Lines
+// are matched wherever they are, so moved paragraphs aren't differences,
+// and blank lines never differ.
+func linesMissingFrom(a, b []string) []bool {
+	have := make(map[string]bool, len(b))
+	for _, line := range b {
+		have[compareLineKey(line)] = true
+	}
+	only := make([]bool, len(a))
+	for i, line := range a {
+		k := compareLineKey(line)
+		only[i] = k != "" && !have[k]
+	}
+	return only
+}
+
+// compareStatus summarizes how much the two documents share.
+func compareStatus(sides [2]compareSide) string {
+	count := func(s compareSide) (only, nonblank int) {
+		for i, line := range s.lines {
+			if strings.TrimSpace(line) != "" {
+				nonblank++
+				if s.only[i] {
+					only++
+				}
+			}
+		}
+		return only, nonblank
+	}
+	lo, ln := count(sides[0])
+	ro, rn := count(sides[1])
+	shared := 0
+	if total := ln + rn; total > 0 {
+		shared = 100 * (total - lo - ro) / total
+	}
+	return fmt.Sprintf("%d%% of lines shared · %d only left · %d only right", shared, lo, ro)
+}
+
+// updateCompare handles keys in the compare view. Both documents scroll
+// together; esc or x closes it.
+func (m Model) updateCompare(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := m.pageStep()
+	switch {
+	case key.Matches(msg, m.keys.Escape), key.Matches(msg, m.keys.Compare), msg.String() == "q":
+		m.comparing = false
+		m.compare = [2]compareSide{}
+		m.statusMsg = ""
+		return m, nil
+	case key.Matches(msg, m.keys.Up):
+		m.scrollCompare(-1)
+	case key.Matches(msg, m.keys.Down):
+		m.scrollCompare(1)
+	case key.Matches(msg, m.keys.PageUp):
+		m.scrollCompare(-2 * page)
+	case key.Matches(msg, m.keys.PageDown):
+		m.scrollCompare(2 * page)
+	case key.Matches(msg, m.keys.HalfUp):
+		m.scrollCompare(-page)
+	case key.Matches(msg, m.keys.HalfDown):
+		m.scrollCompare(page)
+	case key.Matches(msg, m.keys.GotoStart):
+		m.compareOffset = 0
+	case key.Matches(msg, m.keys.GotoEnd):
+		m.scrollCompare(len(m.compare[0].lines) + len(m.compare[1].lines))
+	case key.Matches(msg, m.keys.FindNext):
+		m.nextCompareDifference()
+	}
+	return m, nil
+}
+
+// scrollCompare moves both documents by delta lines, stopping when the
+// longer one's last line is at the top.
+func (m *Model) scrollCompare(delta int) {
+	longest := max(len(m.compare[0].lines), len(m.compare[1].lines))
+	m.compareOffset = min(max(m.compareOffset+delta, 0), max(longest-1, 0))
+}
+
+// nextCompareDifference scrolls to the next line, below the top one, that
+// only one document has.
+func (m *Model) nextCompareDifference() {
+	longest := max(len(m.compare[0].lines), len(m.compare[1].lines))
+	for i := m.compareOffset + 1; i < longest; i++ {
+		for _, s := range m.compare {
+			if i < len(s.only) && s.only[i] {
+				m.compareOffset = i
+				return
+			}
+		}
+	}
+}
+
+// renderCompare renders the two documents side by side from the shared
+// offset, highlighting the lines only one of them has.
+func (m Model) renderCompare(width, height int) string {
+	half := width/2 - 2
+	render := func(s compareSide) string {
+		var sb strings.Builder
+		sb.WriteString(styles.PanelTitleStyle.Render(chunker.TruncateWidth(s.doc.Title, half-2)))
+		for i := m.compareOffset; i < len(s.lines) && i < m.compareOffset+height-2; i++ {
+			line := chunker.TruncateWidth(strings.ReplaceAll(s.lines[i], "\t", "    "), half-2)
+			if s.only[i] {
+				line = styles.PreviewDiffStyle.Render(line)
+			}
+			sb.WriteString("\n" + line)
+		}
+		return styles.PanelStyle.Width(half).Height(height).Render(sb.String())
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, render(m.compare[0]), render(m.compare[1]))
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLinesMissingFrom(t *testing.T) {
+	a := []string{"# Plan", "", "Ship  the beta", "Hire a designer"}
+	b := []string{"hire a designer", "# plan", "ship the beta", "Budget"}
+	got := linesMissingFrom(a, b)
+	for i, want := range []bool{false, false, false, false} {
+		if got[i] != want {
+			t.Errorf("line %q only in a = %v, want %v", a[i], got[i], want)
+		}
+	}
+	if got := linesMissingFrom(b, a); !got[3] || got[0] {
+		t.Errorf("only in b = %v, want just Budget", got)
+	}
+}
+
+func TestCompareView(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	docs := []*storage.Document{
+		{ID: "1", Source: storage.SourceMarkdown, Path: "/notes/plan.md", Title: "Plan",
+			Content: "# Plan\nShip the beta\nHire a designer\n" + strings.Repeat("filler\n", 60) + "Left ending"},
+		{ID: "2", Source: storage.SourceMarkdown, Path: "/notes/plan-copy.md", Title: "Plan copy",
+			Content: "# Plan\nShip the beta\nCut the budget\n" + strings.Repeat("filler\n", 60) + "Right ending"},
+	}
+	for _, doc := range docs {
+		if err := db.InsertDocument(t.Context(), doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width, model.height = 120, 30
+	model.results = summaries(docs)
+	model.panel = PanelResults
+	x := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}
+
+	updated, cmd := model.Update(x)
+	m := updated.(Model)
+	if cmd != nil || m.compareMark == nil {
+		t.Fatal("x should mark the first document")
+	}
+	m.cursor = 1
+	updated, cmd = m.Update(x)
+	if cmd == nil {
+		t.Fatal("x on a second document should load both to compare")
+	}
+	updated, _ = updated.(Model).Update(cmd())
+	m = updated.(Model)
+	if !m.comparing {
+		t.Fatal("the compare view should be open")
+	}
+
+	view := m.View()
+	for _, want := range []string{"Plan copy", "Hire a designer", "Cut the budget"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("compare view missing %q", want)
+		}
+	}
+	if !strings.Contains(m.statusMsg, "2 only left · 2 only right") {
+		t.Errorf("status = %q, want the lines only one document has counted", m.statusMsg)
+	}
+
+	// Both sides scroll together, and n jumps to the next difference.
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updated.(Model)
+	if m.compareOffset != 2 {
+		t.Errorf("after n, offset = %d, want 2 (the first differing line)", m.compareOffset)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updated.(Model)
+	if view := m.View(); !strings.Contains(view, "Left ending") || !strings.Contains(view, "Right ending") {
+		t.Errorf("both documents should scroll to their endings together:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(Model).comparing {
+		t.Error("esc should close the compare view")
+	}
+}
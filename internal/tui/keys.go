@@ -28,6 +28,20 @@ type KeyMap struct {
 	Tag               key.Binding
 	Collection        key.Binding
 	BrowseCollections key.Binding
+	BrowseEntities    key.Binding
+	RecentlyOpened    key.Binding
+	RecentlyIndexed   key.Binding
+	Random            key.Binding
+	NextLink          key.Binding
+	Back              key.Binding
+	SaveAnswer        key.Binding
+	FindNext          key.Binding
+	FindPrev          key.Binding
+	ToggleSource      key.Binding
+	Palette           key.Binding
+	Compare           key.Binding
+	Notifications     key.Binding
+	ReloadConfig      key.Binding
 }
 
 // DefaultKeyMap returns the default keybindings.
@@ -125,6 +139,62 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("C"),
 			key.WithHelp("C", "browse collections"),
 		),
+		BrowseEntities: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "browse people and projects"),
+		),
+		RecentlyOpened: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "recently opened"),
+		),
+		RecentlyIndexed: key.NewBinding(
+			key.WithKeys("I"),
+			key.WithHelp("I", "recently indexed"),
+		),
+		Random: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "random note"),
+		),
+		NextLink: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "next link"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("backspace"),
+			key.WithHelp("backspace", "back"),
+		),
+		SaveAnswer: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "save answer"),
+		),
+		FindNext: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "next match"),
+		),
+		FindPrev: key.NewBinding(
+			key.WithKeys("N"),
+			key.WithHelp("N", "previous match"),
+		),
+		ToggleSource: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("1-9", "toggle source"),
+		),
+		Compare: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "mark / compare"),
+		),
+		Palette: key.NewBinding(
+			key.WithKeys("ctrl+k"),
+			key.WithHelp("ctrl+k", "command palette"),
+		),
+		Notifications: key.NewBinding(
+			key.WithKeys("N"),
+			key.WithHelp("N", "notifications"),
+		),
+		ReloadConfig: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "reload config"),
+		),
 	}
 }
 
@@ -139,6 +209,6 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 		{k.Search, k.Enter, k.Escape},
 		{k.Up, k.Down, k.Tab},
 		{k.Open, k.Copy, k.Refresh},
-		{k.Help, k.Quit},
+		{k.Palette, k.Help, k.Quit},
 	}
 }
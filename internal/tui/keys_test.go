@@ -33,6 +33,16 @@ func TestDefaultKeyMap(t *testing.T) {
 		{"HalfDown", km.HalfDown},
 		{"GotoStart", km.GotoStart},
 		{"GotoEnd", km.GotoEnd},
+		{"BrowseEntities", km.BrowseEntities},
+		{"RecentlyOpened", km.RecentlyOpened},
+		{"RecentlyIndexed", km.RecentlyIndexed},
+		{"Random", km.Random},
+		{"NextLink", km.NextLink},
+		{"Back", km.Back},
+		{"SaveAnswer", km.SaveAnswer},
+		{"FindNext", km.FindNext},
+		{"FindPrev", km.FindPrev},
+		{"ToggleSource", km.ToggleSource},
 	}
 
 	for _, b := range bindings {
@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/tui/styles"
+	"github.com/J-1000/mindcli/pkg/chunker"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxNotifications caps how many notifications the session keeps; the
+// oldest are dropped first.
+const maxNotifications = 200
+
+// notification is a warning or error logged during the session, kept after
+// the status bar has moved on.
+type notification struct {
+	at    time.Time
+	text  string
+	isErr bool // an error rather than a warning
+}
+
+// notifyError shows text as an error in the status bar and logs it.
+func (m *Model) notifyError(text string) {
+	m.statusMsg = text
+	m.statusIsErr = true
+	m.logNotification(text, true)
+}
+
+// logNotification adds text to the notifications log, counting it as
+// unseen until the log is next opened.
+func (m *Model) logNotification(text string, isErr bool) {
+	m.notifications = append(m.notifications, notification{at: time.Now(), text: text, isErr: isErr})
+	if over := len(m.notifications) - maxNotifications; over > 0 {
+		m.notifications = m.notifications[over:]
+	}
+	m.unseenNotifications++
+}
+
+// openNotifications shows the notifications log, newest first.
+func (m Model) openNotifications() (Model, tea.Cmd) {
+	m.notifying = true
+	m.notificationOffset = 0
+	m.unseenNotifications = 0
+	return m, nil
+}
+
+// updateNotifications handles keys in the notifications log: j and k
+// scroll, and esc, N or q close it.
+func (m Model) updateNotifications(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	last := max(len(m.notifications)-1, 0)
+	switch {
+	case key.Matches(msg, m.keys.Escape), key.Matches(msg, m.keys.Notifications), msg.String() == "q":
+		m.notifying = false
+	case key.Matches(msg, m.keys.Up):
+		m.notificationOffset = max(m.notificationOffset-1, 0)
+	case key.Matches(msg, m.keys.Down):
+		m.notificationOffset = min(m.notificationOffset+1, last)
+	case key.Matches(msg, m.keys.GotoStart):
+		m.notificationOffset = 0
+	case key.Matches(msg, m.keys.GotoEnd):
+		m.notificationOffset = last
+	}
+	return m, nil
+}
+
+// renderNotifications renders the log newest first, from the scroll offset.
+func (m Model) renderNotifications(width, height int) string {
+	if len(m.notifications) == 0 {
+		return styles.HelpDescStyle.Render("No warnings or errors this session")
+	}
+	var lines []string
+	for i := len(m.notifications) - 1 - m.notificationOffset; i >= 0 && len(lines) < height; i-- {
+		n := m.notifications[i]
+		mark, style := "!", styles.StatusWarningStyle
+		if n.isErr {
+			mark, style = "✗", styles.StatusErrorStyle
+		}
+		text := chunker.TruncateWidth(n.text, max(width-12, 10))
+		lines = append(lines, fmt.Sprintf("%s %s %s",
+			styles.HelpDescStyle.Render(n.at.Format("15:04:05")), style.Render(mark), text))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNotificationsLog(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width, model.height = 120, 30
+	model.panel = PanelResults
+
+	updated, _ := model.Update(errMsg{errors.New("database is locked")})
+	updated, _ = updated.(Model).Update(reindexDoneMsg{indexed: 10, errs: 2})
+	m := updated.(Model)
+	if m.unseenNotifications != 2 {
+		t.Fatalf("unseen = %d, want the error and the indexing warning", m.unseenNotifications)
+	}
+	if !strings.Contains(m.View(), "2 new") {
+		t.Error("the status bar should count unseen notifications")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	m = updated.(Model)
+	if !m.notifying || m.unseenNotifications != 0 {
+		t.Fatalf("notifying = %v, unseen = %d; want the log open and seen", m.notifying, m.unseenNotifications)
+	}
+	view := m.View()
+	locked := strings.Index(view, "database is locked")
+	indexed := strings.Index(view, "Indexed 10 documents (2 errors)")
+	if locked < 0 || indexed < 0 || indexed > locked {
+		t.Errorf("log should list both notifications, newest first:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(Model).notifying {
+		t.Error("esc should close the notifications log")
+	}
+}
+
+func TestNotificationsCapped(t *testing.T) {
+	var m Model
+	for i := range maxNotifications + 5 {
+		m.logNotification(fmt.Sprintf("warning %d", i), false)
+	}
+	if len(m.notifications) != maxNotifications {
+		t.Fatalf("kept %d notifications, want %d", len(m.notifications), maxNotifications)
+	}
+	if m.notifications[0].text != "warning 5" {
+		t.Errorf("oldest kept = %q, want the first ones dropped", m.notifications[0].text)
+	}
+}
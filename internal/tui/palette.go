@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/internal/tui/styles"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paletteCommand is an action listed in the command palette.
+type paletteCommand struct {
+	name string
+	keys string // shortcut shown beside it, if any
+	run  func(Model) (Model, tea.Cmd)
+}
+
+// paletteCommands returns every action the palette offers. Those with a
+// shortcut run as if its key were pressed in the results panel, so the
+// palette can't drift from what the keys do.
+func (m Model) paletteCommands() []paletteCommand {
+	press := func(name string, b key.Binding) paletteCommand {
+		return paletteCommand{name: name, keys: b.Help().Key, run: func(m Model) (Model, tea.Cmd) {
+			m.panel = PanelResults
+			m.searchInput.Blur()
+			updated, cmd := m.Update(keyPress(b))
+			return updated.(Model), cmd
+		}}
+	}
+	k := m.keys
+	cmds := []paletteCommand{
+		press("Search", k.Search),
+		press("Open selected document", k.Open),
+		press("Copy path of selected document", k.Copy),
+		press("Add tag to selected document", k.Tag),
+		press("Add selected document to a collection", k.Collection),
+		press("Browse collections", k.BrowseCollections),
+		press("Browse people, organizations and projects", k.BrowseEntities),
+		press("Recently opened documents", k.RecentlyOpened),
+		press("Recently indexed documents", k.RecentlyIndexed),
+		press("Random note", k.Random),
+		press("Refresh document list", k.Refresh),
+		press("Save answer as a note", k.SaveAnswer),
+		press("Mark for comparison, or compare with the marked document", k.Compare),
+		press("Cycle source filter", k.Filter),
+		press("Notifications", k.Notifications),
+		press("Reload config", k.ReloadConfig),
+	}
+	if m.reindex != nil {
+		cmds = append(cmds, press("Index sources now", k.Index))
+	}
+	cmds = append(cmds, paletteCommand{name: "Filter: all sources", run: func(m Model) (Model, tea.Cmd) {
+		m.sourceFilters = nil
+		return m, m.refreshForSources()
+	}})
+	for i, s := range storage.AllSources {
+		cmds = append(cmds, paletteCommand{name: "Filter: only " + string(s), run: func(m Model) (Model, tea.Cmd) {
+			m.sourceFilters = []storage.Source{s}
+			return m, m.refreshForSources()
+		}}, paletteCommand{name: "Toggle source " + string(s), keys: fmt.Sprint(i + 1), run: func(m Model) (Model, tea.Cmd) {
+			m.sourceFilters = toggleSource(m.sourceFilters, s)
+			return m, m.refreshForSources()
+		}})
+	}
+	return append(cmds,
+		press("Toggle help", k.Help),
+		press("Quit", k.Quit),
+	)
+}
+
+// keyPress returns the key message of b's first key.
+func keyPress(b key.Binding) tea.KeyMsg {
+	k := b.Keys()[0]
+	switch k {
+	case "ctrl+r":
+		return tea.KeyMsg{Type: tea.KeyCtrlR}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// paletteMatches returns the commands whose names fuzzily match the
+// palette's input, best match first.
+func (m Model) paletteMatches() []paletteCommand {
+	q := strings.TrimSpace(m.paletteInput.Value())
+	type scored struct {
+		cmd   paletteCommand
+		score int
+	}
+	var matches []scored
+	for _, c := range m.paletteCommands() {
+		if score, ok := fuzzyScore(q, c.name); ok {
+			matches = append(matches, scored{c, score})
+		}
+	}
+	// Stable, so equal matches keep the palette's order.
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	out := make([]paletteCommand, len(matches))
+	for i, s := range matches {
+		out[i] = s.cmd
+	}
+	return out
+}
+
+// fuzzyScore reports whether the letters of pattern appear in name in order,
+// ignoring case and spaces, and scores the match: letters that start a word
+// or follow the previous match count extra, so "bc" ranks "Browse
+// collections" above "Subscribe". An empty pattern matches all.
+func fuzzyScore(pattern, name string) (int, bool) {
+	p := []rune(strings.ToLower(strings.ReplaceAll(pattern, " ", "")))
+	n := []rune(strings.ToLower(name))
+	score, j, last := 0, 0, -2
+	for i := 0; i < len(n) && j < len(p); i++ {
+		if n[i] != p[j] {
+			continue
+		}
+		score++
+		if i == 0 || !unicode.IsLetter(n[i-1]) && !unicode.IsDigit(n[i-1]) {
+			score += 2
+		}
+		if i == last+1 {
+			score++
+		}
+		last = i
+		j++
+	}
+	return score, j == len(p)
+}
+
+// openPalette shows the command palette with every command listed.
+func (m Model) openPalette() (Model, tea.Cmd) {
+	m.palette = true
+	m.paletteCursor = 0
+	m.paletteInput.SetValue("")
+	m.paletteInput.Focus()
+	return m, nil
+}
+
+// updatePalette handles keys while the command palette is open: typing
+// narrows the list, up and down select, enter runs and esc closes it.
+func (m Model) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlK:
+		m.palette = false
+		m.paletteInput.Blur()
+		return m, nil
+	case tea.KeyUp, tea.KeyCtrlP:
+		if m.paletteCursor > 0 {
+			m.paletteCursor--
+		}
+		return m, nil
+	case tea.KeyDown, tea.KeyCtrlN:
+		if m.paletteCursor < len(m.paletteMatches())-1 {
+			m.paletteCursor++
+		}
+		return m, nil
+	case tea.KeyEnter:
+		matches := m.paletteMatches()
+		m.palette = false
+		m.paletteInput.Blur()
+		if m.paletteCursor >= len(matches) {
+			return m, nil
+		}
+		return matches[m.paletteCursor].run(m)
+	}
+	var cmd tea.Cmd
+	m.paletteInput, cmd = m.paletteInput.Update(msg)
+	m.paletteCursor = 0
+	return m, cmd
+}
+
+// renderPalette renders the commands matching the palette's input, the
+// selected one highlighted, scrolled to keep it in view.
+func (m Model) renderPalette(width, height int) string {
+	matches := m.paletteMatches()
+	if len(matches) == 0 {
+		return styles.HelpDescStyle.Render("No matching commands")
+	}
+	start := max(0, m.paletteCursor-height+1)
+	var lines []string
+	for i := start; i < len(matches) && len(lines) < height; i++ {
+		c := matches[i]
+		line := fmt.Sprintf("%-*s", max(0, width-12), c.name) + styles.HelpKeyStyle.Render(fmt.Sprintf("%10s", c.keys))
+		if i == m.paletteCursor {
+			line = styles.SelectedResultStyle.Render("▸ ") + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
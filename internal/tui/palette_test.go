@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := fuzzyScore("bcol", "Browse collections"); !ok {
+		t.Error("letters in order should match")
+	}
+	if _, ok := fuzzyScore("colb", "Browse collections"); ok {
+		t.Error("letters out of order shouldn't match")
+	}
+	if _, ok := fuzzyScore("", "Quit"); !ok {
+		t.Error("an empty pattern should match everything")
+	}
+	words, _ := fuzzyScore("bc", "Browse collections")
+	scattered, _ := fuzzyScore("bc", "Subscribe")
+	if words <= scattered {
+		t.Errorf("word starts scored %d, scattered letters %d; want word starts higher", words, scattered)
+	}
+}
+
+func typePalette(m Model, text string) Model {
+	for _, r := range text {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(Model)
+	}
+	return m
+}
+
+func TestPaletteRunsCommands(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width, model.height = 120, 40
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	m := updated.(Model)
+	if !m.palette {
+		t.Fatal("ctrl+k should open the palette")
+	}
+	if !strings.Contains(m.View(), "Browse collections") {
+		t.Error("the open palette should list every command")
+	}
+
+	m = typePalette(m, "only pdf")
+	if got := m.paletteMatches(); len(got) == 0 || got[0].name != "Filter: only pdf" {
+		t.Fatalf("best match for %q = %v, want Filter: only pdf", "only pdf", got)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.palette || len(m.sourceFilters) != 1 || m.sourceFilters[0] != storage.SourcePDF {
+		t.Errorf("palette = %v, filters = %v; want it closed and only pdf searched", m.palette, m.sourceFilters)
+	}
+
+	// Commands with a shortcut do what the key does.
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	m = typePalette(updated.(Model), "recently indexed")
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("running a command should return its work")
+	}
+	if _, ok := cmd().(recentDocsLoadedMsg); !ok {
+		t.Error("Recently indexed documents should load the recent documents, as I does")
+	}
+	if m.panel != PanelResults {
+		t.Errorf("panel = %v, want the results the command acted on", m.panel)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	updated, _ = updated.(Model).Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(Model).palette {
+		t.Error("esc should close the palette")
+	}
+}
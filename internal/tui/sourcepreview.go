@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/J-1000/mindcli/internal/storage"
+	"github.com/J-1000/mindcli/internal/tui/styles"
+)
+
+// maxPreviewPages caps how many of a browser document's pages the preview
+// lists to open.
+const maxPreviewPages = 50
+
+// previewField is a labelled value in a preview's header block.
+type previewField struct {
+	label, value string
+}
+
+// sourceFields returns the header block the preview shows above a
+// document's content for its source: an email's headers, a PDF's page
+// count, a browser's visits and bookmarks. Other sources have none.
+func sourceFields(doc *storage.Document) []previewField {
+	var fields []previewField
+	add := func(label, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			fields = append(fields, previewField{label, value})
+		}
+	}
+	switch doc.Source {
+	case storage.SourceEmail:
+		add("From", doc.Metadata["from"])
+		add("To", doc.Metadata["to"])
+		if date, err := time.Parse(time.RFC3339, doc.Metadata["date"]); err == nil {
+			add("Date", date.Format("Mon 2 Jan 2006 15:04"))
+		}
+		add("Subject", doc.Title)
+		if n := doc.Metadata["messages"]; n != "" && n != "1" {
+			add("Thread", n+" messages")
+		}
+		add("Attachments", doc.Metadata["attachments"])
+	case storage.SourcePDF:
+		if n := doc.Metadata["pages"]; n != "" {
+			add("Pages", n)
+		}
+	case storage.SourceBrowser:
+		if n := doc.Metadata["history_count"]; n != "" {
+			add("History", n+" pages")
+		}
+		if n := doc.Metadata["bookmark_count"]; n != "" && n != "0" {
+			add("Bookmarks", n)
+		}
+	}
+	return fields
+}
+
+// renderSourceFields renders fields as an aligned block, redacted.
+func (m *Model) renderSourceFields(fields []previewField) string {
+	width := 0
+	for _, f := range fields {
+		width = max(width, len(f.label))
+	}
+	var sb strings.Builder
+	for _, f := range fields {
+		sb.WriteString(styles.HelpKeyStyle.Render(fmt.Sprintf("%-*s", width+1, f.label+":")))
+		sb.WriteString(" ")
+		sb.WriteString(m.redactor.Redact(f.value))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// browserPages returns the URLs listed in a browser document, in order and
+// without repeats, up to maxPreviewPages.
+func browserPages(content string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "http://") && !strings.HasPrefix(line, "https://") || seen[line] {
+			continue
+		}
+		seen[line] = true
+		urls = append(urls, line)
+		if len(urls) == maxPreviewPages {
+			break
+		}
+	}
+	return urls
+}
+
+// assetLabel names what the preview lists after a document's wiki links and
+// opens with Enter: a browser document's pages, or a note's files.
+func assetLabel(doc *storage.Document) string {
+	if doc != nil && doc.Source == storage.SourceBrowser {
+		return "Page"
+	}
+	return "Asset"
+}
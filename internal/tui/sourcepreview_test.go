@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/J-1000/mindcli/internal/privacy"
+	"github.com/J-1000/mindcli/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSourceFields(t *testing.T) {
+	email := &storage.Document{Source: storage.SourceEmail, Title: "Offsite", Metadata: map[string]string{
+		"from": "Ada <ada@example.com>", "to": "team@example.com",
+		"date": "2026-03-02T09:30:00Z", "messages": "3",
+	}}
+	var got []string
+	for _, f := range sourceFields(email) {
+		got = append(got, f.label+"="+f.value)
+	}
+	want := "From=Ada <ada@example.com> To=team@example.com Date=Mon 2 Mar 2026 09:30 Subject=Offsite Thread=3 messages"
+	if strings.Join(got, " ") != want {
+		t.Errorf("email fields = %q, want %q", strings.Join(got, " "), want)
+	}
+
+	pdf := &storage.Document{Source: storage.SourcePDF, Metadata: map[string]string{"pages": "12"}}
+	if f := sourceFields(pdf); len(f) != 1 || f[0].value != "12" {
+		t.Errorf("pdf fields = %v, want the page count", f)
+	}
+	if f := sourceFields(&storage.Document{Source: storage.SourceMarkdown}); len(f) != 0 {
+		t.Errorf("markdown fields = %v, want none", f)
+	}
+}
+
+func TestBrowserPages(t *testing.T) {
+	content := "Go\nhttps://go.dev\n\n[Bookmark] Docs\nhttps://go.dev/doc\n\nGo again\nhttps://go.dev\n\nnot a url\n"
+	got := browserPages(content)
+	if len(got) != 2 || got[0] != "https://go.dev" || got[1] != "https://go.dev/doc" {
+		t.Errorf("browserPages = %v, want each URL once in order", got)
+	}
+}
+
+func TestPreviewShowsSourceHeaders(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	doc := &storage.Document{ID: "1", Source: storage.SourceEmail, Path: "/mail/1", Title: "Offsite",
+		Content:  "Let's meet on Friday.",
+		Metadata: map[string]string{"from": "ada@example.com", "to": "bob@example.com", "messages": "2"}}
+	model := New(db, nil, nil, nil, privacy.Redactor{}, nil)
+	model.width, model.height = 120, 40
+	model.updateViewportSize()
+	model.renderPreview(doc)
+	view := model.preview.View()
+	for _, want := range []string{"From:", "ada@example.com", "Subject:", "Thread:", "2 messages"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("email preview missing %q:\n%s", want, view)
+		}
+	}
+
+	browser := &storage.Document{ID: "2", Source: storage.SourceBrowser, Path: "/browser/history", Title: "History",
+		Content: "Go\nhttps://go.dev\n\nDocs\nhttps://go.dev/doc\n"}
+	model.results = []*storage.Document{browser}
+	model.panel = PanelPreview
+	model.renderPreview(browser)
+	if view := model.preview.View(); !strings.Contains(view, "Pages:") {
+		t.Errorf("browser preview should list its pages:\n%s", view)
+	}
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'L'}})
+	if got := updated.(Model).statusMsg; got != "Page: https://go.dev" {
+		t.Errorf("status = %q, want the first page selected", got)
+	}
+}
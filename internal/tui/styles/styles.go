@@ -94,9 +94,18 @@ var (
 	PreviewContentStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#E5E7EB"))
 
+	PreviewFindMatchStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#111827")).
+				Background(ColorPrimary)
+
 	PreviewMetadataStyle = lipgloss.NewStyle().
 				Foreground(ColorMuted).
 				MarginTop(1)
+
+	// PreviewDiffStyle marks lines of a compared document that the other
+	// one doesn't have.
+	PreviewDiffStyle = lipgloss.NewStyle().
+				Foreground(ColorWarning)
 )
 
 // Status bar styles.
@@ -115,6 +124,9 @@ var (
 	StatusErrorStyle = lipgloss.NewStyle().
 				Foreground(ColorError)
 
+	StatusWarningStyle = lipgloss.NewStyle().
+				Foreground(ColorWarning)
+
 	StatusSuccessStyle = lipgloss.NewStyle().
 				Foreground(ColorSecondary)
 )
@@ -145,6 +157,15 @@ func TagBadge(tag string) string {
 		Render("#" + tag)
 }
 
+// AliasBadge marks the result an alias jumped to.
+func AliasBadge(name string) string {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("229")).
+		Background(ColorPrimary).
+		Padding(0, 1).
+		Render("→ " + name)
+}
+
 // CollectionBadge renders a collection name as a colored badge.
 func CollectionBadge(name string) string {
 	return lipgloss.NewStyle().
@@ -154,14 +175,36 @@ func CollectionBadge(name string) string {
 		Render("@" + name)
 }
 
+// VaultBadge renders the name of the vault a note is in.
+func VaultBadge(name string) string {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("189")).
+		Background(lipgloss.Color("60")).
+		Padding(0, 1).
+		Render("⌂ " + name)
+}
+
+// SourceChip renders a source filter chip: in the source's color when on,
+// muted when off.
+func SourceChip(label, source string, on bool) string {
+	if !on {
+		return lipgloss.NewStyle().Foreground(ColorMuted).Padding(0, 1).Render(label)
+	}
+	return SourceBadge(source).Reverse(true).Render(label)
+}
+
 // Badge styles for source types.
 func SourceBadge(source string) lipgloss.Style {
 	colors := map[string]lipgloss.Color{
-		"markdown":  lipgloss.Color("#3B82F6"), // Blue
-		"pdf":       lipgloss.Color("#EF4444"), // Red
-		"email":     lipgloss.Color("#F59E0B"), // Yellow
-		"browser":   lipgloss.Color("#10B981"), // Green
-		"clipboard": lipgloss.Color("#8B5CF6"), // Purple
+		"markdown":   lipgloss.Color("#3B82F6"), // Blue
+		"pdf":        lipgloss.Color("#EF4444"), // Red
+		"email":      lipgloss.Color("#F59E0B"), // Yellow
+		"browser":    lipgloss.Color("#10B981"), // Green
+		"clipboard":  lipgloss.Color("#8B5CF6"), // Purple
+		"data":       lipgloss.Color("#14B8A6"), // Teal
+		"reference":  lipgloss.Color("#EC4899"), // Pink
+		"screenshot": lipgloss.Color("#F97316"), // Orange
+		"project":    lipgloss.Color("#6366F1"), // Indigo
 	}
 
 	color, ok := colors[source]
@@ -1,6 +1,7 @@
 package styles
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/lipgloss"
@@ -51,6 +52,7 @@ func TestStylesAreDefined(t *testing.T) {
 		{"ResultPreviewStyle", ResultPreviewStyle},
 		{"PreviewTitleStyle", PreviewTitleStyle},
 		{"PreviewContentStyle", PreviewContentStyle},
+		{"PreviewFindMatchStyle", PreviewFindMatchStyle},
 		{"PreviewMetadataStyle", PreviewMetadataStyle},
 		{"StatusBarStyle", StatusBarStyle},
 		{"StatusKeyStyle", StatusKeyStyle},
@@ -108,3 +110,23 @@ func TestSourceBadgeColors(t *testing.T) {
 		t.Error("markdown and pdf badges should have different colors")
 	}
 }
+
+func TestVaultBadge(t *testing.T) {
+	if got := VaultBadge("work"); !strings.Contains(got, "work") {
+		t.Errorf("VaultBadge(%q) = %q, want the vault name", "work", got)
+	}
+}
+
+func TestSourceChip(t *testing.T) {
+	on := SourceChip("1 markdown", "markdown", true)
+	off := SourceChip("1 markdown", "markdown", false)
+	for _, chip := range []string{on, off} {
+		if !strings.Contains(chip, "1 markdown") {
+			t.Errorf("chip %q is missing its label", chip)
+		}
+	}
+	// Toggling a chip must not shift the ones after it.
+	if lipgloss.Width(on) != lipgloss.Width(off) {
+		t.Errorf("chip widths differ: on %d, off %d", lipgloss.Width(on), lipgloss.Width(off))
+	}
+}
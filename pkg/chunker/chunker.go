@@ -219,9 +219,11 @@ func findSentences(text string) []segment {
 
 	for i := 0; i < len(runes); i++ {
 		r := runes[i]
-		if r == '.' || r == '!' || r == '?' {
-			// Look ahead: if followed by space+uppercase or end, it's a boundary.
-			if i+1 >= len(runes) || (i+2 < len(runes) && unicode.IsSpace(runes[i+1]) && unicode.IsUpper(runes[i+2])) {
+		if isSentenceEnd(r) {
+			// Look ahead: if followed by space+uppercase or end, it's a
+			// boundary. CJK full stops always end a sentence, since the
+			// scripts that use them don't separate sentences with spaces.
+			if i+1 >= len(runes) || isFullWidthStop(r) || (i+2 < len(runes) && unicode.IsSpace(runes[i+1]) && unicode.IsUpper(runes[i+2])) {
 				byteEnd := len(string(runes[:i+1]))
 				byteStart := len(string(runes[:start]))
 				sent := strings.TrimSpace(string(runes[start : i+1]))
@@ -257,6 +259,25 @@ func findSentences(text string) []segment {
 	return sentences
 }
 
+// isSentenceEnd reports whether r can end a sentence.
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '.', '!', '?':
+		return true
+	}
+	return isFullWidthStop(r)
+}
+
+// isFullWidthStop reports whether r is a sentence-ending mark of Chinese or
+// Japanese text.
+func isFullWidthStop(r rune) bool {
+	switch r {
+	case '。', '！', '？', '｡':
+		return true
+	}
+	return false
+}
+
 // applyOverlap extends each chunk (except the first) to include text from
 // the end of the previous chunk, creating overlapping context windows.
 func applyOverlap(fullText string, chunks []Chunk, overlap int) []Chunk {
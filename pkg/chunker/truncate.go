@@ -0,0 +1,54 @@
+package chunker
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ellipsis marks text shortened by Truncate.
+const Ellipsis = "..."
+
+// Truncate shortens text to at most maxLen characters followed by Ellipsis.
+// It ends after the last whole sentence when that keeps at least half of
+// maxLen, else at the last word boundary, and cuts mid-word only when no
+// boundary is that far in. Text within maxLen, or a maxLen of 0 or less, is
+// returned unchanged.
+func Truncate(text string, maxLen int) string {
+	if maxLen <= 0 || utf8.RuneCountInString(text) <= maxLen {
+		return text
+	}
+	cut := text
+	for i := range text {
+		if maxLen == 0 {
+			cut = text[:i]
+			break
+		}
+		maxLen--
+	}
+	half := utf8.RuneCountInString(cut) / 2
+
+	if end := lastSentenceEnd(cut); end > 0 && utf8.RuneCountInString(cut[:end]) >= half {
+		if r, _ := utf8.DecodeLastRuneInString(cut[:end]); isFullWidthStop(r) {
+			return cut[:end] + Ellipsis
+		}
+		return cut[:end] + " " + Ellipsis
+	}
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 && utf8.RuneCountInString(cut[:i]) >= half {
+		cut = cut[:i]
+	}
+	return strings.TrimRightFunc(cut, unicode.IsSpace) + Ellipsis
+}
+
+// lastSentenceEnd returns the byte offset just past the last complete
+// sentence in text, or 0 when it has none.
+func lastSentenceEnd(text string) int {
+	sentences := findSentences(text)
+	for i := len(sentences) - 1; i >= 0; i-- {
+		s := sentences[i]
+		if r, _ := utf8.DecodeLastRuneInString(s.content); isSentenceEnd(r) {
+			return s.startPos + strings.Index(text[s.startPos:], s.content) + len(s.content)
+		}
+	}
+	return 0
+}
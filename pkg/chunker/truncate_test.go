@@ -0,0 +1,28 @@
+package chunker
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxLen int
+		want   string
+	}{
+		{"fits", "Short text.", 20, "Short text."},
+		{"no limit", "Short text.", 0, "Short text."},
+		{"sentence boundary", "First sentence here. Second one is much longer than that.", 40, "First sentence here. ..."},
+		{"word boundary", "one two three four five six seven", 16, "one two three..."},
+		{"sentence too short", "Hi. Then a long run of words without any stop", 30, "Hi. Then a long run of words..."},
+		{"long word", "abcdefghijklmnopqrstuvwxyz", 10, "abcdefghij..."},
+		{"emoji", "Notes 🎉🎉🎉🎉🎉🎉🎉🎉", 8, "Notes..."},
+		{"multibyte", "日本語のテキストです。とても長い文章が続きます", 12, "日本語のテキストです。..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Truncate(tt.text, tt.maxLen); got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.text, tt.maxLen, got, tt.want)
+			}
+		})
+	}
+}
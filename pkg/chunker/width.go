@@ -0,0 +1,41 @@
+package chunker
+
+import "github.com/mattn/go-runewidth"
+
+// Width returns the number of terminal columns text occupies. East Asian
+// wide characters and most emoji take two columns; combining marks take none.
+func Width(text string) int {
+	return runewidth.StringWidth(text)
+}
+
+// TruncateWidth shortens text to fit in width terminal columns, ending in
+// Ellipsis when it is cut. Unlike Truncate it doesn't look for a sentence
+// or word boundary, and it never splits a character or a sequence of runes
+// that displays as one, such as an emoji with a skin tone. A width of 0 or
+// less yields an empty string.
+func TruncateWidth(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if width <= Width(Ellipsis) {
+		return runewidth.Truncate(text, width, "")
+	}
+	return runewidth.Truncate(text, width, Ellipsis)
+}
+
+// TruncateWidthLeft is TruncateWidth for text whose end matters more than
+// its start, such as a file path: it drops characters from the front and
+// puts Ellipsis before what is left.
+func TruncateWidthLeft(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	w := Width(text)
+	if w <= width {
+		return text
+	}
+	if width <= Width(Ellipsis) {
+		return runewidth.TruncateLeft(text, w-width, "")
+	}
+	return runewidth.TruncateLeft(text, w-width+Width(Ellipsis), Ellipsis)
+}
@@ -0,0 +1,57 @@
+package chunker
+
+import "testing"
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{"fits", "short", 10, "short"},
+		{"ascii", "abcdefghijkl", 8, "abcde..."},
+		{"wide runes", "日本語のタイトル", 9, "日本語..."},
+		{"emoji", "🎉🎉🎉🎉🎉", 7, "🎉🎉..."},
+		{"emoji with modifier", "👍🏽👍🏽👍🏽", 5, "👍🏽..."},
+		{"combining mark", "cafe\u0301 au lait", 7, "cafe\u0301..."},
+		{"narrower than ellipsis", "abcdef", 2, "ab"},
+		{"zero width", "abc", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateWidth(tt.text, tt.width)
+			if got != tt.want {
+				t.Errorf("TruncateWidth(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+			if Width(got) > max(tt.width, 0) {
+				t.Errorf("TruncateWidth(%q, %d) is %d columns wide", tt.text, tt.width, Width(got))
+			}
+		})
+	}
+}
+
+func TestTruncateWidthLeft(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{"fits", "notes.md", 10, "notes.md"},
+		{"ascii", "/a/very/long/path/to/file.txt", 15, ".../to/file.txt"},
+		{"wide runes", "/メモ/日本語.md", 10, "...本語.md"},
+		{"zero width", "abc", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateWidthLeft(tt.text, tt.width)
+			if got != tt.want {
+				t.Errorf("TruncateWidthLeft(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+			if Width(got) > tt.width {
+				t.Errorf("TruncateWidthLeft(%q, %d) is %d columns wide", tt.text, tt.width, Width(got))
+			}
+		})
+	}
+}
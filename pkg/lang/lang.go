@@ -0,0 +1,144 @@
+// Package lang detects the natural language of a text, well enough to tell
+// the notes of a multilingual vault apart.
+package lang
+
+import (
+	"strings"
+	"unicode"
+)
+
+// sampleRunes is how much of a text Detect looks at.
+const sampleRunes = 4000
+
+// minStopwords is the fewest stopwords a Latin-script text needs before it
+// is assigned a language.
+const minStopwords = 3
+
+// stopwords are frequent words that are rare in the other languages listed.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "that", "with", "for", "this", "are", "was", "have", "it", "not", "be", "which", "from", "you"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "mit", "ein", "eine", "ich", "sie", "auf", "auch", "dem", "den", "sich", "für", "wird"},
+	"fr": {"le", "la", "les", "et", "est", "des", "une", "pour", "dans", "que", "qui", "pas", "sur", "avec", "du", "au", "sont", "nous"},
+	"es": {"el", "los", "las", "y", "es", "una", "para", "por", "con", "del", "que", "se", "está", "como", "pero", "muy", "también", "son"},
+	"it": {"il", "della", "che", "è", "non", "gli", "una", "per", "sono", "con", "anche", "del", "nel", "alla", "questo", "ho", "lo", "ma"},
+	"pt": {"os", "que", "não", "uma", "para", "com", "é", "mais", "como", "mas", "dos", "das", "ao", "está", "também", "você", "foi", "em"},
+	"nl": {"het", "een", "van", "en", "is", "niet", "dat", "ik", "zijn", "met", "voor", "ook", "wordt", "naar", "maar", "wij", "deze", "bij"},
+	"sv": {"och", "att", "det", "är", "som", "inte", "för", "med", "en", "har", "jag", "på", "av", "till", "den", "kan", "om", "var"},
+	"pl": {"i", "nie", "się", "jest", "że", "na", "do", "to", "jak", "ale", "tak", "dla", "czy", "już", "są", "przez", "który", "od"},
+}
+
+var stopwordLangs = func() map[string][]string {
+	m := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			m[w] = append(m[w], lang)
+		}
+	}
+	return m
+}()
+
+// Detect returns the ISO 639-1 code of the language text is written in,
+// or "" when it can't tell. Texts in a script used mostly by one language
+// (Japanese, Korean, Chinese, Russian, Greek, Arabic, Hebrew, Hindi, Thai)
+// are told apart by their letters; Latin-script texts by their stopwords,
+// for English, German, French, Spanish, Italian, Portuguese, Dutch,
+// Swedish and Polish.
+func Detect(text string) string {
+	if lang := detectScript(text); lang != "" {
+		return lang
+	}
+	return detectLatin(text)
+}
+
+// detectScript returns the language of the non-Latin script most of the
+// letters in text belong to, if any.
+func detectScript(text string) string {
+	counts := make(map[string]int)
+	var letters, kana int
+	n := 0
+	for _, r := range text {
+		if n++; n > sampleRunes {
+			break
+		}
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+			counts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			if strings.ContainsRune("іїєґІЇЄҐ", r) {
+				counts["uk"]++
+			}
+			counts["ru"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	// Japanese mixes kanji with kana; any amount of kana settles it.
+	if kana > 0 {
+		counts["ja"] += counts["zh"]
+		counts["zh"] = 0
+	}
+	// Ukrainian is told from Russian by the letters only it uses.
+	if counts["uk"] > 0 {
+		counts["uk"] = counts["ru"]
+		counts["ru"] = 0
+	}
+	best, bestCount := "", 0
+	for lang, c := range counts {
+		if c > bestCount || (c == bestCount && lang < best) {
+			best, bestCount = lang, c
+		}
+	}
+	if bestCount*2 < letters {
+		return ""
+	}
+	return best
+}
+
+// detectLatin returns the language whose stopwords occur most often in
+// text, if it is clearly ahead.
+func detectLatin(text string) string {
+	if len(text) > sampleRunes*2 {
+		text = text[:sampleRunes*2]
+	}
+	scores := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		for _, lang := range stopwordLangs[word] {
+			scores[lang]++
+		}
+	}
+	best, bestScore, second := "", 0, 0
+	for lang, s := range scores {
+		switch {
+		case s > bestScore || (s == bestScore && lang < best):
+			best, bestScore, second = lang, s, max(second, bestScore)
+		case s > second:
+			second = s
+		}
+	}
+	if bestScore < minStopwords || bestScore == second {
+		return ""
+	}
+	return best
+}
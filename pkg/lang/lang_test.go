@@ -0,0 +1,45 @@
+package lang
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"english", "The meeting notes are in the shared folder, and this is the plan for the week.", "en"},
+		{"german", "Die Notizen zum Treffen sind im Ordner, und das ist der Plan für die Woche.", "de"},
+		{"french", "Les notes de la réunion sont dans le dossier, et voici le plan pour la semaine.", "fr"},
+		{"spanish", "Las notas de la reunión están en la carpeta y este es el plan para la semana, pero muy corto.", "es"},
+		{"italian", "Le note della riunione sono nella cartella e questo è il piano per la settimana, non altro.", "it"},
+		{"portuguese", "As notas da reunião estão na pasta, mas não sei se você viu o plano para a semana.", "pt"},
+		{"dutch", "De notities van het overleg staan in de map, en dit is het plan voor de week.", "nl"},
+		{"swedish", "Anteckningarna från mötet är i mappen, och det här är planen för veckan som jag har.", "sv"},
+		{"polish", "Notatki ze spotkania są w folderze i to jest plan na tydzień, ale nie wiem czy już.", "pl"},
+		{"japanese", "会議のメモは共有フォルダにあります。今週の計画です。", "ja"},
+		{"chinese", "会议记录在共享文件夹中。这是本周的计划。", "zh"},
+		{"korean", "회의록은 공유 폴더에 있습니다. 이번 주 계획입니다.", "ko"},
+		{"russian", "Заметки о встрече находятся в общей папке. Это план на неделю.", "ru"},
+		{"ukrainian", "Нотатки про зустріч є у спільній теці. Це план на тиждень.", "uk"},
+		{"greek", "Οι σημειώσεις της συνάντησης είναι στον κοινόχρηστο φάκελο.", "el"},
+		{"too short", "Groceries", ""},
+		{"no letters", "42 + 17 = 59", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectMixedScripts(t *testing.T) {
+	// A few foreign words don't change the language of the text.
+	text := "The trip to Tokyo was great and the food (寿司) was the best part of it."
+	if got := Detect(text); got != "en" {
+		t.Errorf("Detect() = %q, want en", got)
+	}
+}
@@ -27,7 +27,7 @@ esac
 ARCHIVE="mindcli_${VERSION}_${OS}_${ARCH}.tar.gz"
 
 echo "Building test binary..."
-CGO_ENABLED=1 GOOS="${OS}" GOARCH="${ARCH}" go build -ldflags "-s -w -X main.version=${VERSION}" -o "${TMP_DIR}/mindcli" ./cmd/mindcli
+CGO_ENABLED=1 GOOS="${OS}" GOARCH="${ARCH}" go build -tags sqlite_fts5 -ldflags "-s -w -X main.version=${VERSION}" -o "${TMP_DIR}/mindcli" ./cmd/mindcli
 
 echo "Creating release-style archive ${ARCHIVE}..."
 tar -czf "${TMP_DIR}/${ARCHIVE}" -C "${TMP_DIR}" mindcli